@@ -17,12 +17,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/admin_camera_tracker"
 	"go.codycody31.dev/squad-aegis/internal/ban_enforcer"
 	"go.codycody31.dev/squad-aegis/internal/clickhouse"
 	"go.codycody31.dev/squad-aegis/internal/core"
 	"go.codycody31.dev/squad-aegis/internal/db"
 	"go.codycody31.dev/squad-aegis/internal/event_manager"
 	"go.codycody31.dev/squad-aegis/internal/identity"
+	"go.codycody31.dev/squad-aegis/internal/log_importer"
 	"go.codycody31.dev/squad-aegis/internal/logwatcher_manager"
 	"go.codycody31.dev/squad-aegis/internal/models"
 	"go.codycody31.dev/squad-aegis/internal/permissions"
@@ -30,6 +32,7 @@ import (
 	"go.codycody31.dev/squad-aegis/internal/plugin_manager"
 	"go.codycody31.dev/squad-aegis/internal/plugin_registry"
 	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
+	"go.codycody31.dev/squad-aegis/internal/rotation_manager"
 	"go.codycody31.dev/squad-aegis/internal/server"
 	"go.codycody31.dev/squad-aegis/internal/shared/config"
 	"go.codycody31.dev/squad-aegis/internal/shared/logger"
@@ -129,8 +132,38 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
+	// Initialize Valkey client
+	valkeyConfig := valkey.Config{
+		Host:     config.Config.Valkey.Host,
+		Port:     config.Config.Valkey.Port,
+		Password: config.Config.Valkey.Password,
+		Database: config.Config.Valkey.Database,
+	}
+
+	log.Info().Msg("Connecting to Valkey...")
+	valkeyClient, err := valkey.NewClient(valkeyConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create Valkey client")
+	}
+	defer valkeyClient.Close()
+
+	// Ping Valkey
+	err = valkeyClient.Ping(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to ping Valkey - will fallback to in-memory storage")
+	} else {
+		log.Info().Msg("Successfully connected to Valkey")
+	}
+
 	// Create event manager for centralized event handling
-	eventManager := event_manager.NewEventManager(ctx, 10000)
+	var eventManager *event_manager.EventManager
+	eventJournal := newEventJournal(ctx, valkeyClient, err == nil)
+	if eventJournal != nil {
+		defer eventJournal.Close()
+		eventManager = event_manager.NewDurableEventManager(ctx, config.Config.Events.BufferSize, eventJournal)
+	} else {
+		eventManager = event_manager.NewEventManager(ctx, config.Config.Events.BufferSize)
+	}
 	defer eventManager.Shutdown()
 
 	// Initialize ClickHouse
@@ -174,29 +207,6 @@ func run(ctx context.Context) error {
 	eventIngester.Start()
 	defer eventIngester.Stop()
 
-	// Initialize Valkey client
-	valkeyConfig := valkey.Config{
-		Host:     config.Config.Valkey.Host,
-		Port:     config.Config.Valkey.Port,
-		Password: config.Config.Valkey.Password,
-		Database: config.Config.Valkey.Database,
-	}
-
-	log.Info().Msg("Connecting to Valkey...")
-	valkeyClient, err := valkey.NewClient(valkeyConfig)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create Valkey client")
-	}
-	defer valkeyClient.Close()
-
-	// Ping Valkey
-	err = valkeyClient.Ping(ctx)
-	if err != nil {
-		log.Warn().Err(err).Msg("Failed to ping Valkey - will fallback to in-memory storage")
-	} else {
-		log.Info().Msg("Successfully connected to Valkey")
-	}
-
 	// Create RCON manager
 	rconManager := rcon_manager.NewRconManager(ctx, eventManager)
 	defer rconManager.Shutdown()
@@ -213,6 +223,12 @@ func run(ctx context.Context) error {
 	pluginManager := plugin_manager.NewPluginManager(ctx, database, eventManager, rconManager, clickhouseClient)
 	defer pluginManager.Stop()
 
+	// Create rotation manager (records layer history, sets the next layer from each server's rotation)
+	rotationManager := rotation_manager.NewRotationManager(ctx, database, clickhouseClient, rconManager, eventManager)
+	rotationManager.Start()
+	defer rotationManager.Stop()
+	pluginManager.SetRotationProvider(rotationManager)
+
 	// Register all available plugins and connectors
 	if err := plugin_registry.RegisterAllConnectors(pluginManager); err != nil {
 		return fmt.Errorf("failed to register connectors: %w", err)
@@ -228,7 +244,8 @@ func run(ctx context.Context) error {
 	}
 
 	// Create workflow manager
-	workflowManager := workflow_manager.NewWorkflowManager(ctx, database, eventManager, rconManager, clickhouseClient)
+	workflowManager := workflow_manager.NewWorkflowManager(ctx, database, eventManager, rconManager, playerTrackerManager, clickhouseClient)
+	workflowManager.SetServerExecutionLimit(config.Config.Workflows.MaxConcurrentPerServer)
 	defer workflowManager.Stop()
 
 	// Start workflow manager
@@ -237,10 +254,15 @@ func run(ctx context.Context) error {
 	}
 
 	// Create and start ban enforcer (watches player connections, kicks banned players in aegis mode)
-	banEnforcer := ban_enforcer.NewBanEnforcer(ctx, database, eventManager, rconManager)
+	banEnforcer := ban_enforcer.NewBanEnforcer(ctx, database, clickhouseClient, eventManager, rconManager)
 	banEnforcer.Start()
 	defer banEnforcer.Stop()
 
+	// Create and start admin camera tracker (pairs admin camera events into sessions, raises camera alerts)
+	adminCameraTracker := admin_camera_tracker.NewAdminCameraTracker(ctx, database, clickhouseClient, rconManager, eventManager)
+	adminCameraTracker.Start()
+	defer adminCameraTracker.Stop()
+
 	// Initialize storage
 	log.Info().Str("type", config.Config.Storage.Type).Msg("Initializing storage...")
 	storageBackend, err := storage.NewStorage(*config.Config)
@@ -249,6 +271,11 @@ func run(ctx context.Context) error {
 	}
 	log.Info().Str("type", config.Config.Storage.Type).Msg("Storage initialized successfully")
 
+	// Create log importer for historical log files
+	logImporter := log_importer.NewImporter(ctx, database, eventIngester, storageBackend)
+	logImporter.Start()
+	defer logImporter.Stop()
+
 	// Start connection managers
 	go rconManager.StartConnectionManager()
 	go logwatcherManager.StartConnectionManager()
@@ -303,22 +330,27 @@ func run(ctx context.Context) error {
 		permissionRepo := permissions.NewRepository(database)
 
 		deps := &server.Dependencies{
-			DB:                   database,
-			Clickhouse:           clickhouseClient,
-			Valkey:               valkeyClient,
-			RconManager:          rconManager,
-			EventManager:         eventManager,
-			LogwatcherManager:    logwatcherManager,
-			PluginManager:        pluginManager,
-			WorkflowManager:      workflowManager,
-			RemoteBanSyncService: core.NewRemoteBanSyncService(database, database),
-			Storage:              storageBackend,
-			PermissionService:    permissionService,
-			PermissionRepo:       permissionRepo,
+			DB:                    database,
+			Clickhouse:            clickhouseClient,
+			Valkey:                valkeyClient,
+			RconManager:           rconManager,
+			EventManager:          eventManager,
+			LogwatcherManager:     logwatcherManager,
+			PluginManager:         pluginManager,
+			WorkflowManager:       workflowManager,
+			RemoteBanSyncService:  core.NewRemoteBanSyncService(database, database),
+			FederationSyncService: core.NewFederationSyncService(database, database),
+			Storage:               storageBackend,
+			LogImporter:           logImporter,
+			RotationManager:       rotationManager,
+			AdminCameraTracker:    adminCameraTracker,
+			PermissionService:     permissionService,
+			PermissionRepo:        permissionRepo,
 		}
 
 		// Start remote ban sync service
-		go deps.RemoteBanSyncService.StartPeriodicSync(ctx) // Initialize router
+		go deps.RemoteBanSyncService.StartPeriodicSync(ctx)
+		go deps.FederationSyncService.StartPeriodicSync(ctx) // Initialize router
 		router := server.NewRouter(deps)
 
 		// Create server with timeout
@@ -396,3 +428,34 @@ func setup(database db.Executor) error {
 
 	return nil
 }
+
+// newEventJournal creates the event journal selected by EVENTS_JOURNAL_MODE.
+// It returns nil for in-memory delivery, which is also the fallback if the
+// journal can't be opened.
+func newEventJournal(ctx context.Context, valkeyClient *valkey.Client, valkeyAvailable bool) event_manager.EventJournal {
+	switch config.Config.Events.Journal.Mode {
+	case "", "memory":
+		return nil
+	case "file":
+		journal, err := event_manager.NewFileJournal(config.Config.Events.Journal.Path, config.Config.Events.Journal.MaxEntries)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to open file event journal - falling back to in-memory events")
+			return nil
+		}
+		return journal
+	case "valkey":
+		if !valkeyAvailable {
+			log.Warn().Msg("Valkey is unavailable - falling back to in-memory events")
+			return nil
+		}
+		journal, err := event_manager.NewValkeyJournal(ctx, valkeyClient, config.Config.Events.Journal.MaxEntries)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to open Valkey event journal - falling back to in-memory events")
+			return nil
+		}
+		return journal
+	default:
+		log.Warn().Str("mode", config.Config.Events.Journal.Mode).Msg("Unknown event journal mode - falling back to in-memory events")
+		return nil
+	}
+}
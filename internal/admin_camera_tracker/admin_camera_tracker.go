@@ -0,0 +1,421 @@
+package admin_camera_tracker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/clickhouse"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
+	squadRcon "go.codycody31.dev/squad-aegis/internal/squad-rcon"
+)
+
+const (
+	// MaxKillWindow bounds the kill window of the server settings, and with it
+	// how long kills are kept in memory
+	MaxKillWindow = 60 * time.Minute
+	// logDelayGrace is added to the kill window after a session so kills that
+	// reach us late through the log are still counted
+	logDelayGrace = 30 * time.Second
+	// killRetention is how long kills are kept to count them after a session
+	killRetention = MaxKillWindow + logDelayGrace
+	// startRetention is how long session starts are kept for the frequent
+	// session alert
+	startRetention = 24 * time.Hour
+	// pruneInterval is how often the kills and session starts of players that
+	// went quiet are dropped
+	pruneInterval = 5 * time.Minute
+)
+
+// Session end reasons
+const (
+	EndReasonUnpossessed  = "unpossessed"
+	EndReasonDisconnected = "disconnected"
+	EndReasonShutdown     = "shutdown"
+)
+
+type openSession struct {
+	session   models.AdminCameraSession
+	settings  *models.AdminCameraSettings
+	longTimer *time.Timer
+}
+
+type serverState struct {
+	open   map[string]*openSession // admin key -> session in progress
+	kills  map[string][]time.Time  // player EOS or Steam ID -> recent kills
+	starts map[string][]time.Time  // admin key -> recent session starts
+}
+
+// AdminCameraTracker pairs the admin camera events of every server into
+// sessions, correlates them with the admin's kills around the session and
+// publishes alerts when the server's thresholds are crossed
+type AdminCameraTracker struct {
+	db               *sql.DB
+	clickhouseClient *clickhouse.Client
+	rconManager      *rcon_manager.RconManager
+	eventManager     *event_manager.EventManager
+	subscriber       *event_manager.EventSubscriber
+	ctx              context.Context
+	cancel           context.CancelFunc
+	wg               sync.WaitGroup
+
+	mu      sync.Mutex
+	servers map[uuid.UUID]*serverState
+}
+
+// NewAdminCameraTracker creates a new AdminCameraTracker instance
+func NewAdminCameraTracker(ctx context.Context, db *sql.DB, clickhouseClient *clickhouse.Client, rconManager *rcon_manager.RconManager, eventManager *event_manager.EventManager) *AdminCameraTracker {
+	ctx, cancel := context.WithCancel(ctx)
+	return &AdminCameraTracker{
+		db:               db,
+		clickhouseClient: clickhouseClient,
+		rconManager:      rconManager,
+		eventManager:     eventManager,
+		ctx:              ctx,
+		cancel:           cancel,
+		servers:          make(map[uuid.UUID]*serverState),
+	}
+}
+
+// Start subscribes to admin camera, kill and disconnect events and begins processing
+func (t *AdminCameraTracker) Start() {
+	log.Info().Msg("Starting admin camera tracker")
+
+	filter := event_manager.EventFilter{
+		Types: []event_manager.EventType{
+			event_manager.EventTypeRconPossessedAdminCamera,
+			event_manager.EventTypeRconUnpossessedAdminCamera,
+			event_manager.EventTypeLogPlayerWounded,
+			event_manager.EventTypeLogPlayerDisconnected,
+		},
+	}
+	t.subscriber = t.eventManager.Subscribe(filter, nil, 500)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.processLoop()
+	}()
+}
+
+// Stop unsubscribes from events, records the sessions still in progress and
+// waits for processing to finish
+func (t *AdminCameraTracker) Stop() {
+	log.Info().Msg("Stopping admin camera tracker")
+
+	if t.subscriber != nil {
+		t.eventManager.Unsubscribe(t.subscriber.ID)
+	}
+
+	t.cancel()
+	t.wg.Wait()
+
+	t.mu.Lock()
+	var remaining []*openSession
+	for _, state := range t.servers {
+		for key, open := range state.open {
+			if open.longTimer != nil {
+				open.longTimer.Stop()
+			}
+			open.session.EndedAt = time.Now().UTC()
+			open.session.EndReason = EndReasonShutdown
+			remaining = append(remaining, open)
+			delete(state.open, key)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, open := range remaining {
+		t.finishSession(open)
+	}
+}
+
+func (t *AdminCameraTracker) processLoop() {
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	eventChan := t.subscriber.Channel
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-pruneTicker.C:
+			t.prune(time.Now().UTC())
+		case event, ok := <-eventChan:
+			if !ok {
+				return
+			}
+
+			switch data := event.Data.(type) {
+			case *event_manager.RconAdminCameraData:
+				if data.Action == "possessed" {
+					t.handlePossessed(event.ServerID, data)
+				} else {
+					t.endSession(event.ServerID, adminKey(data.EosID, data.SteamID), EndReasonUnpossessed)
+				}
+			case *event_manager.LogPlayerWoundedData:
+				t.recordKill(event.ServerID, data)
+			case *event_manager.LogPlayerDisconnectedData:
+				t.endSession(event.ServerID, adminKey(data.EOSID, data.SteamID), EndReasonDisconnected)
+			}
+		}
+	}
+}
+
+// state returns the tracking state of a server. Callers must hold t.mu.
+func (t *AdminCameraTracker) state(serverID uuid.UUID) *serverState {
+	state, ok := t.servers[serverID]
+	if !ok {
+		state = &serverState{
+			open:   make(map[string]*openSession),
+			kills:  make(map[string][]time.Time),
+			starts: make(map[string][]time.Time),
+		}
+		t.servers[serverID] = state
+	}
+	return state
+}
+
+// prune drops the kills and session starts that are too old to be counted,
+// including those of players that haven't been seen since
+func (t *AdminCameraTracker) prune(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, state := range t.servers {
+		pruneKeys(state.kills, now.Add(-killRetention))
+		pruneKeys(state.starts, now.Add(-startRetention))
+	}
+}
+
+func (t *AdminCameraTracker) recordKill(serverID uuid.UUID, data *event_manager.LogPlayerWoundedData) {
+	if data.Teamkill {
+		return
+	}
+
+	now := time.Now().UTC()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.state(serverID)
+	for _, id := range []string{data.AttackerEOS, data.AttackerSteam} {
+		if id == "" || id == data.VictimEOS || id == data.VictimSteam {
+			continue
+		}
+		state.kills[id] = append(pruneBefore(state.kills[id], now.Add(-killRetention)), now)
+	}
+}
+
+func (t *AdminCameraTracker) handlePossessed(serverID uuid.UUID, data *event_manager.RconAdminCameraData) {
+	key := adminKey(data.EosID, data.SteamID)
+	if key == "" {
+		return
+	}
+
+	settings, err := core.GetAdminCameraSettings(t.ctx, t.db, serverID)
+	if err != nil {
+		log.Error().Err(err).Str("serverId", serverID.String()).Msg("Failed to load admin camera settings")
+		return
+	}
+
+	now := time.Now().UTC()
+	open := &openSession{
+		session: models.AdminCameraSession{
+			ID:                uuid.New(),
+			ServerID:          serverID,
+			AdminName:         data.AdminName,
+			AdminEOS:          data.EosID,
+			AdminSteam:        data.SteamID,
+			StartedAt:         now,
+			KillWindowSeconds: settings.KillWindowMinutes * 60,
+			Alerts:            []string{},
+		},
+		settings: settings,
+	}
+
+	t.mu.Lock()
+	state := t.state(serverID)
+	if _, ok := state.open[key]; ok {
+		t.mu.Unlock()
+		return // Already in the camera, e.g. a repeated event after an RCON reconnect
+	}
+	window := time.Duration(settings.KillWindowMinutes) * time.Minute
+	open.session.KillsBefore = countKills(state.kills[key], now.Add(-window), now)
+
+	state.starts[key] = append(pruneBefore(state.starts[key], now.Add(-startRetention)), now)
+	frequent := reachedFrequent(state.starts[key], now, settings)
+	sessionCount := len(pruneBefore(state.starts[key], now.Add(-time.Duration(settings.FrequentSessionWindowMinutes)*time.Minute)))
+
+	if after := longSessionAfter(settings); after > 0 {
+		open.longTimer = time.AfterFunc(after, func() { t.alertLongSession(serverID, key, open) })
+	}
+	state.open[key] = open
+	t.mu.Unlock()
+
+	// Looking up the team takes an RCON round trip, which must not hold up
+	// the event loop
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.resolveTeam(serverID, data, open)
+
+		if frequent {
+			t.mu.Lock()
+			open.session.Alerts = append(open.session.Alerts, models.AdminCameraAlertFrequentSessions)
+			session := open.session
+			t.mu.Unlock()
+
+			t.publishAlert(&session, models.AdminCameraAlertFrequentSessions, sessionCount,
+				fmt.Sprintf("%s entered the admin camera %d times in %d minutes", session.AdminName, sessionCount, settings.FrequentSessionWindowMinutes))
+		}
+	}()
+}
+
+// resolveTeam sets the team and squad of a session from the player list. The
+// admin's team tells whether they were playing while using the camera.
+func (t *AdminCameraTracker) resolveTeam(serverID uuid.UUID, data *event_manager.RconAdminCameraData, open *openSession) {
+	players, err := squadRcon.NewSquadRcon(t.rconManager, serverID).GetServerPlayers()
+	if err != nil {
+		return
+	}
+
+	for _, player := range players.OnlinePlayers {
+		if (data.EosID != "" && player.EosId == data.EosID) || (data.SteamID != "" && player.SteamId == data.SteamID) {
+			t.mu.Lock()
+			open.session.TeamID = player.TeamId
+			open.session.SquadID = player.SquadId
+			t.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (t *AdminCameraTracker) alertLongSession(serverID uuid.UUID, key string, open *openSession) {
+	t.mu.Lock()
+	if t.servers[serverID] == nil || t.servers[serverID].open[key] != open {
+		t.mu.Unlock()
+		return // Ended meanwhile
+	}
+	open.session.Alerts = append(open.session.Alerts, models.AdminCameraAlertLongSession)
+	session := open.session
+	t.mu.Unlock()
+
+	session.DurationSeconds = time.Since(session.StartedAt).Seconds()
+	t.publishAlert(&session, models.AdminCameraAlertLongSession, 0,
+		fmt.Sprintf("%s has been in the admin camera for %d minutes", session.AdminName, int(session.DurationSeconds/60)))
+}
+
+// endSession closes the session of an admin and records it once the kill
+// window after the session has passed
+func (t *AdminCameraTracker) endSession(serverID uuid.UUID, key, reason string) {
+	if key == "" {
+		return
+	}
+
+	t.mu.Lock()
+	state := t.state(serverID)
+	open, ok := state.open[key]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	delete(state.open, key)
+	if open.longTimer != nil {
+		open.longTimer.Stop()
+	}
+	open.session.EndedAt = time.Now().UTC()
+	open.session.EndReason = reason
+	t.mu.Unlock()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		window := time.Duration(open.settings.KillWindowMinutes) * time.Minute
+		select {
+		case <-t.ctx.Done():
+		case <-time.After(window + logDelayGrace):
+		}
+
+		t.finishSession(open)
+	}()
+}
+
+// finishSession counts the kills after a session, raises the ghosting alert
+// and stores the session
+func (t *AdminCameraTracker) finishSession(open *openSession) {
+	session := &open.session
+	session.DurationSeconds = session.EndedAt.Sub(session.StartedAt).Seconds()
+
+	window := time.Duration(open.settings.KillWindowMinutes) * time.Minute
+	t.mu.Lock()
+	session.KillsAfter = countKills(t.state(session.ServerID).kills[adminKey(session.AdminEOS, session.AdminSteam)],
+		session.EndedAt, session.EndedAt.Add(window+logDelayGrace))
+	t.mu.Unlock()
+
+	session.GhostingSuspected = isGhosting(session, open.settings.GhostingKillThreshold)
+	if session.GhostingSuspected && open.settings.AlertsEnabled {
+		session.Alerts = append(session.Alerts, models.AdminCameraAlertGhostingSuspected)
+		t.publishAlert(session, models.AdminCameraAlertGhostingSuspected, 0,
+			fmt.Sprintf("%s got %d kills within %d minutes of leaving the admin camera while on team %d",
+				session.AdminName, session.KillsAfter, open.settings.KillWindowMinutes, session.TeamID))
+	}
+
+	// The tracker context is cancelled on shutdown, which must not lose the sessions still being recorded
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := t.recordSession(ctx, session); err != nil {
+		log.Error().Err(err).Str("serverId", session.ServerID.String()).Str("admin", session.AdminName).Msg("Failed to record admin camera session")
+	}
+}
+
+func (t *AdminCameraTracker) publishAlert(session *models.AdminCameraSession, alert string, sessionCount int, message string) {
+	log.Warn().
+		Str("serverId", session.ServerID.String()).
+		Str("admin", session.AdminName).
+		Str("alert", alert).
+		Msg(message)
+
+	t.eventManager.PublishEvent(session.ServerID, &event_manager.AdminCameraAlertData{
+		Alert:           alert,
+		SessionID:       session.ID.String(),
+		AdminName:       session.AdminName,
+		EosID:           session.AdminEOS,
+		SteamID:         session.AdminSteam,
+		TeamID:          session.TeamID,
+		StartedAt:       session.StartedAt,
+		DurationSeconds: session.DurationSeconds,
+		SessionCount:    sessionCount,
+		KillsBefore:     session.KillsBefore,
+		KillsAfter:      session.KillsAfter,
+		Message:         message,
+	}, message)
+}
+
+func (t *AdminCameraTracker) recordSession(ctx context.Context, session *models.AdminCameraSession) error {
+	if t.clickhouseClient == nil {
+		return nil
+	}
+
+	ghosting := uint8(0)
+	if session.GhostingSuspected {
+		ghosting = 1
+	}
+
+	return t.clickhouseClient.Exec(ctx, `
+		INSERT INTO squad_aegis.server_admin_camera_sessions (
+			id, server_id, admin_name, admin_eos, admin_steam, team_id, squad_id, started_at, ended_at,
+			duration_seconds, end_reason, kill_window_seconds, kills_before, kills_after, ghosting_suspected, alerts
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, session.ID, session.ServerID, session.AdminName, session.AdminEOS, session.AdminSteam,
+		uint8(session.TeamID), uint16(session.SquadID), session.StartedAt, session.EndedAt, session.DurationSeconds,
+		session.EndReason, uint32(session.KillWindowSeconds), uint16(session.KillsBefore), uint16(session.KillsAfter),
+		ghosting, session.Alerts)
+}
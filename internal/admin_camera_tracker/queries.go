@@ -0,0 +1,121 @@
+package admin_camera_tracker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// GetSessions returns the most recent admin camera sessions of a server since
+// the given time, newest first. adminID limits the result to one admin by EOS
+// or Steam ID.
+func (t *AdminCameraTracker) GetSessions(ctx context.Context, serverID uuid.UUID, adminID string, since time.Time, limit int) ([]models.AdminCameraSession, error) {
+	sessions := []models.AdminCameraSession{}
+	if t.clickhouseClient == nil || limit <= 0 {
+		return sessions, nil
+	}
+
+	query := `
+		SELECT id, admin_name, admin_eos, admin_steam, team_id, squad_id, started_at, ended_at, duration_seconds,
+			end_reason, kill_window_seconds, kills_before, kills_after, ghosting_suspected, alerts
+		FROM squad_aegis.server_admin_camera_sessions
+		WHERE server_id = ? AND started_at >= ?`
+	args := []interface{}{serverID, since}
+	if adminID != "" {
+		query += ` AND (admin_eos = ? OR admin_steam = ?)`
+		args = append(args, adminID, adminID)
+	}
+	query += ` ORDER BY started_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := t.clickhouseClient.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query admin camera sessions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		session := models.AdminCameraSession{ServerID: serverID}
+		var teamID, ghosting uint8
+		var squadID, killsBefore, killsAfter uint16
+		var killWindow uint32
+		if err := rows.Scan(&session.ID, &session.AdminName, &session.AdminEOS, &session.AdminSteam, &teamID, &squadID,
+			&session.StartedAt, &session.EndedAt, &session.DurationSeconds, &session.EndReason, &killWindow,
+			&killsBefore, &killsAfter, &ghosting, &session.Alerts); err != nil {
+			return nil, fmt.Errorf("failed to scan admin camera session: %w", err)
+		}
+		session.TeamID = int(teamID)
+		session.SquadID = int(squadID)
+		session.KillWindowSeconds = int(killWindow)
+		session.KillsBefore = int(killsBefore)
+		session.KillsAfter = int(killsAfter)
+		session.GhostingSuspected = ghosting == 1
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// GetAdminSummaries aggregates the admin camera sessions of a server since the
+// given time per admin, most used first. adminID limits the result to one
+// admin by EOS or Steam ID.
+func (t *AdminCameraTracker) GetAdminSummaries(ctx context.Context, serverID uuid.UUID, adminID string, since time.Time) ([]models.AdminCameraAdminSummary, error) {
+	summaries := []models.AdminCameraAdminSummary{}
+	if t.clickhouseClient == nil {
+		return summaries, nil
+	}
+
+	conditions := []string{"server_id = ?", "started_at >= ?"}
+	args := []interface{}{serverID, since}
+	if adminID != "" {
+		conditions = append(conditions, "(admin_eos = ? OR admin_steam = ?)")
+		args = append(args, adminID, adminID)
+	}
+
+	rows, err := t.clickhouseClient.Query(ctx, `
+		SELECT
+			argMax(admin_name, started_at),
+			admin_eos,
+			admin_steam,
+			count(),
+			sum(duration_seconds),
+			avg(duration_seconds),
+			max(duration_seconds),
+			countIf(team_id > 0),
+			sum(kills_after),
+			countIf(ghosting_suspected = 1),
+			countIf(notEmpty(alerts)),
+			max(started_at)
+		FROM squad_aegis.server_admin_camera_sessions
+		WHERE `+strings.Join(conditions, " AND ")+`
+		GROUP BY admin_eos, admin_steam
+		ORDER BY sum(duration_seconds) DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query admin camera summaries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var summary models.AdminCameraAdminSummary
+		var sessions, onTeam, ghosting, alerted uint64
+		var killsAfter uint64
+		if err := rows.Scan(&summary.AdminName, &summary.AdminEOS, &summary.AdminSteam, &sessions, &summary.TotalSeconds,
+			&summary.AverageSeconds, &summary.LongestSeconds, &onTeam, &killsAfter, &ghosting, &alerted,
+			&summary.LastSessionStartedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan admin camera summary: %w", err)
+		}
+		summary.Sessions = int(sessions)
+		summary.SessionsOnTeam = int(onTeam)
+		summary.KillsAfter = int(killsAfter)
+		summary.GhostingSuspected = int(ghosting)
+		summary.AlertedSessions = int(alerted)
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
@@ -0,0 +1,76 @@
+package admin_camera_tracker
+
+import (
+	"time"
+
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// adminKey identifies an admin across the camera, kill and disconnect events,
+// preferring the EOS ID that every event carries
+func adminKey(eosID, steamID string) string {
+	if eosID != "" {
+		return eosID
+	}
+	return steamID
+}
+
+// countKills counts the kill times after from and up to and including to
+func countKills(kills []time.Time, from, to time.Time) int {
+	count := 0
+	for _, t := range kills {
+		if t.After(from) && !t.After(to) {
+			count++
+		}
+	}
+	return count
+}
+
+// pruneBefore drops the times before cutoff. times must be in ascending order.
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// pruneKeys drops the times before cutoff from every key and removes the keys
+// left without any
+func pruneKeys(times map[string][]time.Time, cutoff time.Time) {
+	for key, list := range times {
+		if list = pruneBefore(list, cutoff); len(list) == 0 {
+			delete(times, key)
+		} else {
+			times[key] = list
+		}
+	}
+}
+
+// isGhosting reports whether a session looks like the admin used the camera
+// to gain an advantage: they were playing on a team and got at least
+// threshold kills right after leaving the camera
+func isGhosting(session *models.AdminCameraSession, threshold int) bool {
+	return threshold > 0 && session.TeamID > 0 && session.KillsAfter >= threshold
+}
+
+// longSessionAfter returns how long a session may last before it is reported
+// as long, or zero when the alert is disabled
+func longSessionAfter(settings *models.AdminCameraSettings) time.Duration {
+	if !settings.AlertsEnabled || settings.LongSessionMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(settings.LongSessionMinutes) * time.Minute
+}
+
+// reachedFrequent reports whether starts, the recent session starts of one
+// admin, just reached the frequent session threshold. It only fires once per
+// crossing so an admin flicking in and out doesn't raise an alert per session.
+func reachedFrequent(starts []time.Time, now time.Time, settings *models.AdminCameraSettings) bool {
+	if !settings.AlertsEnabled || settings.FrequentSessionCount <= 0 || settings.FrequentSessionWindowMinutes <= 0 {
+		return false
+	}
+
+	window := time.Duration(settings.FrequentSessionWindowMinutes) * time.Minute
+	return len(pruneBefore(starts, now.Add(-window))) == settings.FrequentSessionCount
+}
@@ -0,0 +1,125 @@
+package admin_camera_tracker
+
+import (
+	"testing"
+	"time"
+
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+var base = time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+func minutes(offsets ...int) []time.Time {
+	times := make([]time.Time, len(offsets))
+	for i, offset := range offsets {
+		times[i] = base.Add(time.Duration(offset) * time.Minute)
+	}
+	return times
+}
+
+func TestAdminKey(t *testing.T) {
+	if got := adminKey("eos", "steam"); got != "eos" {
+		t.Errorf("expected the EOS ID to be preferred, got %q", got)
+	}
+	if got := adminKey("", "steam"); got != "steam" {
+		t.Errorf("expected the Steam ID as fallback, got %q", got)
+	}
+}
+
+func TestCountKills(t *testing.T) {
+	kills := minutes(-10, -4, -1, 0, 2, 5, 6)
+
+	if got := countKills(kills, base.Add(-5*time.Minute), base); got != 3 {
+		t.Errorf("expected 3 kills before, got %d", got)
+	}
+	if got := countKills(kills, base, base.Add(5*time.Minute)); got != 2 {
+		t.Errorf("expected 2 kills after, got %d", got)
+	}
+}
+
+func TestPruneBefore(t *testing.T) {
+	got := pruneBefore(minutes(-30, -20, -5, 0), base.Add(-10*time.Minute))
+	if len(got) != 2 || !got[0].Equal(base.Add(-5*time.Minute)) {
+		t.Fatalf("unexpected result %v", got)
+	}
+
+	if got := pruneBefore(minutes(-30), base); len(got) != 0 {
+		t.Fatalf("expected every time to be pruned, got %v", got)
+	}
+}
+
+func TestPruneKeys(t *testing.T) {
+	times := map[string][]time.Time{
+		"recent": minutes(-30, -5),
+		"stale":  minutes(-30, -20),
+	}
+	pruneKeys(times, base.Add(-10*time.Minute))
+
+	if len(times["recent"]) != 1 {
+		t.Errorf("expected one recent time to be kept, got %v", times["recent"])
+	}
+	if _, ok := times["stale"]; ok {
+		t.Errorf("expected the key without recent times to be removed")
+	}
+}
+
+func TestIsGhosting(t *testing.T) {
+	tests := []struct {
+		name      string
+		teamID    int
+		kills     int
+		threshold int
+		expected  bool
+	}{
+		{"on a team with kills", 1, 3, 2, true},
+		{"at the threshold", 2, 2, 2, true},
+		{"below the threshold", 1, 1, 2, false},
+		{"not on a team", 0, 5, 2, false},
+		{"disabled", 1, 5, 0, false},
+	}
+
+	for _, test := range tests {
+		session := &models.AdminCameraSession{TeamID: test.teamID, KillsAfter: test.kills}
+		if got := isGhosting(session, test.threshold); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestReachedFrequent(t *testing.T) {
+	settings := &models.AdminCameraSettings{
+		AlertsEnabled:                true,
+		FrequentSessionCount:         3,
+		FrequentSessionWindowMinutes: 10,
+	}
+
+	if reachedFrequent(minutes(-5, 0), base, settings) {
+		t.Error("expected no alert below the threshold")
+	}
+	if !reachedFrequent(minutes(-8, -5, 0), base, settings) {
+		t.Error("expected an alert when the threshold is reached")
+	}
+	if reachedFrequent(minutes(-9, -8, -5, 0), base, settings) {
+		t.Error("expected a single alert per crossing")
+	}
+	if reachedFrequent(minutes(-30, -5, 0), base, settings) {
+		t.Error("expected sessions outside the window to be ignored")
+	}
+
+	settings.AlertsEnabled = false
+	if reachedFrequent(minutes(-8, -5, 0), base, settings) {
+		t.Error("expected no alert when alerts are disabled")
+	}
+}
+
+func TestLongSessionAfter(t *testing.T) {
+	settings := &models.AdminCameraSettings{AlertsEnabled: true, LongSessionMinutes: 10}
+	if got := longSessionAfter(settings); got != 10*time.Minute {
+		t.Errorf("expected 10 minutes, got %s", got)
+	}
+
+	settings.LongSessionMinutes = 0
+	if got := longSessionAfter(settings); got != 0 {
+		t.Errorf("expected the alert to be disabled, got %s", got)
+	}
+}
@@ -6,16 +6,22 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/clickhouse"
 	"go.codycody31.dev/squad-aegis/internal/core"
 	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/identity"
 	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
 )
 
 // BanEnforcer watches for player connections and kicks banned players
 // when a server is configured with ban_enforcement_mode = "aegis".
+// Players linked to a banned identity are kicked or flagged according to
+// the server's linked ban settings.
 type BanEnforcer struct {
 	db           *sql.DB
+	resolver     *identity.Resolver
 	eventManager *event_manager.EventManager
 	rconManager  *rcon_manager.RconManager
 	subscriber   *event_manager.EventSubscriber
@@ -24,11 +30,17 @@ type BanEnforcer struct {
 	wg           sync.WaitGroup
 }
 
-// NewBanEnforcer creates a new BanEnforcer instance.
-func NewBanEnforcer(ctx context.Context, db *sql.DB, eventManager *event_manager.EventManager, rconManager *rcon_manager.RconManager) *BanEnforcer {
+// NewBanEnforcer creates a new BanEnforcer instance. Without ClickHouse,
+// bans are only enforced on the banned identifiers themselves.
+func NewBanEnforcer(ctx context.Context, db *sql.DB, ch *clickhouse.Client, eventManager *event_manager.EventManager, rconManager *rcon_manager.RconManager) *BanEnforcer {
 	ctx, cancel := context.WithCancel(ctx)
+	var resolver *identity.Resolver
+	if ch != nil {
+		resolver = identity.NewResolver(ch)
+	}
 	return &BanEnforcer{
 		db:           db,
+		resolver:     resolver,
 		eventManager: eventManager,
 		rconManager:  rconManager,
 		ctx:          ctx,
@@ -85,8 +97,8 @@ func (b *BanEnforcer) handlePlayerConnected(event event_manager.Event) {
 		return
 	}
 
-	steamID := data.SteamID
-	if steamID == "" {
+	steamID, eosID := data.SteamID, data.EOSID
+	if steamID == "" && eosID == "" {
 		return
 	}
 
@@ -103,13 +115,14 @@ func (b *BanEnforcer) handlePlayerConnected(event event_manager.Event) {
 	}
 
 	// Check for an active ban on this server (including subscribed ban lists)
-	ban, err := core.GetActiveBanForServer(b.ctx, b.db, serverID, steamID)
+	ban, err := core.GetActiveBanForServer(b.ctx, b.db, serverID, steamID, eosID)
 	if err != nil {
 		// sql.ErrNoRows means no active ban - this is the normal case
 		if err == sql.ErrNoRows {
+			b.checkLinkedIdentities(serverID, data)
 			return
 		}
-		log.Error().Err(err).Str("steamId", steamID).Str("serverId", serverID.String()).Msg("Failed to check active ban")
+		log.Error().Err(err).Str("steamId", steamID).Str("eosId", eosID).Str("serverId", serverID.String()).Msg("Failed to check active ban")
 		return
 	}
 
@@ -119,11 +132,10 @@ func (b *BanEnforcer) handlePlayerConnected(event event_manager.Event) {
 		reason = "You are banned from this server"
 	}
 
-	kickCmd := fmt.Sprintf("AdminKick %s %s", steamID, reason)
-	_, err = b.rconManager.ExecuteCommand(serverID, kickCmd)
-	if err != nil {
+	if err := b.kick(serverID, playerIdentifier(steamID, eosID), reason); err != nil {
 		log.Error().Err(err).
 			Str("steamId", steamID).
+			Str("eosId", eosID).
 			Str("serverId", serverID.String()).
 			Str("banId", ban.ID).
 			Msg("Failed to kick banned player")
@@ -132,9 +144,23 @@ func (b *BanEnforcer) handlePlayerConnected(event event_manager.Event) {
 
 	log.Info().
 		Str("steamId", steamID).
+		Str("eosId", eosID).
 		Str("serverId", serverID.String()).
 		Str("banId", ban.ID).
 		Bool("permanent", ban.Permanent).
 		Str("reason", reason).
 		Msg("Kicked banned player on connection (aegis enforcement)")
 }
+
+func (b *BanEnforcer) kick(serverID uuid.UUID, identifier, reason string) error {
+	_, err := b.rconManager.ExecuteHighPriorityCommand(serverID, fmt.Sprintf("AdminKick %s %s", identifier, reason))
+	return err
+}
+
+// playerIdentifier picks the ID to address a player by in RCON commands
+func playerIdentifier(steamID, eosID string) string {
+	if steamID != "" {
+		return steamID
+	}
+	return eosID
+}
@@ -0,0 +1,164 @@
+package ban_enforcer
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/identity"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// linkedBanMatch is a ban reached from the connecting player through the identity graph
+type linkedBanMatch struct {
+	ban   models.ServerBan
+	match *identity.LinkMatch
+}
+
+// checkLinkedIdentities looks for active bans on identities linked to a
+// connecting player who isn't banned themselves, and kicks or flags the
+// player when the link is strong enough
+func (b *BanEnforcer) checkLinkedIdentities(serverID uuid.UUID, data *event_manager.LogPlayerConnectedData) {
+	if b.resolver == nil {
+		return
+	}
+
+	settings, err := core.GetLinkedBanSettings(b.ctx, b.db, serverID)
+	if err != nil {
+		log.Error().Err(err).Str("serverId", serverID.String()).Msg("Failed to get linked ban settings")
+		return
+	}
+
+	steamIDs, eosIDs, err := b.resolver.LinkedIdentifiers(b.ctx, data.SteamID, data.EOSID)
+	if err != nil {
+		log.Warn().Err(err).Str("steamId", data.SteamID).Str("eosId", data.EOSID).Msg("Failed to resolve linked identities")
+		return
+	}
+	steamIDs = slices.DeleteFunc(steamIDs, func(id string) bool { return id == data.SteamID })
+	eosIDs = slices.DeleteFunc(eosIDs, func(id string) bool { return id == data.EOSID })
+	if len(steamIDs) == 0 && len(eosIDs) == 0 {
+		return
+	}
+
+	bans, err := core.GetActiveBansForIdentifiers(b.ctx, b.db, serverID, steamIDs, eosIDs)
+	if err != nil {
+		log.Error().Err(err).Str("serverId", serverID.String()).Msg("Failed to check bans of linked identities")
+		return
+	}
+	bans = slices.DeleteFunc(bans, func(ban models.ServerBan) bool {
+		if ban.ExtendToLinked != nil {
+			return !*ban.ExtendToLinked
+		}
+		return !settings.ExtendByDefault
+	})
+	if len(bans) == 0 {
+		return
+	}
+
+	from := []string{}
+	if data.SteamID != "" {
+		steamIDs = append(steamIDs, data.SteamID)
+		from = append(from, identity.SteamKey(data.SteamID))
+	}
+	if data.EOSID != "" {
+		eosIDs = append(eosIDs, data.EOSID)
+		from = append(from, identity.EOSKey(data.EOSID))
+	}
+
+	links, err := b.resolver.IdentityLinks(b.ctx, steamIDs, eosIDs)
+	if err != nil {
+		log.Warn().Err(err).Str("steamId", data.SteamID).Str("eosId", data.EOSID).Msg("Failed to load identity links")
+		return
+	}
+	// This connection may not have reached ClickHouse yet
+	if data.SteamID != "" && data.EOSID != "" && !slices.ContainsFunc(links, func(link identity.IdentityLink) bool {
+		return link.Steam == data.SteamID && link.EOS == data.EOSID
+	}) {
+		links = append(links, identity.IdentityLink{Steam: data.SteamID, EOS: data.EOSID, Observations: 1})
+	}
+
+	best := bestLinkedBan(bans, links, from)
+	if best == nil {
+		return
+	}
+
+	logEvent := log.Info().
+		Str("steamId", data.SteamID).
+		Str("eosId", data.EOSID).
+		Str("serverId", serverID.String()).
+		Str("banId", best.ban.ID).
+		Str("bannedIdentifier", best.match.Identifier).
+		Int("confidence", best.match.Confidence)
+
+	if best.match.Confidence < settings.MinConfidence {
+		logEvent.Int("minConfidence", settings.MinConfidence).Msg("Player linked to a banned identity below the confidence threshold")
+		return
+	}
+
+	action := models.BanIdentityMatchFlagged
+	if settings.Action == models.LinkedBanActionKick {
+		reason := best.ban.Reason
+		if reason == "" {
+			reason = "You are banned from this server"
+		}
+		action = models.BanIdentityMatchKicked
+		if err := b.kick(serverID, playerIdentifier(data.SteamID, data.EOSID), fmt.Sprintf("Linked account banned: %s", reason)); err != nil {
+			log.Error().Err(err).Str("steamId", data.SteamID).Str("serverId", serverID.String()).Msg("Failed to kick player linked to a banned identity")
+			action = models.BanIdentityMatchKickFailed
+		}
+	}
+
+	banID, err := uuid.Parse(best.ban.ID)
+	if err != nil {
+		log.Error().Err(err).Str("banId", best.ban.ID).Msg("Invalid ban ID")
+		return
+	}
+	linksJSON, err := json.Marshal(best.match.Links)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal identity links")
+		return
+	}
+
+	record := &models.BanIdentityMatch{
+		ServerID:         serverID,
+		BanID:            banID,
+		SteamID:          data.SteamID,
+		EOSID:            data.EOSID,
+		PlayerName:       data.PlayerSuffix,
+		BannedIdentifier: best.match.Identifier,
+		Confidence:       best.match.Confidence,
+		Links:            linksJSON,
+		Action:           action,
+	}
+	if err := core.CreateBanIdentityMatch(b.ctx, b.db, record); err != nil {
+		log.Error().Err(err).Str("banId", best.ban.ID).Msg("Failed to record linked identity match")
+	}
+
+	logEvent.Str("action", action).Msg("Player linked to a banned identity")
+}
+
+// bestLinkedBan returns the ban the player is most confidently linked to
+func bestLinkedBan(bans []models.ServerBan, links []identity.IdentityLink, from []string) *linkedBanMatch {
+	var best *linkedBanMatch
+	for _, ban := range bans {
+		targets := []string{}
+		if ban.SteamID != "" {
+			targets = append(targets, identity.SteamKey(ban.SteamID))
+		}
+		if ban.EOSID != "" {
+			targets = append(targets, identity.EOSKey(ban.EOSID))
+		}
+
+		for _, target := range targets {
+			match, ok := identity.FindLinkPath(links, from, target)
+			if ok && (best == nil || match.Confidence > best.match.Confidence) {
+				best = &linkedBanMatch{ban: ban, match: match}
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,206 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+)
+
+// backfillMatchWindow is how far apart an imported event and an existing row
+// with the same chain ID may be and still count as the same event. Rows
+// ingested live before events were stored with their log time carry the time
+// they were published, which trails the log by a few seconds.
+const backfillMatchWindow = 15 * time.Second
+
+// backfillTables maps the log event types that are stored in ClickHouse to
+// their tables
+var backfillTables = map[event_manager.EventType]string{
+	event_manager.EventTypeLogPlayerConnected:    "server_player_connected_events",
+	event_manager.EventTypeLogPlayerDisconnected: "server_player_disconnected_events",
+	event_manager.EventTypeLogPlayerDamaged:      "server_player_damaged_events",
+	event_manager.EventTypeLogPlayerDied:         "server_player_died_events",
+	event_manager.EventTypeLogPlayerWounded:      "server_player_wounded_events",
+	event_manager.EventTypeLogPlayerRevived:      "server_player_revived_events",
+	event_manager.EventTypeLogPlayerPossess:      "server_player_possess_events",
+	event_manager.EventTypeLogJoinSucceeded:      "server_join_succeeded_events",
+	event_manager.EventTypeLogAdminBroadcast:     "server_admin_broadcast_events",
+	event_manager.EventTypeLogDeployableDamaged:  "server_deployable_damaged_events",
+	event_manager.EventTypeLogTickRate:           "server_tick_rate_events",
+	event_manager.EventTypeLogGameEventUnified:   "server_game_events_unified",
+	event_manager.EventTypeLogVehicleDestroyed:   "server_vehicle_destroyed_events",
+	event_manager.EventTypeLogDeployablePlaced:   "server_deployable_events",
+	event_manager.EventTypeLogDeployableRemoved:  "server_deployable_events",
+	event_manager.EventTypeLogSquadLeaderChanged: "server_squad_leader_changed_events",
+	event_manager.EventTypeLogPlayerRoleChanged:  "server_player_role_changed_events",
+}
+
+// backfillSubtypeColumns names the column that tells events apart when one
+// table holds several kinds of them
+var backfillSubtypeColumns = map[event_manager.EventType]string{
+	event_manager.EventTypeLogGameEventUnified:  "event_type",
+	event_manager.EventTypeLogDeployablePlaced:  "action",
+	event_manager.EventTypeLogDeployableRemoved: "action",
+}
+
+// backfillGroup is a set of events of one type for one server
+type backfillGroup struct {
+	serverID  uuid.UUID
+	eventType event_manager.EventType
+}
+
+// Backfill writes historical log events straight to ClickHouse, skipping
+// events that already have a row. An event matches a row of the same type
+// and chain ID within backfillMatchWindow, and every row matches at most one
+// event, so repeated lines are still imported as often as they occurred.
+// Event types that aren't stored in ClickHouse are ignored.
+func (i *EventIngester) Backfill(ctx context.Context, events []*IngestEvent) (inserted, skipped int, err error) {
+	groups := make(map[backfillGroup][]*IngestEvent)
+	for _, event := range events {
+		if _, ok := backfillTables[event.EventType]; !ok {
+			continue
+		}
+		group := backfillGroup{serverID: event.ServerID, eventType: event.EventType}
+		groups[group] = append(groups[group], event)
+	}
+
+	for group, groupEvents := range groups {
+		existing, err := i.existingEventTimes(ctx, group, groupEvents)
+		if err != nil {
+			return inserted, skipped, err
+		}
+
+		missing := make([]*IngestEvent, 0, len(groupEvents))
+		for _, event := range groupEvents {
+			if existing.consume(backfillKey(event), event.EventTime) {
+				skipped++
+				continue
+			}
+			missing = append(missing, event)
+		}
+
+		if len(missing) == 0 {
+			continue
+		}
+
+		if err := i.ingestEventType(group.eventType, missing); err != nil {
+			return inserted, skipped, fmt.Errorf("failed to insert %s events: %w", group.eventType, err)
+		}
+		inserted += len(missing)
+	}
+
+	return inserted, skipped, nil
+}
+
+// existingEventTimes loads the rows already stored in the time range of the
+// events, keyed like backfillKey
+func (i *EventIngester) existingEventTimes(ctx context.Context, group backfillGroup, events []*IngestEvent) (*eventTimeIndex, error) {
+	from, to := events[0].EventTime, events[0].EventTime
+	for _, event := range events[1:] {
+		if event.EventTime.Before(from) {
+			from = event.EventTime
+		}
+		if event.EventTime.After(to) {
+			to = event.EventTime
+		}
+	}
+	from = from.Add(-backfillMatchWindow)
+	to = to.Add(backfillMatchWindow)
+
+	eventTypeColumn := "''"
+	if column, ok := backfillSubtypeColumns[group.eventType]; ok {
+		eventTypeColumn = column
+	}
+
+	query := fmt.Sprintf(`SELECT toUnixTimestamp64Milli(event_time), chain_id, %s
+		FROM squad_aegis.%s
+		WHERE server_id = ?
+			AND event_time >= fromUnixTimestamp64Milli(?)
+			AND event_time <= fromUnixTimestamp64Milli(?)`,
+		eventTypeColumn, backfillTables[group.eventType])
+
+	rows, err := i.client.Query(ctx, query, group.serverID, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query existing %s events: %w", group.eventType, err)
+	}
+	defer rows.Close()
+
+	index := &eventTimeIndex{times: make(map[string][]int64), used: make(map[string][]bool)}
+	for rows.Next() {
+		var millis int64
+		var chainID, eventType string
+		if err := rows.Scan(&millis, &chainID, &eventType); err != nil {
+			return nil, err
+		}
+		key := chainID + "|" + eventType
+		index.times[key] = append(index.times[key], millis)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for key, times := range index.times {
+		sort.Slice(times, func(a, b int) bool { return times[a] < times[b] })
+		index.used[key] = make([]bool, len(times))
+	}
+
+	return index, nil
+}
+
+// backfillKey identifies the rows an event can match
+func backfillKey(event *IngestEvent) string {
+	_, chainID, _ := parseLogLinePrefix(event.RawData)
+
+	var eventType string
+	switch data := event.Data.(type) {
+	case *event_manager.LogGameEventUnifiedData:
+		eventType = data.EventType
+	case *event_manager.LogDeployableData:
+		eventType = data.Action
+	}
+
+	return chainID + "|" + eventType
+}
+
+// eventTimeIndex holds the times of existing rows, sorted per key
+type eventTimeIndex struct {
+	times map[string][]int64
+	used  map[string][]bool
+}
+
+// consume marks the unused row closest to t as matched, if one is within
+// backfillMatchWindow
+func (e *eventTimeIndex) consume(key string, t time.Time) bool {
+	times := e.times[key]
+	if len(times) == 0 {
+		return false
+	}
+	used := e.used[key]
+	millis := t.UnixMilli()
+	window := backfillMatchWindow.Milliseconds()
+
+	best := -1
+	var bestDistance int64
+	start := sort.Search(len(times), func(n int) bool { return times[n] >= millis-window })
+	for n := start; n < len(times) && times[n] <= millis+window; n++ {
+		if used[n] {
+			continue
+		}
+		distance := times[n] - millis
+		if distance < 0 {
+			distance = -distance
+		}
+		if best == -1 || distance < bestDistance {
+			best, bestDistance = n, distance
+		}
+	}
+
+	if best == -1 {
+		return false
+	}
+	used[best] = true
+	return true
+}
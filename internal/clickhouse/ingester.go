@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
 )
 
 // EventIngester handles ingesting events from the event manager into ClickHouse
@@ -83,7 +84,7 @@ func (i *EventIngester) Start() {
 
 	// Subscribe to events from the event manager
 	filter := event_manager.EventFilter{} // Subscribe to all events
-	i.subscriber = i.eventManager.Subscribe(filter, nil, 1000)
+	i.subscriber = i.eventManager.SubscribeDurable("clickhouse_ingester", filter, nil, 1000)
 	eventChan := i.subscriber.Channel
 
 	i.wg.Add(2)
@@ -125,16 +126,60 @@ func (i *EventIngester) Stop() {
 	i.wg.Wait()
 }
 
-// processEvent converts an event manager event to an ingest event
-func (i *EventIngester) processEvent(event event_manager.Event) {
-	ingestEvent := &IngestEvent{
+// NewIngestEvent converts an event manager event to an ingest event. Events
+// parsed from a log line are stored with the time written in the line rather
+// than the time they were published, so a log imported later produces the
+// same rows as the live log did.
+func NewIngestEvent(event event_manager.Event) *IngestEvent {
+	eventTime := event.Timestamp
+	if logTime, _, ok := parseLogLinePrefix(event.RawData); ok {
+		eventTime = logTime
+	}
+
+	return &IngestEvent{
 		EventID:   event.ID,
 		ServerID:  event.ServerID,
 		EventType: event.Type,
-		EventTime: event.Timestamp,
+		EventTime: eventTime,
 		Data:      event.Data,
 		RawData:   event.RawData,
 	}
+}
+
+// parseLogLinePrefix reads the timestamp and chain ID from the
+// "[2006.01.02-15.04.05:000][ 42]" prefix of a SquadGame.log line
+func parseLogLinePrefix(rawData interface{}) (time.Time, string, bool) {
+	line, ok := rawData.(string)
+	if !ok || !strings.HasPrefix(line, "[") {
+		return time.Time{}, "", false
+	}
+
+	timeEnd := strings.Index(line, "][")
+	if timeEnd == -1 {
+		return time.Time{}, "", false
+	}
+	chainEnd := strings.Index(line[timeEnd+2:], "]")
+	if chainEnd == -1 {
+		return time.Time{}, "", false
+	}
+
+	// Go only reads fractional seconds after a period or comma
+	timestamp := line[1:timeEnd]
+	if separator := strings.LastIndex(timestamp, ":"); separator != -1 {
+		timestamp = timestamp[:separator] + "." + timestamp[separator+1:]
+	}
+
+	eventTime, err := time.Parse("2006.01.02-15.04.05.000", timestamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	return eventTime, strings.TrimSpace(line[timeEnd+2 : timeEnd+2+chainEnd]), true
+}
+
+// processEvent converts an event manager event to an ingest event
+func (i *EventIngester) processEvent(event event_manager.Event) {
+	ingestEvent := NewIngestEvent(event)
 
 	select {
 	case i.eventQueue <- ingestEvent:
@@ -217,6 +262,8 @@ func (i *EventIngester) ingestEventType(eventType event_manager.EventType, event
 		return i.ingestChatMessages(events)
 	case event_manager.EventTypeRconServerInfo:
 		return i.ingestServerInfo(events)
+	case event_manager.EventTypeRconConnected, event_manager.EventTypeRconDisconnected, event_manager.EventTypeRconDegraded:
+		return i.ingestRconAvailability(events)
 	// TODO: support ingesting rcon player warned event
 	// TODO: support ingesting possessed and unpossessed admin camera
 	case event_manager.EventTypeLogPlayerConnected:
@@ -243,6 +290,14 @@ func (i *EventIngester) ingestEventType(eventType event_manager.EventType, event
 		return i.ingestTickRate(events)
 	case event_manager.EventTypeLogGameEventUnified:
 		return i.ingestGameEventUnified(events)
+	case event_manager.EventTypeLogVehicleDestroyed:
+		return i.ingestVehicleDestroyed(events)
+	case event_manager.EventTypeLogDeployablePlaced, event_manager.EventTypeLogDeployableRemoved:
+		return i.ingestDeployable(events)
+	case event_manager.EventTypeLogSquadLeaderChanged:
+		return i.ingestSquadLeaderChanged(events)
+	case event_manager.EventTypeLogPlayerRoleChanged:
+		return i.ingestPlayerRoleChanged(events)
 	default:
 		return nil
 	}
@@ -320,6 +375,62 @@ func (i *EventIngester) ingestServerInfo(events []*IngestEvent) error {
 	return i.client.Exec(i.ctx, query, args...)
 }
 
+func (i *EventIngester) ingestRconAvailability(events []*IngestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO squad_aegis.server_rcon_availability
+		(id, event_time, server_id, state, reason, consecutive_failures, latency_ms, downtime_seconds, ingested_at) VALUES`
+
+	values := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*9)
+
+	for _, event := range events {
+		var state, reason string
+		var failures, latencyMs int64
+		var downtime float64
+
+		switch data := event.Data.(type) {
+		case *event_manager.RconConnectedData:
+			state = string(rcon_manager.ConnectionStateConnected)
+			latencyMs = data.LatencyMs
+			downtime = data.DowntimeSeconds
+		case *event_manager.RconDegradedData:
+			state = string(rcon_manager.ConnectionStateDegraded)
+			reason = data.Reason
+			failures = int64(data.ConsecutiveFailures)
+			latencyMs = data.LatencyMs
+		case *event_manager.RconDisconnectedData:
+			state = string(rcon_manager.ConnectionStateDisconnected)
+			reason = data.Reason
+			failures = int64(data.ConsecutiveFailures)
+		default:
+			continue
+		}
+
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			event.EventID,
+			event.EventTime,
+			event.ServerID,
+			state,
+			reason,
+			uint16(min(failures, 65535)),
+			uint32(min(latencyMs, 4294967295)),
+			downtime,
+			time.Now(),
+		)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	query += strings.Join(values, ",")
+	return i.client.Exec(i.ctx, query, args...)
+}
+
 func (i *EventIngester) ingestPlayerConnected(events []*IngestEvent) error {
 	if len(events) == 0 {
 		return nil
@@ -995,3 +1106,196 @@ func (i *EventIngester) ingestGameEventUnified(events []*IngestEvent) error {
 	query += strings.Join(values, ",")
 	return i.client.Exec(i.ctx, query, args...)
 }
+
+func (i *EventIngester) ingestVehicleDestroyed(events []*IngestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO squad_aegis.server_vehicle_destroyed_events
+		(id, event_time, server_id, chain_id, vehicle, damage, attacker_name, attacker_eos, attacker_steam, attacker_team, attacker_controller, weapon, ingested_at) VALUES`
+
+	values := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*13)
+
+	for _, event := range events {
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+
+		// Extract data from structured event data
+		var chainID, vehicle, attackerName, attackerEOS, attackerSteam, attackerTeam, attackerController, weapon string
+		var damage float32
+
+		if vehicleData, ok := event.Data.(*event_manager.LogVehicleDestroyedData); ok {
+			chainID = vehicleData.ChainID
+			vehicle = vehicleData.Vehicle
+			damage = parseFloat32(vehicleData.Damage)
+			attackerName = vehicleData.AttackerName
+			attackerEOS = vehicleData.AttackerEOS
+			attackerSteam = vehicleData.AttackerSteam
+			attackerTeam = vehicleData.AttackerTeam
+			attackerController = vehicleData.AttackerController
+			weapon = vehicleData.Weapon
+		}
+
+		args = append(args,
+			event.EventID,
+			event.EventTime,
+			event.ServerID,
+			chainID,
+			vehicle,
+			damage,
+			attackerName,
+			attackerEOS,
+			attackerSteam,
+			attackerTeam,
+			attackerController,
+			weapon,
+			time.Now(),
+		)
+	}
+
+	query += strings.Join(values, ",")
+	return i.client.Exec(i.ctx, query, args...)
+}
+
+// ingestDeployable ingests deployable placed and removed events
+func (i *EventIngester) ingestDeployable(events []*IngestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO squad_aegis.server_deployable_events
+		(id, event_time, server_id, chain_id, action, deployable, deployable_type, player_suffix, player_eos, player_steam, team_id, ingested_at) VALUES`
+
+	values := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*12)
+
+	for _, event := range events {
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+
+		// Extract data from structured event data
+		var chainID, action, deployable, deployableType, playerSuffix, playerEOS, playerSteam, teamID string
+		if deployableData, ok := event.Data.(*event_manager.LogDeployableData); ok {
+			chainID = deployableData.ChainID
+			action = deployableData.Action
+			deployable = deployableData.Deployable
+			deployableType = deployableData.DeployableType
+			playerSuffix = deployableData.PlayerSuffix
+			playerEOS = deployableData.PlayerEOS
+			playerSteam = deployableData.PlayerSteam
+			teamID = deployableData.TeamID
+		}
+
+		args = append(args,
+			event.EventID,
+			event.EventTime,
+			event.ServerID,
+			chainID,
+			action,
+			deployable,
+			deployableType,
+			playerSuffix,
+			playerEOS,
+			playerSteam,
+			teamID,
+			time.Now(),
+		)
+	}
+
+	query += strings.Join(values, ",")
+	return i.client.Exec(i.ctx, query, args...)
+}
+
+func (i *EventIngester) ingestSquadLeaderChanged(events []*IngestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO squad_aegis.server_squad_leader_changed_events
+		(id, event_time, server_id, chain_id, player_suffix, player_eos, player_steam, squad_id, team_id, ingested_at) VALUES`
+
+	values := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*10)
+
+	for _, event := range events {
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+
+		// Extract data from structured event data
+		var chainID, playerSuffix, playerEOS, playerSteam, squadID, teamID string
+		if leaderData, ok := event.Data.(*event_manager.LogSquadLeaderChangedData); ok {
+			chainID = leaderData.ChainID
+			playerSuffix = leaderData.PlayerSuffix
+			playerEOS = leaderData.PlayerEOS
+			playerSteam = leaderData.PlayerSteam
+			squadID = leaderData.SquadID
+			teamID = leaderData.TeamID
+		}
+
+		args = append(args,
+			event.EventID,
+			event.EventTime,
+			event.ServerID,
+			chainID,
+			playerSuffix,
+			playerEOS,
+			playerSteam,
+			squadID,
+			teamID,
+			time.Now(),
+		)
+	}
+
+	query += strings.Join(values, ",")
+	return i.client.Exec(i.ctx, query, args...)
+}
+
+func (i *EventIngester) ingestPlayerRoleChanged(events []*IngestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO squad_aegis.server_player_role_changed_events
+		(id, event_time, server_id, chain_id, player_suffix, player_eos, player_steam, role, team_id, squad_id, commander, ingested_at) VALUES`
+
+	values := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*12)
+
+	for _, event := range events {
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+
+		// Extract data from structured event data
+		var chainID, playerSuffix, playerEOS, playerSteam, role, teamID, squadID string
+		var commander uint8
+
+		if roleData, ok := event.Data.(*event_manager.LogPlayerRoleChangedData); ok {
+			chainID = roleData.ChainID
+			playerSuffix = roleData.PlayerSuffix
+			playerEOS = roleData.PlayerEOS
+			playerSteam = roleData.PlayerSteam
+			role = roleData.Role
+			teamID = roleData.TeamID
+			squadID = roleData.SquadID
+			if roleData.Commander {
+				commander = 1
+			}
+		}
+
+		args = append(args,
+			event.EventID,
+			event.EventTime,
+			event.ServerID,
+			chainID,
+			playerSuffix,
+			playerEOS,
+			playerSteam,
+			role,
+			teamID,
+			squadID,
+			commander,
+			time.Now(),
+		)
+	}
+
+	query += strings.Join(values, ",")
+	return i.client.Exec(i.ctx, query, args...)
+}
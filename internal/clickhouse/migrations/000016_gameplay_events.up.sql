@@ -0,0 +1,74 @@
+-- Vehicle, deployable, squad leader and role events parsed from the server log
+CREATE TABLE IF NOT EXISTS squad_aegis.server_vehicle_destroyed_events (
+    id                  UUID DEFAULT generateUUIDv4(),
+    event_time          DateTime64(3, 'UTC'),
+    server_id           UUID,
+    chain_id            String,
+    vehicle             LowCardinality(String),
+    damage              Float32,
+    attacker_name       String,
+    attacker_eos        String,
+    attacker_steam      String,
+    attacker_team       String,
+    attacker_controller String,
+    weapon              LowCardinality(String),
+    ingested_at         DateTime DEFAULT now()
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (server_id, event_time);
+
+--migration:split
+CREATE TABLE IF NOT EXISTS squad_aegis.server_deployable_events (
+    id              UUID DEFAULT generateUUIDv4(),
+    event_time      DateTime64(3, 'UTC'),
+    server_id       UUID,
+    chain_id        String,
+    action          LowCardinality(String),
+    deployable      LowCardinality(String),
+    deployable_type LowCardinality(String),
+    player_suffix   String,
+    player_eos      String,
+    player_steam    String,
+    team_id         String,
+    ingested_at     DateTime DEFAULT now()
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (server_id, event_time);
+
+--migration:split
+CREATE TABLE IF NOT EXISTS squad_aegis.server_squad_leader_changed_events (
+    id            UUID DEFAULT generateUUIDv4(),
+    event_time    DateTime64(3, 'UTC'),
+    server_id     UUID,
+    chain_id      String,
+    player_suffix String,
+    player_eos    String,
+    player_steam  String,
+    squad_id      String,
+    team_id       String,
+    ingested_at   DateTime DEFAULT now()
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (server_id, event_time);
+
+--migration:split
+CREATE TABLE IF NOT EXISTS squad_aegis.server_player_role_changed_events (
+    id            UUID DEFAULT generateUUIDv4(),
+    event_time    DateTime64(3, 'UTC'),
+    server_id     UUID,
+    chain_id      String,
+    player_suffix String,
+    player_eos    String,
+    player_steam  String,
+    role          LowCardinality(String),
+    team_id       String,
+    squad_id      String,
+    commander     UInt8 DEFAULT 0,
+    ingested_at   DateTime DEFAULT now()
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (server_id, event_time);
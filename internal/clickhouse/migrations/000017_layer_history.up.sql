@@ -0,0 +1,16 @@
+-- Layers played on each server, recorded at the start of every match
+CREATE TABLE IF NOT EXISTS squad_aegis.server_layer_history (
+    id            UUID DEFAULT generateUUIDv4(),
+    event_time    DateTime64(3, 'UTC'),
+    server_id     UUID,
+    layer         LowCardinality(String),
+    map           LowCardinality(String),
+    team1_faction LowCardinality(String),
+    team2_faction LowCardinality(String),
+    player_count  UInt16,
+    source        LowCardinality(String),
+    ingested_at   DateTime DEFAULT now()
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (server_id, event_time);
@@ -0,0 +1,23 @@
+-- Admin camera sessions, paired from the possess and unpossess RCON events
+CREATE TABLE IF NOT EXISTS squad_aegis.server_admin_camera_sessions (
+    id                  UUID,
+    server_id           UUID,
+    admin_name          String,
+    admin_eos           String,
+    admin_steam         String,
+    team_id             UInt8,
+    squad_id            UInt16,
+    started_at          DateTime64(3, 'UTC'),
+    ended_at            DateTime64(3, 'UTC'),
+    duration_seconds    Float64,
+    end_reason          LowCardinality(String),
+    kill_window_seconds UInt32,
+    kills_before        UInt16,
+    kills_after         UInt16,
+    ghosting_suspected  UInt8,
+    alerts              Array(LowCardinality(String)),
+    ingested_at         DateTime DEFAULT now()
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(started_at)
+ORDER BY (server_id, started_at);
@@ -0,0 +1,15 @@
+-- RCON connection state changes, recorded by the connection supervisor
+CREATE TABLE IF NOT EXISTS squad_aegis.server_rcon_availability (
+    id                   UUID,
+    event_time           DateTime64(3, 'UTC'),
+    server_id            UUID,
+    state                LowCardinality(String),
+    reason               String,
+    consecutive_failures UInt16,
+    latency_ms           UInt32,
+    downtime_seconds     Float64,
+    ingested_at          DateTime DEFAULT now()
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (server_id, event_time);
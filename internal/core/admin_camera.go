@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// GetAdminCameraSettings returns the admin camera alert settings of a server,
+// or the defaults when none have been saved yet
+func GetAdminCameraSettings(ctx context.Context, database db.Executor, serverId uuid.UUID) (*models.AdminCameraSettings, error) {
+	settings := &models.AdminCameraSettings{ServerID: serverId}
+	err := database.QueryRowContext(ctx, `
+		SELECT alerts_enabled, long_session_minutes, frequent_session_count, frequent_session_window_minutes,
+			kill_window_minutes, ghosting_kill_threshold, updated_at
+		FROM server_admin_camera_settings
+		WHERE server_id = $1
+	`, serverId).Scan(&settings.AlertsEnabled, &settings.LongSessionMinutes, &settings.FrequentSessionCount,
+		&settings.FrequentSessionWindowMinutes, &settings.KillWindowMinutes, &settings.GhostingKillThreshold, &settings.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		settings.AlertsEnabled = true
+		settings.LongSessionMinutes = 10
+		settings.FrequentSessionCount = 5
+		settings.FrequentSessionWindowMinutes = 30
+		settings.KillWindowMinutes = 5
+		settings.GhostingKillThreshold = 2
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin camera settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// SaveAdminCameraSettings creates or replaces the admin camera alert settings of a server
+func SaveAdminCameraSettings(ctx context.Context, database db.Executor, settings *models.AdminCameraSettings) error {
+	err := database.QueryRowContext(ctx, `
+		INSERT INTO server_admin_camera_settings (server_id, alerts_enabled, long_session_minutes, frequent_session_count,
+			frequent_session_window_minutes, kill_window_minutes, ghosting_kill_threshold, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (server_id) DO UPDATE SET
+			alerts_enabled = EXCLUDED.alerts_enabled,
+			long_session_minutes = EXCLUDED.long_session_minutes,
+			frequent_session_count = EXCLUDED.frequent_session_count,
+			frequent_session_window_minutes = EXCLUDED.frequent_session_window_minutes,
+			kill_window_minutes = EXCLUDED.kill_window_minutes,
+			ghosting_kill_threshold = EXCLUDED.ghosting_kill_threshold,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, settings.ServerID, settings.AlertsEnabled, settings.LongSessionMinutes, settings.FrequentSessionCount,
+		settings.FrequentSessionWindowMinutes, settings.KillWindowMinutes, settings.GhostingKillThreshold).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save admin camera settings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,339 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// ErrBanAppealOpen is returned when a ban already has an appeal waiting for review
+var ErrBanAppealOpen = errors.New("this ban already has an open appeal")
+
+// AppealableBan is the ban a player is appealing
+type AppealableBan struct {
+	ID       uuid.UUID
+	ServerID uuid.UUID
+	SteamID  int64
+	Lifted   bool
+}
+
+// GetAppealableBan returns the ban with the given ID if it belongs to the Steam
+// ID. Both have to match, so a ban can't be looked up by its ID alone. Bans
+// without a server, such as remote bans, are reviewed by their source instead.
+func GetAppealableBan(ctx context.Context, database db.Executor, banId uuid.UUID, steamId int64) (*AppealableBan, error) {
+	ban := &AppealableBan{}
+	var liftedAt sql.NullTime
+	err := database.QueryRowContext(ctx, `
+		SELECT id, server_id, steam_id, lifted_at
+		FROM server_bans
+		WHERE id = $1 AND steam_id = $2 AND server_id IS NOT NULL
+	`, banId, steamId).Scan(&ban.ID, &ban.ServerID, &ban.SteamID, &liftedAt)
+	if err != nil {
+		return nil, err
+	}
+	ban.Lifted = liftedAt.Valid
+
+	return ban, nil
+}
+
+const banAppealColumns = `
+	a.id, a.ban_id, a.server_id, a.steam_id, a.status, a.statement, a.contact,
+	a.reviewer_id, u.username, a.resolution, a.resolved_duration,
+	sb.reason, sb.duration, sb.created_at,
+	a.created_at, a.updated_at, a.resolved_at
+`
+
+const banAppealJoins = `
+	FROM ban_appeals a
+	JOIN server_bans sb ON a.ban_id = sb.id
+	LEFT JOIN users u ON a.reviewer_id = u.id
+`
+
+func scanBanAppeal(row interface{ Scan(...any) error }) (*models.BanAppeal, error) {
+	appeal := &models.BanAppeal{}
+	var steamId int64
+	var contact, reviewerName, resolution sql.NullString
+	var reviewerId uuid.NullUUID
+	var resolvedDuration sql.NullInt64
+	var resolvedAt sql.NullTime
+
+	err := row.Scan(&appeal.ID, &appeal.BanID, &appeal.ServerID, &steamId, &appeal.Status, &appeal.Statement, &contact,
+		&reviewerId, &reviewerName, &resolution, &resolvedDuration,
+		&appeal.BanReason, &appeal.BanDuration, &appeal.BanCreatedAt,
+		&appeal.CreatedAt, &appeal.UpdatedAt, &resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	appeal.SteamID = strconv.FormatInt(steamId, 10)
+	if contact.Valid {
+		appeal.Contact = &contact.String
+	}
+	if reviewerId.Valid {
+		appeal.ReviewerID = &reviewerId.UUID
+	}
+	if reviewerName.Valid {
+		appeal.ReviewerName = &reviewerName.String
+	}
+	if resolution.Valid {
+		appeal.Resolution = &resolution.String
+	}
+	if resolvedDuration.Valid {
+		duration := int(resolvedDuration.Int64)
+		appeal.ResolvedDuration = &duration
+	}
+	if resolvedAt.Valid {
+		appeal.ResolvedAt = &resolvedAt.Time
+	}
+
+	return appeal, nil
+}
+
+// BanAppealStatusOpen lists the appeals still waiting on a reviewer or the player
+const BanAppealStatusOpen = "open"
+
+// GetBanAppeals lists the appeals of a server, oldest first so the queue is
+// worked in order. An empty status lists every appeal.
+func GetBanAppeals(ctx context.Context, database db.Executor, serverId uuid.UUID, status string, limit int) ([]*models.BanAppeal, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT `+banAppealColumns+banAppealJoins+`
+		WHERE a.server_id = $1 AND ($2 = '' OR a.status = $2 OR ($2 = $4 AND a.status IN ($5, $6)))
+		ORDER BY a.created_at ASC
+		LIMIT $3
+	`, serverId, status, limit, BanAppealStatusOpen, models.BanAppealStatusPending, models.BanAppealStatusMoreInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban appeals: %w", err)
+	}
+	defer rows.Close()
+
+	appeals := []*models.BanAppeal{}
+	for rows.Next() {
+		appeal, err := scanBanAppeal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ban appeal: %w", err)
+		}
+		appeals = append(appeals, appeal)
+	}
+
+	return appeals, rows.Err()
+}
+
+// GetBanAppeal returns an appeal of a server
+func GetBanAppeal(ctx context.Context, database db.Executor, serverId, appealId uuid.UUID) (*models.BanAppeal, error) {
+	return scanBanAppeal(database.QueryRowContext(ctx, `
+		SELECT `+banAppealColumns+banAppealJoins+`
+		WHERE a.id = $1 AND a.server_id = $2
+	`, appealId, serverId))
+}
+
+// GetBanAppealForUpdate returns an appeal and locks it until the transaction
+// ends, so two reviewers can't resolve it at the same time
+func GetBanAppealForUpdate(ctx context.Context, tx *sql.Tx, serverId, appealId uuid.UUID) (*models.BanAppeal, error) {
+	return scanBanAppeal(tx.QueryRowContext(ctx, `
+		SELECT `+banAppealColumns+banAppealJoins+`
+		WHERE a.id = $1 AND a.server_id = $2
+		FOR UPDATE OF a
+	`, appealId, serverId))
+}
+
+// GetLatestBanAppeal returns the most recent appeal of a ban
+func GetLatestBanAppeal(ctx context.Context, database db.Executor, banId uuid.UUID) (*models.BanAppeal, error) {
+	return scanBanAppeal(database.QueryRowContext(ctx, `
+		SELECT `+banAppealColumns+banAppealJoins+`
+		WHERE a.ban_id = $1
+		ORDER BY a.created_at DESC
+		LIMIT 1
+	`, banId))
+}
+
+// CreateBanAppeal stores a new pending appeal. It returns ErrBanAppealOpen
+// when the ban already has an appeal waiting for review.
+func CreateBanAppeal(ctx context.Context, database db.Executor, appeal *models.BanAppeal) error {
+	var open bool
+	err := database.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM ban_appeals WHERE ban_id = $1 AND status IN ($2, $3))
+	`, appeal.BanID, models.BanAppealStatusPending, models.BanAppealStatusMoreInfo).Scan(&open)
+	if err != nil {
+		return fmt.Errorf("failed to check for open ban appeals: %w", err)
+	}
+	if open {
+		return ErrBanAppealOpen
+	}
+
+	steamId, err := strconv.ParseInt(appeal.SteamID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid steam ID: %w", err)
+	}
+
+	appeal.ID = uuid.New()
+	appeal.Status = models.BanAppealStatusPending
+	err = database.QueryRowContext(ctx, `
+		INSERT INTO ban_appeals (id, ban_id, server_id, steam_id, status, statement, contact, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`, appeal.ID, appeal.BanID, appeal.ServerID, steamId, appeal.Status, appeal.Statement, appeal.Contact).
+		Scan(&appeal.CreatedAt, &appeal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create ban appeal: %w", err)
+	}
+
+	return nil
+}
+
+// SetBanAppealStatus changes the status of an appeal. Accepted and denied
+// appeals are resolved by the reviewer.
+func SetBanAppealStatus(ctx context.Context, database db.Executor, appeal *models.BanAppeal) error {
+	var resolvedAt *time.Time
+	if appeal.Status == models.BanAppealStatusAccepted || appeal.Status == models.BanAppealStatusDenied {
+		now := time.Now()
+		resolvedAt = &now
+	}
+
+	err := database.QueryRowContext(ctx, `
+		UPDATE ban_appeals
+		SET status = $2, reviewer_id = COALESCE($3, reviewer_id), resolution = $4, resolved_duration = $5,
+			resolved_at = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, appeal.ID, appeal.Status, appeal.ReviewerID, appeal.Resolution, appeal.ResolvedDuration, resolvedAt).Scan(&appeal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update ban appeal: %w", err)
+	}
+	appeal.ResolvedAt = resolvedAt
+
+	return nil
+}
+
+// AddBanAppealComment stores a comment on an appeal. A nil user marks it as
+// written by the player.
+func AddBanAppealComment(ctx context.Context, database db.Executor, appealId uuid.UUID, userId *uuid.UUID, message string, internal bool) (*models.BanAppealComment, error) {
+	comment := &models.BanAppealComment{
+		ID:       uuid.New(),
+		AppealID: appealId,
+		UserID:   userId,
+		Message:  message,
+		Internal: internal,
+	}
+
+	err := database.QueryRowContext(ctx, `
+		INSERT INTO ban_appeal_comments (id, appeal_id, user_id, message, internal, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at
+	`, comment.ID, appealId, userId, message, internal).Scan(&comment.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add ban appeal comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// GetBanAppealComments returns the comments of an appeal in the order they
+// were written, leaving out internal notes unless includeInternal is set
+func GetBanAppealComments(ctx context.Context, database db.Executor, appealId uuid.UUID, includeInternal bool) ([]*models.BanAppealComment, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT c.id, c.appeal_id, c.user_id, u.username, c.message, c.internal, c.created_at
+		FROM ban_appeal_comments c
+		LEFT JOIN users u ON c.user_id = u.id
+		WHERE c.appeal_id = $1 AND ($2 OR NOT c.internal)
+		ORDER BY c.created_at ASC
+	`, appealId, includeInternal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban appeal comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []*models.BanAppealComment{}
+	for rows.Next() {
+		comment := &models.BanAppealComment{}
+		var userId uuid.NullUUID
+		var username sql.NullString
+		if err := rows.Scan(&comment.ID, &comment.AppealID, &userId, &username, &comment.Message, &comment.Internal, &comment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ban appeal comment: %w", err)
+		}
+		if userId.Valid {
+			comment.UserID = &userId.UUID
+		}
+		if username.Valid {
+			comment.Username = &username.String
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+// AddBanAppealEvent records an entry in the audit trail of an appeal
+func AddBanAppealEvent(ctx context.Context, database db.Executor, appealId uuid.UUID, userId *uuid.UUID, action, fromStatus, toStatus string, details map[string]interface{}) error {
+	var detailsJSON []byte
+	if details != nil {
+		var err error
+		detailsJSON, err = json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to marshal ban appeal event details: %w", err)
+		}
+	}
+
+	_, err := database.ExecContext(ctx, `
+		INSERT INTO ban_appeal_events (id, appeal_id, user_id, action, from_status, to_status, details, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, NOW())
+	`, uuid.New(), appealId, userId, action, fromStatus, toStatus, detailsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to add ban appeal event: %w", err)
+	}
+
+	return nil
+}
+
+// GetBanAppealEvents returns the audit trail of an appeal, oldest first
+func GetBanAppealEvents(ctx context.Context, database db.Executor, appealId uuid.UUID) ([]*models.BanAppealEvent, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT e.id, e.appeal_id, e.user_id, u.username, e.action, e.from_status, e.to_status, e.details, e.created_at
+		FROM ban_appeal_events e
+		LEFT JOIN users u ON e.user_id = u.id
+		WHERE e.appeal_id = $1
+		ORDER BY e.created_at ASC
+	`, appealId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban appeal events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.BanAppealEvent{}
+	for rows.Next() {
+		event := &models.BanAppealEvent{}
+		var userId uuid.NullUUID
+		var username, fromStatus, toStatus sql.NullString
+		var details []byte
+		if err := rows.Scan(&event.ID, &event.AppealID, &userId, &username, &event.Action, &fromStatus, &toStatus, &details, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ban appeal event: %w", err)
+		}
+		if userId.Valid {
+			event.UserID = &userId.UUID
+		}
+		if username.Valid {
+			event.Username = &username.String
+		}
+		if fromStatus.Valid {
+			event.FromStatus = &fromStatus.String
+		}
+		if toStatus.Valid {
+			event.ToStatus = &toStatus.String
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &event.Details); err != nil {
+				return nil, fmt.Errorf("failed to parse ban appeal event details: %w", err)
+			}
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -13,12 +15,17 @@ import (
 
 // BanList Management Functions
 
+var banListColumns = []string{
+	"id", "name", "description", "is_remote", "remote_url", "remote_sync_enabled", "last_synced_at",
+	"federated", "created_at", "updated_at",
+}
+
 func CreateBanList(ctx context.Context, database db.Executor, banList *models.BanList) (*models.BanList, error) {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	sql, args, err := psql.Insert("ban_lists").Columns(
-		"id", "name", "description", "is_remote", "remote_url", "remote_sync_enabled", "created_at", "updated_at",
+		"id", "name", "description", "is_remote", "remote_url", "remote_sync_enabled", "federated", "created_at", "updated_at",
 	).Values(
-		banList.ID, banList.Name, banList.Description, banList.IsRemote, banList.RemoteURL, banList.RemoteSyncEnabled, banList.CreatedAt, banList.UpdatedAt,
+		banList.ID, banList.Name, banList.Description, banList.IsRemote, banList.RemoteURL, banList.RemoteSyncEnabled, banList.Federated, banList.CreatedAt, banList.UpdatedAt,
 	).ToSql()
 	if err != nil {
 		return nil, err
@@ -34,7 +41,7 @@ func CreateBanList(ctx context.Context, database db.Executor, banList *models.Ba
 
 func GetBanLists(ctx context.Context, database db.Executor) ([]*models.BanList, error) {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	sql, args, err := psql.Select("*").From("ban_lists").OrderBy("created_at DESC").ToSql()
+	sql, args, err := psql.Select(banListColumns...).From("ban_lists").OrderBy("created_at DESC").ToSql()
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +58,7 @@ func GetBanLists(ctx context.Context, database db.Executor) ([]*models.BanList,
 		err = rows.Scan(
 			&banList.ID, &banList.Name, &banList.Description, &banList.IsRemote,
 			&banList.RemoteURL, &banList.RemoteSyncEnabled, &banList.LastSyncedAt,
-			&banList.CreatedAt, &banList.UpdatedAt,
+			&banList.Federated, &banList.CreatedAt, &banList.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -64,7 +71,7 @@ func GetBanLists(ctx context.Context, database db.Executor) ([]*models.BanList,
 
 func GetBanListById(ctx context.Context, database db.Executor, banListId uuid.UUID) (*models.BanList, error) {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	sql, args, err := psql.Select("*").From("ban_lists").Where(squirrel.Eq{"id": banListId}).ToSql()
+	sql, args, err := psql.Select(banListColumns...).From("ban_lists").Where(squirrel.Eq{"id": banListId}).ToSql()
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +81,7 @@ func GetBanListById(ctx context.Context, database db.Executor, banListId uuid.UU
 	err = row.Scan(
 		&banList.ID, &banList.Name, &banList.Description, &banList.IsRemote,
 		&banList.RemoteURL, &banList.RemoteSyncEnabled, &banList.LastSyncedAt,
-		&banList.CreatedAt, &banList.UpdatedAt,
+		&banList.Federated, &banList.CreatedAt, &banList.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -188,15 +195,19 @@ func DeleteServerBanListSubscription(ctx context.Context, database db.Executor,
 // Enhanced Ban Functions
 
 func GetServerBans(ctx context.Context, database db.Executor, serverId uuid.UUID) ([]models.ServerBan, error) {
-	sql := `
+	query := `
 		SELECT DISTINCT ON (sb.steam_id)
 			sb.id, sb.server_id, sb.admin_id, u.username, u.steam_id, sb.steam_id, sb.reason,
 			sb.duration, sb.rule_id, sb.ban_list_id, bl.name as ban_list_name,
 			sb.created_at, sb.updated_at
 		FROM server_bans sb
-		JOIN users u ON sb.admin_id = u.id
+		-- Bans of remote and federated lists have no admin
+		LEFT JOIN users u ON sb.admin_id = u.id
 		LEFT JOIN ban_lists bl ON sb.ban_list_id = bl.id
-		WHERE (
+		WHERE sb.lifted_at IS NULL
+		-- Bans.cfg only holds Steam IDs, EOS ID bans are enforced by Aegis
+		AND sb.steam_id IS NOT NULL
+		AND (
 			-- Direct bans on this server
 			sb.server_id = $1
 			OR
@@ -210,7 +221,7 @@ func GetServerBans(ctx context.Context, database db.Executor, serverId uuid.UUID
 		ORDER BY sb.steam_id, sb.created_at DESC
 	`
 
-	rows, err := database.QueryContext(ctx, sql, serverId)
+	rows, err := database.QueryContext(ctx, query, serverId)
 	if err != nil {
 		return nil, err
 	}
@@ -220,11 +231,13 @@ func GetServerBans(ctx context.Context, database db.Executor, serverId uuid.UUID
 	for rows.Next() {
 		var ban models.ServerBan
 		var steamIDInt int64
-		var adminSteamIDInt int64
+		var serverID, adminID uuid.NullUUID
+		var adminName sql.NullString
+		var adminSteamIDInt sql.NullInt64
 		var ruleIDStr, banListIDStr, banListNameStr *string
 
 		err := rows.Scan(
-			&ban.ID, &ban.ServerID, &ban.AdminID, &ban.AdminName, &adminSteamIDInt,
+			&ban.ID, &serverID, &adminID, &adminName, &adminSteamIDInt,
 			&steamIDInt, &ban.Reason, &ban.Duration, &ruleIDStr,
 			&banListIDStr, &banListNameStr, &ban.CreatedAt, &ban.UpdatedAt,
 		)
@@ -236,8 +249,14 @@ func GetServerBans(ctx context.Context, database db.Executor, serverId uuid.UUID
 		ban.SteamID = fmt.Sprintf("%d", steamIDInt)
 		ban.Name = ban.SteamID
 
+		ban.ServerID = serverID.UUID
+		ban.AdminID = adminID.UUID
+		ban.AdminName = adminName.String
+
 		// Convert admin steam ID if present
-		ban.AdminSteamID = fmt.Sprintf("%d", adminSteamIDInt)
+		if adminSteamIDInt.Valid {
+			ban.AdminSteamID = fmt.Sprintf("%d", adminSteamIDInt.Int64)
+		}
 
 		// Set optional fields
 		ban.RuleID = ruleIDStr
@@ -259,13 +278,22 @@ func GetServerBans(ctx context.Context, database db.Executor, serverId uuid.UUID
 // Remote Ban Source Functions
 
 func GetRemoteBanSources(ctx context.Context, database db.Executor) ([]*models.RemoteBanSource, error) {
-	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
-	sql, args, err := psql.Select("*").From("remote_ban_sources").OrderBy("created_at DESC").ToSql()
-	if err != nil {
-		return nil, err
-	}
-
-	rows, err := database.QueryContext(ctx, sql, args...)
+	rows, err := database.QueryContext(ctx, `
+		SELECT s.id, s.name, s.url, s.sync_enabled, s.sync_interval_minutes, s.last_synced_at,
+			s.last_sync_status, s.last_sync_error, s.format, s.public_key, s.signature_url, s.etag,
+			s.last_modified, s.created_at, s.updated_at,
+			r.id, r.status, r.http_status, r.total, r.added, r.removed, r.changed,
+			r.signature_verified, r.error, r.started_at, r.finished_at
+		FROM remote_ban_sources s
+		LEFT JOIN LATERAL (
+			SELECT *
+			FROM remote_ban_sync_runs
+			WHERE source_id = s.id
+			ORDER BY started_at DESC
+			LIMIT 1
+		) r ON true
+		ORDER BY s.created_at DESC
+	`)
 	if err != nil {
 		return nil, err
 	}
@@ -274,26 +302,54 @@ func GetRemoteBanSources(ctx context.Context, database db.Executor) ([]*models.R
 	var sources []*models.RemoteBanSource
 	for rows.Next() {
 		source := &models.RemoteBanSource{}
+		var runID uuid.NullUUID
+		var runStatus sql.NullString
+		var runTotal, runAdded, runRemoved, runChanged sql.NullInt64
+		var runSignatureVerified sql.NullBool
+		var runHTTPStatus *int
+		var runError *string
+		var runStartedAt, runFinishedAt sql.NullTime
 		err = rows.Scan(
 			&source.ID, &source.Name, &source.URL, &source.SyncEnabled,
 			&source.SyncIntervalMinutes, &source.LastSyncedAt, &source.LastSyncStatus,
-			&source.LastSyncError, &source.CreatedAt, &source.UpdatedAt,
+			&source.LastSyncError, &source.Format, &source.PublicKey, &source.SignatureURL,
+			&source.ETag, &source.LastModified, &source.CreatedAt, &source.UpdatedAt,
+			&runID, &runStatus, &runHTTPStatus, &runTotal, &runAdded, &runRemoved, &runChanged,
+			&runSignatureVerified, &runError, &runStartedAt, &runFinishedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if runID.Valid {
+			source.LastSync = &models.RemoteBanSyncRun{
+				ID:                runID.UUID,
+				SourceID:          source.ID,
+				Status:            runStatus.String,
+				HTTPStatus:        runHTTPStatus,
+				Total:             int(runTotal.Int64),
+				Added:             int(runAdded.Int64),
+				Removed:           int(runRemoved.Int64),
+				Changed:           int(runChanged.Int64),
+				SignatureVerified: runSignatureVerified.Bool,
+				Error:             runError,
+				StartedAt:         runStartedAt.Time,
+				FinishedAt:        runFinishedAt.Time,
+			}
+		}
 		sources = append(sources, source)
 	}
 
-	return sources, nil
+	return sources, rows.Err()
 }
 
 func CreateRemoteBanSource(ctx context.Context, database db.Executor, source *models.RemoteBanSource) (*models.RemoteBanSource, error) {
 	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
 	sql, args, err := psql.Insert("remote_ban_sources").Columns(
-		"id", "name", "url", "sync_enabled", "sync_interval_minutes", "created_at", "updated_at",
+		"id", "name", "url", "sync_enabled", "sync_interval_minutes", "format", "public_key", "signature_url",
+		"created_at", "updated_at",
 	).Values(
-		source.ID, source.Name, source.URL, source.SyncEnabled, source.SyncIntervalMinutes, source.CreatedAt, source.UpdatedAt,
+		source.ID, source.Name, source.URL, source.SyncEnabled, source.SyncIntervalMinutes, source.Format,
+		source.PublicKey, source.SignatureURL, source.CreatedAt, source.UpdatedAt,
 	).ToSql()
 	if err != nil {
 		return nil, err
@@ -459,14 +515,16 @@ func IsIgnoredSteamID(ctx context.Context, database db.Executor, steamID string)
 	return count > 0, nil
 }
 
-// GetActiveBanForServer checks if a steam ID has an active (non-expired) ban
-// on the given server, including bans from subscribed ban lists.
+// GetActiveBanForServer checks if a steam ID or EOS ID has an active
+// (non-expired) ban on the given server, including bans from subscribed ban
+// lists. Either ID may be empty.
 // Returns nil if no active ban is found.
-func GetActiveBanForServer(ctx context.Context, database db.Executor, serverID uuid.UUID, steamID string) (*models.ServerBan, error) {
+func GetActiveBanForServer(ctx context.Context, database db.Executor, serverID uuid.UUID, steamID, eosID string) (*models.ServerBan, error) {
 	query := `
-		SELECT sb.id, sb.reason, sb.duration, sb.created_at
+		SELECT sb.id, sb.steam_id, sb.eos_id, sb.reason, sb.duration, sb.extend_to_linked, sb.created_at
 		FROM server_bans sb
-		WHERE sb.steam_id = $1
+		WHERE (sb.steam_id = NULLIF($1, '')::bigint OR sb.eos_id = NULLIF($3, ''))
+		AND sb.lifted_at IS NULL
 		AND (sb.duration = 0 OR sb.created_at + (sb.duration || ' days')::interval > NOW())
 		AND (
 			sb.server_id = $2
@@ -481,14 +539,23 @@ func GetActiveBanForServer(ctx context.Context, database db.Executor, serverID u
 	`
 
 	var ban models.ServerBan
-	err := database.QueryRowContext(ctx, query, steamID, serverID).Scan(
-		&ban.ID, &ban.Reason, &ban.Duration, &ban.CreatedAt,
+	var bannedSteamID sql.NullInt64
+	var bannedEOSID sql.NullString
+	var extendToLinked sql.NullBool
+	err := database.QueryRowContext(ctx, query, steamID, serverID, eosID).Scan(
+		&ban.ID, &bannedSteamID, &bannedEOSID, &ban.Reason, &ban.Duration, &extendToLinked, &ban.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	ban.SteamID = steamID
+	if bannedSteamID.Valid {
+		ban.SteamID = strconv.FormatInt(bannedSteamID.Int64, 10)
+	}
+	ban.EOSID = bannedEOSID.String
+	if extendToLinked.Valid {
+		ban.ExtendToLinked = &extendToLinked.Bool
+	}
 	ban.ServerID = serverID
 	ban.Permanent = ban.Duration == 0
 	if !ban.Permanent {
@@ -0,0 +1,554 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/permissions"
+	"go.codycody31.dev/squad-aegis/internal/squad_cfg"
+)
+
+// roleNamePattern matches the role names Aegis accepts
+var roleNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// squadPermissionCodes maps lower case Squad permissions to RCON permission codes
+var squadPermissionCodes = func() map[string]permissions.Permission {
+	codes := make(map[string]permissions.Permission, len(permissions.ReverseSquadPermissionMap))
+	for squadPerm, code := range permissions.ReverseSquadPermissionMap {
+		codes[strings.ToLower(squadPerm)] = code
+	}
+	return codes
+}()
+
+// PreviewCfgImport compares the content of an Admins.cfg and a Bans.cfg with
+// the roles, admins and active bans of a server
+func PreviewCfgImport(ctx context.Context, database db.Executor, serverId uuid.UUID, adminsCfg, bansCfg string) (*models.CfgImportPreview, error) {
+	preview := &models.CfgImportPreview{
+		AdminsCfg: adminsCfg,
+		BansCfg:   bansCfg,
+		Groups:    []*models.CfgImportGroup{},
+		Admins:    []*models.CfgImportAdmin{},
+		Bans:      []*models.CfgImportBan{},
+		Warnings:  []*models.CfgImportWarning{},
+	}
+
+	if strings.TrimSpace(adminsCfg) != "" {
+		file := squad_cfg.ParseAdmins(adminsCfg)
+		preview.Warnings = append(preview.Warnings, cfgImportWarnings("Admins.cfg", file.Warnings)...)
+		if err := previewCfgImportAdmins(ctx, database, serverId, file, preview); err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(bansCfg) != "" {
+		file := squad_cfg.ParseBans(bansCfg)
+		preview.Warnings = append(preview.Warnings, cfgImportWarnings("Bans.cfg", file.Warnings)...)
+		if err := previewCfgImportBans(ctx, database, serverId, file, preview); err != nil {
+			return nil, err
+		}
+	}
+
+	return preview, nil
+}
+
+func cfgImportWarnings(fileName string, warnings []squad_cfg.Warning) []*models.CfgImportWarning {
+	converted := make([]*models.CfgImportWarning, 0, len(warnings))
+	for _, warning := range warnings {
+		converted = append(converted, &models.CfgImportWarning{
+			File:    fileName,
+			Line:    warning.Line,
+			Text:    warning.Text,
+			Message: warning.Message,
+		})
+	}
+	return converted
+}
+
+func previewCfgImportAdmins(ctx context.Context, database db.Executor, serverId uuid.UUID, file *squad_cfg.AdminsFile, preview *models.CfgImportPreview) error {
+	roles, err := GetServerRoles(ctx, database, serverId)
+	if err != nil {
+		return fmt.Errorf("failed to get server roles: %w", err)
+	}
+	rolesByName := make(map[string]*models.ServerRole, len(roles))
+	for _, role := range roles {
+		rolesByName[strings.ToLower(role.Name)] = role
+	}
+
+	rolePermissions, err := getServerRoleSquadPermissions(ctx, database, serverId)
+	if err != nil {
+		return fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	// Groups admins can be assigned to, by lower case name
+	groups := make(map[string]*models.CfgImportGroup)
+	for _, group := range file.Groups {
+		item := &models.CfgImportGroup{
+			Line:        group.Line,
+			Name:        group.Name,
+			Permissions: []string{},
+		}
+		preview.Groups = append(preview.Groups, item)
+
+		for _, permission := range group.Permissions {
+			code, ok := squadPermissionCodes[strings.ToLower(permission)]
+			if !ok {
+				item.UnknownPermissions = append(item.UnknownPermissions, permission)
+				continue
+			}
+			if squadPerm := code.ToSquadPermission(); !slices.Contains(item.Permissions, squadPerm) {
+				item.Permissions = append(item.Permissions, squadPerm)
+			}
+		}
+		slices.Sort(item.Permissions)
+
+		key := strings.ToLower(group.Name)
+		if previous, ok := groups[key]; ok {
+			item.Status = models.CfgImportSkipped
+			item.Message = fmt.Sprintf("Group is already defined on line %d", previous.Line)
+			continue
+		}
+		if !roleNamePattern.MatchString(group.Name) {
+			item.Status = models.CfgImportSkipped
+			item.Message = "Role names can only contain letters, numbers and underscores"
+			continue
+		}
+
+		if role, ok := rolesByName[key]; ok {
+			item.RoleID = &role.Id
+			item.ExistingPermissions = rolePermissions[role.Id]
+			if item.ExistingPermissions == nil {
+				item.ExistingPermissions = []string{}
+			}
+			if slices.Equal(item.Permissions, item.ExistingPermissions) {
+				item.Status = models.CfgImportUnchanged
+			} else {
+				item.Status = models.CfgImportConflict
+				item.Message = fmt.Sprintf("Role %s has different permissions in Aegis", role.Name)
+			}
+		} else if len(item.Permissions) == 0 {
+			item.Status = models.CfgImportSkipped
+			item.Message = "None of the permissions are known to Aegis"
+			continue
+		} else {
+			item.Status = models.CfgImportNew
+		}
+		groups[key] = item
+	}
+
+	existingAdmins, err := getServerAdminSteamIDs(ctx, database, serverId)
+	if err != nil {
+		return fmt.Errorf("failed to get server admins: %w", err)
+	}
+
+	seen := make(map[string]int)
+	for _, admin := range file.Admins {
+		item := &models.CfgImportAdmin{
+			Line:    admin.Line,
+			SteamID: admin.SteamID,
+			EOSID:   admin.EOSID,
+			Group:   admin.Group,
+			Comment: admin.Comment,
+		}
+		preview.Admins = append(preview.Admins, item)
+
+		if admin.SteamID == "" {
+			item.Status = models.CfgImportSkipped
+			item.Message = "Admins can only be added by Steam ID"
+			continue
+		}
+
+		key := strings.ToLower(admin.Group)
+		var roleId *uuid.UUID
+		if group, ok := groups[key]; ok {
+			roleId = group.RoleID
+		} else if role, ok := rolesByName[key]; ok {
+			roleId = &role.Id
+		} else {
+			item.Status = models.CfgImportSkipped
+			item.Message = fmt.Sprintf("Group %s isn't defined or can't be imported", admin.Group)
+			continue
+		}
+
+		seenKey := admin.SteamID + ":" + key
+		if line, ok := seen[seenKey]; ok {
+			item.Status = models.CfgImportSkipped
+			item.Message = fmt.Sprintf("Admin is already listed on line %d", line)
+			continue
+		}
+		seen[seenKey] = admin.Line
+
+		if roleId != nil && existingAdmins[admin.SteamID+":"+roleId.String()] {
+			item.Status = models.CfgImportUnchanged
+		} else {
+			item.Status = models.CfgImportNew
+		}
+	}
+
+	return nil
+}
+
+// getServerRoleSquadPermissions returns the sorted Squad permissions of every role of a server
+func getServerRoleSquadPermissions(ctx context.Context, database db.Executor, serverId uuid.UUID) (map[uuid.UUID][]string, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT srp.server_role_id, p.code
+		FROM server_role_permissions srp
+		JOIN server_roles sr ON sr.id = srp.server_role_id
+		JOIN permissions p ON p.id = srp.permission_id
+		WHERE sr.server_id = $1
+	`, serverId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rolePermissions := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var roleId uuid.UUID
+		var code string
+		if err := rows.Scan(&roleId, &code); err != nil {
+			return nil, err
+		}
+
+		codes := []permissions.Permission{permissions.Permission(code)}
+		if codes[0] == permissions.Wildcard {
+			codes = permissions.RCONPermissions()
+		}
+		for _, code := range codes {
+			if squadPerm := code.ToSquadPermission(); squadPerm != "" && !slices.Contains(rolePermissions[roleId], squadPerm) {
+				rolePermissions[roleId] = append(rolePermissions[roleId], squadPerm)
+			}
+		}
+	}
+	for _, squadPerms := range rolePermissions {
+		slices.Sort(squadPerms)
+	}
+
+	return rolePermissions, rows.Err()
+}
+
+// getServerAdminSteamIDs returns the active admins of a server as "steamId:roleId" keys
+func getServerAdminSteamIDs(ctx context.Context, database db.Executor, serverId uuid.UUID) (map[string]bool, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT COALESCE(sa.steam_id, u.steam_id), sa.server_role_id
+		FROM server_admins sa
+		LEFT JOIN users u ON u.id = sa.user_id
+		WHERE sa.server_id = $1 AND (sa.expires_at IS NULL OR sa.expires_at > NOW())
+	`, serverId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	admins := make(map[string]bool)
+	for rows.Next() {
+		var steamId sql.NullInt64
+		var roleId uuid.UUID
+		if err := rows.Scan(&steamId, &roleId); err != nil {
+			return nil, err
+		}
+		if steamId.Valid {
+			admins[strconv.FormatInt(steamId.Int64, 10)+":"+roleId.String()] = true
+		}
+	}
+
+	return admins, rows.Err()
+}
+
+// activeServerBan is an active ban of a server an imported ban can conflict with
+type activeServerBan struct {
+	ID        uuid.UUID
+	Reason    string
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+}
+
+func previewCfgImportBans(ctx context.Context, database db.Executor, serverId uuid.UUID, file *squad_cfg.BansFile, preview *models.CfgImportPreview) error {
+	rows, err := database.QueryContext(ctx, `
+		SELECT id, steam_id, eos_id, reason, duration, created_at
+		FROM server_bans
+		WHERE server_id = $1 AND lifted_at IS NULL
+			AND (duration = 0 OR created_at + duration * INTERVAL '1 day' > NOW())
+		ORDER BY created_at DESC
+	`, serverId)
+	if err != nil {
+		return fmt.Errorf("failed to get server bans: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]*activeServerBan)
+	for rows.Next() {
+		ban := &activeServerBan{}
+		var steamId sql.NullInt64
+		var eosId sql.NullString
+		var duration int
+		if err := rows.Scan(&ban.ID, &steamId, &eosId, &ban.Reason, &duration, &ban.CreatedAt); err != nil {
+			return err
+		}
+		if duration > 0 {
+			expiresAt := ban.CreatedAt.Add(time.Duration(duration) * 24 * time.Hour)
+			ban.ExpiresAt = &expiresAt
+		}
+
+		// Keep the latest ban of a player
+		if steamId.Valid {
+			key := "steam:" + strconv.FormatInt(steamId.Int64, 10)
+			if _, ok := existing[key]; !ok {
+				existing[key] = ban
+			}
+		}
+		if eosId.Valid && eosId.String != "" {
+			key := "eos:" + eosId.String
+			if _, ok := existing[key]; !ok {
+				existing[key] = ban
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	seen := make(map[string]int)
+	for _, ban := range file.Bans {
+		item := &models.CfgImportBan{
+			Line:      ban.Line,
+			SteamID:   ban.SteamID,
+			EOSID:     ban.EOSID,
+			Reason:    ban.Reason,
+			IssuedBy:  ban.IssuedBy,
+			ExpiresAt: ban.ExpiresAt,
+		}
+		if squad_cfg.IsSteamID(ban.IssuerID) {
+			item.IssuerSteamID = ban.IssuerID
+		}
+		preview.Bans = append(preview.Bans, item)
+
+		if ban.ExpiresAt != nil && !ban.ExpiresAt.After(now) {
+			item.Status = models.CfgImportSkipped
+			item.Message = "Ban has expired"
+			continue
+		}
+
+		key := "steam:" + ban.SteamID
+		if ban.SteamID == "" {
+			key = "eos:" + ban.EOSID
+		}
+		if line, ok := seen[key]; ok {
+			item.Status = models.CfgImportSkipped
+			item.Message = fmt.Sprintf("Player is already banned on line %d", line)
+			continue
+		}
+		seen[key] = ban.Line
+
+		current, ok := existing[key]
+		if !ok {
+			item.Status = models.CfgImportNew
+			continue
+		}
+
+		item.ExistingBanID = &current.ID
+		item.ExistingReason = current.Reason
+		item.ExistingExpiresAt = current.ExpiresAt
+		if sameBanExpiry(current.ExpiresAt, ban.ExpiresAt) {
+			item.Status = models.CfgImportUnchanged
+		} else {
+			item.Status = models.CfgImportConflict
+			item.Message = "Player is already banned with a different expiry"
+		}
+	}
+
+	return nil
+}
+
+// sameBanExpiry reports whether two expiries are the same, within the day
+// granularity of ban durations
+func sameBanExpiry(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Sub(*b).Abs() < 24*time.Hour
+}
+
+// ApplyCfgImport imports the content of an Admins.cfg and a Bans.cfg into a
+// server in one transaction. Conflicting roles and bans are only changed when
+// overwrite is set. The preview is computed again inside the transaction, so
+// the import applies to the current state of the server.
+func ApplyCfgImport(ctx context.Context, database *sql.DB, serverId uuid.UUID, adminsCfg, bansCfg string, overwrite bool) (*models.CfgImportPreview, *models.CfgImportResult, error) {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	preview, err := PreviewCfgImport(ctx, tx, serverId, adminsCfg, bansCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &models.CfgImportResult{}
+	now := time.Now()
+
+	roleIds := make(map[string]uuid.UUID)
+	for _, group := range preview.Groups {
+		switch {
+		case group.Status == models.CfgImportNew:
+			roleId := uuid.New()
+			isAdmin := slices.ContainsFunc(group.Permissions, func(permission string) bool {
+				return permission != permissions.RCONReserve.ToSquadPermission()
+			})
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO server_roles (id, server_id, name, permissions, is_admin, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, roleId, serverId, group.Name, "", isAdmin, now); err != nil {
+				return nil, nil, fmt.Errorf("failed to create role %s: %w", group.Name, err)
+			}
+			if err := setCfgImportRolePermissions(ctx, tx, roleId, group.Permissions); err != nil {
+				return nil, nil, err
+			}
+			group.RoleID = &roleId
+			result.RolesCreated++
+		case group.Status == models.CfgImportConflict && overwrite:
+			if _, err := tx.ExecContext(ctx, `DELETE FROM server_role_permissions WHERE server_role_id = $1`, *group.RoleID); err != nil {
+				return nil, nil, fmt.Errorf("failed to clear permissions of role %s: %w", group.Name, err)
+			}
+			if err := setCfgImportRolePermissions(ctx, tx, *group.RoleID, group.Permissions); err != nil {
+				return nil, nil, err
+			}
+			result.RolesUpdated++
+		}
+
+		if group.RoleID != nil {
+			roleIds[strings.ToLower(group.Name)] = *group.RoleID
+		}
+	}
+
+	if len(preview.Admins) > 0 {
+		roles, err := GetServerRoles(ctx, tx, serverId)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get server roles: %w", err)
+		}
+		for _, role := range roles {
+			if _, ok := roleIds[strings.ToLower(role.Name)]; !ok {
+				roleIds[strings.ToLower(role.Name)] = role.Id
+			}
+		}
+	}
+
+	users := make(map[string]*uuid.UUID)
+	for _, admin := range preview.Admins {
+		if admin.Status != models.CfgImportNew {
+			continue
+		}
+
+		userId, err := cfgImportUserBySteamID(ctx, tx, users, admin.SteamID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var notes interface{}
+		if admin.Comment != "" {
+			notes = admin.Comment
+		}
+
+		if userId != nil {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO server_admins (id, server_id, user_id, server_role_id, notes, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, uuid.New(), serverId, *userId, roleIds[strings.ToLower(admin.Group)], notes, now)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO server_admins (id, server_id, steam_id, server_role_id, notes, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, uuid.New(), serverId, admin.SteamID, roleIds[strings.ToLower(admin.Group)], notes, now)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to add admin %s: %w", admin.SteamID, err)
+		}
+		result.AdminsAdded++
+	}
+
+	for _, ban := range preview.Bans {
+		switch {
+		case ban.Status == models.CfgImportNew:
+			var adminId *uuid.UUID
+			if ban.IssuerSteamID != "" {
+				if adminId, err = cfgImportUserBySteamID(ctx, tx, users, ban.IssuerSteamID); err != nil {
+					return nil, nil, err
+				}
+			}
+
+			reason := ban.Reason
+			if reason == "" {
+				reason = "Imported from Bans.cfg"
+			}
+
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO server_bans (id, server_id, admin_id, steam_id, eos_id, reason, duration, issued_by, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+			`, uuid.New(), serverId, adminId, nullIfEmpty(ban.SteamID), nullIfEmpty(ban.EOSID), reason,
+				remoteBanDurationDays(now, ban.ExpiresAt), nullIfEmpty(ban.IssuedBy), now); err != nil {
+				return nil, nil, fmt.Errorf("failed to add ban on line %d: %w", ban.Line, err)
+			}
+			result.BansAdded++
+		case ban.Status == models.CfgImportConflict && overwrite:
+			// Keep the creation date of the ban and move its expiry to the one of the file
+			var createdAt time.Time
+			if err := tx.QueryRowContext(ctx, `SELECT created_at FROM server_bans WHERE id = $1`, *ban.ExistingBanID).Scan(&createdAt); err != nil {
+				return nil, nil, fmt.Errorf("failed to get ban on line %d: %w", ban.Line, err)
+			}
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE server_bans SET duration = $1, updated_at = $2 WHERE id = $3
+			`, remoteBanDurationDays(createdAt, ban.ExpiresAt), now, *ban.ExistingBanID); err != nil {
+				return nil, nil, fmt.Errorf("failed to update ban on line %d: %w", ban.Line, err)
+			}
+			result.BansUpdated++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return preview, result, nil
+}
+
+func setCfgImportRolePermissions(ctx context.Context, tx *sql.Tx, roleId uuid.UUID, squadPerms []string) error {
+	for _, squadPerm := range squadPerms {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO server_role_permissions (server_role_id, permission_id)
+			SELECT $1, id FROM permissions WHERE code = $2
+		`, roleId, string(squadPermissionCodes[strings.ToLower(squadPerm)])); err != nil {
+			return fmt.Errorf("failed to set role permissions: %w", err)
+		}
+	}
+	return nil
+}
+
+// cfgImportUserBySteamID returns the user with a Steam ID, or nil when there is none
+func cfgImportUserBySteamID(ctx context.Context, tx *sql.Tx, users map[string]*uuid.UUID, steamId string) (*uuid.UUID, error) {
+	if userId, ok := users[steamId]; ok {
+		return userId, nil
+	}
+
+	var userId uuid.UUID
+	err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE steam_id = $1`, steamId).Scan(&userId)
+	if err == sql.ErrNoRows {
+		users[steamId] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user %s: %w", steamId, err)
+	}
+
+	users[steamId] = &userId
+	return &userId, nil
+}
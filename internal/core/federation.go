@@ -0,0 +1,382 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// maxFederatedEvidenceSummary bounds the evidence text shared with peers per ban
+const maxFederatedEvidenceSummary = 1000
+
+// Federation API Key Functions
+
+// CreateFederationAPIKey stores a new API key for a peer
+func CreateFederationAPIKey(ctx context.Context, database db.Executor, key *models.FederationAPIKey) error {
+	banListIDs := make([]string, 0, len(key.BanListIDs))
+	for _, id := range key.BanListIDs {
+		banListIDs = append(banListIDs, id.String())
+	}
+
+	err := database.QueryRowContext(ctx, `
+		INSERT INTO federation_api_keys (id, name, key_hash, key_prefix, ban_list_ids, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5::uuid[], $6, NOW())
+		RETURNING created_at
+	`, key.ID, key.Name, key.KeyHash, key.KeyPrefix, pq.Array(banListIDs), key.CreatedBy).Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create federation API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetFederationAPIKeys returns every federation API key, including revoked ones
+func GetFederationAPIKeys(ctx context.Context, database db.Executor) ([]*models.FederationAPIKey, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT id, name, key_hash, key_prefix, ban_list_ids, created_by, last_used_at, revoked_at, created_at
+		FROM federation_api_keys
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get federation API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*models.FederationAPIKey{}
+	for rows.Next() {
+		key, err := scanFederationAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetFederationAPIKeyByHash returns the active API key with the given hash
+func GetFederationAPIKeyByHash(ctx context.Context, database db.Executor, keyHash string) (*models.FederationAPIKey, error) {
+	row := database.QueryRowContext(ctx, `
+		SELECT id, name, key_hash, key_prefix, ban_list_ids, created_by, last_used_at, revoked_at, created_at
+		FROM federation_api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`, keyHash)
+
+	key, err := scanFederationAPIKey(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func scanFederationAPIKey(row interface{ Scan(...interface{}) error }) (*models.FederationAPIKey, error) {
+	key := &models.FederationAPIKey{}
+	var banListIDs []string
+	var createdBy uuid.NullUUID
+	if err := row.Scan(&key.ID, &key.Name, &key.KeyHash, &key.KeyPrefix, pq.Array(&banListIDs), &createdBy,
+		&key.LastUsedAt, &key.RevokedAt, &key.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan federation API key: %w", err)
+	}
+	if createdBy.Valid {
+		key.CreatedBy = &createdBy.UUID
+	}
+	key.BanListIDs = make([]uuid.UUID, 0, len(banListIDs))
+	for _, id := range banListIDs {
+		if parsed, err := uuid.Parse(id); err == nil {
+			key.BanListIDs = append(key.BanListIDs, parsed)
+		}
+	}
+
+	return key, nil
+}
+
+// RevokeFederationAPIKey revokes an API key, returning sql.ErrNoRows when
+// there is no active key with the ID
+func RevokeFederationAPIKey(ctx context.Context, database db.Executor, keyId uuid.UUID) error {
+	result, err := database.ExecContext(ctx, `
+		UPDATE federation_api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL
+	`, keyId)
+	if err != nil {
+		return fmt.Errorf("failed to revoke federation API key: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// TouchFederationAPIKey records that an API key was just used
+func TouchFederationAPIKey(ctx context.Context, database db.Executor, keyId uuid.UUID) error {
+	_, err := database.ExecContext(ctx, "UPDATE federation_api_keys SET last_used_at = NOW() WHERE id = $1", keyId)
+	return err
+}
+
+// Federated Ban List Export Functions
+
+// GetFederatedBanLists returns the federated ban lists an API key may read
+func GetFederatedBanLists(ctx context.Context, database db.Executor, key *models.FederationAPIKey) ([]models.FederatedBanListInfo, error) {
+	banListIDs := make([]string, 0, len(key.BanListIDs))
+	for _, id := range key.BanListIDs {
+		banListIDs = append(banListIDs, id.String())
+	}
+
+	rows, err := database.QueryContext(ctx, `
+		SELECT bl.id, bl.name, bl.description,
+			(SELECT COUNT(*) FROM server_bans sb WHERE sb.ban_list_id = bl.id AND sb.lifted_at IS NULL
+				AND (sb.duration = 0 OR sb.created_at + (sb.duration || ' days')::interval > NOW())),
+			GREATEST(bl.updated_at, (SELECT MAX(sb.updated_at) FROM server_bans sb WHERE sb.ban_list_id = bl.id))
+		FROM ban_lists bl
+		WHERE bl.federated
+		AND (cardinality($1::uuid[]) = 0 OR bl.id = ANY($1::uuid[]))
+		ORDER BY bl.name
+	`, pq.Array(banListIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get federated ban lists: %w", err)
+	}
+	defer rows.Close()
+
+	lists := []models.FederatedBanListInfo{}
+	for rows.Next() {
+		var list models.FederatedBanListInfo
+		if err := rows.Scan(&list.ID, &list.Name, &list.Description, &list.BanCount, &list.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan federated ban list: %w", err)
+		}
+		lists = append(lists, list)
+	}
+
+	return lists, rows.Err()
+}
+
+// GetFederatedBanListExport returns the active bans of a federated ban list
+// as they are shared with peers. Bans this instance received from a peer keep
+// the community that issued them, so sharing them on doesn't claim them.
+func GetFederatedBanListExport(ctx context.Context, database db.Executor, banListId uuid.UUID, community string) ([]models.FederatedBanExport, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT sb.id, sb.steam_id, sb.eos_id, sb.reason, sb.duration, sb.created_at,
+			COALESCE(fb.community, $2),
+			COALESCE(fb.rule_category, parent.title, sr.title, ''),
+			COALESCE(fb.issued_by, sb.issued_by, u.username, ''),
+			COALESCE(fb.evidence_summary, sb.evidence_text, ''),
+			COALESCE(fb.evidence_count, (SELECT COUNT(*) FROM ban_evidence be WHERE be.ban_id = sb.id))
+		FROM server_bans sb
+		LEFT JOIN users u ON sb.admin_id = u.id
+		LEFT JOIN server_rules sr ON sb.rule_id = sr.id
+		LEFT JOIN server_rules parent ON sr.parent_id = parent.id
+		LEFT JOIN federated_bans fb ON fb.server_ban_id = sb.id
+		WHERE sb.ban_list_id = $1
+		AND sb.lifted_at IS NULL
+		AND (sb.steam_id IS NOT NULL OR sb.eos_id IS NOT NULL)
+		AND (sb.duration = 0 OR sb.created_at + (sb.duration || ' days')::interval > NOW())
+		ORDER BY sb.created_at
+	`, banListId, community)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get federated bans: %w", err)
+	}
+	defer rows.Close()
+
+	bans := []models.FederatedBanExport{}
+	for rows.Next() {
+		var ban models.FederatedBanExport
+		var steamID sql.NullInt64
+		var eosID sql.NullString
+		if err := rows.Scan(&ban.ID, &steamID, &eosID, &ban.Reason, &ban.DurationDays, &ban.CreatedAt,
+			&ban.Community, &ban.RuleCategory, &ban.IssuedBy, &ban.EvidenceSummary, &ban.EvidenceCount); err != nil {
+			return nil, fmt.Errorf("failed to scan federated ban: %w", err)
+		}
+		if steamID.Valid {
+			ban.SteamID = strconv.FormatInt(steamID.Int64, 10)
+		}
+		ban.EOSID = eosID.String
+		if len(ban.EvidenceSummary) > maxFederatedEvidenceSummary {
+			ban.EvidenceSummary = strings.ToValidUTF8(ban.EvidenceSummary[:maxFederatedEvidenceSummary], "")
+		}
+		if ban.DurationDays > 0 {
+			expiresAt := ban.CreatedAt.AddDate(0, 0, ban.DurationDays)
+			ban.ExpiresAt = &expiresAt
+		}
+		bans = append(bans, ban)
+	}
+
+	return bans, rows.Err()
+}
+
+// Federation Peer Functions
+
+// CreateFederationPeer stores a new peer
+func CreateFederationPeer(ctx context.Context, database db.Executor, peer *models.FederationPeer) error {
+	err := database.QueryRowContext(ctx, `
+		INSERT INTO federation_peers (id, name, url, api_key, trust_level, min_duration_days, rule_categories,
+			sync_enabled, sync_interval_minutes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`, peer.ID, peer.Name, peer.URL, peer.APIKey, peer.TrustLevel, peer.MinDurationDays, pq.Array(peer.RuleCategories),
+		peer.SyncEnabled, peer.SyncIntervalMinutes).Scan(&peer.CreatedAt, &peer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create federation peer: %w", err)
+	}
+
+	return nil
+}
+
+const federationPeerQuery = `
+	SELECT p.id, p.name, p.url, p.api_key, p.trust_level, p.min_duration_days, p.rule_categories, p.ban_list_id,
+		p.sync_enabled, p.sync_interval_minutes, p.last_synced_at, p.last_sync_status, p.last_sync_error,
+		(SELECT COUNT(*) FROM federated_bans fb WHERE fb.peer_id = p.id AND fb.revoked_at IS NULL),
+		(SELECT COUNT(*) FROM federated_bans fb WHERE fb.peer_id = p.id AND fb.revoked_at IS NULL AND fb.server_ban_id IS NOT NULL),
+		p.created_at, p.updated_at
+	FROM federation_peers p
+`
+
+// GetFederationPeers returns every federation peer
+func GetFederationPeers(ctx context.Context, database db.Executor) ([]*models.FederationPeer, error) {
+	rows, err := database.QueryContext(ctx, federationPeerQuery+" ORDER BY p.created_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get federation peers: %w", err)
+	}
+	defer rows.Close()
+
+	peers := []*models.FederationPeer{}
+	for rows.Next() {
+		peer, err := scanFederationPeer(rows)
+		if err != nil {
+			return nil, err
+		}
+		peers = append(peers, peer)
+	}
+
+	return peers, rows.Err()
+}
+
+// GetFederationPeerById returns a federation peer
+func GetFederationPeerById(ctx context.Context, database db.Executor, peerId uuid.UUID) (*models.FederationPeer, error) {
+	return scanFederationPeer(database.QueryRowContext(ctx, federationPeerQuery+" WHERE p.id = $1", peerId))
+}
+
+func scanFederationPeer(row interface{ Scan(...interface{}) error }) (*models.FederationPeer, error) {
+	peer := &models.FederationPeer{}
+	var banListID uuid.NullUUID
+	err := row.Scan(&peer.ID, &peer.Name, &peer.URL, &peer.APIKey, &peer.TrustLevel, &peer.MinDurationDays,
+		pq.Array(&peer.RuleCategories), &banListID, &peer.SyncEnabled, &peer.SyncIntervalMinutes, &peer.LastSyncedAt,
+		&peer.LastSyncStatus, &peer.LastSyncError, &peer.ActiveBans, &peer.EnforcedBans, &peer.CreatedAt, &peer.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan federation peer: %w", err)
+	}
+	if banListID.Valid {
+		peer.BanListID = &banListID.UUID
+	}
+	if peer.RuleCategories == nil {
+		peer.RuleCategories = []string{}
+	}
+
+	return peer, nil
+}
+
+// UpdateFederationPeer changes the given columns of a peer
+func UpdateFederationPeer(ctx context.Context, database db.Executor, peerId uuid.UUID, updateData map[string]interface{}) error {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	updateData["updated_at"] = time.Now()
+
+	query := psql.Update("federation_peers").Where(squirrel.Eq{"id": peerId})
+	for key, value := range updateData {
+		query = query.Set(key, value)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = database.ExecContext(ctx, sql, args...)
+	return err
+}
+
+// DeleteFederationPeer removes a peer along with the bans received from it
+// and its ban list
+func DeleteFederationPeer(ctx context.Context, database *sql.DB, peerId uuid.UUID) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var banListID uuid.NullUUID
+	err = tx.QueryRowContext(ctx, "DELETE FROM federation_peers WHERE id = $1 RETURNING ban_list_id", peerId).Scan(&banListID)
+	if err != nil {
+		return err
+	}
+
+	if banListID.Valid {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM server_bans WHERE ban_list_id = $1", banListID.UUID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM ban_lists WHERE id = $1", banListID.UUID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetFederatedBans returns the most recently received bans of a peer,
+// revoked bans included
+func GetFederatedBans(ctx context.Context, database db.Executor, peerId uuid.UUID, limit int) ([]*models.FederatedBan, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT fb.id, fb.peer_id, p.name, fb.remote_ban_id, fb.remote_ban_list, fb.community, fb.steam_id, fb.eos_id,
+			fb.reason, fb.rule_category, fb.duration_days, fb.evidence_summary, fb.evidence_count, fb.issued_by,
+			fb.banned_at, fb.server_ban_id, fb.revoked_at, fb.received_at, fb.updated_at
+		FROM federated_bans fb
+		JOIN federation_peers p ON fb.peer_id = p.id
+		WHERE fb.peer_id = $1
+		ORDER BY fb.revoked_at IS NOT NULL, fb.banned_at DESC
+		LIMIT $2
+	`, peerId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get federated bans: %w", err)
+	}
+	defer rows.Close()
+
+	bans := []*models.FederatedBan{}
+	for rows.Next() {
+		ban, err := scanFederatedBan(rows)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+
+	return bans, rows.Err()
+}
+
+func scanFederatedBan(row interface{ Scan(...interface{}) error }) (*models.FederatedBan, error) {
+	ban := &models.FederatedBan{}
+	var steamID sql.NullInt64
+	var eosID sql.NullString
+	var serverBanID uuid.NullUUID
+	err := row.Scan(&ban.ID, &ban.PeerID, &ban.PeerName, &ban.RemoteBanID, &ban.RemoteBanList, &ban.Community,
+		&steamID, &eosID, &ban.Reason, &ban.RuleCategory, &ban.DurationDays, &ban.EvidenceSummary, &ban.EvidenceCount,
+		&ban.IssuedBy, &ban.BannedAt, &serverBanID, &ban.RevokedAt, &ban.ReceivedAt, &ban.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan federated ban: %w", err)
+	}
+	if steamID.Valid {
+		ban.SteamID = strconv.FormatInt(steamID.Int64, 10)
+	}
+	ban.EOSID = eosID.String
+	if serverBanID.Valid {
+		ban.ServerBanID = &serverBanID.UUID
+		ban.Enforced = true
+	}
+
+	return ban, nil
+}
@@ -0,0 +1,521 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// FederationAPIPath is the path of the federation API under the base URL of an instance
+const FederationAPIPath = "/api/federation/v1"
+
+// FederationSyncService pulls the federated ban lists of peers into a ban
+// list per peer
+type FederationSyncService struct {
+	database   db.Executor
+	dbInstance *sql.DB // Keep reference to the database instance for transactions
+	client     *http.Client
+}
+
+func NewFederationSyncService(database db.Executor, dbInstance *sql.DB) *FederationSyncService {
+	return &FederationSyncService{
+		database:   database,
+		dbInstance: dbInstance,
+		client:     &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// FederationSyncResult counts the changes a sync applied to the bans of a peer
+type FederationSyncResult struct {
+	Received int `json:"received"`
+	Added    int `json:"added"`
+	Changed  int `json:"changed"`
+	Revoked  int `json:"revoked"`
+	Filtered int `json:"filtered"`
+}
+
+// receivedFederatedBan is a ban of a peer together with the list it came from
+type receivedFederatedBan struct {
+	models.FederatedBanExport
+	list string
+}
+
+// SyncAllPeers syncs every enabled peer whose sync interval has passed
+func (s *FederationSyncService) SyncAllPeers(ctx context.Context) error {
+	peers, err := GetFederationPeers(ctx, s.database)
+	if err != nil {
+		return fmt.Errorf("failed to get federation peers: %w", err)
+	}
+
+	for _, peer := range peers {
+		if !peer.SyncEnabled {
+			continue
+		}
+
+		if peer.LastSyncedAt != nil {
+			nextSync := peer.LastSyncedAt.Add(time.Duration(peer.SyncIntervalMinutes) * time.Minute)
+			if time.Now().Before(nextSync) {
+				continue
+			}
+		}
+
+		s.SyncPeerAndRecord(ctx, peer)
+	}
+
+	return nil
+}
+
+// SyncPeerAndRecord syncs a peer and stores the outcome on it
+func (s *FederationSyncService) SyncPeerAndRecord(ctx context.Context, peer *models.FederationPeer) (*FederationSyncResult, error) {
+	result, err := s.SyncPeer(ctx, peer)
+
+	updateData := map[string]interface{}{
+		"last_synced_at":   time.Now(),
+		"last_sync_status": models.FederationSyncSuccess,
+		"last_sync_error":  nil,
+	}
+	if err != nil {
+		log.Error().Err(err).Str("peer", peer.Name).Msg("Failed to sync federation peer")
+		updateData["last_sync_status"] = models.FederationSyncError
+		updateData["last_sync_error"] = err.Error()
+	} else {
+		log.Info().
+			Str("peer", peer.Name).
+			Int("received", result.Received).
+			Int("added", result.Added).
+			Int("changed", result.Changed).
+			Int("revoked", result.Revoked).
+			Int("filtered", result.Filtered).
+			Msg("Synced federation peer")
+	}
+
+	if updateErr := UpdateFederationPeer(ctx, s.database, peer.ID, updateData); updateErr != nil {
+		log.Error().Err(updateErr).Str("peer", peer.Name).Msg("Failed to record federation peer sync")
+	}
+
+	return result, err
+}
+
+// SyncPeer pulls every ban list the API key of a peer can read, and applies
+// the differences to the bans received from the peer. Bans the peer no longer
+// shares are revoked, so lifting a ban on the peer lifts it here too.
+func (s *FederationSyncService) SyncPeer(ctx context.Context, peer *models.FederationPeer) (*FederationSyncResult, error) {
+	var lists []models.FederatedBanListInfo
+	if err := s.peerGet(ctx, peer, "/ban-lists", "ban_lists", &lists); err != nil {
+		return nil, err
+	}
+
+	received := []receivedFederatedBan{}
+	for _, list := range lists {
+		var bans []models.FederatedBanExport
+		if err := s.peerGet(ctx, peer, "/ban-lists/"+list.ID.String()+"/bans", "bans", &bans); err != nil {
+			return nil, fmt.Errorf("failed to get bans of %s: %w", list.Name, err)
+		}
+		for _, ban := range bans {
+			received = append(received, receivedFederatedBan{FederatedBanExport: ban, list: list.Name})
+		}
+	}
+
+	return s.applyPeerBans(ctx, peer, received)
+}
+
+// peerGet fetches a federation API path of a peer and decodes one field of
+// the response data into out
+func (s *FederationSyncService) peerGet(ctx context.Context, peer *models.FederationPeer, path, field string, out interface{}) error {
+	url := strings.TrimRight(peer.URL, "/") + FederationAPIPath + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid peer URL: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+peer.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach peer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteBanFeedSize))
+	if err != nil {
+		return fmt.Errorf("failed to read peer response: %w", err)
+	}
+
+	var envelope struct {
+		Message string                     `json:"message"`
+		Data    map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("HTTP %d: peer did not answer with the federation API", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, envelope.Message)
+	}
+
+	raw, ok := envelope.Data[field]
+	if !ok {
+		return fmt.Errorf("peer response has no %s", field)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("invalid %s from peer: %w", field, err)
+	}
+
+	return nil
+}
+
+// federatedBanAccepted checks a received ban against the filters of a peer
+func federatedBanAccepted(peer *models.FederationPeer, ban *models.FederatedBanExport) bool {
+	if ban.DurationDays > 0 && ban.DurationDays < peer.MinDurationDays {
+		return false
+	}
+
+	if len(peer.RuleCategories) > 0 {
+		return slices.ContainsFunc(peer.RuleCategories, func(category string) bool {
+			return strings.EqualFold(strings.TrimSpace(category), strings.TrimSpace(ban.RuleCategory))
+		})
+	}
+
+	return true
+}
+
+// federatedBanEnforced tells whether the trust level of a peer enforces a ban
+func federatedBanEnforced(peer *models.FederationPeer, ban *models.FederatedBan) bool {
+	switch peer.TrustLevel {
+	case models.FederationTrustFull:
+		return true
+	case models.FederationTrustEvidence:
+		return ban.EvidenceCount > 0 || (ban.EvidenceSummary != nil && *ban.EvidenceSummary != "")
+	default:
+		return false
+	}
+}
+
+// sameFederatedBan tells whether a stored ban still matches what the peer shares
+func sameFederatedBan(stored, received *models.FederatedBan) bool {
+	return stored.RemoteBanList == received.RemoteBanList &&
+		stored.Community == received.Community &&
+		stored.SteamID == received.SteamID &&
+		stored.EOSID == received.EOSID &&
+		stored.Reason == received.Reason &&
+		stringValue(stored.RuleCategory) == stringValue(received.RuleCategory) &&
+		stored.DurationDays == received.DurationDays &&
+		stringValue(stored.EvidenceSummary) == stringValue(received.EvidenceSummary) &&
+		stored.EvidenceCount == received.EvidenceCount &&
+		stringValue(stored.IssuedBy) == stringValue(received.IssuedBy) &&
+		stored.BannedAt.Equal(received.BannedAt)
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func optionalText(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// applyPeerBans reconciles the stored bans of a peer with the bans it shares,
+// in one transaction
+func (s *FederationSyncService) applyPeerBans(ctx context.Context, peer *models.FederationPeer, received []receivedFederatedBan) (*FederationSyncResult, error) {
+	result := &FederationSyncResult{}
+
+	ignoredSteamIDs, err := GetIgnoredSteamIDs(ctx, s.database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ignored Steam IDs: %w", err)
+	}
+	ignored := make(map[string]bool, len(ignoredSteamIDs))
+	for _, ignoredSteamID := range ignoredSteamIDs {
+		ignored[ignoredSteamID.SteamID] = true
+	}
+
+	tx, err := s.dbInstance.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	banListID, err := s.peerBanList(ctx, tx, peer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban list of peer: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT fb.id, fb.peer_id, '', fb.remote_ban_id, fb.remote_ban_list, fb.community, fb.steam_id, fb.eos_id,
+			fb.reason, fb.rule_category, fb.duration_days, fb.evidence_summary, fb.evidence_count, fb.issued_by,
+			fb.banned_at, fb.server_ban_id, fb.revoked_at, fb.received_at, fb.updated_at
+		FROM federated_bans fb
+		WHERE fb.peer_id = $1
+		FOR UPDATE
+	`, peer.ID)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*models.FederatedBan)
+	for rows.Next() {
+		ban, err := scanFederatedBan(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		existing[ban.RemoteBanID] = ban
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool, len(received))
+	for i := range received {
+		export := &received[i].FederatedBanExport
+		if export.ID == "" || seen[export.ID] {
+			continue
+		}
+		if (export.SteamID == "" || !isRemoteSteamID(export.SteamID)) && !remoteEOSIDPattern.MatchString(export.EOSID) {
+			continue
+		}
+		if export.ExpiresAt != nil && export.ExpiresAt.Before(now) {
+			continue
+		}
+		seen[export.ID] = true
+		result.Received++
+
+		stored, ok := existing[export.ID]
+		if !federatedBanAccepted(peer, export) {
+			// Filtered bans are dropped rather than revoked, the peer still has them
+			if ok {
+				if err := deleteFederatedBan(ctx, tx, stored); err != nil {
+					return nil, err
+				}
+			}
+			result.Filtered++
+			continue
+		}
+
+		community := export.Community
+		if community == "" {
+			community = peer.Name
+		}
+		ban := &models.FederatedBan{
+			RemoteBanID:     export.ID,
+			RemoteBanList:   received[i].list,
+			Community:       community,
+			Reason:          export.Reason,
+			RuleCategory:    optionalText(export.RuleCategory),
+			DurationDays:    max(export.DurationDays, 0),
+			EvidenceSummary: optionalText(export.EvidenceSummary),
+			EvidenceCount:   export.EvidenceCount,
+			IssuedBy:        optionalText(export.IssuedBy),
+			// Postgres keeps microseconds, so compare at that precision
+			BannedAt: export.CreatedAt.Truncate(time.Microsecond),
+		}
+		if isRemoteSteamID(export.SteamID) {
+			ban.SteamID = export.SteamID
+		}
+		if remoteEOSIDPattern.MatchString(export.EOSID) {
+			ban.EOSID = export.EOSID
+		}
+		if ban.BannedAt.IsZero() {
+			ban.BannedAt = now
+		}
+
+		changed := true
+		if !ok {
+			ban.ID = uuid.New()
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO federated_bans (id, peer_id, remote_ban_id, remote_ban_list, community, steam_id, eos_id,
+					reason, rule_category, duration_days, evidence_summary, evidence_count, issued_by, banned_at,
+					received_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $15)
+			`, ban.ID, peer.ID, ban.RemoteBanID, ban.RemoteBanList, ban.Community, nullIfEmpty(ban.SteamID),
+				nullIfEmpty(ban.EOSID), ban.Reason, ban.RuleCategory, ban.DurationDays, ban.EvidenceSummary,
+				ban.EvidenceCount, ban.IssuedBy, ban.BannedAt, now)
+			if err != nil {
+				return nil, err
+			}
+			result.Added++
+		} else {
+			ban.ID = stored.ID
+			ban.ServerBanID = stored.ServerBanID
+			changed = !sameFederatedBan(stored, ban)
+			if changed || stored.RevokedAt != nil {
+				_, err = tx.ExecContext(ctx, `
+					UPDATE federated_bans
+					SET remote_ban_list = $1, community = $2, steam_id = $3, eos_id = $4, reason = $5,
+						rule_category = $6, duration_days = $7, evidence_summary = $8, evidence_count = $9,
+						issued_by = $10, banned_at = $11, revoked_at = NULL, updated_at = $12
+					WHERE id = $13
+				`, ban.RemoteBanList, ban.Community, nullIfEmpty(ban.SteamID), nullIfEmpty(ban.EOSID), ban.Reason,
+					ban.RuleCategory, ban.DurationDays, ban.EvidenceSummary, ban.EvidenceCount, ban.IssuedBy,
+					ban.BannedAt, now, ban.ID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if changed {
+				result.Changed++
+			} else if stored.RevokedAt != nil {
+				// Shared again after being revoked
+				result.Added++
+			}
+		}
+
+		enforce := federatedBanEnforced(peer, ban) && !ignored[ban.SteamID]
+		if err := s.enforceFederatedBan(ctx, tx, banListID, ban, enforce, changed, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// Whatever the peer no longer shares was lifted or removed there
+	for remoteBanID, stored := range existing {
+		if seen[remoteBanID] || stored.RevokedAt != nil {
+			continue
+		}
+		if stored.DurationDays > 0 && stored.BannedAt.AddDate(0, 0, stored.DurationDays).Before(now) {
+			// Expired rather than revoked
+			if err := deleteFederatedBan(ctx, tx, stored); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if stored.ServerBanID != nil {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM server_bans WHERE id = $1", *stored.ServerBanID); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE federated_bans SET revoked_at = $1, server_ban_id = NULL, updated_at = $1 WHERE id = $2
+		`, now, stored.ID); err != nil {
+			return nil, err
+		}
+		result.Revoked++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// enforceFederatedBan keeps the copy of a federated ban in the ban list of
+// the peer in line with whether the ban is enforced
+func (s *FederationSyncService) enforceFederatedBan(ctx context.Context, tx *sql.Tx, banListID uuid.UUID, ban *models.FederatedBan, enforce, changed bool, now time.Time) error {
+	if !enforce {
+		if ban.ServerBanID == nil {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM server_bans WHERE id = $1", *ban.ServerBanID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE federated_bans SET server_ban_id = NULL WHERE id = $1", ban.ID)
+		return err
+	}
+
+	if ban.ServerBanID != nil {
+		if !changed {
+			return nil
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE server_bans
+			SET steam_id = $1, eos_id = $2, reason = $3, duration = $4, evidence_text = $5, issued_by = $6,
+				created_at = $7, updated_at = $8
+			WHERE id = $9
+		`, nullIfEmpty(ban.SteamID), nullIfEmpty(ban.EOSID), ban.Reason, ban.DurationDays, ban.EvidenceSummary,
+			ban.Community, ban.BannedAt, now, *ban.ServerBanID)
+		return err
+	}
+
+	// Like remote bans, federated bans belong to no server or admin
+	serverBanID := uuid.New()
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO server_bans (id, server_id, admin_id, steam_id, eos_id, reason, duration, evidence_text,
+			issued_by, ban_list_id, created_at, updated_at)
+		VALUES ($1, NULL, NULL, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, serverBanID, nullIfEmpty(ban.SteamID), nullIfEmpty(ban.EOSID), ban.Reason, ban.DurationDays,
+		ban.EvidenceSummary, ban.Community, banListID, ban.BannedAt, now)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE federated_bans SET server_ban_id = $1 WHERE id = $2", serverBanID, ban.ID)
+	return err
+}
+
+// deleteFederatedBan forgets a federated ban and its enforced copy
+func deleteFederatedBan(ctx context.Context, tx *sql.Tx, ban *models.FederatedBan) error {
+	if ban.ServerBanID != nil {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM server_bans WHERE id = $1", *ban.ServerBanID); err != nil {
+			return err
+		}
+	}
+	_, err := tx.ExecContext(ctx, "DELETE FROM federated_bans WHERE id = $1", ban.ID)
+	return err
+}
+
+// peerBanList returns the ban list holding the enforced bans of a peer,
+// creating it on the first sync
+func (s *FederationSyncService) peerBanList(ctx context.Context, tx *sql.Tx, peer *models.FederationPeer) (uuid.UUID, error) {
+	if peer.BanListID != nil {
+		var exists bool
+		err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM ban_lists WHERE id = $1)", *peer.BanListID).Scan(&exists)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if exists {
+			return *peer.BanListID, nil
+		}
+	}
+
+	banList := &models.BanList{
+		ID:          uuid.New(),
+		Name:        fmt.Sprintf("Federated: %s", peer.Name),
+		Description: &[]string{fmt.Sprintf("Bans shared by %s through federation", peer.Name)}[0],
+		IsRemote:    true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if _, err := CreateBanList(ctx, tx, banList); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE federation_peers SET ban_list_id = $1 WHERE id = $2", banList.ID, peer.ID); err != nil {
+		return uuid.Nil, err
+	}
+	peer.BanListID = &banList.ID
+
+	return banList.ID, nil
+}
+
+// StartPeriodicSync starts a background goroutine that periodically syncs federation peers
+func (s *FederationSyncService) StartPeriodicSync(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SyncAllPeers(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to sync federation peers")
+			}
+		}
+	}
+}
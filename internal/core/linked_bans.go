@@ -0,0 +1,164 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// GetLinkedBanSettings returns the linked identity ban policy of a server, or
+// the defaults when none has been saved yet
+func GetLinkedBanSettings(ctx context.Context, database db.Executor, serverId uuid.UUID) (*models.LinkedBanSettings, error) {
+	settings := &models.LinkedBanSettings{ServerID: serverId}
+	err := database.QueryRowContext(ctx, `
+		SELECT extend_by_default, action, min_confidence, updated_at
+		FROM server_linked_ban_settings
+		WHERE server_id = $1
+	`, serverId).Scan(&settings.ExtendByDefault, &settings.Action, &settings.MinConfidence, &settings.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		settings.ExtendByDefault = false
+		settings.Action = models.LinkedBanActionFlag
+		settings.MinConfidence = 75
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked ban settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// SaveLinkedBanSettings creates or replaces the linked identity ban policy of a server
+func SaveLinkedBanSettings(ctx context.Context, database db.Executor, settings *models.LinkedBanSettings) error {
+	err := database.QueryRowContext(ctx, `
+		INSERT INTO server_linked_ban_settings (server_id, extend_by_default, action, min_confidence, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (server_id) DO UPDATE SET
+			extend_by_default = EXCLUDED.extend_by_default,
+			action = EXCLUDED.action,
+			min_confidence = EXCLUDED.min_confidence,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, settings.ServerID, settings.ExtendByDefault, settings.Action, settings.MinConfidence).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save linked ban settings: %w", err)
+	}
+
+	return nil
+}
+
+// GetActiveBansForIdentifiers returns the active bans on a server, including
+// subscribed ban lists, of any of the given Steam and EOS IDs
+func GetActiveBansForIdentifiers(ctx context.Context, database db.Executor, serverID uuid.UUID, steamIDs, eosIDs []string) ([]models.ServerBan, error) {
+	steamInts := make([]int64, 0, len(steamIDs))
+	for _, id := range steamIDs {
+		if steamInt, err := strconv.ParseInt(id, 10, 64); err == nil {
+			steamInts = append(steamInts, steamInt)
+		}
+	}
+	if len(steamInts) == 0 && len(eosIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := database.QueryContext(ctx, `
+		SELECT sb.id, sb.steam_id, sb.eos_id, sb.reason, sb.duration, sb.extend_to_linked, sb.created_at
+		FROM server_bans sb
+		WHERE (sb.steam_id = ANY($1) OR sb.eos_id = ANY($2))
+		AND sb.lifted_at IS NULL
+		AND (sb.duration = 0 OR sb.created_at + (sb.duration || ' days')::interval > NOW())
+		AND (
+			sb.server_id = $3
+			OR sb.ban_list_id IN (
+				SELECT sbls.ban_list_id
+				FROM server_ban_list_subscriptions sbls
+				WHERE sbls.server_id = $3
+			)
+		)
+		ORDER BY sb.created_at DESC
+	`, pq.Array(steamInts), pq.Array(eosIDs), serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bans for identifiers: %w", err)
+	}
+	defer rows.Close()
+
+	bans := []models.ServerBan{}
+	for rows.Next() {
+		var ban models.ServerBan
+		var steamID sql.NullInt64
+		var eosID sql.NullString
+		var extendToLinked sql.NullBool
+		if err := rows.Scan(&ban.ID, &steamID, &eosID, &ban.Reason, &ban.Duration, &extendToLinked, &ban.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ban: %w", err)
+		}
+		if steamID.Valid {
+			ban.SteamID = strconv.FormatInt(steamID.Int64, 10)
+		}
+		ban.EOSID = eosID.String
+		if extendToLinked.Valid {
+			ban.ExtendToLinked = &extendToLinked.Bool
+		}
+		ban.ServerID = serverID
+		ban.Permanent = ban.Duration == 0
+		if !ban.Permanent {
+			ban.ExpiresAt = ban.CreatedAt.AddDate(0, 0, ban.Duration)
+		}
+		bans = append(bans, ban)
+	}
+
+	return bans, rows.Err()
+}
+
+// CreateBanIdentityMatch records a player matched to a ban through a linked identity
+func CreateBanIdentityMatch(ctx context.Context, database db.Executor, match *models.BanIdentityMatch) error {
+	match.ID = uuid.New()
+	err := database.QueryRowContext(ctx, `
+		INSERT INTO ban_identity_matches (id, server_id, ban_id, steam_id, eos_id, player_name, banned_identifier,
+			confidence, links, action, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING created_at
+	`, match.ID, match.ServerID, match.BanID, match.SteamID, match.EOSID, match.PlayerName, match.BannedIdentifier,
+		match.Confidence, []byte(match.Links), match.Action).Scan(&match.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create ban identity match: %w", err)
+	}
+
+	return nil
+}
+
+// GetBanIdentityMatches returns the most recent linked identity matches of a server
+func GetBanIdentityMatches(ctx context.Context, database db.Executor, serverId uuid.UUID, limit int) ([]*models.BanIdentityMatch, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT m.id, m.server_id, m.ban_id, m.steam_id, m.eos_id, m.player_name, m.banned_identifier, sb.reason,
+			m.confidence, m.links, m.action, m.created_at
+		FROM ban_identity_matches m
+		JOIN server_bans sb ON m.ban_id = sb.id
+		WHERE m.server_id = $1
+		ORDER BY m.created_at DESC
+		LIMIT $2
+	`, serverId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban identity matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []*models.BanIdentityMatch{}
+	for rows.Next() {
+		match := &models.BanIdentityMatch{}
+		var links []byte
+		if err := rows.Scan(&match.ID, &match.ServerID, &match.BanID, &match.SteamID, &match.EOSID, &match.PlayerName,
+			&match.BannedIdentifier, &match.BanReason, &match.Confidence, &links, &match.Action, &match.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ban identity match: %w", err)
+		}
+		match.Links = links
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
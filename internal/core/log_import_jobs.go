@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+const logImportJobColumns = `id, server_id, files, status, lines_processed, events_parsed, events_inserted,
+	events_skipped, error, created_by, created_at, started_at, finished_at`
+
+// CreateLogImportJob stores a new pending log import job
+func CreateLogImportJob(ctx context.Context, database db.Executor, job *models.LogImportJob) error {
+	files, err := json.Marshal(job.Files)
+	if err != nil {
+		return fmt.Errorf("failed to encode log import files: %w", err)
+	}
+
+	err = database.QueryRowContext(ctx, `
+		INSERT INTO log_import_jobs (id, server_id, files, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING status, created_at
+	`, job.ID, job.ServerID, files, job.CreatedBy).Scan(&job.Status, &job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create log import job: %w", err)
+	}
+
+	return nil
+}
+
+// GetLogImportJob returns a log import job by ID
+func GetLogImportJob(ctx context.Context, database db.Executor, jobId uuid.UUID) (*models.LogImportJob, error) {
+	row := database.QueryRowContext(ctx, `SELECT `+logImportJobColumns+` FROM log_import_jobs WHERE id = $1`, jobId)
+	return scanLogImportJob(row)
+}
+
+// ListLogImportJobs returns the most recent log import jobs, optionally for a single server
+func ListLogImportJobs(ctx context.Context, database db.Executor, serverId *uuid.UUID, limit int) ([]*models.LogImportJob, error) {
+	query := `SELECT ` + logImportJobColumns + ` FROM log_import_jobs`
+	args := []interface{}{}
+	if serverId != nil {
+		query += ` WHERE server_id = $1`
+		args = append(args, *serverId)
+	}
+	query += fmt.Sprintf(` ORDER BY created_at DESC LIMIT %d`, limit)
+
+	rows, err := database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log import jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*models.LogImportJob{}
+	for rows.Next() {
+		job, err := scanLogImportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// StartLogImportJob marks a pending log import job as running
+func StartLogImportJob(ctx context.Context, database db.Executor, jobId uuid.UUID) error {
+	_, err := database.ExecContext(ctx, `
+		UPDATE log_import_jobs SET status = 'running', started_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, jobId)
+	if err != nil {
+		return fmt.Errorf("failed to start log import job: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateLogImportJobProgress records the counters of a running log import job
+func UpdateLogImportJobProgress(ctx context.Context, database db.Executor, job *models.LogImportJob) error {
+	_, err := database.ExecContext(ctx, `
+		UPDATE log_import_jobs
+		SET lines_processed = $1, events_parsed = $2, events_inserted = $3, events_skipped = $4
+		WHERE id = $5
+	`, job.LinesProcessed, job.EventsParsed, job.EventsInserted, job.EventsSkipped, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update log import job progress: %w", err)
+	}
+
+	return nil
+}
+
+// FinishLogImportJob records the final status and counters of a log import job
+func FinishLogImportJob(ctx context.Context, database db.Executor, job *models.LogImportJob) error {
+	_, err := database.ExecContext(ctx, `
+		UPDATE log_import_jobs
+		SET status = $1, error = $2, lines_processed = $3, events_parsed = $4, events_inserted = $5,
+			events_skipped = $6, finished_at = NOW()
+		WHERE id = $7
+	`, job.Status, job.Error, job.LinesProcessed, job.EventsParsed, job.EventsInserted, job.EventsSkipped, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to finish log import job: %w", err)
+	}
+
+	return nil
+}
+
+// FailInterruptedLogImportJobs fails jobs that were left pending or running
+// by a previous process
+func FailInterruptedLogImportJobs(ctx context.Context, database db.Executor) (int64, error) {
+	result, err := database.ExecContext(ctx, `
+		UPDATE log_import_jobs
+		SET status = 'failed', error = 'interrupted by a restart', finished_at = NOW()
+		WHERE status IN ('pending', 'running')
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted log import jobs: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLogImportJob(row rowScanner) (*models.LogImportJob, error) {
+	job := &models.LogImportJob{}
+	var files []byte
+	err := row.Scan(&job.ID, &job.ServerID, &files, &job.Status, &job.LinesProcessed, &job.EventsParsed,
+		&job.EventsInserted, &job.EventsSkipped, &job.Error, &job.CreatedBy, &job.CreatedAt, &job.StartedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(files, &job.Files); err != nil {
+		return nil, fmt.Errorf("failed to decode log import files: %w", err)
+	}
+
+	return job, nil
+}
@@ -2,22 +2,39 @@ package core
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/ed25519"
 	"database/sql"
+	"encoding/base64"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 	"go.codycody31.dev/squad-aegis/internal/db"
 	"go.codycody31.dev/squad-aegis/internal/models"
 )
 
+const (
+	// maxRemoteBanFeedSize bounds the size of a remote ban feed
+	maxRemoteBanFeedSize = 32 << 20
+	// maxRemoteBanSignatureSize bounds the size of a detached signature file
+	maxRemoteBanSignatureSize = 4 << 10
+)
+
+var remoteEOSIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
 type RemoteBanSyncService struct {
 	database   db.Executor
 	dbInstance *sql.DB // Keep reference to the database instance for transactions
@@ -50,35 +67,53 @@ func (s *RemoteBanSyncService) SyncAllSources(ctx context.Context) error {
 			}
 		}
 
-		err := s.SyncSource(ctx, source)
+		run, err := s.SyncSource(ctx, source)
 		if err != nil {
 			log.Error().Err(err).Str("source", source.Name).Msg("Failed to sync remote ban source")
 
+			run.Status = models.RemoteBanSyncError
+			message := err.Error()
+			run.Error = &message
+
 			// Update sync status with error
 			updateData := map[string]interface{}{
 				"last_synced_at":   time.Now(),
-				"last_sync_status": "error",
-				"last_sync_error":  err.Error(),
+				"last_sync_status": run.Status,
+				"last_sync_error":  message,
 			}
 			UpdateRemoteBanSource(ctx, s.database, source.ID, updateData)
 		} else {
-			log.Info().Str("source", source.Name).Msg("Successfully synced remote ban source")
+			log.Info().Str("source", source.Name).Str("status", run.Status).Msg("Successfully synced remote ban source")
 
 			// Update sync status with success
 			updateData := map[string]interface{}{
 				"last_synced_at":   time.Now(),
-				"last_sync_status": "success",
+				"last_sync_status": run.Status,
 				"last_sync_error":  nil,
 			}
 			UpdateRemoteBanSource(ctx, s.database, source.ID, updateData)
 		}
+
+		if err := CreateRemoteBanSyncRun(ctx, s.database, run); err != nil {
+			log.Error().Err(err).Str("source", source.Name).Msg("Failed to record remote ban sync")
+		}
 	}
 
 	return nil
 }
 
-// SyncSource syncs a specific remote ban source
-func (s *RemoteBanSyncService) SyncSource(ctx context.Context, source *models.RemoteBanSource) error {
+// SyncSource syncs a specific remote ban source. The feed is only downloaded
+// when it changed since the last sync, and only the differences are applied
+// to the ban list of the source. The returned run is never nil, so failed
+// syncs can be recorded too.
+func (s *RemoteBanSyncService) SyncSource(ctx context.Context, source *models.RemoteBanSource) (*models.RemoteBanSyncRun, error) {
+	run := &models.RemoteBanSyncRun{
+		ID:        uuid.New(),
+		SourceID:  source.ID,
+		StartedAt: time.Now(),
+	}
+	defer func() { run.FinishedAt = time.Now() }()
+
 	log.Info().Str("source", source.Name).Str("url", source.URL).Msg("Starting sync of remote ban source")
 
 	// Create HTTP client with timeout
@@ -86,51 +121,205 @@ func (s *RemoteBanSyncService) SyncSource(ctx context.Context, source *models.Re
 		Timeout: 60 * time.Second,
 	}
 
-	resp, err := client.Get(source.URL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+	if err != nil {
+		return run, fmt.Errorf("invalid source URL: %w", err)
+	}
+	if source.ETag != nil && *source.ETag != "" {
+		req.Header.Set("If-None-Match", *source.ETag)
+	}
+	if source.LastModified != nil && *source.LastModified != "" {
+		req.Header.Set("If-Modified-Since", *source.LastModified)
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to fetch from %s: %w", source.URL, err)
+		return run, fmt.Errorf("failed to fetch from %s: %w", source.URL, err)
 	}
 	defer resp.Body.Close()
 
+	run.HTTPStatus = &resp.StatusCode
+
+	if resp.StatusCode == http.StatusNotModified {
+		run.Status = models.RemoteBanSyncNotModified
+		return run, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, source.URL)
+		return run, fmt.Errorf("HTTP %d from %s", resp.StatusCode, source.URL)
 	}
 
-	// Get or create a remote ban list for this source
-	banList, err := s.getOrCreateRemoteBanList(ctx, source)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteBanFeedSize+1))
 	if err != nil {
-		return fmt.Errorf("failed to get or create ban list: %w", err)
+		return run, fmt.Errorf("failed to read from %s: %w", source.URL, err)
+	}
+	if len(body) > maxRemoteBanFeedSize {
+		return run, fmt.Errorf("feed is larger than %d bytes", maxRemoteBanFeedSize)
 	}
 
-	// Determine format and process bans
-	contentType := resp.Header.Get("Content-Type")
+	// A tampered feed must not touch the ban list, so verify before parsing
+	if source.PublicKey != nil && *source.PublicKey != "" {
+		if err := s.verifyFeedSignature(ctx, client, source, body); err != nil {
+			return run, err
+		}
+		run.SignatureVerified = true
+	}
 
+	// Determine format and process bans
 	var bans []RemoteBan
-	if strings.Contains(contentType, "text/csv") || strings.HasSuffix(source.URL, ".csv") {
-		bans, err = s.parseCSVBans(resp.Body)
-	} else {
-		bans, err = s.parseTextBans(resp.Body)
+	switch remoteBanFeedFormat(source, resp.Header.Get("Content-Type")) {
+	case models.RemoteBanFormatJSON:
+		bans, err = s.parseJSONBans(body)
+	case models.RemoteBanFormatCSV:
+		bans, err = s.parseCSVBans(bytes.NewReader(body))
+	default:
+		bans, err = s.parseTextBans(bytes.NewReader(body))
+	}
+	if err != nil {
+		return run, fmt.Errorf("failed to parse bans: %w", err)
 	}
 
+	// Get or create a remote ban list for this source
+	banList, err := s.getOrCreateRemoteBanList(ctx, source)
 	if err != nil {
-		return fmt.Errorf("failed to parse bans: %w", err)
+		return run, fmt.Errorf("failed to get or create ban list: %w", err)
 	}
 
-	// Clear existing bans from this ban list and add new ones
-	err = s.updateBanListBans(ctx, banList.ID, bans)
+	diff, err := s.updateBanListBans(ctx, banList.ID, bans)
 	if err != nil {
-		return fmt.Errorf("failed to update ban list: %w", err)
+		return run, fmt.Errorf("failed to update ban list: %w", err)
+	}
+	run.Status = models.RemoteBanSyncSuccess
+	run.Total = diff.Total
+	run.Added = diff.Added
+	run.Removed = diff.Removed
+	run.Changed = diff.Changed
+
+	// Only remember the validators once the feed is applied, so a failed
+	// sync downloads the feed again
+	if err := UpdateRemoteBanSource(ctx, s.database, source.ID, map[string]interface{}{
+		"etag":          nullIfEmpty(resp.Header.Get("ETag")),
+		"last_modified": nullIfEmpty(resp.Header.Get("Last-Modified")),
+	}); err != nil {
+		log.Warn().Err(err).Str("source", source.Name).Msg("Failed to store remote ban feed validators")
 	}
 
-	log.Info().Str("source", source.Name).Int("bans_count", len(bans)).Msg("Successfully synced remote bans")
-	return nil
+	log.Info().
+		Str("source", source.Name).
+		Int("bans_count", diff.Total).
+		Int("added", diff.Added).
+		Int("removed", diff.Removed).
+		Int("changed", diff.Changed).
+		Msg("Successfully synced remote bans")
+	return run, nil
 }
 
 type RemoteBan struct {
-	SteamID   string
-	Reason    string
-	Duration  int // 0 for permanent, minutes for temporary
+	SteamID  string
+	EOSID    string
+	Reason   string
+	IssuedBy string
+	Evidence []string
+	// CreatedAt is zero when the source doesn't say when the ban was issued
 	CreatedAt time.Time
+	// ExpiresAt is nil for permanent bans
+	ExpiresAt *time.Time
+}
+
+// key identifies a ban within a feed, by its Steam ID or else its EOS ID
+func (b *RemoteBan) key() string {
+	if b.SteamID != "" {
+		return "steam:" + b.SteamID
+	}
+	return "eos:" + b.EOSID
+}
+
+// remoteBanJSONEntry is a ban in the JSON feed format
+type remoteBanJSONEntry struct {
+	SteamID   string     `json:"steam_id"`
+	EOSID     string     `json:"eos_id"`
+	Reason    string     `json:"reason"`
+	Issuer    string     `json:"issuer"`
+	Evidence  []string   `json:"evidence"`
+	CreatedAt *time.Time `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ParseRemoteBanPublicKey decodes the base64 Ed25519 public key of a remote ban source
+func ParseRemoteBanPublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("public key is not valid base64: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be a %d byte Ed25519 key", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// verifyFeedSignature checks the detached Ed25519 signature of a feed. The
+// signature file holds the 64 byte signature, raw or base64 encoded.
+func (s *RemoteBanSyncService) verifyFeedSignature(ctx context.Context, client *http.Client, source *models.RemoteBanSource, body []byte) error {
+	publicKey, err := ParseRemoteBanPublicKey(*source.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	signatureURL := source.URL + ".sig"
+	if source.SignatureURL != nil && *source.SignatureURL != "" {
+		signatureURL = *source.SignatureURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signatureURL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid signature URL: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature from %s: %w", signatureURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d fetching signature from %s", resp.StatusCode, signatureURL)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteBanSignatureSize))
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	signature := raw
+	if len(raw) != ed25519.SignatureSize {
+		signature, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(raw)))
+		if err != nil || len(signature) != ed25519.SignatureSize {
+			return errors.New("signature is not a raw or base64 Ed25519 signature")
+		}
+	}
+
+	if !ed25519.Verify(publicKey, body, signature) {
+		return errors.New("signature verification failed, the feed was rejected")
+	}
+
+	return nil
+}
+
+// remoteBanFeedFormat returns the format of a feed, detecting it from the
+// content type and URL for sources set to auto
+func remoteBanFeedFormat(source *models.RemoteBanSource, contentType string) string {
+	if source.Format != "" && source.Format != models.RemoteBanFormatAuto {
+		return source.Format
+	}
+
+	url := strings.ToLower(source.URL)
+	switch {
+	case strings.Contains(contentType, "json") || strings.HasSuffix(url, ".json"):
+		return models.RemoteBanFormatJSON
+	case strings.Contains(contentType, "text/csv") || strings.HasSuffix(url, ".csv"):
+		return models.RemoteBanFormatCSV
+	default:
+		return models.RemoteBanFormatText
+	}
 }
 
 func (s *RemoteBanSyncService) getOrCreateRemoteBanList(ctx context.Context, source *models.RemoteBanSource) (*models.BanList, error) {
@@ -161,6 +350,66 @@ func (s *RemoteBanSyncService) getOrCreateRemoteBanList(ctx context.Context, sou
 	return CreateBanList(ctx, s.database, banList)
 }
 
+// parseJSONBans parses the JSON feed format, either an object with a bans
+// array or a bare array of bans
+func (s *RemoteBanSyncService) parseJSONBans(body []byte) ([]RemoteBan, error) {
+	var entries []remoteBanJSONEntry
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, err
+		}
+	} else {
+		var feed struct {
+			Bans []remoteBanJSONEntry `json:"bans"`
+		}
+		if err := json.Unmarshal(trimmed, &feed); err != nil {
+			return nil, err
+		}
+		entries = feed.Bans
+	}
+
+	now := time.Now()
+	var bans []RemoteBan
+	for _, entry := range entries {
+		ban := RemoteBan{
+			SteamID:   strings.TrimSpace(entry.SteamID),
+			EOSID:     strings.ToLower(strings.TrimSpace(entry.EOSID)),
+			Reason:    strings.TrimSpace(entry.Reason),
+			IssuedBy:  strings.TrimSpace(entry.Issuer),
+			ExpiresAt: entry.ExpiresAt,
+		}
+		if ban.Reason == "" {
+			ban.Reason = "Remote ban"
+		}
+		if entry.CreatedAt != nil {
+			ban.CreatedAt = *entry.CreatedAt
+		}
+		for _, link := range entry.Evidence {
+			if link = strings.TrimSpace(link); link != "" {
+				ban.Evidence = append(ban.Evidence, link)
+			}
+		}
+
+		// Skip bans without a valid ID, and bans that already expired
+		if ban.SteamID != "" && !isRemoteSteamID(ban.SteamID) {
+			continue
+		}
+		if ban.EOSID != "" && !remoteEOSIDPattern.MatchString(ban.EOSID) {
+			continue
+		}
+		if ban.SteamID == "" && ban.EOSID == "" {
+			continue
+		}
+		if ban.ExpiresAt != nil && now.After(*ban.ExpiresAt) {
+			continue
+		}
+
+		bans = append(bans, ban)
+	}
+
+	return bans, nil
+}
+
 func (s *RemoteBanSyncService) parseCSVBans(body io.Reader) ([]RemoteBan, error) {
 	reader := csv.NewReader(body)
 	var bans []RemoteBan
@@ -184,15 +433,13 @@ func (s *RemoteBanSyncService) parseCSVBans(body io.Reader) ([]RemoteBan, error)
 		}
 
 		// Validate Steam ID format (basic check)
-		if len(steamIDStr) < 10 {
+		if !isRemoteSteamID(steamIDStr) {
 			continue // Skip invalid steam IDs
 		}
 
 		ban := RemoteBan{
-			SteamID:   steamIDStr,
-			Reason:    "Remote ban",
-			Duration:  0, // Default to permanent
-			CreatedAt: time.Now(),
+			SteamID: steamIDStr,
+			Reason:  "Remote ban",
 		}
 
 		// Try to parse reason if available
@@ -206,7 +453,7 @@ func (s *RemoteBanSyncService) parseCSVBans(body io.Reader) ([]RemoteBan, error)
 				if time.Now().After(expiryTime) {
 					continue // Skip expired bans
 				}
-				ban.Duration = int(time.Until(expiryTime).Minutes())
+				ban.ExpiresAt = &expiryTime
 			}
 		}
 
@@ -235,15 +482,13 @@ func (s *RemoteBanSyncService) parseTextBans(body io.Reader) ([]RemoteBan, error
 		expiryStr := strings.TrimSpace(parts[1])
 
 		// Validate Steam ID format (basic check)
-		if len(steamIDStr) < 10 {
+		if !isRemoteSteamID(steamIDStr) {
 			continue
 		}
 
 		ban := RemoteBan{
-			SteamID:   steamIDStr,
-			Reason:    "Remote ban",
-			Duration:  0, // Default to permanent
-			CreatedAt: time.Now(),
+			SteamID: steamIDStr,
+			Reason:  "Remote ban",
 		}
 
 		// Parse expiry
@@ -253,7 +498,7 @@ func (s *RemoteBanSyncService) parseTextBans(body io.Reader) ([]RemoteBan, error
 				if time.Now().After(expiryTimestamp) {
 					continue // Skip expired bans
 				}
-				ban.Duration = int(time.Until(expiryTimestamp).Minutes())
+				ban.ExpiresAt = &expiryTimestamp
 			}
 		}
 
@@ -263,43 +508,269 @@ func (s *RemoteBanSyncService) parseTextBans(body io.Reader) ([]RemoteBan, error
 	return bans, scanner.Err()
 }
 
-func (s *RemoteBanSyncService) updateBanListBans(ctx context.Context, banListID uuid.UUID, bans []RemoteBan) error {
+// isRemoteSteamID checks that a Steam ID from a feed fits the steam_id column
+func isRemoteSteamID(steamID string) bool {
+	if len(steamID) < 10 {
+		return false
+	}
+	_, err := strconv.ParseInt(steamID, 10, 64)
+	return err == nil
+}
+
+// remoteBanDiff counts the changes a sync applied to a ban list
+type remoteBanDiff struct {
+	Total, Added, Removed, Changed int
+}
+
+// storedRemoteBan is a ban of a remote ban list as it is stored
+type storedRemoteBan struct {
+	ID           uuid.UUID
+	SteamID      sql.NullInt64
+	EOSID        sql.NullString
+	Reason       string
+	Duration     int
+	EvidenceText sql.NullString
+	IssuedBy     sql.NullString
+	CreatedAt    time.Time
+}
+
+func (b *storedRemoteBan) key() string {
+	if b.SteamID.Valid {
+		return "steam:" + strconv.FormatInt(b.SteamID.Int64, 10)
+	}
+	return "eos:" + b.EOSID.String
+}
+
+// remoteBanDurationDays converts the expiry of a ban to the duration in days
+// stored with it, counted from when the ban was created. Zero is permanent,
+// so an expiring ban lasts at least a day.
+func remoteBanDurationDays(createdAt time.Time, expiresAt *time.Time) int {
+	if expiresAt == nil {
+		return 0
+	}
+	return max(1, int(math.Ceil(expiresAt.Sub(createdAt).Hours()/24)))
+}
+
+// updateBanListBans applies the bans of a feed to a remote ban list as a diff,
+// so unchanged bans keep their IDs and creation dates
+func (s *RemoteBanSyncService) updateBanListBans(ctx context.Context, banListID uuid.UUID, bans []RemoteBan) (*remoteBanDiff, error) {
+	ignored, err := GetIgnoredSteamIDs(ctx, s.database)
+	if err != nil {
+		// Continue processing even if the check fails, to avoid losing legitimate bans
+		log.Warn().Err(err).Msg("Failed to get ignored Steam IDs, including all bans")
+	}
+	ignoredSteamIDs := make(map[string]bool, len(ignored))
+	for _, id := range ignored {
+		ignoredSteamIDs[id.SteamID] = true
+	}
+
 	// Start transaction
 	tx, err := s.dbInstance.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback()
 
-	// Delete existing bans from this ban list
-	_, err = tx.ExecContext(ctx, "DELETE FROM server_bans WHERE ban_list_id = $1", banListID)
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, steam_id, eos_id, reason, duration, evidence_text, issued_by, created_at
+		FROM server_bans
+		WHERE ban_list_id = $1
+		FOR UPDATE
+	`, banListID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var stored []*storedRemoteBan
+	for rows.Next() {
+		ban := &storedRemoteBan{}
+		if err := rows.Scan(&ban.ID, &ban.SteamID, &ban.EOSID, &ban.Reason, &ban.Duration,
+			&ban.EvidenceText, &ban.IssuedBy, &ban.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stored = append(stored, ban)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	// Insert new bans, filtering out ignored Steam IDs
-	for _, ban := range bans {
-		// Check if this Steam ID is in the ignore list
-		isIgnored, err := IsIgnoredSteamID(ctx, s.database, ban.SteamID)
-		if err != nil {
-			log.Warn().Err(err).Str("steam_id", ban.SteamID).Msg("Failed to check if Steam ID is ignored, including ban anyway")
-			// Continue processing even if check fails, to avoid losing legitimate bans
-		} else if isIgnored {
-			log.Info().Str("steam_id", ban.SteamID).Msg("Skipping banned Steam ID - found in ignore list")
-			continue
+	changes := diffRemoteBans(stored, bans, ignoredSteamIDs)
+	now := time.Now()
+
+	for _, ban := range changes.add {
+		var steamID interface{}
+		if ban.SteamID != "" {
+			steamID = ban.SteamID
+		}
+		createdAt := ban.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
 		}
 
 		// For remote bans, use NULL for admin_id and server_id since they don't apply to a specific server/admin
 		_, err = tx.ExecContext(ctx, `
-			INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, ban_list_id, created_at, updated_at)
-			VALUES ($1, NULL, NULL, $2, $3, $4, $5, $6, $7)
-		`, uuid.New(), ban.SteamID, ban.Reason, ban.Duration, banListID, ban.CreatedAt, ban.CreatedAt)
+			INSERT INTO server_bans (id, server_id, admin_id, steam_id, eos_id, reason, duration, evidence_text,
+				issued_by, ban_list_id, created_at, updated_at)
+			VALUES ($1, NULL, NULL, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, uuid.New(), steamID, nullIfEmpty(ban.EOSID), ban.Reason, remoteBanDurationDays(createdAt, ban.ExpiresAt),
+			nullIfEmpty(strings.Join(ban.Evidence, "\n")), nullIfEmpty(ban.IssuedBy), banListID, createdAt, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, update := range changes.update {
+		ban := update.ban
+		_, err = tx.ExecContext(ctx, `
+			UPDATE server_bans
+			SET eos_id = $1, reason = $2, duration = $3, evidence_text = $4, issued_by = $5, updated_at = $6
+			WHERE id = $7
+		`, nullIfEmpty(ban.EOSID), ban.Reason, update.duration, nullIfEmpty(strings.Join(ban.Evidence, "\n")),
+			nullIfEmpty(ban.IssuedBy), now, update.id)
 		if err != nil {
-			return err
+			return nil, err
+		}
+	}
+
+	if len(changes.remove) > 0 {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM server_bans WHERE id = ANY($1::uuid[])", pq.Array(changes.remove)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &changes.diff, nil
+}
+
+// remoteBanChanges is what a sync changes in a remote ban list
+type remoteBanChanges struct {
+	diff   remoteBanDiff
+	add    []RemoteBan
+	update []remoteBanUpdate
+	// remove holds the IDs of bans no longer in the feed and of duplicates
+	remove []string
+}
+
+// remoteBanUpdate is a stored ban that differs from its ban in the feed
+type remoteBanUpdate struct {
+	id       uuid.UUID
+	ban      RemoteBan
+	duration int
+}
+
+// diffRemoteBans compares the bans stored for a remote ban list with the bans
+// of its feed. Bans of ignored Steam IDs and repeated bans of the feed are skipped.
+func diffRemoteBans(stored []*storedRemoteBan, bans []RemoteBan, ignoredSteamIDs map[string]bool) *remoteBanChanges {
+	changes := &remoteBanChanges{}
+
+	existing := make(map[string]*storedRemoteBan, len(stored))
+	for _, ban := range stored {
+		// Lists synced by full replacement may hold a ban twice
+		if _, ok := existing[ban.key()]; ok {
+			changes.remove = append(changes.remove, ban.ID.String())
+			continue
+		}
+		existing[ban.key()] = ban
+	}
+
+	seen := make(map[string]bool, len(bans))
+	for _, ban := range bans {
+		if ban.SteamID != "" && ignoredSteamIDs[ban.SteamID] {
+			log.Info().Str("steam_id", ban.SteamID).Msg("Skipping banned Steam ID - found in ignore list")
+			continue
+		}
+
+		key := ban.key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		changes.diff.Total++
+
+		stored, ok := existing[key]
+		if !ok {
+			changes.add = append(changes.add, ban)
+			changes.diff.Added++
+			continue
+		}
+		delete(existing, key)
+
+		duration := remoteBanDurationDays(stored.CreatedAt, ban.ExpiresAt)
+		if stored.EOSID.String == ban.EOSID &&
+			stored.Reason == ban.Reason &&
+			stored.Duration == duration &&
+			stored.EvidenceText.String == strings.Join(ban.Evidence, "\n") &&
+			stored.IssuedBy.String == ban.IssuedBy {
+			continue
+		}
+
+		changes.update = append(changes.update, remoteBanUpdate{id: stored.ID, ban: ban, duration: duration})
+		changes.diff.Changed++
+	}
+
+	// Whatever is left is no longer in the feed. Duplicates are cleanup, not
+	// bans that left the feed, so they aren't counted as removed.
+	for _, ban := range existing {
+		changes.remove = append(changes.remove, ban.ID.String())
+	}
+	changes.diff.Removed = len(existing)
+
+	return changes
+}
+
+// nullIfEmpty stores empty strings as NULL
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// CreateRemoteBanSyncRun records a sync of a remote ban source
+func CreateRemoteBanSyncRun(ctx context.Context, database db.Executor, run *models.RemoteBanSyncRun) error {
+	_, err := database.ExecContext(ctx, `
+		INSERT INTO remote_ban_sync_runs (id, source_id, status, http_status, total, added, removed, changed,
+			signature_verified, error, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, run.ID, run.SourceID, run.Status, run.HTTPStatus, run.Total, run.Added, run.Removed, run.Changed,
+		run.SignatureVerified, run.Error, run.StartedAt, run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create remote ban sync run: %w", err)
+	}
+
+	return nil
+}
+
+// GetRemoteBanSyncRuns returns the most recent syncs of a remote ban source
+func GetRemoteBanSyncRuns(ctx context.Context, database db.Executor, sourceId uuid.UUID, limit int) ([]*models.RemoteBanSyncRun, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT id, source_id, status, http_status, total, added, removed, changed, signature_verified, error,
+			started_at, finished_at
+		FROM remote_ban_sync_runs
+		WHERE source_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, sourceId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote ban sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*models.RemoteBanSyncRun{}
+	for rows.Next() {
+		run := &models.RemoteBanSyncRun{}
+		if err := rows.Scan(&run.ID, &run.SourceID, &run.Status, &run.HTTPStatus, &run.Total, &run.Added, &run.Removed,
+			&run.Changed, &run.SignatureVerified, &run.Error, &run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan remote ban sync run: %w", err)
 		}
+		runs = append(runs, run)
 	}
 
-	return tx.Commit()
+	return runs, rows.Err()
 }
 
 // StartPeriodicSync starts a background goroutine that periodically syncs remote ban sources
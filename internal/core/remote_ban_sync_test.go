@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func TestParseJSONBans(t *testing.T) {
+	s := &RemoteBanSyncService{}
+	expires := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	body := []byte(`{"bans": [
+		{"steam_id": "76561198000000001", "reason": "  Cheating ", "issuer": "Admin", "evidence": ["https://example.com/1", " "]},
+		{"eos_id": "0123456789ABCDEF0123456789ABCDEF", "expires_at": "` + expires + `"},
+		{"steam_id": "76561198000000002", "expires_at": "` + expired + `"},
+		{"steam_id": "not-a-steam-id"},
+		{"eos_id": "tooshort"},
+		{"reason": "No ID"}
+	]}`)
+
+	bans, err := s.parseJSONBans(body)
+	if err != nil {
+		t.Fatalf("Failed to parse feed: %v", err)
+	}
+	if len(bans) != 2 {
+		t.Fatalf("Expected 2 bans, got %d: %+v", len(bans), bans)
+	}
+
+	if bans[0].SteamID != "76561198000000001" || bans[0].Reason != "Cheating" || bans[0].IssuedBy != "Admin" {
+		t.Errorf("Unexpected Steam ban: %+v", bans[0])
+	}
+	if len(bans[0].Evidence) != 1 || bans[0].ExpiresAt != nil {
+		t.Errorf("Expected one evidence link and a permanent ban, got %+v", bans[0])
+	}
+
+	eosBan := bans[1]
+	if eosBan.SteamID != "" || eosBan.EOSID != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("Expected an EOS only ban with a lowercase ID, got %+v", eosBan)
+	}
+	if eosBan.Reason != "Remote ban" {
+		t.Errorf("Expected the default reason, got %q", eosBan.Reason)
+	}
+	if eosBan.ExpiresAt == nil || eosBan.ExpiresAt.Format(time.RFC3339) != expires {
+		t.Errorf("Expected the ban to expire at %s, got %v", expires, eosBan.ExpiresAt)
+	}
+
+	// A bare array is accepted as well
+	bans, err = s.parseJSONBans([]byte(`[{"steam_id": "76561198000000003"}]`))
+	if err != nil || len(bans) != 1 {
+		t.Errorf("Expected one ban from a bare array, got %+v, %v", bans, err)
+	}
+}
+
+func TestDiffRemoteBans(t *testing.T) {
+	createdAt := time.Now().Add(-24 * time.Hour)
+	stored := func(steamID int64, eosID, reason string) *storedRemoteBan {
+		ban := &storedRemoteBan{ID: uuid.New(), Reason: reason, CreatedAt: createdAt}
+		if steamID != 0 {
+			ban.SteamID = sql.NullInt64{Int64: steamID, Valid: true}
+		}
+		if eosID != "" {
+			ban.EOSID = sql.NullString{String: eosID, Valid: true}
+		}
+		return ban
+	}
+
+	unchanged := stored(76561198000000001, "", "Cheating")
+	changed := stored(76561198000000002, "", "Teamkilling")
+	gone := stored(0, "0123456789abcdef0123456789abcdef", "Griefing")
+	duplicate := stored(76561198000000001, "", "Cheating")
+
+	bans := []RemoteBan{
+		{SteamID: "76561198000000001", Reason: "Cheating"},
+		{SteamID: "76561198000000002", Reason: "Repeated teamkilling"},
+		{EOSID: "fedcba9876543210fedcba9876543210", Reason: "Racism"},
+		{EOSID: "fedcba9876543210fedcba9876543210", Reason: "Racism"},
+		{SteamID: "76561198000000009", Reason: "Ignored"},
+	}
+	ignored := map[string]bool{"76561198000000009": true}
+
+	changes := diffRemoteBans([]*storedRemoteBan{unchanged, changed, gone, duplicate}, bans, ignored)
+
+	if changes.diff != (remoteBanDiff{Total: 3, Added: 1, Removed: 1, Changed: 1}) {
+		t.Errorf("Unexpected diff: %+v", changes.diff)
+	}
+	if len(changes.add) != 1 || changes.add[0].EOSID != "fedcba9876543210fedcba9876543210" {
+		t.Errorf("Expected the EOS ban to be added, got %+v", changes.add)
+	}
+	if len(changes.update) != 1 || changes.update[0].id != changed.ID || changes.update[0].ban.Reason != "Repeated teamkilling" {
+		t.Errorf("Expected the changed ban to be updated, got %+v", changes.update)
+	}
+
+	// The duplicate is deleted without counting as removed
+	removed := map[string]bool{}
+	for _, id := range changes.remove {
+		removed[id] = true
+	}
+	if len(changes.remove) != 2 || !removed[gone.ID.String()] || !removed[duplicate.ID.String()] {
+		t.Errorf("Expected the missing ban and the duplicate to be deleted, got %v", changes.remove)
+	}
+}
+
+func TestVerifyFeedSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	body := []byte(`{"bans": [{"steam_id": "76561198000000001"}]}`)
+	signature := ed25519.Sign(privateKey, body)
+
+	var served []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(served)
+	}))
+	defer server.Close()
+
+	encodedKey := base64.StdEncoding.EncodeToString(publicKey)
+	source := &models.RemoteBanSource{URL: server.URL + "/bans.json", PublicKey: &encodedKey}
+	s := &RemoteBanSyncService{}
+	ctx := context.Background()
+
+	served = signature
+	if err := s.verifyFeedSignature(ctx, server.Client(), source, body); err != nil {
+		t.Errorf("Expected a raw signature to verify, got %v", err)
+	}
+
+	served = []byte(base64.StdEncoding.EncodeToString(signature) + "\n")
+	if err := s.verifyFeedSignature(ctx, server.Client(), source, body); err != nil {
+		t.Errorf("Expected a base64 signature to verify, got %v", err)
+	}
+
+	tampered := []byte(`{"bans": []}`)
+	if err := s.verifyFeedSignature(ctx, server.Client(), source, tampered); err == nil {
+		t.Errorf("Expected a tampered feed to be rejected")
+	}
+
+	served = []byte("not a signature!")
+	if err := s.verifyFeedSignature(ctx, server.Client(), source, body); err == nil {
+		t.Errorf("Expected a signature that is neither raw nor base64 to be rejected")
+	}
+}
@@ -0,0 +1,257 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// GetServerGroups returns every server group with its member servers
+func GetServerGroups(ctx context.Context, database db.Executor) ([]*models.ServerGroup, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT id, name, description, created_at, updated_at
+		FROM server_groups
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server groups: %w", err)
+	}
+	defer rows.Close()
+
+	return scanServerGroups(ctx, database, rows)
+}
+
+// GetUserServerGroups returns the server groups a user has been granted any
+// permission on
+func GetUserServerGroups(ctx context.Context, database db.Executor, userId uuid.UUID) ([]*models.ServerGroup, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT id, name, description, created_at, updated_at
+		FROM server_groups
+		WHERE id IN (SELECT group_id FROM server_group_permissions WHERE user_id = $1)
+		ORDER BY name
+	`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server groups: %w", err)
+	}
+	defer rows.Close()
+
+	return scanServerGroups(ctx, database, rows)
+}
+
+// GetServerGroup returns a single server group with its member servers
+func GetServerGroup(ctx context.Context, database db.Executor, groupId uuid.UUID) (*models.ServerGroup, error) {
+	group := &models.ServerGroup{}
+	err := database.QueryRowContext(ctx, `
+		SELECT id, name, description, created_at, updated_at
+		FROM server_groups
+		WHERE id = $1
+	`, groupId).Scan(&group.ID, &group.Name, &group.Description, &group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	group.ServerIDs, err = GetServerGroupServerIds(ctx, database, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// CreateServerGroup stores a new server group and its members
+func CreateServerGroup(ctx context.Context, database db.Executor, group *models.ServerGroup) error {
+	err := database.QueryRowContext(ctx, `
+		INSERT INTO server_groups (id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING created_at, updated_at
+	`, group.ID, group.Name, group.Description).Scan(&group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create server group: %w", err)
+	}
+
+	return SetServerGroupServers(ctx, database, group.ID, group.ServerIDs)
+}
+
+// UpdateServerGroup saves the name and description of a server group
+func UpdateServerGroup(ctx context.Context, database db.Executor, group *models.ServerGroup) error {
+	err := database.QueryRowContext(ctx, `
+		UPDATE server_groups
+		SET name = $1, description = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING updated_at
+	`, group.Name, group.Description, group.ID).Scan(&group.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update server group: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteServerGroup removes a server group, its members and permission grants
+func DeleteServerGroup(ctx context.Context, database db.Executor, groupId uuid.UUID) error {
+	_, err := database.ExecContext(ctx, `DELETE FROM server_groups WHERE id = $1`, groupId)
+	if err != nil {
+		return fmt.Errorf("failed to delete server group: %w", err)
+	}
+
+	return nil
+}
+
+// GetServerGroupServerIds returns the ids of the member servers of a group
+func GetServerGroupServerIds(ctx context.Context, database db.Executor, groupId uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT sgs.server_id
+		FROM server_group_servers sgs
+		JOIN servers s ON s.id = sgs.server_id
+		WHERE sgs.group_id = $1
+		ORDER BY s.name
+	`, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server group members: %w", err)
+	}
+	defer rows.Close()
+
+	serverIds := []uuid.UUID{}
+	for rows.Next() {
+		var serverId uuid.UUID
+		if err := rows.Scan(&serverId); err != nil {
+			return nil, err
+		}
+		serverIds = append(serverIds, serverId)
+	}
+
+	return serverIds, rows.Err()
+}
+
+// SetServerGroupServers replaces the member servers of a group
+func SetServerGroupServers(ctx context.Context, database db.Executor, groupId uuid.UUID, serverIds []uuid.UUID) error {
+	_, err := database.ExecContext(ctx, `DELETE FROM server_group_servers WHERE group_id = $1`, groupId)
+	if err != nil {
+		return fmt.Errorf("failed to clear server group members: %w", err)
+	}
+
+	for _, serverId := range serverIds {
+		_, err = database.ExecContext(ctx, `
+			INSERT INTO server_group_servers (group_id, server_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, groupId, serverId)
+		if err != nil {
+			return fmt.Errorf("failed to add server %s to group: %w", serverId, err)
+		}
+	}
+
+	return nil
+}
+
+// GetServerGroupPermissions returns the permission grants of every user on a group
+func GetServerGroupPermissions(ctx context.Context, database db.Executor, groupId uuid.UUID) ([]*models.ServerGroupUserPermissions, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT u.id, u.username, p.code
+		FROM server_group_permissions sgp
+		JOIN users u ON u.id = sgp.user_id
+		JOIN permissions p ON p.id = sgp.permission_id
+		WHERE sgp.group_id = $1
+		ORDER BY u.username, p.code
+	`, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server group permissions: %w", err)
+	}
+	defer rows.Close()
+
+	grants := []*models.ServerGroupUserPermissions{}
+	byUser := map[uuid.UUID]*models.ServerGroupUserPermissions{}
+	for rows.Next() {
+		var userId uuid.UUID
+		var username, code string
+		if err := rows.Scan(&userId, &username, &code); err != nil {
+			return nil, err
+		}
+
+		grant, ok := byUser[userId]
+		if !ok {
+			grant = &models.ServerGroupUserPermissions{UserID: userId, Username: username, Permissions: []string{}}
+			byUser[userId] = grant
+			grants = append(grants, grant)
+		}
+		grant.Permissions = append(grant.Permissions, code)
+	}
+
+	return grants, rows.Err()
+}
+
+// GetUserServerGroupPermissions returns the permission codes granted to a user on a group
+func GetUserServerGroupPermissions(ctx context.Context, database db.Executor, groupId, userId uuid.UUID) ([]string, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT p.code
+		FROM server_group_permissions sgp
+		JOIN permissions p ON p.id = sgp.permission_id
+		WHERE sgp.group_id = $1 AND sgp.user_id = $2
+	`, groupId, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server group permissions: %w", err)
+	}
+	defer rows.Close()
+
+	codes := []string{}
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, rows.Err()
+}
+
+// SetUserServerGroupPermissions replaces the permissions granted to a user on
+// a group. Unknown permission codes are ignored.
+func SetUserServerGroupPermissions(ctx context.Context, database db.Executor, groupId, userId uuid.UUID, codes []string) error {
+	_, err := database.ExecContext(ctx, `DELETE FROM server_group_permissions WHERE group_id = $1 AND user_id = $2`, groupId, userId)
+	if err != nil {
+		return fmt.Errorf("failed to clear server group permissions: %w", err)
+	}
+
+	for _, code := range codes {
+		_, err = database.ExecContext(ctx, `
+			INSERT INTO server_group_permissions (group_id, user_id, permission_id)
+			SELECT $1, $2, id FROM permissions WHERE code = $3
+			ON CONFLICT DO NOTHING
+		`, groupId, userId, code)
+		if err != nil {
+			return fmt.Errorf("failed to grant server group permission %s: %w", code, err)
+		}
+	}
+
+	return nil
+}
+
+func scanServerGroups(ctx context.Context, database db.Executor, rows *sql.Rows) ([]*models.ServerGroup, error) {
+	groups := []*models.ServerGroup{}
+	for rows.Next() {
+		group := &models.ServerGroup{}
+		if err := rows.Scan(&group.ID, &group.Name, &group.Description, &group.CreatedAt, &group.UpdatedAt); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for _, group := range groups {
+		serverIds, err := GetServerGroupServerIds(ctx, database, group.ID)
+		if err != nil {
+			return nil, err
+		}
+		group.ServerIDs = serverIds
+	}
+
+	return groups, nil
+}
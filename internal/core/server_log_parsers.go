@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+const serverLogParserColumns = `id, server_id, name, event_name, pattern, field_mapping, enabled, created_at, updated_at`
+
+// GetServerLogParsers returns the custom log parsers of a server
+func GetServerLogParsers(ctx context.Context, database db.Executor, serverId uuid.UUID) ([]*models.ServerLogParser, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT `+serverLogParserColumns+`
+		FROM server_log_parsers
+		WHERE server_id = $1
+		ORDER BY name
+	`, serverId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log parsers: %w", err)
+	}
+	defer rows.Close()
+
+	return scanServerLogParsers(rows)
+}
+
+// GetEnabledServerLogParsers returns the enabled custom log parsers of every server
+func GetEnabledServerLogParsers(ctx context.Context, database db.Executor) ([]*models.ServerLogParser, error) {
+	rows, err := database.QueryContext(ctx, `
+		SELECT `+serverLogParserColumns+`
+		FROM server_log_parsers
+		WHERE enabled = true
+		ORDER BY server_id, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log parsers: %w", err)
+	}
+	defer rows.Close()
+
+	return scanServerLogParsers(rows)
+}
+
+// GetServerLogParser returns a single custom log parser of a server
+func GetServerLogParser(ctx context.Context, database db.Executor, serverId, parserId uuid.UUID) (*models.ServerLogParser, error) {
+	row := database.QueryRowContext(ctx, `
+		SELECT `+serverLogParserColumns+`
+		FROM server_log_parsers
+		WHERE server_id = $1 AND id = $2
+	`, serverId, parserId)
+
+	return scanServerLogParser(row)
+}
+
+// CreateServerLogParser stores a new custom log parser
+func CreateServerLogParser(ctx context.Context, database db.Executor, parser *models.ServerLogParser) error {
+	mapping, err := json.Marshal(parser.FieldMapping)
+	if err != nil {
+		return fmt.Errorf("failed to encode field mapping: %w", err)
+	}
+
+	err = database.QueryRowContext(ctx, `
+		INSERT INTO server_log_parsers (id, server_id, name, event_name, pattern, field_mapping, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at
+	`, parser.ID, parser.ServerID, parser.Name, parser.EventName, parser.Pattern, mapping, parser.Enabled).Scan(&parser.CreatedAt, &parser.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create log parser: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateServerLogParser saves changes to a custom log parser
+func UpdateServerLogParser(ctx context.Context, database db.Executor, parser *models.ServerLogParser) error {
+	mapping, err := json.Marshal(parser.FieldMapping)
+	if err != nil {
+		return fmt.Errorf("failed to encode field mapping: %w", err)
+	}
+
+	err = database.QueryRowContext(ctx, `
+		UPDATE server_log_parsers
+		SET name = $1, event_name = $2, pattern = $3, field_mapping = $4, enabled = $5, updated_at = NOW()
+		WHERE server_id = $6 AND id = $7
+		RETURNING updated_at
+	`, parser.Name, parser.EventName, parser.Pattern, mapping, parser.Enabled, parser.ServerID, parser.ID).Scan(&parser.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update log parser: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteServerLogParser removes a custom log parser
+func DeleteServerLogParser(ctx context.Context, database db.Executor, serverId, parserId uuid.UUID) error {
+	_, err := database.ExecContext(ctx, `DELETE FROM server_log_parsers WHERE server_id = $1 AND id = $2`, serverId, parserId)
+	if err != nil {
+		return fmt.Errorf("failed to delete log parser: %w", err)
+	}
+
+	return nil
+}
+
+func scanServerLogParsers(rows *sql.Rows) ([]*models.ServerLogParser, error) {
+	parsers := []*models.ServerLogParser{}
+	for rows.Next() {
+		parser, err := scanServerLogParser(rows)
+		if err != nil {
+			return nil, err
+		}
+		parsers = append(parsers, parser)
+	}
+
+	return parsers, rows.Err()
+}
+
+func scanServerLogParser(row rowScanner) (*models.ServerLogParser, error) {
+	parser := &models.ServerLogParser{}
+	var mapping []byte
+	err := row.Scan(&parser.ID, &parser.ServerID, &parser.Name, &parser.EventName, &parser.Pattern, &mapping,
+		&parser.Enabled, &parser.CreatedAt, &parser.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(mapping, &parser.FieldMapping); err != nil {
+		return nil, fmt.Errorf("failed to decode field mapping: %w", err)
+	}
+
+	return parser, nil
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// GetServerLogPush returns the push agent settings of a server
+func GetServerLogPush(ctx context.Context, database db.Executor, serverId uuid.UUID) (*models.ServerLogPush, error) {
+	push := &models.ServerLogPush{}
+	err := database.QueryRowContext(ctx, `
+		SELECT server_id, token_hash, last_sequence, last_offset, created_at, updated_at
+		FROM server_log_push
+		WHERE server_id = $1
+	`, serverId).Scan(&push.ServerID, &push.TokenHash, &push.LastSequence, &push.LastOffset, &push.CreatedAt, &push.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return push, nil
+}
+
+// SetServerLogPushToken stores the hash of a new push agent token, replacing
+// any previous token. The resume position is kept.
+func SetServerLogPushToken(ctx context.Context, database db.Executor, serverId uuid.UUID, tokenHash string) error {
+	_, err := database.ExecContext(ctx, `
+		INSERT INTO server_log_push (server_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (server_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, updated_at = NOW()
+	`, serverId, tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to set log push token: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteServerLogPush removes the push agent token and resume position of a server
+func DeleteServerLogPush(ctx context.Context, database db.Executor, serverId uuid.UUID) error {
+	_, err := database.ExecContext(ctx, `DELETE FROM server_log_push WHERE server_id = $1`, serverId)
+	if err != nil {
+		return fmt.Errorf("failed to delete log push settings: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateServerLogPushPosition records the last line accepted from a push agent
+func UpdateServerLogPushPosition(ctx context.Context, database db.Executor, serverId uuid.UUID, sequence int64, offset int64) error {
+	_, err := database.ExecContext(ctx, `
+		UPDATE server_log_push
+		SET last_sequence = $1, last_offset = $2, updated_at = NOW()
+		WHERE server_id = $3
+	`, sequence, offset, serverId)
+	if err != nil {
+		return fmt.Errorf("failed to update log push position: %w", err)
+	}
+
+	return nil
+}
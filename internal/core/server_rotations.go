@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/db"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// GetServerRotation returns the map rotation of a server, or a disabled empty
+// rotation when none has been saved yet
+func GetServerRotation(ctx context.Context, database db.Executor, serverId uuid.UUID) (*models.ServerRotation, error) {
+	rotation := &models.ServerRotation{ServerID: serverId}
+	var layers []byte
+	err := database.QueryRowContext(ctx, `
+		SELECT enabled, mode, map_repeat_window, faction_repeat_window, layers, position, updated_at
+		FROM server_rotations
+		WHERE server_id = $1
+	`, serverId).Scan(&rotation.Enabled, &rotation.Mode, &rotation.MapRepeatWindow, &rotation.FactionRepeatWindow,
+		&layers, &rotation.Position, &rotation.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		rotation.Mode = models.RotationModeOrdered
+		rotation.MapRepeatWindow = 2
+		rotation.FactionRepeatWindow = 1
+		rotation.Layers = []models.RotationLayer{}
+		return rotation, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rotation: %w", err)
+	}
+
+	if err := json.Unmarshal(layers, &rotation.Layers); err != nil {
+		return nil, fmt.Errorf("failed to decode rotation layers: %w", err)
+	}
+
+	return rotation, nil
+}
+
+// SaveServerRotation creates or replaces the map rotation of a server
+func SaveServerRotation(ctx context.Context, database db.Executor, rotation *models.ServerRotation) error {
+	layers, err := json.Marshal(rotation.Layers)
+	if err != nil {
+		return fmt.Errorf("failed to encode rotation layers: %w", err)
+	}
+
+	err = database.QueryRowContext(ctx, `
+		INSERT INTO server_rotations (server_id, enabled, mode, map_repeat_window, faction_repeat_window, layers, position, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (server_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			mode = EXCLUDED.mode,
+			map_repeat_window = EXCLUDED.map_repeat_window,
+			faction_repeat_window = EXCLUDED.faction_repeat_window,
+			layers = EXCLUDED.layers,
+			position = EXCLUDED.position,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, rotation.ServerID, rotation.Enabled, rotation.Mode, rotation.MapRepeatWindow, rotation.FactionRepeatWindow,
+		layers, rotation.Position).Scan(&rotation.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save rotation: %w", err)
+	}
+
+	return nil
+}
+
+// SetServerRotationPosition stores where the ordered rotation of a server continues from
+func SetServerRotationPosition(ctx context.Context, database db.Executor, serverId uuid.UUID, position int) error {
+	_, err := database.ExecContext(ctx, `UPDATE server_rotations SET position = $1 WHERE server_id = $2`, position, serverId)
+	if err != nil {
+		return fmt.Errorf("failed to update rotation position: %w", err)
+	}
+
+	return nil
+}
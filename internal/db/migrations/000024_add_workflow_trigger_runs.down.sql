@@ -0,0 +1,2 @@
+-- Drop workflow trigger runs table
+DROP TABLE IF EXISTS public.server_workflow_trigger_runs;
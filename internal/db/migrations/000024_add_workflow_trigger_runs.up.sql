@@ -0,0 +1,9 @@
+-- Track the last run of scheduled workflow triggers so missed runs can be detected after a restart
+CREATE TABLE public.server_workflow_trigger_runs (
+    workflow_id uuid NOT NULL,
+    trigger_id VARCHAR(255) NOT NULL,
+    last_run_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workflow_id, trigger_id),
+    CONSTRAINT fk_server_workflow_trigger_runs_workflow_id FOREIGN KEY (workflow_id) REFERENCES public.server_workflows(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+ALTER TABLE public.server_workflow_executions DROP COLUMN IF EXISTS revision;
+
+ALTER TABLE public.server_workflows DROP COLUMN IF EXISTS revision;
+
+DROP TABLE IF EXISTS public.server_workflow_revisions;
@@ -0,0 +1,27 @@
+-- Immutable history of every saved workflow definition
+CREATE TABLE public.server_workflow_revisions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    workflow_id uuid NOT NULL,
+    revision INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    enabled BOOLEAN NOT NULL,
+    definition JSONB NOT NULL,
+    change_summary TEXT,
+    restored_from INTEGER,
+    created_by uuid NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_server_workflow_revisions_workflow_id FOREIGN KEY (workflow_id) REFERENCES public.server_workflows(id) ON DELETE CASCADE,
+    UNIQUE(workflow_id, revision)
+);
+
+CREATE INDEX idx_server_workflow_revisions_workflow_id ON public.server_workflow_revisions(workflow_id);
+
+ALTER TABLE public.server_workflows ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE public.server_workflow_executions ADD COLUMN revision INTEGER;
+
+-- Existing workflows start their history at revision 1
+INSERT INTO public.server_workflow_revisions (workflow_id, revision, name, description, enabled, definition, created_by, created_at)
+SELECT id, 1, name, description, enabled, definition, created_by, updated_at
+FROM public.server_workflows;
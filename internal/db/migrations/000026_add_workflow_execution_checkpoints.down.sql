@@ -0,0 +1,5 @@
+ALTER TABLE public.server_workflow_executions DROP COLUMN IF EXISTS retry_of;
+
+ALTER TABLE public.server_workflow_executions DROP COLUMN IF EXISTS checkpoint_at;
+
+ALTER TABLE public.server_workflow_executions DROP COLUMN IF EXISTS checkpoint;
@@ -0,0 +1,6 @@
+-- Execution state saved before each top-level step so executions survive restarts
+ALTER TABLE public.server_workflow_executions ADD COLUMN checkpoint JSONB;
+ALTER TABLE public.server_workflow_executions ADD COLUMN checkpoint_at TIMESTAMPTZ;
+
+-- Execution a retry was started from
+ALTER TABLE public.server_workflow_executions ADD COLUMN retry_of uuid;
@@ -0,0 +1,9 @@
+-- Triggers that didn't start an execution because of a workflow's concurrency or rate limits
+CREATE TABLE public.server_workflow_trigger_drops (
+    workflow_id uuid NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    last_dropped_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workflow_id, reason),
+    CONSTRAINT fk_server_workflow_trigger_drops_workflow_id FOREIGN KEY (workflow_id) REFERENCES public.server_workflows(id) ON DELETE CASCADE
+);
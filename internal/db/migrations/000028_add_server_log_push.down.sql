@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public.server_log_push;
+
+UPDATE public.servers SET log_source_type = NULL WHERE log_source_type = 'push';
+ALTER TABLE public.servers DROP CONSTRAINT IF EXISTS servers_log_source_type_check;
+ALTER TABLE public.servers ADD CONSTRAINT servers_log_source_type_check CHECK (log_source_type IN ('local', 'sftp', 'ftp'));
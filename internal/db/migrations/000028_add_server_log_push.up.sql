@@ -0,0 +1,14 @@
+-- Allow servers to receive logs from a shipper agent instead of pulling them
+ALTER TABLE public.servers DROP CONSTRAINT IF EXISTS servers_log_source_type_check;
+ALTER TABLE public.servers ADD CONSTRAINT servers_log_source_type_check CHECK (log_source_type IN ('local', 'sftp', 'ftp', 'push'));
+
+-- Agent token and the last line acknowledged, so agents can resume after a restart of either side
+CREATE TABLE public.server_log_push (
+    server_id uuid NOT NULL PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL,
+    last_sequence BIGINT NOT NULL DEFAULT 0,
+    last_offset BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_server_log_push_server_id FOREIGN KEY (server_id) REFERENCES public.servers(id) ON DELETE CASCADE
+);
@@ -0,0 +1,20 @@
+-- Imports of historical SquadGame.log files into ClickHouse
+CREATE TABLE public.log_import_jobs (
+    id uuid NOT NULL PRIMARY KEY,
+    server_id uuid NOT NULL,
+    files JSONB NOT NULL DEFAULT '[]'::jsonb,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed', 'cancelled')),
+    lines_processed BIGINT NOT NULL DEFAULT 0,
+    events_parsed BIGINT NOT NULL DEFAULT 0,
+    events_inserted BIGINT NOT NULL DEFAULT 0,
+    events_skipped BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_by uuid,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    CONSTRAINT fk_log_import_jobs_server_id FOREIGN KEY (server_id) REFERENCES public.servers(id) ON DELETE CASCADE,
+    CONSTRAINT fk_log_import_jobs_created_by FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_log_import_jobs_server_id ON public.log_import_jobs (server_id, created_at DESC);
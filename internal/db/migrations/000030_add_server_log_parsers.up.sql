@@ -0,0 +1,14 @@
+-- Admin-defined log parsers publishing LOG_CUSTOM events
+CREATE TABLE public.server_log_parsers (
+    id uuid NOT NULL PRIMARY KEY,
+    server_id uuid NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    event_name VARCHAR(100) NOT NULL,
+    pattern TEXT NOT NULL,
+    field_mapping JSONB NOT NULL DEFAULT '{}'::jsonb,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_server_log_parsers_server_id FOREIGN KEY (server_id) REFERENCES public.servers(id) ON DELETE CASCADE,
+    CONSTRAINT uq_server_log_parsers_name UNIQUE (server_id, name)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.server_group_permissions;
+DROP TABLE IF EXISTS public.server_group_servers;
+DROP TABLE IF EXISTS public.server_groups;
@@ -0,0 +1,34 @@
+-- Named sets of servers that RCON commands, broadcasts, kicks and bans can be fanned out to
+CREATE TABLE public.server_groups (
+    id uuid NOT NULL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_server_groups_name UNIQUE (name)
+);
+
+CREATE TABLE public.server_group_servers (
+    group_id uuid NOT NULL,
+    server_id uuid NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, server_id),
+    CONSTRAINT fk_server_group_servers_group_id FOREIGN KEY (group_id) REFERENCES public.server_groups(id) ON DELETE CASCADE,
+    CONSTRAINT fk_server_group_servers_server_id FOREIGN KEY (server_id) REFERENCES public.servers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_server_group_servers_server_id ON public.server_group_servers(server_id);
+
+-- Permissions granted to a user on a group, independent of their per-server roles
+CREATE TABLE public.server_group_permissions (
+    id uuid NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    permission_id uuid NOT NULL,
+    CONSTRAINT fk_server_group_permissions_group_id FOREIGN KEY (group_id) REFERENCES public.server_groups(id) ON DELETE CASCADE,
+    CONSTRAINT fk_server_group_permissions_user_id FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_server_group_permissions_permission_id FOREIGN KEY (permission_id) REFERENCES public.permissions(id) ON DELETE CASCADE,
+    CONSTRAINT uq_server_group_permissions UNIQUE (group_id, user_id, permission_id)
+);
+
+CREATE INDEX idx_server_group_permissions_user_id ON public.server_group_permissions(user_id);
@@ -0,0 +1,13 @@
+-- Per-server map rotation: layer pool, selection mode and no-repeat windows
+CREATE TABLE public.server_rotations (
+    server_id uuid NOT NULL PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    mode VARCHAR(20) NOT NULL DEFAULT 'ordered',
+    map_repeat_window INTEGER NOT NULL DEFAULT 2,
+    faction_repeat_window INTEGER NOT NULL DEFAULT 1,
+    layers JSONB NOT NULL DEFAULT '[]'::jsonb,
+    position INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_server_rotations_server_id FOREIGN KEY (server_id) REFERENCES public.servers(id) ON DELETE CASCADE,
+    CONSTRAINT chk_server_rotations_mode CHECK (mode IN ('ordered', 'weighted'))
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.server_admin_camera_settings;
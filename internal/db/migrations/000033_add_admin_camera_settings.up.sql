@@ -0,0 +1,12 @@
+-- Per-server thresholds for admin camera alerts
+CREATE TABLE public.server_admin_camera_settings (
+    server_id uuid NOT NULL PRIMARY KEY,
+    alerts_enabled BOOLEAN NOT NULL DEFAULT true,
+    long_session_minutes INTEGER NOT NULL DEFAULT 10,
+    frequent_session_count INTEGER NOT NULL DEFAULT 5,
+    frequent_session_window_minutes INTEGER NOT NULL DEFAULT 30,
+    kill_window_minutes INTEGER NOT NULL DEFAULT 5,
+    ghosting_kill_threshold INTEGER NOT NULL DEFAULT 2,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_server_admin_camera_settings_server_id FOREIGN KEY (server_id) REFERENCES public.servers(id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+DELETE FROM server_role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE code = 'ui:ban_appeals:review');
+
+DELETE FROM role_template_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE code = 'ui:ban_appeals:review');
+
+DELETE FROM permissions WHERE code = 'ui:ban_appeals:review';
+
+DROP TABLE IF EXISTS public.ban_appeal_events;
+DROP TABLE IF EXISTS public.ban_appeal_comments;
+DROP TABLE IF EXISTS public.ban_appeals;
+
+ALTER TABLE public.server_bans DROP COLUMN IF EXISTS lifted_at;
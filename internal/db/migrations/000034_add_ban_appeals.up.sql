@@ -0,0 +1,83 @@
+-- Ban appeals: submitted by banned players without an account, reviewed by admins with ui:ban_appeals:review
+
+-- Lifted bans are kept for their history, but no longer enforced
+ALTER TABLE public.server_bans ADD COLUMN lifted_at TIMESTAMP;
+
+CREATE TABLE public.ban_appeals (
+    id uuid NOT NULL PRIMARY KEY,
+    ban_id uuid NOT NULL,
+    server_id uuid NOT NULL,
+    steam_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    statement TEXT NOT NULL,
+    contact VARCHAR(255),
+    reviewer_id uuid,
+    resolution VARCHAR(20),
+    resolved_duration INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    CONSTRAINT fk_ban_appeals_ban_id FOREIGN KEY (ban_id) REFERENCES public.server_bans(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ban_appeals_server_id FOREIGN KEY (server_id) REFERENCES public.servers(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ban_appeals_reviewer_id FOREIGN KEY (reviewer_id) REFERENCES public.users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_ban_appeals_status CHECK (status IN ('pending', 'more_info', 'accepted', 'denied')),
+    CONSTRAINT chk_ban_appeals_resolution CHECK (resolution IS NULL OR resolution IN ('lift', 'shorten'))
+);
+
+CREATE INDEX idx_ban_appeals_server_status ON public.ban_appeals(server_id, status, created_at);
+CREATE INDEX idx_ban_appeals_ban_id ON public.ban_appeals(ban_id);
+
+-- A ban can only have one open appeal at a time
+CREATE UNIQUE INDEX uq_ban_appeals_open ON public.ban_appeals(ban_id) WHERE status IN ('pending', 'more_info');
+
+-- Messages on an appeal, from the player (user_id NULL) or a reviewer. Internal
+-- notes are only shown to reviewers.
+CREATE TABLE public.ban_appeal_comments (
+    id uuid NOT NULL PRIMARY KEY,
+    appeal_id uuid NOT NULL,
+    user_id uuid,
+    message TEXT NOT NULL,
+    internal BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_ban_appeal_comments_appeal_id FOREIGN KEY (appeal_id) REFERENCES public.ban_appeals(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ban_appeal_comments_user_id FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_ban_appeal_comments_appeal_id ON public.ban_appeal_comments(appeal_id, created_at);
+
+-- Audit trail of an appeal: submission, replies, comments and status changes
+CREATE TABLE public.ban_appeal_events (
+    id uuid NOT NULL PRIMARY KEY,
+    appeal_id uuid NOT NULL,
+    user_id uuid,
+    action VARCHAR(50) NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20),
+    details JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_ban_appeal_events_appeal_id FOREIGN KEY (appeal_id) REFERENCES public.ban_appeals(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ban_appeal_events_user_id FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_ban_appeal_events_appeal_id ON public.ban_appeal_events(appeal_id, created_at);
+
+-- Review permission, granted to the Server Admin and Moderator templates and
+-- to existing roles that can edit bans
+INSERT INTO permissions (code, category, name, description, squad_permission) VALUES
+    ('ui:ban_appeals:review', 'ui', 'Review Ban Appeals', 'Permission to review ban appeals and accept or deny them', NULL)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_template_permissions (role_template_id, permission_id)
+SELECT template_id, p.id
+FROM (VALUES ('00000000-0000-0000-0000-000000000002'::uuid), ('00000000-0000-0000-0000-000000000003'::uuid)) AS t(template_id)
+CROSS JOIN permissions p
+WHERE p.code = 'ui:ban_appeals:review'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO server_role_permissions (server_role_id, permission_id)
+SELECT DISTINCT srp.server_role_id, p2.id
+FROM server_role_permissions srp
+JOIN permissions p1 ON srp.permission_id = p1.id AND p1.code = 'ui:bans:edit'
+CROSS JOIN permissions p2
+WHERE p2.code = 'ui:ban_appeals:review'
+ON CONFLICT DO NOTHING;
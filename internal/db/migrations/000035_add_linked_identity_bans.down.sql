@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS public.ban_identity_matches;
+DROP TABLE IF EXISTS public.server_linked_ban_settings;
+
+ALTER TABLE public.server_bans DROP COLUMN IF EXISTS extend_to_linked;
+
+-- Bans on EOS IDs alone can't be kept once a Steam ID is required again
+DELETE FROM public.server_bans WHERE steam_id IS NULL;
+DROP INDEX IF EXISTS idx_server_bans_eos_id;
+ALTER TABLE public.server_bans DROP CONSTRAINT IF EXISTS chk_server_bans_identifier;
+ALTER TABLE public.server_bans DROP COLUMN IF EXISTS eos_id;
+ALTER TABLE public.server_bans ALTER COLUMN steam_id SET NOT NULL;
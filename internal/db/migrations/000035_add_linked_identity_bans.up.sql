@@ -0,0 +1,40 @@
+-- Bans can target an EOS ID instead of, or as well as, a Steam ID
+ALTER TABLE public.server_bans ALTER COLUMN steam_id DROP NOT NULL;
+ALTER TABLE public.server_bans ADD COLUMN eos_id VARCHAR(32);
+ALTER TABLE public.server_bans ADD CONSTRAINT chk_server_bans_identifier CHECK (steam_id IS NOT NULL OR eos_id IS NOT NULL);
+CREATE INDEX idx_server_bans_eos_id ON public.server_bans (eos_id) WHERE eos_id IS NOT NULL;
+
+-- Whether the ban extends to identities linked to the banned player. NULL follows the server setting.
+ALTER TABLE public.server_bans ADD COLUMN extend_to_linked BOOLEAN;
+
+-- Per-server policy for enforcing bans on linked identities
+CREATE TABLE public.server_linked_ban_settings (
+    server_id uuid NOT NULL PRIMARY KEY,
+    extend_by_default BOOLEAN NOT NULL DEFAULT false,
+    action VARCHAR(10) NOT NULL DEFAULT 'flag',
+    min_confidence INTEGER NOT NULL DEFAULT 75,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_server_linked_ban_settings_action CHECK (action IN ('kick', 'flag')),
+    CONSTRAINT chk_server_linked_ban_settings_min_confidence CHECK (min_confidence BETWEEN 1 AND 100),
+    CONSTRAINT fk_server_linked_ban_settings_server_id FOREIGN KEY (server_id) REFERENCES public.servers(id) ON DELETE CASCADE
+);
+
+-- Connecting players matched to a ban through a linked identity, and why
+CREATE TABLE public.ban_identity_matches (
+    id uuid NOT NULL PRIMARY KEY,
+    server_id uuid NOT NULL,
+    ban_id uuid NOT NULL,
+    steam_id VARCHAR(20) NOT NULL DEFAULT '',
+    eos_id VARCHAR(32) NOT NULL DEFAULT '',
+    player_name TEXT NOT NULL DEFAULT '',
+    banned_identifier TEXT NOT NULL,
+    confidence INTEGER NOT NULL,
+    links JSONB NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_ban_identity_matches_server_id FOREIGN KEY (server_id) REFERENCES public.servers(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ban_identity_matches_ban_id FOREIGN KEY (ban_id) REFERENCES public.server_bans(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_ban_identity_matches_server_created ON public.ban_identity_matches (server_id, created_at DESC);
+CREATE INDEX idx_ban_identity_matches_ban_id ON public.ban_identity_matches (ban_id);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS public.remote_ban_sync_runs;
+
+ALTER TABLE public.server_bans DROP COLUMN IF EXISTS issued_by;
+
+ALTER TABLE public.remote_ban_sources
+    DROP COLUMN IF EXISTS last_modified,
+    DROP COLUMN IF EXISTS etag,
+    DROP COLUMN IF EXISTS signature_url,
+    DROP COLUMN IF EXISTS public_key,
+    DROP COLUMN IF EXISTS format;
@@ -0,0 +1,29 @@
+-- Feed format, conditional request validators and an optional Ed25519 key
+-- for detached signatures of a remote ban source
+ALTER TABLE public.remote_ban_sources
+    ADD COLUMN IF NOT EXISTS format VARCHAR(10) NOT NULL DEFAULT 'auto'
+        CHECK (format IN ('auto', 'csv', 'text', 'json')),
+    ADD COLUMN IF NOT EXISTS public_key TEXT,
+    ADD COLUMN IF NOT EXISTS signature_url TEXT,
+    ADD COLUMN IF NOT EXISTS etag TEXT,
+    ADD COLUMN IF NOT EXISTS last_modified TEXT;
+
+-- Who issued a ban imported from a remote source, as the source reports it
+ALTER TABLE public.server_bans ADD COLUMN IF NOT EXISTS issued_by VARCHAR(255);
+
+CREATE TABLE IF NOT EXISTS public.remote_ban_sync_runs (
+    id UUID PRIMARY KEY,
+    source_id UUID NOT NULL REFERENCES public.remote_ban_sources(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('success', 'not_modified', 'error')),
+    http_status INTEGER,
+    total INTEGER NOT NULL DEFAULT 0,
+    added INTEGER NOT NULL DEFAULT 0,
+    removed INTEGER NOT NULL DEFAULT 0,
+    changed INTEGER NOT NULL DEFAULT 0,
+    signature_verified BOOLEAN NOT NULL DEFAULT false,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_remote_ban_sync_runs_source ON public.remote_ban_sync_runs (source_id, started_at DESC);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public.federated_bans;
+DROP TABLE IF EXISTS public.federation_peers;
+DROP TABLE IF EXISTS public.federation_api_keys;
+
+ALTER TABLE public.ban_lists DROP COLUMN IF EXISTS federated;
@@ -0,0 +1,64 @@
+-- Ban lists shared with federated Aegis instances
+ALTER TABLE public.ban_lists ADD COLUMN IF NOT EXISTS federated BOOLEAN NOT NULL DEFAULT false;
+
+-- API keys issued to peers reading the federated ban lists of this instance.
+-- Only a hash of the key is kept; an empty ban_list_ids allows every
+-- federated list.
+CREATE TABLE IF NOT EXISTS public.federation_api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    key_prefix VARCHAR(16) NOT NULL,
+    ban_list_ids UUID[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES public.users(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Aegis instances this instance pulls ban lists from
+CREATE TABLE IF NOT EXISTS public.federation_peers (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    api_key TEXT NOT NULL,
+    trust_level VARCHAR(20) NOT NULL DEFAULT 'observe'
+        CHECK (trust_level IN ('full', 'evidence', 'observe')),
+    min_duration_days INTEGER NOT NULL DEFAULT 0,
+    rule_categories TEXT[] NOT NULL DEFAULT '{}',
+    ban_list_id UUID REFERENCES public.ban_lists(id) ON DELETE SET NULL,
+    sync_enabled BOOLEAN NOT NULL DEFAULT true,
+    sync_interval_minutes INTEGER NOT NULL DEFAULT 60,
+    last_synced_at TIMESTAMPTZ,
+    last_sync_status VARCHAR(20),
+    last_sync_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Bans received from federated peers. Enforced bans are copied into the ban
+-- list of the peer; bans the peer stops publishing are kept as revoked.
+CREATE TABLE IF NOT EXISTS public.federated_bans (
+    id UUID PRIMARY KEY,
+    peer_id UUID NOT NULL REFERENCES public.federation_peers(id) ON DELETE CASCADE,
+    remote_ban_id VARCHAR(64) NOT NULL,
+    remote_ban_list VARCHAR(255) NOT NULL,
+    community VARCHAR(255) NOT NULL,
+    steam_id BIGINT,
+    eos_id VARCHAR(32),
+    reason TEXT NOT NULL,
+    rule_category VARCHAR(255),
+    duration_days INTEGER NOT NULL,
+    evidence_summary TEXT,
+    evidence_count INTEGER NOT NULL DEFAULT 0,
+    issued_by VARCHAR(255),
+    banned_at TIMESTAMPTZ NOT NULL,
+    server_ban_id UUID REFERENCES public.server_bans(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMPTZ,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (peer_id, remote_ban_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_federated_bans_peer ON public.federated_bans (peer_id, received_at DESC);
+CREATE INDEX IF NOT EXISTS idx_federated_bans_server_ban ON public.federated_bans (server_ban_id);
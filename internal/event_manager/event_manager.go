@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,6 +26,9 @@ const (
 	EventTypeRconUnpossessedAdminCamera EventType = "RCON_UNPOSSESSED_ADMIN_CAMERA"
 	EventTypeRconSquadCreated           EventType = "RCON_SQUAD_CREATED"
 	EventTypeRconServerInfo             EventType = "RCON_SERVER_INFO"
+	EventTypeRconConnected              EventType = "RCON_CONNECTED"
+	EventTypeRconDisconnected           EventType = "RCON_DISCONNECTED"
+	EventTypeRconDegraded               EventType = "RCON_DEGRADED"
 
 	// Log Events
 	EventTypeLogAdminBroadcast     EventType = "LOG_ADMIN_BROADCAST"
@@ -39,6 +43,13 @@ const (
 	EventTypeLogJoinSucceeded      EventType = "LOG_JOIN_SUCCEEDED"
 	EventTypeLogTickRate           EventType = "LOG_TICK_RATE"
 	EventTypeLogGameEventUnified   EventType = "LOG_GAME_EVENT_UNIFIED"
+	EventTypeLogCustom             EventType = "LOG_CUSTOM"
+	EventTypeLogVehicleDestroyed   EventType = "LOG_VEHICLE_DESTROYED"
+	EventTypeLogDeployablePlaced   EventType = "LOG_DEPLOYABLE_PLACED"
+	EventTypeLogDeployableRemoved  EventType = "LOG_DEPLOYABLE_REMOVED"
+	EventTypeLogSquadLeaderChanged EventType = "LOG_SQUAD_LEADER_CHANGED"
+	EventTypeLogPlayerRoleChanged  EventType = "LOG_PLAYER_ROLE_CHANGED"
+	EventTypeLogCommanderAssigned  EventType = "LOG_COMMANDER_ASSIGNED"
 
 	// Player Tracker Events
 	EventTypePlayerListUpdated  EventType = "PLAYER_LIST_UPDATED"
@@ -51,6 +62,9 @@ const (
 	EventTypeEnhancedTeamkill   EventType = "ENHANCED_TEAMKILL"
 	EventTypePlayerStatsUpdated EventType = "PLAYER_STATS_UPDATED"
 
+	// Admin Camera Events
+	EventTypeAdminCameraAlert EventType = "ADMIN_CAMERA_ALERT"
+
 	// Plugin Events
 	EventTypePluginCustom EventType = "PLUGIN_CUSTOM"
 	EventTypePluginLog    EventType = "PLUGIN_LOG"
@@ -69,9 +83,19 @@ type Event struct {
 // EventSubscriber represents a subscriber to events
 type EventSubscriber struct {
 	ID       uuid.UUID
+	Name     string // Set for durable subscribers whose cursor survives restarts
 	Channel  chan Event
 	Filter   EventFilter
 	ServerID *uuid.UUID // If nil, subscribes to all servers
+
+	// Journal delivery state, unused when the event manager has no journal
+	cursor   atomic.Uint64
+	wake     chan struct{}
+	done     chan struct{}
+	pumpDone chan struct{}
+
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
 }
 
 // EventFilter allows filtering events by type and other criteria
@@ -88,6 +112,40 @@ type EventManager struct {
 	ctx         context.Context
 	cancel      context.CancelFunc
 	bufferSize  int
+
+	// journal is optional; when set, events are appended to it and every
+	// subscriber reads from it at its own pace instead of being dropped
+	journal EventJournal
+
+	// handler is set for synchronous event managers and receives every
+	// event instead of the subscribers
+	handler func(Event)
+
+	stats eventStats
+}
+
+// eventStats holds counters reported by GetEventStats
+type eventStats struct {
+	published             atomic.Uint64
+	droppedQueueFull      atomic.Uint64
+	droppedSubscriberFull atomic.Uint64
+	droppedJournalOverrun atomic.Uint64
+	journalErrors         atomic.Uint64
+	backpressureWaits     atomic.Uint64
+	replayed              atomic.Uint64
+}
+
+const (
+	// journalReadBatch is how many entries a subscriber reads from the journal at once
+	journalReadBatch = 256
+	// cursorSaveInterval is how often durable subscribers persist their cursor
+	cursorSaveInterval = 5 * time.Second
+)
+
+// transientEventTypes are never journaled. They are high volume, already
+// persisted elsewhere, and only useful live.
+var transientEventTypes = map[EventType]bool{
+	EventTypePluginLog: true,
 }
 
 // NewEventManager creates a new event manager
@@ -112,8 +170,47 @@ func NewEventManager(ctx context.Context, bufferSize int) *EventManager {
 	return em
 }
 
+// NewDurableEventManager creates an event manager that records events in the
+// given journal. Subscribers consume the journal through their own cursor, so a
+// slow subscriber applies back-pressure to itself and catches up later instead
+// of losing events. Events only drop once they age out of the bounded journal.
+func NewDurableEventManager(ctx context.Context, bufferSize int, journal EventJournal) *EventManager {
+	em := NewEventManager(ctx, bufferSize)
+	em.journal = journal
+
+	log.Info().Msg("Event manager using journal for durable delivery")
+
+	return em
+}
+
+// NewSyncEventManager creates an event manager that passes every published
+// event to handler on the publisher's goroutine. Nothing is queued or
+// distributed to subscribers, so events can't be dropped and never reach live
+// consumers. It is used to replay historical logs. It starts no goroutines,
+// so it doesn't need to be shut down.
+func NewSyncEventManager(ctx context.Context, handler func(Event)) *EventManager {
+	return &EventManager{
+		subscribers: make(map[uuid.UUID]*EventSubscriber),
+		ctx:         ctx,
+		cancel:      func() {},
+		handler:     handler,
+	}
+}
+
 // Subscribe creates a new event subscription
 func (em *EventManager) Subscribe(filter EventFilter, serverID *uuid.UUID, channelSize int) *EventSubscriber {
+	return em.subscribe("", filter, serverID, channelSize)
+}
+
+// SubscribeDurable creates a named subscription. With a journal configured the
+// subscriber's cursor is persisted under name, and after a restart it resumes
+// with the events it missed that are still in the journal. Without a journal
+// it behaves like Subscribe.
+func (em *EventManager) SubscribeDurable(name string, filter EventFilter, serverID *uuid.UUID, channelSize int) *EventSubscriber {
+	return em.subscribe(name, filter, serverID, channelSize)
+}
+
+func (em *EventManager) subscribe(name string, filter EventFilter, serverID *uuid.UUID, channelSize int) *EventSubscriber {
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
@@ -123,34 +220,91 @@ func (em *EventManager) Subscribe(filter EventFilter, serverID *uuid.UUID, chann
 
 	subscriber := &EventSubscriber{
 		ID:       uuid.New(),
+		Name:     name,
 		Channel:  make(chan Event, channelSize),
 		Filter:   filter,
 		ServerID: serverID,
 	}
 
+	if em.journal != nil {
+		subscriber.wake = make(chan struct{}, 1)
+		subscriber.done = make(chan struct{})
+		subscriber.pumpDone = make(chan struct{})
+		subscriber.cursor.Store(em.startingCursor(name))
+		go em.pumpSubscriber(subscriber)
+	}
+
 	em.subscribers[subscriber.ID] = subscriber
 
 	log.Debug().
 		Str("subscriberID", subscriber.ID.String()).
+		Str("name", name).
 		Interface("filter", filter).
 		Msg("New event subscriber registered")
 
 	return subscriber
 }
 
+// startingCursor returns the journal position a new subscriber starts after.
+// Anonymous subscribers only see new events; durable ones resume where they left off.
+func (em *EventManager) startingCursor(name string) uint64 {
+	last, err := em.journal.LastSequence()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read journal position")
+	}
+
+	if name == "" {
+		return last
+	}
+
+	cursor, ok, err := em.journal.LoadCursor(name)
+	if err != nil {
+		log.Error().Err(err).Str("name", name).Msg("Failed to load subscriber cursor, starting from latest event")
+		return last
+	}
+	if !ok || cursor > last {
+		return last
+	}
+
+	if last > cursor {
+		log.Info().
+			Str("name", name).
+			Uint64("cursor", cursor).
+			Uint64("missed", last-cursor).
+			Msg("Durable subscriber resuming from journal")
+	}
+
+	return cursor
+}
+
 // Unsubscribe removes an event subscription
 func (em *EventManager) Unsubscribe(subscriberID uuid.UUID) {
 	em.mu.Lock()
-	defer em.mu.Unlock()
-
-	if subscriber, exists := em.subscribers[subscriberID]; exists {
-		close(subscriber.Channel)
+	subscriber, exists := em.subscribers[subscriberID]
+	if exists {
 		delete(em.subscribers, subscriberID)
+	}
+	em.mu.Unlock()
 
-		log.Debug().
-			Str("subscriberID", subscriberID.String()).
-			Msg("Event subscriber unregistered")
+	if !exists {
+		return
 	}
+
+	em.stopSubscriber(subscriber)
+
+	log.Debug().
+		Str("subscriberID", subscriberID.String()).
+		Msg("Event subscriber unregistered")
+}
+
+// stopSubscriber stops journal delivery for a subscriber that has already been
+// removed from the subscriber map, then closes its channel
+func (em *EventManager) stopSubscriber(subscriber *EventSubscriber) {
+	if subscriber.done != nil {
+		close(subscriber.done)
+		<-subscriber.pumpDone
+	}
+	close(subscriber.Channel)
 }
 
 // PublishEvent publishes an event to the event queue with structured data
@@ -164,11 +318,35 @@ func (em *EventManager) PublishEvent(serverID uuid.UUID, data EventData, rawData
 		Timestamp: time.Now(),
 	}
 
+	em.stats.published.Add(1)
+
+	if em.handler != nil {
+		em.handler(event)
+		return
+	}
+
+	if em.journal != nil && !transientEventTypes[event.Type] {
+		_, err := em.journal.Append(event)
+		if err == nil {
+			em.wakeSubscribers()
+			return
+		}
+
+		// Fall back to in-memory delivery so the event still reaches live subscribers
+		em.stats.journalErrors.Add(1)
+		log.Error().
+			Err(err).
+			Str("eventID", event.ID.String()).
+			Str("eventType", string(event.Type)).
+			Msg("Failed to append event to journal, delivering in-memory")
+	}
+
 	select {
 	case em.eventQueue <- event:
 		// Event queued successfully
 	default:
 		// Queue is full, log warning and drop event
+		em.stats.droppedQueueFull.Add(1)
 		log.Warn().
 			Str("eventID", event.ID.String()).
 			Str("serverID", serverID.String()).
@@ -202,8 +380,11 @@ func (em *EventManager) distributeEvent(event Event) {
 			select {
 			case subscriber.Channel <- event:
 				// Event sent successfully
+				subscriber.delivered.Add(1)
 			default:
 				// Subscriber channel is full, log warning
+				em.stats.droppedSubscriberFull.Add(1)
+				subscriber.dropped.Add(1)
 				log.Warn().
 					Str("subscriberID", subscriber.ID.String()).
 					Str("eventID", event.ID.String()).
@@ -214,6 +395,108 @@ func (em *EventManager) distributeEvent(event Event) {
 	}
 }
 
+// wakeSubscribers notifies journal subscribers that new events are available
+func (em *EventManager) wakeSubscribers() {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	for _, subscriber := range em.subscribers {
+		select {
+		case subscriber.wake <- struct{}{}:
+		default:
+			// Already has a pending wake-up
+		}
+	}
+}
+
+// pumpSubscriber delivers journaled events to a subscriber in order. Sends
+// block while the subscriber's channel is full, so the subscriber falls behind
+// in the journal instead of losing events.
+func (em *EventManager) pumpSubscriber(subscriber *EventSubscriber) {
+	defer close(subscriber.pumpDone)
+
+	saveTicker := time.NewTicker(cursorSaveInterval)
+	defer saveTicker.Stop()
+	defer em.saveCursor(subscriber)
+
+	// Events that were already journaled when a durable subscriber attached are replays
+	replayUntil, _ := em.journal.LastSequence()
+
+	for {
+		cursor := subscriber.cursor.Load()
+		entries, err := em.journal.Read(cursor, journalReadBatch)
+		if err != nil {
+			log.Error().Err(err).Str("subscriberID", subscriber.ID.String()).Msg("Failed to read event journal")
+			select {
+			case <-subscriber.done:
+				return
+			case <-em.ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		if len(entries) == 0 {
+			select {
+			case <-subscriber.done:
+				return
+			case <-em.ctx.Done():
+				return
+			case <-saveTicker.C:
+				em.saveCursor(subscriber)
+			case <-subscriber.wake:
+			}
+			continue
+		}
+
+		// Entries between the cursor and the oldest retained entry aged out of the journal
+		if missed := entries[0].Sequence - cursor - 1; entries[0].Sequence > cursor+1 {
+			em.stats.droppedJournalOverrun.Add(missed)
+			subscriber.dropped.Add(missed)
+			log.Warn().
+				Str("subscriberID", subscriber.ID.String()).
+				Str("name", subscriber.Name).
+				Uint64("missed", missed).
+				Msg("Subscriber fell behind the event journal, events were lost")
+		}
+
+		for _, entry := range entries {
+			if em.eventMatchesFilter(entry.Event, subscriber) {
+				select {
+				case subscriber.Channel <- entry.Event:
+				default:
+					em.stats.backpressureWaits.Add(1)
+					select {
+					case subscriber.Channel <- entry.Event:
+					case <-subscriber.done:
+						return
+					case <-em.ctx.Done():
+						return
+					}
+				}
+
+				subscriber.delivered.Add(1)
+				if subscriber.Name != "" && entry.Sequence <= replayUntil {
+					em.stats.replayed.Add(1)
+				}
+			}
+			subscriber.cursor.Store(entry.Sequence)
+		}
+	}
+}
+
+// saveCursor persists the cursor of a durable subscriber
+func (em *EventManager) saveCursor(subscriber *EventSubscriber) {
+	if subscriber.Name == "" {
+		return
+	}
+
+	if err := em.journal.SaveCursor(subscriber.Name, subscriber.cursor.Load()); err != nil {
+		log.Error().Err(err).Str("name", subscriber.Name).Msg("Failed to save subscriber cursor")
+	}
+}
+
 // eventMatchesFilter checks if an event matches a subscriber's filter
 func (em *EventManager) eventMatchesFilter(event Event, subscriber *EventSubscriber) bool {
 	// Check server filter
@@ -261,11 +544,60 @@ func (em *EventManager) GetEventStats() map[string]interface{} {
 	em.mu.RLock()
 	defer em.mu.RUnlock()
 
+	mode := "memory"
+	var lastSequence, firstSequence uint64
+	if em.journal != nil {
+		mode = "journal"
+		lastSequence, _ = em.journal.LastSequence()
+		firstSequence, _ = em.journal.FirstSequence()
+	}
+
+	var maxLag, totalLag uint64
+	subscriberStats := make([]map[string]interface{}, 0, len(em.subscribers))
+	for _, subscriber := range em.subscribers {
+		stat := map[string]interface{}{
+			"id":           subscriber.ID.String(),
+			"name":         subscriber.Name,
+			"channel_size": len(subscriber.Channel),
+			"channel_cap":  cap(subscriber.Channel),
+			"delivered":    subscriber.delivered.Load(),
+			"dropped":      subscriber.dropped.Load(),
+		}
+
+		if em.journal != nil {
+			var lag uint64
+			if cursor := subscriber.cursor.Load(); lastSequence > cursor {
+				lag = lastSequence - cursor
+			}
+			stat["cursor"] = subscriber.cursor.Load()
+			stat["lag"] = lag
+			totalLag += lag
+			if lag > maxLag {
+				maxLag = lag
+			}
+		}
+
+		subscriberStats = append(subscriberStats, stat)
+	}
+
 	return map[string]interface{}{
-		"subscribers":    len(em.subscribers),
-		"queue_size":     len(em.eventQueue),
-		"queue_capacity": cap(em.eventQueue),
-		"buffer_size":    em.bufferSize,
+		"mode":                    mode,
+		"subscribers":             len(em.subscribers),
+		"queue_size":              len(em.eventQueue),
+		"queue_capacity":          cap(em.eventQueue),
+		"buffer_size":             em.bufferSize,
+		"published":               em.stats.published.Load(),
+		"dropped_queue_full":      em.stats.droppedQueueFull.Load(),
+		"dropped_subscriber_full": em.stats.droppedSubscriberFull.Load(),
+		"dropped_journal_overrun": em.stats.droppedJournalOverrun.Load(),
+		"journal_errors":          em.stats.journalErrors.Load(),
+		"backpressure_waits":      em.stats.backpressureWaits.Load(),
+		"replayed":                em.stats.replayed.Load(),
+		"journal_first_sequence":  firstSequence,
+		"journal_last_sequence":   lastSequence,
+		"max_lag":                 maxLag,
+		"total_lag":               totalLag,
+		"subscriber_stats":        subscriberStats,
 	}
 }
 
@@ -277,12 +609,14 @@ func (em *EventManager) Shutdown() {
 
 	// Close all subscriber channels
 	em.mu.Lock()
-	for _, subscriber := range em.subscribers {
-		close(subscriber.Channel)
-	}
+	subscribers := em.subscribers
 	em.subscribers = make(map[uuid.UUID]*EventSubscriber)
 	em.mu.Unlock()
 
+	for _, subscriber := range subscribers {
+		em.stopSubscriber(subscriber)
+	}
+
 	log.Info().Msg("Event manager shutdown complete")
 }
 
@@ -0,0 +1,129 @@
+package event_manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// eventDataFactories maps event types to constructors for their data structs.
+// Constructors return the same shape (pointer or value) that publishers use,
+// so subscribers can keep their existing type assertions on replayed events.
+var eventDataFactories = map[EventType]func() EventData{
+	// RCON Events
+	EventTypeRconChatMessage:            func() EventData { return &RconChatMessageData{} },
+	EventTypeRconPlayerWarned:           func() EventData { return &RconPlayerWarnedData{} },
+	EventTypeRconPlayerKicked:           func() EventData { return &RconPlayerKickedData{} },
+	EventTypeRconPlayerBanned:           func() EventData { return &RconPlayerBannedData{} },
+	EventTypeRconPossessedAdminCamera:   func() EventData { return &RconAdminCameraData{} },
+	EventTypeRconUnpossessedAdminCamera: func() EventData { return &RconAdminCameraData{} },
+	EventTypeRconSquadCreated:           func() EventData { return &RconSquadCreatedData{} },
+	EventTypeRconServerInfo:             func() EventData { return &RconServerInfoData{} },
+	EventTypeRconConnected:              func() EventData { return &RconConnectedData{} },
+	EventTypeRconDisconnected:           func() EventData { return &RconDisconnectedData{} },
+	EventTypeRconDegraded:               func() EventData { return &RconDegradedData{} },
+
+	// Log Events
+	EventTypeLogAdminBroadcast:     func() EventData { return &LogAdminBroadcastData{} },
+	EventTypeLogDeployableDamaged:  func() EventData { return &LogDeployableDamagedData{} },
+	EventTypeLogPlayerConnected:    func() EventData { return &LogPlayerConnectedData{} },
+	EventTypeLogPlayerDamaged:      func() EventData { return &LogPlayerDamagedData{} },
+	EventTypeLogPlayerDied:         func() EventData { return &LogPlayerDiedData{} },
+	EventTypeLogPlayerWounded:      func() EventData { return &LogPlayerWoundedData{} },
+	EventTypeLogPlayerRevived:      func() EventData { return &LogPlayerRevivedData{} },
+	EventTypeLogPlayerPossess:      func() EventData { return &LogPlayerPossessData{} },
+	EventTypeLogPlayerDisconnected: func() EventData { return &LogPlayerDisconnectedData{} },
+	EventTypeLogJoinSucceeded:      func() EventData { return &LogJoinSucceededData{} },
+	EventTypeLogTickRate:           func() EventData { return &LogTickRateData{} },
+	EventTypeLogGameEventUnified:   func() EventData { return &LogGameEventUnifiedData{} },
+	EventTypeLogCustom:             func() EventData { return &LogCustomData{} },
+	EventTypeLogVehicleDestroyed:   func() EventData { return &LogVehicleDestroyedData{} },
+	EventTypeLogDeployablePlaced:   func() EventData { return &LogDeployableData{} },
+	EventTypeLogDeployableRemoved:  func() EventData { return &LogDeployableData{} },
+	EventTypeLogSquadLeaderChanged: func() EventData { return &LogSquadLeaderChangedData{} },
+	EventTypeLogPlayerRoleChanged:  func() EventData { return &LogPlayerRoleChangedData{} },
+	EventTypeLogCommanderAssigned:  func() EventData { return &LogCommanderAssignedData{} },
+
+	// Player Tracker Events
+	EventTypePlayerListUpdated:  func() EventData { return &PlayerListUpdatedData{} },
+	EventTypePlayerTeamChanged:  func() EventData { return &PlayerTeamChangedData{} },
+	EventTypePlayerSquadChanged: func() EventData { return &PlayerSquadChangedData{} },
+	EventTypeSquadCreated:       func() EventData { return &SquadCreatedData{} },
+	EventTypeSquadDisbanded:     func() EventData { return &SquadDisbandedData{} },
+	EventTypePlayerConnected:    func() EventData { return &PlayerConnectedData{} },
+	EventTypePlayerDisconnected: func() EventData { return &PlayerDisconnectedData{} },
+	EventTypeEnhancedTeamkill:   func() EventData { return &EnhancedTeamkillData{} },
+	EventTypePlayerStatsUpdated: func() EventData { return &PlayerStatsUpdatedData{} },
+
+	// Admin Camera Events
+	EventTypeAdminCameraAlert: func() EventData { return &AdminCameraAlertData{} },
+
+	// Plugin Events
+	EventTypePluginCustom: func() EventData { return &PluginCustomEventData{} },
+}
+
+// DecodeEventData reconstructs typed event data from its JSON representation
+func DecodeEventData(eventType EventType, raw []byte) (EventData, error) {
+	factory, ok := eventDataFactories[eventType]
+	if !ok {
+		return nil, fmt.Errorf("no event data type registered for %s", eventType)
+	}
+
+	data := factory()
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event data: %w", eventType, err)
+	}
+
+	return data, nil
+}
+
+// EventDataFields returns the JSON field names of the data struct registered
+// for an event type. The second value is false for unregistered event types.
+func EventDataFields(eventType EventType) ([]string, bool) {
+	factory, ok := eventDataFactories[eventType]
+	if !ok {
+		return nil, false
+	}
+
+	dataType := reflect.TypeOf(factory())
+	if dataType.Kind() == reflect.Ptr {
+		dataType = dataType.Elem()
+	}
+
+	return structJSONFields(dataType), true
+}
+
+// structJSONFields lists the JSON names of a struct's exported fields,
+// including the fields of embedded structs
+func structJSONFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, structJSONFields(embedded)...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
@@ -0,0 +1,53 @@
+package event_manager
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+)
+
+func TestEventDataFactoriesCoverEventTypes(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "event_manager.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse event_manager.go: %v", err)
+	}
+
+	found := 0
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if ident, ok := value.Type.(*ast.Ident); !ok || ident.Name != "EventType" {
+				continue
+			}
+			for i, name := range value.Names {
+				lit, ok := value.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					t.Fatalf("%s is not a string literal", name.Name)
+				}
+				unquoted, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Fatalf("Failed to unquote %s: %v", name.Name, err)
+				}
+
+				found++
+				eventType := EventType(unquoted)
+				if eventType == EventTypeAll || transientEventTypes[eventType] {
+					continue
+				}
+				if _, ok := eventDataFactories[eventType]; !ok {
+					t.Errorf("%s (%s) has no registered event data factory", name.Name, eventType)
+				}
+			}
+		}
+	}
+
+	if found == 0 {
+		t.Fatalf("Found no EventType constants in event_manager.go")
+	}
+}
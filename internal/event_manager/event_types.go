@@ -1,5 +1,7 @@
 package event_manager
 
+import "time"
+
 // EventData is the base interface that all event data types must implement
 type EventData interface {
 	GetEventType() EventType
@@ -61,6 +63,25 @@ func (d RconAdminCameraData) GetEventType() EventType {
 	return EventTypeRconUnpossessedAdminCamera
 }
 
+// AdminCameraAlertData is published when an admin's camera use crosses one of
+// the server's alert thresholds
+type AdminCameraAlertData struct {
+	Alert           string    `json:"alert"` // "long_session", "frequent_sessions" or "ghosting_suspected"
+	SessionID       string    `json:"session_id"`
+	AdminName       string    `json:"admin_name"`
+	EosID           string    `json:"eos_id"`
+	SteamID         string    `json:"steam_id"`
+	TeamID          int       `json:"team_id"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	SessionCount    int       `json:"session_count,omitempty"`
+	KillsBefore     int       `json:"kills_before,omitempty"`
+	KillsAfter      int       `json:"kills_after,omitempty"`
+	Message         string    `json:"message"`
+}
+
+func (d AdminCameraAlertData) GetEventType() EventType { return EventTypeAdminCameraAlert }
+
 // RconSquadCreatedData represents RCON squad creation event data
 type RconSquadCreatedData struct {
 	PlayerName string `json:"player_name"`
@@ -83,6 +104,36 @@ type RconServerInfoData struct {
 
 func (d RconServerInfoData) GetEventType() EventType { return EventTypeRconServerInfo }
 
+// RconConnectedData represents an RCON connection being established or restored
+type RconConnectedData struct {
+	Reconnected     bool    `json:"reconnected"`
+	Attempts        int     `json:"attempts,omitempty"`         // Reconnect attempts it took to restore the connection
+	DowntimeSeconds float64 `json:"downtime_seconds,omitempty"` // How long the server was unreachable
+	LatencyMs       int64   `json:"latency_ms"`
+}
+
+func (d RconConnectedData) GetEventType() EventType { return EventTypeRconConnected }
+
+// RconDisconnectedData represents an RCON connection being given up on until
+// the next reconnect attempt
+type RconDisconnectedData struct {
+	Reason              string  `json:"reason"`
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	RetryInSeconds      float64 `json:"retry_in_seconds"`
+}
+
+func (d RconDisconnectedData) GetEventType() EventType { return EventTypeRconDisconnected }
+
+// RconDegradedData represents an RCON connection that still answers but is
+// failing or slow
+type RconDegradedData struct {
+	Reason              string `json:"reason"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	LatencyMs           int64  `json:"latency_ms,omitempty"`
+}
+
+func (d RconDegradedData) GetEventType() EventType { return EventTypeRconDegraded }
+
 // Log Event Data Types
 
 // LogAdminBroadcastData represents log admin broadcast event data
@@ -252,7 +303,7 @@ func (d LogTickRateData) GetEventType() EventType { return EventTypeLogTickRate
 type LogGameEventUnifiedData struct {
 	Time      string `json:"time"`
 	ChainID   string `json:"chain_id,omitempty"`
-	EventType string `json:"event_type"` // "ROUND_ENDED", "NEW_GAME", "MATCH_WINNER", "TICKET_UPDATE"
+	EventType string `json:"event_type"` // "ROUND_ENDED", "NEW_GAME", "MATCH_WINNER", "TICKET_UPDATE", "MATCH_STARTED", "MATCH_STATE_CHANGED"
 
 	// Round/Match data
 	Winner     string `json:"winner,omitempty"`
@@ -298,6 +349,96 @@ type LogPlayerDisconnectedData struct {
 
 func (d LogPlayerDisconnectedData) GetEventType() EventType { return EventTypeLogPlayerDisconnected }
 
+// LogCustomData represents a log line matched by an admin-defined parser
+type LogCustomData struct {
+	Time       string            `json:"time,omitempty"`
+	ChainID    string            `json:"chain_id,omitempty"`
+	ParserID   string            `json:"parser_id"`
+	ParserName string            `json:"parser_name"`
+	EventName  string            `json:"event_name"`
+	Fields     map[string]string `json:"fields"`
+	RawLog     string            `json:"raw_log"`
+}
+
+func (d LogCustomData) GetEventType() EventType { return EventTypeLogCustom }
+
+// LogVehicleDestroyedData represents log vehicle destroyed event data
+type LogVehicleDestroyedData struct {
+	Time               string `json:"time"`
+	ChainID            string `json:"chain_id"`
+	Vehicle            string `json:"vehicle"`
+	Damage             string `json:"damage"`
+	AttackerName       string `json:"attacker_name,omitempty"`
+	AttackerEOS        string `json:"attacker_eos,omitempty"`
+	AttackerSteam      string `json:"attacker_steam,omitempty"`
+	AttackerTeam       string `json:"attacker_team,omitempty"`
+	AttackerController string `json:"attacker_controller"`
+	Weapon             string `json:"weapon"`
+}
+
+func (d LogVehicleDestroyedData) GetEventType() EventType { return EventTypeLogVehicleDestroyed }
+
+// LogDeployableData represents a deployable being placed or removed
+type LogDeployableData struct {
+	Time           string `json:"time"`
+	ChainID        string `json:"chain_id"`
+	Action         string `json:"action"` // "placed" or "removed"
+	Deployable     string `json:"deployable"`
+	DeployableType string `json:"deployable_type"` // "FOB", "HAB" or "OTHER"
+	PlayerSuffix   string `json:"player_suffix,omitempty"`
+	PlayerEOS      string `json:"player_eos,omitempty"`
+	PlayerSteam    string `json:"player_steam,omitempty"`
+	TeamID         string `json:"team_id,omitempty"`
+}
+
+func (d LogDeployableData) GetEventType() EventType {
+	if d.Action == "placed" {
+		return EventTypeLogDeployablePlaced
+	}
+	return EventTypeLogDeployableRemoved
+}
+
+// LogSquadLeaderChangedData represents log squad leader changed event data
+type LogSquadLeaderChangedData struct {
+	Time         string `json:"time"`
+	ChainID      string `json:"chain_id"`
+	PlayerSuffix string `json:"player_suffix"`
+	PlayerEOS    string `json:"player_eos,omitempty"`
+	PlayerSteam  string `json:"player_steam,omitempty"`
+	SquadID      string `json:"squad_id"`
+	TeamID       string `json:"team_id"`
+}
+
+func (d LogSquadLeaderChangedData) GetEventType() EventType { return EventTypeLogSquadLeaderChanged }
+
+// LogPlayerRoleChangedData represents log player role (kit) changed event data
+type LogPlayerRoleChangedData struct {
+	Time         string `json:"time"`
+	ChainID      string `json:"chain_id"`
+	PlayerSuffix string `json:"player_suffix"`
+	PlayerEOS    string `json:"player_eos,omitempty"`
+	PlayerSteam  string `json:"player_steam,omitempty"`
+	Role         string `json:"role"`
+	TeamID       string `json:"team_id,omitempty"`
+	SquadID      string `json:"squad_id,omitempty"`
+	Commander    bool   `json:"commander,omitempty"`
+}
+
+func (d LogPlayerRoleChangedData) GetEventType() EventType { return EventTypeLogPlayerRoleChanged }
+
+// LogCommanderAssignedData represents a player taking the commander role
+type LogCommanderAssignedData struct {
+	Time         string `json:"time"`
+	ChainID      string `json:"chain_id"`
+	PlayerSuffix string `json:"player_suffix"`
+	PlayerEOS    string `json:"player_eos,omitempty"`
+	PlayerSteam  string `json:"player_steam,omitempty"`
+	Role         string `json:"role"`
+	TeamID       string `json:"team_id,omitempty"`
+}
+
+func (d LogCommanderAssignedData) GetEventType() EventType { return EventTypeLogCommanderAssigned }
+
 // Plugin Event Data Types
 
 // PluginCustomEventData represents custom event data from plugins
@@ -0,0 +1,418 @@
+package event_manager
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// JournalEntry is an event stored in the journal together with its sequence number
+type JournalEntry struct {
+	Sequence uint64
+	Event    Event
+}
+
+// EventJournal is a bounded, ordered log of published events. Sequence numbers
+// start at 1 and increase by one for every appended event. Once the journal is
+// full the oldest entries are discarded.
+type EventJournal interface {
+	// Append stores an event and returns its sequence number
+	Append(event Event) (uint64, error)
+	// Read returns up to limit entries with a sequence greater than after
+	Read(after uint64, limit int) ([]JournalEntry, error)
+	// FirstSequence returns the oldest sequence still retained (0 if empty)
+	FirstSequence() (uint64, error)
+	// LastSequence returns the newest sequence (0 if empty)
+	LastSequence() (uint64, error)
+	// LoadCursor returns the saved cursor of a named subscriber
+	LoadCursor(name string) (uint64, bool, error)
+	// SaveCursor persists the cursor of a named subscriber
+	SaveCursor(name string, sequence uint64) error
+	// Close releases resources held by the journal
+	Close() error
+}
+
+// journalRecord is the serialized form of a journal entry
+type journalRecord struct {
+	Sequence  uint64          `json:"seq"`
+	ID        uuid.UUID       `json:"id"`
+	ServerID  uuid.UUID       `json:"server_id"`
+	Type      EventType       `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	RawData   string          `json:"raw_data,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// encodeJournalRecord serializes an event for storage. Raw data is only stored
+// when it is the original log line, since consumers such as plugins and the
+// ClickHouse ingester rely on it; other raw values are often not serializable.
+func encodeJournalRecord(sequence uint64, event Event) ([]byte, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	rawData, _ := event.RawData.(string)
+
+	return json.Marshal(journalRecord{
+		Sequence:  sequence,
+		ID:        event.ID,
+		ServerID:  event.ServerID,
+		Type:      event.Type,
+		Data:      data,
+		RawData:   rawData,
+		Timestamp: event.Timestamp,
+	})
+}
+
+// decodeJournalRecord restores a journal entry from its serialized form
+func decodeJournalRecord(raw []byte) (JournalEntry, error) {
+	var record journalRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to unmarshal journal record: %w", err)
+	}
+
+	data, err := DecodeEventData(record.Type, record.Data)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	event := Event{
+		ID:        record.ID,
+		ServerID:  record.ServerID,
+		Type:      record.Type,
+		Data:      data,
+		Timestamp: record.Timestamp,
+	}
+	if record.RawData != "" {
+		event.RawData = record.RawData
+	}
+
+	return JournalEntry{Sequence: record.Sequence, Event: event}, nil
+}
+
+// FileJournal is an EventJournal backed by an append-only file on disk. The
+// retained window is also kept in memory so reads never touch the disk.
+type FileJournal struct {
+	mu         sync.RWMutex
+	dir        string
+	maxEntries int
+	file       *os.File
+	fileLines  int
+	entries    []JournalEntry
+	lastSeq    uint64
+	cursors    map[string]uint64
+}
+
+const (
+	fileJournalEventsName  = "events.jsonl"
+	fileJournalCursorsName = "cursors.json"
+)
+
+// NewFileJournal opens (or creates) a file journal in dir retaining at most maxEntries events
+func NewFileJournal(dir string, maxEntries int) (*FileJournal, error) {
+	if maxEntries <= 0 {
+		maxEntries = 100000
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	j := &FileJournal{
+		dir:        dir,
+		maxEntries: maxEntries,
+		cursors:    make(map[string]uint64),
+	}
+
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+
+	// Rewrite the file if it has grown past the retained window
+	if j.fileLines > len(j.entries) {
+		if err := j.compact(); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, fileJournalEventsName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal file: %w", err)
+	}
+	j.file = file
+
+	log.Info().
+		Str("dir", dir).
+		Int("max_entries", maxEntries).
+		Int("entries", len(j.entries)).
+		Uint64("last_sequence", j.lastSeq).
+		Msg("Opened file event journal")
+
+	return j, nil
+}
+
+// load reads the existing journal and cursor files
+func (j *FileJournal) load() error {
+	if raw, err := os.ReadFile(filepath.Join(j.dir, fileJournalCursorsName)); err == nil {
+		if err := json.Unmarshal(raw, &j.cursors); err != nil {
+			log.Warn().Err(err).Msg("Failed to parse journal cursors, starting without saved cursors")
+			j.cursors = make(map[string]uint64)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read journal cursors: %w", err)
+	}
+
+	file, err := os.Open(filepath.Join(j.dir, fileJournalEventsName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open journal file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		j.fileLines++
+
+		entry, err := decodeJournalRecord(line)
+		if err != nil {
+			// A torn write at the end of the file or an unknown event type; skip it
+			log.Warn().Err(err).Msg("Skipping unreadable journal record")
+			continue
+		}
+
+		if entry.Sequence <= j.lastSeq {
+			continue
+		}
+		j.lastSeq = entry.Sequence
+		j.entries = append(j.entries, entry)
+		j.trimLocked()
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read journal file: %w", err)
+	}
+
+	return nil
+}
+
+// trimLocked drops entries beyond the retained window
+func (j *FileJournal) trimLocked() {
+	if len(j.entries) <= j.maxEntries {
+		return
+	}
+
+	// Reallocate occasionally instead of on every append so the backing array doesn't grow forever
+	if len(j.entries) > j.maxEntries+j.maxEntries/4 {
+		kept := make([]JournalEntry, j.maxEntries, j.maxEntries+j.maxEntries/4+1)
+		copy(kept, j.entries[len(j.entries)-j.maxEntries:])
+		j.entries = kept
+	}
+}
+
+// window returns the retained entries
+func (j *FileJournal) window() []JournalEntry {
+	if len(j.entries) > j.maxEntries {
+		return j.entries[len(j.entries)-j.maxEntries:]
+	}
+	return j.entries
+}
+
+// compact rewrites the journal file so it only holds the retained window
+func (j *FileJournal) compact() error {
+	path := filepath.Join(j.dir, fileJournalEventsName)
+	tmpPath := path + ".tmp"
+
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted journal: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	window := j.window()
+	for _, entry := range window {
+		line, err := encodeJournalRecord(entry.Sequence, entry.Event)
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write compacted journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close compacted journal: %w", err)
+	}
+
+	if j.file != nil {
+		j.file.Close()
+		j.file = nil
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace journal file: %w", err)
+	}
+	j.fileLines = len(window)
+
+	return nil
+}
+
+// Append stores an event and returns its sequence number
+func (j *FileJournal) Append(event Event) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return 0, fmt.Errorf("journal is closed")
+	}
+
+	sequence := j.lastSeq + 1
+	line, err := encodeJournalRecord(sequence, event)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return 0, fmt.Errorf("failed to write journal record: %w", err)
+	}
+
+	j.lastSeq = sequence
+	j.fileLines++
+	j.entries = append(j.entries, JournalEntry{Sequence: sequence, Event: event})
+	j.trimLocked()
+
+	if j.fileLines >= 2*j.maxEntries {
+		if err := j.compact(); err != nil {
+			log.Error().Err(err).Msg("Failed to compact event journal")
+		}
+		if j.file == nil {
+			file, err := os.OpenFile(filepath.Join(j.dir, fileJournalEventsName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return sequence, fmt.Errorf("failed to reopen journal file: %w", err)
+			}
+			j.file = file
+		}
+	}
+
+	return sequence, nil
+}
+
+// Read returns up to limit entries with a sequence greater than after
+func (j *FileJournal) Read(after uint64, limit int) ([]JournalEntry, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	window := j.window()
+	if len(window) == 0 || after >= j.lastSeq {
+		return nil, nil
+	}
+
+	// Sequences can have gaps where unreadable records were skipped on load,
+	// so the start is searched for rather than computed
+	start := sort.Search(len(window), func(i int) bool {
+		return window[i].Sequence > after
+	})
+	if start == len(window) {
+		return nil, nil
+	}
+
+	end := len(window)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	result := make([]JournalEntry, end-start)
+	copy(result, window[start:end])
+	return result, nil
+}
+
+// FirstSequence returns the oldest sequence still retained
+func (j *FileJournal) FirstSequence() (uint64, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	window := j.window()
+	if len(window) == 0 {
+		return 0, nil
+	}
+	return window[0].Sequence, nil
+}
+
+// LastSequence returns the newest sequence
+func (j *FileJournal) LastSequence() (uint64, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.lastSeq, nil
+}
+
+// LoadCursor returns the saved cursor of a named subscriber
+func (j *FileJournal) LoadCursor(name string) (uint64, bool, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	cursor, ok := j.cursors[name]
+	return cursor, ok, nil
+}
+
+// SaveCursor persists the cursor of a named subscriber
+func (j *FileJournal) SaveCursor(name string, sequence uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.cursors[name] == sequence {
+		return nil
+	}
+	j.cursors[name] = sequence
+
+	raw, err := json.Marshal(j.cursors)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal cursors: %w", err)
+	}
+
+	path := filepath.Join(j.dir, fileJournalCursorsName)
+	if err := os.WriteFile(path+".tmp", raw, 0644); err != nil {
+		return fmt.Errorf("failed to write journal cursors: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace journal cursors: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the journal file
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
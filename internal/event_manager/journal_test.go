@@ -0,0 +1,204 @@
+package event_manager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testEvent(message string) Event {
+	return Event{
+		ID:        uuid.New(),
+		ServerID:  uuid.New(),
+		Type:      EventTypeRconChatMessage,
+		Data:      &RconChatMessageData{Message: message},
+		Timestamp: time.Now(),
+	}
+}
+
+func TestFileJournalAppendRead(t *testing.T) {
+	journal, err := NewFileJournal(t.TempDir(), 3)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	defer journal.Close()
+
+	for i, message := range []string{"a", "b", "c", "d"} {
+		sequence, err := journal.Append(testEvent(message))
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		if sequence != uint64(i+1) {
+			t.Errorf("Expected sequence %d, got %d", i+1, sequence)
+		}
+	}
+
+	first, _ := journal.FirstSequence()
+	if first != 2 {
+		t.Errorf("Expected oldest retained sequence 2, got %d", first)
+	}
+
+	entries, err := journal.Read(2, 10)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Sequence != 3 || entries[1].Sequence != 4 {
+		t.Fatalf("Unexpected entries after 2: %+v", entries)
+	}
+
+	// Reading from before the retained window starts at the oldest entry
+	entries, _ = journal.Read(0, 1)
+	if len(entries) != 1 || entries[0].Sequence != 2 {
+		t.Errorf("Expected to read sequence 2 first, got %+v", entries)
+	}
+}
+
+func TestFileJournalReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	journal, err := NewFileJournal(dir, 10)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	journal.Append(testEvent("hello"))
+	world := testEvent("world")
+	world.RawData = "[2025.01.01-00.00.00:000][  1]LogSquad: world"
+	journal.Append(world)
+	if err := journal.SaveCursor("ingester", 1); err != nil {
+		t.Fatalf("SaveCursor failed: %v", err)
+	}
+	journal.Close()
+
+	journal, err = NewFileJournal(dir, 10)
+	if err != nil {
+		t.Fatalf("Failed to reopen journal: %v", err)
+	}
+	defer journal.Close()
+
+	last, _ := journal.LastSequence()
+	if last != 2 {
+		t.Errorf("Expected last sequence 2 after reopen, got %d", last)
+	}
+
+	cursor, ok, _ := journal.LoadCursor("ingester")
+	if !ok || cursor != 1 {
+		t.Errorf("Expected saved cursor 1, got %d (found=%v)", cursor, ok)
+	}
+
+	entries, _ := journal.Read(cursor, 10)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry after cursor, got %d", len(entries))
+	}
+	data, ok := entries[0].Event.Data.(*RconChatMessageData)
+	if !ok || data.Message != "world" {
+		t.Errorf("Expected decoded chat message 'world', got %#v", entries[0].Event.Data)
+	}
+	if entries[0].Event.RawData != world.RawData {
+		t.Errorf("Expected the raw log line to survive a reopen, got %#v", entries[0].Event.RawData)
+	}
+}
+
+func TestFileJournalReadAcrossGaps(t *testing.T) {
+	dir := t.TempDir()
+
+	// Records 2 and 4 can't be read back: a torn write and an unknown event type
+	var lines []string
+	for sequence := uint64(1); sequence <= 6; sequence++ {
+		event := testEvent(string(rune('a' + sequence - 1)))
+		switch sequence {
+		case 2:
+			lines = append(lines, `{"seq":2,"id":`)
+			continue
+		case 4:
+			event.Type = "UNKNOWN_EVENT"
+		}
+		line, err := encodeJournalRecord(sequence, event)
+		if err != nil {
+			t.Fatalf("Failed to encode record: %v", err)
+		}
+		lines = append(lines, string(line))
+	}
+	if err := os.WriteFile(filepath.Join(dir, fileJournalEventsName), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write journal: %v", err)
+	}
+
+	journal, err := NewFileJournal(dir, 10)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	defer journal.Close()
+
+	sequences := func(after uint64, limit int) []uint64 {
+		entries, err := journal.Read(after, limit)
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		var result []uint64
+		for _, entry := range entries {
+			result = append(result, entry.Sequence)
+		}
+		return result
+	}
+
+	for _, test := range []struct {
+		after uint64
+		limit int
+		want  []uint64
+	}{
+		{0, 10, []uint64{1, 3, 5, 6}},
+		{1, 10, []uint64{3, 5, 6}},
+		{2, 10, []uint64{3, 5, 6}},
+		{3, 1, []uint64{5}},
+		{4, 10, []uint64{5, 6}},
+		{5, 10, []uint64{6}},
+		{6, 10, nil},
+	} {
+		got := sequences(test.after, test.limit)
+		if len(got) != len(test.want) {
+			t.Errorf("Read(%d, %d) = %v, expected %v", test.after, test.limit, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("Read(%d, %d) = %v, expected %v", test.after, test.limit, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDurableEventManagerBackpressure(t *testing.T) {
+	journal, err := NewFileJournal(t.TempDir(), 100)
+	if err != nil {
+		t.Fatalf("Failed to open journal: %v", err)
+	}
+	defer journal.Close()
+
+	em := NewDurableEventManager(t.Context(), 10, journal)
+	defer em.Shutdown()
+
+	// A channel of one forces the subscriber to fall behind
+	subscriber := em.SubscribeDurable("test", EventFilter{}, nil, 1)
+
+	serverID := uuid.New()
+	for i := 0; i < 20; i++ {
+		em.PublishEvent(serverID, &RconChatMessageData{Message: "msg"}, nil)
+	}
+
+	for i := 0; i < 20; i++ {
+		select {
+		case <-subscriber.Channel:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Only received %d of 20 events", i)
+		}
+	}
+
+	stats := em.GetEventStats()
+	if stats["dropped_subscriber_full"].(uint64) != 0 {
+		t.Errorf("Expected no dropped events, got %v", stats["dropped_subscriber_full"])
+	}
+}
@@ -91,6 +91,22 @@ type PlayerDisconnectedData struct {
 
 func (d PlayerDisconnectedData) GetEventType() EventType { return EventTypePlayerDisconnected }
 
+// EnhancedTeamkillData is published when a death is confirmed as a teamkill
+// using the tracked teams of both players
+type EnhancedTeamkillData struct {
+	AttackerEOSID   string    `json:"attacker_eos_id"`
+	AttackerSteamID string    `json:"attacker_steam_id"`
+	AttackerName    string    `json:"attacker_name"`
+	VictimEOSID     string    `json:"victim_eos_id"`
+	VictimSteamID   string    `json:"victim_steam_id"`
+	VictimName      string    `json:"victim_name"`
+	TeamID          string    `json:"team_id"`
+	Weapon          string    `json:"weapon"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+func (d EnhancedTeamkillData) GetEventType() EventType { return EventTypeEnhancedTeamkill }
+
 // PlayerStatsUpdatedData is published when player statistics are updated
 type PlayerStatsUpdatedData struct {
 	EOSID      string    `json:"eos_id"`
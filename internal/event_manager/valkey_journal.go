@@ -0,0 +1,181 @@
+package event_manager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/valkey-io/valkey-go"
+	valkeyClient "go.codycody31.dev/squad-aegis/internal/valkey"
+)
+
+const (
+	valkeyJournalStreamKey = "squad-aegis:events:journal"
+	valkeyJournalCursorKey = "squad-aegis:events:cursors"
+	valkeyJournalTimeout   = 5 * time.Second
+)
+
+// ValkeyJournal is an EventJournal backed by a Valkey stream. Stream entry IDs
+// are "<sequence>-0" so sequence lookups map directly onto XRANGE.
+type ValkeyJournal struct {
+	client     *valkeyClient.Client
+	maxEntries int64
+	mu         sync.Mutex
+	lastSeq    uint64
+}
+
+// NewValkeyJournal creates a journal on the shared Valkey stream retaining roughly maxEntries events
+func NewValkeyJournal(ctx context.Context, client *valkeyClient.Client, maxEntries int) (*ValkeyJournal, error) {
+	if maxEntries <= 0 {
+		maxEntries = 100000
+	}
+
+	j := &ValkeyJournal{
+		client:     client,
+		maxEntries: int64(maxEntries),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, valkeyJournalTimeout)
+	defer cancel()
+
+	latest, err := client.XRevRange(ctx, valkeyJournalStreamKey, "+", "-", 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal stream: %w", err)
+	}
+	if len(latest) > 0 {
+		j.lastSeq, err = parseStreamSequence(latest[0].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info().
+		Str("stream", valkeyJournalStreamKey).
+		Int("max_entries", maxEntries).
+		Uint64("last_sequence", j.lastSeq).
+		Msg("Opened Valkey event journal")
+
+	return j, nil
+}
+
+// parseStreamSequence extracts the sequence number from a stream entry ID
+func parseStreamSequence(id string) (uint64, error) {
+	millis, _, _ := strings.Cut(id, "-")
+	sequence, err := strconv.ParseUint(millis, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid journal stream id %q: %w", id, err)
+	}
+	return sequence, nil
+}
+
+// Append stores an event and returns its sequence number
+func (j *ValkeyJournal) Append(event Event) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	sequence := j.lastSeq + 1
+	record, err := encodeJournalRecord(sequence, event)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), valkeyJournalTimeout)
+	defer cancel()
+
+	_, err = j.client.XAdd(ctx, valkeyJournalStreamKey, fmt.Sprintf("%d-0", sequence), j.maxEntries, map[string]string{
+		"event": string(record),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to append to journal stream: %w", err)
+	}
+
+	j.lastSeq = sequence
+	return sequence, nil
+}
+
+// Read returns up to limit entries with a sequence greater than after
+func (j *ValkeyJournal) Read(after uint64, limit int) ([]JournalEntry, error) {
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), valkeyJournalTimeout)
+	defer cancel()
+
+	streamEntries, err := j.client.XRange(ctx, valkeyJournalStreamKey, fmt.Sprintf("%d-0", after+1), "+", int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal stream: %w", err)
+	}
+
+	entries := make([]JournalEntry, 0, len(streamEntries))
+	for _, streamEntry := range streamEntries {
+		entry, err := decodeJournalRecord([]byte(streamEntry.Fields["event"]))
+		if err != nil {
+			log.Warn().Err(err).Str("stream_id", streamEntry.ID).Msg("Skipping unreadable journal record")
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// FirstSequence returns the oldest sequence still retained
+func (j *ValkeyJournal) FirstSequence() (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), valkeyJournalTimeout)
+	defer cancel()
+
+	oldest, err := j.client.XRange(ctx, valkeyJournalStreamKey, "-", "+", 1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read journal stream: %w", err)
+	}
+	if len(oldest) == 0 {
+		return 0, nil
+	}
+	return parseStreamSequence(oldest[0].ID)
+}
+
+// LastSequence returns the newest sequence
+func (j *ValkeyJournal) LastSequence() (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.lastSeq, nil
+}
+
+// LoadCursor returns the saved cursor of a named subscriber
+func (j *ValkeyJournal) LoadCursor(name string) (uint64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), valkeyJournalTimeout)
+	defer cancel()
+
+	value, err := j.client.HGet(ctx, valkeyJournalCursorKey, name)
+	if err != nil {
+		if err == valkey.Nil {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to load journal cursor: %w", err)
+	}
+
+	cursor, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid journal cursor %q: %w", value, err)
+	}
+	return cursor, true, nil
+}
+
+// SaveCursor persists the cursor of a named subscriber
+func (j *ValkeyJournal) SaveCursor(name string, sequence uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), valkeyJournalTimeout)
+	defer cancel()
+
+	return j.client.HSet(ctx, valkeyJournalCursorKey, name, strconv.FormatUint(sequence, 10))
+}
+
+// Close is a no-op; the Valkey client is owned by the caller
+func (j *ValkeyJournal) Close() error {
+	return nil
+}
@@ -12,6 +12,9 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
+// maxDownloadSize caps the size of a file read from a game server
+const maxDownloadSize = 16 << 20
+
 // UploadConfig holds configuration for file upload
 type UploadConfig struct {
 	Protocol string // "sftp" or "ftp"
@@ -25,6 +28,7 @@ type UploadConfig struct {
 // Uploader interface for file upload implementations
 type Uploader interface {
 	Upload(ctx context.Context, content string) error
+	Download(ctx context.Context) (string, error)
 	TestConnection(ctx context.Context) error
 	Close() error
 }
@@ -107,6 +111,21 @@ func (u *SFTPUploader) Upload(ctx context.Context, content string) error {
 	return nil
 }
 
+// Download reads the content of the remote file
+func (u *SFTPUploader) Download(ctx context.Context) (string, error) {
+	if u.sftpClient == nil {
+		return "", fmt.Errorf("SFTP client not connected")
+	}
+
+	file, err := u.sftpClient.Open(u.config.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open remote file: %v", err)
+	}
+	defer file.Close()
+
+	return readDownload(file)
+}
+
 // TestConnection verifies the connection is working
 func (u *SFTPUploader) TestConnection(ctx context.Context) error {
 	if u.sftpClient == nil {
@@ -195,6 +214,21 @@ func (u *FTPUploader) Upload(ctx context.Context, content string) error {
 	return nil
 }
 
+// Download reads the content of the remote file
+func (u *FTPUploader) Download(ctx context.Context) (string, error) {
+	if u.conn == nil {
+		return "", fmt.Errorf("FTP connection not established")
+	}
+
+	resp, err := u.conn.Retr(u.config.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to download file: %v", err)
+	}
+	defer resp.Close()
+
+	return readDownload(resp)
+}
+
 // TestConnection verifies the connection is working
 func (u *FTPUploader) TestConnection(ctx context.Context) error {
 	if u.conn == nil {
@@ -219,3 +253,14 @@ func (u *FTPUploader) Close() error {
 	}
 	return nil
 }
+
+func readDownload(r io.Reader) (string, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxDownloadSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read content: %v", err)
+	}
+	if len(content) > maxDownloadSize {
+		return "", fmt.Errorf("file is larger than %d bytes", maxDownloadSize)
+	}
+	return string(content), nil
+}
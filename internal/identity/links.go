@@ -0,0 +1,191 @@
+package identity
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// IdentityLink is a Steam ID and EOS ID seen together, and how many
+// connections they were seen together on
+type IdentityLink struct {
+	Steam        string `json:"steam"`
+	EOS          string `json:"eos"`
+	Observations uint64 `json:"observations"`
+}
+
+// LinkMatch is the strongest chain of links between a player and a linked
+// identifier. Confidence is a percentage.
+type LinkMatch struct {
+	Identifier string         `json:"identifier"`
+	Confidence int            `json:"confidence"`
+	Links      []IdentityLink `json:"links"`
+}
+
+// SteamKey and EOSKey build the identifiers used by the identity graph
+func SteamKey(steamID string) string { return "steam:" + steamID }
+func EOSKey(eosID string) string     { return "eos:" + eosID }
+
+// linkConfidence is the confidence in a single link. Each connection the pair
+// is seen on halves the chance that the link is a coincidence.
+func linkConfidence(observations uint64) float64 {
+	return 1 - math.Pow(0.5, float64(observations))
+}
+
+// FindLinkPath finds the chain of links from any of the given identifiers to
+// the target with the highest confidence. The confidence of a chain is the
+// product of the confidence of its links, so every hop lowers it.
+func FindLinkPath(links []IdentityLink, from []string, target string) (*LinkMatch, bool) {
+	type edge struct {
+		to   string
+		link IdentityLink
+	}
+	graph := make(map[string][]edge)
+	for _, link := range links {
+		if link.Steam == "" || link.EOS == "" || link.Observations == 0 {
+			continue
+		}
+		steam, eos := SteamKey(link.Steam), EOSKey(link.EOS)
+		graph[steam] = append(graph[steam], edge{to: eos, link: link})
+		graph[eos] = append(graph[eos], edge{to: steam, link: link})
+	}
+
+	// Dijkstra over the best confidence; multiplying by a confidence of at
+	// most one never improves a path, so the first visit is the best one
+	best := make(map[string]float64)
+	via := make(map[string]edge)
+	prev := make(map[string]string)
+	visited := make(map[string]bool)
+	for _, id := range from {
+		best[id] = 1
+	}
+
+	for {
+		current, currentConfidence := "", 0.0
+		for id, confidence := range best {
+			if !visited[id] && (confidence > currentConfidence || (confidence == currentConfidence && id < current)) {
+				current, currentConfidence = id, confidence
+			}
+		}
+		if current == "" {
+			return nil, false
+		}
+		visited[current] = true
+
+		if current == target {
+			break
+		}
+
+		for _, e := range graph[current] {
+			confidence := currentConfidence * linkConfidence(e.link.Observations)
+			if !visited[e.to] && confidence > best[e.to] {
+				best[e.to] = confidence
+				via[e.to] = e
+				prev[e.to] = current
+			}
+		}
+	}
+
+	match := &LinkMatch{
+		Identifier: target,
+		Confidence: int(math.Floor(best[target] * 100)),
+		Links:      []IdentityLink{},
+	}
+	for id := target; ; {
+		parent, ok := prev[id]
+		if !ok {
+			break
+		}
+		match.Links = append(match.Links, via[id].link)
+		id = parent
+	}
+	// Order the links from the player to the target
+	for i, j := 0, len(match.Links)-1; i < j; i, j = i+1, j-1 {
+		match.Links[i], match.Links[j] = match.Links[j], match.Links[i]
+	}
+
+	return match, true
+}
+
+// LinkedIdentifiers returns every Steam ID and EOS ID in the identity groups
+// of the given identifiers, as last computed by the identity worker
+func (r *Resolver) LinkedIdentifiers(ctx context.Context, steamID, eosID string) ([]string, []string, error) {
+	rows, err := r.clickhouse.Query(ctx, `
+		SELECT all_steam_ids, all_eos_ids
+		FROM squad_aegis.player_identities FINAL
+		WHERE canonical_id IN (
+			SELECT canonical_id
+			FROM squad_aegis.player_identity_lookup FINAL
+			WHERE (identifier_type = 'steam' AND identifier_value = ?)
+			   OR (identifier_type = 'eos' AND identifier_value = ?)
+		)
+	`, steamID, eosID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	steamSet := make(map[string]struct{})
+	eosSet := make(map[string]struct{})
+	for rows.Next() {
+		var steamIDs, eosIDs []string
+		if err := rows.Scan(&steamIDs, &eosIDs); err != nil {
+			return nil, nil, err
+		}
+		for _, id := range steamIDs {
+			steamSet[id] = struct{}{}
+		}
+		for _, id := range eosIDs {
+			eosSet[id] = struct{}{}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return setToSortedSlice(steamSet), setToSortedSlice(eosSet), nil
+}
+
+// IdentityLinks returns the Steam and EOS ID pairs that connected with any of
+// the given identifiers, and how often each pair connected
+func (r *Resolver) IdentityLinks(ctx context.Context, steamIDs, eosIDs []string) ([]IdentityLink, error) {
+	// An empty IN list is a syntax error
+	if len(steamIDs) == 0 {
+		steamIDs = []string{""}
+	}
+	if len(eosIDs) == 0 {
+		eosIDs = []string{""}
+	}
+
+	rows, err := r.clickhouse.Query(ctx, `
+		SELECT steam, eos, count() AS observations
+		FROM squad_aegis.server_player_connected_events
+		WHERE steam != '' AND eos != '' AND (steam IN (?) OR eos IN (?))
+		GROUP BY steam, eos
+	`, steamIDs, eosIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []IdentityLink{}
+	for rows.Next() {
+		var link IdentityLink
+		if err := rows.Scan(&link.Steam, &link.EOS, &link.Observations); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Steam != links[j].Steam {
+			return links[i].Steam < links[j].Steam
+		}
+		return links[i].EOS < links[j].EOS
+	})
+
+	return links, nil
+}
@@ -0,0 +1,69 @@
+package identity
+
+import "testing"
+
+func TestFindLinkPathDirectLink(t *testing.T) {
+	links := []IdentityLink{{Steam: "1", EOS: "a", Observations: 2}}
+
+	match, ok := FindLinkPath(links, []string{SteamKey("1")}, EOSKey("a"))
+	if !ok {
+		t.Fatal("expected a path")
+	}
+	if match.Confidence != 75 {
+		t.Errorf("expected 75%% confidence for a link seen twice, got %d", match.Confidence)
+	}
+	if len(match.Links) != 1 || match.Links[0].EOS != "a" {
+		t.Errorf("unexpected links: %v", match.Links)
+	}
+}
+
+func TestFindLinkPathPrefersStrongestChain(t *testing.T) {
+	links := []IdentityLink{
+		// Weak direct link
+		{Steam: "1", EOS: "b", Observations: 1},
+		// Strong chain through a second account
+		{Steam: "1", EOS: "a", Observations: 10},
+		{Steam: "2", EOS: "a", Observations: 10},
+		{Steam: "2", EOS: "b", Observations: 10},
+	}
+
+	match, ok := FindLinkPath(links, []string{SteamKey("1")}, EOSKey("b"))
+	if !ok {
+		t.Fatal("expected a path")
+	}
+	if len(match.Links) != 3 {
+		t.Fatalf("expected the three link chain, got %v", match.Links)
+	}
+	if match.Links[0].Steam != "1" || match.Links[2].EOS != "b" {
+		t.Errorf("expected the links in order from the player, got %v", match.Links)
+	}
+	if match.Confidence != 99 {
+		t.Errorf("expected 99%% confidence, got %d", match.Confidence)
+	}
+}
+
+func TestFindLinkPathEachHopLowersConfidence(t *testing.T) {
+	links := []IdentityLink{
+		{Steam: "1", EOS: "a", Observations: 1},
+		{Steam: "2", EOS: "a", Observations: 1},
+	}
+
+	match, ok := FindLinkPath(links, []string{SteamKey("1")}, SteamKey("2"))
+	if !ok {
+		t.Fatal("expected a path")
+	}
+	if match.Confidence != 25 {
+		t.Errorf("expected 25%% confidence for two links seen once, got %d", match.Confidence)
+	}
+}
+
+func TestFindLinkPathUnlinked(t *testing.T) {
+	links := []IdentityLink{
+		{Steam: "1", EOS: "a", Observations: 5},
+		{Steam: "2", EOS: "b", Observations: 5},
+	}
+
+	if _, ok := FindLinkPath(links, []string{SteamKey("1"), EOSKey("a")}, SteamKey("2")); ok {
+		t.Error("expected no path between unlinked identities")
+	}
+}
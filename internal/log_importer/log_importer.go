@@ -0,0 +1,257 @@
+package log_importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/clickhouse"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/logwatcher_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/storage"
+)
+
+// Log import job statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// backfillBatchSize is how many parsed events are written to ClickHouse at once
+const backfillBatchSize = 1000
+
+// ErrJobNotActive is returned when cancelling a job that isn't pending or running
+var ErrJobNotActive = errors.New("log import job is not pending or running")
+
+// Importer replays historical SquadGame.log files into ClickHouse. Events are
+// parsed by the same parsers as live logs but never published to the event
+// manager, so plugins and workflows don't react to them. Jobs run one at a
+// time in the order they were created.
+type Importer struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	db       *sql.DB
+	ingester *clickhouse.EventIngester
+	storage  storage.Storage
+
+	runMu sync.Mutex // held by the running job
+	mu    sync.Mutex
+	jobs  map[uuid.UUID]context.CancelFunc
+	wg    sync.WaitGroup
+}
+
+// NewImporter creates a new log importer
+func NewImporter(ctx context.Context, db *sql.DB, ingester *clickhouse.EventIngester, storage storage.Storage) *Importer {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &Importer{
+		ctx:      ctx,
+		cancel:   cancel,
+		db:       db,
+		ingester: ingester,
+		storage:  storage,
+		jobs:     make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+// Start fails the jobs a previous process didn't finish. Their files are kept,
+// so they can be imported again; rows already written are skipped.
+func (im *Importer) Start() {
+	count, err := core.FailInterruptedLogImportJobs(im.ctx, im.db)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fail interrupted log import jobs")
+		return
+	}
+	if count > 0 {
+		log.Warn().Int64("count", count).Msg("Marked interrupted log import jobs as failed")
+	}
+}
+
+// Stop cancels all jobs and waits for them to stop
+func (im *Importer) Stop() {
+	im.cancel()
+	im.wg.Wait()
+}
+
+// Submit creates a job importing the given storage files, in order, and
+// queues it
+func (im *Importer) Submit(ctx context.Context, serverID uuid.UUID, files []string, createdBy *uuid.UUID) (*models.LogImportJob, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no files to import")
+	}
+
+	job := &models.LogImportJob{
+		ID:        uuid.New(),
+		ServerID:  serverID,
+		Files:     files,
+		CreatedBy: createdBy,
+	}
+	if err := core.CreateLogImportJob(ctx, im.db, job); err != nil {
+		return nil, err
+	}
+
+	jobCtx, cancel := context.WithCancel(im.ctx)
+	im.mu.Lock()
+	im.jobs[job.ID] = cancel
+	im.mu.Unlock()
+
+	im.wg.Add(1)
+	go func() {
+		defer im.wg.Done()
+		defer func() {
+			im.mu.Lock()
+			delete(im.jobs, job.ID)
+			im.mu.Unlock()
+			cancel()
+		}()
+
+		im.runMu.Lock()
+		defer im.runMu.Unlock()
+
+		im.run(jobCtx, job)
+	}()
+
+	return job, nil
+}
+
+// Cancel stops a pending or running job. Events already written stay in
+// ClickHouse.
+func (im *Importer) Cancel(jobID uuid.UUID) error {
+	im.mu.Lock()
+	cancel, ok := im.jobs[jobID]
+	im.mu.Unlock()
+
+	if !ok {
+		return ErrJobNotActive
+	}
+	cancel()
+	return nil
+}
+
+// run imports the files of a job and records the outcome
+func (im *Importer) run(ctx context.Context, job *models.LogImportJob) {
+	logger := log.With().Str("jobID", job.ID.String()).Str("serverID", job.ServerID.String()).Logger()
+
+	err := ctx.Err()
+	if err == nil {
+		if err = core.StartLogImportJob(ctx, im.db, job.ID); err == nil {
+			job.Status = StatusRunning
+			logger.Info().Strs("files", job.Files).Msg("Starting log import")
+			err = im.importFiles(ctx, job)
+		}
+	}
+
+	switch {
+	case err == nil:
+		job.Status = StatusCompleted
+	case errors.Is(err, context.Canceled):
+		job.Status = StatusCancelled
+	default:
+		job.Status = StatusFailed
+		message := err.Error()
+		job.Error = &message
+	}
+
+	// The job context may be cancelled, so record the outcome with a fresh one
+	finishCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := core.FinishLogImportJob(finishCtx, im.db, job); err != nil {
+		logger.Error().Err(err).Msg("Failed to record log import result")
+	}
+
+	logger.Info().
+		Str("status", job.Status).
+		Int64("lines", job.LinesProcessed).
+		Int64("parsed", job.EventsParsed).
+		Int64("inserted", job.EventsInserted).
+		Int64("skipped", job.EventsSkipped).
+		Msg("Log import finished")
+}
+
+// importFiles replays every file of a job. The event store is shared between
+// files so events spanning a log rotation are still correlated.
+func (im *Importer) importFiles(ctx context.Context, job *models.LogImportJob) error {
+	eventStore := logwatcher_manager.NewMemoryEventStore(job.ServerID)
+
+	replayCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var batch []*clickhouse.IngestEvent
+	var flushErr error
+
+	flush := func() {
+		if len(batch) == 0 || flushErr != nil {
+			return
+		}
+
+		inserted, skipped, err := im.ingester.Backfill(replayCtx, batch)
+		job.EventsInserted += int64(inserted)
+		job.EventsSkipped += int64(skipped)
+		batch = batch[:0]
+		if err != nil {
+			flushErr = err
+			cancel()
+			return
+		}
+
+		if err := core.UpdateLogImportJobProgress(replayCtx, im.db, job); err != nil {
+			log.Warn().Err(err).Str("jobID", job.ID.String()).Msg("Failed to update log import progress")
+		}
+	}
+
+	handle := func(event event_manager.Event) {
+		job.EventsParsed++
+		batch = append(batch, clickhouse.NewIngestEvent(event))
+		if len(batch) >= backfillBatchSize {
+			flush()
+		}
+	}
+
+	for _, path := range job.Files {
+		lines, err := im.replayFile(replayCtx, job.ServerID, path, eventStore, handle)
+		job.LinesProcessed += lines
+		if flushErr != nil {
+			return flushErr
+		}
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	flush()
+	return flushErr
+}
+
+// replayFile replays a single log file, which may be gzip compressed
+func (im *Importer) replayFile(ctx context.Context, serverID uuid.UUID, path string, eventStore logwatcher_manager.EventStoreInterface, handle func(event_manager.Event)) (int64, error) {
+	file, err := im.storage.Get(ctx, path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := logwatcher_manager.OpenLogReader(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	return logwatcher_manager.ReplayLog(ctx, serverID, reader, eventStore, handle)
+}
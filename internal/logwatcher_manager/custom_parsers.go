@@ -0,0 +1,207 @@
+package logwatcher_manager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+const (
+	// maxCustomPatternLength bounds the size of an admin-defined regex. Go
+	// regexes run in linear time, so the length is what bounds the cost of
+	// matching every log line.
+	maxCustomPatternLength = 1000
+	// maxCustomFields bounds the number of fields a custom parser can extract
+	maxCustomFields = 32
+)
+
+// customEventNamePattern restricts event names to characters that are safe
+// to filter on in workflows and plugins
+var customEventNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,100}$`)
+
+// customLogLinePrefix matches the timestamp and chain ID every log line starts with
+var customLogLinePrefix = regexp.MustCompile(`^\[([0-9.:-]+)]\[([ 0-9]*)]`)
+
+// CustomLogParser is a compiled admin-defined log parser
+type CustomLogParser struct {
+	ID        uuid.UUID
+	Name      string
+	EventName string
+	regex     *regexp.Regexp
+	fields    map[string]int // field name to capture group index
+}
+
+// CompileCustomParser validates and compiles an admin-defined log parser
+func CompileCustomParser(parser *models.ServerLogParser) (*CustomLogParser, error) {
+	if !customEventNamePattern.MatchString(parser.EventName) {
+		return nil, fmt.Errorf("event name must be 1-100 letters, digits, '_', '.', ':' or '-'")
+	}
+	if parser.Pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	if len(parser.Pattern) > maxCustomPatternLength {
+		return nil, fmt.Errorf("pattern is longer than %d characters", maxCustomPatternLength)
+	}
+
+	regex, err := regexp.Compile(parser.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	groups := make(map[string]int)
+	for i, name := range regex.SubexpNames() {
+		if name != "" {
+			groups[name] = i
+		}
+	}
+
+	fields := make(map[string]int)
+	if len(parser.FieldMapping) == 0 {
+		fields = groups
+	} else {
+		for field, group := range parser.FieldMapping {
+			if field == "" {
+				return nil, fmt.Errorf("field names can't be empty")
+			}
+			index, ok := groups[group]
+			if !ok {
+				return nil, fmt.Errorf("field %q refers to capture group %q, which the pattern doesn't define", field, group)
+			}
+			fields[field] = index
+		}
+	}
+
+	if len(fields) > maxCustomFields {
+		return nil, fmt.Errorf("parsers can extract at most %d fields", maxCustomFields)
+	}
+
+	return &CustomLogParser{
+		ID:        parser.ID,
+		Name:      parser.Name,
+		EventName: parser.EventName,
+		regex:     regex,
+		fields:    fields,
+	}, nil
+}
+
+// Fields returns the names of the fields the parser extracts
+func (p *CustomLogParser) Fields() []string {
+	names := make([]string, 0, len(p.fields))
+	for name := range p.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Match runs the parser against a log line
+func (p *CustomLogParser) Match(line string) (*event_manager.LogCustomData, bool) {
+	matches := p.regex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, false
+	}
+
+	data := &event_manager.LogCustomData{
+		ParserID:   p.ID.String(),
+		ParserName: p.Name,
+		EventName:  p.EventName,
+		Fields:     make(map[string]string, len(p.fields)),
+		RawLog:     line,
+	}
+	for name, index := range p.fields {
+		data.Fields[name] = matches[index]
+	}
+
+	if prefix := customLogLinePrefix.FindStringSubmatch(line); prefix != nil {
+		data.Time = prefix[1]
+		data.ChainID = strings.TrimSpace(prefix[2])
+	}
+
+	return data, true
+}
+
+// ProcessCustomParsers runs the custom parsers of a server against a log line
+// and publishes a LOG_CUSTOM event for every parser that matches
+func ProcessCustomParsers(logLine string, serverID uuid.UUID, parsers []*CustomLogParser, eventManager *event_manager.EventManager) {
+	for _, parser := range parsers {
+		if data, ok := parser.Match(logLine); ok {
+			eventManager.PublishEvent(serverID, data, logLine)
+		}
+	}
+}
+
+// SetCustomParsers replaces the custom parsers run for a server
+func (m *LogwatcherManager) SetCustomParsers(serverID uuid.UUID, parsers []*CustomLogParser) {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+
+	if len(parsers) == 0 {
+		delete(m.customParsers, serverID)
+		return
+	}
+	m.customParsers[serverID] = parsers
+}
+
+// getCustomParsers returns the custom parsers run for a server
+func (m *LogwatcherManager) getCustomParsers(serverID uuid.UUID) []*CustomLogParser {
+	m.customMu.RLock()
+	defer m.customMu.RUnlock()
+
+	return m.customParsers[serverID]
+}
+
+// ReloadCustomParsers compiles the given parsers of a server and starts
+// running the enabled ones. Parsers that don't compile are skipped.
+func (m *LogwatcherManager) ReloadCustomParsers(serverID uuid.UUID, parsers []*models.ServerLogParser) {
+	m.SetCustomParsers(serverID, compileEnabledParsers(parsers))
+}
+
+// loadCustomParsers loads the enabled custom parsers of every server
+func (m *LogwatcherManager) loadCustomParsers(ctx context.Context, db *sql.DB) {
+	parsers, err := core.GetEnabledServerLogParsers(ctx, db)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load custom log parsers")
+		return
+	}
+
+	byServer := make(map[uuid.UUID][]*models.ServerLogParser)
+	for _, parser := range parsers {
+		byServer[parser.ServerID] = append(byServer[parser.ServerID], parser)
+	}
+
+	for serverID, serverParsers := range byServer {
+		m.ReloadCustomParsers(serverID, serverParsers)
+	}
+}
+
+// compileEnabledParsers compiles the enabled parsers, logging and skipping
+// the ones that don't compile
+func compileEnabledParsers(parsers []*models.ServerLogParser) []*CustomLogParser {
+	compiled := make([]*CustomLogParser, 0, len(parsers))
+	for _, parser := range parsers {
+		if !parser.Enabled {
+			continue
+		}
+
+		customParser, err := CompileCustomParser(parser)
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("serverID", parser.ServerID.String()).
+				Str("parserID", parser.ID.String()).
+				Msg("Skipping custom log parser")
+			continue
+		}
+		compiled = append(compiled, customParser)
+	}
+	return compiled
+}
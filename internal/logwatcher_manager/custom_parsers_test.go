@@ -0,0 +1,63 @@
+package logwatcher_manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func TestCustomLogParser(t *testing.T) {
+	const line = "[2025.06.14-18.20.00:000][512]LogSquadTrace: [DedicatedServer]ClaimVehicle(): Alpha claimed BP_BTR82A_C"
+
+	parser, err := CompileCustomParser(&models.ServerLogParser{
+		ID:           uuid.New(),
+		Name:         "Vehicle claims",
+		EventName:    "vehicle_claimed",
+		Pattern:      `ClaimVehicle\(\): (?P<player>.+) claimed (?P<vehicle>[A-Za-z0-9_]+)_C`,
+		FieldMapping: map[string]string{"player_name": "player", "vehicle": "vehicle"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to compile parser: %v", err)
+	}
+
+	var events []event_manager.Event
+	eventManager := event_manager.NewSyncEventManager(context.Background(), func(event event_manager.Event) {
+		events = append(events, event)
+	})
+
+	ProcessCustomParsers("[2025.06.14-18.20.01:000][513]LogNet: Join succeeded: Bravo", uuid.New(), []*CustomLogParser{parser}, eventManager)
+	ProcessCustomParsers(line, uuid.New(), []*CustomLogParser{parser}, eventManager)
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+
+	data := events[0].Data.(*event_manager.LogCustomData)
+	if data.EventName != "vehicle_claimed" || data.ChainID != "512" || data.Time != "2025.06.14-18.20.00:000" {
+		t.Errorf("Unexpected event: %+v", data)
+	}
+	if data.Fields["player_name"] != "Alpha" || data.Fields["vehicle"] != "BP_BTR82A" || len(data.Fields) != 2 {
+		t.Errorf("Unexpected fields: %+v", data.Fields)
+	}
+
+	// Without a mapping every named group becomes a field
+	parser, err = CompileCustomParser(&models.ServerLogParser{EventName: "claim", Pattern: `(?P<player>\w+) claimed`})
+	if err != nil {
+		t.Fatalf("Failed to compile parser: %v", err)
+	}
+	if data, ok := parser.Match(line); !ok || data.Fields["player"] != "Alpha" {
+		t.Errorf("Unexpected match: %+v", data)
+	}
+
+	for name, invalid := range map[string]models.ServerLogParser{
+		"bad pattern":   {EventName: "claim", Pattern: `(?P<player>\w+`},
+		"missing group": {EventName: "claim", Pattern: `(?P<player>\w+)`, FieldMapping: map[string]string{"vehicle": "vehicle"}},
+		"bad name":      {EventName: "vehicle claimed", Pattern: `claimed`},
+	} {
+		if _, err := CompileCustomParser(&invalid); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+}
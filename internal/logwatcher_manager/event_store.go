@@ -136,28 +136,7 @@ func (es *EventStore) StorePlayerData(playerID string, data *PlayerData) {
 		}
 	}
 
-	// Merge data by updating existing player data with new values
-	if data.PlayerController != "" {
-		existing.PlayerController = data.PlayerController
-	}
-	if data.IP != "" {
-		existing.IP = data.IP
-	}
-	if data.SteamID != "" {
-		existing.SteamID = data.SteamID
-	}
-	if data.EOSID != "" {
-		existing.EOSID = data.EOSID
-	}
-	if data.PlayerSuffix != "" {
-		existing.PlayerSuffix = data.PlayerSuffix
-	}
-	if data.Controller != "" {
-		existing.Controller = data.Controller
-	}
-	if data.TeamID != "" {
-		existing.TeamID = data.TeamID
-	}
+	mergePlayerData(existing, data)
 
 	// Store merged data with playerTTL expiration
 	mergedData, err := json.Marshal(existing)
@@ -223,43 +202,7 @@ func (es *EventStore) StoreSessionData(key string, data *SessionData) {
 		}
 	}
 
-	// Merge data by updating existing session data with new values
-	if data.ChainID != "" {
-		existing.ChainID = data.ChainID
-	}
-	if data.Time != "" {
-		existing.Time = data.Time
-	}
-	if data.WoundTime != "" {
-		existing.WoundTime = data.WoundTime
-	}
-	if data.VictimName != "" {
-		existing.VictimName = data.VictimName
-	}
-	if data.Damage != "" {
-		existing.Damage = data.Damage
-	}
-	if data.AttackerName != "" {
-		existing.AttackerName = data.AttackerName
-	}
-	if data.AttackerEOS != "" {
-		existing.AttackerEOS = data.AttackerEOS
-	}
-	if data.AttackerSteam != "" {
-		existing.AttackerSteam = data.AttackerSteam
-	}
-	if data.AttackerController != "" {
-		existing.AttackerController = data.AttackerController
-	}
-	if data.Weapon != "" {
-		existing.Weapon = data.Weapon
-	}
-	if data.TeamID != "" {
-		existing.TeamID = data.TeamID
-	}
-	if data.EOSID != "" {
-		existing.EOSID = data.EOSID
-	}
+	mergeSessionData(existing, data)
 
 	// Store merged data with sessionTTL expiration
 	mergedData, err := json.Marshal(existing)
@@ -599,3 +542,68 @@ func (es *EventStore) GetPlayerInfoByController(controllerID string) (*event_man
 
 	return nil, false
 }
+
+// mergePlayerData updates existing with the non-empty fields of data
+func mergePlayerData(existing, data *PlayerData) {
+	if data.PlayerController != "" {
+		existing.PlayerController = data.PlayerController
+	}
+	if data.IP != "" {
+		existing.IP = data.IP
+	}
+	if data.SteamID != "" {
+		existing.SteamID = data.SteamID
+	}
+	if data.EOSID != "" {
+		existing.EOSID = data.EOSID
+	}
+	if data.PlayerSuffix != "" {
+		existing.PlayerSuffix = data.PlayerSuffix
+	}
+	if data.Controller != "" {
+		existing.Controller = data.Controller
+	}
+	if data.TeamID != "" {
+		existing.TeamID = data.TeamID
+	}
+}
+
+// mergeSessionData updates existing with the non-empty fields of data
+func mergeSessionData(existing, data *SessionData) {
+	if data.ChainID != "" {
+		existing.ChainID = data.ChainID
+	}
+	if data.Time != "" {
+		existing.Time = data.Time
+	}
+	if data.WoundTime != "" {
+		existing.WoundTime = data.WoundTime
+	}
+	if data.VictimName != "" {
+		existing.VictimName = data.VictimName
+	}
+	if data.Damage != "" {
+		existing.Damage = data.Damage
+	}
+	if data.AttackerName != "" {
+		existing.AttackerName = data.AttackerName
+	}
+	if data.AttackerEOS != "" {
+		existing.AttackerEOS = data.AttackerEOS
+	}
+	if data.AttackerSteam != "" {
+		existing.AttackerSteam = data.AttackerSteam
+	}
+	if data.AttackerController != "" {
+		existing.AttackerController = data.AttackerController
+	}
+	if data.Weapon != "" {
+		existing.Weapon = data.Weapon
+	}
+	if data.TeamID != "" {
+		existing.TeamID = data.TeamID
+	}
+	if data.EOSID != "" {
+		existing.EOSID = data.EOSID
+	}
+}
@@ -0,0 +1,281 @@
+package logwatcher_manager
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+)
+
+// MemoryEventStore tracks player data across the server session in memory. It
+// is used when no Valkey client is configured and in tests. Unlike EventStore,
+// entries don't expire and are lost on restart.
+type MemoryEventStore struct {
+	mu           sync.RWMutex
+	serverID     uuid.UUID
+	joinRequests map[string]*JoinRequestData
+	players      map[string]*PlayerData
+	sessions     map[string]*SessionData
+	roundWinner  *RoundWinnerData
+	roundLoser   *RoundLoserData
+	won          *WonData
+}
+
+// NewMemoryEventStore creates a new in-memory event store for a specific server
+func NewMemoryEventStore(serverID uuid.UUID) *MemoryEventStore {
+	return &MemoryEventStore{
+		serverID:     serverID,
+		joinRequests: make(map[string]*JoinRequestData),
+		players:      make(map[string]*PlayerData),
+		sessions:     make(map[string]*SessionData),
+	}
+}
+
+// GetServerID returns the server ID this event store is tracking
+func (ms *MemoryEventStore) GetServerID() uuid.UUID {
+	return ms.serverID
+}
+
+// StoreJoinRequest stores a join request by chainID
+func (ms *MemoryEventStore) StoreJoinRequest(chainID string, playerData *JoinRequestData) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	request := *playerData
+	ms.joinRequests[chainID] = &request
+}
+
+// GetJoinRequest retrieves and removes a join request by chainID
+func (ms *MemoryEventStore) GetJoinRequest(chainID string) (*JoinRequestData, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	request, exists := ms.joinRequests[chainID]
+	if !exists {
+		return nil, false
+	}
+	delete(ms.joinRequests, chainID)
+
+	return request, true
+}
+
+// StorePlayerData stores persistent player data by playerID
+func (ms *MemoryEventStore) StorePlayerData(playerID string, data *PlayerData) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	existing, exists := ms.players[playerID]
+	if !exists {
+		existing = &PlayerData{}
+		ms.players[playerID] = existing
+	}
+	mergePlayerData(existing, data)
+}
+
+// GetPlayerData retrieves persistent player data by playerID
+func (ms *MemoryEventStore) GetPlayerData(playerID string) (*PlayerData, bool) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	data, exists := ms.players[playerID]
+	if !exists {
+		return nil, false
+	}
+
+	playerData := *data
+	return &playerData, true
+}
+
+// RemovePlayerData removes persistent player data by playerID
+func (ms *MemoryEventStore) RemovePlayerData(playerID string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.players, playerID)
+	return nil
+}
+
+// StoreSessionData stores non-persistent session data by key (usually player name)
+func (ms *MemoryEventStore) StoreSessionData(key string, data *SessionData) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	existing, exists := ms.sessions[key]
+	if !exists {
+		existing = &SessionData{}
+		ms.sessions[key] = existing
+	}
+	mergeSessionData(existing, data)
+}
+
+// GetSessionData retrieves session data by key
+func (ms *MemoryEventStore) GetSessionData(key string) (*SessionData, bool) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	data, exists := ms.sessions[key]
+	if !exists {
+		return nil, false
+	}
+
+	sessionData := *data
+	return &sessionData, true
+}
+
+// StoreRoundWinner stores round winner data
+func (ms *MemoryEventStore) StoreRoundWinner(data *RoundWinnerData) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.roundWinner = data
+}
+
+// StoreRoundLoser stores round loser data
+func (ms *MemoryEventStore) StoreRoundLoser(data *RoundLoserData) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.roundLoser = data
+}
+
+// GetRoundWinner retrieves and optionally removes round winner data
+func (ms *MemoryEventStore) GetRoundWinner(remove bool) (*RoundWinnerData, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	roundWinner := ms.roundWinner
+	if remove {
+		ms.roundWinner = nil
+	}
+
+	return roundWinner, roundWinner != nil
+}
+
+// GetRoundLoser retrieves and optionally removes round loser data
+func (ms *MemoryEventStore) GetRoundLoser(remove bool) (*RoundLoserData, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	roundLoser := ms.roundLoser
+	if remove {
+		ms.roundLoser = nil
+	}
+
+	return roundLoser, roundLoser != nil
+}
+
+// StoreWonData stores WON event data for new game correlation
+func (ms *MemoryEventStore) StoreWonData(data *WonData) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	// A second WON in the same round means there was no single winner
+	if ms.won != nil {
+		nullWinnerData := *data
+		nullWinnerData.Winner = nil
+		data = &nullWinnerData
+	}
+
+	ms.won = data
+}
+
+// GetWonData retrieves and removes WON data
+func (ms *MemoryEventStore) GetWonData() (*WonData, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	won := ms.won
+	ms.won = nil
+
+	return won, won != nil
+}
+
+// ClearNewGameData clears session data for new game
+func (ms *MemoryEventStore) ClearNewGameData() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.sessions = make(map[string]*SessionData)
+}
+
+// CheckTeamkill checks if an action is a teamkill based on victim and attacker data
+func (ms *MemoryEventStore) CheckTeamkill(victimName string, attackerEOSID string) bool {
+	victimData, exists := ms.GetSessionData(victimName)
+	if !exists || victimData.TeamID == "" || attackerEOSID == "" {
+		return false
+	}
+
+	attackerData, exists := ms.GetPlayerData(attackerEOSID)
+	if !exists || attackerData.TeamID != victimData.TeamID {
+		return false
+	}
+
+	// Same team but different players
+	return victimData.EOSID != "" && victimData.EOSID != attackerEOSID
+}
+
+// GetPlayerInfoByName finds a player by their name and returns PlayerInfo for event manager
+func (ms *MemoryEventStore) GetPlayerInfoByName(name string) (*event_manager.PlayerInfo, bool) {
+	sessionData, hasSession := ms.GetSessionData(name)
+	if hasSession {
+		return &event_manager.PlayerInfo{
+			TeamID: sessionData.TeamID,
+			EOSID:  sessionData.EOSID,
+		}, true
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	for _, playerData := range ms.players {
+		if playerData.PlayerSuffix == name {
+			return playerInfoFromData(playerData), true
+		}
+	}
+
+	return nil, false
+}
+
+// GetPlayerInfoByEOSID finds a player by their EOS ID and returns PlayerInfo for event manager
+func (ms *MemoryEventStore) GetPlayerInfoByEOSID(eosID string) (*event_manager.PlayerInfo, bool) {
+	if eosID == "" {
+		return nil, false
+	}
+
+	if data, exists := ms.GetPlayerData(eosID); exists {
+		return playerInfoFromData(data), true
+	}
+
+	return nil, false
+}
+
+// GetPlayerInfoByController finds a player by their controller ID and returns PlayerInfo for event manager
+func (ms *MemoryEventStore) GetPlayerInfoByController(controllerID string) (*event_manager.PlayerInfo, bool) {
+	if controllerID == "" {
+		return nil, false
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	for _, playerData := range ms.players {
+		if playerData.Controller == controllerID || playerData.PlayerController == controllerID {
+			return playerInfoFromData(playerData), true
+		}
+	}
+
+	return nil, false
+}
+
+// playerInfoFromData converts PlayerData to PlayerInfo
+func playerInfoFromData(data *PlayerData) *event_manager.PlayerInfo {
+	return &event_manager.PlayerInfo{
+		PlayerController: data.PlayerController,
+		IP:               data.IP,
+		SteamID:          data.SteamID,
+		EOSID:            data.EOSID,
+		PlayerSuffix:     data.PlayerSuffix,
+		Controller:       data.Controller,
+		TeamID:           data.TeamID,
+	}
+}
@@ -0,0 +1,154 @@
+package logwatcher_manager
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/player_tracker"
+)
+
+// GetGameplayLogParsers returns log parsers for vehicles, deployables, squad
+// leadership and roles
+func GetGameplayLogParsers() []LogParser {
+	return []LogParser{
+		// Match when a vehicle is destroyed
+		{
+			regex: regexp.MustCompile(`^\[([0-9.:-]+)]\[([ 0-9]*)]LogSquadTrace: \[DedicatedServer](?:ASQVehicle::)?Die\(\): Vehicle=([A-Za-z0-9_]+)_C_[0-9]+ KillingDamage=(?:-)*([0-9.]+) from ([A-Za-z0-9_]+)(?: \(Online IDs:(?: EOS: ([^ )|]+))?(?: steam: ([^ )|]+))?\s*(?:\| Controller ID: [\w\d]+)?\))? caused by ([A-Za-z0-9_-]+)_C`),
+			onMatch: func(args []string, serverID uuid.UUID, eventManager *event_manager.EventManager, eventStore EventStoreInterface, playerTracker *player_tracker.PlayerTracker) {
+				eventData := &event_manager.LogVehicleDestroyedData{
+					Time:               args[1],
+					ChainID:            strings.TrimSpace(args[2]),
+					Vehicle:            args[3],
+					Damage:             args[4],
+					AttackerController: args[5],
+					AttackerEOS:        args[6],
+					AttackerSteam:      args[7],
+					Weapon:             args[8],
+				}
+
+				if playerTracker != nil {
+					attacker, exists := playerTracker.GetPlayerByEOSID(args[6])
+					if !exists {
+						attacker, exists = playerTracker.GetPlayerByController(args[5])
+					}
+					if exists {
+						eventData.AttackerName = attacker.PlayerSuffix
+						if strings.TrimSpace(eventData.AttackerName) == "" {
+							eventData.AttackerName = attacker.Name
+						}
+						eventData.AttackerTeam = attacker.TeamID
+						if eventData.AttackerEOS == "" {
+							eventData.AttackerEOS = attacker.EOSID
+						}
+						if eventData.AttackerSteam == "" {
+							eventData.AttackerSteam = attacker.SteamID
+						}
+					}
+				}
+
+				eventManager.PublishEvent(serverID, eventData, args[0])
+			},
+		},
+		// Match when a deployable is built or torn down
+		{
+			regex: regexp.MustCompile(`^\[([0-9.:-]+)]\[([ 0-9]*)]LogSquadTrace: \[DedicatedServer](?:ASQDeployable::)?(OnConstructed|OnDestroyed)\(\): ([A-Za-z0-9_]+)_C_[0-9]+(?: (?:constructed|destroyed) by (.+?) \(Online IDs:(?: EOS: ([^ )]+))?(?: steam: ([^ )]+))?\))?`),
+			onMatch: func(args []string, serverID uuid.UUID, eventManager *event_manager.EventManager, eventStore EventStoreInterface, playerTracker *player_tracker.PlayerTracker) {
+				action := "removed"
+				if args[3] == "OnConstructed" {
+					action = "placed"
+				}
+
+				eventData := &event_manager.LogDeployableData{
+					Time:           args[1],
+					ChainID:        strings.TrimSpace(args[2]),
+					Action:         action,
+					Deployable:     args[4],
+					DeployableType: deployableType(args[4]),
+					PlayerSuffix:   args[5],
+					PlayerEOS:      args[6],
+					PlayerSteam:    args[7],
+				}
+
+				if playerTracker != nil && args[6] != "" {
+					if player, exists := playerTracker.GetPlayerByEOSID(args[6]); exists {
+						eventData.TeamID = player.TeamID
+					}
+				}
+
+				eventManager.PublishEvent(serverID, eventData, args[0])
+			},
+		},
+		// Match when a player becomes the leader of a squad
+		{
+			regex: regexp.MustCompile(`^\[([0-9.:-]+)]\[([ 0-9]*)]LogSquad: (.+) \(Online IDs:(?: EOS: ([^ )]+))?(?: steam: ([^ )]+))?\) is now the leader of squad ([0-9]+) on team ([0-9]+)`),
+			onMatch: func(args []string, serverID uuid.UUID, eventManager *event_manager.EventManager, eventStore EventStoreInterface, playerTracker *player_tracker.PlayerTracker) {
+				eventManager.PublishEvent(serverID, &event_manager.LogSquadLeaderChangedData{
+					Time:         args[1],
+					ChainID:      strings.TrimSpace(args[2]),
+					PlayerSuffix: args[3],
+					PlayerEOS:    args[4],
+					PlayerSteam:  args[5],
+					SquadID:      args[6],
+					TeamID:       args[7],
+				}, args[0])
+			},
+		},
+		// Match when a player selects a role (kit)
+		{
+			regex: regexp.MustCompile(`^\[([0-9.:-]+)]\[([ 0-9]*)]LogSquadTrace: \[DedicatedServer](?:ASQPlayerController::)?ServerSetRole\(\): PC=(.+) \(Online IDs:(?: EOS: ([^ )]+))?(?: steam: ([^ )]+))?\) Role=([A-Za-z0-9_]+)`),
+			onMatch: func(args []string, serverID uuid.UUID, eventManager *event_manager.EventManager, eventStore EventStoreInterface, playerTracker *player_tracker.PlayerTracker) {
+				eventData := &event_manager.LogPlayerRoleChangedData{
+					Time:         args[1],
+					ChainID:      strings.TrimSpace(args[2]),
+					PlayerSuffix: args[3],
+					PlayerEOS:    args[4],
+					PlayerSteam:  args[5],
+					Role:         args[6],
+					Commander:    isCommanderRole(args[6]),
+				}
+
+				if playerTracker != nil && args[4] != "" {
+					if player, exists := playerTracker.GetPlayerByEOSID(args[4]); exists {
+						eventData.TeamID = player.TeamID
+						eventData.SquadID = player.SquadID
+					}
+				}
+
+				eventManager.PublishEvent(serverID, eventData, args[0])
+
+				if eventData.Commander {
+					eventManager.PublishEvent(serverID, &event_manager.LogCommanderAssignedData{
+						Time:         eventData.Time,
+						ChainID:      eventData.ChainID,
+						PlayerSuffix: eventData.PlayerSuffix,
+						PlayerEOS:    eventData.PlayerEOS,
+						PlayerSteam:  eventData.PlayerSteam,
+						Role:         eventData.Role,
+						TeamID:       eventData.TeamID,
+					}, args[0])
+				}
+			},
+		},
+	}
+}
+
+// deployableType classifies a deployable class name as a FOB radio, a HAB or
+// any other deployable
+func deployableType(classname string) string {
+	lower := strings.ToLower(classname)
+	switch {
+	case strings.Contains(lower, "fobradio"):
+		return "FOB"
+	case strings.Contains(lower, "_hab"):
+		return "HAB"
+	default:
+		return "OTHER"
+	}
+}
+
+// isCommanderRole reports whether a role class name is a commander kit
+func isCommanderRole(role string) bool {
+	return strings.Contains(strings.ToLower(role), "commander")
+}
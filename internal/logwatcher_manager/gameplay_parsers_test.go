@@ -0,0 +1,74 @@
+package logwatcher_manager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+)
+
+func TestGameplayLogParsers(t *testing.T) {
+	const lines = `[2025.06.14-18.30.00:000][600]LogSquadTrace: [DedicatedServer]ASQVehicle::Die(): Vehicle=BP_BTR82A_RUS_C_2147480000 KillingDamage=350.00 from BP_PlayerController_C_2147481234 (Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561198000000001 | Controller ID: BP_PlayerController_C_2147481234) caused by BP_Projectile_RPG7_HEAT_C
+[2025.06.14-18.30.01:000][601]LogSquadTrace: [DedicatedServer]ASQDeployable::OnConstructed(): BP_FOBRadio_Woodland_C_2147470001 constructed by Alpha (Online IDs: EOS: 0002a10186d9414496bf20d22d3860ba steam: 76561198000000001)
+[2025.06.14-18.30.02:000][602]LogSquadTrace: [DedicatedServer]ASQDeployable::OnDestroyed(): BP_Hab_Woodland_C_2147470002
+[2025.06.14-18.30.03:000][603]LogSquad: Bravo (Online IDs: EOS: 0002b10186d9414496bf20d22d3860bb steam: 76561198000000002) is now the leader of squad 3 on team 2
+[2025.06.14-18.30.04:000][604]LogSquadTrace: [DedicatedServer]ASQPlayerController::ServerSetRole(): PC=Bravo (Online IDs: EOS: 0002b10186d9414496bf20d22d3860bb steam: 76561198000000002) Role=RGF_Commander_01
+[2025.06.14-18.30.05:000][605]LogGameState: Match State Changed from WaitingToStart to InProgress`
+
+	var events []event_manager.Event
+	_, err := ReplayLog(context.Background(), uuid.New(), strings.NewReader(lines), NewMemoryEventStore(uuid.New()), func(event event_manager.Event) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("Failed to replay log: %v", err)
+	}
+
+	expected := []event_manager.EventType{
+		event_manager.EventTypeLogVehicleDestroyed,
+		event_manager.EventTypeLogDeployablePlaced,
+		event_manager.EventTypeLogDeployableRemoved,
+		event_manager.EventTypeLogSquadLeaderChanged,
+		event_manager.EventTypeLogPlayerRoleChanged,
+		event_manager.EventTypeLogCommanderAssigned,
+		event_manager.EventTypeLogGameEventUnified,
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+	for n, eventType := range expected {
+		if events[n].Type != eventType {
+			t.Errorf("Expected event %d to be %s, got %s", n, eventType, events[n].Type)
+		}
+	}
+
+	vehicle := events[0].Data.(*event_manager.LogVehicleDestroyedData)
+	if vehicle.Vehicle != "BP_BTR82A_RUS" || vehicle.Weapon != "BP_Projectile_RPG7_HEAT" || vehicle.AttackerSteam != "76561198000000001" {
+		t.Errorf("Unexpected vehicle destroyed event: %+v", vehicle)
+	}
+
+	placed := events[1].Data.(*event_manager.LogDeployableData)
+	if placed.DeployableType != "FOB" || placed.PlayerSuffix != "Alpha" {
+		t.Errorf("Unexpected deployable placed event: %+v", placed)
+	}
+	removed := events[2].Data.(*event_manager.LogDeployableData)
+	if removed.DeployableType != "HAB" || removed.PlayerSuffix != "" {
+		t.Errorf("Unexpected deployable removed event: %+v", removed)
+	}
+
+	leader := events[3].Data.(*event_manager.LogSquadLeaderChangedData)
+	if leader.PlayerSuffix != "Bravo" || leader.SquadID != "3" || leader.TeamID != "2" {
+		t.Errorf("Unexpected squad leader event: %+v", leader)
+	}
+
+	role := events[4].Data.(*event_manager.LogPlayerRoleChangedData)
+	if role.Role != "RGF_Commander_01" || !role.Commander {
+		t.Errorf("Unexpected role event: %+v", role)
+	}
+
+	match := events[6].Data.(*event_manager.LogGameEventUnifiedData)
+	if match.EventType != "MATCH_STARTED" || match.FromState != "WaitingToStart" {
+		t.Errorf("Unexpected match state event: %+v", match)
+	}
+}
@@ -0,0 +1,64 @@
+package logwatcher_manager
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+)
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// OpenLogReader returns a reader for a SquadGame.log file, transparently
+// decompressing it when it is a gzip archive
+func OpenLogReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+
+	header, err := buffered.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if bytes.Equal(header, gzipMagic) {
+		return gzip.NewReader(buffered)
+	}
+	return buffered, nil
+}
+
+// ReplayLog runs the lines of a historical log through the log parsers.
+// Events are passed to handle as they are parsed instead of being published
+// to the live event manager, so plugins and workflows never see them. The
+// event store correlates events across lines and can be shared between
+// consecutive files. It returns the number of lines read.
+func ReplayLog(ctx context.Context, serverID uuid.UUID, r io.Reader, eventStore EventStoreInterface, handle func(event_manager.Event)) (int64, error) {
+	eventManager := event_manager.NewSyncEventManager(ctx, handle)
+	parsers := GetOptimizedLogParsers()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines int64
+	for scanner.Scan() {
+		if lines%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return lines, err
+			}
+		}
+		lines++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		ProcessLogForEvents(line, serverID, parsers, eventManager, eventStore, nil)
+	}
+
+	return lines, scanner.Err()
+}
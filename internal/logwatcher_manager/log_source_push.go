@@ -0,0 +1,190 @@
+package logwatcher_manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// pushBufferSize is how many pushed lines can wait for the parser before
+// pushes start blocking the agent
+const pushBufferSize = 1024
+
+var (
+	// ErrPushSourceClosed is returned when lines are pushed to a closed source
+	ErrPushSourceClosed = errors.New("push log source is closed")
+	// ErrNoPushSource is returned when a server isn't connected to a push log source
+	ErrNoPushSource = errors.New("server is not connected to a push log source")
+)
+
+// PushBatch is a batch of consecutive log lines sent by a log shipper agent
+type PushBatch struct {
+	// Sequence is the sequence number of the first line. Agents number every
+	// line they send, starting at 1, so the lines of a batch are numbered
+	// Sequence to Sequence+len(Lines)-1.
+	Sequence int64 `json:"seq"`
+	// Offset is the byte offset in the log file just after the last line,
+	// reported back to the agent so it can resume from there
+	Offset int64    `json:"offset"`
+	Lines  []string `json:"lines"`
+}
+
+// PushPosition is the last line a push source has accepted
+type PushPosition struct {
+	Sequence int64 `json:"seq"`
+	Offset   int64 `json:"offset"`
+}
+
+// PushLogSource implements LogSource for lines pushed by a log shipper agent
+// running on the game host. Sequence numbers are used to drop lines that
+// were already accepted and to detect lines that never arrived.
+type PushLogSource struct {
+	pushMu sync.Mutex // serializes pushes so lines keep their order
+	mu     sync.Mutex
+	lines  chan string
+	done   chan struct{}
+	once   sync.Once
+
+	position       PushPosition
+	gaps           int64
+	missingLines   int64
+	duplicateLines int64
+	lastPush       time.Time
+}
+
+// NewPushLogSource creates a new push source resuming after the given position
+func NewPushLogSource(position PushPosition) *PushLogSource {
+	return &PushLogSource{
+		lines:    make(chan string, pushBufferSize),
+		done:     make(chan struct{}),
+		position: position,
+	}
+}
+
+// Watch returns the channel pushed lines are delivered on
+func (p *PushLogSource) Watch(ctx context.Context) (<-chan string, error) {
+	select {
+	case <-p.done:
+		return nil, ErrPushSourceClosed
+	default:
+	}
+	return p.lines, nil
+}
+
+// Push delivers a batch of lines and returns the position after it. Lines at
+// or before the current position are skipped, so an agent can safely resend
+// a batch it didn't get an acknowledgement for.
+func (p *PushLogSource) Push(ctx context.Context, batch PushBatch) (PushPosition, error) {
+	if batch.Sequence < 1 {
+		return PushPosition{}, errors.New("sequence numbers start at 1")
+	}
+
+	p.pushMu.Lock()
+	defer p.pushMu.Unlock()
+
+	select {
+	case <-p.done:
+		return p.Position(), ErrPushSourceClosed
+	default:
+	}
+
+	p.mu.Lock()
+	last := p.position.Sequence
+	p.mu.Unlock()
+
+	lines := batch.Lines
+	end := batch.Sequence + int64(len(lines)) - 1
+	if end <= last {
+		p.mu.Lock()
+		p.duplicateLines += int64(len(lines))
+		position := p.position
+		p.mu.Unlock()
+		return position, nil
+	}
+
+	if batch.Sequence <= last {
+		skip := last - batch.Sequence + 1
+		lines = lines[skip:]
+
+		p.mu.Lock()
+		p.duplicateLines += skip
+		p.mu.Unlock()
+	} else if last > 0 && batch.Sequence > last+1 {
+		missing := batch.Sequence - last - 1
+
+		p.mu.Lock()
+		p.gaps++
+		p.missingLines += missing
+		p.mu.Unlock()
+
+		log.Warn().
+			Int64("lastSequence", last).
+			Int64("sequence", batch.Sequence).
+			Int64("missingLines", missing).
+			Msg("Gap in pushed log lines")
+	}
+
+	// The offset is only known at the end of a batch, so a batch is delivered
+	// all or nothing: a cancelled push stops before its first line, and once
+	// lines are queued the rest follow even if the agent has gone away.
+	// Otherwise the lines already queued would be sent again on resend.
+	select {
+	case <-ctx.Done():
+		return p.Position(), ctx.Err()
+	default:
+	}
+	for _, line := range lines {
+		select {
+		case p.lines <- line:
+		case <-p.done:
+			return p.Position(), ErrPushSourceClosed
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.position = PushPosition{Sequence: end, Offset: batch.Offset}
+	p.lastPush = time.Now()
+
+	return p.position, nil
+}
+
+// Position returns the last line accepted
+func (p *PushLogSource) Position() PushPosition {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.position
+}
+
+// Stats returns the position and gap counters of the source
+func (p *PushLogSource) Stats() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var lastPush interface{}
+	if !p.lastPush.IsZero() {
+		lastPush = p.lastPush
+	}
+
+	return map[string]interface{}{
+		"lastSequence":   p.position.Sequence,
+		"offset":         p.position.Offset,
+		"gaps":           p.gaps,
+		"missingLines":   p.missingLines,
+		"duplicateLines": p.duplicateLines,
+		"lastPush":       lastPush,
+	}
+}
+
+// Close stops accepting pushes
+func (p *PushLogSource) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+	})
+	return nil
+}
@@ -0,0 +1,113 @@
+package logwatcher_manager
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPushLogSource(t *testing.T) {
+	ctx := context.Background()
+	source := NewPushLogSource(PushPosition{Sequence: 10, Offset: 500})
+	defer source.Close()
+
+	lines, err := source.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+
+	// Lines 9 and 10 were accepted before the restart
+	position, err := source.Push(ctx, PushBatch{Sequence: 9, Offset: 560, Lines: []string{"nine", "ten", "eleven", "twelve"}})
+	if err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+	if position != (PushPosition{Sequence: 12, Offset: 560}) {
+		t.Errorf("Unexpected position: %+v", position)
+	}
+	for _, want := range []string{"eleven", "twelve"} {
+		if got := <-lines; got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+
+	// A resent batch is dropped entirely
+	if _, err := source.Push(ctx, PushBatch{Sequence: 11, Offset: 560, Lines: []string{"eleven", "twelve"}}); err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+	if len(lines) != 0 {
+		t.Errorf("Expected duplicate lines to be dropped, %d queued", len(lines))
+	}
+
+	// Lines 13 and 14 never arrived
+	position, err = source.Push(ctx, PushBatch{Sequence: 15, Offset: 700, Lines: []string{"fifteen"}})
+	if err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+	if position.Sequence != 15 || <-lines != "fifteen" {
+		t.Errorf("Unexpected position after gap: %+v", position)
+	}
+
+	stats := source.Stats()
+	if stats["gaps"].(int64) != 1 || stats["missingLines"].(int64) != 2 || stats["duplicateLines"].(int64) != 4 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	source.Close()
+	if _, err := source.Push(ctx, PushBatch{Sequence: 16, Lines: []string{"sixteen"}}); err != ErrPushSourceClosed {
+		t.Errorf("Expected ErrPushSourceClosed, got %v", err)
+	}
+}
+
+func TestPushLogSourceDeliversWholeBatch(t *testing.T) {
+	source := NewPushLogSource(PushPosition{Sequence: 0, Offset: 100})
+	defer source.Close()
+
+	lines, err := source.Watch(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+
+	batch := PushBatch{Sequence: 1, Offset: 900, Lines: make([]string, pushBufferSize+2)}
+	for i := range batch.Lines {
+		batch.Lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+
+	// A push cancelled before it starts delivers nothing
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	position, err := source.Push(cancelled, batch)
+	if err != context.Canceled {
+		t.Fatalf("Expected the push to be cancelled, got %v", err)
+	}
+	if position != (PushPosition{Sequence: 0, Offset: 100}) || len(lines) != 0 {
+		t.Errorf("Expected nothing to be delivered, got %+v and %d lines", position, len(lines))
+	}
+
+	// The buffer fills up and the push times out while waiting, but the lines
+	// already queued are not cut off from the rest of the batch
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	received := make(chan int)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		count := 0
+		for range batch.Lines {
+			<-lines
+			count++
+		}
+		received <- count
+	}()
+
+	position, err = source.Push(ctx, batch)
+	if err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+	if position != (PushPosition{Sequence: pushBufferSize + 2, Offset: 900}) {
+		t.Errorf("Unexpected position: %+v", position)
+	}
+	if count := <-received; count != len(batch.Lines) {
+		t.Errorf("Expected %d lines, got %d", len(batch.Lines), count)
+	}
+}
@@ -25,6 +25,7 @@ const (
 	LogSourceTypeLocal LogSourceType = "local"
 	LogSourceTypeSFTP  LogSourceType = "sftp"
 	LogSourceTypeFTP   LogSourceType = "ftp"
+	LogSourceTypePush  LogSourceType = "push"
 )
 
 // LogSource defines an interface for different log sources
@@ -45,6 +46,8 @@ type LogSourceConfig struct {
 	Password      string        `json:"password,omitempty"`
 	PollFrequency time.Duration `json:"poll_frequency,omitempty"`
 	ReadFromStart bool          `json:"read_from_start,omitempty"`
+	PushSequence  int64         `json:"push_sequence,omitempty"` // Last sequence accepted from a push agent
+	PushOffset    int64         `json:"push_offset,omitempty"`   // File offset after that line
 }
 
 // LocalFileSource implements LogSource for local file access
@@ -35,6 +35,8 @@ type LogwatcherManager struct {
 	connections          map[uuid.UUID]*ServerLogConnection
 	eventManager         *event_manager.EventManager
 	parsers              []LogParser
+	customParsers        map[uuid.UUID][]*CustomLogParser
+	customMu             sync.RWMutex // guards customParsers without waiting on connection changes
 	valkeyClient         *valkeyClient.Client
 	playerTrackerManager *player_tracker_manager.PlayerTrackerManager
 	mu                   sync.RWMutex
@@ -50,6 +52,7 @@ func NewLogwatcherManager(ctx context.Context, eventManager *event_manager.Event
 		connections:          make(map[uuid.UUID]*ServerLogConnection),
 		eventManager:         eventManager,
 		parsers:              GetOptimizedLogParsers(), // Use the unified parsers
+		customParsers:        make(map[uuid.UUID][]*CustomLogParser),
 		valkeyClient:         valkeyClient,
 		playerTrackerManager: playerTrackerManager,
 		ctx:                  ctx,
@@ -147,7 +150,7 @@ func (m *LogwatcherManager) ConnectToServer(serverID uuid.UUID, config LogSource
 		ServerID:          serverID,
 		LogSource:         logSource,
 		Config:            config,
-		EventStore:        NewEventStore(serverID, m.valkeyClient),
+		EventStore:        m.newEventStore(serverID),
 		Metrics:           NewLogParsingMetrics(),
 		Connected:         true,
 		LastUsed:          time.Now(),
@@ -169,6 +172,15 @@ func (m *LogwatcherManager) ConnectToServer(serverID uuid.UUID, config LogSource
 	return nil
 }
 
+// newEventStore creates the event store for a server, keeping it in memory
+// when no Valkey client is configured
+func (m *LogwatcherManager) newEventStore(serverID uuid.UUID) EventStoreInterface {
+	if m.valkeyClient == nil {
+		return NewMemoryEventStore(serverID)
+	}
+	return NewEventStore(serverID, m.valkeyClient)
+}
+
 // DisconnectFromServer disconnects from a server's log source
 func (m *LogwatcherManager) DisconnectFromServer(serverID uuid.UUID) error {
 	m.mu.Lock()
@@ -241,11 +253,56 @@ func (m *LogwatcherManager) createLogSource(config LogSourceConfig) (LogSource,
 		return NewFTPSource(config.Host, config.Port, config.Username, config.Password,
 			config.FilePath, config.PollFrequency, config.ReadFromStart), nil
 
+	case LogSourceTypePush:
+		return NewPushLogSource(PushPosition{Sequence: config.PushSequence, Offset: config.PushOffset}), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported log source type: %s", config.Type)
 	}
 }
 
+// pushSource returns the push source a server is connected to
+func (m *LogwatcherManager) pushSource(serverID uuid.UUID) (*PushLogSource, error) {
+	m.mu.RLock()
+	conn, exists := m.connections[serverID]
+	m.mu.RUnlock()
+	if !exists {
+		return nil, ErrNoPushSource
+	}
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	source, ok := conn.LogSource.(*PushLogSource)
+	if !conn.Connected || !ok {
+		return nil, ErrNoPushSource
+	}
+
+	return source, nil
+}
+
+// PushLogLines feeds a batch of lines from a log shipper agent into a
+// server's push source and returns the position after it
+func (m *LogwatcherManager) PushLogLines(ctx context.Context, serverID uuid.UUID, batch PushBatch) (PushPosition, error) {
+	source, err := m.pushSource(serverID)
+	if err != nil {
+		return PushPosition{}, err
+	}
+
+	return source.Push(ctx, batch)
+}
+
+// GetPushPosition returns the last line a server's push source accepted, so
+// an agent knows where to resume
+func (m *LogwatcherManager) GetPushPosition(serverID uuid.UUID) (PushPosition, error) {
+	source, err := m.pushSource(serverID)
+	if err != nil {
+		return PushPosition{}, err
+	}
+
+	return source.Position(), nil
+}
+
 // watchLogs watches logs from a server and processes events
 func (m *LogwatcherManager) watchLogs(ctx context.Context, serverID uuid.UUID, conn *ServerLogConnection) {
 	log.Debug().
@@ -304,6 +361,10 @@ func (m *LogwatcherManager) watchLogs(ctx context.Context, serverID uuid.UUID, c
 			} else {
 				ProcessLogForEventsWithMetrics(logLine, serverID, m.parsers, m.eventManager, conn.EventStore, nil, conn.Metrics)
 			}
+
+			if customParsers := m.getCustomParsers(serverID); len(customParsers) > 0 {
+				ProcessCustomParsers(logLine, serverID, customParsers, m.eventManager)
+			}
 		}
 	}
 }
@@ -330,12 +391,17 @@ func (m *LogwatcherManager) calculateReconnectDelay(attempts int) time.Duration
 
 // ConnectToAllServers connects to all servers in the database that have log configuration
 func (m *LogwatcherManager) ConnectToAllServers(ctx context.Context, db *sql.DB) {
+	m.loadCustomParsers(ctx, db)
+
 	// Get all servers from the database with log configuration
 	rows, err := db.QueryContext(ctx, `
-		SELECT id, log_source_type, log_file_path, log_host, log_port, log_username,
-		       log_password, log_poll_frequency, log_read_from_start
-		FROM servers
-		WHERE log_source_type IS NOT NULL AND log_file_path IS NOT NULL AND log_file_path != ''
+		SELECT s.id, s.log_source_type, s.log_file_path, s.log_host, s.log_port, s.log_username,
+		       s.log_password, s.log_poll_frequency, s.log_read_from_start,
+		       COALESCE(p.last_sequence, 0), COALESCE(p.last_offset, 0)
+		FROM servers s
+		LEFT JOIN server_log_push p ON p.server_id = s.id
+		WHERE s.log_source_type = 'push'
+		   OR (s.log_source_type IS NOT NULL AND s.log_file_path IS NOT NULL AND s.log_file_path != '')
 	`)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to query servers for log connections")
@@ -351,23 +417,30 @@ func (m *LogwatcherManager) ConnectToAllServers(ctx context.Context, db *sql.DB)
 		var logPort *int
 		var logPollFrequency *int // in seconds
 		var logReadFromStart *bool
+		var pushSequence, pushOffset int64
 
 		if err := rows.Scan(&id, &logSourceType, &logFilePath, &logHost, &logPort,
-			&logUsername, &logPassword, &logPollFrequency, &logReadFromStart); err != nil {
+			&logUsername, &logPassword, &logPollFrequency, &logReadFromStart,
+			&pushSequence, &pushOffset); err != nil {
 			log.Error().Err(err).Msg("Failed to scan server log configuration")
 			continue
 		}
 
 		// Skip if essential fields are missing
-		if logSourceType == nil || logFilePath == nil {
+		if logSourceType == nil {
 			continue
 		}
 
 		// Build log source config
 		config := LogSourceConfig{
 			Type:          LogSourceType(*logSourceType),
-			FilePath:      *logFilePath,
 			ReadFromStart: false, // Default value
+			PushSequence:  pushSequence,
+			PushOffset:    pushOffset,
+		}
+
+		if logFilePath != nil {
+			config.FilePath = *logFilePath
 		}
 
 		if logHost != nil {
@@ -434,6 +507,9 @@ func (m *LogwatcherManager) GetConnectionStats() map[string]interface{} {
 			// Get metrics for this connection
 			if conn.Metrics != nil {
 				metrics := conn.Metrics.GetMetrics()
+				if source, ok := conn.LogSource.(*PushLogSource); ok {
+					metrics["push"] = source.Stats()
+				}
 				serverMetrics[serverID.String()] = metrics
 
 				// Aggregate metrics
@@ -492,7 +568,12 @@ func (m *LogwatcherManager) GetServerMetrics(serverID uuid.UUID) (map[string]int
 		return nil, errors.New("server not connected or metrics not available")
 	}
 
-	return conn.Metrics.GetMetrics(), nil
+	metrics := conn.Metrics.GetMetrics()
+	if source, ok := conn.LogSource.(*PushLogSource); ok {
+		metrics["push"] = source.Stats()
+	}
+
+	return metrics, nil
 }
 
 // StartConnectionManager starts the connection manager
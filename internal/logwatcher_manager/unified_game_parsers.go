@@ -117,6 +117,25 @@ func GetUnifiedGameEventParsers() []LogParser {
 				eventManager.PublishEvent(serverID, unifiedEvent, args[0])
 			},
 		},
+		// Match any other game state change, such as the match starting
+		{
+			regex: regexp.MustCompile(`^\[([0-9.:-]+)]\[([ 0-9]*)]LogGameState: Match State Changed from ([A-Za-z]+) to ([A-Za-z]+)`),
+			onMatch: func(args []string, serverID uuid.UUID, eventManager *event_manager.EventManager, eventStore EventStoreInterface, playerTracker *player_tracker.PlayerTracker) {
+				eventType := "MATCH_STATE_CHANGED"
+				if args[4] == "InProgress" {
+					eventType = "MATCH_STARTED"
+				}
+
+				eventManager.PublishEvent(serverID, &event_manager.LogGameEventUnifiedData{
+					Time:      args[1],
+					ChainID:   strings.TrimSpace(args[2]),
+					EventType: eventType,
+					FromState: args[3],
+					ToState:   args[4],
+					RawLog:    args[0],
+				}, args[0])
+			},
+		},
 		// Match when bringing world (new game/map change)
 		{
 			regex: regexp.MustCompile(`^\[([0-9.:-]+)]\[([ 0-9]*)]LogWorld: Bringing World \/([A-z0-9]+)\/(?:Maps\/)?([A-z0-9-]+)\/(?:.+\/)?([A-z0-9-]+)(?:\.[A-z0-9-]+)`),
@@ -205,5 +224,8 @@ func GetOptimizedLogParsers() []LogParser {
 	// Add the unified game event parsers
 	optimizedParsers = append(optimizedParsers, GetUnifiedGameEventParsers()...)
 
+	// Add the vehicle, deployable, squad leader and role parsers
+	optimizedParsers = append(optimizedParsers, GetGameplayLogParsers()...)
+
 	return optimizedParsers
 }
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Admin camera alert types
+const (
+	AdminCameraAlertLongSession       = "long_session"
+	AdminCameraAlertFrequentSessions  = "frequent_sessions"
+	AdminCameraAlertGhostingSuspected = "ghosting_suspected"
+)
+
+// AdminCameraSettings holds the alert thresholds for admin camera use on a
+// server. A threshold of zero disables its alert.
+type AdminCameraSettings struct {
+	ServerID      uuid.UUID `json:"server_id"`
+	AlertsEnabled bool      `json:"alerts_enabled"`
+	// LongSessionMinutes alerts when a single session lasts longer
+	LongSessionMinutes int `json:"long_session_minutes"`
+	// FrequentSessionCount alerts when an admin opens this many sessions
+	// within FrequentSessionWindowMinutes
+	FrequentSessionCount         int `json:"frequent_session_count"`
+	FrequentSessionWindowMinutes int `json:"frequent_session_window_minutes"`
+	// KillWindowMinutes is how far before and after a session the admin's
+	// kills are counted
+	KillWindowMinutes int `json:"kill_window_minutes"`
+	// GhostingKillThreshold flags a session when an admin on a team gets this
+	// many kills within the kill window after leaving the camera
+	GhostingKillThreshold int       `json:"ghosting_kill_threshold"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// AdminCameraSession is a stored admin camera session
+type AdminCameraSession struct {
+	ID                uuid.UUID `json:"id"`
+	ServerID          uuid.UUID `json:"server_id"`
+	AdminName         string    `json:"admin_name"`
+	AdminEOS          string    `json:"admin_eos"`
+	AdminSteam        string    `json:"admin_steam"`
+	TeamID            int       `json:"team_id"`
+	SquadID           int       `json:"squad_id"`
+	StartedAt         time.Time `json:"started_at"`
+	EndedAt           time.Time `json:"ended_at"`
+	DurationSeconds   float64   `json:"duration_seconds"`
+	EndReason         string    `json:"end_reason"`
+	KillWindowSeconds int       `json:"kill_window_seconds"`
+	KillsBefore       int       `json:"kills_before"`
+	KillsAfter        int       `json:"kills_after"`
+	GhostingSuspected bool      `json:"ghosting_suspected"`
+	Alerts            []string  `json:"alerts"`
+}
+
+// AdminCameraAdminSummary aggregates the admin camera sessions of one admin
+type AdminCameraAdminSummary struct {
+	AdminName            string    `json:"admin_name"`
+	AdminEOS             string    `json:"admin_eos"`
+	AdminSteam           string    `json:"admin_steam"`
+	Sessions             int       `json:"sessions"`
+	TotalSeconds         float64   `json:"total_seconds"`
+	AverageSeconds       float64   `json:"average_seconds"`
+	LongestSeconds       float64   `json:"longest_seconds"`
+	SessionsOnTeam       int       `json:"sessions_on_team"`
+	KillsAfter           int       `json:"kills_after"`
+	GhostingSuspected    int       `json:"ghosting_suspected"`
+	AlertedSessions      int       `json:"alerted_sessions"`
+	LastSessionStartedAt time.Time `json:"last_session_started_at"`
+}
+
+type AdminCameraSettingsUpdateRequest struct {
+	AlertsEnabled                bool `json:"alerts_enabled"`
+	LongSessionMinutes           int  `json:"long_session_minutes"`
+	FrequentSessionCount         int  `json:"frequent_session_count"`
+	FrequentSessionWindowMinutes int  `json:"frequent_session_window_minutes"`
+	KillWindowMinutes            int  `json:"kill_window_minutes"`
+	GhostingKillThreshold        int  `json:"ghosting_kill_threshold"`
+}
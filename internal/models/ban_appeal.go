@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ban appeal statuses
+const (
+	BanAppealStatusPending  = "pending"
+	BanAppealStatusMoreInfo = "more_info" // A reviewer asked the player for more information
+	BanAppealStatusAccepted = "accepted"
+	BanAppealStatusDenied   = "denied"
+)
+
+// Ban appeal resolutions, how an accepted appeal changed the ban
+const (
+	BanAppealResolutionLift    = "lift"
+	BanAppealResolutionShorten = "shorten"
+)
+
+// BanAppeal is a banned player's request to lift or shorten their ban
+type BanAppeal struct {
+	ID               uuid.UUID  `json:"id"`
+	BanID            uuid.UUID  `json:"ban_id"`
+	ServerID         uuid.UUID  `json:"server_id"`
+	SteamID          string     `json:"steam_id"`
+	PlayerName       string     `json:"player_name,omitempty"`
+	Status           string     `json:"status"`
+	Statement        string     `json:"statement"`
+	Contact          *string    `json:"contact,omitempty"`
+	ReviewerID       *uuid.UUID `json:"reviewer_id,omitempty"`
+	ReviewerName     *string    `json:"reviewer_name,omitempty"`
+	Resolution       *string    `json:"resolution,omitempty"`
+	ResolvedDuration *int       `json:"resolved_duration,omitempty"` // New ban duration in days when shortened
+	BanReason        string     `json:"ban_reason"`
+	BanDuration      int        `json:"ban_duration"`
+	BanCreatedAt     time.Time  `json:"ban_created_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+}
+
+// BanAppealComment is a message on an appeal. Comments without a user are
+// written by the player; internal comments are only shown to reviewers.
+type BanAppealComment struct {
+	ID        uuid.UUID  `json:"id"`
+	AppealID  uuid.UUID  `json:"appeal_id"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	Username  *string    `json:"username,omitempty"`
+	Message   string     `json:"message"`
+	Internal  bool       `json:"internal"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// BanAppealEvent is an entry in the audit trail of an appeal
+type BanAppealEvent struct {
+	ID         uuid.UUID              `json:"id"`
+	AppealID   uuid.UUID              `json:"appeal_id"`
+	UserID     *uuid.UUID             `json:"user_id,omitempty"`
+	Username   *string                `json:"username,omitempty"`
+	Action     string                 `json:"action"`
+	FromStatus *string                `json:"from_status,omitempty"`
+	ToStatus   *string                `json:"to_status,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// ------------------------------------------
+// Requests
+// ------------------------------------------
+
+// BanAppealSubmitRequest is a public appeal submission. The Steam ID and ban
+// ID together identify the ban being appealed.
+type BanAppealSubmitRequest struct {
+	SteamID   string  `json:"steam_id" binding:"required"`
+	BanID     string  `json:"ban_id" binding:"required"`
+	Statement string  `json:"statement" binding:"required"`
+	Contact   *string `json:"contact,omitempty"`
+}
+
+// BanAppealReplyRequest is a player's reply to a request for more information
+type BanAppealReplyRequest struct {
+	SteamID string `json:"steam_id" binding:"required"`
+	BanID   string `json:"ban_id" binding:"required"`
+	Message string `json:"message" binding:"required"`
+}
+
+// BanAppealCommentRequest is a reviewer comment on an appeal
+type BanAppealCommentRequest struct {
+	Message  string `json:"message" binding:"required"`
+	Internal bool   `json:"internal"`
+}
+
+// BanAppealReviewRequest changes the status of an appeal. Accepting requires a
+// resolution; shortening requires the new duration in days.
+type BanAppealReviewRequest struct {
+	Status     string `json:"status" binding:"required"`
+	Resolution string `json:"resolution,omitempty"`
+	Duration   *int   `json:"duration,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+}
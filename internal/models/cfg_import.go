@@ -0,0 +1,111 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status of an entry in a config import preview
+const (
+	// CfgImportNew entries are added by the import
+	CfgImportNew = "new"
+	// CfgImportUnchanged entries already exist in Aegis as they are in the file
+	CfgImportUnchanged = "unchanged"
+	// CfgImportConflict entries exist in Aegis but differ from the file, they are
+	// only changed when the import overwrites conflicts
+	CfgImportConflict = "conflict"
+	// CfgImportSkipped entries can't be imported, Message says why
+	CfgImportSkipped = "skipped"
+)
+
+// Sources a config import reads the files from
+const (
+	CfgImportSourceUpload = "upload"
+	CfgImportSourceServer = "server"
+)
+
+// CfgImportPreviewRequest reads Admins.cfg and Bans.cfg from the game server
+// over the MOTD upload connection, or takes the uploaded content
+type CfgImportPreviewRequest struct {
+	Source     string `json:"source"`
+	AdminsPath string `json:"admins_path"`
+	BansPath   string `json:"bans_path"`
+	AdminsCfg  string `json:"admins_cfg"`
+	BansCfg    string `json:"bans_cfg"`
+}
+
+// CfgImportApplyRequest applies the content of a preview
+type CfgImportApplyRequest struct {
+	AdminsCfg          string `json:"admins_cfg"`
+	BansCfg            string `json:"bans_cfg"`
+	OverwriteConflicts bool   `json:"overwrite_conflicts"`
+}
+
+// CfgImportPreview lists what importing the files would change. The content
+// is returned so it can be applied without reading the files again.
+type CfgImportPreview struct {
+	AdminsCfg string              `json:"admins_cfg"`
+	BansCfg   string              `json:"bans_cfg"`
+	Groups    []*CfgImportGroup   `json:"groups"`
+	Admins    []*CfgImportAdmin   `json:"admins"`
+	Bans      []*CfgImportBan     `json:"bans"`
+	Warnings  []*CfgImportWarning `json:"warnings"`
+}
+
+// CfgImportGroup is a group of Admins.cfg and the server role it maps to
+type CfgImportGroup struct {
+	Line        int      `json:"line"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	// UnknownPermissions are Squad permissions Aegis has no RCON permission for
+	UnknownPermissions  []string   `json:"unknown_permissions,omitempty"`
+	RoleID              *uuid.UUID `json:"role_id,omitempty"`
+	ExistingPermissions []string   `json:"existing_permissions,omitempty"`
+	Status              string     `json:"status"`
+	Message             string     `json:"message,omitempty"`
+}
+
+// CfgImportAdmin is an admin of Admins.cfg
+type CfgImportAdmin struct {
+	Line    int    `json:"line"`
+	SteamID string `json:"steam_id,omitempty"`
+	EOSID   string `json:"eos_id,omitempty"`
+	Group   string `json:"group"`
+	Comment string `json:"comment,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// CfgImportBan is a ban of Bans.cfg and the active server ban it conflicts with
+type CfgImportBan struct {
+	Line              int        `json:"line"`
+	SteamID           string     `json:"steam_id,omitempty"`
+	EOSID             string     `json:"eos_id,omitempty"`
+	Reason            string     `json:"reason"`
+	IssuedBy          string     `json:"issued_by,omitempty"`
+	IssuerSteamID     string     `json:"issuer_steam_id,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	ExistingBanID     *uuid.UUID `json:"existing_ban_id,omitempty"`
+	ExistingReason    string     `json:"existing_reason,omitempty"`
+	ExistingExpiresAt *time.Time `json:"existing_expires_at,omitempty"`
+	Status            string     `json:"status"`
+	Message           string     `json:"message,omitempty"`
+}
+
+// CfgImportWarning is a line of a file that couldn't be parsed
+type CfgImportWarning struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+	Message string `json:"message"`
+}
+
+// CfgImportResult counts the changes an import applied
+type CfgImportResult struct {
+	RolesCreated int `json:"roles_created"`
+	RolesUpdated int `json:"roles_updated"`
+	AdminsAdded  int `json:"admins_added"`
+	BansAdded    int `json:"bans_added"`
+	BansUpdated  int `json:"bans_updated"`
+}
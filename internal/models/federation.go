@@ -0,0 +1,140 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Trust levels of a federation peer, deciding which of its bans are enforced
+const (
+	// FederationTrustFull enforces every ban the peer shares
+	FederationTrustFull = "full"
+	// FederationTrustEvidence only enforces bans that come with evidence
+	FederationTrustEvidence = "evidence"
+	// FederationTrustObserve records the bans of the peer without enforcing any
+	FederationTrustObserve = "observe"
+)
+
+// Outcomes of a federation peer sync
+const (
+	FederationSyncSuccess = "success"
+	FederationSyncError   = "error"
+)
+
+// FederationAPIKey lets a peer read the federated ban lists of this instance
+type FederationAPIKey struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	KeyHash   string    `json:"-"`
+	KeyPrefix string    `json:"key_prefix"`
+	// BanListIDs limits the key to these ban lists; empty allows every federated list
+	BanListIDs []uuid.UUID `json:"ban_list_ids"`
+	CreatedBy  *uuid.UUID  `json:"created_by,omitempty"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// FederationPeer is an Aegis instance whose federated ban lists are pulled
+// into a local ban list
+type FederationPeer struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	APIKey     string    `json:"-"`
+	TrustLevel string    `json:"trust_level"`
+	// MinDurationDays skips temporary bans shorter than this; permanent bans always pass
+	MinDurationDays int `json:"min_duration_days"`
+	// RuleCategories only accepts bans for these rule categories; empty accepts every ban
+	RuleCategories      []string   `json:"rule_categories"`
+	BanListID           *uuid.UUID `json:"ban_list_id,omitempty"`
+	SyncEnabled         bool       `json:"sync_enabled"`
+	SyncIntervalMinutes int        `json:"sync_interval_minutes"`
+	LastSyncedAt        *time.Time `json:"last_synced_at,omitempty"`
+	LastSyncStatus      *string    `json:"last_sync_status,omitempty"`
+	LastSyncError       *string    `json:"last_sync_error,omitempty"`
+	ActiveBans          int        `json:"active_bans"`
+	EnforcedBans        int        `json:"enforced_bans"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// FederatedBan is a ban received from a federation peer. Community is the
+// community that issued the ban, which differs from the peer when the peer
+// shares bans it received itself.
+type FederatedBan struct {
+	ID              uuid.UUID  `json:"id"`
+	PeerID          uuid.UUID  `json:"peer_id"`
+	PeerName        string     `json:"peer_name"`
+	RemoteBanID     string     `json:"remote_ban_id"`
+	RemoteBanList   string     `json:"remote_ban_list"`
+	Community       string     `json:"community"`
+	SteamID         string     `json:"steam_id,omitempty"`
+	EOSID           string     `json:"eos_id,omitempty"`
+	Reason          string     `json:"reason"`
+	RuleCategory    *string    `json:"rule_category,omitempty"`
+	DurationDays    int        `json:"duration_days"`
+	EvidenceSummary *string    `json:"evidence_summary,omitempty"`
+	EvidenceCount   int        `json:"evidence_count"`
+	IssuedBy        *string    `json:"issued_by,omitempty"`
+	BannedAt        time.Time  `json:"banned_at"`
+	ServerBanID     *uuid.UUID `json:"server_ban_id,omitempty"`
+	Enforced        bool       `json:"enforced"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	ReceivedAt      time.Time  `json:"received_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// FederatedBanListInfo describes a federated ban list to peers
+type FederatedBanListInfo struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	BanCount    int       `json:"ban_count"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// FederatedBanExport is an active ban of a federated ban list as peers receive it
+type FederatedBanExport struct {
+	ID              string     `json:"id"`
+	Community       string     `json:"community"`
+	SteamID         string     `json:"steam_id,omitempty"`
+	EOSID           string     `json:"eos_id,omitempty"`
+	Reason          string     `json:"reason"`
+	RuleCategory    string     `json:"rule_category,omitempty"`
+	DurationDays    int        `json:"duration_days"`
+	IssuedBy        string     `json:"issued_by,omitempty"`
+	EvidenceSummary string     `json:"evidence_summary,omitempty"`
+	EvidenceCount   int        `json:"evidence_count"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+}
+
+type FederationAPIKeyCreateRequest struct {
+	Name       string   `json:"name"`
+	BanListIDs []string `json:"ban_list_ids"`
+}
+
+type FederationPeerCreateRequest struct {
+	Name                string   `json:"name"`
+	URL                 string   `json:"url"`
+	APIKey              string   `json:"api_key"`
+	TrustLevel          string   `json:"trust_level"`
+	MinDurationDays     int      `json:"min_duration_days"`
+	RuleCategories      []string `json:"rule_categories"`
+	SyncEnabled         bool     `json:"sync_enabled"`
+	SyncIntervalMinutes int      `json:"sync_interval_minutes"`
+}
+
+// FederationPeerUpdateRequest changes a peer; an empty APIKey keeps the current key
+type FederationPeerUpdateRequest struct {
+	Name                string   `json:"name"`
+	URL                 string   `json:"url"`
+	APIKey              string   `json:"api_key"`
+	TrustLevel          string   `json:"trust_level"`
+	MinDurationDays     int      `json:"min_duration_days"`
+	RuleCategories      []string `json:"rule_categories"`
+	SyncEnabled         bool     `json:"sync_enabled"`
+	SyncIntervalMinutes int      `json:"sync_interval_minutes"`
+}
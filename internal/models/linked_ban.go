@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// What the ban enforcer does with a player matched to a ban through a linked identity
+const (
+	LinkedBanActionKick = "kick"
+	LinkedBanActionFlag = "flag"
+)
+
+// Outcomes recorded for a linked identity match
+const (
+	BanIdentityMatchKicked     = "kicked"
+	BanIdentityMatchKickFailed = "kick_failed"
+	BanIdentityMatchFlagged    = "flagged"
+)
+
+// Per-ban choice of whether the ban extends to linked identities
+const (
+	LinkedIdentitiesServerDefault = "server_default"
+	LinkedIdentitiesExtend        = "extend"
+	LinkedIdentitiesIgnore        = "ignore"
+)
+
+// LinkedBanSettings is the policy of a server for enforcing bans on the
+// identities linked to a banned player. It only applies in aegis enforcement
+// mode.
+type LinkedBanSettings struct {
+	ServerID uuid.UUID `json:"server_id"`
+	// ExtendByDefault extends bans that don't choose for themselves
+	ExtendByDefault bool   `json:"extend_by_default"`
+	Action          string `json:"action"`
+	// MinConfidence is the confidence, as a percentage, a link needs before
+	// the action is taken
+	MinConfidence int       `json:"min_confidence"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// LinkedBanSettingsUpdateRequest changes the linked identity ban policy of a server
+type LinkedBanSettingsUpdateRequest struct {
+	ExtendByDefault bool   `json:"extend_by_default"`
+	Action          string `json:"action" binding:"required"`
+	MinConfidence   int    `json:"min_confidence" binding:"required"`
+}
+
+// BanIdentityMatch records a connecting player matched to a ban through a
+// linked identity. Links is the chain of Steam and EOS ID pairs connecting
+// the player to the banned identifier.
+type BanIdentityMatch struct {
+	ID               uuid.UUID       `json:"id"`
+	ServerID         uuid.UUID       `json:"server_id"`
+	BanID            uuid.UUID       `json:"ban_id"`
+	SteamID          string          `json:"steam_id"`
+	EOSID            string          `json:"eos_id"`
+	PlayerName       string          `json:"player_name"`
+	BannedIdentifier string          `json:"banned_identifier"`
+	BanReason        string          `json:"ban_reason,omitempty"`
+	Confidence       int             `json:"confidence"`
+	Links            json.RawMessage `json:"links"`
+	Action           string          `json:"action"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
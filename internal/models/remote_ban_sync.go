@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Formats of a remote ban feed
+const (
+	// RemoteBanFormatAuto picks JSON, CSV or text from the content type and URL
+	RemoteBanFormatAuto = "auto"
+	RemoteBanFormatCSV  = "csv"
+	RemoteBanFormatText = "text"
+	RemoteBanFormatJSON = "json"
+)
+
+// Outcomes of a remote ban sync
+const (
+	RemoteBanSyncSuccess     = "success"
+	RemoteBanSyncNotModified = "not_modified"
+	RemoteBanSyncError       = "error"
+)
+
+// RemoteBanSyncRun records one sync of a remote ban source. Added, Removed
+// and Changed are the bans the sync applied to the ban list of the source;
+// Total is the number of bans the feed holds after expired and ignored bans
+// are skipped.
+type RemoteBanSyncRun struct {
+	ID                uuid.UUID `json:"id"`
+	SourceID          uuid.UUID `json:"source_id"`
+	Status            string    `json:"status"`
+	HTTPStatus        *int      `json:"http_status,omitempty"`
+	Total             int       `json:"total"`
+	Added             int       `json:"added"`
+	Removed           int       `json:"removed"`
+	Changed           int       `json:"changed"`
+	SignatureVerified bool      `json:"signature_verified"`
+	Error             *string   `json:"error,omitempty"`
+	StartedAt         time.Time `json:"started_at"`
+	FinishedAt        time.Time `json:"finished_at"`
+}
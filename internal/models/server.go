@@ -16,7 +16,7 @@ type Server struct {
 	RconPassword  string    `json:"-"`
 
 	// Log configuration fields
-	LogSourceType    *string `json:"log_source_type,omitempty"`     // "local", "sftp", "ftp"
+	LogSourceType    *string `json:"log_source_type,omitempty"`     // "local", "sftp", "ftp", "push"
 	LogFilePath      *string `json:"log_file_path,omitempty"`       // Path to log file
 	LogHost          *string `json:"log_host,omitempty"`            // Host for SFTP/FTP
 	LogPort          *int    `json:"log_port,omitempty"`            // Port for SFTP/FTP
@@ -32,6 +32,34 @@ type Server struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// ServerLogPush holds the agent token and resume position of a server using
+// the push log source
+type ServerLogPush struct {
+	ServerID     uuid.UUID `json:"server_id"`
+	TokenHash    string    `json:"-"`
+	LastSequence int64     `json:"last_sequence"`
+	LastOffset   int64     `json:"last_offset"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// LogImportJob is an import of historical log files into ClickHouse
+type LogImportJob struct {
+	ID             uuid.UUID  `json:"id"`
+	ServerID       uuid.UUID  `json:"server_id"`
+	Files          []string   `json:"files"`
+	Status         string     `json:"status"`
+	LinesProcessed int64      `json:"lines_processed"`
+	EventsParsed   int64      `json:"events_parsed"`
+	EventsInserted int64      `json:"events_inserted"`
+	EventsSkipped  int64      `json:"events_skipped"`
+	Error          *string    `json:"error,omitempty"`
+	CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
 type ServerBan struct {
 	ID           string        `json:"id"`
 	ServerID     uuid.UUID     `json:"server_id"`
@@ -39,6 +67,7 @@ type ServerBan struct {
 	AdminName    string        `json:"admin_name"`
 	AdminSteamID string        `json:"admin_steam_id,omitempty"`
 	SteamID      string        `json:"steam_id"`
+	EOSID        string        `json:"eos_id,omitempty"`
 	Name         string        `json:"name"`
 	Reason       string        `json:"reason"`
 	Duration     int           `json:"duration"`
@@ -50,8 +79,11 @@ type ServerBan struct {
 	Evidence     []BanEvidence `json:"evidence,omitempty"`
 	Permanent    bool          `json:"permanent"`
 	ExpiresAt    time.Time     `json:"expires_at,omitempty"`
-	CreatedAt    time.Time     `json:"created_at"`
-	UpdatedAt    time.Time     `json:"updated_at"`
+	LiftedAt     *time.Time    `json:"lifted_at,omitempty"` // Lifted bans are kept, but no longer enforced
+	// ExtendToLinked extends the ban to identities linked to the player; nil follows the server setting
+	ExtendToLinked *bool     `json:"extend_to_linked"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 type BanEvidence struct {
@@ -80,8 +112,10 @@ type BanList struct {
 	RemoteURL         *string    `json:"remote_url,omitempty"`
 	RemoteSyncEnabled bool       `json:"remote_sync_enabled"`
 	LastSyncedAt      *time.Time `json:"last_synced_at,omitempty"`
-	CreatedAt         time.Time  `json:"created_at"`
-	UpdatedAt         time.Time  `json:"updated_at"`
+	// Federated shares the list with peers holding a federation API key
+	Federated bool      `json:"federated"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type ServerBanListSubscription struct {
@@ -101,8 +135,19 @@ type RemoteBanSource struct {
 	LastSyncedAt        *time.Time `json:"last_synced_at,omitempty"`
 	LastSyncStatus      *string    `json:"last_sync_status,omitempty"`
 	LastSyncError       *string    `json:"last_sync_error,omitempty"`
-	CreatedAt           time.Time  `json:"created_at"`
-	UpdatedAt           time.Time  `json:"updated_at"`
+	// Format is one of the RemoteBanFormat values
+	Format string `json:"format"`
+	// PublicKey is a base64 Ed25519 public key. When set, every feed needs a
+	// valid detached signature, fetched from SignatureURL or the feed URL
+	// with .sig appended.
+	PublicKey    *string `json:"public_key,omitempty"`
+	SignatureURL *string `json:"signature_url,omitempty"`
+	// Validators of the last applied feed, sent back as conditional request headers
+	ETag         *string           `json:"-"`
+	LastModified *string           `json:"-"`
+	LastSync     *RemoteBanSyncRun `json:"last_sync,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
 }
 
 type ServerAdmin struct {
@@ -141,12 +186,15 @@ type ServerRole struct {
 
 type ServerBanCreateRequest struct {
 	SteamID      string                  `json:"steam_id"`
+	EOSID        string                  `json:"eos_id,omitempty"`
 	Reason       string                  `json:"reason"`
 	Duration     int                     `json:"duration"`
 	RuleID       *string                 `json:"rule_id,omitempty"`
 	BanListID    *string                 `json:"ban_list_id,omitempty"`
 	EvidenceText *string                 `json:"evidence_text,omitempty"`
 	Evidence     []BanEvidenceCreateItem `json:"evidence,omitempty"`
+	// LinkedIdentities is server_default, extend or ignore
+	LinkedIdentities *string `json:"linked_identities,omitempty"`
 }
 
 type BanEvidenceCreateItem struct {
@@ -171,16 +219,21 @@ type ServerBanUpdateRequest struct {
 	RuleID       *uuid.UUID               `json:"rule_id,omitempty"`
 	EvidenceText *string                  `json:"evidence_text,omitempty"`
 	Evidence     *[]BanEvidenceCreateItem `json:"evidence,omitempty"`
+	Lifted       *bool                    `json:"lifted,omitempty"` // Lifts the ban, or reinstates a lifted one
+	// LinkedIdentities is server_default, extend or ignore
+	LinkedIdentities *string `json:"linked_identities,omitempty"`
 }
 
 type BanListCreateRequest struct {
 	Name        string  `json:"name"`
 	Description *string `json:"description,omitempty"`
+	Federated   bool    `json:"federated"`
 }
 
 type BanListUpdateRequest struct {
 	Name        string  `json:"name"`
 	Description *string `json:"description,omitempty"`
+	Federated   bool    `json:"federated"`
 }
 
 type ServerBanListSubscriptionRequest struct {
@@ -192,6 +245,9 @@ type RemoteBanSourceCreateRequest struct {
 	URL                 string `json:"url"`
 	SyncEnabled         bool   `json:"sync_enabled"`
 	SyncIntervalMinutes int    `json:"sync_interval_minutes"`
+	Format              string `json:"format"`
+	PublicKey           string `json:"public_key"`
+	SignatureURL        string `json:"signature_url"`
 }
 
 type RemoteBanSourceUpdateRequest struct {
@@ -199,6 +255,9 @@ type RemoteBanSourceUpdateRequest struct {
 	URL                 string `json:"url"`
 	SyncEnabled         bool   `json:"sync_enabled"`
 	SyncIntervalMinutes int    `json:"sync_interval_minutes"`
+	Format              string `json:"format"`
+	PublicKey           string `json:"public_key"`
+	SignatureURL        string `json:"signature_url"`
 }
 
 type ServerAdminCreateRequest struct {
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServerGroup is a named set of servers that RCON commands, broadcasts,
+// kicks and bans can be fanned out to
+type ServerGroup struct {
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	Description *string     `json:"description,omitempty"`
+	ServerIDs   []uuid.UUID `json:"server_ids"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// ServerGroupUserPermissions lists the permission codes granted to a user on
+// a server group
+type ServerGroupUserPermissions struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	Permissions []string  `json:"permissions"`
+}
+
+type ServerGroupCreateRequest struct {
+	Name        string      `json:"name" binding:"required"`
+	Description *string     `json:"description"`
+	ServerIDs   []uuid.UUID `json:"server_ids"`
+}
+
+type ServerGroupUpdateRequest struct {
+	Name        *string     `json:"name,omitempty"`
+	Description *string     `json:"description,omitempty"`
+	ServerIDs   []uuid.UUID `json:"server_ids,omitempty"`
+}
+
+type ServerGroupPermissionsUpdateRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+type ServerGroupRconRequest struct {
+	Command string `json:"command" binding:"required"`
+}
+
+type ServerGroupBroadcastRequest struct {
+	Message string `json:"message" binding:"required"`
+}
+
+type ServerGroupKickRequest struct {
+	SteamID string `json:"steam_id" binding:"required"`
+	Reason  string `json:"reason"`
+}
+
+type ServerGroupBanRequest struct {
+	SteamID      string  `json:"steam_id" binding:"required"`
+	Reason       string  `json:"reason" binding:"required"`
+	Duration     int     `json:"duration"` // Duration in days, 0 for permanent
+	BanListID    *string `json:"ban_list_id,omitempty"`
+	EvidenceText *string `json:"evidence_text,omitempty"`
+}
+
+// ServerGroupActionResult is the outcome of a group action on one member server
+type ServerGroupActionResult struct {
+	ServerID   uuid.UUID  `json:"server_id"`
+	ServerName string     `json:"server_name"`
+	Success    bool       `json:"success"`
+	Response   string     `json:"response,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	AuditLogID *uuid.UUID `json:"audit_log_id,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServerLogParser is an admin-defined regex run against every log line of a
+// server. Matches are published as LOG_CUSTOM events.
+type ServerLogParser struct {
+	ID        uuid.UUID `json:"id"`
+	ServerID  uuid.UUID `json:"server_id"`
+	Name      string    `json:"name"`
+	EventName string    `json:"event_name"`
+	Pattern   string    `json:"pattern"`
+	// FieldMapping maps event field names to named capture groups. When it is
+	// empty every named group becomes a field of the same name.
+	FieldMapping map[string]string `json:"field_mapping"`
+	Enabled      bool              `json:"enabled"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+type ServerLogParserCreateRequest struct {
+	Name         string            `json:"name" binding:"required"`
+	EventName    string            `json:"event_name" binding:"required"`
+	Pattern      string            `json:"pattern" binding:"required"`
+	FieldMapping map[string]string `json:"field_mapping"`
+	Enabled      *bool             `json:"enabled"`
+}
+
+type ServerLogParserUpdateRequest struct {
+	Name         *string           `json:"name,omitempty"`
+	EventName    *string           `json:"event_name,omitempty"`
+	Pattern      *string           `json:"pattern,omitempty"`
+	FieldMapping map[string]string `json:"field_mapping,omitempty"`
+	Enabled      *bool             `json:"enabled,omitempty"`
+}
+
+type ServerLogParserTestRequest struct {
+	Pattern      string            `json:"pattern" binding:"required"`
+	EventName    string            `json:"event_name"`
+	FieldMapping map[string]string `json:"field_mapping"`
+	Lines        []string          `json:"lines" binding:"required"`
+}
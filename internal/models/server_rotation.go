@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RotationModeOrdered  = "ordered"
+	RotationModeWeighted = "weighted"
+)
+
+// ServerRotation is the map rotation of a server. The rotation manager picks
+// the next layer from Layers whenever a match starts.
+type ServerRotation struct {
+	ServerID uuid.UUID `json:"server_id"`
+	Enabled  bool      `json:"enabled"`
+	// Mode is "ordered" to walk the pool in order or "weighted" to pick at
+	// random, weighted by each layer's weight
+	Mode string `json:"mode"`
+	// MapRepeatWindow is the number of most recent matches whose maps may not
+	// be picked again
+	MapRepeatWindow int `json:"map_repeat_window"`
+	// FactionRepeatWindow is the number of most recent matches whose factions
+	// may not be picked again
+	FactionRepeatWindow int             `json:"faction_repeat_window"`
+	Layers              []RotationLayer `json:"layers"`
+	// Position is the index in Layers the ordered mode continues from
+	Position  int       `json:"position"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RotationLayer is one entry of a rotation pool with the rules deciding when
+// it can be picked
+type RotationLayer struct {
+	Layer        string `json:"layer"`
+	Team1Faction string `json:"team1_faction,omitempty"`
+	Team2Faction string `json:"team2_faction,omitempty"`
+	Weight       int    `json:"weight"`
+	// MinPlayers and MaxPlayers limit the entry to a player count range. Zero
+	// means no limit.
+	MinPlayers int `json:"min_players"`
+	MaxPlayers int `json:"max_players"`
+	// StartHour and EndHour limit the entry to a UTC time of day window. The
+	// window wraps around midnight when StartHour is after EndHour.
+	StartHour *int `json:"start_hour,omitempty"`
+	EndHour   *int `json:"end_hour,omitempty"`
+}
+
+// LayerHistoryEntry is a layer played on a server, as stored in ClickHouse
+type LayerHistoryEntry struct {
+	EventTime    time.Time `json:"event_time"`
+	Layer        string    `json:"layer"`
+	Map          string    `json:"map"`
+	Team1Faction string    `json:"team1_faction"`
+	Team2Faction string    `json:"team2_faction"`
+	PlayerCount  int       `json:"player_count"`
+	Source       string    `json:"source"`
+}
+
+type ServerRotationUpdateRequest struct {
+	Enabled             bool            `json:"enabled"`
+	Mode                string          `json:"mode"`
+	MapRepeatWindow     int             `json:"map_repeat_window"`
+	FactionRepeatWindow int             `json:"faction_repeat_window"`
+	Layers              []RotationLayer `json:"layers"`
+}
+
+type ServerRotationSetNextRequest struct {
+	Layer        string `json:"layer" binding:"required"`
+	Team1Faction string `json:"team1_faction"`
+	Team2Faction string `json:"team2_faction"`
+}
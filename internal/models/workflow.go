@@ -15,6 +15,7 @@ type ServerWorkflow struct {
 	Description *string                  `json:"description,omitempty"`
 	Enabled     bool                     `json:"enabled"`
 	Definition  WorkflowDefinition       `json:"definition"`
+	Revision    int                      `json:"revision"` // Current revision number, incremented on every save
 	CreatedBy   uuid.UUID                `json:"created_by"`
 	CreatedAt   time.Time                `json:"created_at"`
 	UpdatedAt   time.Time                `json:"updated_at"`
@@ -28,15 +29,66 @@ type WorkflowDefinition struct {
 	Variables     map[string]interface{} `json:"variables"` // Default workflow variables
 	Steps         []WorkflowStep         `json:"steps"`     // Ordered list of steps to execute
 	ErrorHandling WorkflowErrorHandling  `json:"error_handling,omitempty"`
+	LuaLimits     *WorkflowLuaLimits     `json:"lua_limits,omitempty"`  // Resource limits for Lua scripts, defaults apply when nil
+	Concurrency   *WorkflowConcurrency   `json:"concurrency,omitempty"` // Limits on concurrent executions and trigger rate, unlimited when nil
+}
+
+// WorkflowConcurrency limits how many executions of a workflow run at once
+// and how often its triggers may start new ones
+type WorkflowConcurrency struct {
+	MaxConcurrent  int                `json:"max_concurrent,omitempty"`  // Executions allowed to run at once, 0 for no limit
+	OverflowPolicy string             `json:"overflow_policy,omitempty"` // "queue" (default) or "drop" for triggers over the limit
+	MaxQueued      int                `json:"max_queued,omitempty"`      // Triggers waiting beyond this are dropped, defaults to 100
+	RateLimit      *WorkflowRateLimit `json:"rate_limit,omitempty"`
+}
+
+// WorkflowRateLimit debounces or throttles triggers within a time window
+type WorkflowRateLimit struct {
+	Mode     string `json:"mode"`          // "throttle" runs the first trigger of a window, "debounce" runs the last once the window is quiet
+	WindowMs int64  `json:"window_ms"`     // Window length in milliseconds
+	Key      string `json:"key,omitempty"` // Template splitting triggers into separate windows, e.g. "${trigger_event.eos_id}"
+}
+
+// Concurrency overflow policies
+const (
+	OverflowPolicyQueue = "queue"
+	OverflowPolicyDrop  = "drop"
+)
+
+// Rate limit modes
+const (
+	RateLimitThrottle = "throttle"
+	RateLimitDebounce = "debounce"
+)
+
+// WorkflowLuaLimits restricts the resources Lua scripts in a workflow can use.
+// Zero values fall back to the defaults.
+type WorkflowLuaLimits struct {
+	TimeoutSeconds  int      `json:"timeout_seconds,omitempty"`  // Wall-clock limit per script
+	MaxInstructions int64    `json:"max_instructions,omitempty"` // VM instruction budget per script
+	MaxMemoryKB     int      `json:"max_memory_kb,omitempty"`    // Limit on the estimated size of values a script holds
+	Libraries       []string `json:"libraries,omitempty"`        // Standard libraries to load: base, table, string, math, os, coroutine
 }
 
 // WorkflowTrigger defines what events trigger this workflow
 type WorkflowTrigger struct {
 	ID         string              `json:"id"`                   // Unique identifier for this trigger
 	Name       string              `json:"name"`                 // Human-readable name
+	Type       string              `json:"type,omitempty"`       // "event" (default), "cron" or "interval"
 	EventType  string              `json:"event_type"`           // Event type from event_manager
 	Conditions []WorkflowCondition `json:"conditions,omitempty"` // Optional conditions to filter events
 	Enabled    bool                `json:"enabled"`              // Whether this trigger is active
+
+	// Scheduled triggers
+	Cron            string `json:"cron,omitempty"`             // Five-field cron expression or macro like "@hourly"
+	IntervalSeconds int    `json:"interval_seconds,omitempty"` // Seconds between runs for interval triggers
+	Timezone        string `json:"timezone,omitempty"`         // IANA time zone for cron triggers, defaults to UTC
+	MissedRuns      string `json:"missed_runs,omitempty"`      // "skip" (default) or "run_once" after downtime
+}
+
+// IsScheduled returns true if the trigger fires on a schedule instead of an event
+func (t *WorkflowTrigger) IsScheduled() bool {
+	return t.Type == TriggerTypeCron || t.Type == TriggerTypeInterval
 }
 
 // WorkflowStep represents a single step in a workflow
@@ -76,16 +128,65 @@ type WorkflowErrorAction struct {
 
 // ServerWorkflowExecution tracks workflow executions
 type ServerWorkflowExecution struct {
-	ID           uuid.UUID              `json:"id"`
-	WorkflowID   uuid.UUID              `json:"workflow_id"`
-	ExecutionID  uuid.UUID              `json:"execution_id"`  // Links to ClickHouse logs
-	Status       string                 `json:"status"`        // "RUNNING", "COMPLETED", "FAILED", "CANCELLED"
-	TriggerData  map[string]interface{} `json:"trigger_data"`  // Event data that triggered the workflow
-	StartedAt    time.Time              `json:"started_at"`
-	CompletedAt  *time.Time             `json:"completed_at,omitempty"`
-	ErrorMessage *string                `json:"error_message,omitempty"`
+	ID           uuid.UUID                    `json:"id"`
+	WorkflowID   uuid.UUID                    `json:"workflow_id"`
+	ExecutionID  uuid.UUID                    `json:"execution_id"` // Links to ClickHouse logs
+	Status       string                       `json:"status"`       // "RUNNING", "COMPLETED", "FAILED", "CANCELLED"
+	TriggerData  map[string]interface{}       `json:"trigger_data"` // Event data that triggered the workflow
+	StartedAt    time.Time                    `json:"started_at"`
+	CompletedAt  *time.Time                   `json:"completed_at,omitempty"`
+	ErrorMessage *string                      `json:"error_message,omitempty"`
+	Revision     *int                         `json:"revision,omitempty"` // Workflow revision that ran, nil for executions before revisions existed
+	RetryOf      *uuid.UUID                   `json:"retry_of,omitempty"` // Execution this one retried
+	Checkpoint   *WorkflowExecutionCheckpoint `json:"checkpoint,omitempty"`
+	CheckpointAt *time.Time                   `json:"checkpoint_at,omitempty"`
+}
+
+// WorkflowExecutionCheckpoint is the saved state of a running execution,
+// written before each top-level step so the execution can resume after a restart
+type WorkflowExecutionCheckpoint struct {
+	StepIndex      int                    `json:"step_index"` // Top-level step the execution resumes at
+	StepID         string                 `json:"step_id"`
+	Variables      map[string]interface{} `json:"variables"`
+	StepResults    map[string]interface{} `json:"step_results"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	CompletedSteps uint32                 `json:"completed_steps"`
+	FailedSteps    uint32                 `json:"failed_steps"`
+	SkippedSteps   uint32                 `json:"skipped_steps"`
+	DelayUntil     *time.Time             `json:"delay_until,omitempty"` // End of the delay step at StepIndex, if it was sleeping
 }
 
+// ServerWorkflowRevision is an immutable snapshot of a saved workflow
+type ServerWorkflowRevision struct {
+	ID            uuid.UUID          `json:"id"`
+	WorkflowID    uuid.UUID          `json:"workflow_id"`
+	Revision      int                `json:"revision"`
+	Name          string             `json:"name"`
+	Description   *string            `json:"description,omitempty"`
+	Enabled       bool               `json:"enabled"`
+	Definition    WorkflowDefinition `json:"definition"`
+	ChangeSummary *string            `json:"change_summary,omitempty"`
+	RestoredFrom  *int               `json:"restored_from,omitempty"` // Revision a rollback restored
+	CreatedBy     uuid.UUID          `json:"created_by"`
+	CreatedAt     time.Time          `json:"created_at"`
+	Changes       []WorkflowChange   `json:"changes,omitempty"` // Differences from the previous revision
+}
+
+// WorkflowChange is a single difference between two workflow revisions
+type WorkflowChange struct {
+	Path     string      `json:"path"` // Dotted path, e.g. "definition.steps[id=warn].config.message"
+	Type     string      `json:"type"` // added, removed or changed
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
+}
+
+// Workflow change types
+const (
+	WorkflowChangeAdded   = "added"
+	WorkflowChangeRemoved = "removed"
+	WorkflowChangeChanged = "changed"
+)
+
 // ServerWorkflowVariable stores dynamic variables for workflows
 type ServerWorkflowVariable struct {
 	ID          uuid.UUID              `json:"id"`
@@ -108,8 +209,22 @@ type WorkflowExecutionContext struct {
 	StepResults  map[string]interface{} `json:"step_results"`
 	CurrentStep  string                 `json:"current_step"`
 	StartedAt    time.Time              `json:"started_at"`
+	LuaLimits    *WorkflowLuaLimits     `json:"lua_limits,omitempty"`
 }
 
+// Predefined trigger types
+const (
+	TriggerTypeEvent    = "event"
+	TriggerTypeCron     = "cron"
+	TriggerTypeInterval = "interval"
+)
+
+// Missed run policies for scheduled triggers
+const (
+	MissedRunsSkip    = "skip"
+	MissedRunsRunOnce = "run_once"
+)
+
 // Predefined step types
 const (
 	StepTypeCondition = "condition"
@@ -165,10 +280,15 @@ type ServerWorkflowCreateRequest struct {
 }
 
 type ServerWorkflowUpdateRequest struct {
-	Name        *string             `json:"name,omitempty"`
-	Description *string             `json:"description,omitempty"`
-	Enabled     *bool               `json:"enabled,omitempty"`
-	Definition  *WorkflowDefinition `json:"definition,omitempty"`
+	Name          *string             `json:"name,omitempty"`
+	Description   *string             `json:"description,omitempty"`
+	Enabled       *bool               `json:"enabled,omitempty"`
+	Definition    *WorkflowDefinition `json:"definition,omitempty"`
+	ChangeSummary *string             `json:"change_summary,omitempty"`
+}
+
+type WorkflowRollbackRequest struct {
+	ChangeSummary *string `json:"change_summary,omitempty"`
 }
 
 type ServerWorkflowVariableCreateRequest struct {
@@ -188,6 +308,75 @@ type WorkflowExecuteRequest struct {
 	Variables    map[string]interface{} `json:"variables,omitempty"`
 }
 
+// WorkflowBundle is the portable format for exporting workflows and importing
+// them on another server
+type WorkflowBundle struct {
+	Format     string                `json:"format"`  // Always "squad-aegis-workflows"
+	Version    int                   `json:"version"` // Bundle format version
+	ExportedAt time.Time             `json:"exported_at"`
+	Workflows  []WorkflowBundleEntry `json:"workflows"`
+}
+
+// WorkflowBundleEntry is a single workflow inside a bundle
+type WorkflowBundleEntry struct {
+	Name        string                   `json:"name"`
+	Description *string                  `json:"description,omitempty"`
+	Enabled     bool                     `json:"enabled"`
+	Definition  WorkflowDefinition       `json:"definition"`
+	Variables   []WorkflowBundleVariable `json:"variables,omitempty"`
+	KV          map[string]interface{}   `json:"kv,omitempty"` // Seed data for the workflow KV store
+}
+
+// WorkflowBundleVariable is a workflow variable inside a bundle
+type WorkflowBundleVariable struct {
+	Name        string                 `json:"name"`
+	Value       map[string]interface{} `json:"value"`
+	Description *string                `json:"description,omitempty"`
+}
+
+// WorkflowCloneRequest copies a workflow to other servers
+type WorkflowCloneRequest struct {
+	Targets   []WorkflowCloneTarget `json:"targets" binding:"required"`
+	IncludeKV bool                  `json:"include_kv"`        // Copy the current KV store contents
+	Enabled   *bool                 `json:"enabled,omitempty"` // Defaults to the source workflow's state
+}
+
+// WorkflowCloneTarget is a server a workflow is cloned to
+type WorkflowCloneTarget struct {
+	ServerID  uuid.UUID              `json:"server_id" binding:"required"`
+	Name      *string                `json:"name,omitempty"`      // Defaults to the source workflow name
+	Variables map[string]interface{} `json:"variables,omitempty"` // Overrides for the definition's default variables
+}
+
+// WorkflowValidateRequest is the body of the standalone validation endpoint
+type WorkflowValidateRequest struct {
+	Definition WorkflowDefinition `json:"definition" binding:"required"`
+}
+
+// WorkflowValidationResult holds the problems found in a workflow definition.
+// Errors block saving the workflow; warnings are informational.
+type WorkflowValidationResult struct {
+	Valid    bool                      `json:"valid"`
+	Errors   []WorkflowValidationIssue `json:"errors"`
+	Warnings []WorkflowValidationIssue `json:"warnings"`
+}
+
+// WorkflowValidationIssue is a single problem found by the validator
+type WorkflowValidationIssue struct {
+	Severity string `json:"severity"`            // error or warning
+	Code     string `json:"code"`                // Machine readable issue type, e.g. "dangling_reference"
+	Path     string `json:"path"`                // Location in the definition, e.g. "steps[2].true_steps[0]"
+	StepID   string `json:"step_id,omitempty"`   // Step the issue belongs to
+	StepName string `json:"step_name,omitempty"` // Name of that step
+	Message  string `json:"message"`
+}
+
+// Workflow validation severities
+const (
+	ValidationSeverityError   = "error"
+	ValidationSeverityWarning = "warning"
+)
+
 // WorkflowExecutionLog represents a single log entry from ClickHouse
 type WorkflowExecutionLog struct {
 	ExecutionID      uuid.UUID              `json:"execution_id"`
@@ -241,6 +430,75 @@ type WorkflowLogMessage struct {
 	Metadata    map[string]interface{} `json:"metadata"`  // Additional context
 }
 
+// WorkflowSimulateRequest runs a workflow without side effects. The trigger
+// event is either given directly or replayed from a previous execution.
+type WorkflowSimulateRequest struct {
+	TriggerEvent      map[string]interface{} `json:"trigger_event,omitempty"`
+	ReplayExecutionID *uuid.UUID             `json:"replay_execution_id,omitempty"`
+	Definition        *WorkflowDefinition    `json:"definition,omitempty"`     // Unsaved definition to simulate instead of the stored one
+	Variables         map[string]interface{} `json:"variables,omitempty"`      // Overrides for workflow variables
+	KV                map[string]interface{} `json:"kv,omitempty"`             // Initial contents of the simulated KV store
+	RconResponses     map[string]string      `json:"rcon_responses,omitempty"` // Mock responses by full command or command name
+}
+
+// WorkflowSimulationResult is the outcome of a simulated workflow run
+type WorkflowSimulationResult struct {
+	ExecutionID      uuid.UUID                   `json:"execution_id"`
+	WorkflowID       uuid.UUID                   `json:"workflow_id"`
+	TriggerMatched   bool                        `json:"trigger_matched"`
+	MatchedTriggerID string                      `json:"matched_trigger_id,omitempty"`
+	TriggerEvent     map[string]interface{}      `json:"trigger_event"`
+	Status           string                      `json:"status"`
+	ErrorMessage     *string                     `json:"error_message,omitempty"`
+	DurationMs       int64                       `json:"duration_ms"`
+	Trace            []WorkflowSimulationStep    `json:"trace"`
+	Messages         []WorkflowSimulationMessage `json:"messages"`
+	SideEffects      []WorkflowSideEffect        `json:"side_effects"`
+	Variables        map[string]interface{}      `json:"variables"`
+	StepResults      map[string]interface{}      `json:"step_results"`
+	KV               map[string]interface{}      `json:"kv"`
+}
+
+// WorkflowSimulationStep is a step log entry captured during a simulation
+type WorkflowSimulationStep struct {
+	Time       time.Time              `json:"time"`
+	StepName   string                 `json:"step_name"`
+	StepType   string                 `json:"step_type"`
+	StepOrder  uint32                 `json:"step_order"`
+	Status     string                 `json:"status"`
+	Input      map[string]interface{} `json:"input"`
+	Output     map[string]interface{} `json:"output"`
+	Error      *string                `json:"error,omitempty"`
+	DurationMs uint32                 `json:"duration_ms"`
+	Variables  map[string]interface{} `json:"variables"` // Snapshot of the workflow variables after the entry
+}
+
+// WorkflowSimulationMessage is a log message captured during a simulation
+type WorkflowSimulationMessage struct {
+	Time     time.Time `json:"time"`
+	StepID   string    `json:"step_id"`
+	StepName string    `json:"step_name"`
+	Level    string    `json:"level"`
+	Message  string    `json:"message"`
+}
+
+// WorkflowSideEffect is an external action that a simulation captured instead of performing
+type WorkflowSideEffect struct {
+	Time    time.Time              `json:"time"`
+	StepID  string                 `json:"step_id"`
+	Type    string                 `json:"type"`   // rcon, http, database or kv
+	Target  string                 `json:"target"` // Command, URL, table or key
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Side effect types captured by workflow simulations
+const (
+	SideEffectRcon     = "rcon"
+	SideEffectHTTP     = "http"
+	SideEffectDatabase = "database"
+	SideEffectKV       = "kv"
+)
+
 // Helper methods
 
 // MarshalDefinition converts WorkflowDefinition to JSON for database storage
@@ -261,7 +519,7 @@ func (w *ServerWorkflow) IsActive() bool {
 // GetTriggerByEventType returns the first trigger that matches the event type
 func (w *ServerWorkflow) GetTriggerByEventType(eventType string) *WorkflowTrigger {
 	for _, trigger := range w.Definition.Triggers {
-		if trigger.EventType == eventType && trigger.Enabled {
+		if trigger.EventType == eventType && trigger.Enabled && !trigger.IsScheduled() {
 			return &trigger
 		}
 	}
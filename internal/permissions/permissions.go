@@ -20,34 +20,35 @@ const Wildcard Permission = "*"
 
 // UI Permissions - Control access to pages/components.
 const (
-	UIDashboardView   Permission = "ui:dashboard:view"
-	UIAuditLogsView   Permission = "ui:audit_logs:view"
-	UIMetricsView     Permission = "ui:metrics:view"
-	UIFeedsView       Permission = "ui:feeds:view"
-	UIConsoleView     Permission = "ui:console:view"
-	UIConsoleExecute  Permission = "ui:console:execute"
-	UIPluginsView     Permission = "ui:plugins:view"
-	UIPluginsManage   Permission = "ui:plugins:manage"
-	UIWorkflowsView   Permission = "ui:workflows:view"
-	UIWorkflowsManage Permission = "ui:workflows:manage"
-	UISettingsView    Permission = "ui:settings:view"
-	UISettingsManage  Permission = "ui:settings:manage"
-	UIUsersManage     Permission = "ui:users:manage"
-	UIRolesManage     Permission = "ui:roles:manage"
-	UIBansView        Permission = "ui:bans:view"
-	UIBansCreate      Permission = "ui:bans:create"
-	UIBansEdit        Permission = "ui:bans:edit"
-	UIBansDelete      Permission = "ui:bans:delete"
-	UIPlayersView     Permission = "ui:players:view"
-	UIPlayersKick     Permission = "ui:players:kick"
-	UIPlayersWarn     Permission = "ui:players:warn"
-	UIPlayersMove     Permission = "ui:players:move"
-	UIRulesView       Permission = "ui:rules:view"
-	UIRulesManage     Permission = "ui:rules:manage"
-	UIBanListsView    Permission = "ui:ban_lists:view"
-	UIBanListsManage  Permission = "ui:ban_lists:manage"
-	UIMOTDView        Permission = "ui:motd:view"
-	UIMOTDManage      Permission = "ui:motd:manage"
+	UIDashboardView    Permission = "ui:dashboard:view"
+	UIAuditLogsView    Permission = "ui:audit_logs:view"
+	UIMetricsView      Permission = "ui:metrics:view"
+	UIFeedsView        Permission = "ui:feeds:view"
+	UIConsoleView      Permission = "ui:console:view"
+	UIConsoleExecute   Permission = "ui:console:execute"
+	UIPluginsView      Permission = "ui:plugins:view"
+	UIPluginsManage    Permission = "ui:plugins:manage"
+	UIWorkflowsView    Permission = "ui:workflows:view"
+	UIWorkflowsManage  Permission = "ui:workflows:manage"
+	UISettingsView     Permission = "ui:settings:view"
+	UISettingsManage   Permission = "ui:settings:manage"
+	UIUsersManage      Permission = "ui:users:manage"
+	UIRolesManage      Permission = "ui:roles:manage"
+	UIBansView         Permission = "ui:bans:view"
+	UIBansCreate       Permission = "ui:bans:create"
+	UIBansEdit         Permission = "ui:bans:edit"
+	UIBansDelete       Permission = "ui:bans:delete"
+	UIPlayersView      Permission = "ui:players:view"
+	UIPlayersKick      Permission = "ui:players:kick"
+	UIPlayersWarn      Permission = "ui:players:warn"
+	UIPlayersMove      Permission = "ui:players:move"
+	UIRulesView        Permission = "ui:rules:view"
+	UIRulesManage      Permission = "ui:rules:manage"
+	UIBanListsView     Permission = "ui:ban_lists:view"
+	UIBanListsManage   Permission = "ui:ban_lists:manage"
+	UIBanAppealsReview Permission = "ui:ban_appeals:review"
+	UIMOTDView         Permission = "ui:motd:view"
+	UIMOTDManage       Permission = "ui:motd:manage"
 )
 
 // RCON/Squad Permissions - Map to Squad's admin.cfg permissions.
@@ -188,7 +189,7 @@ func AllPermissions() []Permission {
 		UIWorkflowsView, UIWorkflowsManage, UISettingsView, UISettingsManage,
 		UIUsersManage, UIRolesManage, UIBansView, UIBansCreate, UIBansEdit, UIBansDelete,
 		UIPlayersView, UIPlayersKick, UIPlayersWarn, UIPlayersMove,
-		UIRulesView, UIRulesManage, UIBanListsView, UIBanListsManage,
+		UIRulesView, UIRulesManage, UIBanListsView, UIBanListsManage, UIBanAppealsReview,
 		UIMOTDView, UIMOTDManage,
 		// RCON
 		RCONReserve, RCONBalance, RCONCanSeeAdminChat, RCONManageServer,
@@ -207,7 +208,7 @@ func UIPermissions() []Permission {
 		UIWorkflowsView, UIWorkflowsManage, UISettingsView, UISettingsManage,
 		UIUsersManage, UIRolesManage, UIBansView, UIBansCreate, UIBansEdit, UIBansDelete,
 		UIPlayersView, UIPlayersKick, UIPlayersWarn, UIPlayersMove,
-		UIRulesView, UIRulesManage, UIBanListsView, UIBanListsManage,
+		UIRulesView, UIRulesManage, UIBanListsView, UIBanListsManage, UIBanAppealsReview,
 		UIMOTDView, UIMOTDManage,
 	}
 }
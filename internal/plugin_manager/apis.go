@@ -13,6 +13,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"go.codycody31.dev/squad-aegis/internal/clickhouse"
 	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
 	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
 	"go.codycody31.dev/squad-aegis/internal/shared/config"
 	squadRcon "go.codycody31.dev/squad-aegis/internal/squad-rcon"
@@ -610,20 +611,31 @@ func (api *databaseAPI) DeletePluginData(key string) error {
 // rconAPI implements RconAPI interface
 type rconAPI struct {
 	serverID         uuid.UUID
+	instanceID       uuid.UUID
 	db               *sql.DB
 	rconManager      *rcon_manager.RconManager
 	clickhouseClient *clickhouse.Client
 }
 
-func NewRconAPI(serverID uuid.UUID, db *sql.DB, rconManager *rcon_manager.RconManager, clickhouseClient *clickhouse.Client) RconAPI {
+func NewRconAPI(serverID, instanceID uuid.UUID, db *sql.DB, rconManager *rcon_manager.RconManager, clickhouseClient *clickhouse.Client) RconAPI {
 	return &rconAPI{
 		serverID:         serverID,
+		instanceID:       instanceID,
 		db:               db,
 		rconManager:      rconManager,
 		clickhouseClient: clickhouseClient,
 	}
 }
 
+// execute queues a command as this plugin instance, so it is rate limited
+// separately from other plugins, workflows and users
+func (api *rconAPI) execute(command string, priority int) (string, error) {
+	return api.rconManager.ExecuteCommandWithOptions(api.serverID, command, rcon_manager.CommandOptions{
+		Priority: priority,
+		Caller:   rcon_manager.PluginCaller(api.instanceID),
+	})
+}
+
 func (api *rconAPI) SendCommand(command string) (string, error) {
 	// Extract command name (first word)
 	parts := strings.Fields(command)
@@ -632,7 +644,7 @@ func (api *rconAPI) SendCommand(command string) (string, error) {
 	}
 
 	// Execute command via RCON manager
-	response, err := api.rconManager.ExecuteCommand(api.serverID, command)
+	response, err := api.execute(command, rcon_manager.PriorityNormal)
 	if err != nil {
 		return "", fmt.Errorf("failed to execute RCON command: %w", err)
 	}
@@ -642,7 +654,7 @@ func (api *rconAPI) SendCommand(command string) (string, error) {
 
 func (api *rconAPI) Broadcast(message string) error {
 	command := fmt.Sprintf("AdminBroadcast %s", message)
-	_, err := api.rconManager.ExecuteCommand(api.serverID, command)
+	_, err := api.execute(command, rcon_manager.PriorityNormal)
 	if err != nil {
 		return fmt.Errorf("failed to send broadcast message: %w", err)
 	}
@@ -651,7 +663,7 @@ func (api *rconAPI) Broadcast(message string) error {
 
 func (api *rconAPI) SendWarningToPlayer(playerID string, message string) error {
 	command := fmt.Sprintf("AdminWarn \"%s\" %s", playerID, message)
-	_, err := api.rconManager.ExecuteCommand(api.serverID, command)
+	_, err := api.execute(command, rcon_manager.PriorityNormal)
 	if err != nil {
 		return fmt.Errorf("failed to send warning to player: %w", err)
 	}
@@ -660,7 +672,7 @@ func (api *rconAPI) SendWarningToPlayer(playerID string, message string) error {
 
 func (api *rconAPI) KickPlayer(playerID string, reason string) error {
 	command := fmt.Sprintf("AdminKick \"%s\" %s", playerID, reason)
-	_, err := api.rconManager.ExecuteCommand(api.serverID, command)
+	_, err := api.execute(command, rcon_manager.PriorityHigh)
 	if err != nil {
 		return fmt.Errorf("failed to kick player: %w", err)
 	}
@@ -669,7 +681,7 @@ func (api *rconAPI) KickPlayer(playerID string, reason string) error {
 
 func (api *rconAPI) RemovePlayerFromSquad(playerID string) error {
 	command := fmt.Sprintf("AdminRemovePlayerFromSquad \"%s\"", playerID)
-	_, err := api.rconManager.ExecuteCommand(api.serverID, command)
+	_, err := api.execute(command, rcon_manager.PriorityNormal)
 	if err != nil {
 		return fmt.Errorf("failed to remove player from squad: %w", err)
 	}
@@ -678,7 +690,7 @@ func (api *rconAPI) RemovePlayerFromSquad(playerID string) error {
 
 func (api *rconAPI) RemovePlayerFromSquadById(playerID string) error {
 	command := fmt.Sprintf("AdminRemovePlayerFromSquadById \"%s\"", playerID)
-	_, err := api.rconManager.ExecuteCommand(api.serverID, command)
+	_, err := api.execute(command, rcon_manager.PriorityNormal)
 	if err != nil {
 		return fmt.Errorf("failed to remove player from squad: %w", err)
 	}
@@ -690,7 +702,7 @@ func (api *rconAPI) BanPlayer(playerID string, reason string, duration time.Dura
 	durationDays := int(duration.Hours() / 24)
 
 	command := fmt.Sprintf("AdminBan \"%s\" %dd %s", playerID, durationDays, reason)
-	_, err := api.rconManager.ExecuteCommand(api.serverID, command)
+	_, err := api.execute(command, rcon_manager.PriorityHigh)
 	if err != nil {
 		return fmt.Errorf("failed to ban player: %w", err)
 	}
@@ -803,14 +815,14 @@ func (api *rconAPI) BanWithEvidence(playerID string, reason string, duration tim
 
 	// Execute RCON ban
 	command := fmt.Sprintf("AdminBan \"%s\" %dd %s", playerID, durationDays, reason)
-	_, err = api.rconManager.ExecuteCommand(api.serverID, command)
+	_, err = api.execute(command, rcon_manager.PriorityHigh)
 	if err != nil {
 		log.Error().Err(err).Str("banID", banID.String()).Msg("RCON ban failed but database ban created")
 	}
 
 	// Kick player
 	kickCommand := fmt.Sprintf("AdminKick \"%s\" %s", playerID, reason)
-	_, _ = api.rconManager.ExecuteCommand(api.serverID, kickCommand)
+	_, _ = api.execute(kickCommand, rcon_manager.PriorityHigh)
 
 	return banID.String(), nil
 }
@@ -1065,6 +1077,35 @@ func (api *connectorAPI) ListConnectors() []string {
 	return api.pluginManager.ListAvailableConnectors()
 }
 
+// rotationAPI implements RotationAPI interface
+type rotationAPI struct {
+	serverID uuid.UUID
+	ctx      context.Context
+	provider RotationProvider
+}
+
+func NewRotationAPI(ctx context.Context, serverID uuid.UUID, provider RotationProvider) RotationAPI {
+	return &rotationAPI{
+		serverID: serverID,
+		ctx:      ctx,
+		provider: provider,
+	}
+}
+
+func (api *rotationAPI) GetVoteCandidates(count int) ([]models.RotationLayer, error) {
+	if api.provider == nil {
+		return nil, fmt.Errorf("map rotation is not available")
+	}
+	return api.provider.GetVoteCandidates(api.ctx, api.serverID, count)
+}
+
+func (api *rotationAPI) SetNextLayer(layer models.RotationLayer, source string) error {
+	if api.provider == nil {
+		return fmt.Errorf("map rotation is not available")
+	}
+	return api.provider.SetNextLayer(api.ctx, api.serverID, layer, source)
+}
+
 // logAPI implements LogAPI interface
 type logAPI struct {
 	serverID         uuid.UUID
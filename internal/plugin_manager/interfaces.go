@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
 	"go.codycody31.dev/squad-aegis/internal/shared/plug_config_schema"
 )
 
@@ -249,6 +250,9 @@ type PluginAPIs struct {
 	// Connector access
 	ConnectorAPI ConnectorAPI
 
+	// Map rotation access
+	RotationAPI RotationAPI
+
 	// Logging
 	LogAPI LogAPI
 }
@@ -390,6 +394,21 @@ type ConnectorAPI interface {
 	ListConnectors() []string
 }
 
+// RotationAPI provides access to the map rotation of the server
+type RotationAPI interface {
+	// GetVoteCandidates picks up to count layers from the rotation pool to offer in a vote
+	GetVoteCandidates(count int) ([]models.RotationLayer, error)
+
+	// SetNextLayer sets the next layer, recording source in the layer history
+	SetNextLayer(layer models.RotationLayer, source string) error
+}
+
+// RotationProvider is implemented by the rotation manager backing RotationAPI
+type RotationProvider interface {
+	GetVoteCandidates(ctx context.Context, serverID uuid.UUID, count int) ([]models.RotationLayer, error)
+	SetNextLayer(ctx context.Context, serverID uuid.UUID, layer models.RotationLayer, source string) error
+}
+
 // LogAPI provides logging functionality to plugins
 type LogAPI interface {
 	// Info logs an info message
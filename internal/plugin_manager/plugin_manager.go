@@ -38,6 +38,9 @@ type PluginManager struct {
 
 	// Event subscription
 	eventSubscriber *event_manager.EventSubscriber
+
+	// Map rotation, set after creation since the rotation manager is optional
+	rotationProvider RotationProvider
 }
 
 // NewPluginManager creates a new plugin manager
@@ -63,6 +66,12 @@ func NewPluginManager(ctx context.Context, db *sql.DB, eventManager *event_manag
 	return pm
 }
 
+// SetRotationProvider sets the rotation manager exposed to plugins through
+// RotationAPI. It must be called before Start.
+func (pm *PluginManager) SetRotationProvider(provider RotationProvider) {
+	pm.rotationProvider = provider
+}
+
 // Start starts the plugin manager
 func (pm *PluginManager) Start() error {
 	log.Info().Msg("Starting plugin manager")
@@ -788,10 +797,11 @@ func (pm *PluginManager) createPluginAPIs(serverID, instanceID uuid.UUID, plugin
 	return &PluginAPIs{
 		ServerAPI:    NewServerAPI(serverID, pm.db, pm.rconManager),
 		DatabaseAPI:  NewDatabaseAPI(instanceID, pm.db),
-		RconAPI:      NewRconAPI(serverID, pm.db, pm.rconManager, pm.clickhouseClient),
+		RconAPI:      NewRconAPI(serverID, instanceID, pm.db, pm.rconManager, pm.clickhouseClient),
 		AdminAPI:     NewAdminAPI(serverID, pm.db, pm.rconManager, instanceID),
 		EventAPI:     NewEventAPI(serverID, instanceID, pluginName, pm.eventManager),
 		ConnectorAPI: NewConnectorAPI(pm),
+		RotationAPI:  NewRotationAPI(pm.ctx, serverID, pm.rotationProvider),
 		LogAPI:       NewLogAPI(serverID, instanceID, pluginName, pluginID, logLevel, pm.clickhouseClient, pm.db, pm.eventManager),
 	}
 }
@@ -24,6 +24,7 @@ import (
 	"go.codycody31.dev/squad-aegis/internal/plugins/fog_of_war"
 	"go.codycody31.dev/squad-aegis/internal/plugins/intervalled_broadcasts"
 	"go.codycody31.dev/squad-aegis/internal/plugins/kill_broadcast"
+	"go.codycody31.dev/squad-aegis/internal/plugins/layer_vote"
 	"go.codycody31.dev/squad-aegis/internal/plugins/rule_lookup"
 	"go.codycody31.dev/squad-aegis/internal/plugins/seeding_mode"
 	"go.codycody31.dev/squad-aegis/internal/plugins/server_seeder_whitelist"
@@ -194,6 +195,12 @@ func RegisterAllPlugins(pm *plugin_manager.PluginManager) error {
 		return err
 	}
 
+	// Register Layer Vote plugin
+	if err := pm.RegisterPlugin(layer_vote.Define()); err != nil {
+		log.Error().Err(err).Msg("Failed to register Layer Vote plugin")
+		return err
+	}
+
 	// Register Kill Broadcast plugin
 	if err := pm.RegisterPlugin(discord_teamkill.Define()); err != nil {
 		log.Error().Err(err).Msg("Failed to register Discord Teamkill plugin")
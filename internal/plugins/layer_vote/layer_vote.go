@@ -0,0 +1,452 @@
+package layer_vote
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/plugin_manager"
+	"go.codycody31.dev/squad-aegis/internal/shared/plug_config_schema"
+)
+
+// LayerVotePlugin lets players vote on the next layer from the server's rotation pool
+type LayerVotePlugin struct {
+	// Plugin configuration
+	config map[string]interface{}
+	apis   *plugin_manager.PluginAPIs
+
+	// State management
+	mu     sync.Mutex
+	status plugin_manager.PluginStatus
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Vote state
+	openTimer  *time.Timer
+	closeTimer *time.Timer
+	candidates []models.RotationLayer
+	votes      map[string]int // steamID -> candidate index
+}
+
+// Define returns the plugin definition
+func Define() plugin_manager.PluginDefinition {
+	return plugin_manager.PluginDefinition{
+		ID:                     "layer_vote",
+		Name:                   "Layer Vote",
+		Description:            "Opens a vote on the next layer near the end of each match. Candidates are picked from the server's map rotation pool and the winner is set with AdminSetNextLayer.",
+		Version:                "1.0.0",
+		Author:                 "Squad Aegis",
+		AllowMultipleInstances: false,
+		RequiredConnectors:     []string{},
+		LongRunning:            true,
+
+		ConfigSchema: plug_config_schema.ConfigSchema{
+			Fields: []plug_config_schema.ConfigField{
+				{
+					Name:        "command",
+					Description: "The command used to vote, e.g. !vote 2.",
+					Required:    false,
+					Type:        plug_config_schema.FieldTypeString,
+					Default:     "vote",
+				},
+				{
+					Name:        "vote_start_minutes",
+					Description: "Minutes after a match starts before the vote opens. Set this close to the usual match length.",
+					Required:    false,
+					Type:        plug_config_schema.FieldTypeInt,
+					Default:     50,
+				},
+				{
+					Name:        "vote_duration_seconds",
+					Description: "How long the vote stays open. It also closes when the round ends.",
+					Required:    false,
+					Type:        plug_config_schema.FieldTypeInt,
+					Default:     180,
+				},
+				{
+					Name:        "candidate_count",
+					Description: "Number of layers offered in a vote.",
+					Required:    false,
+					Type:        plug_config_schema.FieldTypeInt,
+					Default:     3,
+				},
+				{
+					Name:        "min_players",
+					Description: "Minimum number of players online for a vote to open.",
+					Required:    false,
+					Type:        plug_config_schema.FieldTypeInt,
+					Default:     10,
+				},
+			},
+		},
+
+		Events: []event_manager.EventType{
+			event_manager.EventTypeRconChatMessage,
+			event_manager.EventTypeLogGameEventUnified,
+		},
+
+		CreateInstance: func() plugin_manager.Plugin {
+			return &LayerVotePlugin{}
+		},
+	}
+}
+
+// GetDefinition returns the plugin definition
+func (p *LayerVotePlugin) GetDefinition() plugin_manager.PluginDefinition {
+	return Define()
+}
+
+func (p *LayerVotePlugin) GetCommands() []plugin_manager.PluginCommand {
+	return []plugin_manager.PluginCommand{}
+}
+
+func (p *LayerVotePlugin) ExecuteCommand(commandID string, params map[string]interface{}) (*plugin_manager.CommandResult, error) {
+	return nil, fmt.Errorf("no commands available")
+}
+
+func (p *LayerVotePlugin) GetCommandExecutionStatus(executionID string) (*plugin_manager.CommandExecutionStatus, error) {
+	return nil, fmt.Errorf("no commands available")
+}
+
+// Initialize initializes the plugin with its configuration and dependencies
+func (p *LayerVotePlugin) Initialize(config map[string]interface{}, apis *plugin_manager.PluginAPIs) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.config = config
+	p.apis = apis
+
+	return nil
+}
+
+// Start begins plugin execution
+func (p *LayerVotePlugin) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.status == plugin_manager.PluginStatusRunning {
+		return nil // Already running
+	}
+
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	p.status = plugin_manager.PluginStatusRunning
+
+	// Aegis may start mid-match; schedule a vote as if the match just started
+	p.scheduleVoteLocked()
+
+	return nil
+}
+
+// Stop gracefully stops the plugin
+func (p *LayerVotePlugin) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.status == plugin_manager.PluginStatusStopped {
+		return nil // Already stopped
+	}
+
+	p.status = plugin_manager.PluginStatusStopping
+	p.resetVoteLocked()
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.status = plugin_manager.PluginStatusStopped
+
+	return nil
+}
+
+// GetStatus returns the current plugin status
+func (p *LayerVotePlugin) GetStatus() plugin_manager.PluginStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// GetConfig returns the current plugin configuration
+func (p *LayerVotePlugin) GetConfig() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.config
+}
+
+// UpdateConfig updates the plugin configuration
+func (p *LayerVotePlugin) UpdateConfig(config map[string]interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	definition := p.GetDefinition()
+	if err := definition.ConfigSchema.Validate(config); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	definition.ConfigSchema.FillDefaults(config)
+
+	p.config = config
+
+	p.apis.LogAPI.Info("Layer Vote plugin configuration updated", map[string]interface{}{
+		"command":               p.getStringConfig("command"),
+		"vote_start_minutes":    p.getIntConfig("vote_start_minutes"),
+		"vote_duration_seconds": p.getIntConfig("vote_duration_seconds"),
+		"candidate_count":       p.getIntConfig("candidate_count"),
+		"min_players":           p.getIntConfig("min_players"),
+	})
+
+	return nil
+}
+
+// HandleEvent processes an event if the plugin is subscribed to it
+func (p *LayerVotePlugin) HandleEvent(event *plugin_manager.PluginEvent) error {
+	switch event.Type {
+	case string(event_manager.EventTypeRconChatMessage):
+		data, ok := event.Data.(*event_manager.RconChatMessageData)
+		if !ok {
+			return fmt.Errorf("invalid event data type")
+		}
+		return p.handleChatMessage(data)
+	case string(event_manager.EventTypeLogGameEventUnified):
+		data, ok := event.Data.(*event_manager.LogGameEventUnifiedData)
+		if !ok {
+			return fmt.Errorf("invalid event data type")
+		}
+		return p.handleGameEvent(data)
+	}
+
+	return nil
+}
+
+// handleGameEvent schedules a vote when a match starts and closes an open
+// vote when the round ends
+func (p *LayerVotePlugin) handleGameEvent(event *event_manager.LogGameEventUnifiedData) error {
+	switch event.EventType {
+	case "NEW_GAME":
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.resetVoteLocked()
+		p.scheduleVoteLocked()
+	case "ROUND_ENDED":
+		p.closeVote()
+	}
+
+	return nil
+}
+
+// handleChatMessage processes vote commands
+func (p *LayerVotePlugin) handleChatMessage(event *event_manager.RconChatMessageData) error {
+	fields := strings.Fields(strings.TrimSpace(event.Message))
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "!"+p.getStringConfig("command")) {
+		return nil // Not our command
+	}
+
+	if len(fields) > 1 && strings.EqualFold(fields[1], "start") {
+		isAdmin, err := p.isPlayerAdmin(event.SteamID)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return p.apis.RconAPI.SendWarningToPlayer(event.SteamID, "You must be an admin to start a layer vote.")
+		}
+		return p.openVote(true)
+	}
+
+	p.mu.Lock()
+	candidates := p.candidates
+	p.mu.Unlock()
+
+	if len(candidates) == 0 {
+		return p.apis.RconAPI.SendWarningToPlayer(event.SteamID, "There is no layer vote running.")
+	}
+
+	if len(fields) == 1 {
+		return p.apis.RconAPI.SendWarningToPlayer(event.SteamID, p.formatCandidates(candidates))
+	}
+
+	choice, err := strconv.Atoi(fields[1])
+	if err != nil || choice < 1 || choice > len(candidates) {
+		return p.apis.RconAPI.SendWarningToPlayer(event.SteamID,
+			fmt.Sprintf("Vote with !%s <1-%d>. %s", p.getStringConfig("command"), len(candidates), p.formatCandidates(candidates)))
+	}
+
+	p.mu.Lock()
+	if p.votes == nil {
+		p.mu.Unlock()
+		return nil // Vote closed meanwhile
+	}
+	p.votes[event.SteamID] = choice - 1
+	p.mu.Unlock()
+
+	return p.apis.RconAPI.SendWarningToPlayer(event.SteamID, fmt.Sprintf("Your vote for %s has been recorded.", candidates[choice-1].Layer))
+}
+
+// scheduleVoteLocked starts the timer opening the next vote. Callers must hold p.mu.
+func (p *LayerVotePlugin) scheduleVoteLocked() {
+	if p.status != plugin_manager.PluginStatusRunning {
+		return
+	}
+
+	delay := time.Duration(p.getIntConfig("vote_start_minutes")) * time.Minute
+	p.openTimer = time.AfterFunc(delay, func() {
+		if err := p.openVote(false); err != nil {
+			p.apis.LogAPI.Error("Failed to open layer vote", err, nil)
+		}
+	})
+}
+
+// openVote picks candidates from the rotation and announces the vote. Admins
+// can force a vote regardless of the player count.
+func (p *LayerVotePlugin) openVote(force bool) error {
+	p.mu.Lock()
+	if p.status != plugin_manager.PluginStatusRunning || p.votes != nil {
+		p.mu.Unlock()
+		return nil // Stopped or a vote is already running
+	}
+	p.mu.Unlock()
+
+	if !force {
+		players, err := p.apis.ServerAPI.GetPlayers()
+		if err != nil {
+			return fmt.Errorf("failed to get player list: %w", err)
+		}
+		if len(players) < p.getIntConfig("min_players") {
+			p.apis.LogAPI.Debug("Not enough players for a layer vote", map[string]interface{}{"players": len(players)})
+			return nil
+		}
+	}
+
+	candidates, err := p.apis.RotationAPI.GetVoteCandidates(p.getIntConfig("candidate_count"))
+	if err != nil {
+		return fmt.Errorf("failed to get vote candidates: %w", err)
+	}
+	if len(candidates) < 2 {
+		p.apis.LogAPI.Warn("Not enough eligible layers in the rotation for a vote", map[string]interface{}{"candidates": len(candidates)})
+		return nil
+	}
+
+	duration := time.Duration(p.getIntConfig("vote_duration_seconds")) * time.Second
+
+	p.mu.Lock()
+	if p.status != plugin_manager.PluginStatusRunning || p.votes != nil {
+		p.mu.Unlock()
+		return nil
+	}
+	p.candidates = candidates
+	p.votes = make(map[string]int)
+	p.closeTimer = time.AfterFunc(duration, p.closeVote)
+	p.mu.Unlock()
+
+	p.apis.LogAPI.Info("Layer vote opened", map[string]interface{}{"candidates": len(candidates)})
+
+	return p.apis.RconAPI.Broadcast(fmt.Sprintf("Vote for the next layer with !%s <number> (%s): %s",
+		p.getStringConfig("command"), duration, p.formatCandidates(candidates)))
+}
+
+// closeVote tallies an open vote and sets the winning layer
+func (p *LayerVotePlugin) closeVote() {
+	p.mu.Lock()
+	candidates, votes := p.candidates, p.votes
+	p.resetVoteLocked()
+	p.mu.Unlock()
+
+	if votes == nil {
+		return // No vote running
+	}
+
+	winner, count := tally(len(candidates), votes)
+	if count == 0 {
+		p.apis.RconAPI.Broadcast("The layer vote ended without votes.")
+		return
+	}
+
+	layer := candidates[winner]
+	if err := p.apis.RotationAPI.SetNextLayer(layer, "vote"); err != nil {
+		p.apis.LogAPI.Error("Failed to set voted layer", err, map[string]interface{}{"layer": layer.Layer})
+		return
+	}
+
+	p.apis.LogAPI.Info("Layer vote closed", map[string]interface{}{
+		"layer": layer.Layer,
+		"votes": count,
+		"total": len(votes),
+	})
+
+	p.apis.RconAPI.Broadcast(fmt.Sprintf("%s won the layer vote with %d of %d votes.", layer.Layer, count, len(votes)))
+}
+
+// resetVoteLocked stops the vote timers and discards votes. Callers must hold p.mu.
+func (p *LayerVotePlugin) resetVoteLocked() {
+	if p.openTimer != nil {
+		p.openTimer.Stop()
+		p.openTimer = nil
+	}
+	if p.closeTimer != nil {
+		p.closeTimer.Stop()
+		p.closeTimer = nil
+	}
+	p.candidates = nil
+	p.votes = nil
+}
+
+// tally returns the candidate with the most votes and its vote count. Ties go
+// to the candidate listed first.
+func tally(candidateCount int, votes map[string]int) (int, int) {
+	counts := make([]int, candidateCount)
+	for _, choice := range votes {
+		counts[choice]++
+	}
+
+	winner := 0
+	for i, count := range counts {
+		if count > counts[winner] {
+			winner = i
+		}
+	}
+
+	return winner, counts[winner]
+}
+
+func (p *LayerVotePlugin) formatCandidates(candidates []models.RotationLayer) string {
+	options := make([]string, 0, len(candidates))
+	for i, candidate := range candidates {
+		options = append(options, fmt.Sprintf("%d) %s", i+1, candidate.Layer))
+	}
+	return strings.Join(options, " ")
+}
+
+// isPlayerAdmin checks if a player is an admin
+func (p *LayerVotePlugin) isPlayerAdmin(steamID string) (bool, error) {
+	admins, err := p.apis.ServerAPI.GetAdmins()
+	if err != nil {
+		return false, fmt.Errorf("failed to get admin list: %w", err)
+	}
+
+	for _, admin := range admins {
+		if admin.SteamID == steamID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Helper methods for config access
+
+func (p *LayerVotePlugin) getStringConfig(key string) string {
+	if val, ok := p.config[key].(string); ok {
+		return val
+	}
+	return ""
+}
+
+func (p *LayerVotePlugin) getIntConfig(key string) int {
+	if val, ok := p.config[key].(int); ok {
+		return val
+	}
+	if val, ok := p.config[key].(float64); ok {
+		return int(val)
+	}
+	return 0
+}
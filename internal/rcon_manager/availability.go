@@ -0,0 +1,79 @@
+package rcon_manager
+
+import (
+	"time"
+)
+
+// ConnectionState describes how reachable a server's RCON is
+type ConnectionState string
+
+const (
+	ConnectionStateConnected    ConnectionState = "connected"
+	ConnectionStateDegraded     ConnectionState = "degraded"     // Reachable, but probes are failing or slow
+	ConnectionStateDisconnected ConnectionState = "disconnected" // Unreachable, the circuit is open
+)
+
+// StateChange is a recorded transition of a connection's state
+type StateChange struct {
+	Time  time.Time       `json:"time"`
+	State ConnectionState `json:"state"`
+}
+
+// AvailabilitySummary sums up how long a connection spent in each state
+type AvailabilitySummary struct {
+	ConnectedSeconds    float64 `json:"connected_seconds"`
+	DegradedSeconds     float64 `json:"degraded_seconds"`
+	DisconnectedSeconds float64 `json:"disconnected_seconds"`
+	UptimePercent       float64 `json:"uptime_percent"` // Connected or degraded, out of the time with a known state
+	Outages             int     `json:"outages"`        // Transitions into disconnected
+}
+
+// Availability sums up the state changes of a connection between start and
+// end. initial is the state in effect at start, or empty when it is unknown;
+// time without a known state is left out of the uptime. changes must be
+// sorted by time.
+func Availability(initial ConnectionState, changes []StateChange, start, end time.Time) AvailabilitySummary {
+	summary := AvailabilitySummary{}
+
+	state := initial
+	from := start
+	add := func(until time.Time) {
+		if !until.After(from) {
+			return
+		}
+		seconds := until.Sub(from).Seconds()
+		switch state {
+		case ConnectionStateConnected:
+			summary.ConnectedSeconds += seconds
+		case ConnectionStateDegraded:
+			summary.DegradedSeconds += seconds
+		case ConnectionStateDisconnected:
+			summary.DisconnectedSeconds += seconds
+		}
+	}
+
+	for _, change := range changes {
+		if !change.Time.After(start) {
+			state = change.State
+			continue
+		}
+		if !change.Time.Before(end) {
+			break
+		}
+
+		add(change.Time)
+		if change.State == ConnectionStateDisconnected && state != ConnectionStateDisconnected {
+			summary.Outages++
+		}
+		state = change.State
+		from = change.Time
+	}
+	add(end)
+
+	known := summary.ConnectedSeconds + summary.DegradedSeconds + summary.DisconnectedSeconds
+	if known > 0 {
+		summary.UptimePercent = (summary.ConnectedSeconds + summary.DegradedSeconds) / known * 100
+	}
+
+	return summary
+}
@@ -0,0 +1,63 @@
+package rcon_manager
+
+import (
+	"testing"
+	"time"
+)
+
+func at(minutes int) time.Time {
+	return now.Add(time.Duration(minutes) * time.Minute)
+}
+
+func TestAvailability(t *testing.T) {
+	changes := []StateChange{
+		{Time: at(-30), State: ConnectionStateConnected},
+		{Time: at(10), State: ConnectionStateDegraded},
+		{Time: at(20), State: ConnectionStateDisconnected},
+		{Time: at(30), State: ConnectionStateConnected},
+		{Time: at(90), State: ConnectionStateDisconnected},
+	}
+
+	summary := Availability("", changes, at(0), at(60))
+
+	if summary.ConnectedSeconds != 40*60 {
+		t.Errorf("expected 40 connected minutes, got %v seconds", summary.ConnectedSeconds)
+	}
+	if summary.DegradedSeconds != 10*60 {
+		t.Errorf("expected 10 degraded minutes, got %v seconds", summary.DegradedSeconds)
+	}
+	if summary.DisconnectedSeconds != 10*60 {
+		t.Errorf("expected 10 disconnected minutes, got %v seconds", summary.DisconnectedSeconds)
+	}
+	if summary.Outages != 1 {
+		t.Errorf("expected 1 outage, got %d", summary.Outages)
+	}
+	if got := int(summary.UptimePercent*100) / 100; got != 83 {
+		t.Errorf("expected an uptime of 83%%, got %v", summary.UptimePercent)
+	}
+}
+
+func TestAvailabilityUnknownState(t *testing.T) {
+	changes := []StateChange{
+		{Time: at(30), State: ConnectionStateConnected},
+	}
+
+	summary := Availability("", changes, at(0), at(60))
+	if summary.ConnectedSeconds != 30*60 || summary.UptimePercent != 100 {
+		t.Errorf("expected the time before the first state to be left out, got %+v", summary)
+	}
+
+	if summary := Availability("", nil, at(0), at(60)); summary.UptimePercent != 0 {
+		t.Errorf("expected no uptime without a known state, got %+v", summary)
+	}
+}
+
+func TestAvailabilityInitialState(t *testing.T) {
+	summary := Availability(ConnectionStateDisconnected, nil, at(0), at(60))
+	if summary.DisconnectedSeconds != 60*60 || summary.UptimePercent != 0 {
+		t.Errorf("expected the initial state to cover the range, got %+v", summary)
+	}
+	if summary.Outages != 0 {
+		t.Errorf("expected an outage that started before the range not to be counted, got %d", summary.Outages)
+	}
+}
@@ -0,0 +1,112 @@
+package rcon_manager
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+const (
+	CircuitFailureThreshold = 3               // Consecutive failures before a connection is given up on
+	ReconnectBaseDelay      = 5 * time.Second // Backoff before the first reconnect attempt
+	ReconnectMaxDelay       = 2 * time.Minute // Upper bound of the reconnect backoff
+	ReconnectJitter         = 0.2             // Backoff is randomised by up to ±20%
+	reconnectMaxDoublings   = 16              // Keeps the backoff shift from overflowing
+)
+
+// ErrCircuitOpen is returned while a server's RCON is unreachable, so callers
+// fail fast instead of queueing commands that cannot be delivered
+var ErrCircuitOpen = errors.New("rcon unavailable: server is unreachable, reconnecting")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker counts the consecutive failures of a connection. Once the
+// threshold is reached it opens and rejects commands until a jittered,
+// exponentially growing backoff expires, then lets a single reconnect attempt
+// through. It is not safe for concurrent use; ServerConnection.mu guards it.
+type circuitBreaker struct {
+	threshold int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	random    func() float64 // Returns a number in [0, 1), swapped out in tests
+
+	state    circuitState
+	failures int       // Consecutive failures
+	attempts int       // Failed reconnect attempts since the breaker opened
+	openedAt time.Time // When the breaker last opened from closed
+	retryAt  time.Time // When the next reconnect attempt is due
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		threshold: CircuitFailureThreshold,
+		baseDelay: ReconnectBaseDelay,
+		maxDelay:  ReconnectMaxDelay,
+		random:    rand.Float64,
+	}
+}
+
+// allow reports whether commands may be sent over the connection
+func (b *circuitBreaker) allow() bool {
+	return b.state == circuitClosed
+}
+
+// readyToRetry reports whether the backoff of an open breaker has expired and
+// moves it to half-open, so only one reconnect attempt is made at a time
+func (b *circuitBreaker) readyToRetry(now time.Time) bool {
+	if b.state != circuitOpen || now.Before(b.retryAt) {
+		return false
+	}
+
+	b.state = circuitHalfOpen
+	return true
+}
+
+// success closes the breaker and forgets previous failures
+func (b *circuitBreaker) success() {
+	b.state = circuitClosed
+	b.failures = 0
+	b.attempts = 0
+}
+
+// failure records a failure and reports whether it opened a closed breaker.
+// A failed reconnect attempt reopens the breaker with a longer backoff.
+func (b *circuitBreaker) failure(now time.Time) bool {
+	b.failures++
+
+	switch b.state {
+	case circuitClosed:
+		if b.failures < b.threshold {
+			return false
+		}
+		b.state = circuitOpen
+		b.openedAt = now
+		b.attempts = 0
+		b.retryAt = now.Add(b.backoff())
+		return true
+	case circuitHalfOpen:
+		b.attempts++
+		b.state = circuitOpen
+		b.retryAt = now.Add(b.backoff())
+	}
+
+	return false
+}
+
+// backoff doubles the base delay for each failed reconnect attempt, caps it
+// at the maximum delay and applies the jitter
+func (b *circuitBreaker) backoff() time.Duration {
+	delay := b.baseDelay << min(b.attempts, reconnectMaxDoublings)
+	if delay <= 0 || delay > b.maxDelay {
+		delay = b.maxDelay
+	}
+
+	factor := 1 + ReconnectJitter*(2*b.random()-1)
+	return time.Duration(float64(delay) * factor)
+}
@@ -0,0 +1,96 @@
+package rcon_manager
+
+import (
+	"testing"
+	"time"
+)
+
+var now = time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+func testBreaker(random float64) *circuitBreaker {
+	breaker := newCircuitBreaker()
+	breaker.random = func() float64 { return random }
+	return breaker
+}
+
+func TestCircuitBreakerOpensAtThreshold(t *testing.T) {
+	breaker := testBreaker(0.5)
+
+	for i := 1; i < CircuitFailureThreshold; i++ {
+		if breaker.failure(now) {
+			t.Fatalf("expected the breaker to stay closed after %d failures", i)
+		}
+		if !breaker.allow() {
+			t.Fatalf("expected commands to be allowed after %d failures", i)
+		}
+	}
+
+	if !breaker.failure(now) {
+		t.Fatal("expected the breaker to open at the threshold")
+	}
+	if breaker.allow() {
+		t.Error("expected commands to be rejected while open")
+	}
+	if breaker.failure(now) {
+		t.Error("expected an open breaker not to report opening again")
+	}
+}
+
+func TestCircuitBreakerSuccessResets(t *testing.T) {
+	breaker := testBreaker(0.5)
+
+	breaker.failure(now)
+	breaker.failure(now)
+	breaker.success()
+
+	if breaker.failure(now) {
+		t.Error("expected a success to reset the failure count")
+	}
+}
+
+func TestCircuitBreakerReconnectBackoff(t *testing.T) {
+	breaker := testBreaker(0.5)
+	for i := 0; i < CircuitFailureThreshold; i++ {
+		breaker.failure(now)
+	}
+
+	if !breaker.retryAt.Equal(now.Add(ReconnectBaseDelay)) {
+		t.Fatalf("expected the first retry after %s, got %s", ReconnectBaseDelay, breaker.retryAt.Sub(now))
+	}
+	if breaker.readyToRetry(now.Add(ReconnectBaseDelay - time.Second)) {
+		t.Fatal("expected no retry before the backoff expires")
+	}
+
+	for attempt, expected := range []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, ReconnectMaxDelay, ReconnectMaxDelay} {
+		retry := breaker.retryAt
+		if !breaker.readyToRetry(retry) {
+			t.Fatalf("attempt %d: expected a retry once the backoff expires", attempt+1)
+		}
+		if breaker.readyToRetry(retry) {
+			t.Fatalf("attempt %d: expected a single retry while half-open", attempt+1)
+		}
+
+		breaker.failure(retry)
+		if got := breaker.retryAt.Sub(retry); got != expected {
+			t.Errorf("attempt %d: expected a backoff of %s, got %s", attempt+1, expected, got)
+		}
+	}
+
+	breaker.readyToRetry(breaker.retryAt)
+	breaker.success()
+	if !breaker.allow() {
+		t.Error("expected a successful reconnect to close the breaker")
+	}
+}
+
+func TestCircuitBreakerJitter(t *testing.T) {
+	low := testBreaker(0).backoff()
+	high := testBreaker(0.999999).backoff()
+
+	if low != time.Duration(float64(ReconnectBaseDelay)*(1-ReconnectJitter)) {
+		t.Errorf("unexpected lower bound %s", low)
+	}
+	if high <= ReconnectBaseDelay || high > time.Duration(float64(ReconnectBaseDelay)*(1+ReconnectJitter)) {
+		t.Errorf("unexpected upper bound %s", high)
+	}
+}
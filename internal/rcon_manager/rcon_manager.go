@@ -20,9 +20,7 @@ import (
 
 const (
 	DefaultCommandTimeout = 30 * time.Second
-	HealthCheckInterval   = 120 * time.Second
 	ServerInfoInterval    = 60 * time.Second // Collect server info every 60 seconds
-	MaxReconnectAttempts  = 3
 	CommandQueueSize      = 1000
 	EventChannelSize      = 1000
 	MaxConcurrentCommands = 1
@@ -42,16 +40,6 @@ type RconEvent struct {
 	Time     time.Time
 }
 
-// RconCommand represents a command to be executed on the RCON server
-type RconCommand struct {
-	Command  string
-	Response chan CommandResponse
-	Priority int             // Higher values = higher priority
-	Timeout  time.Duration   // Per-command timeout override
-	Retries  int             // Number of retries for this command
-	ctx      context.Context // Command-specific context
-}
-
 // CommandResponse represents the response from an RCON command
 type CommandResponse struct {
 	Response string
@@ -61,16 +49,24 @@ type CommandResponse struct {
 // ServerConnection represents a connection to an RCON server
 type ServerConnection struct {
 	ServerID           uuid.UUID
-	Rcon               *rcon.Rcon // Single connection for both commands and events
-	CommandChan        chan RconCommand
+	Rcon               *rcon.Rcon // Single connection for both commands and events, nil while disconnected
 	EventChan          chan RconEvent
 	LastUsed           time.Time
 	LastHealthCheck    time.Time
 	LastServerInfoTime time.Time
 	mu                 sync.Mutex
+	scheduler          *commandScheduler
 	cmdSemaphore       chan struct{}
-	isHealthy          bool
 	reconnectCount     int
+	config             rcon.RconConfig // Used by the supervisor to reconnect
+	ctx                context.Context // Cancelled when the connection is removed
+	cancel             context.CancelFunc
+	wake               chan struct{}
+	breaker            *circuitBreaker
+	state              ConnectionState
+	stateSince         time.Time
+	latency            time.Duration // Latency of the last successful probe
+	lastError          string
 }
 
 // RconManager manages RCON connections to multiple servers
@@ -256,51 +252,6 @@ func (m *RconManager) parseServerInfoResponse(response string) (playerCount, pub
 	return playerCount, publicQueue, reservedQueue, nil
 }
 
-// collectServerInfo collects server info and emits a server info event
-func (m *RconManager) collectServerInfo(serverID uuid.UUID, conn *ServerConnection) {
-	conn.mu.Lock()
-	conn.LastServerInfoTime = time.Now()
-	conn.mu.Unlock()
-
-	// Execute ShowServerInfo command
-	response, err := m.ExecuteCommandWithOptions(serverID, "ShowServerInfo", CommandOptions{
-		Priority: PriorityLow,
-		Timeout:  10 * time.Second,
-		Retries:  1,
-	})
-
-	if err != nil {
-		log.Error().
-			Str("serverID", serverID.String()).
-			Err(err).
-			Msg("Failed to collect server info")
-		return
-	}
-
-	// Parse the server info response using Squad's special format
-	playerCount, publicQueue, reservedQueue, err := m.parseServerInfoResponse(response)
-	if err != nil {
-		log.Error().
-			Str("serverID", serverID.String()).
-			Err(err).
-			Str("response", response).
-			Msg("Failed to parse server info response")
-		return
-	}
-
-	// Create and publish the server info event
-	serverInfoData := &event_manager.RconServerInfoData{
-		PlayerCount:     playerCount,
-		PublicQueue:     publicQueue,
-		ReservedQueue:   reservedQueue,
-		TotalQueueCount: publicQueue + reservedQueue,
-	}
-
-	if m.eventManager != nil {
-		m.eventManager.PublishEvent(serverID, serverInfoData, response)
-	}
-}
-
 // ConnectToServer connects to an RCON server
 func (m *RconManager) ConnectToServer(serverID uuid.UUID, host string, port int, password string) error {
 	m.mu.Lock()
@@ -315,18 +266,26 @@ func (m *RconManager) ConnectToServer(serverID uuid.UUID, host string, port int,
 
 		// Connection already exists, update last used time
 		conn.LastUsed = time.Now()
+		if !conn.breaker.allow() {
+			return ErrCircuitOpen
+		}
 		return nil
 	}
 
+	// Reconnects are left to the supervisor so they can back off
+	config := rcon.RconConfig{
+		Host:     host,
+		Port:     portStr,
+		Password: password,
+	}
+
+	connCtx, cancel := context.WithCancel(m.ctx)
+
 	// Create single RCON connection
-	rconConn, err := rcon.NewRconWithContext(m.ctx, rcon.RconConfig{
-		Host:               host,
-		Port:               portStr,
-		Password:           password,
-		AutoReconnect:      true,
-		AutoReconnectDelay: 5,
-	})
+	rconConn, err := rcon.NewRconWithContext(connCtx, config)
 	if err != nil {
+		cancel()
+
 		log.Error().
 			Str("serverID", serverID.String()).
 			Err(err).
@@ -340,20 +299,26 @@ func (m *RconManager) ConnectToServer(serverID uuid.UUID, host string, port int,
 	conn := &ServerConnection{
 		ServerID:           serverID,
 		Rcon:               rconConn,
-		CommandChan:        make(chan RconCommand, CommandQueueSize),
+		scheduler:          newCommandScheduler(),
 		EventChan:          make(chan RconEvent, EventChannelSize),
 		LastUsed:           time.Now(),
 		LastHealthCheck:    time.Now(),
 		LastServerInfoTime: time.Time{}, // Initialize to zero time so it triggers immediately
 		cmdSemaphore:       cmdSemaphore,
-		isHealthy:          true,
 		reconnectCount:     0,
+		config:             config,
+		ctx:                connCtx,
+		cancel:             cancel,
+		wake:               make(chan struct{}, 1),
+		breaker:            newCircuitBreaker(),
+		state:              ConnectionStateConnected,
+		stateSince:         time.Now(),
 	}
 
 	m.connections[serverID] = conn
 
-	// Start listening for events and processing commands
-	go m.listenForEvents(serverID, rconConn)
+	// Start listening for events, processing commands and supervising the connection
+	m.listenForEvents(serverID, rconConn)
 	go m.processCommands(serverID, conn)
 	go m.monitorConnection(serverID, conn)
 
@@ -361,37 +326,9 @@ func (m *RconManager) ConnectToServer(serverID uuid.UUID, host string, port int,
 		Str("serverID", serverID.String()).
 		Msg("Connected to RCON server")
 
-	return nil
-}
-
-// monitorConnection monitors a connection's health and handles reconnection
-func (m *RconManager) monitorConnection(serverID uuid.UUID, conn *ServerConnection) {
-	// Use a shorter interval to handle both health checks and server info collection
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			now := time.Now()
-
-			// Check if health check is needed
-			conn.mu.Lock()
-			needsServerInfo := now.Sub(conn.LastServerInfoTime) >= ServerInfoInterval
-			conn.mu.Unlock()
-
-			// Collect server info periodically
-			if needsServerInfo && conn.isHealthy {
-				go m.collectServerInfo(serverID, conn)
-			}
+	m.publishConnectionEvent(serverID, &event_manager.RconConnectedData{})
 
-		case <-m.ctx.Done():
-			log.Debug().
-				Str("serverID", serverID.String()).
-				Msg("Stopping connection monitor")
-			return
-		}
-	}
+	return nil
 }
 
 // DisconnectFromServer disconnects from an RCON server
@@ -411,8 +348,11 @@ func (m *RconManager) DisconnectFromServer(serverID uuid.UUID, force bool) error
 		defer conn.mu.Unlock()
 	}
 
-	// Close the connection
-	conn.Rcon.Close()
+	// Stop the supervisor and command processor, then close the connection
+	conn.cancel()
+	if conn.Rcon != nil {
+		conn.Rcon.Close()
+	}
 
 	// Remove the connection from the map
 	delete(m.connections, serverID)
@@ -421,6 +361,10 @@ func (m *RconManager) DisconnectFromServer(serverID uuid.UUID, force bool) error
 		Str("serverID", serverID.String()).
 		Msg("Disconnected from RCON server")
 
+	m.publishConnectionEvent(serverID, &event_manager.RconDisconnectedData{
+		Reason: "disconnected",
+	})
+
 	return nil
 }
 
@@ -439,6 +383,7 @@ type CommandOptions struct {
 	Timeout  time.Duration
 	Retries  int
 	Context  context.Context
+	Caller   string // Who sends the command, see PluginCaller, WorkflowCaller and UserCaller. Defaults to SystemCaller.
 }
 
 // ExecuteCommandWithOptions executes a command with specific options
@@ -458,6 +403,12 @@ func (m *RconManager) ExecuteCommandWithOptions(serverID uuid.UUID, command stri
 	if options.Context == nil {
 		options.Context = context.Background()
 	}
+	if options.Priority == 0 {
+		options.Priority = PriorityNormal
+	}
+	if options.Caller == "" {
+		options.Caller = SystemCaller
+	}
 
 	// Get connection with health check
 	m.mu.RLock()
@@ -468,27 +419,20 @@ func (m *RconManager) ExecuteCommandWithOptions(serverID uuid.UUID, command stri
 		return "", errors.New("server not connected")
 	}
 
-	// Update last used time efficiently
+	// Update last used time efficiently and fail fast while the server is unreachable
 	conn.mu.Lock()
 	conn.LastUsed = time.Now()
+	allowed := conn.breaker.allow()
 	conn.mu.Unlock()
 
+	if !allowed {
+		return "", ErrCircuitOpen
+	}
+
 	// Create command context with timeout
 	ctx, cancel := context.WithTimeout(options.Context, options.Timeout)
 	defer cancel()
 
-	responseChan := make(chan CommandResponse, 1)
-	defer close(responseChan)
-
-	rconCmd := RconCommand{
-		Command:  command,
-		Response: responseChan,
-		Priority: options.Priority,
-		Timeout:  options.Timeout,
-		Retries:  options.Retries,
-		ctx:      ctx,
-	}
-
 	// Attempt to send command with retries
 	var lastErr error
 	for attempt := 0; attempt < options.Retries; attempt++ {
@@ -500,41 +444,36 @@ func (m *RconManager) ExecuteCommandWithOptions(serverID uuid.UUID, command stri
 				Msg("Retrying command execution")
 		}
 
-		// Send command to processor
-		select {
-		case conn.CommandChan <- rconCmd:
-			// Command queued successfully
-		case <-ctx.Done():
-			lastErr = ctx.Err()
-			if lastErr == context.DeadlineExceeded {
-				lastErr = errors.New("command queue timeout")
-			}
-			continue
-		case <-m.ctx.Done():
-			return "", errors.New("rcon manager shutting down")
+		// Queue the command, each attempt waits for its own response
+		waiter := commandWaiter{
+			ctx:      ctx,
+			response: make(chan CommandResponse, 1),
+		}
+		if err := conn.scheduler.enqueue(command, options.Priority, options.Caller, waiter, time.Now()); err != nil {
+			lastErr = err
+			break
 		}
 
 		// Wait for response
 		select {
-		case response := <-responseChan:
-			if response.Error != nil {
-				lastErr = response.Error
-				// Don't retry on certain errors
-				if isNonRetryableError(response.Error) {
-					break
-				}
-				continue
+		case response := <-waiter.response:
+			if response.Error == nil {
+				return response.Response, nil
 			}
-			return response.Response, nil
+			lastErr = response.Error
 		case <-ctx.Done():
 			lastErr = ctx.Err()
 			if lastErr == context.DeadlineExceeded {
 				lastErr = errors.New("command execution timeout")
 			}
-			continue
 		case <-m.ctx.Done():
 			return "", errors.New("rcon manager shutting down")
 		}
+
+		// Don't retry on certain errors
+		if isNonRetryableError(lastErr) || ctx.Err() != nil {
+			break
+		}
 	}
 
 	log.Error().
@@ -560,6 +499,8 @@ func isNonRetryableError(err error) bool {
 		"authentication failed",
 		"server not connected",
 		"rcon manager shutting down",
+		"rcon unavailable",
+		"rcon command queue is full",
 	}
 
 	for _, nonRetryable := range nonRetryableErrors {
@@ -587,44 +528,62 @@ func (m *RconManager) processCommands(serverID uuid.UUID, conn *ServerConnection
 	}()
 
 	for {
-		select {
-		case cmd := <-conn.CommandChan:
+		cmd, wait := conn.scheduler.next(time.Now())
+		if cmd != nil {
 			m.processCommand(serverID, conn, cmd)
+			continue
+		}
 
-		case <-m.ctx.Done():
+		// Wait for a new command, or for a rate limited caller to get a token
+		var timer *time.Timer
+		var retry <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			retry = timer.C
+		}
+
+		select {
+		case <-conn.scheduler.ready:
+		case <-retry:
+		case <-conn.ctx.Done():
 			log.Debug().
 				Str("serverID", serverID.String()).
 				Msg("Stopping command processor due to context cancellation")
 			return
 		}
+
+		if timer != nil {
+			timer.Stop()
+		}
 	}
 }
 
 // processCommand processes a single command with proper resource management
-func (m *RconManager) processCommand(serverID uuid.UUID, conn *ServerConnection, cmd RconCommand) {
+func (m *RconManager) processCommand(serverID uuid.UUID, conn *ServerConnection, cmd *queuedCommand) {
+	// The command runs until the last of its waiters gives up
+	ctx, cancel := context.WithCancel(conn.ctx)
+	if deadline, ok := cmd.deadline(); ok {
+		ctx, cancel = context.WithDeadline(conn.ctx, deadline)
+	}
+	defer cancel()
+
 	// Acquire the semaphore to limit concurrent commands
 	select {
 	case conn.cmdSemaphore <- struct{}{}:
 		// Acquired semaphore
-	case <-cmd.ctx.Done():
+	case <-ctx.Done():
 		// Command context cancelled before acquiring semaphore
-		select {
-		case cmd.Response <- CommandResponse{
+		cmd.respond(CommandResponse{
 			Response: "",
-			Error:    cmd.ctx.Err(),
-		}:
-		default:
-		}
+			Error:    ctx.Err(),
+		})
 		return
 	case <-m.ctx.Done():
 		// Manager shutting down
-		select {
-		case cmd.Response <- CommandResponse{
+		cmd.respond(CommandResponse{
 			Response: "",
 			Error:    errors.New("rcon manager shutting down"),
-		}:
-		default:
-		}
+		})
 		return
 	}
 
@@ -648,7 +607,7 @@ func (m *RconManager) processCommand(serverID uuid.UUID, conn *ServerConnection,
 			if r := recover(); r != nil {
 				log.Error().
 					Str("serverID", serverID.String()).
-					Str("command", cmd.Command).
+					Str("command", cmd.command).
 					Interface("panic", r).
 					Msg("Command execution panic recovered")
 
@@ -662,12 +621,13 @@ func (m *RconManager) processCommand(serverID uuid.UUID, conn *ServerConnection,
 			}
 		}()
 
-		// Check if connection is still healthy before executing
-		if !conn.isHealthy {
+		// The client is dropped while the circuit is open
+		client := conn.client()
+		if client == nil {
 			select {
 			case responseChan <- CommandResponse{
 				Response: "",
-				Error:    errors.New("connection is unhealthy"),
+				Error:    ErrCircuitOpen,
 			}:
 			default:
 			}
@@ -675,25 +635,25 @@ func (m *RconManager) processCommand(serverID uuid.UUID, conn *ServerConnection,
 		}
 
 		// Execute the command
-		response := conn.Rcon.Execute(cmd.Command)
+		response := client.Execute(cmd.command)
 
 		// Handle empty responses more gracefully
 		var err error
 		if response == "" {
 			// Some commands legitimately return empty responses
 			// Only consider it an error for certain command types
-			if m.shouldHaveResponse(cmd.Command) {
+			if m.shouldHaveResponse(cmd.command) {
 				err = errors.New("empty response received")
 				log.Debug().
 					Str("serverID", serverID.String()).
-					Str("command", cmd.Command).
+					Str("command", cmd.command).
 					Dur("execTime", time.Since(startTime)).
 					Msg("Command returned empty response")
 			}
 		}
 
 		// Check if this was a ShowServerInfo command and process it
-		if strings.EqualFold(strings.TrimSpace(cmd.Command), "ShowServerInfo") && response != "" && err == nil {
+		if strings.EqualFold(strings.TrimSpace(cmd.command), "ShowServerInfo") && response != "" && err == nil {
 			go m.processShowServerInfoResponse(serverID, response)
 		}
 
@@ -707,7 +667,7 @@ func (m *RconManager) processCommand(serverID uuid.UUID, conn *ServerConnection,
 			// Channel might be closed or full
 			log.Debug().
 				Str("serverID", serverID.String()).
-				Str("command", cmd.Command).
+				Str("command", cmd.command).
 				Msg("Could not send response, channel unavailable")
 		}
 	}()
@@ -719,23 +679,27 @@ func (m *RconManager) processCommand(serverID uuid.UUID, conn *ServerConnection,
 	case response := <-responseChan:
 		cmdResponse = response
 
-	case <-cmd.ctx.Done():
+	case <-ctx.Done():
 		// Command-specific timeout
 		cmdResponse = CommandResponse{
 			Response: "",
-			Error:    fmt.Errorf("command timeout: %w", cmd.ctx.Err()),
+			Error:    fmt.Errorf("command timeout: %w", ctx.Err()),
 		}
 
-		// Mark connection as potentially unhealthy if timeout
-		if cmd.ctx.Err() == context.DeadlineExceeded {
+		// Count the timeout against the circuit, unless it has already opened
+		if ctx.Err() == context.DeadlineExceeded {
 			conn.mu.Lock()
-			conn.isHealthy = false
+			closed := conn.breaker.allow()
 			conn.mu.Unlock()
 
 			log.Debug().
 				Str("serverID", serverID.String()).
-				Str("command", cmd.Command).
-				Msg("Command timed out, marking connection as unhealthy")
+				Str("command", cmd.command).
+				Msg("Command timed out")
+
+			if closed {
+				m.recordFailure(serverID, conn, "command timed out")
+			}
 		}
 
 	case <-m.ctx.Done():
@@ -745,19 +709,8 @@ func (m *RconManager) processCommand(serverID uuid.UUID, conn *ServerConnection,
 		}
 	}
 
-	// Send response back to caller with timeout protection
-	select {
-	case cmd.Response <- cmdResponse:
-		// Response sent successfully
-	case <-time.After(1 * time.Second):
-		// Caller might have given up, log but don't block
-		log.Debug().
-			Str("serverID", serverID.String()).
-			Str("command", cmd.Command).
-			Msg("Could not send response to caller, caller may have timed out")
-	case <-m.ctx.Done():
-		// Manager shutting down, nothing we can do
-	}
+	// Send the response to every caller waiting for it
+	cmd.respond(cmdResponse)
 }
 
 // shouldHaveResponse determines if a command should return a non-empty response
@@ -784,7 +737,7 @@ func (m *RconManager) shouldHaveResponse(command string) bool {
 	return true
 }
 
-// listenForEvents listens for events from an RCON server
+// listenForEvents registers the event handlers of an RCON client
 func (m *RconManager) listenForEvents(serverID uuid.UUID, sr *rcon.Rcon) {
 	// Helper function to update LastUsed and broadcast event
 	updateAndBroadcast := func(eventType string, data interface{}) {
@@ -838,7 +791,18 @@ func (m *RconManager) listenForEvents(serverID uuid.UUID, sr *rcon.Rcon) {
 		updateAndBroadcast("SQUAD_CREATED", data)
 	})
 
-	// Listen for connection events
+	// Listen for connection events, the supervisor probes the connection to
+	// decide whether it was lost
+	wakeSupervisor := func() {
+		m.mu.RLock()
+		conn, exists := m.connections[serverID]
+		m.mu.RUnlock()
+
+		if exists {
+			conn.wakeSupervisor()
+		}
+	}
+
 	sr.Emitter.On("close", func(data interface{}) {
 		log.Warn().
 			Str("serverID", serverID.String()).
@@ -846,6 +810,7 @@ func (m *RconManager) listenForEvents(serverID uuid.UUID, sr *rcon.Rcon) {
 			Msg("RCON event connection closed")
 
 		updateAndBroadcast("CONNECTION_CLOSED", data)
+		wakeSupervisor()
 	})
 
 	sr.Emitter.On("error", func(data interface{}) {
@@ -855,10 +820,8 @@ func (m *RconManager) listenForEvents(serverID uuid.UUID, sr *rcon.Rcon) {
 			Msg("RCON event connection error")
 
 		updateAndBroadcast("CONNECTION_ERROR", data)
+		wakeSupervisor()
 	})
-
-	// Block until context is done
-	<-m.ctx.Done()
 }
 
 // StartConnectionManager starts the connection manager
@@ -873,8 +836,11 @@ func (m *RconManager) cleanupAllConnections() {
 	defer m.mu.Unlock()
 
 	for _, conn := range m.connections {
+		conn.cancel()
 		conn.mu.Lock()
-		conn.Rcon.Close()
+		if conn.Rcon != nil {
+			conn.Rcon.Close()
+		}
 		conn.mu.Unlock()
 	}
 
@@ -1018,25 +984,45 @@ func (m *RconManager) GetConnectionStats(serverID uuid.UUID) (ConnectionStats, e
 		return ConnectionStats{}, errors.New("server not connected")
 	}
 
+	queue := conn.scheduler.stats(time.Now())
+
 	conn.mu.Lock()
 	defer conn.mu.Unlock()
 
-	return ConnectionStats{
-		ServerID:        serverID,
-		LastUsed:        conn.LastUsed,
-		LastHealthCheck: conn.LastHealthCheck,
-		IsHealthy:       conn.isHealthy,
-		ReconnectCount:  conn.reconnectCount,
-		QueueLength:     len(conn.CommandChan),
-	}, nil
+	stats := ConnectionStats{
+		ServerID:            serverID,
+		LastUsed:            conn.LastUsed,
+		LastHealthCheck:     conn.LastHealthCheck,
+		IsHealthy:           conn.state == ConnectionStateConnected,
+		ReconnectCount:      conn.reconnectCount,
+		QueueLength:         queue.Length,
+		Queue:               queue,
+		State:               conn.state,
+		StateSince:          conn.stateSince,
+		ConsecutiveFailures: conn.breaker.failures,
+		LatencyMs:           conn.latency.Milliseconds(),
+		LastError:           conn.lastError,
+	}
+	if conn.state == ConnectionStateDisconnected {
+		stats.NextRetry = conn.breaker.retryAt
+	}
+
+	return stats, nil
 }
 
 // ConnectionStats represents statistics about a connection
 type ConnectionStats struct {
-	ServerID        uuid.UUID
-	LastUsed        time.Time
-	LastHealthCheck time.Time
-	IsHealthy       bool
-	ReconnectCount  int
-	QueueLength     int
+	ServerID            uuid.UUID
+	LastUsed            time.Time
+	LastHealthCheck     time.Time
+	IsHealthy           bool
+	ReconnectCount      int
+	QueueLength         int
+	Queue               QueueStats // Queue depth per priority and caller, and recent wait times
+	State               ConnectionState
+	StateSince          time.Time
+	ConsecutiveFailures int
+	LatencyMs           int64     // Latency of the last successful probe
+	LastError           string    // Reason of the last failure, cleared once the connection is healthy
+	NextRetry           time.Time // When the next reconnect attempt is due, zero unless disconnected
 }
@@ -0,0 +1,480 @@
+package rcon_manager
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SystemCaller is the caller of commands sent by Aegis itself, which are not
+// rate limited
+const SystemCaller = "system"
+
+// waitSampleSize is how many recent queue waits the wait time stats cover
+const waitSampleSize = 100
+
+// bucketPruneInterval is how often the token buckets of idle callers are dropped
+const bucketPruneInterval = time.Minute
+
+// ErrQueueFull is returned when a server already has CommandQueueSize commands queued
+var ErrQueueFull = errors.New("rcon command queue is full")
+
+// PluginCaller identifies commands sent by a plugin instance
+func PluginCaller(instanceID uuid.UUID) string {
+	return "plugin:" + instanceID.String()
+}
+
+// WorkflowCaller identifies commands sent by a workflow
+func WorkflowCaller(workflowID uuid.UUID) string {
+	return "workflow:" + workflowID.String()
+}
+
+// UserCaller identifies commands sent by a user from the web UI
+func UserCaller(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+// RateLimit is a token bucket: a caller can send Burst commands at once and
+// PerSecond commands a second after that
+type RateLimit struct {
+	PerSecond float64
+	Burst     int
+}
+
+// CallerRateLimits are the rate limits of each kind of caller, applied to each
+// caller separately. Commands over the limit wait in the queue. Other callers,
+// such as the system, are not limited.
+var CallerRateLimits = map[string]RateLimit{
+	"user":     {PerSecond: 5, Burst: 20},
+	"plugin":   {PerSecond: 2, Burst: 10},
+	"workflow": {PerSecond: 2, Burst: 10},
+}
+
+// coalescableCommands are read commands whose queued duplicates share a single
+// execution
+var coalescableCommands = map[string]bool{
+	"listplayers":    true,
+	"listsquads":     true,
+	"listlayers":     true,
+	"listlevels":     true,
+	"showserverinfo": true,
+	"showcurrentmap": true,
+	"shownextmap":    true,
+}
+
+// coalesceKey returns the key identical read commands are coalesced by, or an
+// empty string if the command must always be executed
+func coalesceKey(command string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(command), " "))
+	name, _, _ := strings.Cut(key, " ")
+	if !coalescableCommands[name] {
+		return ""
+	}
+	return key
+}
+
+// callerKind returns the kind of a caller, the part before the colon
+func callerKind(caller string) string {
+	kind, _, _ := strings.Cut(caller, ":")
+	return kind
+}
+
+// tokenBucket rate limits a single caller
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   now,
+	}
+}
+
+// take takes a token if one is available, otherwise it returns how long it
+// takes until one is
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.PerSecond)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	if b.limit.PerSecond <= 0 {
+		return false, time.Hour
+	}
+	return false, time.Duration((1 - b.tokens) / b.limit.PerSecond * float64(time.Second))
+}
+
+// full reports whether the bucket has refilled to its burst by now, which
+// makes it no different from a new bucket
+func (b *tokenBucket) full(now time.Time) bool {
+	missing := float64(b.limit.Burst) - b.tokens
+	if missing <= 0 {
+		return true
+	}
+	if b.limit.PerSecond <= 0 {
+		return false
+	}
+	return now.Sub(b.last).Seconds()*b.limit.PerSecond >= missing
+}
+
+// commandWaiter is a caller waiting for the response of a queued command
+type commandWaiter struct {
+	ctx      context.Context
+	response chan CommandResponse // Buffered, so responding never blocks
+}
+
+// queuedCommand is a command waiting in the queue, shared by every caller
+// whose identical read command was coalesced into it
+type queuedCommand struct {
+	command     string
+	key         string // Coalescing key, empty if the command is not coalesced
+	priority    int
+	caller      string
+	queuedAt    time.Time
+	waiters     []commandWaiter
+	rateLimited bool // Whether the command had to wait for its caller's rate limit
+}
+
+// live reports whether any waiter still wants the response
+func (c *queuedCommand) live() bool {
+	for _, waiter := range c.waiters {
+		if waiter.ctx.Err() == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// deadline returns the latest deadline of the waiters, false if any waiter
+// has none
+func (c *queuedCommand) deadline() (time.Time, bool) {
+	var latest time.Time
+	for _, waiter := range c.waiters {
+		if waiter.ctx.Err() != nil {
+			continue
+		}
+		deadline, ok := waiter.ctx.Deadline()
+		if !ok {
+			return time.Time{}, false
+		}
+		if deadline.After(latest) {
+			latest = deadline
+		}
+	}
+	return latest, true
+}
+
+// respond sends the response to every waiter
+func (c *queuedCommand) respond(response CommandResponse) {
+	for _, waiter := range c.waiters {
+		select {
+		case waiter.response <- response:
+		default:
+		}
+	}
+}
+
+// priorityLevel holds the queued commands of one priority, per caller. Callers
+// take turns so one busy caller cannot hold back the others.
+type priorityLevel struct {
+	priority int
+	callers  []string
+	queues   map[string][]*queuedCommand
+	next     int // Index of the caller whose turn it is
+}
+
+// commandScheduler queues the commands of a server. Higher priorities always
+// go first; within a priority callers are served round robin, each within its
+// rate limit. It is safe for concurrent use.
+type commandScheduler struct {
+	mu          sync.Mutex
+	levels      []*priorityLevel // Sorted by descending priority
+	pending     map[string]*queuedCommand
+	buckets     map[string]*tokenBucket
+	lastPrune   time.Time
+	length      int
+	ready       chan struct{} // Signalled when a command is queued
+	waits       []time.Duration
+	waitIndex   int
+	coalesced   uint64
+	rateLimited uint64
+}
+
+func newCommandScheduler() *commandScheduler {
+	return &commandScheduler{
+		pending: make(map[string]*queuedCommand),
+		buckets: make(map[string]*tokenBucket),
+		ready:   make(chan struct{}, 1),
+		waits:   make([]time.Duration, 0, waitSampleSize),
+	}
+}
+
+// enqueue queues a command for a waiter. An identical read command that is
+// already queued with at least the same priority is shared instead.
+func (s *commandScheduler) enqueue(command string, priority int, caller string, waiter commandWaiter, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := coalesceKey(command)
+	if existing, ok := s.pending[key]; ok && key != "" && existing.priority >= priority {
+		existing.waiters = append(existing.waiters, waiter)
+		s.coalesced++
+		return nil
+	}
+
+	if s.length >= CommandQueueSize {
+		return ErrQueueFull
+	}
+
+	cmd := &queuedCommand{
+		command:  command,
+		key:      key,
+		priority: priority,
+		caller:   caller,
+		queuedAt: now,
+		waiters:  []commandWaiter{waiter},
+	}
+	if key != "" {
+		s.pending[key] = cmd
+	}
+
+	level := s.level(priority)
+	if _, ok := level.queues[caller]; !ok {
+		level.callers = append(level.callers, caller)
+	}
+	level.queues[caller] = append(level.queues[caller], cmd)
+	s.length++
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// level returns the level of a priority, creating it if needed. The caller
+// must hold s.mu.
+func (s *commandScheduler) level(priority int) *priorityLevel {
+	i, found := slices.BinarySearchFunc(s.levels, priority, func(level *priorityLevel, priority int) int {
+		return priority - level.priority
+	})
+	if !found {
+		s.levels = slices.Insert(s.levels, i, &priorityLevel{
+			priority: priority,
+			queues:   make(map[string][]*queuedCommand),
+		})
+	}
+	return s.levels[i]
+}
+
+// next takes the command to execute next. When every queued command is held
+// back by its caller's rate limit it returns nil and how long to wait; when
+// the queue is empty it returns nil and zero. Commands nobody waits for
+// anymore are dropped.
+func (s *commandScheduler) next(now time.Time) (*queuedCommand, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneBuckets(now)
+
+	var wait time.Duration
+	for _, level := range s.levels {
+		s.dropAbandoned(level)
+
+		for turn := 0; turn < len(level.callers); turn++ {
+			i := (level.next + turn) % len(level.callers)
+			caller := level.callers[i]
+
+			if bucket := s.bucket(caller, now); bucket != nil {
+				if ok, retryIn := bucket.take(now); !ok {
+					if cmd := level.queues[caller][0]; !cmd.rateLimited {
+						cmd.rateLimited = true
+						s.rateLimited++
+					}
+					if wait == 0 || retryIn < wait {
+						wait = retryIn
+					}
+					continue
+				}
+			}
+
+			cmd := s.pop(level, i)
+			s.recordWait(now.Sub(cmd.queuedAt))
+			return cmd, 0
+		}
+	}
+
+	return nil, wait
+}
+
+// pop takes the first command of the caller at index i of a level and passes
+// the turn to the caller after it. The caller must hold s.mu.
+func (s *commandScheduler) pop(level *priorityLevel, i int) *queuedCommand {
+	caller := level.callers[i]
+	queue := level.queues[caller]
+	cmd := queue[0]
+	s.forget(cmd)
+
+	if len(queue) > 1 {
+		level.queues[caller] = queue[1:]
+		i++
+	} else {
+		delete(level.queues, caller)
+		level.callers = slices.Delete(level.callers, i, i+1)
+	}
+
+	level.next = 0
+	if len(level.callers) > 0 {
+		level.next = i % len(level.callers)
+	}
+
+	return cmd
+}
+
+// dropAbandoned removes the commands of a level that nobody waits for
+// anymore. The caller must hold s.mu.
+func (s *commandScheduler) dropAbandoned(level *priorityLevel) {
+	callers := level.callers[:0]
+	next := 0
+
+	for i, caller := range level.callers {
+		queue := level.queues[caller][:0]
+		for _, cmd := range level.queues[caller] {
+			if cmd.live() {
+				queue = append(queue, cmd)
+			} else {
+				s.forget(cmd)
+			}
+		}
+
+		if len(queue) == 0 {
+			delete(level.queues, caller)
+			continue
+		}
+
+		level.queues[caller] = queue
+		callers = append(callers, caller)
+		if i < level.next {
+			next++
+		}
+	}
+
+	level.callers = callers
+	level.next = next
+	if level.next >= len(level.callers) {
+		level.next = 0
+	}
+}
+
+// forget takes a command out of the queue length and the coalescing index.
+// The caller must hold s.mu.
+func (s *commandScheduler) forget(cmd *queuedCommand) {
+	if cmd.key != "" && s.pending[cmd.key] == cmd {
+		delete(s.pending, cmd.key)
+	}
+	s.length--
+}
+
+// bucket returns the token bucket of a caller, nil if it is not rate limited.
+// The caller must hold s.mu.
+func (s *commandScheduler) bucket(caller string, now time.Time) *tokenBucket {
+	limit, ok := CallerRateLimits[callerKind(caller)]
+	if !ok {
+		return nil
+	}
+
+	bucket, ok := s.buckets[caller]
+	if !ok {
+		bucket = newTokenBucket(limit, now)
+		s.buckets[caller] = bucket
+	}
+	return bucket
+}
+
+// pruneBuckets drops the buckets of callers that have been idle long enough
+// to refill, so callers that come and go don't keep a bucket forever. The
+// caller must hold s.mu.
+func (s *commandScheduler) pruneBuckets(now time.Time) {
+	if now.Sub(s.lastPrune) < bucketPruneInterval {
+		return
+	}
+	s.lastPrune = now
+
+	for caller, bucket := range s.buckets {
+		if bucket.full(now) {
+			delete(s.buckets, caller)
+		}
+	}
+}
+
+// recordWait keeps the wait of a dispatched command for the stats. The caller
+// must hold s.mu.
+func (s *commandScheduler) recordWait(wait time.Duration) {
+	if len(s.waits) < waitSampleSize {
+		s.waits = append(s.waits, wait)
+		return
+	}
+	s.waits[s.waitIndex] = wait
+	s.waitIndex = (s.waitIndex + 1) % waitSampleSize
+}
+
+// QueueStats describes the command queue of a server
+type QueueStats struct {
+	Length           int            `json:"length"`
+	ByPriority       map[int]int    `json:"by_priority"`
+	ByCaller         map[string]int `json:"by_caller"`
+	OldestWaitMs     int64          `json:"oldest_wait_ms"` // How long the oldest queued command has been waiting
+	AverageWaitMs    int64          `json:"average_wait_ms"`
+	MaxWaitMs        int64          `json:"max_wait_ms"`
+	CoalescedCount   uint64         `json:"coalesced_count"`    // Commands that shared the execution of an identical queued command
+	RateLimitedCount uint64         `json:"rate_limited_count"` // Commands that had to wait for their caller's rate limit
+}
+
+// stats returns the queue depth and the waits of recently dispatched commands
+func (s *commandScheduler) stats(now time.Time) QueueStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := QueueStats{
+		Length:           s.length,
+		ByPriority:       make(map[int]int),
+		ByCaller:         make(map[string]int),
+		CoalescedCount:   s.coalesced,
+		RateLimitedCount: s.rateLimited,
+	}
+
+	for _, level := range s.levels {
+		for caller, queue := range level.queues {
+			stats.ByPriority[level.priority] += len(queue)
+			stats.ByCaller[caller] += len(queue)
+			stats.OldestWaitMs = max(stats.OldestWaitMs, now.Sub(queue[0].queuedAt).Milliseconds())
+		}
+	}
+
+	var total time.Duration
+	for _, wait := range s.waits {
+		total += wait
+		stats.MaxWaitMs = max(stats.MaxWaitMs, wait.Milliseconds())
+	}
+	if len(s.waits) > 0 {
+		stats.AverageWaitMs = (total / time.Duration(len(s.waits))).Milliseconds()
+	}
+
+	return stats
+}
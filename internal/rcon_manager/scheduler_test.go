@@ -0,0 +1,231 @@
+package rcon_manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testWaiter(ctx context.Context) commandWaiter {
+	return commandWaiter{ctx: ctx, response: make(chan CommandResponse, 1)}
+}
+
+func mustEnqueue(t *testing.T, s *commandScheduler, command string, priority int, caller string, when time.Time) commandWaiter {
+	t.Helper()
+	waiter := testWaiter(context.Background())
+	if err := s.enqueue(command, priority, caller, waiter, when); err != nil {
+		t.Fatalf("enqueue %q: %v", command, err)
+	}
+	return waiter
+}
+
+func dispatched(t *testing.T, s *commandScheduler, when time.Time) []string {
+	t.Helper()
+	commands := []string{}
+	for {
+		cmd, _ := s.next(when)
+		if cmd == nil {
+			return commands
+		}
+		commands = append(commands, cmd.command)
+	}
+}
+
+func TestSchedulerHigherPriorityFirst(t *testing.T) {
+	s := newCommandScheduler()
+	mustEnqueue(t, s, "AdminBroadcast low", PriorityLow, SystemCaller, now)
+	mustEnqueue(t, s, "AdminBroadcast normal", PriorityNormal, SystemCaller, now)
+	mustEnqueue(t, s, "AdminKick 1 critical", PriorityCritical, SystemCaller, now)
+	mustEnqueue(t, s, "AdminKick 2 high", PriorityHigh, SystemCaller, now)
+
+	got := dispatched(t, s, now)
+	want := []string{"AdminKick 1 critical", "AdminKick 2 high", "AdminBroadcast normal", "AdminBroadcast low"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestSchedulerCallersTakeTurns(t *testing.T) {
+	s := newCommandScheduler()
+	busy := PluginCaller(uuid.New())
+	other := WorkflowCaller(uuid.New())
+
+	for _, command := range []string{"AdminWarn a", "AdminWarn b", "AdminWarn c"} {
+		mustEnqueue(t, s, command, PriorityNormal, busy, now)
+	}
+	mustEnqueue(t, s, "AdminWarn x", PriorityNormal, other, now)
+	mustEnqueue(t, s, "AdminWarn y", PriorityNormal, other, now)
+
+	got := dispatched(t, s, now)
+	want := []string{"AdminWarn a", "AdminWarn x", "AdminWarn b", "AdminWarn y", "AdminWarn c"}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestSchedulerRateLimitsCallers(t *testing.T) {
+	s := newCommandScheduler()
+	caller := PluginCaller(uuid.New())
+	limit := CallerRateLimits["plugin"]
+
+	for i := 0; i < limit.Burst+1; i++ {
+		mustEnqueue(t, s, "AdminBroadcast spam", PriorityNormal, caller, now)
+	}
+	mustEnqueue(t, s, "AdminBroadcast system", PriorityLow, SystemCaller, now)
+
+	if got := len(dispatched(t, s, now)); got != limit.Burst+1 {
+		t.Fatalf("expected the burst and the system command to be dispatched, got %d commands", got)
+	}
+
+	cmd, wait := s.next(now)
+	if cmd != nil {
+		t.Fatal("expected the command over the burst to wait for its rate limit")
+	}
+	if want := time.Duration(float64(time.Second) / limit.PerSecond); wait != want {
+		t.Errorf("expected to wait %s, got %s", want, wait)
+	}
+
+	if cmd, _ := s.next(now.Add(wait)); cmd == nil {
+		t.Error("expected the command to be dispatched once a token is available")
+	}
+
+	stats := s.stats(now)
+	if stats.RateLimitedCount != 1 {
+		t.Errorf("expected 1 rate limited command, got %d", stats.RateLimitedCount)
+	}
+}
+
+func TestSchedulerPrunesIdleBuckets(t *testing.T) {
+	s := newCommandScheduler()
+	idle := PluginCaller(uuid.New())
+	busy := PluginCaller(uuid.New())
+	limit := CallerRateLimits["plugin"]
+
+	mustEnqueue(t, s, "AdminBroadcast once", PriorityNormal, idle, now)
+	for i := 0; i < limit.Burst; i++ {
+		mustEnqueue(t, s, "AdminBroadcast spam", PriorityNormal, busy, now)
+	}
+	dispatched(t, s, now)
+
+	// One token has refilled for the busy caller, the idle caller is full again
+	later := now.Add(time.Duration(float64(time.Second) / limit.PerSecond))
+	s.lastPrune = time.Time{}
+	s.pruneBuckets(later)
+
+	if _, ok := s.buckets[idle]; ok {
+		t.Error("expected the bucket of the idle caller to be dropped")
+	}
+	if _, ok := s.buckets[busy]; !ok {
+		t.Fatal("expected the bucket of the busy caller to be kept until it refills")
+	}
+
+	s.pruneBuckets(later.Add(bucketPruneInterval))
+	if _, ok := s.buckets[busy]; ok {
+		t.Error("expected the refilled bucket to be dropped")
+	}
+}
+
+func TestSchedulerCoalescesReadCommands(t *testing.T) {
+	s := newCommandScheduler()
+	first := mustEnqueue(t, s, "ListPlayers", PriorityNormal, PluginCaller(uuid.New()), now)
+	second := mustEnqueue(t, s, "listplayers ", PriorityLow, UserCaller(uuid.New()), now)
+	mustEnqueue(t, s, "AdminBroadcast hi", PriorityNormal, SystemCaller, now)
+	mustEnqueue(t, s, "AdminBroadcast hi", PriorityNormal, SystemCaller, now)
+
+	if stats := s.stats(now); stats.Length != 3 || stats.CoalescedCount != 1 {
+		t.Fatalf("expected 3 queued and 1 coalesced command, got %d and %d", stats.Length, stats.CoalescedCount)
+	}
+
+	cmd, _ := s.next(now)
+	if cmd == nil || cmd.command != "ListPlayers" {
+		t.Fatalf("expected ListPlayers to be dispatched first, got %v", cmd)
+	}
+	cmd.respond(CommandResponse{Response: "players"})
+
+	for _, waiter := range []commandWaiter{first, second} {
+		if response := <-waiter.response; response.Response != "players" {
+			t.Errorf("expected every waiter to get the response, got %q", response.Response)
+		}
+	}
+
+	// Once dispatched, the next ListPlayers is executed again
+	mustEnqueue(t, s, "ListPlayers", PriorityNormal, SystemCaller, now)
+	if stats := s.stats(now); stats.CoalescedCount != 1 {
+		t.Errorf("expected a dispatched command not to be coalesced into, got %d coalesced", stats.CoalescedCount)
+	}
+}
+
+func TestSchedulerDoesNotCoalesceIntoLowerPriority(t *testing.T) {
+	s := newCommandScheduler()
+	mustEnqueue(t, s, "ListSquads", PriorityLow, SystemCaller, now)
+	mustEnqueue(t, s, "ListSquads", PriorityHigh, SystemCaller, now)
+
+	if stats := s.stats(now); stats.Length != 2 || stats.CoalescedCount != 0 {
+		t.Errorf("expected a higher priority read to be queued separately, got %d queued and %d coalesced", stats.Length, stats.CoalescedCount)
+	}
+}
+
+func TestSchedulerDropsAbandonedCommands(t *testing.T) {
+	s := newCommandScheduler()
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.enqueue("AdminBroadcast gone", PriorityNormal, SystemCaller, testWaiter(ctx), now); err != nil {
+		t.Fatal(err)
+	}
+	mustEnqueue(t, s, "AdminBroadcast kept", PriorityNormal, SystemCaller, now)
+	cancel()
+
+	got := dispatched(t, s, now)
+	if len(got) != 1 || got[0] != "AdminBroadcast kept" {
+		t.Errorf("expected only the live command to be dispatched, got %v", got)
+	}
+	if stats := s.stats(now); stats.Length != 0 {
+		t.Errorf("expected an empty queue, got %d", stats.Length)
+	}
+}
+
+func TestSchedulerQueueFull(t *testing.T) {
+	s := newCommandScheduler()
+	for i := 0; i < CommandQueueSize; i++ {
+		mustEnqueue(t, s, "AdminBroadcast hi", PriorityNormal, SystemCaller, now)
+	}
+
+	err := s.enqueue("AdminBroadcast hi", PriorityNormal, SystemCaller, testWaiter(context.Background()), now)
+	if err != ErrQueueFull {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+}
+
+func TestSchedulerStats(t *testing.T) {
+	s := newCommandScheduler()
+	caller := UserCaller(uuid.New())
+	mustEnqueue(t, s, "AdminKick 1", PriorityHigh, caller, now)
+	mustEnqueue(t, s, "AdminWarn 1 hi", PriorityNormal, caller, now.Add(time.Second))
+	mustEnqueue(t, s, "AdminBroadcast hi", PriorityNormal, SystemCaller, now.Add(2*time.Second))
+
+	if cmd, _ := s.next(now.Add(4 * time.Second)); cmd == nil {
+		t.Fatal("expected a command to be dispatched")
+	}
+
+	stats := s.stats(now.Add(5 * time.Second))
+	if stats.Length != 2 {
+		t.Errorf("expected 2 queued commands, got %d", stats.Length)
+	}
+	if stats.ByPriority[PriorityNormal] != 2 || stats.ByCaller[caller] != 1 || stats.ByCaller[SystemCaller] != 1 {
+		t.Errorf("unexpected queue depth: %v %v", stats.ByPriority, stats.ByCaller)
+	}
+	if stats.OldestWaitMs != 4000 {
+		t.Errorf("expected the oldest command to have waited 4000ms, got %d", stats.OldestWaitMs)
+	}
+	if stats.AverageWaitMs != 4000 || stats.MaxWaitMs != 4000 {
+		t.Errorf("expected the dispatched command to have waited 4000ms, got %d average and %d max", stats.AverageWaitMs, stats.MaxWaitMs)
+	}
+}
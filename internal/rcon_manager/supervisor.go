@@ -0,0 +1,307 @@
+package rcon_manager
+
+import (
+	"fmt"
+	"time"
+
+	rcon "github.com/SquadGO/squad-rcon-go/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+)
+
+const (
+	ProbeInterval         = 30 * time.Second // How often a connected server is probed
+	DegradedProbeInterval = 5 * time.Second  // How often a degraded server is probed
+	ProbeTimeout          = 10 * time.Second // How long a probe waits for the command slot
+	DegradedLatency       = 3 * time.Second  // Probes slower than this mark the connection as degraded
+)
+
+// client returns the RCON client of the connection, nil while it is disconnected
+func (conn *ServerConnection) client() *rcon.Rcon {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	return conn.Rcon
+}
+
+// wakeSupervisor makes the supervisor check the connection right away
+func (conn *ServerConnection) wakeSupervisor() {
+	select {
+	case conn.wake <- struct{}{}:
+	default:
+	}
+}
+
+// nextCheck returns how long the supervisor waits before checking the
+// connection again
+func (conn *ServerConnection) nextCheck(now time.Time) time.Duration {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	switch conn.state {
+	case ConnectionStateDisconnected:
+		return max(conn.breaker.retryAt.Sub(now), 0)
+	case ConnectionStateDegraded:
+		return DegradedProbeInterval
+	default:
+		return ProbeInterval
+	}
+}
+
+// setState changes the state of the connection and reports whether it changed.
+// The caller must hold conn.mu.
+func (conn *ServerConnection) setState(state ConnectionState, now time.Time) bool {
+	if conn.state == state {
+		return false
+	}
+
+	conn.state = state
+	conn.stateSince = now
+	return true
+}
+
+// monitorConnection supervises a connection. It probes the server with
+// ShowServerInfo, which also feeds the periodic server info event, publishes
+// state changes and reconnects with backoff once the circuit has opened.
+func (m *RconManager) monitorConnection(serverID uuid.UUID, conn *ServerConnection) {
+	// Probe right away so server info is collected as soon as we are connected
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-conn.wake:
+			timer.Stop()
+		case <-conn.ctx.Done():
+			log.Debug().
+				Str("serverID", serverID.String()).
+				Msg("Stopping connection monitor")
+			return
+		}
+
+		conn.mu.Lock()
+		disconnected := conn.state == ConnectionStateDisconnected
+		conn.mu.Unlock()
+
+		if disconnected {
+			m.reconnect(serverID, conn)
+		} else {
+			m.probe(serverID, conn)
+		}
+
+		timer.Reset(conn.nextCheck(time.Now()))
+	}
+}
+
+// probe checks that the server answers ShowServerInfo and records the outcome
+func (m *RconManager) probe(serverID uuid.UUID, conn *ServerConnection) {
+	conn.mu.Lock()
+	conn.LastHealthCheck = time.Now()
+	client := conn.Rcon
+	conn.mu.Unlock()
+
+	if client == nil {
+		m.recordFailure(serverID, conn, "no connection")
+		return
+	}
+
+	// Take the command slot so the probe does not interleave with a command
+	select {
+	case conn.cmdSemaphore <- struct{}{}:
+	case <-time.After(ProbeTimeout):
+		m.recordFailure(serverID, conn, "command queue is stalled")
+		return
+	case <-conn.ctx.Done():
+		return
+	}
+
+	start := time.Now()
+	response := client.Execute("ShowServerInfo")
+	latency := time.Since(start)
+	<-conn.cmdSemaphore
+
+	if response == "" {
+		m.recordFailure(serverID, conn, "no response to ShowServerInfo")
+		return
+	}
+
+	m.recordSuccess(serverID, conn, latency)
+
+	conn.mu.Lock()
+	now := time.Now()
+	needsServerInfo := now.Sub(conn.LastServerInfoTime) >= ServerInfoInterval
+	if needsServerInfo {
+		conn.LastServerInfoTime = now
+	}
+	conn.mu.Unlock()
+
+	if needsServerInfo {
+		m.processShowServerInfoResponse(serverID, response)
+	}
+}
+
+// reconnect opens a new connection once the backoff of the circuit has expired.
+// The connection only counts as restored once it answers a probe.
+func (m *RconManager) reconnect(serverID uuid.UUID, conn *ServerConnection) {
+	conn.mu.Lock()
+	ready := conn.breaker.readyToRetry(time.Now())
+	attempt := conn.breaker.attempts + 1
+	conn.mu.Unlock()
+
+	if !ready {
+		return
+	}
+
+	log.Debug().
+		Str("serverID", serverID.String()).
+		Int("attempt", attempt).
+		Msg("Reconnecting to RCON server")
+
+	rconConn, err := rcon.NewRconWithContext(conn.ctx, conn.config)
+	if err != nil {
+		m.recordFailure(serverID, conn, err.Error())
+		return
+	}
+
+	m.listenForEvents(serverID, rconConn)
+
+	conn.mu.Lock()
+	if conn.ctx.Err() != nil {
+		// Disconnected while we were dialing
+		conn.mu.Unlock()
+		rconConn.Close()
+		return
+	}
+	conn.Rcon = rconConn
+	conn.reconnectCount++
+	conn.mu.Unlock()
+
+	m.probe(serverID, conn)
+}
+
+// recordSuccess closes the circuit after a successful probe. A slow answer
+// marks the connection as degraded, unless it was just restored.
+func (m *RconManager) recordSuccess(serverID uuid.UUID, conn *ServerConnection, latency time.Duration) {
+	now := time.Now()
+
+	conn.mu.Lock()
+	previous := conn.state
+	downSince := conn.stateSince
+	attempts := conn.breaker.attempts + 1
+
+	state := ConnectionStateConnected
+	if latency > DegradedLatency && previous != ConnectionStateDisconnected {
+		state = ConnectionStateDegraded
+	}
+
+	conn.breaker.success()
+	conn.latency = latency
+	if state == ConnectionStateConnected {
+		conn.lastError = ""
+	}
+	changed := conn.setState(state, now)
+	conn.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if state == ConnectionStateDegraded {
+		reason := fmt.Sprintf("ShowServerInfo took %s", latency.Round(time.Millisecond))
+
+		log.Warn().
+			Str("serverID", serverID.String()).
+			Dur("latency", latency).
+			Msg("RCON connection degraded")
+
+		m.publishConnectionEvent(serverID, &event_manager.RconDegradedData{
+			Reason:    reason,
+			LatencyMs: latency.Milliseconds(),
+		})
+		return
+	}
+
+	data := &event_manager.RconConnectedData{
+		LatencyMs: latency.Milliseconds(),
+	}
+	if previous == ConnectionStateDisconnected {
+		data.Reconnected = true
+		data.Attempts = attempts
+		data.DowntimeSeconds = now.Sub(downSince).Seconds()
+	}
+
+	log.Info().
+		Str("serverID", serverID.String()).
+		Bool("reconnected", data.Reconnected).
+		Int("attempts", data.Attempts).
+		Msg("RCON connection healthy")
+
+	m.publishConnectionEvent(serverID, data)
+}
+
+// recordFailure counts a failed probe, reconnect or command against the
+// circuit. The connection is degraded until the failure threshold is reached,
+// then the circuit opens, the client is closed and reconnects are scheduled.
+func (m *RconManager) recordFailure(serverID uuid.UUID, conn *ServerConnection, reason string) {
+	now := time.Now()
+
+	conn.mu.Lock()
+	opened := conn.breaker.failure(now)
+	failures := conn.breaker.failures
+	retryIn := conn.breaker.retryAt.Sub(now)
+	conn.lastError = reason
+
+	var stale *rcon.Rcon
+	var data event_manager.EventData
+	switch {
+	case opened:
+		stale, conn.Rcon = conn.Rcon, nil
+		conn.setState(ConnectionStateDisconnected, now)
+		data = &event_manager.RconDisconnectedData{
+			Reason:              reason,
+			ConsecutiveFailures: failures,
+			RetryInSeconds:      retryIn.Seconds(),
+		}
+	case !conn.breaker.allow():
+		// A reconnect attempt failed, drop the half-open client
+		stale, conn.Rcon = conn.Rcon, nil
+	case conn.setState(ConnectionStateDegraded, now):
+		data = &event_manager.RconDegradedData{
+			Reason:              reason,
+			ConsecutiveFailures: failures,
+		}
+	}
+	conn.mu.Unlock()
+
+	if stale != nil {
+		stale.Close()
+	}
+
+	if opened {
+		log.Warn().
+			Str("serverID", serverID.String()).
+			Str("reason", reason).
+			Int("failures", failures).
+			Dur("retryIn", retryIn).
+			Msg("RCON connection lost, circuit opened")
+	} else {
+		log.Debug().
+			Str("serverID", serverID.String()).
+			Str("reason", reason).
+			Int("failures", failures).
+			Msg("RCON health check failed")
+	}
+
+	if data != nil {
+		m.publishConnectionEvent(serverID, data)
+	}
+}
+
+// publishConnectionEvent publishes a connection state change to the event manager
+func (m *RconManager) publishConnectionEvent(serverID uuid.UUID, data event_manager.EventData) {
+	if m.eventManager != nil {
+		m.eventManager.PublishEvent(serverID, data, nil)
+	}
+}
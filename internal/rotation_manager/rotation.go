@@ -0,0 +1,181 @@
+package rotation_manager
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// MapFromLayer returns the map part of a layer name, e.g. "Narva" for
+// "Narva_RAAS_v1"
+func MapFromLayer(layer string) string {
+	name, _, _ := strings.Cut(layer, "_")
+	return name
+}
+
+// NextLayerCommand returns the RCON command setting entry as the next layer
+func NextLayerCommand(entry models.RotationLayer) string {
+	command := "AdminSetNextLayer " + entry.Layer
+	if entry.Team1Faction != "" && entry.Team2Faction != "" {
+		command += " " + entry.Team1Faction + " " + entry.Team2Faction
+	}
+	return command
+}
+
+// Eligible returns the indexes of the pool entries that may be played next.
+// history holds the most recent layers first. When the no-repeat windows rule
+// out every entry they are ignored, so a small pool never stalls the rotation;
+// player count and time of day rules always apply.
+func Eligible(rotation *models.ServerRotation, history []models.LayerHistoryEntry, playerCount int, now time.Time) []int {
+	var allowed, fresh []int
+	for i, entry := range rotation.Layers {
+		if !matchesRules(entry, playerCount, now) {
+			continue
+		}
+		allowed = append(allowed, i)
+		if !repeats(entry, history, rotation.MapRepeatWindow, rotation.FactionRepeatWindow) {
+			fresh = append(fresh, i)
+		}
+	}
+
+	if len(fresh) > 0 {
+		return fresh
+	}
+	return allowed
+}
+
+// PickNext picks the next layer of a rotation and returns its index in the
+// pool. Ordered rotations continue from their position, weighted rotations
+// pick at random.
+func PickNext(rotation *models.ServerRotation, history []models.LayerHistoryEntry, playerCount int, now time.Time, rnd *rand.Rand) (int, bool) {
+	eligible := Eligible(rotation, history, playerCount, now)
+	if len(eligible) == 0 {
+		return 0, false
+	}
+
+	if rotation.Mode == models.RotationModeWeighted {
+		return eligible[weightedIndex(rotation.Layers, eligible, rnd)], true
+	}
+
+	isEligible := make(map[int]bool, len(eligible))
+	for _, i := range eligible {
+		isEligible[i] = true
+	}
+	for offset := range rotation.Layers {
+		i := (rotation.Position + offset) % len(rotation.Layers)
+		if i < 0 {
+			i += len(rotation.Layers)
+		}
+		if isEligible[i] {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// PickCandidates picks up to count distinct layers to offer in a vote,
+// weighted by their weight and with at most one layer per map
+func PickCandidates(rotation *models.ServerRotation, history []models.LayerHistoryEntry, playerCount int, now time.Time, count int, rnd *rand.Rand) []models.RotationLayer {
+	remaining := Eligible(rotation, history, playerCount, now)
+	candidates := []models.RotationLayer{}
+	maps := map[string]bool{}
+
+	for len(candidates) < count && len(remaining) > 0 {
+		n := weightedIndex(rotation.Layers, remaining, rnd)
+		entry := rotation.Layers[remaining[n]]
+		remaining = append(remaining[:n], remaining[n+1:]...)
+
+		mapName := MapFromLayer(entry.Layer)
+		if maps[mapName] {
+			continue
+		}
+		maps[mapName] = true
+		candidates = append(candidates, entry)
+	}
+
+	return candidates
+}
+
+// matchesRules reports whether entry may be played at this player count and time
+func matchesRules(entry models.RotationLayer, playerCount int, now time.Time) bool {
+	if entry.MinPlayers > 0 && playerCount < entry.MinPlayers {
+		return false
+	}
+	if entry.MaxPlayers > 0 && playerCount > entry.MaxPlayers {
+		return false
+	}
+
+	if entry.StartHour != nil && entry.EndHour != nil {
+		hour := now.UTC().Hour()
+		start, end := *entry.StartHour, *entry.EndHour
+		if start <= end {
+			if hour < start || hour >= end {
+				return false
+			}
+		} else if hour < start && hour >= end {
+			return false
+		}
+	}
+
+	return true
+}
+
+// repeats reports whether entry's map or factions were played within the
+// no-repeat windows
+func repeats(entry models.RotationLayer, history []models.LayerHistoryEntry, mapWindow, factionWindow int) bool {
+	mapName := MapFromLayer(entry.Layer)
+	for i := 0; i < mapWindow && i < len(history); i++ {
+		if strings.EqualFold(history[i].Map, mapName) {
+			return true
+		}
+	}
+
+	if entry.Team1Faction == "" && entry.Team2Faction == "" {
+		return false
+	}
+	for i := 0; i < factionWindow && i < len(history); i++ {
+		for _, played := range []string{history[i].Team1Faction, history[i].Team2Faction} {
+			if played == "" {
+				continue
+			}
+			if sameFaction(played, entry.Team1Faction) || sameFaction(played, entry.Team2Faction) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// sameFaction compares factions ignoring the unit type, so "USA+CombinedArms"
+// matches "USA"
+func sameFaction(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	a, _, _ = strings.Cut(a, "+")
+	b, _, _ = strings.Cut(b, "+")
+	return strings.EqualFold(a, b)
+}
+
+// weightedIndex picks a position in indexes, weighted by the weight of the
+// pool entries they point at. Entries without a weight count as 1.
+func weightedIndex(layers []models.RotationLayer, indexes []int, rnd *rand.Rand) int {
+	total := 0
+	for _, i := range indexes {
+		total += max(layers[i].Weight, 1)
+	}
+
+	pick := rnd.Intn(total)
+	for n, i := range indexes {
+		pick -= max(layers[i].Weight, 1)
+		if pick < 0 {
+			return n
+		}
+	}
+
+	return len(indexes) - 1
+}
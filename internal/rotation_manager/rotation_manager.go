@@ -0,0 +1,340 @@
+package rotation_manager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/clickhouse"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
+	squadRcon "go.codycody31.dev/squad-aegis/internal/squad-rcon"
+)
+
+// newGameSettleDelay is how long to wait after a new game before asking the
+// server for the current layer, so RCON reports the layer that was loaded
+const newGameSettleDelay = 15 * time.Second
+
+// Layer sources recorded in the layer history
+const (
+	SourceRotation = "rotation"
+	SourceVote     = "vote"
+	SourceAdmin    = "admin"
+	SourceServer   = "server"
+)
+
+// ErrEmptyRotation is returned when a server's rotation has no layers to pick from
+var ErrEmptyRotation = errors.New("rotation has no eligible layers")
+
+type pendingLayer struct {
+	layer  string
+	source string
+}
+
+// RotationManager records the layers played on every server and, for servers
+// with an enabled rotation, sets the next layer whenever a match starts
+type RotationManager struct {
+	db               *sql.DB
+	clickhouseClient *clickhouse.Client
+	rconManager      *rcon_manager.RconManager
+	eventManager     *event_manager.EventManager
+	subscriber       *event_manager.EventSubscriber
+	ctx              context.Context
+	cancel           context.CancelFunc
+	wg               sync.WaitGroup
+
+	mu      sync.Mutex
+	rnd     *rand.Rand
+	pending map[uuid.UUID]pendingLayer // serverID -> layer Aegis set as next
+}
+
+// NewRotationManager creates a new RotationManager instance
+func NewRotationManager(ctx context.Context, db *sql.DB, clickhouseClient *clickhouse.Client, rconManager *rcon_manager.RconManager, eventManager *event_manager.EventManager) *RotationManager {
+	ctx, cancel := context.WithCancel(ctx)
+	return &RotationManager{
+		db:               db,
+		clickhouseClient: clickhouseClient,
+		rconManager:      rconManager,
+		eventManager:     eventManager,
+		ctx:              ctx,
+		cancel:           cancel,
+		rnd:              rand.New(rand.NewSource(time.Now().UnixNano())),
+		pending:          make(map[uuid.UUID]pendingLayer),
+	}
+}
+
+// Start subscribes to game events and begins processing
+func (m *RotationManager) Start() {
+	log.Info().Msg("Starting rotation manager")
+
+	filter := event_manager.EventFilter{
+		Types: []event_manager.EventType{event_manager.EventTypeLogGameEventUnified},
+	}
+	m.subscriber = m.eventManager.Subscribe(filter, nil, 100)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.processLoop()
+	}()
+}
+
+// Stop unsubscribes from events and waits for processing to finish
+func (m *RotationManager) Stop() {
+	log.Info().Msg("Stopping rotation manager")
+
+	if m.subscriber != nil {
+		m.eventManager.Unsubscribe(m.subscriber.ID)
+	}
+
+	m.cancel()
+	m.wg.Wait()
+}
+
+func (m *RotationManager) processLoop() {
+	eventChan := m.subscriber.Channel
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case event, ok := <-eventChan:
+			if !ok {
+				return
+			}
+
+			data, ok := event.Data.(*event_manager.LogGameEventUnifiedData)
+			if !ok || data.EventType != "NEW_GAME" {
+				continue
+			}
+
+			m.wg.Add(1)
+			go func(serverID uuid.UUID, data *event_manager.LogGameEventUnifiedData) {
+				defer m.wg.Done()
+				m.handleNewGame(serverID, data)
+			}(event.ServerID, data)
+		}
+	}
+}
+
+// handleNewGame records the layer that was loaded and picks the next one
+func (m *RotationManager) handleNewGame(serverID uuid.UUID, data *event_manager.LogGameEventUnifiedData) {
+	select {
+	case <-m.ctx.Done():
+		return
+	case <-time.After(newGameSettleDelay):
+	}
+
+	r := squadRcon.NewSquadRcon(m.rconManager, serverID)
+
+	entry := models.LayerHistoryEntry{
+		EventTime: time.Now().UTC(),
+		Layer:     data.LayerClassname,
+		Source:    SourceServer,
+	}
+	if current, err := r.GetCurrentMap(); err == nil {
+		entry.Layer = current.Layer
+		if len(current.Factions) == 2 {
+			entry.Team1Faction = current.Factions[0]
+			entry.Team2Faction = current.Factions[1]
+		}
+	}
+	if entry.Layer == "" {
+		log.Debug().Str("serverId", serverID.String()).Msg("Unable to determine the new layer, skipping rotation")
+		return
+	}
+	entry.Map = MapFromLayer(entry.Layer)
+
+	if players, err := r.GetServerPlayers(); err == nil {
+		entry.PlayerCount = len(players.OnlinePlayers)
+	}
+
+	m.mu.Lock()
+	if pending, ok := m.pending[serverID]; ok {
+		if strings.EqualFold(pending.layer, entry.Layer) {
+			entry.Source = pending.source
+		}
+		delete(m.pending, serverID)
+	}
+	m.mu.Unlock()
+
+	if err := m.recordLayer(m.ctx, serverID, entry); err != nil {
+		log.Error().Err(err).Str("serverId", serverID.String()).Str("layer", entry.Layer).Msg("Failed to record layer history")
+	}
+
+	rotation, err := core.GetServerRotation(m.ctx, m.db, serverID)
+	if err != nil {
+		log.Error().Err(err).Str("serverId", serverID.String()).Msg("Failed to load rotation")
+		return
+	}
+	if !rotation.Enabled || len(rotation.Layers) == 0 {
+		return
+	}
+
+	history, err := m.GetLayerHistory(m.ctx, serverID, historyWindow(rotation))
+	if err != nil {
+		log.Warn().Err(err).Str("serverId", serverID.String()).Msg("Failed to load layer history, ignoring no-repeat windows")
+		history = []models.LayerHistoryEntry{entry}
+	}
+
+	m.mu.Lock()
+	index, ok := PickNext(rotation, history, entry.PlayerCount, time.Now(), m.rnd)
+	m.mu.Unlock()
+	if !ok {
+		log.Warn().Str("serverId", serverID.String()).Int("players", entry.PlayerCount).Msg("No rotation layer matches the current rules")
+		return
+	}
+
+	next := rotation.Layers[index]
+	if err := m.SetNextLayer(m.ctx, serverID, next, SourceRotation); err != nil {
+		log.Error().Err(err).Str("serverId", serverID.String()).Str("layer", next.Layer).Msg("Failed to set next layer")
+		return
+	}
+
+	if rotation.Mode == models.RotationModeOrdered {
+		if err := core.SetServerRotationPosition(m.ctx, m.db, serverID, (index+1)%len(rotation.Layers)); err != nil {
+			log.Error().Err(err).Str("serverId", serverID.String()).Msg("Failed to save rotation position")
+		}
+	}
+
+	log.Info().
+		Str("serverId", serverID.String()).
+		Str("current", entry.Layer).
+		Str("next", next.Layer).
+		Int("players", entry.PlayerCount).
+		Msg("Set next layer from rotation")
+}
+
+// SetNextLayer sets the next layer of a server. source is recorded in the
+// layer history once the layer is played.
+func (m *RotationManager) SetNextLayer(ctx context.Context, serverID uuid.UUID, layer models.RotationLayer, source string) error {
+	if strings.TrimSpace(layer.Layer) == "" {
+		return fmt.Errorf("layer is required")
+	}
+
+	if _, err := m.rconManager.ExecuteCommand(serverID, NextLayerCommand(layer)); err != nil {
+		return fmt.Errorf("failed to set next layer: %w", err)
+	}
+
+	m.mu.Lock()
+	m.pending[serverID] = pendingLayer{layer: layer.Layer, source: source}
+	m.mu.Unlock()
+
+	return nil
+}
+
+// GetVoteCandidates picks up to count layers from a server's rotation pool to
+// offer in a vote. The pool is used even when automatic rotation is disabled.
+func (m *RotationManager) GetVoteCandidates(ctx context.Context, serverID uuid.UUID, count int) ([]models.RotationLayer, error) {
+	rotation, history, playerCount, err := m.selectionState(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	candidates := PickCandidates(rotation, history, playerCount, time.Now(), count, m.rnd)
+	m.mu.Unlock()
+
+	if len(candidates) == 0 {
+		return nil, ErrEmptyRotation
+	}
+
+	return candidates, nil
+}
+
+// GetEligibleLayers returns the pool entries of a server's rotation that may
+// be picked right now
+func (m *RotationManager) GetEligibleLayers(ctx context.Context, serverID uuid.UUID) ([]models.RotationLayer, error) {
+	rotation, history, playerCount, err := m.selectionState(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []models.RotationLayer{}
+	for _, i := range Eligible(rotation, history, playerCount, time.Now()) {
+		layers = append(layers, rotation.Layers[i])
+	}
+
+	return layers, nil
+}
+
+// GetLayerHistory returns the most recent layers played on a server, newest first
+func (m *RotationManager) GetLayerHistory(ctx context.Context, serverID uuid.UUID, limit int) ([]models.LayerHistoryEntry, error) {
+	history := []models.LayerHistoryEntry{}
+	if m.clickhouseClient == nil || limit <= 0 {
+		return history, nil
+	}
+
+	rows, err := m.clickhouseClient.Query(ctx, `
+		SELECT event_time, layer, map, team1_faction, team2_faction, player_count, source
+		FROM squad_aegis.server_layer_history
+		WHERE server_id = ?
+		ORDER BY event_time DESC
+		LIMIT ?
+	`, serverID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query layer history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.LayerHistoryEntry
+		var playerCount uint16
+		if err := rows.Scan(&entry.EventTime, &entry.Layer, &entry.Map, &entry.Team1Faction, &entry.Team2Faction, &playerCount, &entry.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan layer history: %w", err)
+		}
+		entry.PlayerCount = int(playerCount)
+		history = append(history, entry)
+	}
+
+	return history, rows.Err()
+}
+
+// selectionState loads everything the layer selection depends on
+func (m *RotationManager) selectionState(ctx context.Context, serverID uuid.UUID) (*models.ServerRotation, []models.LayerHistoryEntry, int, error) {
+	rotation, err := core.GetServerRotation(ctx, m.db, serverID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(rotation.Layers) == 0 {
+		return nil, nil, 0, ErrEmptyRotation
+	}
+
+	history, err := m.GetLayerHistory(ctx, serverID, historyWindow(rotation))
+	if err != nil {
+		log.Warn().Err(err).Str("serverId", serverID.String()).Msg("Failed to load layer history, ignoring no-repeat windows")
+		history = []models.LayerHistoryEntry{}
+	}
+
+	playerCount := 0
+	if players, err := squadRcon.NewSquadRcon(m.rconManager, serverID).GetServerPlayers(); err == nil {
+		playerCount = len(players.OnlinePlayers)
+	}
+
+	return rotation, history, playerCount, nil
+}
+
+func (m *RotationManager) recordLayer(ctx context.Context, serverID uuid.UUID, entry models.LayerHistoryEntry) error {
+	if m.clickhouseClient == nil {
+		return nil
+	}
+
+	return m.clickhouseClient.Exec(ctx, `
+		INSERT INTO squad_aegis.server_layer_history (
+			event_time, server_id, layer, map, team1_faction, team2_faction, player_count, source
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.EventTime, serverID, entry.Layer, entry.Map, entry.Team1Faction, entry.Team2Faction, uint16(entry.PlayerCount), entry.Source)
+}
+
+// historyWindow is the number of history entries the no-repeat windows look at
+func historyWindow(rotation *models.ServerRotation) int {
+	return max(rotation.MapRepeatWindow, rotation.FactionRepeatWindow, 1)
+}
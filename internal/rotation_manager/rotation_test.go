@@ -0,0 +1,187 @@
+package rotation_manager
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func layers(names ...string) []models.RotationLayer {
+	entries := make([]models.RotationLayer, len(names))
+	for i, name := range names {
+		entries[i] = models.RotationLayer{Layer: name}
+	}
+	return entries
+}
+
+func played(names ...string) []models.LayerHistoryEntry {
+	history := make([]models.LayerHistoryEntry, len(names))
+	for i, name := range names {
+		history[i] = models.LayerHistoryEntry{Layer: name, Map: MapFromLayer(name)}
+	}
+	return history
+}
+
+var noon = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestMapFromLayer(t *testing.T) {
+	tests := map[string]string{
+		"Narva_RAAS_v1":     "Narva",
+		"GooseBay_Invasion": "GooseBay",
+		"Jensens":           "Jensens",
+	}
+	for layer, expected := range tests {
+		if got := MapFromLayer(layer); got != expected {
+			t.Errorf("MapFromLayer(%q) = %q, expected %q", layer, got, expected)
+		}
+	}
+}
+
+func TestNextLayerCommand(t *testing.T) {
+	if got := NextLayerCommand(models.RotationLayer{Layer: "Narva_RAAS_v1"}); got != "AdminSetNextLayer Narva_RAAS_v1" {
+		t.Errorf("unexpected command %q", got)
+	}
+
+	entry := models.RotationLayer{Layer: "Narva_RAAS_v1", Team1Faction: "USA+CombinedArms", Team2Faction: "RGF+Armored"}
+	if got := NextLayerCommand(entry); got != "AdminSetNextLayer Narva_RAAS_v1 USA+CombinedArms RGF+Armored" {
+		t.Errorf("unexpected command %q", got)
+	}
+}
+
+func TestPickNextOrdered(t *testing.T) {
+	rotation := &models.ServerRotation{
+		Mode:            models.RotationModeOrdered,
+		MapRepeatWindow: 1,
+		Layers:          layers("Narva_RAAS_v1", "Gorodok_RAAS_v1", "Yehorivka_RAAS_v1"),
+		Position:        1,
+	}
+	rnd := rand.New(rand.NewSource(1))
+
+	index, ok := PickNext(rotation, nil, 50, noon, rnd)
+	if !ok || index != 1 {
+		t.Fatalf("expected index 1, got %d (ok=%v)", index, ok)
+	}
+
+	// Gorodok was just played, so the rotation skips ahead
+	index, ok = PickNext(rotation, played("Gorodok_AAS_v2"), 50, noon, rnd)
+	if !ok || index != 2 {
+		t.Fatalf("expected index 2, got %d (ok=%v)", index, ok)
+	}
+
+	// Position wraps around the end of the pool
+	rotation.Position = 2
+	index, ok = PickNext(rotation, played("Yehorivka_RAAS_v1"), 50, noon, rnd)
+	if !ok || index != 0 {
+		t.Fatalf("expected index 0, got %d (ok=%v)", index, ok)
+	}
+}
+
+func TestEligibleRules(t *testing.T) {
+	rotation := &models.ServerRotation{
+		Layers: []models.RotationLayer{
+			{Layer: "Skorpo_Seed_v1", MaxPlayers: 40},
+			{Layer: "Narva_RAAS_v1", MinPlayers: 40},
+			{Layer: "Mutaha_RAAS_v1", MinPlayers: 40, StartHour: intPtr(22), EndHour: intPtr(6)},
+			{Layer: "Fallujah_RAAS_v1", MinPlayers: 40, StartHour: intPtr(10), EndHour: intPtr(14)},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		players  int
+		now      time.Time
+		expected []int
+	}{
+		{"seeding", 10, noon, []int{0}},
+		{"live at noon", 80, noon, []int{1, 3}},
+		{"live at night", 80, time.Date(2025, 1, 1, 23, 0, 0, 0, time.UTC), []int{1, 2}},
+		{"live after midnight", 80, time.Date(2025, 1, 1, 5, 0, 0, 0, time.UTC), []int{1, 2}},
+		{"end hour is exclusive", 80, time.Date(2025, 1, 1, 14, 0, 0, 0, time.UTC), []int{1}},
+	}
+
+	for _, test := range tests {
+		got := Eligible(rotation, nil, test.players, test.now)
+		if len(got) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.expected[i] {
+				t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestEligibleRepeatWindows(t *testing.T) {
+	rotation := &models.ServerRotation{
+		MapRepeatWindow:     2,
+		FactionRepeatWindow: 1,
+		Layers: []models.RotationLayer{
+			{Layer: "Narva_RAAS_v1"},
+			{Layer: "Gorodok_RAAS_v1"},
+			{Layer: "Kohat_RAAS_v1", Team1Faction: "USA+Armored", Team2Faction: "MEA"},
+			{Layer: "Sumari_AAS_v1"},
+		},
+	}
+	history := []models.LayerHistoryEntry{
+		{Layer: "Narva_AAS_v1", Map: "Narva", Team1Faction: "USA+CombinedArms", Team2Faction: "RGF"},
+		{Layer: "Gorodok_AAS_v1", Map: "Gorodok"},
+		{Layer: "Sumari_AAS_v1", Map: "Sumari"},
+	}
+
+	got := Eligible(rotation, history, 50, noon)
+	if len(got) != 1 || got[0] != 3 {
+		t.Fatalf("expected only Sumari to be eligible, got %v", got)
+	}
+}
+
+func TestEligibleFallsBackWhenEverythingRepeats(t *testing.T) {
+	rotation := &models.ServerRotation{
+		MapRepeatWindow: 5,
+		Layers: []models.RotationLayer{
+			{Layer: "Narva_RAAS_v1"},
+			{Layer: "Gorodok_RAAS_v1", MinPlayers: 60},
+		},
+	}
+
+	got := Eligible(rotation, played("Gorodok_RAAS_v1", "Narva_RAAS_v1"), 20, noon)
+	if len(got) != 1 || got[0] != 0 {
+		t.Fatalf("expected the repeat window to be ignored but not the player rule, got %v", got)
+	}
+}
+
+func TestPickCandidatesUniqueMaps(t *testing.T) {
+	rotation := &models.ServerRotation{
+		Mode:   models.RotationModeWeighted,
+		Layers: layers("Narva_RAAS_v1", "Narva_AAS_v1", "Narva_Invasion_v1", "Gorodok_RAAS_v1", "Kohat_RAAS_v1"),
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		candidates := PickCandidates(rotation, nil, 50, noon, 3, rand.New(rand.NewSource(seed)))
+		if len(candidates) != 3 {
+			t.Fatalf("seed %d: expected 3 candidates, got %d", seed, len(candidates))
+		}
+
+		maps := map[string]bool{}
+		for _, candidate := range candidates {
+			mapName := MapFromLayer(candidate.Layer)
+			if maps[mapName] {
+				t.Fatalf("seed %d: map %s offered twice in %v", seed, mapName, candidates)
+			}
+			maps[mapName] = true
+		}
+	}
+
+	candidates := PickCandidates(rotation, nil, 50, noon, 10, rand.New(rand.NewSource(1)))
+	if len(candidates) != 3 {
+		t.Fatalf("expected one candidate per map, got %d", len(candidates))
+	}
+}
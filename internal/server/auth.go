@@ -551,3 +551,48 @@ func (s *Server) RequireAllPermissions(perms ...permissions.Permission) gin.Hand
 		c.Next()
 	}
 }
+
+// RequireGroupPermission checks if the user has been granted a specific
+// permission on a server group
+func (s *Server) RequireGroupPermission(perm permissions.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := s.getUserFromSession(c)
+		if user == nil {
+			responses.Unauthorized(c, "Unauthorized", nil)
+			c.Abort()
+			return
+		}
+
+		if user.SuperAdmin {
+			c.Next()
+			return
+		}
+
+		groupId, err := uuid.Parse(c.Param("groupId"))
+		if err != nil {
+			responses.BadRequest(c, "Invalid server group ID", nil)
+			c.Abort()
+			return
+		}
+
+		codes, err := core.GetUserServerGroupPermissions(c.Request.Context(), s.Dependencies.DB, groupId, user.Id)
+		if err != nil {
+			responses.InternalServerError(c, fmt.Errorf("failed to check permissions: %w", err), nil)
+			c.Abort()
+			return
+		}
+
+		userPerms := make([]permissions.Permission, 0, len(codes))
+		for _, code := range codes {
+			userPerms = append(userPerms, permissions.Permission(code))
+		}
+
+		if !permissions.EvaluatePermission(userPerms, perm) {
+			responses.Forbidden(c, "You don't have the required permission", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"errors"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -44,6 +46,7 @@ func (s *Server) BanListsCreate(c *gin.Context) {
 		Name:        request.Name,
 		Description: request.Description,
 		IsRemote:    false, // Default to local ban list
+		Federated:   request.Federated,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -103,6 +106,7 @@ func (s *Server) BanListsUpdate(c *gin.Context) {
 	updateData := map[string]interface{}{
 		"name":        request.Name,
 		"description": request.Description,
+		"federated":   request.Federated,
 	}
 
 	err = core.UpdateBanList(c.Request.Context(), s.Dependencies.DB, banListId, updateData)
@@ -283,6 +287,10 @@ func (s *Server) RemoteBanSourcesCreate(c *gin.Context) {
 		responses.BadRequest(c, "Source URL is required", &gin.H{"error": "Source URL is required"})
 		return
 	}
+	if err := validateRemoteBanSourceFeed(&request.Format, request.PublicKey, request.SignatureURL); err != nil {
+		responses.BadRequest(c, "Invalid remote ban source", &gin.H{"error": err.Error()})
+		return
+	}
 
 	source := &models.RemoteBanSource{
 		ID:                  uuid.New(),
@@ -290,6 +298,9 @@ func (s *Server) RemoteBanSourcesCreate(c *gin.Context) {
 		URL:                 request.URL,
 		SyncEnabled:         request.SyncEnabled,
 		SyncIntervalMinutes: request.SyncIntervalMinutes,
+		Format:              request.Format,
+		PublicKey:           optionalString(request.PublicKey),
+		SignatureURL:        optionalString(request.SignatureURL),
 		CreatedAt:           time.Now(),
 		UpdatedAt:           time.Now(),
 	}
@@ -329,12 +340,22 @@ func (s *Server) RemoteBanSourcesUpdate(c *gin.Context) {
 		responses.BadRequest(c, "Source URL is required", &gin.H{"error": "Source URL is required"})
 		return
 	}
+	if err := validateRemoteBanSourceFeed(&request.Format, request.PublicKey, request.SignatureURL); err != nil {
+		responses.BadRequest(c, "Invalid remote ban source", &gin.H{"error": err.Error()})
+		return
+	}
 
 	updateData := map[string]interface{}{
 		"name":                  request.Name,
 		"url":                   request.URL,
 		"sync_enabled":          request.SyncEnabled,
 		"sync_interval_minutes": request.SyncIntervalMinutes,
+		"format":                request.Format,
+		"public_key":            optionalString(request.PublicKey),
+		"signature_url":         optionalString(request.SignatureURL),
+		// The feed may need to be read differently now, so fetch it in full next time
+		"etag":          nil,
+		"last_modified": nil,
 	}
 
 	err = core.UpdateRemoteBanSource(c.Request.Context(), s.Dependencies.DB, sourceId, updateData)
@@ -363,3 +384,62 @@ func (s *Server) RemoteBanSourcesDelete(c *gin.Context) {
 
 	responses.Success(c, "Remote ban source deleted successfully", nil)
 }
+
+// RemoteBanSourceSyncs lists the recent syncs of a remote ban source
+func (s *Server) RemoteBanSourceSyncs(c *gin.Context) {
+	sourceId, err := uuid.Parse(c.Param("sourceId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid source ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 50
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			responses.BadRequest(c, "Invalid limit", &gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = min(limit, 500)
+	}
+
+	runs, err := core.GetRemoteBanSyncRuns(c.Request.Context(), s.Dependencies.DB, sourceId, limit)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Remote ban source syncs fetched successfully", &gin.H{
+		"syncs": runs,
+	})
+}
+
+// validateRemoteBanSourceFeed checks the feed options of a remote ban source,
+// defaulting the format to auto
+func validateRemoteBanSourceFeed(format *string, publicKey, signatureURL string) error {
+	switch *format {
+	case "":
+		*format = models.RemoteBanFormatAuto
+	case models.RemoteBanFormatAuto, models.RemoteBanFormatCSV, models.RemoteBanFormatText, models.RemoteBanFormatJSON:
+	default:
+		return errors.New("format must be auto, csv, text or json")
+	}
+
+	if publicKey != "" {
+		if _, err := core.ParseRemoteBanPublicKey(publicKey); err != nil {
+			return err
+		}
+	} else if signatureURL != "" {
+		return errors.New("a signature URL needs a public key to verify it with")
+	}
+
+	return nil
+}
+
+// optionalString stores empty strings as NULL
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
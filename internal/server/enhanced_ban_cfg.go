@@ -1,11 +1,8 @@
 package server
 
 import (
-	"bufio"
 	"database/sql"
-	"encoding/csv"
 	"fmt"
-	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -59,7 +56,7 @@ func (s *Server) generateServerSpecificBans(c *gin.Context, serverId uuid.UUID,
 		SELECT sb.steam_id, sb.reason, sb.duration, sb.created_at, sb.admin_id, u.username, u.steam_id
 		FROM server_bans sb
 		LEFT JOIN users u ON sb.admin_id = u.id
-		WHERE sb.server_id = $1 AND sb.ban_list_id IS NULL
+		WHERE sb.server_id = $1 AND sb.ban_list_id IS NULL AND sb.lifted_at IS NULL AND sb.steam_id IS NOT NULL
 	`, serverId)
 	if err != nil {
 		return err
@@ -160,157 +157,25 @@ func (s *Server) processBanRows(rows *sql.Rows, banCfg *strings.Builder, now tim
 	return nil
 }
 
+// appendRemoteBans adds the bans of enabled remote ban sources, as of their
+// last sync. Serving the synced lists keeps signature checks and ignored Steam
+// IDs in effect, and spares the sources a download per request.
 func (s *Server) appendRemoteBans(c *gin.Context, banCfg *strings.Builder, now time.Time) error {
-	// Get enabled remote ban sources
-	sources, err := core.GetRemoteBanSources(c.Request.Context(), s.Dependencies.DB)
-	if err != nil {
-		return err
-	}
-
-	for _, source := range sources {
-		if !source.SyncEnabled {
-			continue
-		}
-
-		err = s.fetchAndProcessRemoteBans(c, source.URL, banCfg, now)
-		if err != nil {
-			// Log error but continue with other sources
-			fmt.Printf("Warning: Failed to fetch from %s: %v\n", source.URL, err)
-			continue
-		}
-	}
-
-	return nil
-}
-
-func (s *Server) fetchAndProcessRemoteBans(c *gin.Context, url string, banCfg *strings.Builder, now time.Time) error {
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	resp, err := client.Get(url)
+	rows, err := s.Dependencies.DB.QueryContext(c.Request.Context(), `
+		SELECT sb.steam_id, sb.reason, sb.duration, sb.created_at, sb.admin_id, u.username, u.steam_id
+		FROM server_bans sb
+		JOIN ban_lists bl ON sb.ban_list_id = bl.id
+		LEFT JOIN users u ON sb.admin_id = u.id
+		WHERE bl.is_remote
+		AND bl.remote_url IN (SELECT url FROM remote_ban_sources WHERE sync_enabled)
+		AND sb.lifted_at IS NULL AND sb.steam_id IS NOT NULL
+	`)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
-	}
-
-	// Determine format based on content type or URL
-	contentType := resp.Header.Get("Content-Type")
-
-	if strings.Contains(contentType, "text/csv") || strings.HasSuffix(url, ".csv") {
-		return s.processCSVBans(c, resp.Body, banCfg, now)
-	} else {
-		// Assume text format (steamid:timestamp)
-		return s.processTextBans(c, resp.Body, banCfg, now)
-	}
-}
-
-func (s *Server) processCSVBans(c *gin.Context, body io.Reader, banCfg *strings.Builder, now time.Time) error {
-	reader := csv.NewReader(body)
-
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		if len(record) < 1 {
-			continue
-		}
-
-		steamID := strings.TrimSpace(record[0])
-		if steamID == "" || steamID == "steam_id" { // Skip header
-			continue
-		}
-
-		// Check if this Steam ID is in the ignore list
-		isIgnored, err := core.IsIgnoredSteamID(c.Request.Context(), s.Dependencies.DB, steamID)
-		if err != nil {
-			// Log error but continue processing
-			log.Warn().Err(err).Str("steam_id", steamID).Msg("Failed to check if Steam ID is ignored, including ban anyway")
-		} else if isIgnored {
-			// Skip this ban if it's in the ignore list
-			continue
-		}
-
-		// For CSV format, we assume permanent bans unless specified otherwise
-		expiry := "0"
-		if len(record) > 3 && record[3] != "" {
-			if record[3] == "0" {
-				expiry = "0"
-			} else {
-				// If there's an expiry timestamp
-				if expiryTime, err := time.Parse(time.RFC3339, record[3]); err == nil {
-					if now.After(expiryTime) {
-						continue // Skip expired bans
-					}
-					expiry = strconv.FormatInt(expiryTime.Unix(), 10)
-				}
-			}
-		}
-
-		// Remote bans don't have admin info, so use "Remote" as admin name
-		banCfg.WriteString(fmt.Sprintf("Remote [SteamID 0] Banned:%s:%s //From remote source\n", steamID, expiry))
-	}
-
-	return nil
-}
-
-func (s *Server) processTextBans(c *gin.Context, body io.Reader, banCfg *strings.Builder, now time.Time) error {
-	scanner := bufio.NewScanner(body)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.Split(line, ":")
-		if len(parts) != 2 {
-			continue
-		}
-
-		steamID := strings.TrimSpace(parts[0])
-		expiryStr := strings.TrimSpace(parts[1])
-
-		if steamID == "" {
-			continue
-		}
-
-		// Check if this Steam ID is in the ignore list
-		isIgnored, err := core.IsIgnoredSteamID(c.Request.Context(), s.Dependencies.DB, steamID)
-		if err != nil {
-			// Log error but continue processing
-			log.Warn().Err(err).Str("steam_id", steamID).Msg("Failed to check if Steam ID is ignored, including ban anyway")
-		} else if isIgnored {
-			// Skip this ban if it's in the ignore list
-			continue
-		}
-
-		// Check if ban is expired
-		if expiryStr != "0" {
-			if expiryTime, err := strconv.ParseInt(expiryStr, 10, 64); err == nil {
-				if now.After(time.Unix(expiryTime, 0)) {
-					continue // Skip expired bans
-				}
-			}
-		} else {
-			expiryStr = "0"
-		}
-
-		// Remote bans don't have admin info, so use "Remote" as admin name
-		banCfg.WriteString(fmt.Sprintf("Remote [SteamID 0] Banned:%s:%s //From remote source\n", steamID, expiryStr))
-	}
+	defer rows.Close()
 
-	return scanner.Err()
+	return s.processBanRows(rows, banCfg, now)
 }
 
 // BanListCfg handles generating a ban config for a specific ban list
@@ -327,7 +192,7 @@ func (s *Server) BanListCfg(c *gin.Context) {
 		SELECT sb.steam_id, sb.reason, sb.duration, sb.created_at, sb.admin_id, u.username, u.steam_id
 		FROM server_bans sb
 		LEFT JOIN users u ON sb.admin_id = u.id
-		WHERE sb.ban_list_id = $1
+		WHERE sb.ban_list_id = $1 AND sb.lifted_at IS NULL AND sb.steam_id IS NOT NULL
 	`, banListId)
 	if err != nil {
 		responses.BadRequest(c, "Failed to query bans", &gin.H{"error": err.Error()})
@@ -0,0 +1,468 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+	"go.codycody31.dev/squad-aegis/internal/shared/config"
+)
+
+// federationKeyPrefix starts every federation API key, so leaked keys are easy to spot
+const federationKeyPrefix = "aegis_fed_"
+
+// maxFederatedBans bounds the federated bans returned for a peer
+const maxFederatedBans = 1000
+
+// Federation API Key Handlers
+
+// FederationKeysList lists the API keys issued to peers
+func (s *Server) FederationKeysList(c *gin.Context) {
+	keys, err := core.GetFederationAPIKeys(c.Request.Context(), s.Dependencies.DB)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Federation API keys fetched successfully", &gin.H{"keys": keys})
+}
+
+// FederationKeyCreate issues an API key to a peer. The key is only returned
+// once; Aegis keeps a hash of it.
+func (s *Server) FederationKeyCreate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	var request models.FederationAPIKeyCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		responses.BadRequest(c, "Peer name is required", &gin.H{"error": "Peer name is required"})
+		return
+	}
+
+	banListIDs := make([]uuid.UUID, 0, len(request.BanListIDs))
+	for _, id := range request.BanListIDs {
+		banListID, err := uuid.Parse(id)
+		if err != nil {
+			responses.BadRequest(c, "Invalid ban list ID", &gin.H{"error": err.Error()})
+			return
+		}
+		banListIDs = append(banListIDs, banListID)
+	}
+
+	keyBytes := make([]byte, 32)
+	if _, err := rand.Read(keyBytes); err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to generate key"})
+		return
+	}
+	apiKey := federationKeyPrefix + hex.EncodeToString(keyBytes)
+
+	key := &models.FederationAPIKey{
+		ID:         uuid.New(),
+		Name:       request.Name,
+		KeyHash:    hashFederationKey(apiKey),
+		KeyPrefix:  apiKey[:len(federationKeyPrefix)+6],
+		BanListIDs: banListIDs,
+		CreatedBy:  &user.Id,
+	}
+	if err := core.CreateFederationAPIKey(c.Request.Context(), s.Dependencies.DB, key); err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to store key"})
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), nil, &user.Id, "federation:key:create", map[string]interface{}{
+		"keyId":      key.ID.String(),
+		"name":       key.Name,
+		"banListIds": request.BanListIDs,
+	})
+
+	responses.Success(c, "Federation API key created successfully", &gin.H{"key": key, "api_key": apiKey})
+}
+
+// FederationKeyRevoke revokes an API key, cutting the peer off
+func (s *Server) FederationKeyRevoke(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	keyId, err := uuid.Parse(c.Param("keyId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid key ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := core.RevokeFederationAPIKey(c.Request.Context(), s.Dependencies.DB, keyId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Federation API key not found", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), nil, &user.Id, "federation:key:revoke", map[string]interface{}{
+		"keyId": keyId.String(),
+	})
+
+	responses.Success(c, "Federation API key revoked successfully", nil)
+}
+
+// Federation API Handlers, authenticated with a federation API key
+
+// AuthFederationKey checks the bearer token of a peer against the issued
+// federation API keys
+func (s *Server) AuthFederationKey(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	key, err := core.GetFederationAPIKeyByHash(c.Request.Context(), s.Dependencies.DB, hashFederationKey(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.Unauthorized(c, "Unauthorized", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if err := core.TouchFederationAPIKey(c.Request.Context(), s.Dependencies.DB, key.ID); err != nil {
+		log.Warn().Err(err).Str("keyId", key.ID.String()).Msg("Failed to record federation API key use")
+	}
+
+	c.Set("federationKey", key)
+}
+
+// FederationBanLists lists the federated ban lists the key of the peer can read
+func (s *Server) FederationBanLists(c *gin.Context) {
+	key := c.MustGet("federationKey").(*models.FederationAPIKey)
+
+	lists, err := core.GetFederatedBanLists(c.Request.Context(), s.Dependencies.DB, key)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Federated ban lists fetched successfully", &gin.H{
+		"community": federationCommunity(),
+		"ban_lists": lists,
+	})
+}
+
+// FederationBanListBans returns the active bans of a federated ban list
+func (s *Server) FederationBanListBans(c *gin.Context) {
+	key := c.MustGet("federationKey").(*models.FederationAPIKey)
+
+	banListId, err := uuid.Parse(c.Param("banListId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid ban list ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	lists, err := core.GetFederatedBanLists(c.Request.Context(), s.Dependencies.DB, key)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+	index := slices.IndexFunc(lists, func(list models.FederatedBanListInfo) bool { return list.ID == banListId })
+	if index < 0 {
+		// Lists that aren't shared with the key are reported as missing
+		responses.NotFound(c, "Ban list not found", nil)
+		return
+	}
+
+	community := federationCommunity()
+	bans, err := core.GetFederatedBanListExport(c.Request.Context(), s.Dependencies.DB, banListId, community)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Federated bans fetched successfully", &gin.H{
+		"community": community,
+		"ban_list":  lists[index],
+		"bans":      bans,
+	})
+}
+
+// Federation Peer Handlers
+
+// FederationPeersList lists the peers this instance pulls ban lists from
+func (s *Server) FederationPeersList(c *gin.Context) {
+	peers, err := core.GetFederationPeers(c.Request.Context(), s.Dependencies.DB)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Federation peers fetched successfully", &gin.H{"peers": peers})
+}
+
+// FederationPeerCreate adds a peer
+func (s *Server) FederationPeerCreate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	var request models.FederationPeerCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+	if request.APIKey == "" {
+		responses.BadRequest(c, "Invalid federation peer", &gin.H{"error": "API key is required"})
+		return
+	}
+
+	peer := &models.FederationPeer{
+		ID:                  uuid.New(),
+		Name:                strings.TrimSpace(request.Name),
+		URL:                 strings.TrimRight(strings.TrimSpace(request.URL), "/"),
+		APIKey:              strings.TrimSpace(request.APIKey),
+		TrustLevel:          request.TrustLevel,
+		MinDurationDays:     request.MinDurationDays,
+		RuleCategories:      cleanRuleCategories(request.RuleCategories),
+		SyncEnabled:         request.SyncEnabled,
+		SyncIntervalMinutes: request.SyncIntervalMinutes,
+	}
+	if err := validateFederationPeer(peer); err != nil {
+		responses.BadRequest(c, "Invalid federation peer", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := core.CreateFederationPeer(c.Request.Context(), s.Dependencies.DB, peer); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), nil, &user.Id, "federation:peer:create", map[string]interface{}{
+		"peerId":     peer.ID.String(),
+		"name":       peer.Name,
+		"url":        peer.URL,
+		"trustLevel": peer.TrustLevel,
+	})
+
+	responses.Success(c, "Federation peer created successfully", &gin.H{"peer": peer})
+}
+
+// FederationPeerUpdate changes a peer. The trust level and filters apply from
+// the next sync, which also enforces or drops the bans already received.
+func (s *Server) FederationPeerUpdate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	peerId, err := uuid.Parse(c.Param("peerId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid peer ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	peer, err := core.GetFederationPeerById(c.Request.Context(), s.Dependencies.DB, peerId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Federation peer not found", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	var request models.FederationPeerUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	peer.Name = strings.TrimSpace(request.Name)
+	peer.URL = strings.TrimRight(strings.TrimSpace(request.URL), "/")
+	if request.APIKey != "" {
+		peer.APIKey = strings.TrimSpace(request.APIKey)
+	}
+	peer.TrustLevel = request.TrustLevel
+	peer.MinDurationDays = request.MinDurationDays
+	peer.RuleCategories = cleanRuleCategories(request.RuleCategories)
+	peer.SyncEnabled = request.SyncEnabled
+	peer.SyncIntervalMinutes = request.SyncIntervalMinutes
+	if err := validateFederationPeer(peer); err != nil {
+		responses.BadRequest(c, "Invalid federation peer", &gin.H{"error": err.Error()})
+		return
+	}
+
+	updateData := map[string]interface{}{
+		"name":                  peer.Name,
+		"url":                   peer.URL,
+		"api_key":               peer.APIKey,
+		"trust_level":           peer.TrustLevel,
+		"min_duration_days":     peer.MinDurationDays,
+		"rule_categories":       pq.Array(peer.RuleCategories),
+		"sync_enabled":          peer.SyncEnabled,
+		"sync_interval_minutes": peer.SyncIntervalMinutes,
+		// Apply the new settings on the next sync
+		"last_synced_at": nil,
+	}
+	if err := core.UpdateFederationPeer(c.Request.Context(), s.Dependencies.DB, peerId, updateData); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), nil, &user.Id, "federation:peer:update", map[string]interface{}{
+		"peerId":          peerId.String(),
+		"name":            peer.Name,
+		"url":             peer.URL,
+		"trustLevel":      peer.TrustLevel,
+		"minDurationDays": peer.MinDurationDays,
+		"ruleCategories":  peer.RuleCategories,
+		"apiKeyChanged":   request.APIKey != "",
+	})
+
+	responses.Success(c, "Federation peer updated successfully", &gin.H{"peer": peer})
+}
+
+// FederationPeerDelete removes a peer, lifting every ban received from it
+func (s *Server) FederationPeerDelete(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	peerId, err := uuid.Parse(c.Param("peerId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid peer ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := core.DeleteFederationPeer(c.Request.Context(), s.Dependencies.DB, peerId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Federation peer not found", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), nil, &user.Id, "federation:peer:delete", map[string]interface{}{
+		"peerId": peerId.String(),
+	})
+
+	responses.Success(c, "Federation peer deleted successfully", nil)
+}
+
+// FederationPeerSync syncs a peer right away
+func (s *Server) FederationPeerSync(c *gin.Context) {
+	peerId, err := uuid.Parse(c.Param("peerId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid peer ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	peer, err := core.GetFederationPeerById(c.Request.Context(), s.Dependencies.DB, peerId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Federation peer not found", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	result, err := s.Dependencies.FederationSyncService.SyncPeerAndRecord(c.Request.Context(), peer)
+	if err != nil {
+		responses.BadRequest(c, "Failed to sync federation peer", &gin.H{"error": err.Error()})
+		return
+	}
+
+	responses.Success(c, "Federation peer synced successfully", &gin.H{"result": result})
+}
+
+// FederationPeerBans lists the bans received from a peer, revoked bans last
+func (s *Server) FederationPeerBans(c *gin.Context) {
+	peerId, err := uuid.Parse(c.Param("peerId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid peer ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 200
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			responses.BadRequest(c, "Invalid limit", &gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = min(limit, maxFederatedBans)
+	}
+
+	bans, err := core.GetFederatedBans(c.Request.Context(), s.Dependencies.DB, peerId, limit)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Federated bans fetched successfully", &gin.H{"bans": bans})
+}
+
+func validateFederationPeer(peer *models.FederationPeer) error {
+	if peer.Name == "" {
+		return errors.New("peer name is required")
+	}
+
+	parsed, err := url.Parse(peer.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("peer URL must be the http or https address of an Aegis instance")
+	}
+
+	switch peer.TrustLevel {
+	case "":
+		peer.TrustLevel = models.FederationTrustObserve
+	case models.FederationTrustFull, models.FederationTrustEvidence, models.FederationTrustObserve:
+	default:
+		return errors.New("trust_level must be full, evidence or observe")
+	}
+
+	if peer.MinDurationDays < 0 {
+		return errors.New("min_duration_days can't be negative")
+	}
+	if peer.SyncIntervalMinutes <= 0 {
+		peer.SyncIntervalMinutes = 60
+	}
+
+	return nil
+}
+
+// cleanRuleCategories trims rule categories and drops empty and repeated ones
+func cleanRuleCategories(categories []string) []string {
+	cleaned := []string{}
+	for _, category := range categories {
+		category = strings.TrimSpace(category)
+		if category != "" && !slices.ContainsFunc(cleaned, func(existing string) bool {
+			return strings.EqualFold(existing, category)
+		}) {
+			cleaned = append(cleaned, category)
+		}
+	}
+	return cleaned
+}
+
+// federationCommunity is the name peers see on the bans this instance shares
+func federationCommunity() string {
+	if config.Config.Federation.Community != "" {
+		return config.Config.Federation.Community
+	}
+	return config.Config.App.Url
+}
+
+func hashFederationKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
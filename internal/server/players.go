@@ -1462,7 +1462,8 @@ func (s *Server) getPlayerStatistics(c *gin.Context, playerID string, isSteamID
 // getPlayerRecentActivity retrieves recent player activity
 func (s *Server) getPlayerRecentActivity(c *gin.Context, playerID string, isSteamID bool, limit int) ([]PlayerActivity, error) {
 	// Combine multiple event types into a single activity feed
-	// We'll query died events, wounded events, and chat messages
+	// We'll query connections, deaths, chat messages, roles, squad leadership,
+	// deployables and destroyed vehicles
 
 	whereClause := "steam = ?"
 	if !isSteamID {
@@ -1498,11 +1499,60 @@ func (s *Server) getPlayerRecentActivity(c *gin.Context, playerID string, isStea
 		FROM squad_aegis.server_player_chat_messages
 		WHERE steam_id = ? OR eos_id = ?
 
+		UNION ALL
+
+		SELECT
+			event_time,
+			'role' as event_type,
+			concat('Selected role ', role) as description,
+			server_id
+		FROM squad_aegis.server_player_role_changed_events
+		WHERE player_steam = ? OR player_eos = ?
+
+		UNION ALL
+
+		SELECT
+			event_time,
+			'squad_leader' as event_type,
+			concat('Became leader of squad ', squad_id, ' on team ', team_id) as description,
+			server_id
+		FROM squad_aegis.server_squad_leader_changed_events
+		WHERE player_steam = ? OR player_eos = ?
+
+		UNION ALL
+
+		SELECT
+			event_time,
+			'deployable' as event_type,
+			concat(if(action = 'placed', 'Built ', 'Destroyed '), deployable) as description,
+			server_id
+		FROM squad_aegis.server_deployable_events
+		WHERE player_steam = ? OR player_eos = ?
+
+		UNION ALL
+
+		SELECT
+			event_time,
+			'vehicle_destroyed' as event_type,
+			concat('Destroyed ', vehicle, ' with ', weapon) as description,
+			server_id
+		FROM squad_aegis.server_vehicle_destroyed_events
+		WHERE attacker_steam = ? OR attacker_eos = ?
+
 		ORDER BY event_time DESC
 		LIMIT ?
 	`, whereClause)
 
-	rows, err := s.Dependencies.Clickhouse.Query(c.Request.Context(), query, playerID, playerID, playerID, playerID, playerID, limit)
+	rows, err := s.Dependencies.Clickhouse.Query(c.Request.Context(), query,
+		playerID,           // connections
+		playerID, playerID, // deaths
+		playerID, playerID, // chat
+		playerID, playerID, // roles
+		playerID, playerID, // squad leadership
+		playerID, playerID, // deployables
+		playerID, playerID, // vehicles destroyed
+		limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1751,6 +1801,7 @@ func (s *Server) getPlayerActiveBans(c *gin.Context, playerID string, isSteamID
 		LEFT JOIN servers s ON b.server_id = s.id
 		LEFT JOIN users u ON b.admin_id = u.id
 		WHERE b.steam_id = $1
+		AND b.lifted_at IS NULL
 		AND (b.duration = 0 OR b.created_at + (b.duration || ' days')::interval > NOW())
 		ORDER BY b.created_at DESC
 	`
@@ -2719,7 +2770,7 @@ func (s *Server) PlayerRelatedPlayers(c *gin.Context) {
 
 		// Check if this player is banned (duration 0 = permanent, otherwise check if created_at + duration > now)
 		if player.SteamID != "" {
-			banQuery := `SELECT EXISTS(SELECT 1 FROM server_bans WHERE steam_id = $1 AND (duration = 0 OR created_at + (duration || ' days')::interval > NOW()))`
+			banQuery := `SELECT EXISTS(SELECT 1 FROM server_bans WHERE steam_id = $1 AND lifted_at IS NULL AND (duration = 0 OR created_at + (duration || ' days')::interval > NOW()))`
 			var isBanned bool
 			if err := s.Dependencies.DB.QueryRow(banQuery, player.SteamID).Scan(&isBanned); err == nil {
 				player.IsBanned = isBanned
@@ -3123,7 +3174,7 @@ func (s *Server) PlayersAltGroups(c *gin.Context) {
 
 			// Check if this player is banned
 			if player.SteamID != "" {
-				banQuery := `SELECT EXISTS(SELECT 1 FROM server_bans WHERE steam_id = $1 AND (duration = 0 OR created_at + (duration || ' days')::interval > NOW()))`
+				banQuery := `SELECT EXISTS(SELECT 1 FROM server_bans WHERE steam_id = $1 AND lifted_at IS NULL AND (duration = 0 OR created_at + (duration || ' days')::interval > NOW()))`
 				var isBanned bool
 				if err := s.Dependencies.DB.QueryRow(banQuery, player.SteamID).Scan(&isBanned); err == nil {
 					player.IsBanned = isBanned
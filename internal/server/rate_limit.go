@@ -0,0 +1,51 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// requestLimiter allows a key at most limit requests within a sliding window.
+// It lives in memory, so limits are per instance and reset on restart.
+type requestLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+}
+
+func newRequestLimiter(limit int, window time.Duration) *requestLimiter {
+	return &requestLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+// allow records a request for the key. When the key is over its limit the
+// request is not recorded, and the time until the next request is allowed is
+// returned.
+func (l *requestLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	for k, hits := range l.hits {
+		for len(hits) > 0 && !hits[0].After(cutoff) {
+			hits = hits[1:]
+		}
+		if len(hits) == 0 {
+			delete(l.hits, k)
+		} else {
+			l.hits[k] = hits
+		}
+	}
+
+	hits := l.hits[key]
+	if len(hits) >= l.limit {
+		return false, hits[0].Add(l.window).Sub(now)
+	}
+	l.hits[key] = append(hits, now)
+
+	return true, 0
+}
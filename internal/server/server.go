@@ -7,14 +7,18 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"time"
 
+	"go.codycody31.dev/squad-aegis/internal/admin_camera_tracker"
 	"go.codycody31.dev/squad-aegis/internal/clickhouse"
 	"go.codycody31.dev/squad-aegis/internal/core"
 	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/log_importer"
 	"go.codycody31.dev/squad-aegis/internal/logwatcher_manager"
 	"go.codycody31.dev/squad-aegis/internal/permissions"
 	"go.codycody31.dev/squad-aegis/internal/plugin_manager"
 	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
+	"go.codycody31.dev/squad-aegis/internal/rotation_manager"
 	"go.codycody31.dev/squad-aegis/internal/server/web"
 	"go.codycody31.dev/squad-aegis/internal/shared/config"
 	"go.codycody31.dev/squad-aegis/internal/storage"
@@ -26,27 +30,38 @@ import (
 
 type Server struct {
 	Dependencies *Dependencies
+
+	// Public ban appeal endpoints are rate limited per client IP, and submissions
+	// and replies per appealed ban
+	appealIPLimiter  *requestLimiter
+	appealBanLimiter *requestLimiter
 }
 
 type Dependencies struct {
-	DB                   *sql.DB
-	Clickhouse           *clickhouse.Client
-	Valkey               *valkey.Client
-	RconManager          *rcon_manager.RconManager
-	EventManager         *event_manager.EventManager
-	LogwatcherManager    *logwatcher_manager.LogwatcherManager
-	PluginManager        *plugin_manager.PluginManager
-	WorkflowManager      *workflow_manager.WorkflowManager
-	RemoteBanSyncService *core.RemoteBanSyncService
-	Storage              storage.Storage
-	PermissionService    *permissions.Service
-	PermissionRepo       *permissions.Repository
+	DB                    *sql.DB
+	Clickhouse            *clickhouse.Client
+	Valkey                *valkey.Client
+	RconManager           *rcon_manager.RconManager
+	EventManager          *event_manager.EventManager
+	LogwatcherManager     *logwatcher_manager.LogwatcherManager
+	PluginManager         *plugin_manager.PluginManager
+	WorkflowManager       *workflow_manager.WorkflowManager
+	RemoteBanSyncService  *core.RemoteBanSyncService
+	FederationSyncService *core.FederationSyncService
+	Storage               storage.Storage
+	LogImporter           *log_importer.Importer
+	RotationManager       *rotation_manager.RotationManager
+	AdminCameraTracker    *admin_camera_tracker.AdminCameraTracker
+	PermissionService     *permissions.Service
+	PermissionRepo        *permissions.Repository
 }
 
 func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 	router := gin.New()
 	server := &Server{
-		Dependencies: serverDependencies,
+		Dependencies:     serverDependencies,
+		appealIPLimiter:  newRequestLimiter(60, time.Hour),
+		appealBanLimiter: newRequestLimiter(5, time.Hour),
 	}
 
 	if config.Config.Log.ShowGin {
@@ -181,6 +196,34 @@ func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 			remoteBanSourcesGroup.POST("", server.RemoteBanSourcesCreate)
 			remoteBanSourcesGroup.PUT("/:sourceId", server.RemoteBanSourcesUpdate)
 			remoteBanSourcesGroup.DELETE("/:sourceId", server.RemoteBanSourcesDelete)
+			remoteBanSourcesGroup.GET("/:sourceId/syncs", server.RemoteBanSourceSyncs)
+		}
+
+		// Ban List Federation Management Routes
+		federationGroup := apiGroup.Group("/federation")
+		{
+			federationGroup.Use(server.AuthSession)
+			federationGroup.Use(server.AuthIsSuperAdmin())
+
+			federationGroup.GET("/keys", server.FederationKeysList)
+			federationGroup.POST("/keys", server.FederationKeyCreate)
+			federationGroup.DELETE("/keys/:keyId", server.FederationKeyRevoke)
+
+			federationGroup.GET("/peers", server.FederationPeersList)
+			federationGroup.POST("/peers", server.FederationPeerCreate)
+			federationGroup.PUT("/peers/:peerId", server.FederationPeerUpdate)
+			federationGroup.DELETE("/peers/:peerId", server.FederationPeerDelete)
+			federationGroup.POST("/peers/:peerId/sync", server.FederationPeerSync)
+			federationGroup.GET("/peers/:peerId/bans", server.FederationPeerBans)
+		}
+
+		// Federation API for peers, authenticated with a federation API key
+		federationAPIGroup := apiGroup.Group("/federation/v1")
+		{
+			federationAPIGroup.Use(server.AuthFederationKey)
+
+			federationAPIGroup.GET("/ban-lists", server.FederationBanLists)
+			federationAPIGroup.GET("/ban-lists/:banListId/bans", server.FederationBanListBans)
 		}
 
 		// Ignored Steam ID Management Routes
@@ -196,6 +239,16 @@ func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 			ignoredSteamIDsGroup.GET("/check/:steam_id", server.IgnoredSteamIDsCheck)
 		}
 
+		// Log shipper agents authenticate with their per-server push token
+		logIngestGroup := apiGroup.Group("/log-ingest/:serverId")
+		{
+			logIngestGroup.Use(server.AuthLogPushToken)
+
+			logIngestGroup.GET("", server.LogIngestPosition)
+			logIngestGroup.POST("", server.LogIngestLines)
+			logIngestGroup.GET("/ws", server.LogIngestWebSocket)
+		}
+
 		adminGroup := apiGroup.Group("/admin")
 		{
 			adminGroup.Use(server.AuthSession)
@@ -204,6 +257,30 @@ func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 			adminGroup.POST("/cleanup-expired-admins", server.ServerAdminsCleanupExpired)
 		}
 
+		// Server groups fan RCON commands, broadcasts, kicks and bans out to their member servers
+		serverGroupsGroup := apiGroup.Group("/server-groups")
+		{
+			serverGroupsGroup.Use(server.AuthSession)
+
+			serverGroupsGroup.GET("", server.ServerGroupsList)
+			serverGroupsGroup.POST("", server.AuthIsSuperAdmin(), server.ServerGroupsCreate)
+
+			groupGroup := serverGroupsGroup.Group("/:groupId")
+			{
+				groupGroup.GET("", server.ServerGroupGet)
+				groupGroup.PUT("", server.AuthIsSuperAdmin(), server.ServerGroupUpdate)
+				groupGroup.DELETE("", server.AuthIsSuperAdmin(), server.ServerGroupDelete)
+
+				groupGroup.GET("/permissions", server.AuthIsSuperAdmin(), server.ServerGroupPermissionsList)
+				groupGroup.PUT("/permissions/:userId", server.AuthIsSuperAdmin(), server.ServerGroupPermissionsUpdate)
+
+				groupGroup.POST("/rcon/execute", server.RequireGroupPermission(permissions.UIConsoleExecute), server.ServerGroupRconExecute)
+				groupGroup.POST("/broadcast", server.RequireGroupPermission(permissions.UIConsoleExecute), server.ServerGroupBroadcast)
+				groupGroup.POST("/kick", server.RequireGroupPermission(permissions.UIPlayersKick), server.ServerGroupKick)
+				groupGroup.POST("/ban", server.RequireGroupPermission(permissions.UIBansCreate), server.ServerGroupBan)
+			}
+		}
+
 		serversGroup := apiGroup.Group("/servers")
 		{
 			serversGroup.Use(server.AuthSession)
@@ -233,6 +310,27 @@ func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 
 				// Log watcher management
 				serverGroup.POST("/logwatcher/restart", server.RequirePermission(permissions.UISettingsManage), server.ServerLogwatcherRestart)
+				serverGroup.POST("/logwatcher/push-token", server.RequirePermission(permissions.UISettingsManage), server.ServerLogPushTokenCreate)
+				serverGroup.DELETE("/logwatcher/push-token", server.RequirePermission(permissions.UISettingsManage), server.ServerLogPushTokenDelete)
+
+				// Custom log parsers
+				serverGroup.GET("/log-parsers", server.RequirePermission(permissions.UISettingsView), server.ServerLogParsersList)
+				serverGroup.POST("/log-parsers", server.RequirePermission(permissions.UISettingsManage), server.ServerLogParserCreate)
+				serverGroup.POST("/log-parsers/test", server.RequirePermission(permissions.UISettingsView), server.ServerLogParserTest)
+				serverGroup.PUT("/log-parsers/:parserId", server.RequirePermission(permissions.UISettingsManage), server.ServerLogParserUpdate)
+				serverGroup.DELETE("/log-parsers/:parserId", server.RequirePermission(permissions.UISettingsManage), server.ServerLogParserDelete)
+
+				serverGroup.GET("/rotation", server.RequirePermission(permissions.UISettingsView), server.ServerRotationGet)
+				serverGroup.PUT("/rotation", server.RequirePermission(permissions.UISettingsManage), server.ServerRotationUpdate)
+				serverGroup.GET("/rotation/eligible", server.RequirePermission(permissions.UISettingsView), server.ServerRotationEligible)
+				serverGroup.GET("/rotation/history", server.RequirePermission(permissions.UISettingsView), server.ServerRotationHistory)
+				serverGroup.POST("/rotation/next", server.RequirePermission(permissions.UIConsoleExecute), server.ServerRotationSetNext)
+
+				serverGroup.GET("/admin-camera/sessions", server.RequirePermission(permissions.UIAuditLogsView), server.ServerAdminCameraSessions)
+				serverGroup.GET("/admin-camera/admins", server.RequirePermission(permissions.UIAuditLogsView), server.ServerAdminCameraAdmins)
+				serverGroup.GET("/admin-camera/admins/:adminId", server.RequirePermission(permissions.UIAuditLogsView), server.ServerAdminCameraAdmin)
+				serverGroup.GET("/admin-camera/settings", server.RequirePermission(permissions.UISettingsView), server.ServerAdminCameraSettingsGet)
+				serverGroup.PUT("/admin-camera/settings", server.RequirePermission(permissions.UISettingsManage), server.ServerAdminCameraSettingsUpdate)
 
 				// Live feeds for chat, connections, and teamkills
 				serverGroup.GET("/feeds", server.RequirePermission(permissions.UIFeedsView), server.ServerFeeds)
@@ -259,10 +357,22 @@ func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 				serverGroup.PUT("/admins/:adminId", server.AuthIsSuperAdmin(), server.ServerAdminsUpdate)
 				serverGroup.DELETE("/admins/:adminId", server.AuthIsSuperAdmin(), server.ServerAdminsRemove)
 
+				serverGroup.POST("/cfg-import/preview", server.AuthIsSuperAdmin(), server.ServerCfgImportPreview)
+				serverGroup.POST("/cfg-import/apply", server.AuthIsSuperAdmin(), server.ServerCfgImportApply)
+
 				serverGroup.GET("/bans", server.RequirePermission(permissions.UIBansView), server.ServerBansList)
 				serverGroup.POST("/bans", server.RequirePermission(permissions.UIBansCreate), server.ServerBansAdd)
 				serverGroup.PUT("/bans/:banId", server.RequirePermission(permissions.UIBansEdit), server.ServerBansUpdate)
 				serverGroup.DELETE("/bans/:banId", server.RequirePermission(permissions.UIBansDelete), server.ServerBansRemove)
+				serverGroup.GET("/bans/identity-matches", server.RequirePermission(permissions.UIBansView), server.ServerBanIdentityMatches)
+				serverGroup.GET("/linked-bans/settings", server.RequirePermission(permissions.UISettingsView), server.ServerLinkedBanSettingsGet)
+				serverGroup.PUT("/linked-bans/settings", server.RequirePermission(permissions.UISettingsManage), server.ServerLinkedBanSettingsUpdate)
+
+				// Ban appeal review queue
+				serverGroup.GET("/ban-appeals", server.RequirePermission(permissions.UIBanAppealsReview), server.ServerBanAppealsList)
+				serverGroup.GET("/ban-appeals/:appealId", server.RequirePermission(permissions.UIBanAppealsReview), server.ServerBanAppealGet)
+				serverGroup.POST("/ban-appeals/:appealId/comments", server.RequirePermission(permissions.UIBanAppealsReview), server.ServerBanAppealComment)
+				serverGroup.POST("/ban-appeals/:appealId/review", server.RequirePermission(permissions.UIBanAppealsReview), server.ServerBanAppealReview)
 
 				// Ban list subscription management
 				serverGroup.GET("/ban-list-subscriptions", server.RequirePermission(permissions.UIBanListsView), server.ServerBanListSubscriptions)
@@ -343,6 +453,9 @@ func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 					workflowsGroup.Use(server.RequirePermission(permissions.UIWorkflowsManage))
 					workflowsGroup.GET("", server.ServerWorkflowsList)
 					workflowsGroup.POST("", server.ServerWorkflowCreate)
+					workflowsGroup.GET("/export", server.ServerWorkflowsExport)
+					workflowsGroup.POST("/import", server.ServerWorkflowsImport)
+					workflowsGroup.POST("/validate", server.ServerWorkflowValidate)
 
 					workflowGroup := workflowsGroup.Group("/:workflowId")
 					{
@@ -351,6 +464,18 @@ func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 						workflowGroup.DELETE("", server.ServerWorkflowDelete)
 						workflowGroup.GET("/executions", server.ServerWorkflowExecutions)
 						workflowGroup.GET("/executions/stats", server.ServerWorkflowExecutionStats)
+						workflowGroup.POST("/simulate", server.ServerWorkflowSimulate)
+						workflowGroup.GET("/export", server.ServerWorkflowExport)
+						workflowGroup.POST("/clone", server.ServerWorkflowClone)
+
+						// Workflow revision history
+						revisionsGroup := workflowGroup.Group("/revisions")
+						{
+							revisionsGroup.GET("", server.ServerWorkflowRevisionsList)
+							revisionsGroup.GET("/diff", server.ServerWorkflowRevisionsDiff)
+							revisionsGroup.GET("/:revision", server.ServerWorkflowRevisionGet)
+							revisionsGroup.POST("/:revision/rollback", server.ServerWorkflowRollback)
+						}
 
 						// Workflow execution details and logs
 						executionGroup := workflowGroup.Group("/executions/:executionId")
@@ -358,6 +483,8 @@ func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 							executionGroup.GET("", server.ServerWorkflowExecutionGet)
 							executionGroup.GET("/logs", server.ServerWorkflowExecutionLogs)
 							executionGroup.GET("/messages", server.ServerWorkflowExecutionMessages)
+							executionGroup.POST("/cancel", server.ServerWorkflowExecutionCancel)
+							executionGroup.POST("/retry", server.ServerWorkflowExecutionRetry)
 						}
 
 						// Workflow variables
@@ -459,12 +586,23 @@ func NewRouter(serverDependencies *Dependencies) *gin.Engine {
 			sudoGroup.GET("/database/postgresql", server.GetPostgreSQLStats)
 			sudoGroup.GET("/database/clickhouse", server.GetClickHouseStats)
 			sudoGroup.POST("/database/optimize/:type", server.OptimizeDatabase)
+
+			// Historical log imports
+			sudoGroup.GET("/log-imports", server.GetLogImports)
+			sudoGroup.POST("/log-imports", server.CreateLogImport)
+			sudoGroup.GET("/log-imports/:jobId", server.GetLogImport)
+			sudoGroup.POST("/log-imports/:jobId/cancel", server.CancelLogImport)
 		}
 
 		// Public Routes for the server
 		apiGroup.GET("/servers/:serverId/admins/cfg", server.ServerAdminsCfg)
 		apiGroup.GET("/servers/:serverId/bans/cfg", server.ServerBansCfgEnhanced)
 		apiGroup.GET("/ban-lists/:banListId/cfg", server.BanListCfg)
+
+		// Public ban appeals, identified by Steam ID and ban ID
+		apiGroup.POST("/ban-appeals", server.BanAppealSubmit)
+		apiGroup.GET("/ban-appeals/status", server.BanAppealStatus)
+		apiGroup.POST("/ban-appeals/reply", server.BanAppealReply)
 	}
 
 	return router
@@ -0,0 +1,573 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/commands"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/permissions"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+	squadRcon "go.codycody31.dev/squad-aegis/internal/squad-rcon"
+)
+
+// serverGroupAction runs a group action against one member server. It returns
+// the RCON response and the changes recorded in the server's audit log entry.
+type serverGroupAction func(ctx context.Context, server *models.Server, r *squadRcon.SquadRcon) (string, map[string]interface{}, error)
+
+// ServerGroupsList handles listing server groups. Super admins see every
+// group, other users the groups they have been granted permissions on.
+func (s *Server) ServerGroupsList(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	var groups []*models.ServerGroup
+	var err error
+	if user.SuperAdmin {
+		groups, err = core.GetServerGroups(c.Request.Context(), s.Dependencies.DB)
+	} else {
+		groups, err = core.GetUserServerGroups(c.Request.Context(), s.Dependencies.DB, user.Id)
+	}
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Server groups fetched successfully", &gin.H{"groups": groups})
+}
+
+// ServerGroupsCreate handles creating a server group
+func (s *Server) ServerGroupsCreate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	var request models.ServerGroupCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		responses.BadRequest(c, "Server group name is required", &gin.H{"error": "Server group name is required"})
+		return
+	}
+
+	group := &models.ServerGroup{
+		ID:          uuid.New(),
+		Name:        request.Name,
+		Description: request.Description,
+		ServerIDs:   request.ServerIDs,
+	}
+	if group.ServerIDs == nil {
+		group.ServerIDs = []uuid.UUID{}
+	}
+
+	tx, err := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+	defer tx.Rollback()
+
+	if err := core.CreateServerGroup(c.Request.Context(), tx, group); err != nil {
+		s.respondServerGroupWriteError(c, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), nil, &user.Id, "group:create", map[string]interface{}{
+		"groupId":   group.ID.String(),
+		"name":      group.Name,
+		"serverIds": group.ServerIDs,
+	})
+
+	responses.Success(c, "Server group created successfully", &gin.H{"group": group})
+}
+
+// ServerGroupGet handles getting a server group
+func (s *Server) ServerGroupGet(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	group, ok := s.loadServerGroup(c)
+	if !ok {
+		return
+	}
+
+	if !user.SuperAdmin {
+		codes, err := core.GetUserServerGroupPermissions(c.Request.Context(), s.Dependencies.DB, group.ID, user.Id)
+		if err != nil {
+			responses.InternalServerError(c, err, nil)
+			return
+		}
+		if len(codes) == 0 {
+			responses.NotFound(c, "Server group not found", nil)
+			return
+		}
+	}
+
+	responses.Success(c, "Server group fetched successfully", &gin.H{"group": group})
+}
+
+// ServerGroupUpdate handles renaming a server group and replacing its members
+func (s *Server) ServerGroupUpdate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	group, ok := s.loadServerGroup(c)
+	if !ok {
+		return
+	}
+
+	var request models.ServerGroupUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.Name != nil {
+		name := strings.TrimSpace(*request.Name)
+		if name == "" {
+			responses.BadRequest(c, "Server group name is required", &gin.H{"error": "Server group name is required"})
+			return
+		}
+		group.Name = name
+	}
+	if request.Description != nil {
+		group.Description = request.Description
+	}
+	if request.ServerIDs != nil {
+		group.ServerIDs = request.ServerIDs
+	}
+
+	tx, err := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+	defer tx.Rollback()
+
+	if err := core.UpdateServerGroup(c.Request.Context(), tx, group); err != nil {
+		s.respondServerGroupWriteError(c, err)
+		return
+	}
+
+	if request.ServerIDs != nil {
+		if err := core.SetServerGroupServers(c.Request.Context(), tx, group.ID, group.ServerIDs); err != nil {
+			s.respondServerGroupWriteError(c, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), nil, &user.Id, "group:update", map[string]interface{}{
+		"groupId":   group.ID.String(),
+		"name":      group.Name,
+		"serverIds": group.ServerIDs,
+	})
+
+	responses.Success(c, "Server group updated successfully", &gin.H{"group": group})
+}
+
+// ServerGroupDelete handles deleting a server group
+func (s *Server) ServerGroupDelete(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	group, ok := s.loadServerGroup(c)
+	if !ok {
+		return
+	}
+
+	if err := core.DeleteServerGroup(c.Request.Context(), s.Dependencies.DB, group.ID); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), nil, &user.Id, "group:delete", map[string]interface{}{
+		"groupId": group.ID.String(),
+		"name":    group.Name,
+	})
+
+	responses.SimpleSuccess(c, "Server group deleted successfully")
+}
+
+// ServerGroupPermissionsList handles listing the users granted permissions on a server group
+func (s *Server) ServerGroupPermissionsList(c *gin.Context) {
+	group, ok := s.loadServerGroup(c)
+	if !ok {
+		return
+	}
+
+	grants, err := core.GetServerGroupPermissions(c.Request.Context(), s.Dependencies.DB, group.ID)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Server group permissions fetched successfully", &gin.H{"users": grants})
+}
+
+// ServerGroupPermissionsUpdate handles replacing the permissions of a user on
+// a server group. An empty list revokes the user's access to the group.
+func (s *Server) ServerGroupPermissionsUpdate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	group, ok := s.loadServerGroup(c)
+	if !ok {
+		return
+	}
+
+	userId, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid user ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var request models.ServerGroupPermissionsUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := core.SetUserServerGroupPermissions(c.Request.Context(), s.Dependencies.DB, group.ID, userId, request.Permissions); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			responses.NotFound(c, "User not found", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), nil, &user.Id, "group:permissions:update", map[string]interface{}{
+		"groupId":     group.ID.String(),
+		"userId":      userId.String(),
+		"permissions": request.Permissions,
+	})
+
+	responses.SimpleSuccess(c, "Server group permissions updated successfully")
+}
+
+// ServerGroupRconExecute handles running an RCON command on every server of a group
+func (s *Server) ServerGroupRconExecute(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	group, ok := s.loadServerGroup(c)
+	if !ok {
+		return
+	}
+
+	var request models.ServerGroupRconRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	commandParts := strings.Fields(request.Command)
+	if len(commandParts) == 0 {
+		responses.BadRequest(c, "Command cannot be empty", &gin.H{"error": "Command cannot be empty"})
+		return
+	}
+
+	if !user.SuperAdmin {
+		var commandFound *commands.CommandInfo
+		for _, cmd := range commands.CommandMatrix {
+			if strings.EqualFold(cmd.Name, commandParts[0]) && cmd.SupportsRCON {
+				commandFound = &cmd
+				break
+			}
+		}
+
+		codes, err := core.GetUserServerGroupPermissions(c.Request.Context(), s.Dependencies.DB, group.ID, user.Id)
+		if err != nil {
+			responses.InternalServerError(c, fmt.Errorf("failed to get user permissions: %w", err), nil)
+			return
+		}
+		userPerms := make([]permissions.Permission, 0, len(codes))
+		for _, code := range codes {
+			userPerms = append(userPerms, permissions.Permission(code))
+		}
+
+		if commandFound == nil || !permissions.EvaluatePermission(userPerms, permissions.Permission("rcon:"+commandFound.Category)) {
+			responses.BadRequest(c, "Invalid or unsupported command", &gin.H{"error": "Invalid or unsupported command"})
+			return
+		}
+	}
+
+	results := s.runServerGroupAction(c.Request.Context(), user, group, "group:rcon:execute", "server:rcon:execute",
+		map[string]interface{}{"command": request.Command},
+		func(ctx context.Context, server *models.Server, r *squadRcon.SquadRcon) (string, map[string]interface{}, error) {
+			response, err := r.ExecuteRaw(request.Command)
+			return response, map[string]interface{}{"command": request.Command}, err
+		})
+
+	responses.Success(c, "RCON command sent to server group", &gin.H{"results": results})
+}
+
+// ServerGroupBroadcast handles sending an AdminBroadcast to every server of a group
+func (s *Server) ServerGroupBroadcast(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	group, ok := s.loadServerGroup(c)
+	if !ok {
+		return
+	}
+
+	var request models.ServerGroupBroadcastRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	message := strings.TrimSpace(request.Message)
+	if message == "" {
+		responses.BadRequest(c, "Message cannot be empty", &gin.H{"error": "Message cannot be empty"})
+		return
+	}
+	command := "AdminBroadcast " + message
+
+	results := s.runServerGroupAction(c.Request.Context(), user, group, "group:rcon:broadcast", "server:rcon:execute",
+		map[string]interface{}{"message": message},
+		func(ctx context.Context, server *models.Server, r *squadRcon.SquadRcon) (string, map[string]interface{}, error) {
+			response, err := r.ExecuteRaw(command)
+			return response, map[string]interface{}{"command": command}, err
+		})
+
+	responses.Success(c, "Broadcast sent to server group", &gin.H{"results": results})
+}
+
+// ServerGroupKick handles kicking a player from every server of a group
+func (s *Server) ServerGroupKick(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	group, ok := s.loadServerGroup(c)
+	if !ok {
+		return
+	}
+
+	var request models.ServerGroupKickRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if !steamIDPattern.MatchString(request.SteamID) {
+		responses.BadRequest(c, "Invalid Steam ID format", &gin.H{"error": "Steam ID must be a 17 digit Steam64 ID"})
+		return
+	}
+
+	kickCommand := strings.TrimSpace(fmt.Sprintf("AdminKick \"%s\" %s", request.SteamID, request.Reason))
+
+	results := s.runServerGroupAction(c.Request.Context(), user, group, "group:rcon:kick", "server:rcon:command:kick",
+		map[string]interface{}{"steamId": request.SteamID, "reason": request.Reason},
+		func(ctx context.Context, server *models.Server, r *squadRcon.SquadRcon) (string, map[string]interface{}, error) {
+			response, err := r.ExecuteRaw(kickCommand)
+			return response, map[string]interface{}{"steamId": request.SteamID, "reason": request.Reason}, err
+		})
+
+	responses.Success(c, "Kick sent to server group", &gin.H{"results": results})
+}
+
+// ServerGroupBan handles banning a player on every server of a group. Each
+// server gets its own ban record, enforced according to its ban enforcement mode.
+func (s *Server) ServerGroupBan(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	group, ok := s.loadServerGroup(c)
+	if !ok {
+		return
+	}
+
+	var request models.ServerGroupBanRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.Duration < 0 {
+		responses.BadRequest(c, "Duration must be a positive integer", &gin.H{"error": "Duration must be a positive integer"})
+		return
+	}
+
+	if !steamIDPattern.MatchString(request.SteamID) {
+		responses.BadRequest(c, "Invalid Steam ID format", &gin.H{"error": "Steam ID must be a 17 digit Steam64 ID"})
+		return
+	}
+
+	groupChanges := map[string]interface{}{"steamId": request.SteamID, "reason": request.Reason, "duration": request.Duration}
+	if request.BanListID != nil && *request.BanListID != "" {
+		groupChanges["banListId"] = *request.BanListID
+	}
+
+	// Only the first ban saved is added to the ban list, so the list doesn't
+	// get one entry per server
+	var banListMu sync.Mutex
+	banListAdded := false
+
+	results := s.runServerGroupAction(c.Request.Context(), user, group, "group:ban:create", "server:ban:create", groupChanges,
+		func(ctx context.Context, server *models.Server, r *squadRcon.SquadRcon) (string, map[string]interface{}, error) {
+			banRequest := &models.ServerBanCreateRequest{
+				SteamID:      request.SteamID,
+				Reason:       request.Reason,
+				Duration:     request.Duration,
+				EvidenceText: request.EvidenceText,
+			}
+
+			banListMu.Lock()
+			if !banListAdded {
+				banRequest.BanListID = request.BanListID
+			}
+			_, auditData, err := s.createServerBan(ctx, server, user.Id, banRequest)
+			if err == nil && banRequest.BanListID != nil {
+				banListAdded = true
+			}
+			banListMu.Unlock()
+			if err != nil {
+				return "", nil, err
+			}
+
+			// The ban is stored either way; a failed RCON call only delays enforcement
+			if err := enforceServerBan(r, server, request.SteamID, request.Duration, request.Reason); err != nil {
+				log.Error().Err(err).Str("steamId", request.SteamID).Str("serverId", server.Id.String()).Msg("Failed to apply group ban via RCON")
+				return "Ban saved, RCON enforcement failed: " + err.Error(), auditData, nil
+			}
+
+			return "Ban saved", auditData, nil
+		})
+
+	responses.Success(c, "Ban applied to server group", &gin.H{"results": results})
+}
+
+// runServerGroupAction runs action concurrently on every member server of a
+// group and returns the per-server results in member order. Each successful
+// server action gets its own audit log entry pointing at the group entry,
+// which in turn lists the ids of the server entries.
+func (s *Server) runServerGroupAction(ctx context.Context, user *models.User, group *models.ServerGroup, groupAction, serverAction string, groupChanges map[string]interface{}, action serverGroupAction) []models.ServerGroupActionResult {
+	groupAuditID := uuid.New()
+	results := make([]models.ServerGroupActionResult, len(group.ServerIDs))
+
+	var wg sync.WaitGroup
+	for i, serverId := range group.ServerIDs {
+		wg.Add(1)
+		go func(i int, serverId uuid.UUID) {
+			defer wg.Done()
+
+			result := models.ServerGroupActionResult{ServerID: serverId}
+			defer func() { results[i] = result }()
+
+			server, err := core.GetServerById(ctx, s.Dependencies.DB, serverId, nil)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			result.ServerName = server.Name
+
+			ipAddress := server.IpAddress
+			if server.RconIpAddress != nil {
+				ipAddress = *server.RconIpAddress
+			}
+
+			if err := s.Dependencies.RconManager.ConnectToServer(serverId, ipAddress, server.RconPort, server.RconPassword); err != nil {
+				result.Error = fmt.Sprintf("failed to connect to RCON: %v", err)
+				return
+			}
+
+			r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId).
+				AsCaller(rcon_manager.UserCaller(user.Id), rcon_manager.PriorityHigh)
+
+			response, changes, err := action(ctx, server, r)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			result.Success = true
+			result.Response = response
+
+			auditID := uuid.New()
+			if changes == nil {
+				changes = map[string]interface{}{}
+			}
+			changes["groupId"] = group.ID.String()
+			changes["groupAuditId"] = groupAuditID.String()
+			s.CreateAuditLogWithID(ctx, auditID, &serverId, &user.Id, serverAction, changes)
+			result.AuditLogID = &auditID
+		}(i, serverId)
+	}
+	wg.Wait()
+
+	children := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		child := map[string]interface{}{
+			"serverId": result.ServerID.String(),
+			"success":  result.Success,
+		}
+		if result.AuditLogID != nil {
+			child["auditLogId"] = result.AuditLogID.String()
+		}
+		if result.Error != "" {
+			child["error"] = result.Error
+		}
+		children = append(children, child)
+	}
+
+	groupChanges["groupId"] = group.ID.String()
+	groupChanges["groupName"] = group.Name
+	groupChanges["children"] = children
+	s.CreateAuditLogWithID(ctx, groupAuditID, nil, &user.Id, groupAction, groupChanges)
+
+	return results
+}
+
+// loadServerGroup parses the groupId route parameter and loads the group,
+// responding with an error when it can't
+func (s *Server) loadServerGroup(c *gin.Context) (*models.ServerGroup, bool) {
+	groupId, err := uuid.Parse(c.Param("groupId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server group ID", &gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	group, err := core.GetServerGroup(c.Request.Context(), s.Dependencies.DB, groupId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Server group not found", nil)
+			return nil, false
+		}
+		responses.InternalServerError(c, err, nil)
+		return nil, false
+	}
+
+	return group, true
+}
+
+// respondServerGroupWriteError maps database errors from saving a server group
+// to responses
+func (s *Server) respondServerGroupWriteError(c *gin.Context, err error) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			responses.Conflict(c, "A server group with this name already exists", nil)
+			return
+		case "23503":
+			responses.BadRequest(c, "Unknown server in server group", &gin.H{"error": err.Error()})
+			return
+		}
+	}
+	responses.InternalServerError(c, err, nil)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"go.codycody31.dev/squad-aegis/internal/core"
 	"go.codycody31.dev/squad-aegis/internal/logwatcher_manager"
 	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
 	"go.codycody31.dev/squad-aegis/internal/server/responses"
 	squadRcon "go.codycody31.dev/squad-aegis/internal/squad-rcon"
 )
@@ -96,32 +98,7 @@ func (s *Server) ServersCreate(c *gin.Context) {
 	_ = s.Dependencies.RconManager.ConnectToServer(server.Id, ipAddress, server.RconPort, server.RconPassword)
 
 	// Connect to logwatcher if log configuration is provided
-	if server.LogSourceType != nil && server.LogFilePath != nil {
-		config := logwatcher_manager.LogSourceConfig{
-			Type:          logwatcher_manager.LogSourceType(*server.LogSourceType),
-			FilePath:      *server.LogFilePath,
-			ReadFromStart: false, // Default value
-		}
-
-		if server.LogHost != nil {
-			config.Host = *server.LogHost
-		}
-		if server.LogPort != nil {
-			config.Port = *server.LogPort
-		}
-		if server.LogUsername != nil {
-			config.Username = *server.LogUsername
-		}
-		if server.LogPassword != nil {
-			config.Password = *server.LogPassword
-		}
-		if server.LogPollFrequency != nil {
-			config.PollFrequency = time.Duration(*server.LogPollFrequency) * time.Second
-		}
-		if server.LogReadFromStart != nil {
-			config.ReadFromStart = *server.LogReadFromStart
-		}
-
+	if config, ok := s.logSourceConfig(c.Request.Context(), server); ok {
 		_ = s.Dependencies.LogwatcherManager.ConnectToServer(server.Id, config)
 	}
 
@@ -305,6 +282,23 @@ func (s *Server) ServerStatus(c *gin.Context) {
 		serverStatus["rcon"] = false
 	}
 
+	// Connection state as tracked by the RCON supervisor
+	if connection := s.rconConnectionStatus(serverUUID); connection != nil {
+		serverStatus["rcon_connection"] = connection
+	}
+
+	// RCON availability over the last day
+	if s.Dependencies.Clickhouse != nil {
+		end := time.Now()
+		start := end.Add(-24 * time.Hour)
+		initial, changes, err := getRconStateChanges(c.Request.Context(), s.Dependencies.Clickhouse, serverUUID, start, end)
+		if err != nil {
+			log.Error().Err(err).Str("server_id", serverUUID.String()).Msg("Failed to query RCON availability")
+		} else {
+			serverStatus["rcon_availability"] = rcon_manager.Availability(initial, changes, start, end)
+		}
+	}
+
 	responses.Success(c, "Server status fetched successfully", &gin.H{"status": serverStatus})
 }
 
@@ -497,10 +491,10 @@ func (s *Server) ServerUpdate(c *gin.Context) {
 		switch logSourceType {
 		case "":
 			request.LogSourceType = nil
-		case "local", "sftp", "ftp":
+		case "local", "sftp", "ftp", "push":
 			request.LogSourceType = &logSourceType
 		default:
-			responses.BadRequest(c, "Invalid log source type", &gin.H{"error": "log_source_type must be one of: local, sftp, ftp"})
+			responses.BadRequest(c, "Invalid log source type", &gin.H{"error": "log_source_type must be one of: local, sftp, ftp, push"})
 			return
 		}
 	}
@@ -563,32 +557,7 @@ func (s *Server) ServerUpdate(c *gin.Context) {
 	_ = s.Dependencies.RconManager.ConnectToServer(server.Id, ipAddress, server.RconPort, server.RconPassword)
 
 	// Reconnect logwatcher if log configuration is provided
-	if server.LogSourceType != nil && server.LogFilePath != nil {
-		config := logwatcher_manager.LogSourceConfig{
-			Type:          logwatcher_manager.LogSourceType(*server.LogSourceType),
-			FilePath:      *server.LogFilePath,
-			ReadFromStart: false, // Default value
-		}
-
-		if server.LogHost != nil {
-			config.Host = *server.LogHost
-		}
-		if server.LogPort != nil {
-			config.Port = *server.LogPort
-		}
-		if server.LogUsername != nil {
-			config.Username = *server.LogUsername
-		}
-		if server.LogPassword != nil {
-			config.Password = *server.LogPassword
-		}
-		if server.LogPollFrequency != nil {
-			config.PollFrequency = time.Duration(*server.LogPollFrequency) * time.Second
-		}
-		if server.LogReadFromStart != nil {
-			config.ReadFromStart = *server.LogReadFromStart
-		}
-
+	if config, ok := s.logSourceConfig(c.Request.Context(), server); ok {
 		_ = s.Dependencies.LogwatcherManager.ConnectToServer(server.Id, config)
 	} else {
 		// Disconnect from logwatcher if log configuration is removed
@@ -628,7 +597,8 @@ func (s *Server) ServerLogwatcherRestart(c *gin.Context) {
 	}
 
 	// Check if server has log watcher configuration
-	if server.LogSourceType == nil || server.LogFilePath == nil {
+	config, ok := s.logSourceConfig(c.Request.Context(), server)
+	if !ok {
 		responses.BadRequest(c, "Server does not have log watcher configuration", &gin.H{"error": "No log configuration found"})
 		return
 	}
@@ -643,13 +613,52 @@ func (s *Server) ServerLogwatcherRestart(c *gin.Context) {
 
 	// Then reconnect to the log watcher with current configuration
 	log.Info().Str("server_id", serverId.String()).Msg("Reconnecting to log watcher")
-	
+
+	err = s.Dependencies.LogwatcherManager.ConnectToServer(serverId, config)
+	if err != nil {
+		responses.BadRequest(c, "Failed to reconnect to log watcher", &gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Info().Str("server_id", serverId.String()).Msg("Log watcher connection restarted")
+
+	// Create audit log for the action
+	auditData := map[string]interface{}{
+		"serverId": serverId.String(),
+		"logType":  *server.LogSourceType,
+		"logPath":  config.FilePath,
+	}
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:logwatcher:restart", auditData)
+
+	responses.Success(c, "Log watcher restarted successfully", nil)
+}
+
+// logSourceConfig builds the log source configuration of a server. It reports
+// false when the server has no log source configured.
+func (s *Server) logSourceConfig(ctx context.Context, server *models.Server) (logwatcher_manager.LogSourceConfig, bool) {
+	if server.LogSourceType == nil {
+		return logwatcher_manager.LogSourceConfig{}, false
+	}
+
 	config := logwatcher_manager.LogSourceConfig{
 		Type:          logwatcher_manager.LogSourceType(*server.LogSourceType),
-		FilePath:      *server.LogFilePath,
 		ReadFromStart: false, // Default value
 	}
 
+	if config.Type == logwatcher_manager.LogSourceTypePush {
+		// Resume after the last line the agent got an acknowledgement for
+		if push, err := core.GetServerLogPush(ctx, s.Dependencies.DB, server.Id); err == nil {
+			config.PushSequence = push.LastSequence
+			config.PushOffset = push.LastOffset
+		}
+		return config, true
+	}
+
+	if server.LogFilePath == nil {
+		return logwatcher_manager.LogSourceConfig{}, false
+	}
+	config.FilePath = *server.LogFilePath
+
 	if server.LogHost != nil {
 		config.Host = *server.LogHost
 	}
@@ -669,21 +678,5 @@ func (s *Server) ServerLogwatcherRestart(c *gin.Context) {
 		config.ReadFromStart = *server.LogReadFromStart
 	}
 
-	err = s.Dependencies.LogwatcherManager.ConnectToServer(serverId, config)
-	if err != nil {
-		responses.BadRequest(c, "Failed to reconnect to log watcher", &gin.H{"error": err.Error()})
-		return
-	}
-
-	log.Info().Str("server_id", serverId.String()).Msg("Log watcher connection restarted")
-
-	// Create audit log for the action
-	auditData := map[string]interface{}{
-		"serverId": serverId.String(),
-		"logType":  *server.LogSourceType,
-		"logPath":  *server.LogFilePath,
-	}
-	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:logwatcher:restart", auditData)
-
-	responses.Success(c, "Log watcher restarted successfully", nil)
+	return config, true
 }
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/admin_camera_tracker"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+)
+
+const (
+	// maxAdminCameraSessions bounds the sessions returned by the sessions endpoint
+	maxAdminCameraSessions = 500
+	// maxAdminCameraDays bounds how far back the admin camera endpoints look
+	maxAdminCameraDays = 365
+)
+
+// ServerAdminCameraSessions lists the admin camera sessions of a server,
+// optionally for a single admin
+func (s *Server) ServerAdminCameraSessions(c *gin.Context) {
+	serverId, since, ok := s.adminCameraRequest(c)
+	if !ok {
+		return
+	}
+
+	limit := 100
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			responses.BadRequest(c, "Invalid limit", &gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = min(limit, maxAdminCameraSessions)
+	}
+
+	sessions, err := s.Dependencies.AdminCameraTracker.GetSessions(c.Request.Context(), serverId, strings.TrimSpace(c.Query("admin")), since, limit)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Admin camera sessions fetched successfully", &gin.H{"sessions": sessions})
+}
+
+// ServerAdminCameraAdmins aggregates the admin camera use of a server per admin
+func (s *Server) ServerAdminCameraAdmins(c *gin.Context) {
+	serverId, since, ok := s.adminCameraRequest(c)
+	if !ok {
+		return
+	}
+
+	admins, err := s.Dependencies.AdminCameraTracker.GetAdminSummaries(c.Request.Context(), serverId, "", since)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	totals := models.AdminCameraAdminSummary{}
+	for _, admin := range admins {
+		totals.Sessions += admin.Sessions
+		totals.TotalSeconds += admin.TotalSeconds
+		totals.LongestSeconds = max(totals.LongestSeconds, admin.LongestSeconds)
+		totals.SessionsOnTeam += admin.SessionsOnTeam
+		totals.KillsAfter += admin.KillsAfter
+		totals.GhostingSuspected += admin.GhostingSuspected
+		totals.AlertedSessions += admin.AlertedSessions
+		if admin.LastSessionStartedAt.After(totals.LastSessionStartedAt) {
+			totals.LastSessionStartedAt = admin.LastSessionStartedAt
+		}
+	}
+	if totals.Sessions > 0 {
+		totals.AverageSeconds = totals.TotalSeconds / float64(totals.Sessions)
+	}
+
+	responses.Success(c, "Admin camera summary fetched successfully", &gin.H{"admins": admins, "totals": totals})
+}
+
+// ServerAdminCameraAdmin returns the admin camera summary and recent sessions
+// of one admin, identified by EOS or Steam ID
+func (s *Server) ServerAdminCameraAdmin(c *gin.Context) {
+	serverId, since, ok := s.adminCameraRequest(c)
+	if !ok {
+		return
+	}
+
+	adminId := strings.TrimSpace(c.Param("adminId"))
+
+	summaries, err := s.Dependencies.AdminCameraTracker.GetAdminSummaries(c.Request.Context(), serverId, adminId, since)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+	if len(summaries) == 0 {
+		responses.NotFound(c, "No admin camera sessions found for this admin", nil)
+		return
+	}
+
+	sessions, err := s.Dependencies.AdminCameraTracker.GetSessions(c.Request.Context(), serverId, adminId, since, maxAdminCameraSessions)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Admin camera summary fetched successfully", &gin.H{"admin": summaries[0], "sessions": sessions})
+}
+
+// ServerAdminCameraSettingsGet returns the admin camera alert settings of a server
+func (s *Server) ServerAdminCameraSettingsGet(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := core.GetAdminCameraSettings(c.Request.Context(), s.Dependencies.DB, serverId)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Admin camera settings fetched successfully", &gin.H{"settings": settings})
+}
+
+// ServerAdminCameraSettingsUpdate changes the admin camera alert settings of a server
+func (s *Server) ServerAdminCameraSettingsUpdate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.AdminCameraSettingsUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.BadRequest(c, "Invalid request body", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateAdminCameraSettings(&req); err != nil {
+		responses.BadRequest(c, "Invalid admin camera settings", &gin.H{"error": err.Error()})
+		return
+	}
+
+	settings := &models.AdminCameraSettings{
+		ServerID:                     serverId,
+		AlertsEnabled:                req.AlertsEnabled,
+		LongSessionMinutes:           req.LongSessionMinutes,
+		FrequentSessionCount:         req.FrequentSessionCount,
+		FrequentSessionWindowMinutes: req.FrequentSessionWindowMinutes,
+		KillWindowMinutes:            req.KillWindowMinutes,
+		GhostingKillThreshold:        req.GhostingKillThreshold,
+	}
+
+	if err := core.SaveAdminCameraSettings(c.Request.Context(), s.Dependencies.DB, settings); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:admin_camera:settings:update", map[string]interface{}{
+		"alertsEnabled":                settings.AlertsEnabled,
+		"longSessionMinutes":           settings.LongSessionMinutes,
+		"frequentSessionCount":         settings.FrequentSessionCount,
+		"frequentSessionWindowMinutes": settings.FrequentSessionWindowMinutes,
+		"killWindowMinutes":            settings.KillWindowMinutes,
+		"ghostingKillThreshold":        settings.GhostingKillThreshold,
+	})
+
+	responses.Success(c, "Admin camera settings updated successfully", &gin.H{"settings": settings})
+}
+
+// adminCameraRequest checks access to the server of an admin camera request
+// and parses the days query parameter, 30 by default
+func (s *Server) adminCameraRequest(c *gin.Context) (uuid.UUID, time.Time, bool) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return uuid.Nil, time.Time{}, false
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return uuid.Nil, time.Time{}, false
+	}
+
+	days := 30
+	if raw := c.Query("days"); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 || days > maxAdminCameraDays {
+			responses.BadRequest(c, "Invalid days", &gin.H{"error": fmt.Sprintf("days must be between 1 and %d", maxAdminCameraDays)})
+			return uuid.Nil, time.Time{}, false
+		}
+	}
+
+	return serverId, time.Now().UTC().AddDate(0, 0, -days), true
+}
+
+func validateAdminCameraSettings(req *models.AdminCameraSettingsUpdateRequest) error {
+	if req.LongSessionMinutes < 0 || req.FrequentSessionCount < 0 || req.GhostingKillThreshold < 0 {
+		return fmt.Errorf("thresholds cannot be negative")
+	}
+	if req.FrequentSessionCount > 0 && req.FrequentSessionWindowMinutes < 1 {
+		return fmt.Errorf("the frequent session window must be at least one minute")
+	}
+	if req.FrequentSessionWindowMinutes < 0 || req.FrequentSessionWindowMinutes > 24*60 {
+		return fmt.Errorf("the frequent session window must be between 0 and 1440 minutes")
+	}
+	if req.KillWindowMinutes < 1 || time.Duration(req.KillWindowMinutes)*time.Minute > admin_camera_tracker.MaxKillWindow {
+		return fmt.Errorf("the kill window must be between 1 and %d minutes", int(admin_camera_tracker.MaxKillWindow.Minutes()))
+	}
+
+	return nil
+}
@@ -27,6 +27,12 @@ type AuditLogEntry struct {
 
 // CreateAuditLog creates a new audit log entry
 func (s *Server) CreateAuditLog(ctx context.Context, serverID *uuid.UUID, userID *uuid.UUID, action string, changes interface{}) {
+	s.CreateAuditLogWithID(ctx, uuid.New(), serverID, userID, action, changes)
+}
+
+// CreateAuditLogWithID creates a new audit log entry with a caller-chosen id,
+// so related entries can reference it before it is written
+func (s *Server) CreateAuditLogWithID(ctx context.Context, id uuid.UUID, serverID *uuid.UUID, userID *uuid.UUID, action string, changes interface{}) {
 	// Convert changes to JSON
 	changesJSON, err := json.Marshal(changes)
 	if err != nil {
@@ -38,7 +44,7 @@ func (s *Server) CreateAuditLog(ctx context.Context, serverID *uuid.UUID, userID
 	_, err = s.Dependencies.DB.ExecContext(ctx, `
 		INSERT INTO audit_logs (id, server_id, user_id, action, changes, timestamp)
 		VALUES ($1, $2, $3, $4, $5, $6)
-	`, uuid.New(), serverID, userID, action, changesJSON, time.Now())
+	`, id, serverID, userID, action, changesJSON, time.Now())
 
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create audit log")
@@ -0,0 +1,625 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+)
+
+const (
+	maxBanAppealStatementLength = 4000
+	maxBanAppealMessageLength   = 2000
+	maxBanAppealContactLength   = 200
+	maxBanAppealsListed         = 500
+)
+
+// errBanAppealNotFound is shown for any ban that can't be appealed, so the
+// public endpoints don't reveal which bans exist
+var errBanAppealNotFound = errors.New("no active ban matches this Steam ID and ban ID")
+
+// BanAppealSubmit handles a public ban appeal submission
+func (s *Server) BanAppealSubmit(c *gin.Context) {
+	var request models.BanAppealSubmitRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	ban, ok := s.appealedBan(c, request.SteamID, request.BanID, true)
+	if !ok {
+		return
+	}
+	if ban.Lifted {
+		responses.NotFound(c, "Ban not found", &gin.H{"error": errBanAppealNotFound.Error()})
+		return
+	}
+
+	statement := strings.TrimSpace(request.Statement)
+	if statement == "" || len(statement) > maxBanAppealStatementLength {
+		responses.BadRequest(c, "Invalid statement", &gin.H{"error": fmt.Sprintf("statement must be between 1 and %d characters", maxBanAppealStatementLength)})
+		return
+	}
+
+	var contact *string
+	if request.Contact != nil {
+		trimmed := strings.TrimSpace(*request.Contact)
+		if len(trimmed) > maxBanAppealContactLength {
+			responses.BadRequest(c, "Invalid contact", &gin.H{"error": fmt.Sprintf("contact must be at most %d characters", maxBanAppealContactLength)})
+			return
+		}
+		if trimmed != "" {
+			contact = &trimmed
+		}
+	}
+
+	appeal := &models.BanAppeal{
+		BanID:     ban.ID,
+		ServerID:  ban.ServerID,
+		SteamID:   strconv.FormatInt(ban.SteamID, 10),
+		Statement: statement,
+		Contact:   contact,
+	}
+
+	tx, err := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to begin transaction: %w", err), nil)
+		return
+	}
+	defer tx.Rollback()
+
+	if err := core.CreateBanAppeal(c.Request.Context(), tx, appeal); err != nil {
+		if errors.Is(err, core.ErrBanAppealOpen) {
+			responses.Conflict(c, "An appeal for this ban is already open", &gin.H{"error": err.Error()})
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if err := core.AddBanAppealEvent(c.Request.Context(), tx, appeal.ID, nil, "submitted", "", appeal.Status, nil); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to commit transaction: %w", err), nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &appeal.ServerID, nil, "server:ban_appeal:submit", map[string]interface{}{
+		"appealId": appeal.ID.String(),
+		"banId":    appeal.BanID.String(),
+		"steamId":  appeal.SteamID,
+	})
+
+	responses.Success(c, "Ban appeal submitted successfully", &gin.H{"appeal": publicBanAppeal(appeal)})
+}
+
+// BanAppealStatus returns the latest appeal of a ban and the comments the
+// player can see
+func (s *Server) BanAppealStatus(c *gin.Context) {
+	ban, ok := s.appealedBan(c, c.Query("steam_id"), c.Query("ban_id"), false)
+	if !ok {
+		return
+	}
+
+	appeal, err := core.GetLatestBanAppeal(c.Request.Context(), s.Dependencies.DB, ban.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.Success(c, "No appeal found for this ban", &gin.H{"appeal": nil, "comments": []*models.BanAppealComment{}, "lifted": ban.Lifted})
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	comments, err := core.GetBanAppealComments(c.Request.Context(), s.Dependencies.DB, appeal.ID, false)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Ban appeal fetched successfully", &gin.H{
+		"appeal":   publicBanAppeal(appeal),
+		"comments": publicBanAppealComments(comments),
+		"lifted":   ban.Lifted,
+	})
+}
+
+// BanAppealReply handles a player's answer to a request for more information.
+// The reply sends the appeal back to the review queue.
+func (s *Server) BanAppealReply(c *gin.Context) {
+	var request models.BanAppealReplyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	ban, ok := s.appealedBan(c, request.SteamID, request.BanID, true)
+	if !ok {
+		return
+	}
+
+	message := strings.TrimSpace(request.Message)
+	if message == "" || len(message) > maxBanAppealMessageLength {
+		responses.BadRequest(c, "Invalid message", &gin.H{"error": fmt.Sprintf("message must be between 1 and %d characters", maxBanAppealMessageLength)})
+		return
+	}
+
+	latest, err := core.GetLatestBanAppeal(c.Request.Context(), s.Dependencies.DB, ban.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "No appeal found for this ban", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	tx, err := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to begin transaction: %w", err), nil)
+		return
+	}
+	defer tx.Rollback()
+
+	appeal, err := core.GetBanAppealForUpdate(c.Request.Context(), tx, latest.ServerID, latest.ID)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+	if appeal.Status != models.BanAppealStatusMoreInfo {
+		responses.Conflict(c, "The appeal is not waiting for more information", &gin.H{"status": appeal.Status})
+		return
+	}
+
+	comment, err := core.AddBanAppealComment(c.Request.Context(), tx, appeal.ID, nil, message, false)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	appeal.Status = models.BanAppealStatusPending
+	if err := core.SetBanAppealStatus(c.Request.Context(), tx, appeal); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if err := core.AddBanAppealEvent(c.Request.Context(), tx, appeal.ID, nil, "player_replied", models.BanAppealStatusMoreInfo, appeal.Status, nil); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to commit transaction: %w", err), nil)
+		return
+	}
+
+	responses.Success(c, "Reply sent successfully", &gin.H{"appeal": publicBanAppeal(appeal), "comment": comment})
+}
+
+// appealedBan rate limits a public appeal request and looks up the ban it is
+// about. Writes are also limited per ban, so one ban can't flood the queue.
+// The response has been written when ok is false.
+func (s *Server) appealedBan(c *gin.Context, steamIdRaw, banIdRaw string, write bool) (*core.AppealableBan, bool) {
+	now := time.Now()
+	if allowed, retryAfter := s.appealIPLimiter.allow(c.ClientIP(), now); !allowed {
+		tooManyAppealRequests(c, retryAfter)
+		return nil, false
+	}
+
+	steamId, err := strconv.ParseInt(strings.TrimSpace(steamIdRaw), 10, 64)
+	if err != nil || steamId <= 0 {
+		responses.BadRequest(c, "Invalid Steam ID", &gin.H{"error": "steam_id must be a Steam64 ID"})
+		return nil, false
+	}
+
+	banId, err := uuid.Parse(strings.TrimSpace(banIdRaw))
+	if err != nil {
+		responses.BadRequest(c, "Invalid ban ID", &gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if write {
+		if allowed, retryAfter := s.appealBanLimiter.allow(fmt.Sprintf("%d:%s", steamId, banId), now); !allowed {
+			tooManyAppealRequests(c, retryAfter)
+			return nil, false
+		}
+	}
+
+	ban, err := core.GetAppealableBan(c.Request.Context(), s.Dependencies.DB, banId, steamId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Ban not found", &gin.H{"error": errBanAppealNotFound.Error()})
+			return nil, false
+		}
+		responses.InternalServerError(c, fmt.Errorf("failed to get ban: %w", err), nil)
+		return nil, false
+	}
+
+	return ban, true
+}
+
+func tooManyAppealRequests(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	responses.TooManyRequests(c, "Too many requests, try again later", &gin.H{"retry_after": seconds})
+}
+
+// publicBanAppeal strips the reviewer from an appeal shown to the player
+func publicBanAppeal(appeal *models.BanAppeal) *models.BanAppeal {
+	public := *appeal
+	public.ReviewerID = nil
+	public.ReviewerName = nil
+	return &public
+}
+
+// publicBanAppealComments hides which admin wrote each comment from the player
+func publicBanAppealComments(comments []*models.BanAppealComment) []*models.BanAppealComment {
+	public := make([]*models.BanAppealComment, 0, len(comments))
+	for _, comment := range comments {
+		stripped := *comment
+		stripped.UserID = nil
+		stripped.Username = nil
+		if comment.UserID != nil {
+			staff := "Staff"
+			stripped.Username = &staff
+		}
+		public = append(public, &stripped)
+	}
+	return public
+}
+
+// ServerBanAppealsList lists the ban appeals of a server, optionally by status
+func (s *Server) ServerBanAppealsList(c *gin.Context) {
+	serverId, ok := s.banAppealServer(c)
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && status != core.BanAppealStatusOpen && !isBanAppealStatus(status) {
+		responses.BadRequest(c, "Invalid status", &gin.H{"error": "status must be open, pending, more_info, accepted or denied"})
+		return
+	}
+
+	appeals, err := core.GetBanAppeals(c.Request.Context(), s.Dependencies.DB, serverId, status, maxBanAppealsListed)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	steamIDs := make([]string, 0, len(appeals))
+	for _, appeal := range appeals {
+		steamIDs = append(steamIDs, appeal.SteamID)
+	}
+	playerNames := s.lookupPlayerNamesBatch(c.Request.Context(), steamIDs)
+	for _, appeal := range appeals {
+		appeal.PlayerName = playerNames[appeal.SteamID]
+	}
+
+	responses.Success(c, "Ban appeals fetched successfully", &gin.H{"appeals": appeals})
+}
+
+// ServerBanAppealGet returns an appeal with its ban, comments and audit trail
+func (s *Server) ServerBanAppealGet(c *gin.Context) {
+	serverId, ok := s.banAppealServer(c)
+	if !ok {
+		return
+	}
+
+	appeal, ok := s.getServerBanAppeal(c, serverId)
+	if !ok {
+		return
+	}
+
+	ban, err := s.getServerBan(c.Request.Context(), s.Dependencies.DB, serverId, appeal.BanID)
+	if err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to get ban: %w", err), nil)
+		return
+	}
+	ban.Evidence, _ = s.loadBanEvidence(c.Request.Context(), ban.ID)
+
+	comments, err := core.GetBanAppealComments(c.Request.Context(), s.Dependencies.DB, appeal.ID, true)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	events, err := core.GetBanAppealEvents(c.Request.Context(), s.Dependencies.DB, appeal.ID)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if name, ok := s.lookupPlayerNamesBatch(c.Request.Context(), []string{appeal.SteamID})[appeal.SteamID]; ok {
+		appeal.PlayerName = name
+		ban.Name = name
+	}
+
+	responses.Success(c, "Ban appeal fetched successfully", &gin.H{
+		"appeal":   appeal,
+		"ban":      ban,
+		"comments": comments,
+		"events":   events,
+	})
+}
+
+// ServerBanAppealComment adds a reviewer comment to an appeal. Internal
+// comments are only shown to reviewers.
+func (s *Server) ServerBanAppealComment(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, ok := s.banAppealServer(c)
+	if !ok {
+		return
+	}
+
+	var request models.BanAppealCommentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	message := strings.TrimSpace(request.Message)
+	if message == "" || len(message) > maxBanAppealMessageLength {
+		responses.BadRequest(c, "Invalid message", &gin.H{"error": fmt.Sprintf("message must be between 1 and %d characters", maxBanAppealMessageLength)})
+		return
+	}
+
+	appeal, ok := s.getServerBanAppeal(c, serverId)
+	if !ok {
+		return
+	}
+
+	tx, err := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to begin transaction: %w", err), nil)
+		return
+	}
+	defer tx.Rollback()
+
+	comment, err := core.AddBanAppealComment(c.Request.Context(), tx, appeal.ID, &user.Id, message, request.Internal)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	err = core.AddBanAppealEvent(c.Request.Context(), tx, appeal.ID, &user.Id, "commented", "", "", map[string]interface{}{
+		"commentId": comment.ID.String(),
+		"internal":  request.Internal,
+	})
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to commit transaction: %w", err), nil)
+		return
+	}
+
+	username := user.Username
+	comment.Username = &username
+
+	responses.Success(c, "Comment added successfully", &gin.H{"comment": comment})
+}
+
+// ServerBanAppealReview changes the status of an open appeal. Accepting an
+// appeal lifts or shortens the ban through the same path as editing it.
+func (s *Server) ServerBanAppealReview(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, ok := s.banAppealServer(c)
+	if !ok {
+		return
+	}
+
+	appealId, err := uuid.Parse(c.Param("appealId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid appeal ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var request models.BanAppealReviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	comment := strings.TrimSpace(request.Comment)
+	if len(comment) > maxBanAppealMessageLength {
+		responses.BadRequest(c, "Invalid comment", &gin.H{"error": fmt.Sprintf("comment must be at most %d characters", maxBanAppealMessageLength)})
+		return
+	}
+
+	switch request.Status {
+	case models.BanAppealStatusMoreInfo:
+		if comment == "" {
+			responses.BadRequest(c, "A comment is required", &gin.H{"error": "tell the player what information is needed"})
+			return
+		}
+	case models.BanAppealStatusAccepted:
+		if request.Resolution != models.BanAppealResolutionLift && request.Resolution != models.BanAppealResolutionShorten {
+			responses.BadRequest(c, "Invalid resolution", &gin.H{"error": "resolution must be lift or shorten"})
+			return
+		}
+		if request.Resolution == models.BanAppealResolutionShorten && (request.Duration == nil || *request.Duration < 1) {
+			responses.BadRequest(c, "Invalid duration", &gin.H{"error": "shortening a ban requires a duration of at least 1 day"})
+			return
+		}
+	case models.BanAppealStatusDenied:
+	default:
+		responses.BadRequest(c, "Invalid status", &gin.H{"error": "status must be more_info, accepted or denied"})
+		return
+	}
+
+	tx, err := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to begin transaction: %w", err), nil)
+		return
+	}
+	defer tx.Rollback()
+
+	appeal, err := core.GetBanAppealForUpdate(c.Request.Context(), tx, serverId, appealId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Ban appeal not found", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if appeal.Status != models.BanAppealStatusPending && appeal.Status != models.BanAppealStatusMoreInfo {
+		responses.Conflict(c, "The appeal has already been resolved", &gin.H{"status": appeal.Status})
+		return
+	}
+
+	fromStatus := appeal.Status
+	details := map[string]interface{}{}
+	var banUpdate *serverBanUpdate
+
+	if request.Status == models.BanAppealStatusAccepted {
+		update := models.ServerBanUpdateRequest{}
+		if request.Resolution == models.BanAppealResolutionLift {
+			lifted := true
+			update.Lifted = &lifted
+		} else {
+			if appeal.BanDuration != 0 && *request.Duration >= appeal.BanDuration {
+				responses.BadRequest(c, "Invalid duration", &gin.H{"error": fmt.Sprintf("the new duration must be shorter than the current %d days", appeal.BanDuration)})
+				return
+			}
+			update.Duration = request.Duration
+			appeal.ResolvedDuration = request.Duration
+			details["oldDuration"] = appeal.BanDuration
+			details["newDuration"] = *request.Duration
+		}
+
+		banUpdate, err = s.updateServerBan(c.Request.Context(), tx, serverId, appeal.BanID, update)
+		if err != nil {
+			var updateErr *banRequestError
+			if errors.As(err, &updateErr) {
+				responses.BadRequest(c, updateErr.message, &gin.H{"error": updateErr.err.Error()})
+			} else {
+				responses.BadRequest(c, "Failed to update ban", &gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		resolution := request.Resolution
+		appeal.Resolution = &resolution
+		details["resolution"] = resolution
+	}
+
+	appeal.Status = request.Status
+	appeal.ReviewerID = &user.Id
+	if err := core.SetBanAppealStatus(c.Request.Context(), tx, appeal); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if comment != "" {
+		added, err := core.AddBanAppealComment(c.Request.Context(), tx, appeal.ID, &user.Id, comment, false)
+		if err != nil {
+			responses.InternalServerError(c, err, nil)
+			return
+		}
+		details["commentId"] = added.ID.String()
+	}
+
+	if err := core.AddBanAppealEvent(c.Request.Context(), tx, appeal.ID, &user.Id, "reviewed", fromStatus, appeal.Status, details); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to commit transaction: %w", err), nil)
+		return
+	}
+
+	// The ban is unbanned on the game server and audited only once the appeal
+	// and the ban update are both committed
+	var ban *models.ServerBan
+	if banUpdate != nil {
+		ban = s.finishServerBanUpdate(c.Request.Context(), user.Id, banUpdate, map[string]interface{}{
+			"appealId": appeal.ID.String(),
+		})
+	}
+
+	username := user.Username
+	appeal.ReviewerName = &username
+
+	auditData := map[string]interface{}{
+		"appealId":   appeal.ID.String(),
+		"banId":      appeal.BanID.String(),
+		"steamId":    appeal.SteamID,
+		"fromStatus": fromStatus,
+		"toStatus":   appeal.Status,
+	}
+	for key, value := range details {
+		auditData[key] = value
+	}
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:ban_appeal:review", auditData)
+
+	responses.Success(c, "Ban appeal reviewed successfully", &gin.H{"appeal": appeal, "ban": ban})
+}
+
+// banAppealServer parses the server of a reviewer request and checks the user
+// can access it. The response has been written when ok is false.
+func (s *Server) banAppealServer(c *gin.Context) (uuid.UUID, bool) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return uuid.Nil, false
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return uuid.Nil, false
+	}
+
+	return serverId, true
+}
+
+// getServerBanAppeal loads the appeal named in the route. The response has
+// been written when ok is false.
+func (s *Server) getServerBanAppeal(c *gin.Context, serverId uuid.UUID) (*models.BanAppeal, bool) {
+	appealId, err := uuid.Parse(c.Param("appealId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid appeal ID", &gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	appeal, err := core.GetBanAppeal(c.Request.Context(), s.Dependencies.DB, serverId, appealId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Ban appeal not found", nil)
+			return nil, false
+		}
+		responses.InternalServerError(c, err, nil)
+		return nil, false
+	}
+
+	return appeal, true
+}
+
+func isBanAppealStatus(status string) bool {
+	switch status {
+	case models.BanAppealStatusPending, models.BanAppealStatusMoreInfo, models.BanAppealStatusAccepted, models.BanAppealStatusDenied:
+		return true
+	}
+	return false
+}
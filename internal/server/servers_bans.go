@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +17,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/db"
 	"go.codycody31.dev/squad-aegis/internal/models"
 	"go.codycody31.dev/squad-aegis/internal/server/responses"
 	squadRcon "go.codycody31.dev/squad-aegis/internal/squad-rcon"
@@ -40,7 +43,7 @@ func (s *Server) ServerBansList(c *gin.Context) {
 
 	// Query the database for bans
 	rows, err := s.Dependencies.DB.QueryContext(c.Request.Context(), `
-		SELECT sb.id, sb.server_id, sb.admin_id, u.username, sb.steam_id, sb.reason, sb.duration, sb.rule_id, sr.title as rule_title,  sb.ban_list_id, bl.name as ban_list_name, sb.evidence_text, sb.created_at, sb.updated_at
+		SELECT sb.id, sb.server_id, sb.admin_id, u.username, sb.steam_id, sb.eos_id, sb.reason, sb.duration, sb.rule_id, sr.title as rule_title,  sb.ban_list_id, bl.name as ban_list_name, sb.evidence_text, sb.lifted_at, sb.extend_to_linked, sb.created_at, sb.updated_at
 		FROM server_bans sb
 		JOIN users u ON sb.admin_id = u.id
 		LEFT JOIN ban_lists bl ON sb.ban_list_id = bl.id
@@ -58,18 +61,22 @@ func (s *Server) ServerBansList(c *gin.Context) {
 	steamIDs := []string{}
 	for rows.Next() {
 		var ban models.ServerBan
-		var steamIDInt int64
+		var steamIDInt sql.NullInt64
+		var eosID sql.NullString
 		var ruleID sql.NullString
 		var ruleTitle sql.NullString
 		var banListID sql.NullString
 		var banListName sql.NullString
 		var evidenceText sql.NullString
+		var liftedAt sql.NullTime
+		var extendToLinked sql.NullBool
 		err := rows.Scan(
 			&ban.ID,
 			&ban.ServerID,
 			&ban.AdminID,
 			&ban.AdminName,
 			&steamIDInt,
+			&eosID,
 			&ban.Reason,
 			&ban.Duration,
 			&ruleID,
@@ -77,6 +84,8 @@ func (s *Server) ServerBansList(c *gin.Context) {
 			&banListID,
 			&banListName,
 			&evidenceText,
+			&liftedAt,
+			&extendToLinked,
 			&ban.CreatedAt,
 			&ban.UpdatedAt,
 		)
@@ -85,11 +94,14 @@ func (s *Server) ServerBansList(c *gin.Context) {
 			return
 		}
 
-		// Convert steamID from int64 to string
-		ban.SteamID = strconv.FormatInt(steamIDInt, 10)
+		// Convert steamID from int64 to string; bans on an EOS ID may have none
+		if steamIDInt.Valid {
+			ban.SteamID = strconv.FormatInt(steamIDInt.Int64, 10)
 
-		// Collect steam IDs for batch lookup
-		steamIDs = append(steamIDs, ban.SteamID)
+			// Collect steam IDs for batch lookup
+			steamIDs = append(steamIDs, ban.SteamID)
+		}
+		ban.EOSID = eosID.String
 
 		// Set rule ID if present
 		if ruleID.Valid {
@@ -114,6 +126,14 @@ func (s *Server) ServerBansList(c *gin.Context) {
 			ban.EvidenceText = &evidenceText.String
 		}
 
+		if liftedAt.Valid {
+			ban.LiftedAt = &liftedAt.Time
+		}
+
+		if extendToLinked.Valid {
+			ban.ExtendToLinked = &extendToLinked.Bool
+		}
+
 		// Calculate if ban is permanent and expiry date
 		ban.Permanent = ban.Duration == 0
 		if !ban.Permanent {
@@ -134,8 +154,8 @@ func (s *Server) ServerBansList(c *gin.Context) {
 		if name, ok := playerNames[bans[i].SteamID]; ok {
 			bans[i].Name = name
 		} else {
-			// Fallback to steam ID if no name found
-			bans[i].Name = bans[i].SteamID
+			// Fallback to the banned ID if no name found
+			bans[i].Name = banIdentifier(&bans[i])
 		}
 	}
 
@@ -168,119 +188,137 @@ func (s *Server) ServerBansAdd(c *gin.Context) {
 		return
 	}
 
-	// Validate request
-	if request.SteamID == "" {
-		responses.BadRequest(c, "Steam ID is required", &gin.H{"error": "Steam ID is required"})
+	banID, auditData, err := s.createServerBan(c.Request.Context(), server, user.Id, &request)
+	if err != nil {
+		var requestErr *banRequestError
+		if errors.As(err, &requestErr) {
+			responses.BadRequest(c, requestErr.message, &gin.H{"error": requestErr.err.Error()})
+		} else {
+			responses.BadRequest(c, "Failed to create ban", &gin.H{"error": err.Error()})
+		}
 		return
 	}
 
+	// Apply the ban via RCON if the server is online
+	playerID := request.SteamID
+	if playerID == "" {
+		playerID = request.EOSID
+	}
+	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, server.Id)
+	if err := enforceServerBan(r, server, playerID, request.Duration, request.Reason); err != nil {
+		log.Error().Err(err).Str("playerId", playerID).Str("serverId", server.Id.String()).Msg("Failed to apply ban via RCON")
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:ban:create", auditData)
+
+	responses.Success(c, "Ban created successfully", &gin.H{
+		"banId": banID.String(),
+	})
+}
+
+// createServerBan validates a ban request and stores the ban with its rule,
+// ban list and evidence. It is shared by the ban endpoint and server group
+// bans, and returns the ban's ID with the details for its audit log entry.
+// Enforcing the ban on the game server is left to the caller.
+func (s *Server) createServerBan(ctx context.Context, server *models.Server, adminId uuid.UUID, request *models.ServerBanCreateRequest) (uuid.UUID, map[string]interface{}, error) {
+	// Validate request
+	if request.SteamID == "" && request.EOSID == "" {
+		return uuid.Nil, nil, newBanRequestError("Steam ID or EOS ID is required", errors.New("Steam ID or EOS ID is required"))
+	}
+
 	if request.Reason == "" {
-		responses.BadRequest(c, "Ban reason is required", &gin.H{"error": "Ban reason is required"})
-		return
+		return uuid.Nil, nil, newBanRequestError("Ban reason is required", errors.New("Ban reason is required"))
 	}
 
 	if request.Duration < 0 {
-		responses.BadRequest(c, "Duration must be a positive integer", &gin.H{"error": "Duration must be a positive integer"})
-		return
+		return uuid.Nil, nil, newBanRequestError("Duration must be a positive integer", errors.New("Duration must be a positive integer"))
 	}
 
 	// Convert SteamID to int64
-	steamID, err := strconv.ParseInt(request.SteamID, 10, 64)
+	var steamID interface{}
+	if request.SteamID != "" {
+		if !steamIDPattern.MatchString(request.SteamID) {
+			return uuid.Nil, nil, newBanRequestError("Invalid Steam ID format", errors.New("Steam ID must be a 17 digit Steam64 ID"))
+		}
+		steamIDInt, err := strconv.ParseInt(request.SteamID, 10, 64)
+		if err != nil {
+			return uuid.Nil, nil, newBanRequestError("Invalid Steam ID format", errors.New("Steam ID must be a valid 64-bit integer"))
+		}
+		steamID = steamIDInt
+	}
+
+	var eosID interface{}
+	if request.EOSID != "" {
+		if !eosIDPattern.MatchString(request.EOSID) {
+			return uuid.Nil, nil, newBanRequestError("Invalid EOS ID format", errors.New("EOS ID must be 32 hexadecimal characters"))
+		}
+		request.EOSID = strings.ToLower(request.EOSID)
+		eosID = request.EOSID
+	}
+
+	linkedIdentities := models.LinkedIdentitiesServerDefault
+	if request.LinkedIdentities != nil && *request.LinkedIdentities != "" {
+		linkedIdentities = *request.LinkedIdentities
+	}
+	extendToLinked, err := linkedIdentitiesPolicy(linkedIdentities)
 	if err != nil {
-		responses.BadRequest(c, "Invalid Steam ID format", &gin.H{"error": "Steam ID must be a valid 64-bit integer"})
-		return
+		return uuid.Nil, nil, newBanRequestError("Invalid linked identities option", err)
 	}
 
 	// Insert the ban into the database (using steam_id directly)
 	var banID uuid.UUID = uuid.New()
 	now := time.Now()
 
-	query := `
-		INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, evidence_text, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id
-	`
-	args := []interface{}{banID, serverId, user.Id, steamID, request.Reason, request.Duration, request.EvidenceText, now, now}
+	columns := []string{"id", "server_id", "admin_id", "steam_id", "eos_id", "reason", "duration", "extend_to_linked", "evidence_text", "created_at", "updated_at"}
+	args := []interface{}{banID, server.Id, adminId, steamID, eosID, request.Reason, request.Duration, extendToLinked, request.EvidenceText, now, now}
 
 	// Add rule_id and ban_list_id if provided
 	if request.RuleID != nil && *request.RuleID != "" {
 		ruleUUID, err := uuid.Parse(*request.RuleID)
 		if err != nil {
-			responses.BadRequest(c, "Invalid rule ID format", &gin.H{"error": err.Error()})
-			return
+			return uuid.Nil, nil, newBanRequestError("Invalid rule ID format", err)
 		}
+		columns = append(columns, "rule_id")
+		args = append(args, ruleUUID)
+	}
 
-		if request.BanListID != nil && *request.BanListID != "" {
-			banListUUID, err := uuid.Parse(*request.BanListID)
-			if err != nil {
-				responses.BadRequest(c, "Invalid ban list ID format", &gin.H{"error": err.Error()})
-				return
-			}
-			query = `
-				INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, rule_id, ban_list_id, evidence_text, created_at, updated_at)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-				RETURNING id
-			`
-			args = append(args[:6], ruleUUID, banListUUID, request.EvidenceText, now, now)
-		} else {
-			query = `
-				INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, rule_id, evidence_text, created_at, updated_at)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-				RETURNING id
-			`
-			args = append(args[:6], ruleUUID, request.EvidenceText, now, now)
-		}
-	} else if request.BanListID != nil && *request.BanListID != "" {
+	if request.BanListID != nil && *request.BanListID != "" {
 		banListUUID, err := uuid.Parse(*request.BanListID)
 		if err != nil {
-			responses.BadRequest(c, "Invalid ban list ID format", &gin.H{"error": err.Error()})
-			return
+			return uuid.Nil, nil, newBanRequestError("Invalid ban list ID format", err)
 		}
-		query = `
-			INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, ban_list_id, evidence_text, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-			RETURNING id
-		`
-		args = append(args[:6], banListUUID, request.EvidenceText, now, now)
+		columns = append(columns, "ban_list_id")
+		args = append(args, banListUUID)
+	}
+
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
 	}
+	query := fmt.Sprintf(`
+		INSERT INTO server_bans (%s)
+		VALUES (%s)
+		RETURNING id
+	`, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
 
 	var returnedBanID string
-	err = s.Dependencies.DB.QueryRowContext(c.Request.Context(), query, args...).Scan(&returnedBanID)
+	err = s.Dependencies.DB.QueryRowContext(ctx, query, args...).Scan(&returnedBanID)
 	if err != nil {
-		responses.BadRequest(c, "Failed to create ban", &gin.H{"error": err.Error()})
-		return
+		return uuid.Nil, nil, newBanRequestError("Failed to create ban", err)
 	}
 
 	// Insert evidence records if provided
 	if len(request.Evidence) > 0 {
-		err = s.createBanEvidence(c.Request.Context(), banID.String(), serverId, request.Evidence)
+		err = s.createBanEvidence(ctx, banID.String(), server.Id, request.Evidence)
 		if err != nil {
 			log.Error().Err(err).Str("banId", banID.String()).Msg("Failed to create ban evidence")
 			// Don't fail the entire ban creation, just log the error
 		}
 	}
 
-	// Apply the ban via RCON if the server is online
-	if server != nil {
-		r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, server.Id)
-		if server.BanEnforcementMode == "aegis" {
-			// In aegis mode, just kick the player now; the ban enforcer handles future connections
-			err = r.KickPlayer(request.SteamID, request.Reason)
-			if err != nil {
-				log.Error().Err(err).Str("steamId", request.SteamID).Str("serverId", server.Id.String()).Msg("Failed to kick player via RCON")
-			}
-		} else {
-			// In server mode, send AdminBan so the game server enforces the ban
-			err = r.BanPlayer(request.SteamID, request.Duration, request.Reason)
-			if err != nil {
-				log.Error().Err(err).Str("steamId", request.SteamID).Str("serverId", server.Id.String()).Msg("Failed to apply ban via RCON")
-			}
-		}
-	}
-
 	// Log rule violation to ClickHouse if rule ID is provided
-	if request.RuleID != nil && *request.RuleID != "" {
-		if err := s.logRuleViolation(c.Request.Context(), serverId, request.SteamID, request.RuleID, &user.Id, "BAN"); err != nil {
+	if request.RuleID != nil && *request.RuleID != "" && request.SteamID != "" {
+		if err := s.logRuleViolation(ctx, server.Id, request.SteamID, request.RuleID, &adminId, "BAN"); err != nil {
 			log.Warn().Err(err).Str("steamId", request.SteamID).Str("ruleId", *request.RuleID).Msg("Failed to log rule violation for manual ban")
 			// Don't fail the ban creation if violation logging fails
 		}
@@ -288,17 +326,22 @@ func (s *Server) ServerBansAdd(c *gin.Context) {
 
 	// Create detailed audit log
 	auditData := map[string]interface{}{
-		"banId":         banID.String(),
-		"steamId":       request.SteamID,
-		"reason":        request.Reason,
-		"duration":      request.Duration,
-		"evidenceCount": len(request.Evidence),
+		"banId":            banID.String(),
+		"steamId":          request.SteamID,
+		"eosId":            request.EOSID,
+		"reason":           request.Reason,
+		"duration":         request.Duration,
+		"evidenceCount":    len(request.Evidence),
+		"linkedIdentities": linkedIdentities,
 	}
 
-	// Add rule ID to audit log if provided
+	// Add rule and ban list to audit log if provided
 	if request.RuleID != nil && *request.RuleID != "" {
 		auditData["ruleId"] = *request.RuleID
 	}
+	if request.BanListID != nil && *request.BanListID != "" {
+		auditData["banListId"] = *request.BanListID
+	}
 
 	// Add expiry information if not permanent
 	if request.Duration > 0 {
@@ -306,11 +349,17 @@ func (s *Server) ServerBansAdd(c *gin.Context) {
 		auditData["expiresAt"] = expiresAt.Format(time.RFC3339)
 	}
 
-	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:ban:create", auditData)
+	return banID, auditData, nil
+}
 
-	responses.Success(c, "Ban created successfully", &gin.H{
-		"banId": banID.String(),
-	})
+// enforceServerBan applies a new ban on the game server. In aegis mode the
+// player is only kicked and the ban enforcer handles future connections; in
+// server mode AdminBan is sent so the game server enforces the ban.
+func enforceServerBan(r *squadRcon.SquadRcon, server *models.Server, playerID string, duration int, reason string) error {
+	if server.BanEnforcementMode == "aegis" {
+		return r.KickPlayer(playerID, reason)
+	}
+	return r.BanPlayer(playerID, duration, reason)
 }
 
 // ServerBansRemove handles removing a ban
@@ -339,16 +388,17 @@ func (s *Server) ServerBansRemove(c *gin.Context) {
 	}
 
 	// Get the ban details first (to get the Steam ID for RCON unban)
-	var steamIDInt int64
+	var steamIDInt sql.NullInt64
+	var eosID sql.NullString
 	var reason string
 	var duration int
 	var adminId uuid.UUID
 
 	err = s.Dependencies.DB.QueryRowContext(c.Request.Context(), `
-		SELECT sb.steam_id, sb.reason, sb.duration, sb.admin_id
+		SELECT sb.steam_id, sb.eos_id, sb.reason, sb.duration, sb.admin_id
 		FROM server_bans sb
 		WHERE sb.id = $1 AND sb.server_id = $2
-	`, banId, serverId).Scan(&steamIDInt, &reason, &duration, &adminId)
+	`, banId, serverId).Scan(&steamIDInt, &eosID, &reason, &duration, &adminId)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			responses.BadRequest(c, "Ban not found", &gin.H{"error": "Ban not found"})
@@ -387,14 +437,21 @@ func (s *Server) ServerBansRemove(c *gin.Context) {
 	}
 
 	// Also remove the ban via RCON if the server is online and using server-side enforcement
-	steamIDStr := strconv.FormatInt(steamIDInt, 10)
+	steamIDStr := ""
+	if steamIDInt.Valid {
+		steamIDStr = strconv.FormatInt(steamIDInt.Int64, 10)
+	}
+	playerID := steamIDStr
+	if playerID == "" {
+		playerID = eosID.String
+	}
 	if server != nil && server.BanEnforcementMode != "aegis" {
 		r, err := rcon.NewRcon(rcon.RconConfig{Host: server.IpAddress, Password: server.RconPassword, Port: strconv.Itoa(server.RconPort), AutoReconnect: true, AutoReconnectDelay: 5})
 		if err == nil {
 			defer r.Close()
 
 			// Execute the unban command
-			unbanCommand := fmt.Sprintf("AdminUnban %s", steamIDStr)
+			unbanCommand := fmt.Sprintf("AdminUnban %s", playerID)
 			cmdResponse := r.Execute(unbanCommand)
 			if cmdResponse == "" {
 				log.Error().Msgf("Failed to execute unban command for banId %s: %s", banId.String(), unbanCommand)
@@ -408,6 +465,7 @@ func (s *Server) ServerBansRemove(c *gin.Context) {
 	auditData := map[string]interface{}{
 		"banId":    banId.String(),
 		"steamId":  steamIDStr,
+		"eosId":    eosID.String,
 		"reason":   reason,
 		"duration": duration,
 	}
@@ -441,78 +499,71 @@ func (s *Server) ServerBansUpdate(c *gin.Context) {
 		return
 	}
 
-	// Get the current ban details first
-	var currentBan models.ServerBan
-	var steamIDInt int64
-	var ruleID sql.NullString
-	var ruleTitle sql.NullString
-	var banListID sql.NullString
-	var banListName sql.NullString
-	var evidenceText sql.NullString
+	tx, err := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to begin transaction: %w", err), nil)
+		return
+	}
+	defer tx.Rollback()
 
-	err = s.Dependencies.DB.QueryRowContext(c.Request.Context(), `
-		SELECT sb.id, sb.server_id, sb.admin_id, u.username, sb.steam_id, sb.reason, sb.duration, sb.rule_id, sr.title as rule_title,  sb.ban_list_id, bl.name as ban_list_name, sb.evidence_text, sb.created_at, sb.updated_at
-		FROM server_bans sb
-		JOIN users u ON sb.admin_id = u.id
-		LEFT JOIN ban_lists bl ON sb.ban_list_id = bl.id
-		LEFT JOIN server_rules sr ON sb.rule_id = sr.id
-		WHERE sb.id = $1 AND sb.server_id = $2
-	`, banId, serverId).Scan(
-		&currentBan.ID,
-		&currentBan.ServerID,
-		&currentBan.AdminID,
-		&currentBan.AdminName,
-		&steamIDInt,
-		&currentBan.Reason,
-		&currentBan.Duration,
-		&ruleID,
-		&ruleTitle,
-		&banListID,
-		&banListName,
-		&evidenceText,
-		&currentBan.CreatedAt,
-		&currentBan.UpdatedAt,
-	)
+	update, err := s.updateServerBan(c.Request.Context(), tx, serverId, banId, request)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			responses.BadRequest(c, "Ban not found", &gin.H{"error": "Ban not found"})
+		var updateErr *banRequestError
+		if errors.As(err, &updateErr) {
+			responses.BadRequest(c, updateErr.message, &gin.H{"error": updateErr.err.Error()})
 		} else {
-			responses.BadRequest(c, "Failed to get ban details", &gin.H{"error": err.Error()})
+			responses.BadRequest(c, "Failed to update ban", &gin.H{"error": err.Error()})
 		}
 		return
 	}
 
-	// Convert steamID from int64 to string
-	currentBan.SteamID = strconv.FormatInt(steamIDInt, 10)
-	currentBan.Name = currentBan.SteamID
-
-	// Set rule ID if present
-	if ruleID.Valid {
-		currentBan.RuleID = &ruleID.String
+	if err := tx.Commit(); err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to commit transaction: %w", err), nil)
+		return
 	}
 
-	// Set rule name if present
-	if ruleTitle.Valid {
-		currentBan.RuleName = &ruleTitle.String
-	}
+	updatedBan := s.finishServerBanUpdate(c.Request.Context(), user.Id, update, nil)
 
-	// Set ban list information if present
-	if banListID.Valid {
-		currentBan.BanListID = &banListID.String
-	}
-	if banListName.Valid {
-		currentBan.BanListName = &banListName.String
-	}
+	responses.Success(c, "Ban updated successfully", &gin.H{
+		"ban": updatedBan,
+	})
+}
 
-	// Set evidence text if present
-	if evidenceText.Valid {
-		currentBan.EvidenceText = &evidenceText.String
-	}
+// banRequestError is a rejected ban creation or update, with the message shown to the user
+type banRequestError struct {
+	message string
+	err     error
+}
 
-	// Calculate if ban is permanent and expiry date
-	currentBan.Permanent = currentBan.Duration == 0
-	if !currentBan.Permanent {
-		currentBan.ExpiresAt = currentBan.CreatedAt.Add(time.Duration(currentBan.Duration) * 24 * time.Hour)
+func (e *banRequestError) Error() string {
+	return e.message + ": " + e.err.Error()
+}
+
+func newBanRequestError(message string, err error) error {
+	return &banRequestError{message: message, err: err}
+}
+
+// serverBanUpdate is a ban update written in a transaction, with what is left
+// to do once the transaction is committed
+type serverBanUpdate struct {
+	serverId     uuid.UUID
+	previous     *models.ServerBan
+	ban          *models.ServerBan
+	lifting      bool
+	removedFiles []string
+}
+
+// updateServerBan writes an update to a ban in tx. It is shared by the ban
+// edit endpoint and accepted ban appeals, which call finishServerBanUpdate
+// after committing.
+func (s *Server) updateServerBan(ctx context.Context, tx *sql.Tx, serverId, banId uuid.UUID, request models.ServerBanUpdateRequest) (*serverBanUpdate, error) {
+	// Get the current ban details first
+	currentBan, err := s.getServerBan(ctx, tx, serverId, banId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, newBanRequestError("Ban not found", errors.New("Ban not found"))
+		}
+		return nil, newBanRequestError("Failed to get ban details", err)
 	}
 
 	// Build update query dynamically based on provided fields
@@ -528,8 +579,7 @@ func (s *Server) ServerBansUpdate(c *gin.Context) {
 
 	if request.Duration != nil {
 		if *request.Duration < 0 {
-			responses.BadRequest(c, "Duration must be a positive integer", &gin.H{"error": "Duration must be a positive integer"})
-			return
+			return nil, newBanRequestError("Duration must be a positive integer", errors.New("Duration must be a positive integer"))
 		}
 		updateFields = append(updateFields, fmt.Sprintf("duration = $%d", argIndex))
 		updateArgs = append(updateArgs, *request.Duration)
@@ -546,8 +596,7 @@ func (s *Server) ServerBansUpdate(c *gin.Context) {
 			// Add to ban list
 			banListUUID, err := uuid.Parse(*request.BanListID)
 			if err != nil {
-				responses.BadRequest(c, "Invalid ban list ID format", &gin.H{"error": err.Error()})
-				return
+				return nil, newBanRequestError("Invalid ban list ID format", err)
 			}
 			updateFields = append(updateFields, fmt.Sprintf("ban_list_id = $%d", argIndex))
 			updateArgs = append(updateArgs, banListUUID)
@@ -567,18 +616,40 @@ func (s *Server) ServerBansUpdate(c *gin.Context) {
 		argIndex++
 	}
 
+	if request.LinkedIdentities != nil {
+		extendToLinked, err := linkedIdentitiesPolicy(*request.LinkedIdentities)
+		if err != nil {
+			return nil, newBanRequestError("Invalid linked identities option", err)
+		}
+		updateFields = append(updateFields, fmt.Sprintf("extend_to_linked = $%d", argIndex))
+		updateArgs = append(updateArgs, extendToLinked)
+		argIndex++
+	}
+
+	// Lifting keeps the ban for its history, so only the first lift is recorded
+	now := time.Now()
+	lifting := request.Lifted != nil && *request.Lifted && currentBan.LiftedAt == nil
+	if request.Lifted != nil {
+		if *request.Lifted {
+			updateFields = append(updateFields, fmt.Sprintf("lifted_at = COALESCE(lifted_at, $%d)", argIndex))
+			updateArgs = append(updateArgs, now)
+		} else {
+			updateFields = append(updateFields, fmt.Sprintf("lifted_at = $%d", argIndex))
+			updateArgs = append(updateArgs, nil)
+		}
+		argIndex++
+	}
+
 	// Check if evidence is being updated (separate from ban fields)
 	// Evidence can be nil (not updating), empty array (clearing evidence), or have items (updating evidence)
 	hasEvidenceUpdate := request.Evidence != nil
 
 	// If no fields to update and no evidence update, return error
 	if len(updateFields) == 0 && !hasEvidenceUpdate {
-		responses.BadRequest(c, "No fields to update", &gin.H{"error": "At least one field must be provided for update"})
-		return
+		return nil, newBanRequestError("No fields to update", errors.New("At least one field must be provided for update"))
 	}
 
 	// Add updated_at timestamp
-	now := time.Now()
 	updateFields = append(updateFields, fmt.Sprintf("updated_at = $%d", argIndex))
 	updateArgs = append(updateArgs, now)
 	argIndex++
@@ -589,31 +660,28 @@ func (s *Server) ServerBansUpdate(c *gin.Context) {
 	updateArgs = append(updateArgs, banId, serverId)
 
 	// Execute the update
-	result, err := s.Dependencies.DB.ExecContext(c.Request.Context(), query, updateArgs...)
+	result, err := tx.ExecContext(ctx, query, updateArgs...)
 	if err != nil {
-		responses.BadRequest(c, "Failed to update ban", &gin.H{"error": err.Error()})
-		return
+		return nil, newBanRequestError("Failed to update ban", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		responses.BadRequest(c, "Failed to get rows affected", &gin.H{"error": err.Error()})
-		return
+		return nil, newBanRequestError("Failed to get rows affected", err)
 	}
 
 	if rowsAffected == 0 {
-		responses.BadRequest(c, "Ban not found", &gin.H{"error": "Ban not found"})
-		return
+		return nil, newBanRequestError("Ban not found", errors.New("Ban not found"))
 	}
 
 	// Update evidence records if provided (including empty array to clear evidence)
+	var removedFiles []string
 	if request.Evidence != nil {
 		// First, get the list of existing evidence files to determine which ones to delete
-		existingFiles, err := s.getExistingEvidenceFiles(c.Request.Context(), banId.String())
+		existingFiles, err := s.getExistingEvidenceFiles(ctx, tx, banId.String())
 		if err != nil {
 			log.Error().Err(err).Str("banId", banId.String()).Msg("Failed to query existing evidence files")
-			responses.BadRequest(c, "Failed to update evidence", &gin.H{"error": "Failed to query existing evidence"})
-			return
+			return nil, newBanRequestError("Failed to update evidence", errors.New("Failed to query existing evidence"))
 		}
 
 		// Build a set of file paths from the new evidence that should be kept
@@ -624,153 +692,236 @@ func (s *Server) ServerBansUpdate(c *gin.Context) {
 			}
 		}
 
-		// Determine which files need to be deleted (files that exist but are NOT in the new evidence)
-		filesToDelete := []string{}
+		// Files that exist but are NOT in the new evidence are deleted once the
+		// update is committed
 		for _, existingFile := range existingFiles {
 			if !newFilePaths[existingFile] {
-				filesToDelete = append(filesToDelete, existingFile)
+				removedFiles = append(removedFiles, existingFile)
 			}
 		}
 
-		// Delete only the files that are being removed
-		if len(filesToDelete) > 0 {
-			if err := s.deleteSpecificEvidenceFiles(c.Request.Context(), banId.String(), filesToDelete); err != nil {
-				log.Warn().Err(err).Str("banId", banId.String()).Msg("Failed to delete some evidence files from storage, continuing with database update")
-				// Continue with update even if file deletion fails
-			}
-		}
-
-		// Use a transaction to ensure atomicity - if insert fails, rollback the delete
-		tx, txErr := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
-		if txErr != nil {
-			log.Error().Err(txErr).Str("banId", banId.String()).Msg("Failed to begin transaction for evidence update")
-			responses.BadRequest(c, "Failed to update evidence", &gin.H{"error": "Failed to start evidence update transaction"})
-			return
-		}
-		defer tx.Rollback()
-
 		// Delete existing evidence records from database
-		_, delErr := tx.ExecContext(c.Request.Context(), `
+		if _, err := tx.ExecContext(ctx, `
 			DELETE FROM ban_evidence WHERE ban_id = $1
-		`, banId)
-		if delErr != nil {
-			log.Error().Err(delErr).Str("banId", banId.String()).Msg("Failed to delete old ban evidence")
-			tx.Rollback()
-			responses.BadRequest(c, "Failed to update evidence", &gin.H{"error": "Failed to delete existing evidence"})
-			return
+		`, banId); err != nil {
+			log.Error().Err(err).Str("banId", banId.String()).Msg("Failed to delete old ban evidence")
+			return nil, newBanRequestError("Failed to update evidence", errors.New("Failed to delete existing evidence"))
 		}
 
 		// Insert new evidence (if any)
 		if len(*request.Evidence) > 0 {
-			createErr := s.createBanEvidenceWithTx(c.Request.Context(), tx, banId.String(), serverId, *request.Evidence)
-			if createErr != nil {
-				log.Error().Err(createErr).Str("banId", banId.String()).Msg("Failed to create updated ban evidence")
-				tx.Rollback()
-				responses.BadRequest(c, "Failed to update evidence", &gin.H{"error": createErr.Error()})
-				return
+			if err := s.createBanEvidenceWithTx(ctx, tx, banId.String(), serverId, *request.Evidence); err != nil {
+				log.Error().Err(err).Str("banId", banId.String()).Msg("Failed to create updated ban evidence")
+				return nil, newBanRequestError("Failed to update evidence", err)
 			}
 		}
+	}
 
-		// Commit the transaction
-		if commitErr := tx.Commit(); commitErr != nil {
-			log.Error().Err(commitErr).Str("banId", banId.String()).Msg("Failed to commit evidence update transaction")
-			responses.BadRequest(c, "Failed to update evidence", &gin.H{"error": "Failed to commit evidence update"})
-			return
+	// Get updated ban details for the response
+	updatedBan, err := s.getServerBan(ctx, tx, serverId, banId)
+	if err != nil {
+		return nil, newBanRequestError("Failed to get updated ban details", err)
+	}
+
+	return &serverBanUpdate{
+		serverId:     serverId,
+		previous:     currentBan,
+		ban:          updatedBan,
+		lifting:      lifting,
+		removedFiles: removedFiles,
+	}, nil
+}
+
+// finishServerBanUpdate runs the side effects of a committed ban update: it
+// deletes removed evidence files, unbans a lifted ban on the game server and
+// records the update in the audit log, with auditExtra added to the entry
+func (s *Server) finishServerBanUpdate(ctx context.Context, userId uuid.UUID, update *serverBanUpdate, auditExtra map[string]interface{}) *models.ServerBan {
+	serverId := update.serverId
+	currentBan := update.previous
+	updatedBan := update.ban
+
+	if len(update.removedFiles) > 0 {
+		if err := s.deleteSpecificEvidenceFiles(ctx, updatedBan.ID, update.removedFiles); err != nil {
+			log.Warn().Err(err).Str("banId", updatedBan.ID).Msg("Failed to delete some evidence files from storage")
 		}
 	}
 
-	// Get updated ban details for response and RCON
-	var updatedBan models.ServerBan
-	var updatedSteamIDInt int64
-	var updatedRuleID sql.NullString
-	var updatedRuleTitle sql.NullString
-	var updatedBanListID sql.NullString
-	var updatedBanListName sql.NullString
-	var updatedEvidenceText sql.NullString
+	// Load evidence records
+	updatedBan.Evidence, _ = s.loadBanEvidence(ctx, updatedBan.ID)
 
-	err = s.Dependencies.DB.QueryRowContext(c.Request.Context(), `
-		SELECT sb.id, sb.server_id, sb.admin_id, u.username, sb.steam_id, sb.reason, sb.duration, sb.rule_id, sr.title as rule_title,  sb.ban_list_id, bl.name as ban_list_name, sb.evidence_text, sb.created_at, sb.updated_at
+	// A lifted ban is no longer enforced by Aegis; servers enforcing bans
+	// themselves need to be told
+	if update.lifting {
+		s.unbanOnServer(ctx, serverId, banIdentifier(updatedBan))
+	}
+
+	// Create detailed audit log
+	auditData := map[string]interface{}{
+		"banId":        updatedBan.ID,
+		"steamId":      updatedBan.SteamID,
+		"oldReason":    currentBan.Reason,
+		"newReason":    updatedBan.Reason,
+		"oldDuration":  currentBan.Duration,
+		"newDuration":  updatedBan.Duration,
+		"oldBanListId": currentBan.BanListID,
+		"newBanListId": updatedBan.BanListID,
+		"oldRuleId":    currentBan.RuleID,
+		"newRuleId":    updatedBan.RuleID,
+		"oldLiftedAt":  currentBan.LiftedAt,
+		"newLiftedAt":  updatedBan.LiftedAt,
+		"eosId":        updatedBan.EOSID,
+
+		"oldExtendToLinked": currentBan.ExtendToLinked,
+		"newExtendToLinked": updatedBan.ExtendToLinked,
+	}
+	for key, value := range auditExtra {
+		auditData[key] = value
+	}
+
+	s.CreateAuditLog(ctx, &serverId, &userId, "server:ban:update", auditData)
+
+	return updatedBan
+}
+
+// getServerBan loads a ban of a server, without its evidence
+func (s *Server) getServerBan(ctx context.Context, database db.Executor, serverId, banId uuid.UUID) (*models.ServerBan, error) {
+	var ban models.ServerBan
+	var adminID uuid.NullUUID
+	var adminName sql.NullString
+	var steamIDInt sql.NullInt64
+	var eosID sql.NullString
+	var ruleID sql.NullString
+	var ruleTitle sql.NullString
+	var banListID sql.NullString
+	var banListName sql.NullString
+	var evidenceText sql.NullString
+	var liftedAt sql.NullTime
+	var extendToLinked sql.NullBool
+
+	err := database.QueryRowContext(ctx, `
+		SELECT sb.id, sb.server_id, sb.admin_id, u.username, sb.steam_id, sb.eos_id, sb.reason, sb.duration, sb.rule_id, sr.title as rule_title,  sb.ban_list_id, bl.name as ban_list_name, sb.evidence_text, sb.lifted_at, sb.extend_to_linked, sb.created_at, sb.updated_at
 		FROM server_bans sb
-		JOIN users u ON sb.admin_id = u.id
+		LEFT JOIN users u ON sb.admin_id = u.id
 		LEFT JOIN ban_lists bl ON sb.ban_list_id = bl.id
 		LEFT JOIN server_rules sr ON sb.rule_id = sr.id
-		WHERE sb.id = $1
-	`, banId).Scan(
-		&updatedBan.ID,
-		&updatedBan.ServerID,
-		&updatedBan.AdminID,
-		&updatedBan.AdminName,
-		&updatedSteamIDInt,
-		&updatedBan.Reason,
-		&updatedBan.Duration,
-		&updatedRuleID,
-		&updatedRuleTitle,
-		&updatedBanListID,
-		&updatedBanListName,
-		&updatedEvidenceText,
-		&updatedBan.CreatedAt,
-		&updatedBan.UpdatedAt,
+		WHERE sb.id = $1 AND sb.server_id = $2
+	`, banId, serverId).Scan(
+		&ban.ID,
+		&ban.ServerID,
+		&adminID,
+		&adminName,
+		&steamIDInt,
+		&eosID,
+		&ban.Reason,
+		&ban.Duration,
+		&ruleID,
+		&ruleTitle,
+		&banListID,
+		&banListName,
+		&evidenceText,
+		&liftedAt,
+		&extendToLinked,
+		&ban.CreatedAt,
+		&ban.UpdatedAt,
 	)
 	if err != nil {
-		responses.BadRequest(c, "Failed to get updated ban details", &gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
 
-	// Convert steamID from int64 to string
-	updatedBan.SteamID = strconv.FormatInt(updatedSteamIDInt, 10)
-	updatedBan.Name = updatedBan.SteamID
+	// Bans issued by plugins and workflows have no admin
+	ban.AdminID = adminID.UUID
+	ban.AdminName = adminName.String
+
+	// Convert steamID from int64 to string; bans on an EOS ID may have none
+	if steamIDInt.Valid {
+		ban.SteamID = strconv.FormatInt(steamIDInt.Int64, 10)
+	}
+	ban.EOSID = eosID.String
+	ban.Name = banIdentifier(&ban)
 
 	// Set rule ID if present
-	if updatedRuleID.Valid {
-		updatedBan.RuleID = &updatedRuleID.String
+	if ruleID.Valid {
+		ban.RuleID = &ruleID.String
 	}
 
 	// Set rule name if present
-	if updatedRuleTitle.Valid {
-		updatedBan.RuleName = &updatedRuleTitle.String
+	if ruleTitle.Valid {
+		ban.RuleName = &ruleTitle.String
 	}
 
 	// Set ban list information if present
-	if updatedBanListID.Valid {
-		updatedBan.BanListID = &updatedBanListID.String
+	if banListID.Valid {
+		ban.BanListID = &banListID.String
 	}
-	if updatedBanListName.Valid {
-		updatedBan.BanListName = &updatedBanListName.String
+	if banListName.Valid {
+		ban.BanListName = &banListName.String
 	}
 
 	// Set evidence text if present
-	if updatedEvidenceText.Valid {
-		updatedBan.EvidenceText = &updatedEvidenceText.String
+	if evidenceText.Valid {
+		ban.EvidenceText = &evidenceText.String
 	}
 
-	// Load evidence records
-	updatedBan.Evidence, _ = s.loadBanEvidence(c.Request.Context(), updatedBan.ID)
+	if liftedAt.Valid {
+		ban.LiftedAt = &liftedAt.Time
+	}
+
+	if extendToLinked.Valid {
+		ban.ExtendToLinked = &extendToLinked.Bool
+	}
 
 	// Calculate if ban is permanent and expiry date
-	updatedBan.Permanent = updatedBan.Duration == 0
-	if !updatedBan.Permanent {
-		updatedBan.ExpiresAt = updatedBan.CreatedAt.Add(time.Duration(updatedBan.Duration) * 24 * time.Hour)
+	ban.Permanent = ban.Duration == 0
+	if !ban.Permanent {
+		ban.ExpiresAt = ban.CreatedAt.Add(time.Duration(ban.Duration) * 24 * time.Hour)
 	}
 
-	// Create detailed audit log
-	auditData := map[string]interface{}{
-		"banId":        banId.String(),
-		"steamId":      updatedBan.SteamID,
-		"oldReason":    currentBan.Reason,
-		"newReason":    updatedBan.Reason,
-		"oldDuration":  currentBan.Duration,
-		"newDuration":  updatedBan.Duration,
-		"oldBanListId": currentBan.BanListID,
-		"newBanListId": updatedBan.BanListID,
-		"oldRuleId":    currentBan.RuleID,
-		"newRuleId":    updatedBan.RuleID,
+	return &ban, nil
+}
+
+var (
+	// steamIDPattern matches a Steam64 ID
+	steamIDPattern = regexp.MustCompile(`^\d{17}$`)
+	// eosIDPattern matches an Epic Online Services account ID
+	eosIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+)
+
+// banIdentifier returns the ID a ban targets, its Steam ID if it has one
+func banIdentifier(ban *models.ServerBan) string {
+	if ban.SteamID != "" {
+		return ban.SteamID
 	}
+	return ban.EOSID
+}
 
-	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:ban:update", auditData)
+// linkedIdentitiesPolicy converts a linked identities choice to the value
+// stored on a ban, nil following the server setting
+func linkedIdentitiesPolicy(choice string) (*bool, error) {
+	switch choice {
+	case models.LinkedIdentitiesServerDefault:
+		return nil, nil
+	case models.LinkedIdentitiesExtend:
+		extend := true
+		return &extend, nil
+	case models.LinkedIdentitiesIgnore:
+		extend := false
+		return &extend, nil
+	}
+	return nil, errors.New("linked_identities must be server_default, extend or ignore")
+}
 
-	responses.Success(c, "Ban updated successfully", &gin.H{
-		"ban": updatedBan,
-	})
+// unbanOnServer removes a ban from a server that enforces bans itself. Servers
+// in aegis enforcement mode need nothing, the ban enforcer checks the database.
+func (s *Server) unbanOnServer(ctx context.Context, serverId uuid.UUID, playerId string) {
+	mode, err := core.GetServerBanEnforcementMode(ctx, s.Dependencies.DB, serverId)
+	if err != nil || mode == "aegis" {
+		return
+	}
+
+	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId)
+	if _, err := r.ExecuteRaw(fmt.Sprintf("AdminUnban %s", playerId)); err != nil {
+		log.Error().Err(err).Str("playerId", playerId).Str("serverId", serverId.String()).Msg("Failed to unban player via RCON")
+	}
 }
 
 // ServerBansCfg handles generating the ban config file for the server
@@ -787,7 +938,7 @@ func (s *Server) ServerBansCfg(c *gin.Context) {
 		SELECT sb.steam_id, sb.reason, sb.duration, sb.created_at, sb.admin_id, u.username, u.steam_id
 		FROM server_bans sb
 		LEFT JOIN users u ON sb.admin_id = u.id
-		WHERE sb.server_id = $1
+		WHERE sb.server_id = $1 AND sb.lifted_at IS NULL AND sb.steam_id IS NOT NULL
 	`, serverId)
 	if err != nil {
 		responses.BadRequest(c, "Failed to query bans", &gin.H{"error": err.Error()})
@@ -1241,8 +1392,8 @@ func (s *Server) createBanEvidence(ctx context.Context, banID string, serverID u
 }
 
 // getExistingEvidenceFiles returns a list of file paths for all file_upload evidence for a ban
-func (s *Server) getExistingEvidenceFiles(ctx context.Context, banID string) ([]string, error) {
-	rows, err := s.Dependencies.DB.QueryContext(ctx, `
+func (s *Server) getExistingEvidenceFiles(ctx context.Context, database db.Executor, banID string) ([]string, error) {
+	rows, err := database.QueryContext(ctx, `
 		SELECT file_path
 		FROM ban_evidence
 		WHERE ban_id = $1 AND evidence_type = 'file_upload' AND file_path IS NOT NULL
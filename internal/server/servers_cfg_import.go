@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/file_upload"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+)
+
+// maxCfgImportSize caps the size of an uploaded Admins.cfg or Bans.cfg
+const maxCfgImportSize = 16 << 20
+
+// ServerCfgImportPreview reads an Admins.cfg and a Bans.cfg, from the game
+// server or the request, and lists what importing them would change
+func (s *Server) ServerCfgImportPreview(c *gin.Context) {
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var request models.CfgImportPreviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	switch request.Source {
+	case models.CfgImportSourceUpload:
+	case models.CfgImportSourceServer:
+		if request.AdminsCfg, request.BansCfg, err = s.downloadServerCfgFiles(c.Request.Context(), serverId, request.AdminsPath, request.BansPath); err != nil {
+			responses.BadRequest(c, "Failed to read the config files from the game server", &gin.H{"error": err.Error()})
+			return
+		}
+	default:
+		responses.BadRequest(c, "Invalid source", &gin.H{"error": "source must be upload or server"})
+		return
+	}
+
+	if len(request.AdminsCfg) > maxCfgImportSize || len(request.BansCfg) > maxCfgImportSize {
+		responses.BadRequest(c, "Config file is too large", &gin.H{"error": fmt.Sprintf("files can't be larger than %d bytes", maxCfgImportSize)})
+		return
+	}
+	if strings.TrimSpace(request.AdminsCfg) == "" && strings.TrimSpace(request.BansCfg) == "" {
+		responses.BadRequest(c, "Nothing to import", &gin.H{"error": "Both config files are empty"})
+		return
+	}
+
+	preview, err := core.PreviewCfgImport(c.Request.Context(), s.Dependencies.DB, serverId, request.AdminsCfg, request.BansCfg)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Config import preview created successfully", &gin.H{"preview": preview})
+}
+
+// ServerCfgImportApply imports the files of a preview into the server's roles,
+// admins and bans in one transaction
+func (s *Server) ServerCfgImportApply(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var request models.CfgImportApplyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.AdminsCfg) > maxCfgImportSize || len(request.BansCfg) > maxCfgImportSize {
+		responses.BadRequest(c, "Config file is too large", &gin.H{"error": fmt.Sprintf("files can't be larger than %d bytes", maxCfgImportSize)})
+		return
+	}
+
+	preview, result, err := core.ApplyCfgImport(c.Request.Context(), s.Dependencies.DB, serverId, request.AdminsCfg, request.BansCfg, request.OverwriteConflicts)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if result.RolesCreated > 0 || result.RolesUpdated > 0 || result.AdminsAdded > 0 {
+		s.Dependencies.PermissionService.InvalidateServerCache(serverId)
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:cfg:import", map[string]interface{}{
+		"rolesCreated":       result.RolesCreated,
+		"rolesUpdated":       result.RolesUpdated,
+		"adminsAdded":        result.AdminsAdded,
+		"bansAdded":          result.BansAdded,
+		"bansUpdated":        result.BansUpdated,
+		"overwriteConflicts": request.OverwriteConflicts,
+		"warnings":           len(preview.Warnings),
+	})
+
+	responses.Success(c, "Config files imported successfully", &gin.H{"result": result, "preview": preview})
+}
+
+// downloadServerCfgFiles reads Admins.cfg and Bans.cfg over the MOTD upload
+// connection of a server. Paths default to the directory of the MOTD file.
+func (s *Server) downloadServerCfgFiles(ctx context.Context, serverId uuid.UUID, adminsPath, bansPath string) (string, string, error) {
+	motdConfig, err := s.fetchOrCreateMOTDConfig(ctx, serverId)
+	if err != nil {
+		return "", "", err
+	}
+
+	uploadConfig, err := s.getUploadConfig(ctx, serverId, motdConfig)
+	if err != nil {
+		return "", "", err
+	}
+
+	configDir := path.Dir(motdConfig.MOTDFilePath)
+	if strings.TrimSpace(adminsPath) == "" {
+		adminsPath = path.Join(configDir, "Admins.cfg")
+	}
+	if strings.TrimSpace(bansPath) == "" {
+		bansPath = path.Join(configDir, "Bans.cfg")
+	}
+
+	adminsCfg, err := downloadServerFile(ctx, uploadConfig, adminsPath)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", adminsPath, err)
+	}
+	bansCfg, err := downloadServerFile(ctx, uploadConfig, bansPath)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", bansPath, err)
+	}
+
+	return adminsCfg, bansCfg, nil
+}
+
+func downloadServerFile(ctx context.Context, config file_upload.UploadConfig, filePath string) (string, error) {
+	config.FilePath = filePath
+
+	uploader, err := file_upload.NewUploader(config)
+	if err != nil {
+		return "", err
+	}
+	defer uploader.Close()
+
+	return uploader.Download(ctx)
+}
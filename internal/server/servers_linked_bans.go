@@ -0,0 +1,131 @@
+package server
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+)
+
+// maxBanIdentityMatches bounds the matches returned by the identity matches endpoint
+const maxBanIdentityMatches = 500
+
+// ServerLinkedBanSettingsGet returns the linked identity ban policy of a server
+func (s *Server) ServerLinkedBanSettingsGet(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := core.GetLinkedBanSettings(c.Request.Context(), s.Dependencies.DB, serverId)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Linked ban settings fetched successfully", &gin.H{"settings": settings})
+}
+
+// ServerLinkedBanSettingsUpdate changes the linked identity ban policy of a server
+func (s *Server) ServerLinkedBanSettingsUpdate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.LinkedBanSettingsUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.BadRequest(c, "Invalid request body", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateLinkedBanSettings(&req); err != nil {
+		responses.BadRequest(c, "Invalid linked ban settings", &gin.H{"error": err.Error()})
+		return
+	}
+
+	settings := &models.LinkedBanSettings{
+		ServerID:        serverId,
+		ExtendByDefault: req.ExtendByDefault,
+		Action:          req.Action,
+		MinConfidence:   req.MinConfidence,
+	}
+
+	if err := core.SaveLinkedBanSettings(c.Request.Context(), s.Dependencies.DB, settings); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:linked_bans:settings:update", map[string]interface{}{
+		"extendByDefault": settings.ExtendByDefault,
+		"action":          settings.Action,
+		"minConfidence":   settings.MinConfidence,
+	})
+
+	responses.Success(c, "Linked ban settings updated successfully", &gin.H{"settings": settings})
+}
+
+// ServerBanIdentityMatches lists the players recently matched to a ban of a
+// server through a linked identity
+func (s *Server) ServerBanIdentityMatches(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 50
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			responses.BadRequest(c, "Invalid limit", &gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = min(limit, maxBanIdentityMatches)
+	}
+
+	matches, err := core.GetBanIdentityMatches(c.Request.Context(), s.Dependencies.DB, serverId, limit)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Ban identity matches fetched successfully", &gin.H{"matches": matches})
+}
+
+func validateLinkedBanSettings(req *models.LinkedBanSettingsUpdateRequest) error {
+	if req.Action != models.LinkedBanActionKick && req.Action != models.LinkedBanActionFlag {
+		return errors.New("action must be kick or flag")
+	}
+	if req.MinConfidence < 1 || req.MinConfidence > 100 {
+		return errors.New("min_confidence must be between 1 and 100")
+	}
+	return nil
+}
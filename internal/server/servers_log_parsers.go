@@ -0,0 +1,325 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/logwatcher_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+)
+
+const (
+	// maxLogParsersPerServer bounds the number of regexes run against every log line
+	maxLogParsersPerServer = 50
+	// maxLogParserTestLines bounds the sample lines accepted by the test endpoint
+	maxLogParserTestLines = 100
+)
+
+// ServerLogParsersList lists the custom log parsers of a server
+func (s *Server) ServerLogParsersList(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	parsers, err := core.GetServerLogParsers(c.Request.Context(), s.Dependencies.DB, serverId)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Log parsers fetched successfully", &gin.H{"parsers": parsers})
+}
+
+// ServerLogParserCreate adds a custom log parser to a server
+func (s *Server) ServerLogParserCreate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ServerLogParserCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.BadRequest(c, "Invalid request body", &gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := core.GetServerLogParsers(c.Request.Context(), s.Dependencies.DB, serverId)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+	if len(existing) >= maxLogParsersPerServer {
+		responses.BadRequest(c, "Too many log parsers", &gin.H{"error": "servers can have at most 50 log parsers"})
+		return
+	}
+
+	parser := &models.ServerLogParser{
+		ID:           uuid.New(),
+		ServerID:     serverId,
+		Name:         strings.TrimSpace(req.Name),
+		EventName:    req.EventName,
+		Pattern:      req.Pattern,
+		FieldMapping: req.FieldMapping,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+	}
+
+	if !s.validateLogParser(c, parser) {
+		return
+	}
+
+	if err := core.CreateServerLogParser(c.Request.Context(), s.Dependencies.DB, parser); err != nil {
+		if isUniqueViolation(err) {
+			responses.Conflict(c, "A log parser with this name already exists", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.reloadLogParsers(c.Request.Context(), serverId)
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:log_parser:create", map[string]interface{}{
+		"parserId":  parser.ID.String(),
+		"name":      parser.Name,
+		"eventName": parser.EventName,
+		"pattern":   parser.Pattern,
+	})
+
+	responses.Success(c, "Log parser created successfully", &gin.H{"parser": parser})
+}
+
+// ServerLogParserUpdate changes a custom log parser
+func (s *Server) ServerLogParserUpdate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	parserId, err := uuid.Parse(c.Param("parserId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid parser ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ServerLogParserUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.BadRequest(c, "Invalid request body", &gin.H{"error": err.Error()})
+		return
+	}
+
+	parser, err := core.GetServerLogParser(c.Request.Context(), s.Dependencies.DB, serverId, parserId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Log parser not found", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if req.Name != nil {
+		parser.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.EventName != nil {
+		parser.EventName = *req.EventName
+	}
+	if req.Pattern != nil {
+		parser.Pattern = *req.Pattern
+	}
+	if req.FieldMapping != nil {
+		parser.FieldMapping = req.FieldMapping
+	}
+	if req.Enabled != nil {
+		parser.Enabled = *req.Enabled
+	}
+
+	if !s.validateLogParser(c, parser) {
+		return
+	}
+
+	if err := core.UpdateServerLogParser(c.Request.Context(), s.Dependencies.DB, parser); err != nil {
+		if isUniqueViolation(err) {
+			responses.Conflict(c, "A log parser with this name already exists", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.reloadLogParsers(c.Request.Context(), serverId)
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:log_parser:update", map[string]interface{}{
+		"parserId":  parser.ID.String(),
+		"name":      parser.Name,
+		"eventName": parser.EventName,
+		"pattern":   parser.Pattern,
+		"enabled":   parser.Enabled,
+	})
+
+	responses.Success(c, "Log parser updated successfully", &gin.H{"parser": parser})
+}
+
+// ServerLogParserDelete removes a custom log parser
+func (s *Server) ServerLogParserDelete(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	parserId, err := uuid.Parse(c.Param("parserId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid parser ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := core.DeleteServerLogParser(c.Request.Context(), s.Dependencies.DB, serverId, parserId); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.reloadLogParsers(c.Request.Context(), serverId)
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:log_parser:delete", map[string]interface{}{
+		"parserId": parserId.String(),
+	})
+
+	responses.Success(c, "Log parser deleted successfully", nil)
+}
+
+// ServerLogParserTest runs sample log lines against a parser definition
+// without saving it, returning the event each line would publish
+func (s *Server) ServerLogParserTest(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ServerLogParserTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.BadRequest(c, "Invalid request body", &gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Lines) > maxLogParserTestLines {
+		responses.BadRequest(c, "Too many sample lines", &gin.H{"error": "at most 100 lines can be tested at once"})
+		return
+	}
+
+	eventName := req.EventName
+	if eventName == "" {
+		eventName = "test"
+	}
+
+	parser, err := logwatcher_manager.CompileCustomParser(&models.ServerLogParser{
+		Name:         "test",
+		EventName:    eventName,
+		Pattern:      req.Pattern,
+		FieldMapping: req.FieldMapping,
+	})
+	if err != nil {
+		responses.BadRequest(c, "Invalid log parser", &gin.H{"error": err.Error()})
+		return
+	}
+
+	results := make([]gin.H, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		line = strings.TrimSpace(line)
+		data, matched := parser.Match(line)
+
+		result := gin.H{"line": line, "matched": matched}
+		if matched {
+			result["event"] = data
+		}
+		results = append(results, result)
+	}
+
+	responses.Success(c, "Log parser tested successfully", &gin.H{
+		"fields":  parser.Fields(),
+		"results": results,
+	})
+}
+
+// validateLogParser checks that a parser compiles, responding with the error
+// when it doesn't
+func (s *Server) validateLogParser(c *gin.Context, parser *models.ServerLogParser) bool {
+	if parser.Name == "" || len(parser.Name) > 100 {
+		responses.BadRequest(c, "Invalid log parser", &gin.H{"error": "name must be 1-100 characters"})
+		return false
+	}
+
+	if parser.FieldMapping == nil {
+		parser.FieldMapping = map[string]string{}
+	}
+
+	if _, err := logwatcher_manager.CompileCustomParser(parser); err != nil {
+		responses.BadRequest(c, "Invalid log parser", &gin.H{"error": err.Error()})
+		return false
+	}
+
+	return true
+}
+
+// reloadLogParsers applies the stored parsers of a server to its log watcher
+func (s *Server) reloadLogParsers(ctx context.Context, serverId uuid.UUID) {
+	parsers, err := core.GetServerLogParsers(ctx, s.Dependencies.DB, serverId)
+	if err != nil {
+		log.Error().Err(err).Str("serverID", serverId.String()).Msg("Failed to reload custom log parsers")
+		return
+	}
+
+	s.Dependencies.LogwatcherManager.ReloadCustomParsers(serverId, parsers)
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/logwatcher_manager"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+)
+
+// maxLogPushMessageSize limits a single batch sent over the WebSocket or
+// streamed in a request body
+const maxLogPushMessageSize = 1024 * 1024
+
+// batchLimitReader caps how much of a streamed request body is read for a
+// single batch, so one oversized batch can't be buffered without bound
+type batchLimitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func newBatchLimitReader(r io.Reader) *batchLimitReader {
+	return &batchLimitReader{r: r, remaining: maxLogPushMessageSize}
+}
+
+func (l *batchLimitReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		return 0, fmt.Errorf("batch is larger than %d bytes", maxLogPushMessageSize)
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// reset starts the allowance for the next batch
+func (l *batchLimitReader) reset() {
+	l.remaining = maxLogPushMessageSize
+}
+
+// ServerLogPushTokenCreate generates a new token for a server's log shipper
+// agent. The token is only returned once; Aegis keeps a hash of it.
+func (s *Server) ServerLogPushTokenCreate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to generate token"})
+		return
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	if err := core.SetServerLogPushToken(c.Request.Context(), s.Dependencies.DB, serverId, hashLogPushToken(token)); err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to store token"})
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:logwatcher:push_token_create", map[string]interface{}{
+		"serverId": serverId.String(),
+	})
+
+	responses.Success(c, "Log push token created successfully", &gin.H{"token": token})
+}
+
+// ServerLogPushTokenDelete revokes a server's log shipper agent token
+func (s *Server) ServerLogPushTokenDelete(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := core.DeleteServerLogPush(c.Request.Context(), s.Dependencies.DB, serverId); err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to revoke token"})
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:logwatcher:push_token_delete", map[string]interface{}{
+		"serverId": serverId.String(),
+	})
+
+	responses.Success(c, "Log push token revoked successfully", nil)
+}
+
+// AuthLogPushToken checks the bearer token of a log shipper agent against the
+// token stored for the server in the URL
+func (s *Server) AuthLogPushToken(c *gin.Context) {
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	push, err := core.GetServerLogPush(c.Request.Context(), s.Dependencies.DB, serverId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.Unauthorized(c, "Unauthorized", nil)
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashLogPushToken(token)), []byte(push.TokenHash)) != 1 {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	c.Set("logPushServerId", serverId)
+}
+
+// LogIngestPosition returns the last line accepted for the server, so an
+// agent knows which file offset and sequence number to resume from
+func (s *Server) LogIngestPosition(c *gin.Context) {
+	serverId := c.MustGet("logPushServerId").(uuid.UUID)
+
+	position, err := s.Dependencies.LogwatcherManager.GetPushPosition(serverId)
+	if err != nil {
+		responses.Conflict(c, "Server is not using the push log source", &gin.H{"error": err.Error()})
+		return
+	}
+
+	responses.Success(c, "Log push position fetched successfully", &gin.H{"position": position})
+}
+
+// LogIngestLines accepts a stream of newline-delimited JSON batches, which
+// lets an agent keep a chunked request open and send batches as lines are
+// written. The response carries the position after the last batch.
+func (s *Server) LogIngestLines(c *gin.Context) {
+	serverId := c.MustGet("logPushServerId").(uuid.UUID)
+
+	body := newBatchLimitReader(c.Request.Body)
+	decoder := json.NewDecoder(body)
+	batches := 0
+
+	var position logwatcher_manager.PushPosition
+	for {
+		var batch logwatcher_manager.PushBatch
+		if err := decoder.Decode(&batch); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			responses.BadRequest(c, "Invalid batch", &gin.H{"error": err.Error(), "batches": batches, "position": position})
+			return
+		}
+		body.reset()
+
+		var err error
+		position, err = s.pushLogBatch(c.Request.Context(), serverId, batch)
+		if err != nil {
+			if errors.Is(err, logwatcher_manager.ErrNoPushSource) {
+				responses.Conflict(c, "Server is not using the push log source", &gin.H{"error": err.Error()})
+				return
+			}
+			responses.BadRequest(c, "Failed to push batch", &gin.H{"error": err.Error(), "batches": batches, "position": position})
+			return
+		}
+		batches++
+	}
+
+	if batches == 0 {
+		var err error
+		position, err = s.Dependencies.LogwatcherManager.GetPushPosition(serverId)
+		if err != nil {
+			responses.Conflict(c, "Server is not using the push log source", &gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	responses.Success(c, "Log lines accepted", &gin.H{"batches": batches, "position": position})
+}
+
+// LogIngestWebSocket accepts batches over a WebSocket. The server first sends
+// the position to resume from, then acknowledges every batch with the
+// position after it.
+func (s *Server) LogIngestWebSocket(c *gin.Context) {
+	serverId := c.MustGet("logPushServerId").(uuid.UUID)
+
+	position, err := s.Dependencies.LogwatcherManager.GetPushPosition(serverId)
+	if err != nil {
+		responses.Conflict(c, "Server is not using the push log source", &gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		responses.BadRequest(c, "Failed to upgrade to WebSocket", &gin.H{"error": err.Error()})
+		return
+	}
+	defer conn.Close()
+
+	conn.SetReadLimit(maxLogPushMessageSize)
+
+	log.Info().Str("serverID", serverId.String()).Msg("Log shipper agent connected")
+	defer log.Info().Str("serverID", serverId.String()).Msg("Log shipper agent disconnected")
+
+	if err := conn.WriteJSON(gin.H{"type": "position", "seq": position.Sequence, "offset": position.Offset}); err != nil {
+		return
+	}
+
+	for {
+		var batch logwatcher_manager.PushBatch
+		if err := conn.ReadJSON(&batch); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Warn().Err(err).Str("serverID", serverId.String()).Msg("Log shipper agent connection lost")
+			}
+			return
+		}
+
+		position, err := s.pushLogBatch(c.Request.Context(), serverId, batch)
+		if err != nil {
+			_ = conn.WriteJSON(gin.H{"type": "error", "error": err.Error(), "seq": position.Sequence, "offset": position.Offset})
+			return
+		}
+
+		if err := conn.WriteJSON(gin.H{"type": "ack", "seq": position.Sequence, "offset": position.Offset}); err != nil {
+			return
+		}
+	}
+}
+
+// pushLogBatch feeds a batch into the log pipeline and saves the new position
+// before it is acknowledged, so the agent can resume after a restart
+func (s *Server) pushLogBatch(ctx context.Context, serverId uuid.UUID, batch logwatcher_manager.PushBatch) (logwatcher_manager.PushPosition, error) {
+	before, err := s.Dependencies.LogwatcherManager.GetPushPosition(serverId)
+	if err != nil {
+		return before, err
+	}
+
+	position, err := s.Dependencies.LogwatcherManager.PushLogLines(ctx, serverId, batch)
+	if err != nil {
+		return position, err
+	}
+
+	if position != before {
+		saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := core.UpdateServerLogPushPosition(saveCtx, s.Dependencies.DB, serverId, position.Sequence, position.Offset); err != nil {
+			return position, err
+		}
+	}
+
+	return position, nil
+}
+
+// hashLogPushToken returns the hex SHA-256 of an agent token
+func hashLogPushToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
 	"go.codycody31.dev/squad-aegis/internal/server/responses"
 )
 
@@ -35,6 +36,7 @@ type ServerMetricsData struct {
 	PlayerDamagedStats     []MetricPoint  `json:"player_damaged_stats"`
 	DeployableDamagedStats []MetricPoint  `json:"deployable_damaged_stats"`
 	AdminBroadcastStats    []MetricPoint  `json:"admin_broadcast_stats"`
+	RconAvailability       []MetricPoint  `json:"rcon_availability"` // RCON uptime percentage per interval
 	Period                 string         `json:"period"`
 	Summary                MetricsSummary `json:"summary"`
 }
@@ -58,6 +60,8 @@ type MetricsSummary struct {
 	TotalPluginLogs        int     `json:"total_plugin_logs"`
 	MostPlayedMap          string  `json:"most_played_map"`
 	PeakPlayerCount        int     `json:"peak_player_count"`
+	RconUptimePercent      float64 `json:"rcon_uptime_percent"`
+	RconOutages            int     `json:"rcon_outages"`
 }
 
 // ServerMetricsHistory provides detailed metrics history from ClickHouse
@@ -199,6 +203,7 @@ func (s *Server) getMetricsFromClickHouse(ctx context.Context, serverId uuid.UUI
 		PlayerDamagedStats:     []MetricPoint{},
 		DeployableDamagedStats: []MetricPoint{},
 		AdminBroadcastStats:    []MetricPoint{},
+		RconAvailability:       []MetricPoint{},
 	}
 
 	// Query player count metrics from server info data (including public/reserved queue)
@@ -764,6 +769,18 @@ func (s *Server) getMetricsFromClickHouse(ctx context.Context, serverId uuid.UUI
 		}
 	}
 
+	// Query RCON availability from the recorded connection state changes
+	initialRconState, rconStateChanges, err := getRconStateChanges(ctx, clickhouseClient, serverId, startTime, endTime)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to query RCON availability from ClickHouse")
+	} else {
+		metricsData.RconAvailability = rconAvailabilitySeries(initialRconState, rconStateChanges, startTime, endTime, intervalMinutes)
+
+		rconSummary := rcon_manager.Availability(initialRconState, rconStateChanges, startTime, endTime)
+		metricsData.Summary.RconUptimePercent = rconSummary.UptimePercent
+		metricsData.Summary.RconOutages = rconSummary.Outages
+	}
+
 	// Calculate summary metrics
 	if len(metricsData.PlayerCount) > 0 {
 		// Current players is the last data point
@@ -13,6 +13,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"go.codycody31.dev/squad-aegis/internal/commands"
 	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
 	"go.codycody31.dev/squad-aegis/internal/server/responses"
 	squadRcon "go.codycody31.dev/squad-aegis/internal/squad-rcon"
 )
@@ -152,7 +153,10 @@ func (s *Server) ServerRconExecute(c *gin.Context) {
 	}
 
 	// Execute command using RCON manager
-	response, err := s.Dependencies.RconManager.ExecuteCommand(serverId, request.Command)
+	response, err := s.Dependencies.RconManager.ExecuteCommandWithOptions(serverId, request.Command, rcon_manager.CommandOptions{
+		Priority: rcon_manager.PriorityHigh,
+		Caller:   rcon_manager.UserCaller(user.Id),
+	})
 	if err != nil {
 		responses.BadRequest(c, "Failed to execute RCON command", &gin.H{"error": err.Error()})
 		return
@@ -236,7 +240,8 @@ func (s *Server) ServerRconKickPlayer(c *gin.Context) {
 		return
 	}
 
-	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId)
+	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId).
+		AsCaller(rcon_manager.UserCaller(user.Id), rcon_manager.PriorityHigh)
 
 	// Format the kick command
 	kickCommand := "AdminKick " + request.SteamId
@@ -279,7 +284,8 @@ func (s *Server) ServerRconWarnPlayer(c *gin.Context) {
 		return
 	}
 
-	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId)
+	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId).
+		AsCaller(rcon_manager.UserCaller(user.Id), rcon_manager.PriorityHigh)
 	response, err := r.ExecuteRaw("AdminWarn " + request.SteamId + " " + request.Message)
 	if err != nil {
 		responses.BadRequest(c, "Failed to warn player", &gin.H{"error": err.Error()})
@@ -314,7 +320,8 @@ func (s *Server) ServerRconMovePlayer(c *gin.Context) {
 		return
 	}
 
-	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId)
+	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId).
+		AsCaller(rcon_manager.UserCaller(user.Id), rcon_manager.PriorityHigh)
 	response, err := r.ExecuteRaw("AdminForceTeamChange " + request.SteamId)
 	if err != nil {
 		responses.BadRequest(c, "Failed to move player", &gin.H{"error": err.Error()})
@@ -472,7 +479,8 @@ func (s *Server) ServerRconPlayerKick(c *gin.Context) {
 		return
 	}
 
-	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId)
+	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId).
+		AsCaller(rcon_manager.UserCaller(user.Id), rcon_manager.PriorityHigh)
 
 	// Format the kick command
 	kickCommand := "AdminKick " + request.SteamId
@@ -582,7 +590,8 @@ func (s *Server) ServerRconPlayerBan(c *gin.Context) {
 		return
 	}
 
-	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId)
+	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId).
+		AsCaller(rcon_manager.UserCaller(user.Id), rcon_manager.PriorityHigh)
 
 	if server.BanEnforcementMode == "aegis" {
 		// In aegis mode, just kick the player now; the ban enforcer handles future connections
@@ -649,7 +658,8 @@ func (s *Server) ServerRconPlayerWarn(c *gin.Context) {
 		return
 	}
 
-	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId)
+	r := squadRcon.NewSquadRcon(s.Dependencies.RconManager, serverId).
+		AsCaller(rcon_manager.UserCaller(user.Id), rcon_manager.PriorityHigh)
 	response, err := r.ExecuteRaw("AdminWarn " + request.SteamId + " " + request.Message)
 	if err != nil {
 		responses.BadRequest(c, "Failed to warn player", &gin.H{"error": err.Error()})
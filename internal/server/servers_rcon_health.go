@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/clickhouse"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
+)
+
+// rconConnectionStatus describes the supervised RCON connection of a server,
+// nil when there is none
+func (s *Server) rconConnectionStatus(serverId uuid.UUID) *gin.H {
+	stats, err := s.Dependencies.RconManager.GetConnectionStats(serverId)
+	if err != nil {
+		return nil
+	}
+
+	status := gin.H{
+		"state":                stats.State,
+		"state_since":          stats.StateSince,
+		"consecutive_failures": stats.ConsecutiveFailures,
+		"latency_ms":           stats.LatencyMs,
+		"last_error":           stats.LastError,
+		"last_health_check":    stats.LastHealthCheck,
+		"reconnect_count":      stats.ReconnectCount,
+		"queue_length":         stats.QueueLength,
+		"queue":                stats.Queue,
+	}
+	if !stats.NextRetry.IsZero() {
+		status["next_retry"] = stats.NextRetry
+	}
+
+	return &status
+}
+
+// getRconStateChanges returns the RCON state of a server at start and the
+// state changes recorded between start and end
+func getRconStateChanges(ctx context.Context, client *clickhouse.Client, serverId uuid.UUID, start, end time.Time) (rcon_manager.ConnectionState, []rcon_manager.StateChange, error) {
+	var initial string
+	err := client.QueryRow(ctx, `
+		SELECT state
+		FROM squad_aegis.server_rcon_availability
+		WHERE server_id = ?
+		AND event_time < ?
+		ORDER BY event_time DESC
+		LIMIT 1
+	`, serverId, start).Scan(&initial)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", nil, err
+	}
+
+	rows, err := client.Query(ctx, `
+		SELECT event_time, state
+		FROM squad_aegis.server_rcon_availability
+		WHERE server_id = ?
+		AND event_time >= ?
+		AND event_time < ?
+		ORDER BY event_time ASC
+	`, serverId, start, end)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	changes := []rcon_manager.StateChange{}
+	for rows.Next() {
+		var change rcon_manager.StateChange
+		var state string
+		if err := rows.Scan(&change.Time, &state); err != nil {
+			return "", nil, err
+		}
+		change.State = rcon_manager.ConnectionState(state)
+		changes = append(changes, change)
+	}
+
+	return rcon_manager.ConnectionState(initial), changes, rows.Err()
+}
+
+// rconAvailabilitySeries splits the RCON uptime percentage into intervals,
+// leaving out intervals without a known state
+func rconAvailabilitySeries(initial rcon_manager.ConnectionState, changes []rcon_manager.StateChange, startTime, endTime time.Time, intervalMinutes int) []MetricPoint {
+	points := []MetricPoint{}
+	interval := time.Duration(intervalMinutes) * time.Minute
+
+	for current := startTime.Truncate(interval); current.Before(endTime); current = current.Add(interval) {
+		from := maxTime(current, startTime)
+		to := current.Add(interval)
+		if to.After(endTime) {
+			to = endTime
+		}
+
+		summary := rcon_manager.Availability(initial, changes, from, to)
+		if summary.ConnectedSeconds+summary.DegradedSeconds+summary.DisconnectedSeconds == 0 {
+			continue
+		}
+
+		points = append(points, MetricPoint{
+			Timestamp: current,
+			Value:     math.Round(summary.UptimePercent*100) / 100,
+		})
+	}
+
+	return points
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
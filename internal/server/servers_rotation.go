@@ -0,0 +1,282 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/rotation_manager"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+)
+
+const (
+	// maxRotationLayers bounds the size of a rotation pool
+	maxRotationLayers = 200
+	// maxRotationHistory bounds the layer history returned by the history endpoint
+	maxRotationHistory = 200
+)
+
+// ServerRotationGet returns the map rotation of a server
+func (s *Server) ServerRotationGet(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	rotation, err := core.GetServerRotation(c.Request.Context(), s.Dependencies.DB, serverId)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Rotation fetched successfully", &gin.H{"rotation": rotation})
+}
+
+// ServerRotationUpdate replaces the map rotation of a server
+func (s *Server) ServerRotationUpdate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ServerRotationUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.BadRequest(c, "Invalid request body", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateRotation(&req); err != nil {
+		responses.BadRequest(c, "Invalid rotation", &gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := core.GetServerRotation(c.Request.Context(), s.Dependencies.DB, serverId)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	rotation := &models.ServerRotation{
+		ServerID:            serverId,
+		Enabled:             req.Enabled,
+		Mode:                req.Mode,
+		MapRepeatWindow:     req.MapRepeatWindow,
+		FactionRepeatWindow: req.FactionRepeatWindow,
+		Layers:              req.Layers,
+	}
+	if len(rotation.Layers) > 0 && existing.Position < len(rotation.Layers) {
+		rotation.Position = existing.Position
+	}
+
+	if err := core.SaveServerRotation(c.Request.Context(), s.Dependencies.DB, rotation); err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:rotation:update", map[string]interface{}{
+		"enabled":             rotation.Enabled,
+		"mode":                rotation.Mode,
+		"mapRepeatWindow":     rotation.MapRepeatWindow,
+		"factionRepeatWindow": rotation.FactionRepeatWindow,
+		"layerCount":          len(rotation.Layers),
+	})
+
+	responses.Success(c, "Rotation updated successfully", &gin.H{"rotation": rotation})
+}
+
+// ServerRotationEligible lists the rotation layers that may be picked next,
+// given the current player count, time of day and layer history
+func (s *Server) ServerRotationEligible(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	layers, err := s.Dependencies.RotationManager.GetEligibleLayers(c.Request.Context(), serverId)
+	if err != nil && !errors.Is(err, rotation_manager.ErrEmptyRotation) {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+	if layers == nil {
+		layers = []models.RotationLayer{}
+	}
+
+	responses.Success(c, "Eligible layers fetched successfully", &gin.H{"layers": layers})
+}
+
+// ServerRotationHistory lists the most recent layers played on a server
+func (s *Server) ServerRotationHistory(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 25
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			responses.BadRequest(c, "Invalid limit", &gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		limit = min(limit, maxRotationHistory)
+	}
+
+	history, err := s.Dependencies.RotationManager.GetLayerHistory(c.Request.Context(), serverId, limit)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Layer history fetched successfully", &gin.H{"history": history})
+}
+
+// ServerRotationSetNext sets the next layer of a server
+func (s *Server) ServerRotationSetNext(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	serverId, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	server, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverId, user)
+	if err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ServerRotationSetNextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.BadRequest(c, "Invalid request body", &gin.H{"error": err.Error()})
+		return
+	}
+
+	layer := models.RotationLayer{
+		Layer:        strings.TrimSpace(req.Layer),
+		Team1Faction: strings.TrimSpace(req.Team1Faction),
+		Team2Faction: strings.TrimSpace(req.Team2Faction),
+	}
+	if (layer.Team1Faction == "") != (layer.Team2Faction == "") {
+		responses.BadRequest(c, "Invalid factions", &gin.H{"error": "set both factions or neither"})
+		return
+	}
+
+	ipAddress := server.IpAddress
+	if server.RconIpAddress != nil {
+		ipAddress = *server.RconIpAddress
+	}
+
+	if err := s.Dependencies.RconManager.ConnectToServer(serverId, ipAddress, server.RconPort, server.RconPassword); err != nil {
+		responses.BadRequest(c, "Failed to connect to RCON", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.Dependencies.RotationManager.SetNextLayer(c.Request.Context(), serverId, layer, rotation_manager.SourceAdmin); err != nil {
+		responses.BadRequest(c, "Failed to set next layer", &gin.H{"error": err.Error()})
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &serverId, &user.Id, "server:rotation:set_next", map[string]interface{}{
+		"layer":        layer.Layer,
+		"team1Faction": layer.Team1Faction,
+		"team2Faction": layer.Team2Faction,
+	})
+
+	responses.Success(c, "Next layer set successfully", &gin.H{"command": rotation_manager.NextLayerCommand(layer)})
+}
+
+// validateRotation checks a rotation update and normalises its layers
+func validateRotation(req *models.ServerRotationUpdateRequest) error {
+	if req.Mode == "" {
+		req.Mode = models.RotationModeOrdered
+	}
+	if req.Mode != models.RotationModeOrdered && req.Mode != models.RotationModeWeighted {
+		return fmt.Errorf("mode must be %q or %q", models.RotationModeOrdered, models.RotationModeWeighted)
+	}
+	if req.MapRepeatWindow < 0 || req.FactionRepeatWindow < 0 {
+		return fmt.Errorf("repeat windows cannot be negative")
+	}
+	if len(req.Layers) > maxRotationLayers {
+		return fmt.Errorf("rotations can have at most %d layers", maxRotationLayers)
+	}
+	if req.Enabled && len(req.Layers) == 0 {
+		return fmt.Errorf("an enabled rotation needs at least one layer")
+	}
+	if req.Layers == nil {
+		req.Layers = []models.RotationLayer{}
+	}
+
+	for i := range req.Layers {
+		layer := &req.Layers[i]
+		layer.Layer = strings.TrimSpace(layer.Layer)
+		layer.Team1Faction = strings.TrimSpace(layer.Team1Faction)
+		layer.Team2Faction = strings.TrimSpace(layer.Team2Faction)
+
+		if layer.Layer == "" {
+			return fmt.Errorf("layer %d has no name", i+1)
+		}
+		if strings.ContainsAny(layer.Layer+layer.Team1Faction+layer.Team2Faction, " \t\r\n") {
+			return fmt.Errorf("layer %d: names cannot contain whitespace", i+1)
+		}
+		if (layer.Team1Faction == "") != (layer.Team2Faction == "") {
+			return fmt.Errorf("layer %d: set both factions or neither", i+1)
+		}
+		if layer.Weight < 0 {
+			return fmt.Errorf("layer %d: weight cannot be negative", i+1)
+		}
+		if layer.MinPlayers < 0 || layer.MaxPlayers < 0 {
+			return fmt.Errorf("layer %d: player limits cannot be negative", i+1)
+		}
+		if layer.MaxPlayers > 0 && layer.MinPlayers > layer.MaxPlayers {
+			return fmt.Errorf("layer %d: min players is above max players", i+1)
+		}
+		if (layer.StartHour == nil) != (layer.EndHour == nil) {
+			return fmt.Errorf("layer %d: set both start and end hour or neither", i+1)
+		}
+		if layer.StartHour != nil {
+			if *layer.StartHour < 0 || *layer.StartHour > 23 || *layer.EndHour < 0 || *layer.EndHour > 23 {
+				return fmt.Errorf("layer %d: hours must be between 0 and 23", i+1)
+			}
+		}
+	}
+
+	return nil
+}
@@ -114,6 +114,12 @@ func (s *Server) ServerWorkflowCreate(c *gin.Context) {
 		return
 	}
 
+	definitionValidation := workflow_manager.ValidateWorkflowDefinition(&request.Definition, nil)
+	if !definitionValidation.Valid {
+		responses.BadRequest(c, "Workflow definition is invalid", &gin.H{"validation": definitionValidation})
+		return
+	}
+
 	// Create workflow
 	workflow := &models.ServerWorkflow{
 		ID:          uuid.New(),
@@ -140,7 +146,55 @@ func (s *Server) ServerWorkflowCreate(c *gin.Context) {
 	}
 
 	responses.Success(c, "Workflow created successfully", &gin.H{
-		"workflow": workflow,
+		"workflow":   workflow,
+		"validation": definitionValidation,
+	})
+}
+
+// ServerWorkflowValidate checks a workflow definition without saving it. When
+// workflow_id is given, that workflow's stored variables count as defined.
+func (s *Server) ServerWorkflowValidate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	serverID, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var request models.WorkflowValidateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var variableNames []string
+	if value := c.Query("workflow_id"); value != "" {
+		workflowID, err := uuid.Parse(value)
+		if err != nil {
+			responses.BadRequest(c, "Invalid workflow ID", &gin.H{"error": err.Error()})
+			return
+		}
+
+		workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+		workflow, err := workflowDB.GetWorkflow(workflowID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			responses.InternalServerError(c, err, &gin.H{"error": "Failed to get workflow"})
+			return
+		}
+		if err != nil || workflow.ServerID != serverID {
+			responses.NotFound(c, "Workflow not found", nil)
+			return
+		}
+		variableNames = workflow_manager.WorkflowVariableNames(workflow.Variables)
+	}
+
+	responses.Success(c, "Workflow definition validated", &gin.H{
+		"validation": workflow_manager.ValidateWorkflowDefinition(&request.Definition, variableNames),
 	})
 }
 
@@ -204,8 +258,14 @@ func (s *Server) ServerWorkflowUpdate(c *gin.Context) {
 	}
 	workflow.UpdatedAt = time.Now()
 
+	definitionValidation := workflow_manager.ValidateWorkflowDefinition(&workflow.Definition, workflow_manager.WorkflowVariableNames(workflow.Variables))
+	if request.Definition != nil && !definitionValidation.Valid {
+		responses.BadRequest(c, "Workflow definition is invalid", &gin.H{"validation": definitionValidation})
+		return
+	}
+
 	// Update workflow
-	if err := workflowDB.UpdateWorkflow(workflow); err != nil {
+	if err := workflowDB.UpdateWorkflow(workflow, user.Id, request.ChangeSummary); err != nil {
 		responses.InternalServerError(c, err, &gin.H{"error": "Failed to update workflow"})
 		return
 	}
@@ -216,7 +276,8 @@ func (s *Server) ServerWorkflowUpdate(c *gin.Context) {
 	}
 
 	responses.Success(c, "Workflow updated successfully", &gin.H{
-		"workflow": workflow,
+		"workflow":   workflow,
+		"validation": definitionValidation,
 	})
 }
 
@@ -273,6 +334,184 @@ func (s *Server) ServerWorkflowDelete(c *gin.Context) {
 	responses.Success(c, "Workflow deleted successfully", nil)
 }
 
+// getServerWorkflow loads a workflow and verifies it belongs to the server in the request path.
+// It writes the error response and returns nil when the workflow can't be used.
+func (s *Server) getServerWorkflow(c *gin.Context, workflowDB *workflow_manager.WorkflowDatabase) *models.ServerWorkflow {
+	serverID, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return nil
+	}
+
+	workflowID, err := uuid.Parse(c.Param("workflowId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid workflow ID", &gin.H{"error": err.Error()})
+		return nil
+	}
+
+	workflow, err := workflowDB.GetWorkflow(workflowID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Workflow not found", nil)
+			return nil
+		}
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to get workflow"})
+		return nil
+	}
+
+	if workflow.ServerID != serverID {
+		responses.NotFound(c, "Workflow not found", nil)
+		return nil
+	}
+
+	return workflow
+}
+
+// getWorkflowRevision loads the revision named by a request parameter
+func (s *Server) getWorkflowRevision(c *gin.Context, workflowDB *workflow_manager.WorkflowDatabase, workflowID uuid.UUID, value string) *models.ServerWorkflowRevision {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		responses.BadRequest(c, "Invalid revision", &gin.H{"revision": value})
+		return nil
+	}
+
+	revision, err := workflowDB.GetWorkflowRevision(workflowID, number)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Revision not found", nil)
+			return nil
+		}
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to get revision"})
+		return nil
+	}
+
+	return revision
+}
+
+// ServerWorkflowRevisionsList returns the revision history of a workflow
+func (s *Server) ServerWorkflowRevisionsList(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+	workflow := s.getServerWorkflow(c, workflowDB)
+	if workflow == nil {
+		return
+	}
+
+	revisions, err := workflowDB.GetWorkflowRevisions(workflow.ID)
+	if err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to get revisions"})
+		return
+	}
+
+	responses.Success(c, "Revisions retrieved successfully", &gin.H{
+		"current_revision": workflow.Revision,
+		"revisions":        revisions,
+	})
+}
+
+// ServerWorkflowRevisionGet returns a single revision of a workflow
+func (s *Server) ServerWorkflowRevisionGet(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+	workflow := s.getServerWorkflow(c, workflowDB)
+	if workflow == nil {
+		return
+	}
+
+	revision := s.getWorkflowRevision(c, workflowDB, workflow.ID, c.Param("revision"))
+	if revision == nil {
+		return
+	}
+
+	responses.Success(c, "Revision retrieved successfully", &gin.H{
+		"revision": revision,
+	})
+}
+
+// ServerWorkflowRevisionsDiff compares two revisions of a workflow. The "to"
+// revision defaults to the current one.
+func (s *Server) ServerWorkflowRevisionsDiff(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+	workflow := s.getServerWorkflow(c, workflowDB)
+	if workflow == nil {
+		return
+	}
+
+	from := s.getWorkflowRevision(c, workflowDB, workflow.ID, c.Query("from"))
+	if from == nil {
+		return
+	}
+
+	to := s.getWorkflowRevision(c, workflowDB, workflow.ID, c.DefaultQuery("to", strconv.Itoa(workflow.Revision)))
+	if to == nil {
+		return
+	}
+
+	responses.Success(c, "Revisions compared successfully", &gin.H{
+		"from":    from.Revision,
+		"to":      to.Revision,
+		"changes": workflow_manager.DiffWorkflowRevisions(from, to),
+	})
+}
+
+// ServerWorkflowRollback restores an earlier revision of a workflow as a new revision
+func (s *Server) ServerWorkflowRollback(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	var request models.WorkflowRollbackRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+	workflow := s.getServerWorkflow(c, workflowDB)
+	if workflow == nil {
+		return
+	}
+
+	revision := s.getWorkflowRevision(c, workflowDB, workflow.ID, c.Param("revision"))
+	if revision == nil {
+		return
+	}
+
+	if err := workflowDB.RestoreWorkflowRevision(workflow, revision, user.Id, request.ChangeSummary); err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to roll back workflow"})
+		return
+	}
+
+	// Reload workflows in the workflow manager
+	if err := s.Dependencies.WorkflowManager.ReloadWorkflows(); err != nil {
+		// Log error but don't fail the request since workflow was updated
+	}
+
+	responses.Success(c, "Workflow rolled back successfully", &gin.H{
+		"workflow": workflow,
+	})
+}
+
 // ServerWorkflowExecutions returns execution history for a workflow
 func (s *Server) ServerWorkflowExecutions(c *gin.Context) {
 	user := s.getUserFromSession(c)
@@ -384,8 +623,14 @@ func (s *Server) ServerWorkflowExecutionStats(c *gin.Context) {
 		return
 	}
 
+	running, queued := s.Dependencies.WorkflowManager.ConcurrencyStatus(workflowID)
+
 	responses.Success(c, "Execution statistics retrieved successfully", &gin.H{
 		"stats": stats,
+		"concurrency": gin.H{
+			"running": running,
+			"queued":  queued,
+		},
 	})
 }
 
@@ -633,6 +878,97 @@ func (s *Server) ServerWorkflowExecutionMessages(c *gin.Context) {
 	})
 }
 
+// ServerWorkflowSimulate dry-runs a workflow and returns the trace and captured side effects
+func (s *Server) ServerWorkflowSimulate(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	serverID, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	workflowID, err := uuid.Parse(c.Param("workflowId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid workflow ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	var request models.WorkflowSimulateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+
+	workflow, err := workflowDB.GetWorkflow(workflowID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Workflow not found", nil)
+			return
+		}
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to get workflow"})
+		return
+	}
+
+	if workflow.ServerID != serverID {
+		responses.NotFound(c, "Workflow not found", nil)
+		return
+	}
+
+	// Replay the trigger event of a previous execution
+	if request.ReplayExecutionID != nil {
+		execution, err := workflowDB.GetWorkflowExecution(*request.ReplayExecutionID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				responses.NotFound(c, "Execution not found", nil)
+				return
+			}
+			responses.InternalServerError(c, err, &gin.H{"error": "Failed to get execution"})
+			return
+		}
+
+		if execution.WorkflowID != workflowID {
+			responses.NotFound(c, "Execution not found", nil)
+			return
+		}
+
+		request.TriggerEvent = execution.TriggerData
+
+		// Older executions may only have their trigger data in ClickHouse
+		if len(request.TriggerEvent) == 0 && s.Dependencies.Clickhouse != nil {
+			logs, err := s.Dependencies.Clickhouse.GetWorkflowExecutionLogs(c.Request.Context(), execution.ExecutionID, 1, 0)
+			if err != nil {
+				responses.InternalServerError(c, err, &gin.H{"error": "Failed to get execution logs"})
+				return
+			}
+			if len(logs) > 0 {
+				request.TriggerEvent = logs[0].TriggerEventData
+			}
+		}
+	}
+
+	if len(request.TriggerEvent) == 0 {
+		responses.BadRequest(c, "A trigger_event or replay_execution_id is required", nil)
+		return
+	}
+
+	result, err := s.Dependencies.WorkflowManager.SimulateWorkflow(workflow, &request)
+	if err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to simulate workflow"})
+		return
+	}
+
+	responses.Success(c, "Workflow simulated successfully", &gin.H{
+		"simulation": result,
+	})
+}
+
 // ServerWorkflowVariablesList returns variables for a workflow
 func (s *Server) ServerWorkflowVariablesList(c *gin.Context) {
 	user := s.getUserFromSession(c)
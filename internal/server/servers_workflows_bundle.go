@@ -0,0 +1,315 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/permissions"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+	"go.codycody31.dev/squad-aegis/internal/workflow_manager"
+)
+
+// maxWorkflowBundleSize limits the size of an uploaded workflow bundle
+const maxWorkflowBundleSize = 5 << 20
+
+// wantsYAML reports whether a request asks for YAML instead of JSON
+func wantsYAML(c *gin.Context) bool {
+	format := strings.ToLower(c.Query("format"))
+	if format != "" {
+		return format == "yaml" || format == "yml"
+	}
+	return strings.Contains(strings.ToLower(c.ContentType()), "yaml")
+}
+
+// sendWorkflowBundle writes a bundle as a file download
+func sendWorkflowBundle(c *gin.Context, bundle *models.WorkflowBundle, name string) {
+	asYAML := wantsYAML(c)
+
+	data, err := workflow_manager.EncodeWorkflowBundle(bundle, asYAML)
+	if err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to encode workflow bundle"})
+		return
+	}
+
+	extension, contentType := "json", "application/json"
+	if asYAML {
+		extension, contentType = "yaml", "application/yaml"
+	}
+
+	filename := fmt.Sprintf("%s_%s.%s", name, time.Now().Format("2006-01-02"), extension)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// ServerWorkflowsExport exports all workflows of a server, or the ones listed in ?ids=
+func (s *Server) ServerWorkflowsExport(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	serverID, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	selected := make(map[uuid.UUID]bool)
+	if ids := c.Query("ids"); ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			workflowID, err := uuid.Parse(strings.TrimSpace(id))
+			if err != nil {
+				responses.BadRequest(c, "Invalid workflow ID", &gin.H{"error": err.Error()})
+				return
+			}
+			selected[workflowID] = true
+		}
+	}
+
+	includeKV := c.Query("include_kv") == "true"
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+	workflows, err := workflowDB.GetWorkflowsByServerID(serverID)
+	if err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to get workflows"})
+		return
+	}
+
+	entries := []models.WorkflowBundleEntry{}
+	for i := range workflows {
+		if len(selected) > 0 && !selected[workflows[i].ID] {
+			continue
+		}
+
+		entry, err := workflowDB.ExportWorkflow(&workflows[i], includeKV)
+		if err != nil {
+			responses.InternalServerError(c, err, &gin.H{"error": "Failed to export workflow"})
+			return
+		}
+		entries = append(entries, *entry)
+	}
+
+	if len(entries) == 0 {
+		responses.NotFound(c, "No workflows to export", nil)
+		return
+	}
+
+	sendWorkflowBundle(c, workflow_manager.NewWorkflowBundle(entries), "workflows")
+}
+
+// ServerWorkflowExport exports a single workflow
+func (s *Server) ServerWorkflowExport(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+	workflow := s.getServerWorkflow(c, workflowDB)
+	if workflow == nil {
+		return
+	}
+
+	entry, err := workflowDB.ExportWorkflow(workflow, c.Query("include_kv") == "true")
+	if err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to export workflow"})
+		return
+	}
+
+	sendWorkflowBundle(c, workflow_manager.NewWorkflowBundle([]models.WorkflowBundleEntry{*entry}), "workflow")
+}
+
+// ServerWorkflowsImport creates workflows on a server from a JSON or YAML bundle.
+// The whole bundle is validated before anything is created.
+func (s *Server) ServerWorkflowsImport(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	serverID, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWorkflowBundleSize+1))
+	if err != nil {
+		responses.BadRequest(c, "Failed to read request body", &gin.H{"error": err.Error()})
+		return
+	}
+	if len(data) > maxWorkflowBundleSize {
+		responses.BadRequest(c, "Workflow bundle is too large", nil)
+		return
+	}
+
+	bundle, err := workflow_manager.ParseWorkflowBundle(data, wantsYAML(c))
+	if err != nil {
+		responses.BadRequest(c, "Invalid workflow bundle", &gin.H{"error": err.Error()})
+		return
+	}
+
+	problems, err := workflow_manager.ValidateWorkflowBundle(bundle)
+	if err != nil {
+		responses.BadRequest(c, "Invalid workflow bundle", &gin.H{"error": err.Error()})
+		return
+	}
+	if len(problems) > 0 {
+		responses.BadRequest(c, "Workflow bundle validation failed", &gin.H{"errors": problems})
+		return
+	}
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+
+	// The bundle is imported in one transaction, so a failure creates nothing
+	tx, err := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to begin transaction: %w", err), nil)
+		return
+	}
+	defer tx.Rollback()
+
+	imported := []*models.ServerWorkflow{}
+	for i := range bundle.Workflows {
+		workflow, err := workflowDB.ImportWorkflow(tx, serverID, &bundle.Workflows[i], user.Id, nil)
+		if err != nil {
+			responses.InternalServerError(c, err, &gin.H{
+				"error": fmt.Sprintf("Failed to import workflow %q", bundle.Workflows[i].Name),
+			})
+			return
+		}
+		imported = append(imported, workflow)
+	}
+
+	if err := tx.Commit(); err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to commit transaction: %w", err), nil)
+		return
+	}
+
+	if err := s.Dependencies.WorkflowManager.ReloadWorkflows(); err != nil {
+		log.Error().Err(err).Msg("Failed to reload workflows after import")
+	}
+
+	responses.Success(c, "Workflows imported successfully", &gin.H{
+		"workflows": imported,
+	})
+}
+
+// canManageWorkflows checks that the user may manage workflows on a server.
+// For super admins an unknown server is reported as sql.ErrNoRows.
+func (s *Server) canManageWorkflows(c *gin.Context, user *models.User, serverID uuid.UUID) (bool, error) {
+	if user.SuperAdmin {
+		server, err := core.GetServerById(c.Request.Context(), s.Dependencies.DB, serverID, user)
+		if err != nil {
+			return false, err
+		}
+		if server.Id != serverID {
+			return false, sql.ErrNoRows
+		}
+		return true, nil
+	}
+
+	return s.Dependencies.PermissionService.HasPermission(c.Request.Context(), user.Id, serverID, permissions.UIWorkflowsManage)
+}
+
+// ServerWorkflowClone copies a workflow to other servers with per-server variable overrides
+func (s *Server) ServerWorkflowClone(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	var request models.WorkflowCloneRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		responses.BadRequest(c, "Invalid request payload", &gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.Targets) == 0 {
+		responses.BadRequest(c, "At least one target server is required", nil)
+		return
+	}
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+	workflow := s.getServerWorkflow(c, workflowDB)
+	if workflow == nil {
+		return
+	}
+
+	// Check every target before creating anything
+	for _, target := range request.Targets {
+		allowed, err := s.canManageWorkflows(c, user, target.ServerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Target server not found", &gin.H{"server_id": target.ServerID})
+			return
+		}
+		if err != nil {
+			responses.InternalServerError(c, fmt.Errorf("failed to check permissions: %w", err), nil)
+			return
+		}
+		if !allowed {
+			responses.Forbidden(c, "You can't manage workflows on one of the target servers", &gin.H{"server_id": target.ServerID})
+			return
+		}
+	}
+
+	entry, err := workflowDB.ExportWorkflow(workflow, request.IncludeKV)
+	if err != nil {
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to export workflow"})
+		return
+	}
+	if request.Enabled != nil {
+		entry.Enabled = *request.Enabled
+	}
+
+	// Every target is cloned in one transaction, so a failure creates nothing
+	tx, err := s.Dependencies.DB.BeginTx(c.Request.Context(), nil)
+	if err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to begin transaction: %w", err), nil)
+		return
+	}
+	defer tx.Rollback()
+
+	clones := []*models.ServerWorkflow{}
+	for _, target := range request.Targets {
+		targetEntry := *entry
+		if target.Name != nil && *target.Name != "" {
+			targetEntry.Name = *target.Name
+		}
+
+		clone, err := workflowDB.ImportWorkflow(tx, target.ServerID, &targetEntry, user.Id, target.Variables)
+		if err != nil {
+			responses.InternalServerError(c, err, &gin.H{
+				"error": fmt.Sprintf("Failed to clone workflow to server %s", target.ServerID),
+			})
+			return
+		}
+		clones = append(clones, clone)
+	}
+
+	if err := tx.Commit(); err != nil {
+		responses.InternalServerError(c, fmt.Errorf("failed to commit transaction: %w", err), nil)
+		return
+	}
+
+	if err := s.Dependencies.WorkflowManager.ReloadWorkflows(); err != nil {
+		log.Error().Err(err).Msg("Failed to reload workflows after clone")
+	}
+
+	responses.Success(c, "Workflow cloned successfully", &gin.H{
+		"workflows": clones,
+	})
+}
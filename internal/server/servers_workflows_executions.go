@@ -0,0 +1,128 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+	"go.codycody31.dev/squad-aegis/internal/workflow_manager"
+)
+
+// workflowExecutionFromRequest loads the execution named in the URL after checking
+// that it belongs to the workflow and server. It writes the error response itself.
+func (s *Server) workflowExecutionFromRequest(c *gin.Context) (*models.ServerWorkflowExecution, bool) {
+	serverID, err := uuid.Parse(c.Param("serverId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	workflowID, err := uuid.Parse(c.Param("workflowId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid workflow ID", &gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	executionID, err := uuid.Parse(c.Param("executionId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid execution ID", &gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	workflowDB := workflow_manager.NewWorkflowDatabase(s.Dependencies.DB)
+
+	workflow, err := workflowDB.GetWorkflow(workflowID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Workflow not found", nil)
+			return nil, false
+		}
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to get workflow"})
+		return nil, false
+	}
+
+	if workflow.ServerID != serverID {
+		responses.NotFound(c, "Workflow not found", nil)
+		return nil, false
+	}
+
+	execution, err := workflowDB.GetWorkflowExecution(executionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responses.NotFound(c, "Execution not found", nil)
+			return nil, false
+		}
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to get execution"})
+		return nil, false
+	}
+
+	if execution.WorkflowID != workflowID {
+		responses.NotFound(c, "Execution not found", nil)
+		return nil, false
+	}
+
+	return execution, true
+}
+
+// ServerWorkflowExecutionCancel stops a running execution
+func (s *Server) ServerWorkflowExecutionCancel(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	execution, ok := s.workflowExecutionFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := s.Dependencies.WorkflowManager.CancelExecution(execution.ExecutionID); err != nil {
+		if errors.Is(err, workflow_manager.ErrExecutionNotRunning) {
+			responses.Conflict(c, "Execution is not running", &gin.H{"status": execution.Status})
+			return
+		}
+		responses.InternalServerError(c, err, &gin.H{"error": "Failed to cancel execution"})
+		return
+	}
+
+	responses.Success(c, "Execution cancellation requested", &gin.H{
+		"execution_id": execution.ExecutionID,
+	})
+}
+
+// ServerWorkflowExecutionRetry starts a new execution with the trigger event
+// of a finished one
+func (s *Server) ServerWorkflowExecutionRetry(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	if user == nil {
+		responses.Unauthorized(c, "Unauthorized", nil)
+		return
+	}
+
+	execution, ok := s.workflowExecutionFromRequest(c)
+	if !ok {
+		return
+	}
+
+	executionID, err := s.Dependencies.WorkflowManager.RetryExecution(execution.ExecutionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, workflow_manager.ErrExecutionRunning):
+			responses.Conflict(c, "Execution is still running", nil)
+		case errors.Is(err, workflow_manager.ErrWorkflowNotActive):
+			responses.Conflict(c, "Workflow must be enabled to retry an execution", nil)
+		default:
+			responses.InternalServerError(c, err, &gin.H{"error": "Failed to retry execution"})
+		}
+		return
+	}
+
+	responses.Success(c, "Execution retry started", &gin.H{
+		"execution_id": executionID,
+		"retry_of":     execution.ExecutionID,
+	})
+}
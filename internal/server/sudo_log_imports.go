@@ -0,0 +1,165 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/core"
+	"go.codycody31.dev/squad-aegis/internal/log_importer"
+	"go.codycody31.dev/squad-aegis/internal/server/responses"
+)
+
+// CreateLogImport starts importing historical log files for a server. Files
+// can be uploaded as "files" or refer to files already in storage through
+// "storage_paths"; uploaded files are imported after the storage ones, in the
+// order given.
+func (s *Server) CreateLogImport(c *gin.Context) {
+	user := s.getUserFromSession(c)
+	ctx := c.Request.Context()
+
+	serverId, err := uuid.Parse(c.PostForm("server_id"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := core.GetServerById(ctx, s.Dependencies.DB, serverId, user); err != nil {
+		responses.BadRequest(c, "Failed to get server", &gin.H{"error": err.Error()})
+		return
+	}
+
+	files := []string{}
+	for _, path := range c.PostFormArray("storage_paths") {
+		exists, err := s.Dependencies.Storage.Exists(ctx, path)
+		if err != nil {
+			responses.InternalServerError(c, fmt.Errorf("failed to check file: %w", err), nil)
+			return
+		}
+		if !exists {
+			responses.BadRequest(c, "File not found in storage", &gin.H{"path": path})
+			return
+		}
+		files = append(files, path)
+	}
+
+	if form, err := c.MultipartForm(); err == nil {
+		uploadId := uuid.New()
+		for _, file := range form.File["files"] {
+			// Uploads are kept under log-imports/{serverId}/{uploadId}/ so a failed
+			// import can be retried from storage
+			path := fmt.Sprintf("log-imports/%s/%s/%s", serverId.String(), uploadId.String(), filepath.Base(file.Filename))
+
+			src, err := file.Open()
+			if err != nil {
+				responses.BadRequest(c, "Failed to open uploaded file", &gin.H{"error": err.Error(), "file": file.Filename})
+				return
+			}
+			err = s.Dependencies.Storage.Save(ctx, path, src)
+			src.Close()
+			if err != nil {
+				responses.InternalServerError(c, fmt.Errorf("failed to save uploaded file: %w", err), nil)
+				return
+			}
+			files = append(files, path)
+		}
+	}
+
+	if len(files) == 0 {
+		responses.BadRequest(c, "No files to import", nil)
+		return
+	}
+
+	job, err := s.Dependencies.LogImporter.Submit(ctx, serverId, files, &user.Id)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(ctx, &serverId, &user.Id, "server:log_import:create", map[string]interface{}{
+		"jobId": job.ID.String(),
+		"files": files,
+	})
+
+	responses.Success(c, "Log import started", &gin.H{"job": job})
+}
+
+// GetLogImports lists recent log import jobs, optionally for one server
+func (s *Server) GetLogImports(c *gin.Context) {
+	var serverId *uuid.UUID
+	if serverIdStr := c.Query("server_id"); serverIdStr != "" {
+		id, err := uuid.Parse(serverIdStr)
+		if err != nil {
+			responses.BadRequest(c, "Invalid server ID", &gin.H{"error": err.Error()})
+			return
+		}
+		serverId = &id
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 500 {
+			limit = l
+		}
+	}
+
+	jobs, err := core.ListLogImportJobs(c.Request.Context(), s.Dependencies.DB, serverId, limit)
+	if err != nil {
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	responses.Success(c, "Log imports retrieved successfully", &gin.H{"jobs": jobs})
+}
+
+// GetLogImport returns a single log import job and its progress
+func (s *Server) GetLogImport(c *gin.Context) {
+	jobId, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid job ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := core.GetLogImportJob(c.Request.Context(), s.Dependencies.DB, jobId)
+	if err != nil {
+		responses.NotFound(c, "Log import not found", &gin.H{"error": err.Error()})
+		return
+	}
+
+	responses.Success(c, "Log import retrieved successfully", &gin.H{"job": job})
+}
+
+// CancelLogImport stops a pending or running log import job
+func (s *Server) CancelLogImport(c *gin.Context) {
+	user := s.getUserFromSession(c)
+
+	jobId, err := uuid.Parse(c.Param("jobId"))
+	if err != nil {
+		responses.BadRequest(c, "Invalid job ID", &gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := core.GetLogImportJob(c.Request.Context(), s.Dependencies.DB, jobId)
+	if err != nil {
+		responses.NotFound(c, "Log import not found", &gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.Dependencies.LogImporter.Cancel(jobId); err != nil {
+		if errors.Is(err, log_importer.ErrJobNotActive) {
+			responses.Conflict(c, "Log import is not running", &gin.H{"status": job.Status})
+			return
+		}
+		responses.InternalServerError(c, err, nil)
+		return
+	}
+
+	s.CreateAuditLog(c.Request.Context(), &job.ServerID, &user.Id, "server:log_import:cancel", map[string]interface{}{
+		"jobId": jobId.String(),
+	})
+
+	responses.SimpleSuccess(c, "Log import cancelled")
+}
@@ -38,6 +38,20 @@ type Struct struct {
 		Password string `default:""`
 		Database int    `default:"0"`
 	}
+	Events struct {
+		BufferSize int `default:"10000"`
+		Journal    struct {
+			Mode       string `default:"memory"` // "memory", "file" or "valkey"
+			Path       string `default:"storage/events"`
+			MaxEntries int    `default:"100000"`
+		}
+	}
+	Federation struct {
+		Community string `default:""` // Name peers see on the bans this instance shares, defaults to the App URL
+	}
+	Workflows struct {
+		MaxConcurrentPerServer int `default:"50"` // Executions allowed to run at once per server across all workflows, 0 for no limit
+	}
 	Log struct {
 		Level          string `default:"info"`
 		ShowGin        bool   `default:"false"`
@@ -21,6 +21,10 @@ type SquadRcon struct {
 	// Replace direct Rcon reference with RconManager and serverID
 	Manager  *rcon_manager.RconManager
 	ServerID uuid.UUID
+
+	// Who the commands are queued for and at which priority, see AsCaller
+	Caller   string
+	Priority int
 }
 
 // Player represents a player in the game
@@ -302,6 +306,24 @@ func NewSquadRcon(manager *rcon_manager.RconManager, serverID uuid.UUID) *SquadR
 	}
 }
 
+// AsCaller returns a copy that queues its commands for caller at priority, so
+// they count against the caller's rate limit
+func (s *SquadRcon) AsCaller(caller string, priority int) *SquadRcon {
+	scoped := *s
+	scoped.Caller = caller
+	scoped.Priority = priority
+	return &scoped
+}
+
+// execute runs a command through the RCON manager's command queue. Kicks and
+// bans are sent with at least high priority.
+func (s *SquadRcon) execute(command string, minPriority int) (string, error) {
+	return s.Manager.ExecuteCommandWithOptions(s.ServerID, command, rcon_manager.CommandOptions{
+		Priority: max(s.Priority, minPriority),
+		Caller:   s.Caller,
+	})
+}
+
 // NewSquadRconWithConnection creates a new SquadRcon instance and connects to the server using RconManager
 func NewSquadRconWithConnection(manager *rcon_manager.RconManager, serverID uuid.UUID, host string, port int, password string) (*SquadRcon, error) {
 	// Connect to the server using RconManager
@@ -325,19 +347,19 @@ func (s *SquadRcon) BanPlayer(steamId string, duration int, reason string) error
 	} else {
 		durationStr = fmt.Sprintf("%dd", duration)
 	}
-	_, err := s.Manager.ExecuteCommand(s.ServerID, fmt.Sprintf("AdminBan %s %s %s", steamId, durationStr, reason))
+	_, err := s.execute(fmt.Sprintf("AdminBan %s %s %s", steamId, durationStr, reason), rcon_manager.PriorityHigh)
 	return err
 }
 
 // KickPlayer kicks a player from the server
 func (s *SquadRcon) KickPlayer(steamId string, reason string) error {
-	_, err := s.Manager.ExecuteCommand(s.ServerID, fmt.Sprintf("AdminKick %s %s", steamId, reason))
+	_, err := s.execute(fmt.Sprintf("AdminKick %s %s", steamId, reason), rcon_manager.PriorityHigh)
 	return err
 }
 
 // GetServerPlayers gets the online and disconnected players from the server
 func (s *SquadRcon) GetServerPlayers() (PlayersData, error) {
-	playersResponse, err := s.Manager.ExecuteCommand(s.ServerID, "ListPlayers")
+	playersResponse, err := s.execute("ListPlayers", rcon_manager.PriorityNormal)
 	if err != nil {
 		return PlayersData{}, err
 	}
@@ -392,7 +414,7 @@ func (s *SquadRcon) GetServerPlayers() (PlayersData, error) {
 }
 
 func (s *SquadRcon) GetServerSquads() ([]Squad, []string, error) {
-	squadsResponse, err := s.Manager.ExecuteCommand(s.ServerID, "ListSquads")
+	squadsResponse, err := s.execute("ListSquads", rcon_manager.PriorityNormal)
 	if err != nil {
 		return []Squad{}, []string{}, err
 	}
@@ -439,7 +461,7 @@ func (s *SquadRcon) GetServerSquads() ([]Squad, []string, error) {
 }
 
 func (s *SquadRcon) GetCurrentMap() (Map, error) {
-	currentMap, err := s.Manager.ExecuteCommand(s.ServerID, "ShowCurrentMap")
+	currentMap, err := s.execute("ShowCurrentMap", rcon_manager.PriorityNormal)
 	if err != nil {
 		return Map{}, err
 	}
@@ -458,7 +480,7 @@ func (s *SquadRcon) GetCurrentMap() (Map, error) {
 }
 
 func (s *SquadRcon) GetNextMap() (Map, error) {
-	nextMap, err := s.Manager.ExecuteCommand(s.ServerID, "ShowNextMap")
+	nextMap, err := s.execute("ShowNextMap", rcon_manager.PriorityNormal)
 	if err != nil {
 		if nextMap == "Next level is not defined" {
 			return Map{}, ErrNoNextMap
@@ -482,7 +504,7 @@ func (s *SquadRcon) GetNextMap() (Map, error) {
 
 // GetAvailableMaps gets the available maps from the server
 func (s *SquadRcon) GetAvailableLayers() ([]Layer, error) {
-	availableLayers, err := s.Manager.ExecuteCommand(s.ServerID, "ListLayers")
+	availableLayers, err := s.execute("ListLayers", rcon_manager.PriorityNormal)
 	if err != nil {
 		return []Layer{}, err
 	}
@@ -512,7 +534,7 @@ func (s *SquadRcon) GetAvailableLayers() ([]Layer, error) {
 
 // GetServerInfo gets the server info from the server
 func (s *SquadRcon) GetServerInfo() (ServerInfo, error) {
-	serverInfo, err := s.Manager.ExecuteCommand(s.ServerID, "ShowServerInfo")
+	serverInfo, err := s.execute("ShowServerInfo", rcon_manager.PriorityNormal)
 	if err != nil {
 		return ServerInfo{}, err
 	}
@@ -622,5 +644,5 @@ func (s *SquadRcon) Close() {
 
 // ExecuteRaw allows executing raw RCON commands directly
 func (s *SquadRcon) ExecuteRaw(command string) (string, error) {
-	return s.Manager.ExecuteCommand(s.ServerID, command)
+	return s.execute(command, rcon_manager.PriorityNormal)
 }
@@ -0,0 +1,208 @@
+package squad_cfg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	steamIDPattern = regexp.MustCompile(`^7656119\d{10}$`)
+	eosIDPattern   = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+	// banIssuerPattern matches the "Name [SteamID 7656...]" prefix Squad writes before "Banned:"
+	banIssuerPattern = regexp.MustCompile(`^(.*?)\s*\[(?i:SteamID|EOSID)\s+([^\]]*)\]$`)
+)
+
+// Group is a Group= line of Admins.cfg
+type Group struct {
+	Name        string
+	Permissions []string
+	Line        int
+}
+
+// Admin is an Admin= line of Admins.cfg. Exactly one of SteamID and EOSID is set.
+type Admin struct {
+	SteamID string
+	EOSID   string
+	Group   string
+	Comment string
+	Line    int
+}
+
+// Ban is a line of Bans.cfg. Exactly one of SteamID and EOSID is set, and a
+// nil ExpiresAt is a permanent ban.
+type Ban struct {
+	SteamID   string
+	EOSID     string
+	IssuedBy  string
+	IssuerID  string
+	ExpiresAt *time.Time
+	Reason    string
+	Line      int
+}
+
+// Warning is a line that couldn't be parsed and was skipped
+type Warning struct {
+	Line    int
+	Text    string
+	Message string
+}
+
+// AdminsFile is a parsed Admins.cfg
+type AdminsFile struct {
+	Groups   []Group
+	Admins   []Admin
+	Warnings []Warning
+}
+
+// BansFile is a parsed Bans.cfg
+type BansFile struct {
+	Bans     []Ban
+	Warnings []Warning
+}
+
+// ParseAdmins parses the groups and admins of an Admins.cfg. Comments after
+// "//" on an admin line are kept as the admin's comment.
+func ParseAdmins(content string) *AdminsFile {
+	file := &AdminsFile{}
+
+	for number, line := range splitLines(content) {
+		body, comment := splitComment(line)
+		if body == "" {
+			continue
+		}
+		warn := func(format string, args ...interface{}) {
+			file.Warnings = append(file.Warnings, Warning{Line: number + 1, Text: line, Message: fmt.Sprintf(format, args...)})
+		}
+
+		key, value, ok := strings.Cut(body, "=")
+		if !ok {
+			warn("expected a Group= or Admin= line")
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "group":
+			name, permissions, ok := strings.Cut(value, ":")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				warn("expected Group=<name>:<permissions>")
+				continue
+			}
+
+			group := Group{Name: name, Permissions: []string{}, Line: number + 1}
+			for _, permission := range strings.Split(permissions, ",") {
+				if permission = strings.TrimSpace(permission); permission != "" {
+					group.Permissions = append(group.Permissions, permission)
+				}
+			}
+			file.Groups = append(file.Groups, group)
+		case "admin":
+			id, group, ok := strings.Cut(value, ":")
+			group = strings.TrimSpace(group)
+			if !ok || group == "" {
+				warn("expected Admin=<player ID>:<group>")
+				continue
+			}
+
+			admin := Admin{Group: group, Comment: comment, Line: number + 1}
+			if !setPlayerID(strings.TrimSpace(id), &admin.SteamID, &admin.EOSID) {
+				warn("%q is not a Steam or EOS ID", strings.TrimSpace(id))
+				continue
+			}
+			file.Admins = append(file.Admins, admin)
+		default:
+			warn("unknown key %q", strings.TrimSpace(key))
+		}
+	}
+
+	return file
+}
+
+// ParseBans parses a Bans.cfg. Both the "Name [SteamID id] Banned:id:expiry"
+// lines Squad writes and bare "id:expiry" lines are accepted, an expiry of 0
+// is a permanent ban and the comment after "//" is the reason.
+func ParseBans(content string) *BansFile {
+	file := &BansFile{}
+
+	for number, line := range splitLines(content) {
+		body, comment := splitComment(line)
+		if body == "" {
+			continue
+		}
+		warn := func(format string, args ...interface{}) {
+			file.Warnings = append(file.Warnings, Warning{Line: number + 1, Text: line, Message: fmt.Sprintf(format, args...)})
+		}
+
+		ban := Ban{Reason: comment, Line: number + 1}
+
+		if issuer, banned, ok := strings.Cut(body, "Banned:"); ok {
+			body = banned
+			issuer = strings.TrimSpace(issuer)
+			if match := banIssuerPattern.FindStringSubmatch(issuer); match != nil {
+				ban.IssuedBy = strings.TrimSpace(match[1])
+				ban.IssuerID = strings.TrimSpace(match[2])
+			} else {
+				ban.IssuedBy = issuer
+			}
+		}
+
+		id, expiry, ok := strings.Cut(body, ":")
+		if !ok {
+			warn("expected <player ID>:<expiry>")
+			continue
+		}
+		if !setPlayerID(strings.TrimSpace(id), &ban.SteamID, &ban.EOSID) {
+			warn("%q is not a Steam or EOS ID", strings.TrimSpace(id))
+			continue
+		}
+
+		expiresAt, err := strconv.ParseInt(strings.TrimSpace(expiry), 10, 64)
+		if err != nil || expiresAt < 0 {
+			warn("%q is not a Unix timestamp", strings.TrimSpace(expiry))
+			continue
+		}
+		if expiresAt > 0 {
+			expires := time.Unix(expiresAt, 0).UTC()
+			ban.ExpiresAt = &expires
+		}
+
+		file.Bans = append(file.Bans, ban)
+	}
+
+	return file
+}
+
+func splitLines(content string) []string {
+	content = strings.TrimPrefix(content, "\ufeff")
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return lines
+}
+
+// splitComment splits a line into its content and the comment after "//"
+func splitComment(line string) (string, string) {
+	body, comment, _ := strings.Cut(line, "//")
+	return strings.TrimSpace(body), strings.TrimSpace(comment)
+}
+
+// IsSteamID reports whether id is a Steam ID of an individual account
+func IsSteamID(id string) bool {
+	return steamIDPattern.MatchString(id)
+}
+
+func setPlayerID(id string, steamID, eosID *string) bool {
+	switch {
+	case steamIDPattern.MatchString(id):
+		*steamID = id
+	case eosIDPattern.MatchString(id):
+		*eosID = strings.ToLower(id)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package squad_cfg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAdmins(t *testing.T) {
+	file := ParseAdmins("\ufeff// Server admins\r\n" +
+		"Group=SeniorAdmin:changemap, kick,ban ,reserve\r\n" +
+		"Group=Whitelist:reserve\r\n" +
+		"\r\n" +
+		"Admin=76561198000000001:SeniorAdmin // Jane\r\n" +
+		"admin=76561198000000002:Whitelist\r\n" +
+		"Admin=0002A1B2C3D4E5F6A7B8C9D0E1F2A3B4:Whitelist // EOS player\r\n")
+
+	if len(file.Warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", file.Warnings)
+	}
+	if len(file.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(file.Groups))
+	}
+	senior := file.Groups[0]
+	if senior.Name != "SeniorAdmin" || senior.Line != 2 {
+		t.Errorf("unexpected group: %+v", senior)
+	}
+	if len(senior.Permissions) != 4 || senior.Permissions[1] != "kick" || senior.Permissions[2] != "ban" {
+		t.Errorf("expected trimmed permissions, got %v", senior.Permissions)
+	}
+
+	if len(file.Admins) != 3 {
+		t.Fatalf("expected 3 admins, got %d", len(file.Admins))
+	}
+	if admin := file.Admins[0]; admin.SteamID != "76561198000000001" || admin.Group != "SeniorAdmin" || admin.Comment != "Jane" {
+		t.Errorf("unexpected admin: %+v", admin)
+	}
+	if admin := file.Admins[1]; admin.SteamID != "76561198000000002" || admin.Comment != "" {
+		t.Errorf("expected the lower case key to parse, got %+v", admin)
+	}
+	if admin := file.Admins[2]; admin.EOSID != "0002a1b2c3d4e5f6a7b8c9d0e1f2a3b4" || admin.SteamID != "" {
+		t.Errorf("expected a lower case EOS ID, got %+v", admin)
+	}
+}
+
+func TestParseAdminsWarnings(t *testing.T) {
+	file := ParseAdmins("Group=:reserve\n" +
+		"Admin=76561198000000001\n" +
+		"Admin=not-an-id:Admin\n" +
+		"Moderator=76561198000000001:Admin\n" +
+		"just some text\n")
+
+	if len(file.Groups) != 0 || len(file.Admins) != 0 {
+		t.Fatalf("expected nothing to parse, got %+v", file)
+	}
+	if len(file.Warnings) != 5 {
+		t.Fatalf("expected a warning per line, got %v", file.Warnings)
+	}
+	for i, warning := range file.Warnings {
+		if warning.Line != i+1 {
+			t.Errorf("expected warning %d on line %d, got line %d", i, i+1, warning.Line)
+		}
+	}
+}
+
+func TestParseBans(t *testing.T) {
+	file := ParseBans("Jane [SteamID 76561198000000001] Banned:76561198000000002:0 //Cheating\n" +
+		"System [SteamID 0] Banned:76561198000000003:1893456000 //Teamkilling: repeated\n" +
+		"76561198000000004:0\n" +
+		"[EOSID 0002a1b2c3d4e5f6a7b8c9d0e1f2a3b5] Banned:0002A1B2C3D4E5F6A7B8C9D0E1F2A3B4:0\n")
+
+	if len(file.Warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", file.Warnings)
+	}
+	if len(file.Bans) != 4 {
+		t.Fatalf("expected 4 bans, got %d", len(file.Bans))
+	}
+
+	permanent := file.Bans[0]
+	if permanent.SteamID != "76561198000000002" || permanent.IssuedBy != "Jane" || permanent.IssuerID != "76561198000000001" {
+		t.Errorf("unexpected ban: %+v", permanent)
+	}
+	if permanent.ExpiresAt != nil || permanent.Reason != "Cheating" {
+		t.Errorf("expected a permanent ban for cheating, got %+v", permanent)
+	}
+
+	temporary := file.Bans[1]
+	if temporary.ExpiresAt == nil || !temporary.ExpiresAt.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected expiry: %v", temporary.ExpiresAt)
+	}
+	if temporary.Reason != "Teamkilling: repeated" {
+		t.Errorf("expected the whole comment as reason, got %q", temporary.Reason)
+	}
+
+	if bare := file.Bans[2]; bare.SteamID != "76561198000000004" || bare.IssuedBy != "" {
+		t.Errorf("unexpected bare ban: %+v", bare)
+	}
+	if eos := file.Bans[3]; eos.EOSID != "0002a1b2c3d4e5f6a7b8c9d0e1f2a3b4" || eos.IssuedBy != "" {
+		t.Errorf("unexpected EOS ban: %+v", eos)
+	}
+}
+
+func TestParseBansWarnings(t *testing.T) {
+	file := ParseBans("Jane [SteamID 76561198000000001] Banned:76561198000000002 //No expiry\n" +
+		"76561198000000003:soon\n" +
+		"12345:0\n")
+
+	if len(file.Bans) != 0 {
+		t.Fatalf("expected no bans, got %+v", file.Bans)
+	}
+	if len(file.Warnings) != 3 {
+		t.Fatalf("expected a warning per line, got %v", file.Warnings)
+	}
+}
@@ -0,0 +1,29 @@
+package testharness
+
+import (
+	"fmt"
+	"time"
+
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+)
+
+// WaitForEvent reads events from a subscriber until one of the given type
+// arrives. Events of other types are discarded.
+func WaitForEvent(subscriber *event_manager.EventSubscriber, eventType event_manager.EventType, timeout time.Duration) (event_manager.Event, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case event, ok := <-subscriber.Channel:
+			if !ok {
+				return event_manager.Event{}, fmt.Errorf("subscriber closed while waiting for %s", eventType)
+			}
+			if event.Type == eventType {
+				return event, nil
+			}
+		case <-deadline.C:
+			return event_manager.Event{}, fmt.Errorf("timed out waiting for %s", eventType)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package testharness
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/clickhouse"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/logwatcher_manager"
+	"go.codycody31.dev/squad-aegis/internal/player_tracker_manager"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
+	squadRcon "go.codycody31.dev/squad-aegis/internal/squad-rcon"
+)
+
+const waitTimeout = 5 * time.Second
+
+var (
+	alpha = Player{ID: 0, EOSID: "0002a1b2c3d4e5f60718293a4b5c6d7e", SteamID: "76561198000000001", Name: "Alpha", TeamID: 1, SquadID: 1, IsLeader: true, Role: "USA_SL_01"}
+	bravo = Player{ID: 1, EOSID: "0002f1e2d3c4b5a69788796a5b4c3d2e", SteamID: "76561198000000002", Name: "Bravo", TeamID: 1, SquadID: 1, Role: "USA_Rifleman_01"}
+	delta = Player{ID: 2, EOSID: "0002aabbccddeeff0011223344556677", SteamID: "76561198000000003", Name: "Delta", TeamID: 2, Role: "RGF_Rifleman_01"}
+)
+
+// connectRcon starts a fake server and connects an RCON manager to it
+func connectRcon(t *testing.T) (*RconServer, *squadRcon.SquadRcon, *event_manager.EventSubscriber) {
+	t.Helper()
+
+	server, err := NewRconServer("secret")
+	if err != nil {
+		t.Fatalf("Failed to start fake RCON server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	eventManager := event_manager.NewEventManager(ctx, 0)
+	rconManager := rcon_manager.NewRconManager(ctx, eventManager)
+	t.Cleanup(rconManager.Shutdown)
+
+	serverID := uuid.New()
+	subscriber := eventManager.Subscribe(event_manager.EventFilter{}, &serverID, 100)
+
+	if err := rconManager.ConnectToServer(serverID, server.Host(), server.Port(), server.Password()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := server.WaitForClients(1, waitTimeout); err != nil {
+		t.Fatalf("Client never authenticated: %v", err)
+	}
+
+	return server, squadRcon.NewSquadRcon(rconManager, serverID), subscriber
+}
+
+func TestRconQueries(t *testing.T) {
+	server, rcon, _ := connectRcon(t)
+	server.SetPlayers(alpha, bravo, delta)
+	server.SetDisconnectedPlayers(DisconnectedPlayer{ID: 7, EOSID: "0002000000000000000000000000000a", SteamID: "76561198000000009", Name: "Echo", Since: 95 * time.Second})
+	server.SetSquads(Squad{ID: 1, TeamID: 1, Name: "ALPHA"})
+
+	teams, err := rcon.GetTeamsAndSquads()
+	if err != nil {
+		t.Fatalf("Failed to get teams: %v", err)
+	}
+	if len(teams) != 2 || teams[0].Name != "United States Army" {
+		t.Fatalf("Unexpected teams: %+v", teams)
+	}
+	squad := teams[0].Squads[0]
+	if squad.Size != 2 || len(squad.Players) != 2 || squad.Leader == nil || squad.Leader.Name != "Alpha" {
+		t.Errorf("Unexpected squad: %+v", squad)
+	}
+	if len(teams[1].Players) != 1 || teams[1].Players[0].Name != "Delta" {
+		t.Errorf("Expected Delta to be unassigned on team 2, got %+v", teams[1].Players)
+	}
+
+	players, err := rcon.GetServerPlayers()
+	if err != nil {
+		t.Fatalf("Failed to get players: %v", err)
+	}
+	if len(players.DisconnectedPlayers) != 1 || players.DisconnectedPlayers[0].SinceDisconnect != "01m35s" {
+		t.Errorf("Unexpected disconnected players: %+v", players.DisconnectedPlayers)
+	}
+
+	currentMap, err := rcon.GetCurrentMap()
+	if err != nil {
+		t.Fatalf("Failed to get current map: %v", err)
+	}
+	if currentMap.Layer != "Gorodok_RAAS_v1" || currentMap.Factions[1] != "RGF" {
+		t.Errorf("Unexpected current map: %+v", currentMap)
+	}
+
+	server.SetServerInfo("PublicQueue_I", "4")
+	info, err := rcon.GetServerInfo()
+	if err != nil {
+		t.Fatalf("Failed to get server info: %v", err)
+	}
+	if info.PlayerCount != 3 || info.PublicQueue != 4 || info.TeamTwo != "Russian Ground Forces" {
+		t.Errorf("Unexpected server info: %+v", info)
+	}
+}
+
+func TestRconLongResponse(t *testing.T) {
+	server, rcon, _ := connectRcon(t)
+
+	// Enough players for ListPlayers to span several packets
+	players := make([]Player, 100)
+	for i := range players {
+		players[i] = Player{ID: i, EOSID: fmt.Sprintf("%032x", i+1), SteamID: fmt.Sprintf("765611980%08d", i), Name: fmt.Sprintf("Player%d", i), TeamID: i%2 + 1, Role: "Rifleman"}
+	}
+	server.SetPlayers(players...)
+
+	response, err := rcon.GetServerPlayers()
+	if err != nil {
+		t.Fatalf("Failed to get players: %v", err)
+	}
+	if got := len(response.OnlinePlayers); got != len(players) {
+		t.Errorf("Expected %d players, got %d", len(players), got)
+	}
+}
+
+func TestRconServerPackets(t *testing.T) {
+	server, rcon, subscriber := connectRcon(t)
+	server.SetPlayers(alpha, bravo)
+
+	server.EmitChat(ChatAll, alpha, "!admin help")
+	event, err := WaitForEvent(subscriber, event_manager.EventTypeRconChatMessage, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat := event.Data.(*event_manager.RconChatMessageData); chat.Message != "!admin help" || chat.EosID != alpha.EOSID {
+		t.Errorf("Unexpected chat message: %+v", chat)
+	}
+
+	server.EmitAdminCamera(alpha, true)
+	if _, err := WaitForEvent(subscriber, event_manager.EventTypeRconPossessedAdminCamera, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rcon.ExecuteRaw(fmt.Sprintf("AdminWarn %s Stop that", bravo.EOSID)); err != nil {
+		t.Fatalf("Failed to warn: %v", err)
+	}
+	event, err = WaitForEvent(subscriber, event_manager.EventTypeRconPlayerWarned, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if warn := event.Data.(*event_manager.RconPlayerWarnedData); warn.PlayerName != "Bravo" || warn.Message != "Stop that" {
+		t.Errorf("Unexpected warn: %+v", warn)
+	}
+
+	if err := rcon.KickPlayer(bravo.SteamID, "Bye"); err != nil {
+		t.Fatalf("Failed to kick: %v", err)
+	}
+	if _, err := WaitForEvent(subscriber, event_manager.EventTypeRconPlayerKicked, waitTimeout); err != nil {
+		t.Fatal(err)
+	}
+	if players := server.Players(); len(players) != 1 {
+		t.Errorf("Expected the kicked player to leave, got %+v", players)
+	}
+	if _, err := server.WaitForCommand("AdminKick "+bravo.SteamID, waitTimeout); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLogReplay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventManager := event_manager.NewEventManager(ctx, 0)
+	playerTrackerManager := player_tracker_manager.NewPlayerTrackerManager(ctx, nil, eventManager, nil)
+	logwatcherManager := logwatcher_manager.NewLogwatcherManager(ctx, eventManager, nil, playerTrackerManager)
+	defer logwatcherManager.Shutdown()
+
+	serverID := uuid.New()
+	subscriber := eventManager.Subscribe(event_manager.EventFilter{}, &serverID, 100)
+
+	writer, err := NewLogWriter(filepath.Join(t.TempDir(), "SquadGame.log"))
+	if err != nil {
+		t.Fatalf("Failed to create log writer: %v", err)
+	}
+	defer writer.Close()
+
+	err = logwatcherManager.ConnectToServer(serverID, logwatcher_manager.LogSourceConfig{
+		Type:     logwatcher_manager.LogSourceTypeLocal,
+		FilePath: writer.Path(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect log source: %v", err)
+	}
+
+	if _, err := writer.ReplayFixture(ctx, "round.log", 0); err != nil {
+		t.Fatalf("Failed to replay fixture: %v", err)
+	}
+
+	// Events arrive in log order
+	event, err := WaitForEvent(subscriber, event_manager.EventTypeLogPlayerConnected, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if connected := event.Data.(*event_manager.LogPlayerConnectedData); connected.EOSID != alpha.EOSID {
+		t.Errorf("Unexpected connected player: %+v", connected)
+	}
+
+	event, err = WaitForEvent(subscriber, event_manager.EventTypeLogPlayerDied, waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if died := event.Data.(*event_manager.LogPlayerDiedData); died.VictimName != "Bravo" || died.AttackerEOS != alpha.EOSID {
+		t.Errorf("Unexpected death: %+v", died)
+	}
+
+	// The round end carries the winner the event store kept from the ticket lines
+	for {
+		event, err = WaitForEvent(subscriber, event_manager.EventTypeLogGameEventUnified, waitTimeout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if game := event.Data.(*event_manager.LogGameEventUnifiedData); game.EventType == "ROUND_ENDED" {
+			if game.Winner != "United States Army" || game.LoserData == "" {
+				t.Errorf("Unexpected round end: %+v", game)
+			}
+			break
+		}
+	}
+}
+
+func TestLogPush(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventManager := event_manager.NewEventManager(ctx, 0)
+	playerTrackerManager := player_tracker_manager.NewPlayerTrackerManager(ctx, nil, eventManager, nil)
+	logwatcherManager := logwatcher_manager.NewLogwatcherManager(ctx, eventManager, nil, playerTrackerManager)
+	defer logwatcherManager.Shutdown()
+
+	serverID := uuid.New()
+	subscriber := eventManager.Subscribe(event_manager.EventFilter{}, &serverID, 100)
+
+	err := logwatcherManager.ConnectToServer(serverID, logwatcher_manager.LogSourceConfig{
+		Type: logwatcher_manager.LogSourceTypePush,
+	})
+	if err != nil {
+		t.Fatalf("Failed to connect log source: %v", err)
+	}
+
+	fixture, err := Fixture("round.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(string(fixture), "\r\n", "\n")), "\n")
+
+	// Push the first half twice, as an agent would after a lost acknowledgement
+	half := len(lines) / 2
+	for _, batch := range []logwatcher_manager.PushBatch{
+		{Sequence: 1, Offset: 100, Lines: lines[:half]},
+		{Sequence: 1, Offset: 100, Lines: lines[:half]},
+		{Sequence: int64(half) + 1, Offset: 200, Lines: lines[half:]},
+	} {
+		if _, err := logwatcherManager.PushLogLines(ctx, serverID, batch); err != nil {
+			t.Fatalf("Failed to push: %v", err)
+		}
+	}
+
+	position, err := logwatcherManager.GetPushPosition(serverID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position.Sequence != int64(len(lines)) || position.Offset != 200 {
+		t.Errorf("Unexpected position: %+v", position)
+	}
+
+	connected := 0
+	for connected < 2 {
+		event, err := WaitForEvent(subscriber, event_manager.EventTypeLogPlayerConnected, waitTimeout)
+		if err != nil {
+			t.Fatalf("Expected 2 connections, got %d: %v", connected, err)
+		}
+		if connected == 0 && event.Data.(*event_manager.LogPlayerConnectedData).EOSID != alpha.EOSID {
+			t.Errorf("Unexpected first connection: %+v", event.Data)
+		}
+		connected++
+	}
+	if _, err := WaitForEvent(subscriber, event_manager.EventTypeLogPlayerConnected, 200*time.Millisecond); err == nil {
+		t.Error("Expected the resent batch to be dropped")
+	}
+}
+
+func TestLogImport(t *testing.T) {
+	ctx := context.Background()
+	serverID := uuid.New()
+
+	fixture, err := Fixture("round.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(strings.TrimSpace(string(fixture)), "\n")
+
+	// Split the round across a rotated log and a gzip archive, between the
+	// wound and the death it completes
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	if _, err := gz.Write([]byte(strings.Join(lines[9:], ""))); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+
+	files := []io.Reader{strings.NewReader(strings.Join(lines[:9], "")), &archive}
+
+	var events []event_manager.Event
+	eventStore := logwatcher_manager.NewMemoryEventStore(serverID)
+	var total int64
+	for _, file := range files {
+		reader, err := logwatcher_manager.OpenLogReader(file)
+		if err != nil {
+			t.Fatalf("Failed to open log: %v", err)
+		}
+		read, err := logwatcher_manager.ReplayLog(ctx, serverID, reader, eventStore, func(event event_manager.Event) {
+			events = append(events, event)
+		})
+		if err != nil {
+			t.Fatalf("Failed to replay log: %v", err)
+		}
+		total += read
+	}
+	if total != int64(len(lines)) {
+		t.Errorf("Expected %d lines, read %d", len(lines), total)
+	}
+
+	var died *event_manager.LogPlayerDiedData
+	var roundEnded *event_manager.LogGameEventUnifiedData
+	for _, event := range events {
+		switch data := event.Data.(type) {
+		case *event_manager.LogPlayerDiedData:
+			died = data
+		case *event_manager.LogGameEventUnifiedData:
+			if data.EventType == "ROUND_ENDED" {
+				roundEnded = data
+			}
+		case *event_manager.LogPlayerConnectedData:
+			// Imported events keep the time they were logged at
+			want := time.Date(2025, 6, 14, 18, 1, 10, 120*int(time.Millisecond), time.UTC)
+			if got := clickhouse.NewIngestEvent(event).EventTime; data.EOSID == alpha.EOSID && !got.Equal(want) {
+				t.Errorf("Expected event time %v, got %v", want, got)
+			}
+		}
+	}
+
+	if died == nil || died.AttackerEOS != alpha.EOSID {
+		t.Errorf("Unexpected death: %+v", died)
+	}
+	if roundEnded == nil || roundEnded.Winner != "United States Army" {
+		t.Errorf("Unexpected round end: %+v", roundEnded)
+	}
+}
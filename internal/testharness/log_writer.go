@@ -0,0 +1,106 @@
+package testharness
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed fixtures/*.log
+var fixtures embed.FS
+
+// Fixture returns the contents of a SquadGame.log fixture, e.g. "round.log"
+func Fixture(name string) ([]byte, error) {
+	return fixtures.ReadFile(path.Join("fixtures", name))
+}
+
+// LogWriter appends lines to a log file the way a Squad server writes
+// SquadGame.log, so a local log source can tail it
+type LogWriter struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewLogWriter creates the log file, or appends to it if it exists
+func NewLogWriter(filePath string) (*LogWriter, error) {
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	return &LogWriter{
+		path: filePath,
+		file: file,
+	}, nil
+}
+
+// Path returns the path of the log file
+func (w *LogWriter) Path() string {
+	return w.path
+}
+
+// WriteLine appends a line to the log
+func (w *LogWriter) WriteLine(line string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := w.file.WriteString(strings.TrimRight(line, "\r\n") + "\n")
+	return err
+}
+
+// Replay appends the lines read from r, waiting interval between lines. Blank
+// lines are skipped. It returns the number of lines written.
+func (w *LogWriter) Replay(ctx context.Context, r io.Reader, interval time.Duration) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	written := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if written > 0 && interval > 0 {
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return written, ctx.Err()
+			}
+		}
+
+		if err := w.WriteLine(line); err != nil {
+			return written, err
+		}
+		written++
+	}
+
+	return written, scanner.Err()
+}
+
+// ReplayFixture replays an embedded SquadGame.log fixture
+func (w *LogWriter) ReplayFixture(ctx context.Context, name string, interval time.Duration) (int, error) {
+	data, err := Fixture(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return w.Replay(ctx, bytes.NewReader(data), interval)
+}
+
+// Close closes the log file
+func (w *LogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Close()
+}
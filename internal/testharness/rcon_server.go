@@ -0,0 +1,771 @@
+// Package testharness provides an in-process fake Squad server for integration
+// tests: an RCON server speaking the Source RCON protocol and a writer that
+// replays SquadGame.log fixtures.
+package testharness
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source RCON packet types as used by Squad
+const (
+	packetResponse = 0x00
+	packetServer   = 0x01
+	packetCommand  = 0x02 // Also used for the auth response
+	packetAuth     = 0x03
+
+	// maxPacketBody is the largest body sent in one packet, longer responses
+	// are split across several packets
+	maxPacketBody = 4096
+)
+
+// endOfResponse is what Squad sends after echoing the empty packet clients
+// write behind every command. Clients use it to find the end of responses
+// that span several packets.
+var endOfResponse = []byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+// responseLatency delays the end of every response. The RCON client drops a
+// response that completes before it starts waiting for it, which a real
+// network round trip hides but loopback doesn't.
+const responseLatency = 10 * time.Millisecond
+
+// Chat types used in chat packets
+const (
+	ChatAll   = "ChatAll"
+	ChatTeam  = "ChatTeam"
+	ChatSquad = "ChatSquad"
+	ChatAdmin = "ChatAdmin"
+)
+
+// CommandHandler answers an RCON command. args is everything after the
+// command name. Handlers run on the connection's goroutine and may call the
+// server's setters and Emit methods.
+type CommandHandler func(args string) string
+
+// Player is an online player listed by ListPlayers
+type Player struct {
+	ID       int
+	EOSID    string
+	SteamID  string
+	Name     string
+	TeamID   int
+	SquadID  int // 0 when not in a squad
+	IsLeader bool
+	Role     string
+}
+
+// DisconnectedPlayer is a recently disconnected player listed by ListPlayers
+type DisconnectedPlayer struct {
+	ID      int
+	EOSID   string
+	SteamID string
+	Name    string
+	Since   time.Duration
+}
+
+// Squad is a squad listed by ListSquads. The size is counted from the online
+// players and the creator defaults to the squad leader.
+type Squad struct {
+	ID             int
+	TeamID         int
+	Name           string
+	Locked         bool
+	CreatorName    string
+	CreatorEOSID   string
+	CreatorSteamID string
+}
+
+// Layer is a layer reported by ShowCurrentMap and ShowNextMap
+type Layer struct {
+	Level    string
+	Layer    string
+	Factions [2]string
+}
+
+// RconServer is a scriptable fake Squad RCON server listening on localhost
+type RconServer struct {
+	listener net.Listener
+	password string
+	wg       sync.WaitGroup
+
+	mu           sync.Mutex
+	closed       bool
+	clients      map[*rconClient]bool // Value is whether the client authenticated
+	changed      chan struct{}        // Closed and replaced when commands or clients change
+	handlers     map[string]CommandHandler
+	commands     []string
+	players      []Player
+	disconnected []DisconnectedPlayer
+	squads       []Squad
+	teams        [2]string
+	currentMap   Layer
+	nextMap      *Layer
+	serverInfo   map[string]interface{}
+}
+
+// rconClient is a connection to the fake server
+type rconClient struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+}
+
+// NewRconServer starts a fake RCON server on a random localhost port
+func NewRconServer(password string) (*RconServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	s := &RconServer{
+		listener: listener,
+		password: password,
+		clients:  make(map[*rconClient]bool),
+		changed:  make(chan struct{}),
+		teams:    [2]string{"United States Army", "Russian Ground Forces"},
+		currentMap: Layer{
+			Level:    "Gorodok",
+			Layer:    "Gorodok_RAAS_v1",
+			Factions: [2]string{"USA", "RGF"},
+		},
+		serverInfo: make(map[string]interface{}),
+	}
+
+	s.handlers = map[string]CommandHandler{
+		"listplayers":    s.listPlayers,
+		"listsquads":     s.listSquads,
+		"showserverinfo": s.showServerInfo,
+		"showcurrentmap": s.showCurrentMap,
+		"shownextmap":    s.showNextMap,
+		"adminwarn":      s.adminWarn(false),
+		"adminwarnbyid":  s.adminWarn(true),
+		"adminkick":      s.adminKick(false),
+		"adminkickbyid":  s.adminKick(true),
+	}
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+
+	return s, nil
+}
+
+// Addr returns the host:port the server listens on
+func (s *RconServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Host returns the host the server listens on
+func (s *RconServer) Host() string {
+	return s.listener.Addr().(*net.TCPAddr).IP.String()
+}
+
+// Port returns the port the server listens on
+func (s *RconServer) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Password returns the RCON password clients must authenticate with
+func (s *RconServer) Password() string {
+	return s.password
+}
+
+// Handle sets the handler for a command, replacing the default one if any.
+// Command names are case-insensitive like on a real server.
+func (s *RconServer) Handle(command string, handler CommandHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[strings.ToLower(command)] = handler
+}
+
+// SetPlayers replaces the online players
+func (s *RconServer) SetPlayers(players ...Player) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.players = append([]Player(nil), players...)
+}
+
+// SetDisconnectedPlayers replaces the recently disconnected players
+func (s *RconServer) SetDisconnectedPlayers(players ...DisconnectedPlayer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.disconnected = append([]DisconnectedPlayer(nil), players...)
+}
+
+// SetSquads replaces the squads
+func (s *RconServer) SetSquads(squads ...Squad) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.squads = append([]Squad(nil), squads...)
+}
+
+// SetTeams sets the names of both teams
+func (s *RconServer) SetTeams(teamOne, teamTwo string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.teams = [2]string{teamOne, teamTwo}
+}
+
+// SetCurrentMap sets the layer reported by ShowCurrentMap
+func (s *RconServer) SetCurrentMap(layer Layer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.currentMap = layer
+}
+
+// SetNextMap sets the layer reported by ShowNextMap, nil when it isn't defined
+func (s *RconServer) SetNextMap(layer *Layer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextMap = layer
+}
+
+// SetServerInfo overrides a ShowServerInfo field, e.g. "PublicQueue_I"
+func (s *RconServer) SetServerInfo(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.serverInfo[key] = value
+}
+
+// Players returns the online players
+func (s *RconServer) Players() []Player {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Player(nil), s.players...)
+}
+
+// Commands returns the commands received so far, in order
+func (s *RconServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.commands...)
+}
+
+// ClearCommands forgets the commands received so far
+func (s *RconServer) ClearCommands() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commands = nil
+}
+
+// WaitForCommand waits until a command starting with prefix was received and
+// returns it. The prefix is matched case-insensitively.
+func (s *RconServer) WaitForCommand(prefix string, timeout time.Duration) (string, error) {
+	var command string
+	err := s.waitFor(timeout, func() bool {
+		for _, received := range s.commands {
+			if len(received) >= len(prefix) && strings.EqualFold(received[:len(prefix)], prefix) {
+				command = received
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return "", fmt.Errorf("command %q: %w", prefix, err)
+	}
+
+	return command, nil
+}
+
+// WaitForClients waits until at least n clients are authenticated
+func (s *RconServer) WaitForClients(n int, timeout time.Duration) error {
+	err := s.waitFor(timeout, func() bool {
+		return s.authenticatedLocked() >= n
+	})
+	if err != nil {
+		return fmt.Errorf("%d authenticated clients: %w", n, err)
+	}
+
+	return nil
+}
+
+// waitFor waits until done, which is called with the lock held, returns true
+func (s *RconServer) waitFor(timeout time.Duration, done func() bool) error {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		s.mu.Lock()
+		if done() {
+			s.mu.Unlock()
+			return nil
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return errors.New("timed out waiting")
+		}
+	}
+}
+
+// notifyLocked wakes up waiters after commands or clients changed
+func (s *RconServer) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// authenticatedLocked counts the authenticated clients
+func (s *RconServer) authenticatedLocked() int {
+	count := 0
+	for _, authenticated := range s.clients {
+		if authenticated {
+			count++
+		}
+	}
+	return count
+}
+
+// Emit sends a server packet with a raw body to all authenticated clients
+func (s *RconServer) Emit(body string) {
+	// A zero ID would make a 246 byte body start with endOfResponse
+	packet := encodePacket(1, packetServer, body)
+
+	s.mu.Lock()
+	clients := make([]*rconClient, 0, len(s.clients))
+	for client, authenticated := range s.clients {
+		if authenticated {
+			clients = append(clients, client)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, client := range clients {
+		client.write(packet)
+	}
+}
+
+// EmitChat sends a chat message from a player
+func (s *RconServer) EmitChat(chatType string, player Player, message string) {
+	s.Emit(fmt.Sprintf("[%s] [Online IDs:EOS: %s steam: %s] %s : %s", chatType, player.EOSID, player.SteamID, player.Name, message))
+}
+
+// EmitWarn sends the notice of a player being warned
+func (s *RconServer) EmitWarn(player Player, message string) {
+	s.Emit(fmt.Sprintf("Remote admin has warned player %s. Message was \"%s\"", player.Name, message))
+}
+
+// EmitKick sends the notice of a player being kicked
+func (s *RconServer) EmitKick(player Player) {
+	s.Emit(fmt.Sprintf("Kicked player %d. [Online IDs= EOS: %s steam: %s] %s", player.ID, player.EOSID, player.SteamID, player.Name))
+}
+
+// EmitAdminCamera sends the notice of an admin entering or leaving the admin camera
+func (s *RconServer) EmitAdminCamera(admin Player, possessed bool) {
+	// Squad spells "IDs" differently in the two messages
+	if possessed {
+		s.Emit(fmt.Sprintf("[Online Ids:EOS: %s steam: %s] %s has possessed admin camera.", admin.EOSID, admin.SteamID, admin.Name))
+		return
+	}
+	s.Emit(fmt.Sprintf("[Online IDs:EOS: %s steam: %s] %s has unpossessed admin camera.", admin.EOSID, admin.SteamID, admin.Name))
+}
+
+// EmitSquadCreated sends the notice of a player creating a squad
+func (s *RconServer) EmitSquadCreated(player Player, squadID int, squadName, teamName string) {
+	s.Emit(fmt.Sprintf("%s (Online IDs: EOS: %s steam: %s) has created Squad %d (Squad Name: %s) on %s",
+		player.Name, player.EOSID, player.SteamID, squadID, squadName, teamName))
+}
+
+// DropConnections closes all client connections as if the server restarted.
+// The server keeps accepting new connections.
+func (s *RconServer) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		client.conn.Close()
+	}
+}
+
+// Close stops the server and closes all client connections
+func (s *RconServer) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	err := s.listener.Close()
+	for client := range s.clients {
+		client.conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// acceptLoop accepts connections until the server is closed
+func (s *RconServer) acceptLoop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		client := &rconClient{conn: conn}
+		s.clients[client] = false
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serve(client)
+	}
+}
+
+// serve reads packets from a client until it disconnects
+func (s *RconServer) serve(client *rconClient) {
+	defer s.wg.Done()
+	defer func() {
+		client.conn.Close()
+
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.notifyLocked()
+		s.mu.Unlock()
+	}()
+
+	reader := bufio.NewReader(client.conn)
+	for {
+		id, packetType, body, err := readPacket(reader)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		authenticated := s.clients[client]
+		s.mu.Unlock()
+
+		switch {
+		case packetType == packetAuth:
+			if body != s.password {
+				client.write(encodePacket(-1, packetCommand, ""))
+				return
+			}
+			client.write(encodePacket(id, packetResponse, ""))
+			client.write(encodePacket(id, packetCommand, ""))
+
+			s.mu.Lock()
+			s.clients[client] = true
+			s.notifyLocked()
+			s.mu.Unlock()
+
+		case packetType != packetCommand || !authenticated:
+			return
+
+		case body == "":
+			time.Sleep(responseLatency)
+			client.write(append(encodePacket(id, packetResponse, ""), endOfResponse...))
+
+		default:
+			client.writeResponse(id, s.execute(body))
+		}
+	}
+}
+
+// execute records a command and runs its handler
+func (s *RconServer) execute(command string) string {
+	name, args, _ := strings.Cut(strings.TrimSpace(command), " ")
+
+	s.mu.Lock()
+	s.commands = append(s.commands, command)
+	s.notifyLocked()
+	handler := s.handlers[strings.ToLower(name)]
+	s.mu.Unlock()
+
+	if handler == nil {
+		return ""
+	}
+	return handler(strings.TrimSpace(args))
+}
+
+// write sends raw bytes to the client
+func (c *rconClient) write(data []byte) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.Write(data)
+}
+
+// writeResponse sends a command response, split into packets of at most maxPacketBody bytes
+func (c *rconClient) writeResponse(id int32, response string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	for {
+		chunk := response
+		if len(chunk) > maxPacketBody {
+			chunk = chunk[:maxPacketBody]
+		}
+		response = response[len(chunk):]
+
+		if _, err := c.conn.Write(encodePacket(id, packetResponse, chunk)); err != nil || response == "" {
+			return
+		}
+	}
+}
+
+// readPacket reads one packet. The size field doesn't count itself, and the
+// body is followed by two null bytes.
+func readPacket(reader *bufio.Reader) (id int32, packetType int32, body string, err error) {
+	var size int32
+	if err := binary.Read(reader, binary.LittleEndian, &size); err != nil {
+		return 0, 0, "", err
+	}
+	if size < 10 || size > maxPacketBody+10 {
+		return 0, 0, "", fmt.Errorf("invalid packet size %d", size)
+	}
+
+	packet := make([]byte, size)
+	if _, err := io.ReadFull(reader, packet); err != nil {
+		return 0, 0, "", err
+	}
+
+	id = int32(binary.LittleEndian.Uint32(packet[0:4]))
+	packetType = int32(binary.LittleEndian.Uint32(packet[4:8]))
+	body = strings.TrimRight(string(packet[8:size-2]), "\x00")
+
+	return id, packetType, body, nil
+}
+
+// encodePacket encodes one packet
+func encodePacket(id int32, packetType int32, body string) []byte {
+	packet := make([]byte, len(body)+14)
+
+	binary.LittleEndian.PutUint32(packet[0:4], uint32(len(body)+10))
+	binary.LittleEndian.PutUint32(packet[4:8], uint32(id))
+	binary.LittleEndian.PutUint32(packet[8:12], uint32(packetType))
+	copy(packet[12:], body)
+
+	return packet
+}
+
+// listPlayers answers ListPlayers
+func (s *RconServer) listPlayers(string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("----- Active Players -----\n")
+	for _, player := range s.players {
+		squadID := "N/A"
+		if player.SquadID > 0 {
+			squadID = strconv.Itoa(player.SquadID)
+		}
+		fmt.Fprintf(&b, "ID: %d | Online IDs: EOS: %s steam: %s | Name: %s | Team ID: %d | Squad ID: %s | Is Leader: %s | Role: %s\n",
+			player.ID, player.EOSID, player.SteamID, player.Name, player.TeamID, squadID, boolString(player.IsLeader), player.Role)
+	}
+
+	b.WriteString("----- Recently Disconnected Players [Max of 15] -----\n")
+	for _, player := range s.disconnected {
+		seconds := int(player.Since.Seconds())
+		fmt.Fprintf(&b, "ID: %d | Online IDs: EOS: %s steam: %s | Since Disconnect: %02dm.%02ds | Name: %s\n",
+			player.ID, player.EOSID, player.SteamID, seconds/60, seconds%60, player.Name)
+	}
+
+	return b.String()
+}
+
+// listSquads answers ListSquads
+func (s *RconServer) listSquads(string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("----- Active Squads -----\n")
+	for i, teamName := range s.teams {
+		teamID := i + 1
+		fmt.Fprintf(&b, "Team ID: %d (%s)\n", teamID, teamName)
+
+		for _, squad := range s.squads {
+			if squad.TeamID != teamID {
+				continue
+			}
+
+			size := 0
+			creator := Player{Name: squad.CreatorName, EOSID: squad.CreatorEOSID, SteamID: squad.CreatorSteamID}
+			for _, player := range s.players {
+				if player.TeamID != teamID || player.SquadID != squad.ID {
+					continue
+				}
+				size++
+				if player.IsLeader && creator.Name == "" {
+					creator = player
+				}
+			}
+
+			fmt.Fprintf(&b, "ID: %d | Name: %s | Size: %d | Locked: %s | Creator Name: %s | Creator Online IDs: EOS: %s steam: %s\n",
+				squad.ID, squad.Name, size, boolString(squad.Locked), creator.Name, creator.EOSID, creator.SteamID)
+		}
+	}
+
+	return b.String()
+}
+
+// showServerInfo answers ShowServerInfo with Squad's typed key suffixes
+func (s *RconServer) showServerInfo(string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := map[string]interface{}{
+		"MaxPlayers":              100,
+		"GameMode_s":              "RAAS",
+		"MapName_s":               s.currentMap.Layer,
+		"GameVersion_s":           "v8.0.0.0",
+		"LICENSEDSERVER_b":        false,
+		"PLAYTIME_I":              "0",
+		"FLAGS_I":                 "7",
+		"MATCHHOPPER_s":           "TeamDeathmatch",
+		"MatchTimeout_d":          "120",
+		"SESSIONTEMPLATENAME_s":   "GameSession",
+		"Password_b":              false,
+		"PlayerCount_I":           strconv.Itoa(len(s.players)),
+		"ServerName_s":            "Squad Aegis Test Server",
+		"CurrentModLoadedCount_I": "0",
+		"AllModsWhitelisted_b":    false,
+		"Region_s":                "eu-central-1",
+		"TeamOne_s":               s.teams[0],
+		"TeamTwo_s":               s.teams[1],
+		"PlayerReserveCount_I":    "2",
+		"PublicQueueLimit_I":      "25",
+		"PublicQueue_I":           "0",
+		"ReservedQueue_I":         "0",
+		"BeaconPort_I":            "15000",
+	}
+	for key, value := range s.serverInfo {
+		info[key] = value
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// showCurrentMap answers ShowCurrentMap
+func (s *RconServer) showCurrentMap(string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fmt.Sprintf("Current level is %s, layer is %s, factions %s %s",
+		s.currentMap.Level, s.currentMap.Layer, s.currentMap.Factions[0], s.currentMap.Factions[1])
+}
+
+// showNextMap answers ShowNextMap
+func (s *RconServer) showNextMap(string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.nextMap == nil {
+		return "Next level is not defined"
+	}
+	return fmt.Sprintf("Next level is %s, layer is %s, factions %s %s",
+		s.nextMap.Level, s.nextMap.Layer, s.nextMap.Factions[0], s.nextMap.Factions[1])
+}
+
+// adminWarn answers AdminWarn and AdminWarnById by emitting the warn notice
+func (s *RconServer) adminWarn(byID bool) CommandHandler {
+	return func(args string) string {
+		target, message, _ := strings.Cut(args, " ")
+
+		s.mu.Lock()
+		index := s.findPlayerLocked(target, byID)
+		var player Player
+		if index >= 0 {
+			player = s.players[index]
+		}
+		s.mu.Unlock()
+
+		if index < 0 {
+			return fmt.Sprintf("Could not find player %s", target)
+		}
+
+		s.EmitWarn(player, message)
+		return ""
+	}
+}
+
+// adminKick answers AdminKick and AdminKickById by moving the player to the
+// recently disconnected players and emitting the kick notice
+func (s *RconServer) adminKick(byID bool) CommandHandler {
+	return func(args string) string {
+		target, _, _ := strings.Cut(args, " ")
+
+		s.mu.Lock()
+		index := s.findPlayerLocked(target, byID)
+		var player Player
+		if index >= 0 {
+			player = s.players[index]
+			s.players = append(s.players[:index], s.players[index+1:]...)
+			s.disconnected = append(s.disconnected, DisconnectedPlayer{
+				ID:      player.ID,
+				EOSID:   player.EOSID,
+				SteamID: player.SteamID,
+				Name:    player.Name,
+			})
+		}
+		s.mu.Unlock()
+
+		if index < 0 {
+			return fmt.Sprintf("Could not find player %s", target)
+		}
+
+		s.EmitKick(player)
+		return ""
+	}
+}
+
+// findPlayerLocked returns the index of the online player matching target, or
+// -1. Without byID, target may be an EOS ID, a Steam ID or a name.
+func (s *RconServer) findPlayerLocked(target string, byID bool) int {
+	for i, player := range s.players {
+		if byID {
+			if strconv.Itoa(player.ID) == target {
+				return i
+			}
+			continue
+		}
+		if player.EOSID == target || player.SteamID == target || strings.EqualFold(player.Name, target) {
+			return i
+		}
+	}
+	return -1
+}
+
+// boolString formats a bool the way Squad does
+func boolString(value bool) string {
+	if value {
+		return "True"
+	}
+	return "False"
+}
@@ -3,6 +3,7 @@ package valkey
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/valkey-io/valkey-go"
@@ -161,6 +162,66 @@ func (c *Client) Expire(ctx context.Context, key string, expiration time.Duratio
 	return c.client.Do(ctx, cmd).Error()
 }
 
+// StreamEntry is a single entry read from a Valkey stream
+type StreamEntry struct {
+	ID     string
+	Fields map[string]string
+}
+
+// XAdd appends an entry to a stream with an explicit ID, trimming the stream to roughly maxLen entries
+func (c *Client) XAdd(ctx context.Context, key string, id string, maxLen int64, fields map[string]string) (string, error) {
+	if len(fields) == 0 {
+		return "", fmt.Errorf("stream entry must have at least one field")
+	}
+
+	var fv valkey.Completed
+	if maxLen > 0 {
+		builder := c.client.B().Xadd().Key(key).Maxlen().Almost().Threshold(strconv.FormatInt(maxLen, 10)).Id(id).FieldValue()
+		for field, value := range fields {
+			builder = builder.FieldValue(field, value)
+		}
+		fv = builder.Build()
+	} else {
+		builder := c.client.B().Xadd().Key(key).Id(id).FieldValue()
+		for field, value := range fields {
+			builder = builder.FieldValue(field, value)
+		}
+		fv = builder.Build()
+	}
+
+	return c.client.Do(ctx, fv).ToString()
+}
+
+// XRange returns up to count stream entries between start and end (inclusive)
+func (c *Client) XRange(ctx context.Context, key string, start, end string, count int64) ([]StreamEntry, error) {
+	cmd := c.client.B().Xrange().Key(key).Start(start).End(end).Count(count).Build()
+	return c.streamEntries(c.client.Do(ctx, cmd))
+}
+
+// XRevRange returns up to count stream entries between end and start, newest first
+func (c *Client) XRevRange(ctx context.Context, key string, end, start string, count int64) ([]StreamEntry, error) {
+	cmd := c.client.B().Xrevrange().Key(key).End(end).Start(start).Count(count).Build()
+	return c.streamEntries(c.client.Do(ctx, cmd))
+}
+
+func (c *Client) streamEntries(result valkey.ValkeyResult) ([]StreamEntry, error) {
+	if result.Error() != nil {
+		return nil, result.Error()
+	}
+
+	entries, err := result.AsXRange()
+	if err != nil {
+		return nil, err
+	}
+
+	streamEntries := make([]StreamEntry, 0, len(entries))
+	for _, entry := range entries {
+		streamEntries = append(streamEntries, StreamEntry{ID: entry.ID, Fields: entry.FieldValues})
+	}
+
+	return streamEntries, nil
+}
+
 // Close closes the Valkey client connection
 func (c *Client) Close() {
 	if c.client != nil {
@@ -0,0 +1,209 @@
+package workflow_manager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression
+// (minute hour day-of-month month day-of-week)
+type cronSchedule struct {
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	domStar  bool
+	dowStar  bool
+	location *time.Location
+}
+
+// cronField describes the allowed range and names of a cron field
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDom    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDow = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronMacros are shorthand expressions accepted in place of five fields
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCronSchedule parses a cron expression evaluated in the given time zone
+func parseCronSchedule(expression string, location *time.Location) (*cronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := cronMacros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	if location == nil {
+		location = time.UTC
+	}
+
+	schedule := &cronSchedule{location: location}
+	var err error
+
+	if schedule.minute, err = parseCronField(fields[0], cronMinute); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseCronField(fields[1], cronHour); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = parseCronField(fields[2], cronDom); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseCronField(fields[3], cronMonth); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = parseCronField(fields[4], cronDow); err != nil {
+		return nil, err
+	}
+
+	// Sunday can be written as 0 or 7
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+
+	schedule.domStar = fields[2] == "*" || fields[2] == "?"
+	schedule.dowStar = fields[4] == "*" || fields[4] == "?"
+
+	return schedule, nil
+}
+
+// parseCronField parses a single comma separated cron field into a bit set
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, field.name)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			start, end = field.min, field.max
+		case strings.Contains(rangePart, "-"):
+			low, high, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseCronValue(low, field); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(high, field); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if start, err = parseCronValue(rangePart, field); err != nil {
+				return 0, err
+			}
+			end = start
+			if hasStep {
+				end = field.max
+			}
+		}
+
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q in %s field", rangePart, field.name)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronValue parses a number or name within a cron field
+func parseCronValue(value string, field cronField) (int, error) {
+	if n, ok := field.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", value, field.name)
+	}
+	if n < field.min || n > field.max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d] in %s field", n, field.min, field.max, field.name)
+	}
+	return n, nil
+}
+
+// Next returns the first activation time strictly after t
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+
+	// Give up after five years; only impossible dates like Feb 30 get this far
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches applies the standard cron rule: when both day fields are
+// restricted, a day matching either of them is accepted
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
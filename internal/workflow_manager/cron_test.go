@@ -0,0 +1,85 @@
+package workflow_manager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		location   *time.Location
+		from       time.Time
+		expected   time.Time
+	}{
+		{
+			name:       "Every minute",
+			expression: "* * * * *",
+			location:   time.UTC,
+			from:       time.Date(2025, 1, 1, 10, 0, 30, 0, time.UTC),
+			expected:   time.Date(2025, 1, 1, 10, 1, 0, 0, time.UTC),
+		},
+		{
+			name:       "Every 15 minutes",
+			expression: "*/15 * * * *",
+			location:   time.UTC,
+			from:       time.Date(2025, 1, 1, 10, 16, 0, 0, time.UTC),
+			expected:   time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "Daily macro rolls over to next day",
+			expression: "@daily",
+			location:   time.UTC,
+			from:       time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+			expected:   time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Weekdays at 18:30 by name",
+			expression: "30 18 * * mon-fri",
+			location:   time.UTC,
+			from:       time.Date(2025, 1, 3, 19, 0, 0, 0, time.UTC), // Friday
+			expected:   time.Date(2025, 1, 6, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "Sunday as 7",
+			expression: "0 12 * * 7",
+			location:   time.UTC,
+			from:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected:   time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Time zone",
+			expression: "0 20 * * *",
+			location:   newYork,
+			from:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			expected:   time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.expression, tt.location)
+			if err != nil {
+				t.Fatalf("parseCronSchedule(%q) failed: %v", tt.expression, err)
+			}
+			next := schedule.Next(tt.from)
+			if !next.Equal(tt.expected) {
+				t.Errorf("Next(%v) = %v, expected %v", tt.from, next, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := parseCronSchedule(expression, time.UTC); err == nil {
+			t.Errorf("Expected error for cron expression %q", expression)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package workflow_manager
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// WorkflowBundleFormat identifies workflow bundles
+	WorkflowBundleFormat = "squad-aegis-workflows"
+	// WorkflowBundleVersion is the bundle format version written by exports
+	WorkflowBundleVersion = 1
+)
+
+// ValidateWorkflowBundle checks the bundle header and every workflow in it.
+// Problems are keyed by workflow index; an empty map means the bundle is valid.
+func ValidateWorkflowBundle(bundle *models.WorkflowBundle) (map[int][]string, error) {
+	if bundle.Format != WorkflowBundleFormat {
+		return nil, fmt.Errorf("unknown bundle format %q", bundle.Format)
+	}
+	if bundle.Version < 1 || bundle.Version > WorkflowBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+	if len(bundle.Workflows) == 0 {
+		return nil, fmt.Errorf("bundle contains no workflows")
+	}
+
+	problems := make(map[int][]string)
+	for i, entry := range bundle.Workflows {
+		var entryProblems []string
+		if entry.Name == "" || len(entry.Name) > 255 {
+			entryProblems = append(entryProblems, "name must be between 1 and 255 characters")
+		}
+		for _, issue := range ValidateWorkflowDefinition(&entry.Definition, nil).Errors {
+			entryProblems = append(entryProblems, fmt.Sprintf("%s: %s", issue.Path, issue.Message))
+		}
+		if len(entryProblems) > 0 {
+			problems[i] = entryProblems
+		}
+	}
+
+	return problems, nil
+}
+
+// ParseWorkflowBundle decodes a JSON or YAML bundle. YAML is converted to JSON
+// first so both formats use the same field names.
+func ParseWorkflowBundle(data []byte, isYAML bool) (*models.WorkflowBundle, error) {
+	if isYAML {
+		var document interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML bundle: %w", err)
+		}
+
+		var err error
+		data, err = json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert YAML bundle: %w", err)
+		}
+	}
+
+	var bundle models.WorkflowBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+
+	return &bundle, nil
+}
+
+// EncodeWorkflowBundle encodes a bundle as indented JSON or as YAML
+func EncodeWorkflowBundle(bundle *models.WorkflowBundle, asYAML bool) ([]byte, error) {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil || !asYAML {
+		return data, err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(document)
+}
+
+// ExportWorkflow converts a stored workflow into a bundle entry
+func (wd *WorkflowDatabase) ExportWorkflow(workflow *models.ServerWorkflow, includeKV bool) (*models.WorkflowBundleEntry, error) {
+	entry := &models.WorkflowBundleEntry{
+		Name:        workflow.Name,
+		Description: workflow.Description,
+		Enabled:     workflow.Enabled,
+		Definition:  workflow.Definition,
+	}
+
+	for _, variable := range workflow.Variables {
+		entry.Variables = append(entry.Variables, models.WorkflowBundleVariable{
+			Name:        variable.Name,
+			Value:       variable.Value,
+			Description: variable.Description,
+		})
+	}
+
+	if includeKV {
+		kv, err := wd.GetAllKVPairs(workflow.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load KV store: %w", err)
+		}
+		if len(kv) > 0 {
+			entry.KV = kv
+		}
+	}
+
+	return entry, nil
+}
+
+// NewWorkflowBundle wraps exported workflows in a bundle
+func NewWorkflowBundle(entries []models.WorkflowBundleEntry) *models.WorkflowBundle {
+	return &models.WorkflowBundle{
+		Format:     WorkflowBundleFormat,
+		Version:    WorkflowBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Workflows:  entries,
+	}
+}
+
+// ImportWorkflow creates a workflow on a server from a bundle entry in tx, along
+// with its variables and KV seed data. Variable overrides replace keys of the
+// definition's default variables. Imports of several workflows share tx so
+// they are committed or rolled back together.
+func (wd *WorkflowDatabase) ImportWorkflow(tx *sql.Tx, serverID uuid.UUID, entry *models.WorkflowBundleEntry, createdBy uuid.UUID, overrides map[string]interface{}) (*models.ServerWorkflow, error) {
+	now := time.Now()
+
+	definition := entry.Definition
+	if len(overrides) > 0 {
+		variables := make(map[string]interface{}, len(definition.Variables)+len(overrides))
+		for key, value := range definition.Variables {
+			variables[key] = value
+		}
+		for key, value := range overrides {
+			variables[key] = value
+		}
+		definition.Variables = variables
+	}
+
+	workflow := &models.ServerWorkflow{
+		ID:          uuid.New(),
+		ServerID:    serverID,
+		Name:        entry.Name,
+		Description: entry.Description,
+		Enabled:     entry.Enabled,
+		Definition:  definition,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := insertWorkflow(tx, workflow); err != nil {
+		return nil, fmt.Errorf("failed to create workflow: %w", err)
+	}
+
+	if err := importWorkflowData(tx, workflow, entry, now); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// importWorkflowData stores the variables and KV seed data of an imported workflow
+func importWorkflowData(tx *sql.Tx, workflow *models.ServerWorkflow, entry *models.WorkflowBundleEntry, now time.Time) error {
+	for _, bundleVariable := range entry.Variables {
+		variable := models.ServerWorkflowVariable{
+			ID:          uuid.New(),
+			WorkflowID:  workflow.ID,
+			Name:        bundleVariable.Name,
+			Value:       bundleVariable.Value,
+			Description: bundleVariable.Description,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := insertWorkflowVariable(tx, &variable); err != nil {
+			return fmt.Errorf("failed to create variable %s: %w", variable.Name, err)
+		}
+		workflow.Variables = append(workflow.Variables, variable)
+	}
+
+	for key, value := range entry.KV {
+		if err := setWorkflowKVValue(tx, workflow.ID, key, value); err != nil {
+			return fmt.Errorf("failed to seed KV key %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
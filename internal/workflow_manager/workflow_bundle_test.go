@@ -0,0 +1,93 @@
+package workflow_manager
+
+import (
+	"strings"
+	"testing"
+
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func TestWorkflowBundleYAMLRoundTrip(t *testing.T) {
+	bundle := NewWorkflowBundle([]models.WorkflowBundleEntry{
+		{
+			Name:    "Warn teamkillers",
+			Enabled: true,
+			Definition: models.WorkflowDefinition{
+				Version:   "1.0",
+				Variables: map[string]interface{}{"warning": "Stop teamkilling"},
+				Steps: []models.WorkflowStep{
+					{ID: "warn", Name: "Warn", Type: models.StepTypeAction, Enabled: true, Config: map[string]interface{}{
+						"action_type": models.ActionTypeWarnPlayer,
+						"player_id":   "${trigger_event.steam_id}",
+						"message":     "${warning}",
+					}},
+				},
+			},
+			KV: map[string]interface{}{"count": float64(3)},
+		},
+	})
+
+	data, err := EncodeWorkflowBundle(bundle, true)
+	if err != nil {
+		t.Fatalf("Failed to encode bundle: %v", err)
+	}
+	if !strings.Contains(string(data), "format: squad-aegis-workflows") {
+		t.Fatalf("Expected YAML output, got:\n%s", data)
+	}
+
+	parsed, err := ParseWorkflowBundle(data, true)
+	if err != nil {
+		t.Fatalf("Failed to parse bundle: %v", err)
+	}
+
+	problems, err := ValidateWorkflowBundle(parsed)
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("Expected a valid bundle, got %v %v", err, problems)
+	}
+
+	entry := parsed.Workflows[0]
+	if entry.Name != "Warn teamkillers" || entry.Definition.Steps[0].Config["message"] != "${warning}" {
+		t.Errorf("Workflow did not survive the round trip: %+v", entry)
+	}
+	if entry.KV["count"] != float64(3) {
+		t.Errorf("Expected KV count 3, got %v", entry.KV["count"])
+	}
+}
+
+func TestValidateWorkflowBundle(t *testing.T) {
+	bundle := NewWorkflowBundle([]models.WorkflowBundleEntry{
+		{
+			Name: "Nested",
+			Definition: models.WorkflowDefinition{
+				Steps: []models.WorkflowStep{
+					{ID: "check", Name: "Check", Type: models.StepTypeCondition, Config: map[string]interface{}{
+						"conditions": []interface{}{
+							map[string]interface{}{"field": "trigger_event.message", "operator": "equals", "value": "!launch"},
+						},
+						"true_steps": []interface{}{
+							map[string]interface{}{
+								"id":     "launch",
+								"name":   "Launch",
+								"type":   models.StepTypeAction,
+								"config": map[string]interface{}{"action_type": "launch_missiles"},
+							},
+						},
+					}},
+				},
+			},
+		},
+	})
+
+	problems, err := ValidateWorkflowBundle(bundle)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(problems[0]) != 1 || !strings.Contains(problems[0][0], "steps[0].true_steps[0]") {
+		t.Errorf("Expected the nested action type to be flagged, got %v", problems)
+	}
+
+	bundle.Format = "something-else"
+	if _, err := ValidateWorkflowBundle(bundle); err == nil {
+		t.Errorf("Expected an error for an unknown bundle format")
+	}
+}
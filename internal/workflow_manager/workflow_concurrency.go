@@ -0,0 +1,402 @@
+package workflow_manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+const (
+	// defaultMaxQueuedTriggers is used when a workflow doesn't set max_queued
+	defaultMaxQueuedTriggers = 100
+	// maxQueuedTriggersLimit is the hard upper bound for max_queued
+	maxQueuedTriggersLimit = 10000
+	// maxRateLimitWindow is the longest debounce or throttle window
+	maxRateLimitWindow = 24 * time.Hour
+	// rateLimitPruneThreshold is how many throttle windows a workflow keeps
+	// before expired ones are removed
+	rateLimitPruneThreshold = 1024
+	// triggerDropFlushInterval is how often dropped trigger counts are written to PostgreSQL
+	triggerDropFlushInterval = 10 * time.Second
+)
+
+// Reasons a trigger didn't start an execution, as counted in the execution stats
+const (
+	triggerDropThrottled = "throttled"
+	triggerDropDebounced = "debounced"
+	triggerDropDropped   = "dropped"
+	triggerDropQueueFull = "queue_full"
+)
+
+// queuedTrigger is a trigger waiting for a free execution slot
+type queuedTrigger struct {
+	workflow     *models.ServerWorkflow
+	triggerEvent map[string]interface{}
+	queuedAt     time.Time
+}
+
+// rateLimitWindow is the state of one rate limit key
+type rateLimitWindow struct {
+	last  time.Time   // Start of a throttle window
+	timer *time.Timer // Pending debounced run
+}
+
+// workflowLimiter tracks the executions, queue and rate limit windows of a workflow
+type workflowLimiter struct {
+	serverID uuid.UUID
+	running  int
+	queue    []queuedTrigger
+	windows  map[string]*rateLimitWindow
+}
+
+// limiterFor returns the limiter of a workflow, creating it if needed.
+// concurrencyMutex must be held.
+func (wm *WorkflowManager) limiterFor(workflow *models.ServerWorkflow) *workflowLimiter {
+	if wm.limiters == nil {
+		wm.limiters = make(map[uuid.UUID]*workflowLimiter)
+	}
+	limiter, ok := wm.limiters[workflow.ID]
+	if !ok {
+		limiter = &workflowLimiter{serverID: workflow.ServerID, windows: make(map[string]*rateLimitWindow)}
+		wm.limiters[workflow.ID] = limiter
+	}
+	return limiter
+}
+
+// SetServerExecutionLimit sets how many executions may run at once on a
+// server across all of its workflows. 0 removes the limit.
+func (wm *WorkflowManager) SetServerExecutionLimit(limit int) {
+	wm.concurrencyMutex.Lock()
+	defer wm.concurrencyMutex.Unlock()
+
+	wm.serverExecutionLimit = limit
+}
+
+// dispatchWorkflow starts an execution for a trigger, subject to the
+// workflow's rate limit, its concurrency limit and the per-server limit
+func (wm *WorkflowManager) dispatchWorkflow(workflow *models.ServerWorkflow, triggerEvent map[string]interface{}) {
+	if settings := workflow.Definition.Concurrency; settings != nil && settings.RateLimit != nil && settings.RateLimit.WindowMs > 0 {
+		if !wm.passRateLimit(workflow, triggerEvent, settings.RateLimit) {
+			return
+		}
+	}
+
+	wm.admitTrigger(workflow, triggerEvent)
+}
+
+// passRateLimit reports whether a trigger may run now. Debounced triggers
+// are admitted later by their timer.
+func (wm *WorkflowManager) passRateLimit(workflow *models.ServerWorkflow, triggerEvent map[string]interface{}, rateLimit *models.WorkflowRateLimit) bool {
+	key := ""
+	if rateLimit.Key != "" {
+		key = wm.replaceVariablesWithContext(rateLimit.Key, workflow.Definition.Variables, triggerEvent, nil)
+	}
+	window := time.Duration(rateLimit.WindowMs) * time.Millisecond
+	now := time.Now()
+
+	wm.concurrencyMutex.Lock()
+	defer wm.concurrencyMutex.Unlock()
+
+	limiter := wm.limiterFor(workflow)
+	current := limiter.windows[key]
+
+	switch rateLimit.Mode {
+	case models.RateLimitDebounce:
+		// A newer trigger replaces the pending one and restarts the window
+		if current != nil && current.timer != nil {
+			current.timer.Stop()
+			wm.countTriggerDropLocked(workflow.ID, triggerDropDebounced)
+		}
+
+		next := &rateLimitWindow{last: now}
+		next.timer = time.AfterFunc(window, func() {
+			wm.fireDebouncedTrigger(workflow, key, next, triggerEvent)
+		})
+		limiter.windows[key] = next
+		return false
+	default:
+		if current != nil && now.Sub(current.last) < window {
+			wm.countTriggerDropLocked(workflow.ID, triggerDropThrottled)
+			log.Debug().
+				Str("workflow_id", workflow.ID.String()).
+				Str("key", key).
+				Msg("Workflow trigger throttled")
+			return false
+		}
+
+		if len(limiter.windows) >= rateLimitPruneThreshold {
+			for windowKey, existing := range limiter.windows {
+				if existing.timer == nil && now.Sub(existing.last) >= window {
+					delete(limiter.windows, windowKey)
+				}
+			}
+		}
+		limiter.windows[key] = &rateLimitWindow{last: now}
+		return true
+	}
+}
+
+// fireDebouncedTrigger admits the last trigger of a debounce window once it has been quiet
+func (wm *WorkflowManager) fireDebouncedTrigger(workflow *models.ServerWorkflow, key string, window *rateLimitWindow, triggerEvent map[string]interface{}) {
+	if wm.ctx.Err() != nil {
+		return
+	}
+
+	wm.concurrencyMutex.Lock()
+	limiter := wm.limiterFor(workflow)
+	if limiter.windows[key] != window {
+		// Replaced by a newer trigger after the timer had already fired
+		wm.concurrencyMutex.Unlock()
+		return
+	}
+	delete(limiter.windows, key)
+	wm.concurrencyMutex.Unlock()
+
+	wm.admitTrigger(workflow, triggerEvent)
+}
+
+// hasCapacityLocked reports whether another execution of the workflow may start.
+// concurrencyMutex must be held.
+func (wm *WorkflowManager) hasCapacityLocked(workflow *models.ServerWorkflow, limiter *workflowLimiter) bool {
+	if settings := workflow.Definition.Concurrency; settings != nil && settings.MaxConcurrent > 0 && limiter.running >= settings.MaxConcurrent {
+		return false
+	}
+	if wm.serverExecutionLimit > 0 && wm.serverRunning[limiter.serverID] >= wm.serverExecutionLimit {
+		return false
+	}
+	return true
+}
+
+// admitTrigger starts an execution if there is capacity and otherwise queues
+// or drops the trigger according to the workflow's overflow policy
+func (wm *WorkflowManager) admitTrigger(workflow *models.ServerWorkflow, triggerEvent map[string]interface{}) {
+	wm.concurrencyMutex.Lock()
+	defer wm.concurrencyMutex.Unlock()
+
+	limiter := wm.limiterFor(workflow)
+
+	// Triggers already waiting go first
+	if len(limiter.queue) == 0 && wm.hasCapacityLocked(workflow, limiter) {
+		wm.startAdmittedLocked(workflow, limiter, triggerEvent)
+		return
+	}
+
+	policy := models.OverflowPolicyQueue
+	maxQueued := defaultMaxQueuedTriggers
+	if settings := workflow.Definition.Concurrency; settings != nil {
+		if settings.OverflowPolicy != "" {
+			policy = settings.OverflowPolicy
+		}
+		if settings.MaxQueued > 0 {
+			maxQueued = settings.MaxQueued
+		}
+	}
+
+	reason := ""
+	if policy == models.OverflowPolicyDrop {
+		reason = triggerDropDropped
+	} else if len(limiter.queue) >= maxQueued {
+		reason = triggerDropQueueFull
+	}
+
+	if reason != "" {
+		wm.countTriggerDropLocked(workflow.ID, reason)
+		log.Debug().
+			Str("workflow_id", workflow.ID.String()).
+			Str("reason", reason).
+			Msg("Workflow trigger dropped by concurrency limit")
+		return
+	}
+
+	limiter.queue = append(limiter.queue, queuedTrigger{
+		workflow:     workflow,
+		triggerEvent: triggerEvent,
+		queuedAt:     time.Now(),
+	})
+}
+
+// startAdmittedLocked takes an execution slot and starts the execution.
+// concurrencyMutex must be held.
+func (wm *WorkflowManager) startAdmittedLocked(workflow *models.ServerWorkflow, limiter *workflowLimiter, triggerEvent map[string]interface{}) {
+	if wm.serverRunning == nil {
+		wm.serverRunning = make(map[uuid.UUID]int)
+	}
+	limiter.running++
+	wm.serverRunning[limiter.serverID]++
+
+	go func() {
+		defer wm.releaseExecutionSlot(workflow.ID, limiter.serverID)
+		wm.executeWorkflow(workflow, triggerEvent)
+	}()
+}
+
+// releaseExecutionSlot frees the slot of a finished execution and starts
+// queued triggers of the same server, oldest first
+func (wm *WorkflowManager) releaseExecutionSlot(workflowID, serverID uuid.UUID) {
+	wm.concurrencyMutex.Lock()
+	defer wm.concurrencyMutex.Unlock()
+
+	if limiter, ok := wm.limiters[workflowID]; ok {
+		limiter.running--
+	}
+	wm.serverRunning[serverID]--
+	if wm.serverRunning[serverID] <= 0 {
+		delete(wm.serverRunning, serverID)
+	}
+
+	if wm.ctx.Err() != nil {
+		return
+	}
+
+	for {
+		var next *workflowLimiter
+		for _, limiter := range wm.limiters {
+			if limiter.serverID != serverID || len(limiter.queue) == 0 || !wm.hasCapacityLocked(limiter.queue[0].workflow, limiter) {
+				continue
+			}
+			if next == nil || limiter.queue[0].queuedAt.Before(next.queue[0].queuedAt) {
+				next = limiter
+			}
+		}
+		if next == nil {
+			break
+		}
+
+		queued := next.queue[0]
+		next.queue = next.queue[1:]
+		wm.startAdmittedLocked(queued.workflow, next, queued.triggerEvent)
+	}
+
+	if limiter, ok := wm.limiters[workflowID]; ok && limiter.running == 0 && len(limiter.queue) == 0 && len(limiter.windows) == 0 {
+		delete(wm.limiters, workflowID)
+	}
+}
+
+// pruneQueuedTriggers drops queued triggers of workflows that are no longer
+// active. It is called after the workflows are reloaded.
+func (wm *WorkflowManager) pruneQueuedTriggers() {
+	wm.concurrencyMutex.Lock()
+	defer wm.concurrencyMutex.Unlock()
+
+	for workflowID, limiter := range wm.limiters {
+		workflow, ok := wm.activeWorkflows[workflowID]
+		if !ok {
+			limiter.queue = nil
+			for _, window := range limiter.windows {
+				if window.timer != nil {
+					window.timer.Stop()
+				}
+			}
+			limiter.windows = make(map[string]*rateLimitWindow)
+			continue
+		}
+
+		// Queued triggers run with the reloaded definition
+		for i := range limiter.queue {
+			limiter.queue[i].workflow = workflow
+		}
+	}
+}
+
+// countTriggerDropLocked records a trigger that didn't start an execution.
+// concurrencyMutex must be held.
+func (wm *WorkflowManager) countTriggerDropLocked(workflowID uuid.UUID, reason string) {
+	wm.addTriggerDropsLocked(workflowID, reason, 1)
+}
+
+// addTriggerDropsLocked adds count drops for reason. concurrencyMutex must be held.
+func (wm *WorkflowManager) addTriggerDropsLocked(workflowID uuid.UUID, reason string, count int64) {
+	if wm.triggerDrops == nil {
+		wm.triggerDrops = make(map[uuid.UUID]map[string]int64)
+	}
+	if wm.triggerDrops[workflowID] == nil {
+		wm.triggerDrops[workflowID] = make(map[string]int64)
+	}
+	wm.triggerDrops[workflowID][reason] += count
+}
+
+// triggerDropLoop periodically writes the dropped trigger counts to PostgreSQL
+func (wm *WorkflowManager) triggerDropLoop() {
+	ticker := time.NewTicker(triggerDropFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wm.ctx.Done():
+			return
+		case <-ticker.C:
+			wm.flushTriggerDrops()
+		}
+	}
+}
+
+// flushTriggerDrops writes the counted drops to PostgreSQL. Counts that fail
+// to write are kept for the next flush.
+func (wm *WorkflowManager) flushTriggerDrops() {
+	wm.concurrencyMutex.Lock()
+	drops := wm.triggerDrops
+	wm.triggerDrops = nil
+	wm.concurrencyMutex.Unlock()
+
+	if wm.db == nil {
+		return
+	}
+
+	for workflowID, counts := range drops {
+		if err := wm.workflowDB.AddTriggerDrops(workflowID, counts); err != nil {
+			log.Error().Err(err).Str("workflow_id", workflowID.String()).Msg("Failed to record dropped workflow triggers")
+
+			wm.concurrencyMutex.Lock()
+			for reason, count := range counts {
+				wm.addTriggerDropsLocked(workflowID, reason, count)
+			}
+			wm.concurrencyMutex.Unlock()
+		}
+	}
+}
+
+// ConcurrencyStatus returns how many executions of a workflow are running
+// under its limits and how many triggers are queued
+func (wm *WorkflowManager) ConcurrencyStatus(workflowID uuid.UUID) (running, queued int) {
+	wm.concurrencyMutex.Lock()
+	defer wm.concurrencyMutex.Unlock()
+
+	if limiter, ok := wm.limiters[workflowID]; ok {
+		return limiter.running, len(limiter.queue)
+	}
+	return 0, 0
+}
+
+// validateConcurrency reports problems with a workflow's concurrency settings
+func validateConcurrency(concurrency *models.WorkflowConcurrency) []string {
+	if concurrency == nil {
+		return nil
+	}
+
+	var problems []string
+	if concurrency.MaxConcurrent < 0 {
+		problems = append(problems, "max_concurrent must not be negative")
+	}
+	switch concurrency.OverflowPolicy {
+	case "", models.OverflowPolicyQueue, models.OverflowPolicyDrop:
+	default:
+		problems = append(problems, fmt.Sprintf("overflow_policy %q must be %q or %q", concurrency.OverflowPolicy, models.OverflowPolicyQueue, models.OverflowPolicyDrop))
+	}
+	if concurrency.MaxQueued < 0 || concurrency.MaxQueued > maxQueuedTriggersLimit {
+		problems = append(problems, fmt.Sprintf("max_queued must be between 0 and %d", maxQueuedTriggersLimit))
+	}
+
+	if rateLimit := concurrency.RateLimit; rateLimit != nil {
+		if rateLimit.Mode != models.RateLimitThrottle && rateLimit.Mode != models.RateLimitDebounce {
+			problems = append(problems, fmt.Sprintf("rate_limit.mode %q must be %q or %q", rateLimit.Mode, models.RateLimitThrottle, models.RateLimitDebounce))
+		}
+		if rateLimit.WindowMs <= 0 || time.Duration(rateLimit.WindowMs)*time.Millisecond > maxRateLimitWindow {
+			problems = append(problems, fmt.Sprintf("rate_limit.window_ms must be between 1 and %d", maxRateLimitWindow.Milliseconds()))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,115 @@
+package workflow_manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func limitedWorkflow(concurrency *models.WorkflowConcurrency) *models.ServerWorkflow {
+	return &models.ServerWorkflow{
+		ID:         uuid.New(),
+		ServerID:   uuid.New(),
+		Definition: models.WorkflowDefinition{Concurrency: concurrency},
+	}
+}
+
+func TestRateLimitThrottlePerKey(t *testing.T) {
+	wm := &WorkflowManager{ctx: context.Background()}
+	workflow := limitedWorkflow(&models.WorkflowConcurrency{
+		RateLimit: &models.WorkflowRateLimit{Mode: models.RateLimitThrottle, WindowMs: 60000, Key: "${trigger_event.eos_id}"},
+	})
+	rateLimit := workflow.Definition.Concurrency.RateLimit
+
+	if !wm.passRateLimit(workflow, map[string]interface{}{"eos_id": "a"}, rateLimit) {
+		t.Fatalf("Expected the first trigger for a key to pass")
+	}
+	if wm.passRateLimit(workflow, map[string]interface{}{"eos_id": "a"}, rateLimit) {
+		t.Errorf("Expected a second trigger for the same key to be throttled")
+	}
+	if !wm.passRateLimit(workflow, map[string]interface{}{"eos_id": "b"}, rateLimit) {
+		t.Errorf("Expected a different key to have its own window")
+	}
+
+	if got := wm.triggerDrops[workflow.ID][triggerDropThrottled]; got != 1 {
+		t.Errorf("Expected 1 throttled trigger, got %d", got)
+	}
+}
+
+func TestRateLimitDebounce(t *testing.T) {
+	wm := &WorkflowManager{ctx: context.Background()}
+	workflow := limitedWorkflow(&models.WorkflowConcurrency{
+		MaxConcurrent: 1,
+		RateLimit:     &models.WorkflowRateLimit{Mode: models.RateLimitDebounce, WindowMs: 20},
+	})
+
+	// Occupy the only slot so the debounced trigger is queued instead of run
+	wm.limiterFor(workflow).running = 1
+
+	for i := 0; i < 3; i++ {
+		wm.dispatchWorkflow(workflow, map[string]interface{}{"n": i})
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		running, queued := wm.ConcurrencyStatus(workflow.ID)
+		if queued == 1 {
+			if running != 1 {
+				t.Errorf("Expected 1 running execution, got %d", running)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Debounced trigger was never admitted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	wm.concurrencyMutex.Lock()
+	defer wm.concurrencyMutex.Unlock()
+	if n := wm.limiters[workflow.ID].queue[0].triggerEvent["n"]; n != 2 {
+		t.Errorf("Expected the last trigger to be kept, got %v", n)
+	}
+	if got := wm.triggerDrops[workflow.ID][triggerDropDebounced]; got != 2 {
+		t.Errorf("Expected 2 debounced triggers, got %d", got)
+	}
+}
+
+func TestAdmitTriggerOverflow(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		wantQueued int
+		wantDrops  map[string]int64
+	}{
+		{name: "queue", policy: models.OverflowPolicyQueue, wantQueued: 2, wantDrops: map[string]int64{triggerDropQueueFull: 1}},
+		{name: "drop", policy: models.OverflowPolicyDrop, wantQueued: 0, wantDrops: map[string]int64{triggerDropDropped: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &WorkflowManager{ctx: context.Background(), serverExecutionLimit: 5}
+			workflow := limitedWorkflow(&models.WorkflowConcurrency{MaxConcurrent: 10, OverflowPolicy: tt.policy, MaxQueued: 2})
+
+			// The server limit applies even though the workflow has room
+			wm.limiterFor(workflow)
+			wm.serverRunning = map[uuid.UUID]int{workflow.ServerID: 5}
+
+			for i := 0; i < 3; i++ {
+				wm.admitTrigger(workflow, map[string]interface{}{})
+			}
+
+			if _, queued := wm.ConcurrencyStatus(workflow.ID); queued != tt.wantQueued {
+				t.Errorf("Expected %d queued triggers, got %d", tt.wantQueued, queued)
+			}
+			for reason, want := range tt.wantDrops {
+				if got := wm.triggerDrops[workflow.ID][reason]; got != want {
+					t.Errorf("Expected %d %s drops, got %d", want, reason, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,512 @@
+package workflow_manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+const (
+	// defaultLoopMaxIterations is used when a loop step doesn't set max_iterations
+	defaultLoopMaxIterations = 100
+	// loopIterationLimit is the hard upper bound for max_iterations
+	loopIterationLimit = 1000
+	// defaultParallelTimeout is used when a parallel step doesn't set timeout_ms
+	defaultParallelTimeout = 60 * time.Second
+)
+
+// Parallel step join modes
+const (
+	parallelModeAll = "all"
+	parallelModeAny = "any"
+)
+
+// parseInlineSteps converts an inline step list from a step config into workflow steps
+func parseInlineSteps(raw interface{}) ([]models.WorkflowStep, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	stepsBytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal steps: %w", err)
+	}
+
+	var steps []models.WorkflowStep
+	if err := json.Unmarshal(stepsBytes, &steps); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal steps: %w", err)
+	}
+
+	for i := range steps {
+		if steps[i].ID == "" {
+			steps[i].ID = generateId()
+		}
+	}
+
+	return steps, nil
+}
+
+// executeInlineSteps runs nested steps in order, logging each one with the
+// given extra output fields so nested results can be told apart in ClickHouse
+func (wm *WorkflowManager) executeInlineSteps(context *models.WorkflowExecutionContext, workflow *models.ServerWorkflow, steps []models.WorkflowStep, logFields map[string]interface{}) error {
+	for idx, inlineStep := range steps {
+		stepCopy := inlineStep
+		if !stepCopy.Enabled {
+			continue
+		}
+		if err := wm.stopErr(context); err != nil {
+			return err
+		}
+
+		stepStartTime := time.Now()
+
+		runningOutput := map[string]interface{}{"inline": true}
+		for k, v := range logFields {
+			runningOutput[k] = v
+		}
+		wm.logWorkflowStep(context, workflow, stepCopy.Name, strings.ToUpper(stepCopy.Type), uint32(idx+1), "RUNNING",
+			stepCopy.Config, runningOutput, nil, 0)
+
+		err := wm.executeStep(context, &stepCopy, workflow)
+		stepDuration := time.Since(stepStartTime)
+
+		if err != nil {
+			errorMsg := err.Error()
+			wm.logWorkflowStep(context, workflow, stepCopy.Name, strings.ToUpper(stepCopy.Type), uint32(idx+1), "FAILED",
+				stepCopy.Config, runningOutput, &errorMsg, uint32(stepDuration.Milliseconds()))
+			return fmt.Errorf("step %s failed: %w", stepCopy.Name, err)
+		}
+
+		stepOutput := map[string]interface{}{"status": "completed"}
+		for k, v := range runningOutput {
+			stepOutput[k] = v
+		}
+		if resultMap, ok := context.StepResults[stepCopy.ID].(map[string]interface{}); ok {
+			for k, v := range resultMap {
+				stepOutput[k] = v
+			}
+		}
+		wm.logWorkflowStep(context, workflow, stepCopy.Name, strings.ToUpper(stepCopy.Type), uint32(idx+1), "COMPLETED",
+			stepCopy.Config, stepOutput, nil, uint32(stepDuration.Milliseconds()))
+	}
+
+	return nil
+}
+
+// resolveLoopItems returns the items a loop step iterates over. items can be
+// an array, "players" for the players currently tracked on the server, or a
+// field path into the workflow data such as "trigger_event.players"
+func (wm *WorkflowManager) resolveLoopItems(context *models.WorkflowExecutionContext, step *models.WorkflowStep) ([]interface{}, error) {
+	raw, ok := step.Config["items"]
+	if !ok {
+		return nil, fmt.Errorf("missing items in loop step config")
+	}
+
+	if source, ok := raw.(string); ok {
+		if source == "players" {
+			return wm.getTrackedPlayers(context), nil
+		}
+		raw = wm.getFieldValue(source, wm.createDataContext(context))
+		if raw == nil {
+			return nil, nil
+		}
+	}
+
+	switch items := raw.(type) {
+	case []interface{}:
+		return items, nil
+	case []map[string]interface{}:
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = item
+		}
+		return result, nil
+	case []string:
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = item
+		}
+		return result, nil
+	case map[string]interface{}:
+		// Iterate maps as key/value pairs in key order
+		keys := make([]string, 0, len(items))
+		for key := range items {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		result := make([]interface{}, len(keys))
+		for i, key := range keys {
+			result[i] = map[string]interface{}{"key": key, "value": items[key]}
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("loop items must be an array, got %T", raw)
+	}
+}
+
+// getTrackedPlayers returns the connected players of the workflow's server as maps
+func (wm *WorkflowManager) getTrackedPlayers(context *models.WorkflowExecutionContext) []interface{} {
+	if wm.playerTrackerManager == nil {
+		return nil
+	}
+
+	tracker, ok := wm.playerTrackerManager.GetTracker(context.ServerID)
+	if !ok {
+		return nil
+	}
+
+	players := tracker.GetAllPlayers()
+	eosIDs := make([]string, 0, len(players))
+	for eosID, player := range players {
+		if player.IsConnected {
+			eosIDs = append(eosIDs, eosID)
+		}
+	}
+	sort.Strings(eosIDs)
+
+	result := make([]interface{}, 0, len(eosIDs))
+	for _, eosID := range eosIDs {
+		playerBytes, err := json.Marshal(players[eosID])
+		if err != nil {
+			continue
+		}
+		var player map[string]interface{}
+		if err := json.Unmarshal(playerBytes, &player); err != nil {
+			continue
+		}
+		result = append(result, player)
+	}
+
+	return result
+}
+
+// executeLoopStep runs the nested steps once for each item, binding the
+// current item and index to workflow variables
+func (wm *WorkflowManager) executeLoopStep(context *models.WorkflowExecutionContext, step *models.WorkflowStep, workflow *models.ServerWorkflow) error {
+	steps, err := parseInlineSteps(step.Config["steps"])
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("loop step requires at least one nested step")
+	}
+
+	items, err := wm.resolveLoopItems(context, step)
+	if err != nil {
+		return err
+	}
+
+	itemVariable, _ := step.Config["item_variable"].(string)
+	if itemVariable == "" {
+		itemVariable = "item"
+	}
+	indexVariable, _ := step.Config["index_variable"].(string)
+	if indexVariable == "" {
+		indexVariable = "index"
+	}
+
+	maxIterations := defaultLoopMaxIterations
+	if value, ok := step.Config["max_iterations"].(float64); ok && value > 0 {
+		maxIterations = int(value)
+	}
+	if maxIterations > loopIterationLimit {
+		maxIterations = loopIterationLimit
+	}
+
+	continueOnError, _ := step.Config["continue_on_error"].(bool)
+
+	truncated := false
+	if len(items) > maxIterations {
+		log.Warn().
+			Str("execution_id", context.ExecutionID.String()).
+			Str("step_id", step.ID).
+			Int("items", len(items)).
+			Int("max_iterations", maxIterations).
+			Msg("Loop step has more items than max_iterations, extra items are skipped")
+		items = items[:maxIterations]
+		truncated = true
+	}
+
+	// Restore any variables the loop shadows once it finishes
+	previousItem, hadItem := context.Variables[itemVariable]
+	previousIndex, hadIndex := context.Variables[indexVariable]
+	defer func() {
+		if hadItem {
+			context.Variables[itemVariable] = previousItem
+		} else {
+			delete(context.Variables, itemVariable)
+		}
+		if hadIndex {
+			context.Variables[indexVariable] = previousIndex
+		} else {
+			delete(context.Variables, indexVariable)
+		}
+	}()
+
+	completed, failed := 0, 0
+	iterations := make([]map[string]interface{}, 0, len(items))
+
+	for index, item := range items {
+		if wm.ctx.Err() != nil {
+			return wm.ctx.Err()
+		}
+
+		context.Variables[itemVariable] = item
+		context.Variables[indexVariable] = index
+
+		iterationStart := time.Now()
+		logFields := map[string]interface{}{"loop_step": step.ID, "iteration": index}
+		err := wm.executeInlineSteps(context, workflow, steps, logFields)
+		iterationDuration := time.Since(iterationStart)
+
+		iteration := map[string]interface{}{"iteration": index, "status": "completed"}
+		iterationName := fmt.Sprintf("%s [iteration %d]", step.Name, index)
+
+		if err != nil {
+			failed++
+			errorMsg := err.Error()
+			iteration["status"] = "failed"
+			iteration["error"] = errorMsg
+			iterations = append(iterations, iteration)
+
+			wm.logWorkflowStep(context, workflow, iterationName, "LOOP_ITERATION", uint32(index+1), "FAILED",
+				map[string]interface{}{itemVariable: item}, iteration, &errorMsg, uint32(iterationDuration.Milliseconds()))
+
+			if !continueOnError {
+				context.StepResults[step.ID] = loopResult(len(items), completed, failed, truncated, iterations)
+				return fmt.Errorf("loop iteration %d failed: %w", index, err)
+			}
+			continue
+		}
+
+		completed++
+		iterations = append(iterations, iteration)
+		wm.logWorkflowStep(context, workflow, iterationName, "LOOP_ITERATION", uint32(index+1), "COMPLETED",
+			map[string]interface{}{itemVariable: item}, iteration, nil, uint32(iterationDuration.Milliseconds()))
+	}
+
+	context.StepResults[step.ID] = loopResult(len(items), completed, failed, truncated, iterations)
+
+	log.Debug().
+		Str("execution_id", context.ExecutionID.String()).
+		Str("step_id", step.ID).
+		Int("iterations", len(items)).
+		Int("failed", failed).
+		Msg("Loop step completed")
+
+	return nil
+}
+
+// loopResult builds the step result stored for a loop step
+func loopResult(total, completed, failed int, truncated bool, iterations []map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"iterations": total,
+		"completed":  completed,
+		"failed":     failed,
+		"truncated":  truncated,
+		"results":    iterations,
+	}
+}
+
+// parallelBranch is a named list of steps run concurrently with other branches
+type parallelBranch struct {
+	Name  string                `json:"name"`
+	Steps []models.WorkflowStep `json:"steps"`
+}
+
+// branchResult is the outcome of a single parallel branch
+type branchResult struct {
+	index    int
+	context  *models.WorkflowExecutionContext
+	err      error
+	duration time.Duration
+}
+
+// cloneExecutionContext copies the mutable parts of an execution context so a
+// parallel branch can run without sharing maps with other branches
+func cloneExecutionContext(context *models.WorkflowExecutionContext) *models.WorkflowExecutionContext {
+	clone := *context
+	clone.Variables = make(map[string]interface{}, len(context.Variables))
+	for k, v := range context.Variables {
+		clone.Variables[k] = v
+	}
+	clone.StepResults = make(map[string]interface{}, len(context.StepResults))
+	for k, v := range context.StepResults {
+		clone.StepResults[k] = v
+	}
+	clone.Metadata = make(map[string]interface{}, len(context.Metadata))
+	for k, v := range context.Metadata {
+		clone.Metadata[k] = v
+	}
+	// Condition steps write to skipped_steps, so each branch needs its own copy
+	if skippedSteps, ok := context.Metadata["skipped_steps"].(map[string]bool); ok {
+		skippedCopy := make(map[string]bool, len(skippedSteps))
+		for k, v := range skippedSteps {
+			skippedCopy[k] = v
+		}
+		clone.Metadata["skipped_steps"] = skippedCopy
+	}
+	return &clone
+}
+
+// executeParallelStep runs branches concurrently. In "all" mode every branch
+// must succeed; in "any" mode the step completes as soon as one branch
+// succeeds. Branches run on copies of the execution context and their
+// variables and step results are merged back in branch order.
+func (wm *WorkflowManager) executeParallelStep(context *models.WorkflowExecutionContext, step *models.WorkflowStep, workflow *models.ServerWorkflow) error {
+	branchesBytes, err := json.Marshal(step.Config["branches"])
+	if err != nil {
+		return fmt.Errorf("failed to marshal branches: %w", err)
+	}
+
+	var branches []parallelBranch
+	if err := json.Unmarshal(branchesBytes, &branches); err != nil {
+		return fmt.Errorf("failed to unmarshal branches: %w", err)
+	}
+	if len(branches) == 0 {
+		return fmt.Errorf("parallel step requires at least one branch")
+	}
+
+	mode, _ := step.Config["mode"].(string)
+	if mode == "" {
+		mode = parallelModeAll
+	}
+	if mode != parallelModeAll && mode != parallelModeAny {
+		return fmt.Errorf("unsupported parallel mode: %s", mode)
+	}
+
+	timeout := defaultParallelTimeout
+	if value, ok := step.Config["timeout_ms"].(float64); ok && value > 0 {
+		timeout = time.Duration(value) * time.Millisecond
+	}
+
+	for i := range branches {
+		if branches[i].Name == "" {
+			branches[i].Name = fmt.Sprintf("branch_%d", i+1)
+		}
+		for j := range branches[i].Steps {
+			if branches[i].Steps[j].ID == "" {
+				branches[i].Steps[j].ID = generateId()
+			}
+		}
+	}
+
+	// Branches still running when the step returns, after the first success in
+	// "any" mode, a timeout or a shutdown, are cancelled before their next step
+	cancels := make([]func(), 0, len(branches))
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	results := make(chan branchResult, len(branches))
+	for i, branch := range branches {
+		branchContext := cloneExecutionContext(context)
+		cancels = append(cancels, wm.registerBranch(context, branchContext))
+		go func(index int, branch parallelBranch, branchContext *models.WorkflowExecutionContext) {
+			defer wm.unregisterBranch(branchContext)
+			start := time.Now()
+			logFields := map[string]interface{}{"parallel_step": step.ID, "branch": branch.Name}
+			err := wm.executeInlineSteps(branchContext, workflow, branch.Steps, logFields)
+			results <- branchResult{index: index, context: branchContext, err: err, duration: time.Since(start)}
+		}(i, branch, branchContext)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	finished := make([]*branchResult, len(branches))
+	branchOutputs := make(map[string]interface{}, len(branches))
+	succeeded, failed := 0, 0
+	var firstErr error
+	timedOut := false
+
+collect:
+	for received := 0; received < len(branches); received++ {
+		select {
+		case result := <-results:
+			finished[result.index] = &result
+			branch := branches[result.index]
+			output := map[string]interface{}{"branch": branch.Name, "status": "completed"}
+
+			if result.err != nil {
+				failed++
+				errorMsg := result.err.Error()
+				output["status"] = "failed"
+				output["error"] = errorMsg
+				if firstErr == nil {
+					firstErr = fmt.Errorf("branch %s failed: %w", branch.Name, result.err)
+				}
+				wm.logWorkflowStep(context, workflow, fmt.Sprintf("%s [%s]", step.Name, branch.Name), "PARALLEL_BRANCH",
+					uint32(result.index+1), "FAILED", map[string]interface{}{"branch": branch.Name}, output, &errorMsg,
+					uint32(result.duration.Milliseconds()))
+			} else {
+				succeeded++
+				wm.logWorkflowStep(context, workflow, fmt.Sprintf("%s [%s]", step.Name, branch.Name), "PARALLEL_BRANCH",
+					uint32(result.index+1), "COMPLETED", map[string]interface{}{"branch": branch.Name}, output, nil,
+					uint32(result.duration.Milliseconds()))
+			}
+			branchOutputs[branch.Name] = output
+
+			if mode == parallelModeAny && succeeded > 0 {
+				break collect
+			}
+		case <-timer.C:
+			timedOut = true
+			break collect
+		case <-wm.executionCtx(context).Done():
+			return wm.stopErr(context)
+		}
+	}
+
+	// Merge the state of finished, successful branches back in branch order
+	for _, result := range finished {
+		if result == nil || result.err != nil {
+			continue
+		}
+		for k, v := range result.context.Variables {
+			context.Variables[k] = v
+		}
+		for k, v := range result.context.StepResults {
+			context.StepResults[k] = v
+		}
+	}
+
+	for _, branch := range branches {
+		if _, ok := branchOutputs[branch.Name]; !ok {
+			status := "cancelled"
+			if timedOut {
+				status = "timed_out"
+			}
+			branchOutputs[branch.Name] = map[string]interface{}{"branch": branch.Name, "status": status}
+		}
+	}
+
+	context.StepResults[step.ID] = map[string]interface{}{
+		"mode":      mode,
+		"branches":  branchOutputs,
+		"succeeded": succeeded,
+		"failed":    failed,
+		"timed_out": timedOut,
+	}
+
+	switch {
+	case mode == parallelModeAny && succeeded > 0:
+		return nil
+	case timedOut:
+		return fmt.Errorf("parallel step timed out after %s", timeout)
+	case mode == parallelModeAny:
+		return fmt.Errorf("all parallel branches failed: %w", firstErr)
+	case firstErr != nil:
+		return firstErr
+	}
+
+	return nil
+}
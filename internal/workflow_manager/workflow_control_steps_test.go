@@ -0,0 +1,155 @@
+package workflow_manager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func newTestExecutionContext() *models.WorkflowExecutionContext {
+	return &models.WorkflowExecutionContext{
+		ExecutionID: uuid.New(),
+		Variables:   map[string]interface{}{"names": []interface{}{"a", "b", "c"}},
+		StepResults: make(map[string]interface{}),
+		Metadata:    make(map[string]interface{}),
+	}
+}
+
+func incrementStep(variable string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":    "increment " + variable,
+		"type":    models.StepTypeVariable,
+		"enabled": true,
+		"config":  map[string]interface{}{"operation": "increment", "variable_name": variable},
+	}
+}
+
+func TestExecuteLoopStep(t *testing.T) {
+	wm := &WorkflowManager{ctx: context.Background()}
+	execCtx := newTestExecutionContext()
+
+	step := &models.WorkflowStep{
+		ID:   "loop",
+		Name: "loop",
+		Type: models.StepTypeLoop,
+		Config: map[string]interface{}{
+			"items":          "names",
+			"max_iterations": float64(2),
+			"steps":          []interface{}{incrementStep("count")},
+		},
+	}
+
+	if err := wm.executeStep(execCtx, step, &models.ServerWorkflow{}); err != nil {
+		t.Fatalf("Loop step failed: %v", err)
+	}
+
+	if execCtx.Variables["count"] != 2.0 {
+		t.Errorf("Expected 2 iterations due to max_iterations, got count=%v", execCtx.Variables["count"])
+	}
+	if _, ok := execCtx.Variables["item"]; ok {
+		t.Errorf("Expected loop item variable to be removed after the loop")
+	}
+
+	result := execCtx.StepResults["loop"].(map[string]interface{})
+	if result["truncated"] != true {
+		t.Errorf("Expected loop result to be marked truncated")
+	}
+}
+
+func TestExecuteParallelStep(t *testing.T) {
+	wm := &WorkflowManager{ctx: context.Background()}
+	execCtx := newTestExecutionContext()
+
+	step := &models.WorkflowStep{
+		ID:   "parallel",
+		Name: "parallel",
+		Type: models.StepTypeParallel,
+		Config: map[string]interface{}{
+			"branches": []interface{}{
+				map[string]interface{}{"name": "first", "steps": []interface{}{incrementStep("a")}},
+				map[string]interface{}{"name": "second", "steps": []interface{}{incrementStep("b")}},
+			},
+		},
+	}
+
+	if err := wm.executeStep(execCtx, step, &models.ServerWorkflow{}); err != nil {
+		t.Fatalf("Parallel step failed: %v", err)
+	}
+
+	if execCtx.Variables["a"] != 1.0 || execCtx.Variables["b"] != 1.0 {
+		t.Errorf("Expected both branch variables to be merged, got a=%v b=%v", execCtx.Variables["a"], execCtx.Variables["b"])
+	}
+
+	// A failing branch fails the step in "all" mode but not in "any" mode
+	step.Config["branches"] = []interface{}{
+		map[string]interface{}{"name": "ok", "steps": []interface{}{incrementStep("c")}},
+		map[string]interface{}{"name": "bad", "steps": []interface{}{map[string]interface{}{
+			"name": "bad", "type": "unknown", "enabled": true,
+		}}},
+	}
+	if err := wm.executeStep(execCtx, step, &models.ServerWorkflow{}); err == nil {
+		t.Errorf("Expected parallel step in all mode to fail")
+	}
+
+	step.Config["mode"] = "any"
+	if err := wm.executeStep(execCtx, step, &models.ServerWorkflow{}); err != nil {
+		t.Errorf("Expected parallel step in any mode to succeed, got %v", err)
+	}
+}
+
+func TestExecuteParallelStepCancelsLosingBranches(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	wm := &WorkflowManager{ctx: context.Background()}
+	execCtx := newTestExecutionContext()
+
+	step := &models.WorkflowStep{
+		ID:   "parallel",
+		Name: "parallel",
+		Type: models.StepTypeParallel,
+		Config: map[string]interface{}{
+			"mode": "any",
+			"branches": []interface{}{
+				map[string]interface{}{"name": "fast", "steps": []interface{}{incrementStep("a")}},
+				map[string]interface{}{"name": "slow", "steps": []interface{}{
+					map[string]interface{}{
+						"name": "wait", "type": models.StepTypeDelay, "enabled": true,
+						"config": map[string]interface{}{"delay_ms": 200.0},
+					},
+					map[string]interface{}{
+						"name": "request", "type": models.StepTypeAction, "enabled": true,
+						"config": map[string]interface{}{"action_type": models.ActionTypeHTTPRequest, "url": server.URL},
+					},
+				}},
+			},
+		},
+	}
+
+	start := time.Now()
+	if err := wm.executeStep(execCtx, step, &models.ServerWorkflow{}); err != nil {
+		t.Fatalf("Parallel step failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("Expected parallel step in any mode to return after the first branch, took %v", elapsed)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+	if count := requests.Load(); count != 0 {
+		t.Errorf("Expected the cancelled branch not to run its later steps, got %d requests", count)
+	}
+	wm.executionMutex.RLock()
+	defer wm.executionMutex.RUnlock()
+	if len(wm.branches) != 0 {
+		t.Errorf("Expected finished branches to be unregistered, got %d", len(wm.branches))
+	}
+}
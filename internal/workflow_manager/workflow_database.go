@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/db"
 	"go.codycody31.dev/squad-aegis/internal/models"
 )
 
@@ -19,37 +21,58 @@ func NewWorkflowDatabase(db *sql.DB) *WorkflowDatabase {
 	return &WorkflowDatabase{db: db}
 }
 
-// CreateWorkflow creates a new workflow in the database
+// CreateWorkflow creates a new workflow in the database along with its first revision
 func (wd *WorkflowDatabase) CreateWorkflow(workflow *models.ServerWorkflow) error {
+	tx, err := wd.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertWorkflow(tx, workflow); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertWorkflow inserts a new workflow and its first revision in tx
+func insertWorkflow(tx *sql.Tx, workflow *models.ServerWorkflow) error {
 	definitionJSON, err := json.Marshal(workflow.Definition)
 	if err != nil {
 		return fmt.Errorf("failed to marshal workflow definition: %w", err)
 	}
 
+	workflow.Revision = 1
+
 	query := `
-		INSERT INTO server_workflows (id, server_id, name, description, enabled, definition, created_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO server_workflows (id, server_id, name, description, enabled, definition, revision, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
-	_, err = wd.db.Exec(query,
+	_, err = tx.Exec(query,
 		workflow.ID,
 		workflow.ServerID,
 		workflow.Name,
 		workflow.Description,
 		workflow.Enabled,
 		definitionJSON,
+		workflow.Revision,
 		workflow.CreatedBy,
 		workflow.CreatedAt,
 		workflow.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	return insertWorkflowRevision(tx, workflow, definitionJSON, workflow.CreatedBy, nil, nil)
 }
 
 // GetWorkflow retrieves a workflow by ID
 func (wd *WorkflowDatabase) GetWorkflow(workflowID uuid.UUID) (*models.ServerWorkflow, error) {
 	query := `
-		SELECT id, server_id, name, description, enabled, definition, created_by, created_at, updated_at
+		SELECT id, server_id, name, description, enabled, definition, revision, created_by, created_at, updated_at
 		FROM server_workflows
 		WHERE id = $1
 	`
@@ -65,6 +88,7 @@ func (wd *WorkflowDatabase) GetWorkflow(workflowID uuid.UUID) (*models.ServerWor
 		&description,
 		&workflow.Enabled,
 		&definitionJSON,
+		&workflow.Revision,
 		&workflow.CreatedBy,
 		&workflow.CreatedAt,
 		&workflow.UpdatedAt,
@@ -93,7 +117,7 @@ func (wd *WorkflowDatabase) GetWorkflow(workflowID uuid.UUID) (*models.ServerWor
 // GetWorkflowsByServerID retrieves all workflows for a server
 func (wd *WorkflowDatabase) GetWorkflowsByServerID(serverID uuid.UUID) ([]models.ServerWorkflow, error) {
 	query := `
-		SELECT id, server_id, name, description, enabled, definition, created_by, created_at, updated_at
+		SELECT id, server_id, name, description, enabled, definition, revision, created_by, created_at, updated_at
 		FROM server_workflows
 		WHERE server_id = $1
 		ORDER BY created_at DESC
@@ -119,6 +143,7 @@ func (wd *WorkflowDatabase) GetWorkflowsByServerID(serverID uuid.UUID) ([]models
 			&description,
 			&workflow.Enabled,
 			&definitionJSON,
+			&workflow.Revision,
 			&workflow.CreatedBy,
 			&workflow.CreatedAt,
 			&workflow.UpdatedAt,
@@ -147,29 +172,48 @@ func (wd *WorkflowDatabase) GetWorkflowsByServerID(serverID uuid.UUID) ([]models
 	return workflows, nil
 }
 
-// UpdateWorkflow updates a workflow in the database
-func (wd *WorkflowDatabase) UpdateWorkflow(workflow *models.ServerWorkflow) error {
+// UpdateWorkflow updates a workflow in the database and records the result as a new revision
+func (wd *WorkflowDatabase) UpdateWorkflow(workflow *models.ServerWorkflow, updatedBy uuid.UUID, changeSummary *string) error {
+	return wd.saveWorkflow(workflow, updatedBy, changeSummary, nil)
+}
+
+// saveWorkflow updates a workflow and appends a revision in one transaction
+func (wd *WorkflowDatabase) saveWorkflow(workflow *models.ServerWorkflow, updatedBy uuid.UUID, changeSummary *string, restoredFrom *int) error {
 	definitionJSON, err := json.Marshal(workflow.Definition)
 	if err != nil {
 		return fmt.Errorf("failed to marshal workflow definition: %w", err)
 	}
 
+	tx, err := wd.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE server_workflows
-		SET name = $1, description = $2, enabled = $3, definition = $4, updated_at = $5
+		SET name = $1, description = $2, enabled = $3, definition = $4, updated_at = $5, revision = revision + 1
 		WHERE id = $6
+		RETURNING revision
 	`
 
-	_, err = wd.db.Exec(query,
+	err = tx.QueryRow(query,
 		workflow.Name,
 		workflow.Description,
 		workflow.Enabled,
 		definitionJSON,
 		workflow.UpdatedAt,
 		workflow.ID,
-	)
+	).Scan(&workflow.Revision)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := insertWorkflowRevision(tx, workflow, definitionJSON, updatedBy, changeSummary, restoredFrom); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // DeleteWorkflow deletes a workflow from the database
@@ -182,6 +226,11 @@ func (wd *WorkflowDatabase) DeleteWorkflow(workflowID uuid.UUID) error {
 
 // CreateWorkflowVariable creates a new workflow variable
 func (wd *WorkflowDatabase) CreateWorkflowVariable(variable *models.ServerWorkflowVariable) error {
+	return insertWorkflowVariable(wd.db, variable)
+}
+
+// insertWorkflowVariable inserts a workflow variable with database, which may be a transaction
+func insertWorkflowVariable(database db.Executor, variable *models.ServerWorkflowVariable) error {
 	valueJSON, err := json.Marshal(variable.Value)
 	if err != nil {
 		return fmt.Errorf("failed to marshal variable value: %w", err)
@@ -192,7 +241,7 @@ func (wd *WorkflowDatabase) CreateWorkflowVariable(variable *models.ServerWorkfl
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	_, err = wd.db.Exec(query,
+	_, err = database.Exec(query,
 		variable.ID,
 		variable.WorkflowID,
 		variable.Name,
@@ -318,7 +367,7 @@ func (wd *WorkflowDatabase) loadWorkflowVariables(workflow *models.ServerWorkflo
 // GetExecutionsByWorkflowID retrieves workflow executions for a workflow
 func (wd *WorkflowDatabase) GetExecutionsByWorkflowID(workflowID uuid.UUID, limit, offset int) ([]models.ServerWorkflowExecution, error) {
 	query := `
-		SELECT id, workflow_id, execution_id, status, trigger_data, started_at, completed_at, error_message
+		SELECT id, workflow_id, execution_id, status, trigger_data, started_at, completed_at, error_message, revision, retry_of
 		FROM server_workflow_executions
 		WHERE workflow_id = $1
 		ORDER BY started_at DESC
@@ -337,6 +386,8 @@ func (wd *WorkflowDatabase) GetExecutionsByWorkflowID(workflowID uuid.UUID, limi
 		var execution models.ServerWorkflowExecution
 		var completedAt sql.NullTime
 		var errorMessage sql.NullString
+		var revision sql.NullInt64
+		var retryOf uuid.NullUUID
 		var triggerDataJSON []byte
 
 		err := rows.Scan(
@@ -348,6 +399,8 @@ func (wd *WorkflowDatabase) GetExecutionsByWorkflowID(workflowID uuid.UUID, limi
 			&execution.StartedAt,
 			&completedAt,
 			&errorMessage,
+			&revision,
+			&retryOf,
 		)
 
 		if err != nil {
@@ -368,6 +421,15 @@ func (wd *WorkflowDatabase) GetExecutionsByWorkflowID(workflowID uuid.UUID, limi
 			execution.ErrorMessage = &errorMessage.String
 		}
 
+		if revision.Valid {
+			rev := int(revision.Int64)
+			execution.Revision = &rev
+		}
+
+		if retryOf.Valid {
+			execution.RetryOf = &retryOf.UUID
+		}
+
 		executions = append(executions, execution)
 	}
 
@@ -377,7 +439,7 @@ func (wd *WorkflowDatabase) GetExecutionsByWorkflowID(workflowID uuid.UUID, limi
 // GetExecutionsByServerID retrieves workflow executions for a server
 func (wd *WorkflowDatabase) GetExecutionsByServerID(serverID uuid.UUID, limit, offset int) ([]models.ServerWorkflowExecution, error) {
 	query := `
-		SELECT e.id, e.workflow_id, e.execution_id, e.status, e.trigger_data, e.started_at, e.completed_at, e.error_message
+		SELECT e.id, e.workflow_id, e.execution_id, e.status, e.trigger_data, e.started_at, e.completed_at, e.error_message, e.revision, e.retry_of
 		FROM server_workflow_executions e
 		JOIN server_workflows w ON e.workflow_id = w.id
 		WHERE w.server_id = $1
@@ -397,6 +459,8 @@ func (wd *WorkflowDatabase) GetExecutionsByServerID(serverID uuid.UUID, limit, o
 		var execution models.ServerWorkflowExecution
 		var completedAt sql.NullTime
 		var errorMessage sql.NullString
+		var revision sql.NullInt64
+		var retryOf uuid.NullUUID
 		var triggerDataJSON []byte
 
 		err := rows.Scan(
@@ -408,6 +472,8 @@ func (wd *WorkflowDatabase) GetExecutionsByServerID(serverID uuid.UUID, limit, o
 			&execution.StartedAt,
 			&completedAt,
 			&errorMessage,
+			&revision,
+			&retryOf,
 		)
 
 		if err != nil {
@@ -428,6 +494,15 @@ func (wd *WorkflowDatabase) GetExecutionsByServerID(serverID uuid.UUID, limit, o
 			execution.ErrorMessage = &errorMessage.String
 		}
 
+		if revision.Valid {
+			rev := int(revision.Int64)
+			execution.Revision = &rev
+		}
+
+		if retryOf.Valid {
+			execution.RetryOf = &retryOf.UUID
+		}
+
 		executions = append(executions, execution)
 	}
 
@@ -437,8 +512,8 @@ func (wd *WorkflowDatabase) GetExecutionsByServerID(serverID uuid.UUID, limit, o
 // CreateWorkflowExecution creates a new workflow execution record
 func (wd *WorkflowDatabase) CreateWorkflowExecution(execution *models.ServerWorkflowExecution) error {
 	query := `
-		INSERT INTO server_workflow_executions (id, workflow_id, execution_id, status, trigger_data, started_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO server_workflow_executions (id, workflow_id, execution_id, status, trigger_data, started_at, revision, retry_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	triggerDataJSON, err := json.Marshal(execution.TriggerData)
@@ -453,6 +528,8 @@ func (wd *WorkflowDatabase) CreateWorkflowExecution(execution *models.ServerWork
 		execution.Status,
 		triggerDataJSON,
 		execution.StartedAt,
+		execution.Revision,
+		execution.RetryOf,
 	)
 
 	return err
@@ -476,20 +553,76 @@ func (wd *WorkflowDatabase) UpdateWorkflowExecution(execution *models.ServerWork
 	return err
 }
 
+// FinishRunningExecution sets the final status of an execution that is still
+// RUNNING. It reports false if the execution had already finished.
+func (wd *WorkflowDatabase) FinishRunningExecution(executionID uuid.UUID, status, errorMessage string) (bool, error) {
+	result, err := wd.db.Exec(`
+		UPDATE server_workflow_executions
+		SET status = $1, completed_at = NOW(), error_message = $2
+		WHERE execution_id = $3 AND status = 'RUNNING'
+	`, status, errorMessage, executionID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
 // GetWorkflowExecution retrieves a workflow execution by execution ID
 func (wd *WorkflowDatabase) GetWorkflowExecution(executionID uuid.UUID) (*models.ServerWorkflowExecution, error) {
 	query := `
-		SELECT id, workflow_id, execution_id, status, trigger_data, started_at, completed_at, error_message
+		SELECT id, workflow_id, execution_id, status, trigger_data, started_at, completed_at, error_message, revision, retry_of, checkpoint, checkpoint_at
 		FROM server_workflow_executions
 		WHERE execution_id = $1
 	`
 
+	return scanWorkflowExecutionWithCheckpoint(wd.db.QueryRow(query, executionID))
+}
+
+// GetRunningExecutions retrieves every execution still marked as RUNNING, oldest first
+func (wd *WorkflowDatabase) GetRunningExecutions() ([]models.ServerWorkflowExecution, error) {
+	query := `
+		SELECT id, workflow_id, execution_id, status, trigger_data, started_at, completed_at, error_message, revision, retry_of, checkpoint, checkpoint_at
+		FROM server_workflow_executions
+		WHERE status = 'RUNNING'
+		ORDER BY started_at ASC
+	`
+
+	rows, err := wd.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []models.ServerWorkflowExecution
+
+	for rows.Next() {
+		execution, err := scanWorkflowExecutionWithCheckpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, *execution)
+	}
+
+	return executions, rows.Err()
+}
+
+// scanWorkflowExecutionWithCheckpoint scans an execution row that includes its checkpoint columns
+func scanWorkflowExecutionWithCheckpoint(row interface{ Scan(dest ...any) error }) (*models.ServerWorkflowExecution, error) {
 	var execution models.ServerWorkflowExecution
 	var completedAt sql.NullTime
 	var errorMessage sql.NullString
+	var revision sql.NullInt64
+	var retryOf uuid.NullUUID
 	var triggerDataJSON []byte
+	var checkpointJSON []byte
+	var checkpointAt sql.NullTime
 
-	err := wd.db.QueryRow(query, executionID).Scan(
+	err := row.Scan(
 		&execution.ID,
 		&execution.WorkflowID,
 		&execution.ExecutionID,
@@ -498,6 +631,10 @@ func (wd *WorkflowDatabase) GetWorkflowExecution(executionID uuid.UUID) (*models
 		&execution.StartedAt,
 		&completedAt,
 		&errorMessage,
+		&revision,
+		&retryOf,
+		&checkpointJSON,
+		&checkpointAt,
 	)
 
 	if err != nil {
@@ -518,9 +655,44 @@ func (wd *WorkflowDatabase) GetWorkflowExecution(executionID uuid.UUID) (*models
 		execution.ErrorMessage = &errorMessage.String
 	}
 
+	if revision.Valid {
+		rev := int(revision.Int64)
+		execution.Revision = &rev
+	}
+
+	if retryOf.Valid {
+		execution.RetryOf = &retryOf.UUID
+	}
+
+	if len(checkpointJSON) > 0 {
+		if err := json.Unmarshal(checkpointJSON, &execution.Checkpoint); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+		}
+	}
+
+	if checkpointAt.Valid {
+		execution.CheckpointAt = &checkpointAt.Time
+	}
+
 	return &execution, nil
 }
 
+// SaveExecutionCheckpoint stores the resume state of a running execution
+func (wd *WorkflowDatabase) SaveExecutionCheckpoint(executionID uuid.UUID, checkpoint *models.WorkflowExecutionCheckpoint) error {
+	checkpointJSON, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	_, err = wd.db.Exec(`
+		UPDATE server_workflow_executions
+		SET checkpoint = $1, checkpoint_at = NOW()
+		WHERE execution_id = $2 AND status = 'RUNNING'
+	`, checkpointJSON, executionID)
+
+	return err
+}
+
 // KV Store Operations
 
 // GetKVValue retrieves a value from the workflow KV store
@@ -547,6 +719,11 @@ func (wd *WorkflowDatabase) GetKVValue(workflowID uuid.UUID, key string) (interf
 
 // SetKVValue sets a value in the workflow KV store (creates or updates)
 func (wd *WorkflowDatabase) SetKVValue(workflowID uuid.UUID, key string, value interface{}) error {
+	return setWorkflowKVValue(wd.db, workflowID, key, value)
+}
+
+// setWorkflowKVValue upserts a KV value with database, which may be a transaction
+func setWorkflowKVValue(database db.Executor, workflowID uuid.UUID, key string, value interface{}) error {
 	valueJSON, err := json.Marshal(value)
 	if err != nil {
 		return fmt.Errorf("failed to marshal KV value: %w", err)
@@ -559,7 +736,7 @@ func (wd *WorkflowDatabase) SetKVValue(workflowID uuid.UUID, key string, value i
 		DO UPDATE SET value = $3, updated_at = NOW()
 	`
 
-	_, err = wd.db.Exec(query, workflowID, key, valueJSON)
+	_, err = database.Exec(query, workflowID, key, valueJSON)
 	return err
 }
 
@@ -667,10 +844,11 @@ func (wd *WorkflowDatabase) CountKVPairs(workflowID uuid.UUID) (int, error) {
 
 // WorkflowExecutionStats holds computed statistics for a workflow
 type WorkflowExecutionStats struct {
-	TotalExecutions int64   `json:"total_executions"`
-	SuccessRate     float64 `json:"success_rate"`
-	AvgDurationMs   *int64  `json:"avg_duration_ms"`
-	RunningCount    int64   `json:"running_count"`
+	TotalExecutions int64            `json:"total_executions"`
+	SuccessRate     float64          `json:"success_rate"`
+	AvgDurationMs   *int64           `json:"avg_duration_ms"`
+	RunningCount    int64            `json:"running_count"`
+	DroppedTriggers map[string]int64 `json:"dropped_triggers"` // Triggers that didn't run, by reason
 }
 
 // GetWorkflowExecutionStats computes aggregate statistics for a workflow
@@ -711,5 +889,94 @@ func (wd *WorkflowDatabase) GetWorkflowExecutionStats(workflowID uuid.UUID) (*Wo
 		stats.AvgDurationMs = &ms
 	}
 
+	stats.DroppedTriggers, err = wd.GetTriggerDrops(workflowID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &stats, nil
 }
+
+// GetTriggerDrops returns how many triggers of a workflow were throttled,
+// debounced or dropped, by reason
+func (wd *WorkflowDatabase) GetTriggerDrops(workflowID uuid.UUID) (map[string]int64, error) {
+	rows, err := wd.db.Query(`
+		SELECT reason, count
+		FROM server_workflow_trigger_drops
+		WHERE workflow_id = $1
+	`, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drops := make(map[string]int64)
+	for rows.Next() {
+		var reason string
+		var count int64
+		if err := rows.Scan(&reason, &count); err != nil {
+			return nil, err
+		}
+		drops[reason] = count
+	}
+
+	return drops, rows.Err()
+}
+
+// AddTriggerDrops adds to the dropped trigger counts of a workflow
+func (wd *WorkflowDatabase) AddTriggerDrops(workflowID uuid.UUID, drops map[string]int64) error {
+	for reason, count := range drops {
+		_, err := wd.db.Exec(`
+			INSERT INTO server_workflow_trigger_drops (workflow_id, reason, count, last_dropped_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (workflow_id, reason)
+			DO UPDATE SET count = server_workflow_trigger_drops.count + EXCLUDED.count, last_dropped_at = EXCLUDED.last_dropped_at
+		`, workflowID, reason, count)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scheduled Trigger Operations
+
+// GetTriggerLastRuns returns the last run time of each scheduled trigger of a workflow
+func (wd *WorkflowDatabase) GetTriggerLastRuns(workflowID uuid.UUID) (map[string]time.Time, error) {
+	query := `
+		SELECT trigger_id, last_run_at
+		FROM server_workflow_trigger_runs
+		WHERE workflow_id = $1
+	`
+
+	rows, err := wd.db.Query(query, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lastRuns := make(map[string]time.Time)
+	for rows.Next() {
+		var triggerID string
+		var lastRunAt time.Time
+		if err := rows.Scan(&triggerID, &lastRunAt); err != nil {
+			return nil, err
+		}
+		lastRuns[triggerID] = lastRunAt
+	}
+
+	return lastRuns, rows.Err()
+}
+
+// SetTriggerLastRun records when a scheduled trigger last fired
+func (wd *WorkflowDatabase) SetTriggerLastRun(workflowID uuid.UUID, triggerID string, lastRunAt time.Time) error {
+	query := `
+		INSERT INTO server_workflow_trigger_runs (workflow_id, trigger_id, last_run_at, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (workflow_id, trigger_id)
+		DO UPDATE SET last_run_at = $3, updated_at = NOW()
+	`
+
+	_, err := wd.db.Exec(query, workflowID, triggerID, lastRunAt)
+	return err
+}
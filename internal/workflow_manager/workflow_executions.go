@@ -0,0 +1,414 @@
+package workflow_manager
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+var (
+	// ErrExecutionNotRunning is returned when cancelling an execution that already finished
+	ErrExecutionNotRunning = errors.New("execution is not running")
+	// ErrExecutionRunning is returned when retrying an execution that hasn't finished yet
+	ErrExecutionRunning = errors.New("execution is still running")
+	// ErrWorkflowNotActive is returned when retrying an execution of a disabled or deleted workflow
+	ErrWorkflowNotActive = errors.New("workflow is not active")
+
+	// errExecutionCancelled stops an execution that was cancelled through the API
+	errExecutionCancelled = errors.New("execution was cancelled")
+	// errExecutionInterrupted stops an execution because the manager is shutting
+	// down. The execution stays RUNNING and resumes on the next start.
+	errExecutionInterrupted = errors.New("execution was interrupted by a shutdown")
+	// errBranchCancelled stops a parallel branch whose step no longer needs it
+	errBranchCancelled = errors.New("parallel branch was cancelled")
+)
+
+// Reasons for failing RUNNING executions that can't be resumed on startup
+const (
+	orphanWorkflowInactive = "Execution was interrupted by a restart and its workflow is no longer enabled"
+	orphanNoCheckpoint     = "Execution was interrupted by a restart before it saved a checkpoint"
+	orphanWorkflowChanged  = "Execution was interrupted by a restart and its workflow has changed since it started"
+)
+
+// executionRun tracks an execution running in this process so it can be
+// cancelled and checkpointed
+type executionRun struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cancelled atomic.Bool
+
+	// Only touched by the goroutine running the execution
+	summary   *models.WorkflowExecutionSummary
+	stepIndex int
+	stepID    string
+}
+
+// err reports why the run was stopped, or nil while it may continue
+func (r *executionRun) err() error {
+	if r.ctx.Err() == nil {
+		return nil
+	}
+	if r.cancelled.Load() {
+		return errExecutionCancelled
+	}
+	return errExecutionInterrupted
+}
+
+// registerRun starts tracking an execution
+func (wm *WorkflowManager) registerRun(executionID uuid.UUID) *executionRun {
+	ctx, cancel := context.WithCancel(wm.ctx)
+	run := &executionRun{ctx: ctx, cancel: cancel, stepIndex: -1}
+
+	wm.executionMutex.Lock()
+	if wm.runs == nil {
+		wm.runs = make(map[uuid.UUID]*executionRun)
+	}
+	wm.runs[executionID] = run
+	wm.executionMutex.Unlock()
+
+	return run
+}
+
+// unregisterRun stops tracking an execution
+func (wm *WorkflowManager) unregisterRun(executionID uuid.UUID) {
+	wm.executionMutex.Lock()
+	if run, ok := wm.runs[executionID]; ok {
+		run.cancel()
+		delete(wm.runs, executionID)
+	}
+	wm.executionMutex.Unlock()
+}
+
+// runFor returns the run of an execution, or nil for simulations
+func (wm *WorkflowManager) runFor(context *models.WorkflowExecutionContext) *executionRun {
+	wm.executionMutex.RLock()
+	defer wm.executionMutex.RUnlock()
+
+	return wm.runs[context.ExecutionID]
+}
+
+// executionCtx returns the context that is cancelled when the execution, or
+// the parallel branch the context belongs to, stops
+func (wm *WorkflowManager) executionCtx(context *models.WorkflowExecutionContext) context.Context {
+	if ctx := wm.branchCtx(context); ctx != nil {
+		return ctx
+	}
+	if run := wm.runFor(context); run != nil {
+		return run.ctx
+	}
+	return wm.ctx
+}
+
+// registerBranch gives the context of a parallel branch its own context,
+// derived from the context it was cloned from
+func (wm *WorkflowManager) registerBranch(parent, branch *models.WorkflowExecutionContext) context.CancelFunc {
+	ctx, cancel := context.WithCancel(wm.executionCtx(parent))
+
+	wm.executionMutex.Lock()
+	if wm.branches == nil {
+		wm.branches = make(map[*models.WorkflowExecutionContext]context.Context)
+	}
+	wm.branches[branch] = ctx
+	wm.executionMutex.Unlock()
+
+	return cancel
+}
+
+// unregisterBranch stops tracking the context of a parallel branch
+func (wm *WorkflowManager) unregisterBranch(branch *models.WorkflowExecutionContext) {
+	wm.executionMutex.Lock()
+	delete(wm.branches, branch)
+	wm.executionMutex.Unlock()
+}
+
+// branchCtx returns the context of a parallel branch, or nil outside of one
+func (wm *WorkflowManager) branchCtx(context *models.WorkflowExecutionContext) context.Context {
+	wm.executionMutex.RLock()
+	defer wm.executionMutex.RUnlock()
+
+	return wm.branches[context]
+}
+
+// stopErr reports why the steps of an execution context must stop, or nil
+// while they may continue
+func (wm *WorkflowManager) stopErr(context *models.WorkflowExecutionContext) error {
+	if run := wm.runFor(context); run != nil {
+		if err := run.err(); err != nil {
+			return err
+		}
+	}
+	if ctx := wm.branchCtx(context); ctx != nil && ctx.Err() != nil {
+		return errBranchCancelled
+	}
+	return wm.ctx.Err()
+}
+
+// checkpointStep is called before each top-level step. It stops the
+// execution if it was cancelled and saves the state to resume from.
+func (wm *WorkflowManager) checkpointStep(context *models.WorkflowExecutionContext, index int, step *models.WorkflowStep) error {
+	run := wm.runFor(context)
+	if run == nil {
+		return nil
+	}
+	if err := run.err(); err != nil {
+		return err
+	}
+
+	run.stepIndex = index
+	run.stepID = step.ID
+	wm.saveCheckpoint(context, run, nil)
+	return nil
+}
+
+// saveCheckpoint writes the execution state to PostgreSQL. A failed write is
+// only logged, the execution itself carries on.
+func (wm *WorkflowManager) saveCheckpoint(context *models.WorkflowExecutionContext, run *executionRun, delayUntil *time.Time) {
+	if wm.db == nil || run.stepIndex < 0 {
+		return
+	}
+
+	checkpoint := &models.WorkflowExecutionCheckpoint{
+		StepIndex:   run.stepIndex,
+		StepID:      run.stepID,
+		Variables:   context.Variables,
+		StepResults: context.StepResults,
+		Metadata:    context.Metadata,
+		DelayUntil:  delayUntil,
+	}
+	if run.summary != nil {
+		checkpoint.CompletedSteps = run.summary.CompletedSteps
+		checkpoint.FailedSteps = run.summary.FailedSteps
+		checkpoint.SkippedSteps = run.summary.SkippedSteps
+	}
+
+	if err := wm.workflowDB.SaveExecutionCheckpoint(context.ExecutionID, checkpoint); err != nil {
+		log.Warn().
+			Err(err).
+			Str("execution_id", context.ExecutionID.String()).
+			Str("step_id", run.stepID).
+			Msg("Failed to save execution checkpoint")
+	}
+}
+
+// sleep waits for duration unless the execution is cancelled or interrupted first
+func (wm *WorkflowManager) sleep(context *models.WorkflowExecutionContext, duration time.Duration) error {
+	if wm.runFor(context) == nil && wm.branchCtx(context) == nil {
+		time.Sleep(duration)
+		return nil
+	}
+	if duration <= 0 {
+		return wm.stopErr(context)
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-wm.executionCtx(context).Done():
+		return wm.stopErr(context)
+	}
+}
+
+// restoreExecutionContext rebuilds the execution context of a RUNNING execution from its checkpoint
+func restoreExecutionContext(workflow *models.ServerWorkflow, execution *models.ServerWorkflowExecution) *models.WorkflowExecutionContext {
+	checkpoint := execution.Checkpoint
+
+	context := &models.WorkflowExecutionContext{
+		ExecutionID:  execution.ExecutionID,
+		WorkflowID:   workflow.ID,
+		ServerID:     workflow.ServerID,
+		TriggerEvent: execution.TriggerData,
+		Metadata:     checkpoint.Metadata,
+		Variables:    checkpoint.Variables,
+		StepResults:  checkpoint.StepResults,
+		CurrentStep:  checkpoint.StepID,
+		StartedAt:    execution.StartedAt,
+		LuaLimits:    workflow.Definition.LuaLimits,
+	}
+
+	if context.TriggerEvent == nil {
+		context.TriggerEvent = make(map[string]interface{})
+	}
+	if context.Metadata == nil {
+		context.Metadata = make(map[string]interface{})
+	}
+	if context.Variables == nil {
+		context.Variables = make(map[string]interface{})
+	}
+	if context.StepResults == nil {
+		context.StepResults = make(map[string]interface{})
+	}
+
+	// skipped_steps comes back from JSON as a generic map
+	if skipped, ok := context.Metadata["skipped_steps"].(map[string]interface{}); ok {
+		skippedSteps := make(map[string]bool, len(skipped))
+		for stepID, value := range skipped {
+			if skip, ok := value.(bool); ok {
+				skippedSteps[stepID] = skip
+			}
+		}
+		context.Metadata["skipped_steps"] = skippedSteps
+	}
+
+	return context
+}
+
+// resumeBlocker returns why a RUNNING execution can't be resumed, or "" if it can
+func resumeBlocker(workflow *models.ServerWorkflow, execution *models.ServerWorkflowExecution) string {
+	if workflow == nil {
+		return orphanWorkflowInactive
+	}
+
+	checkpoint := execution.Checkpoint
+	if checkpoint == nil {
+		return orphanNoCheckpoint
+	}
+
+	// Step indexes are only meaningful for the revision that saved them
+	if execution.Revision == nil || *execution.Revision != workflow.Revision {
+		return orphanWorkflowChanged
+	}
+	steps := workflow.Definition.Steps
+	if checkpoint.StepIndex < 0 || checkpoint.StepIndex >= len(steps) || steps[checkpoint.StepIndex].ID != checkpoint.StepID {
+		return orphanWorkflowChanged
+	}
+
+	return ""
+}
+
+// resumeExecutions resumes the executions left RUNNING by the previous
+// process and fails the ones that can't be resumed. It is called from Start
+// once the workflows are loaded.
+func (wm *WorkflowManager) resumeExecutions() {
+	executions, err := wm.workflowDB.GetRunningExecutions()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load running workflow executions")
+		return
+	}
+
+	resumed := 0
+	for i := range executions {
+		execution := &executions[i]
+
+		wm.executionMutex.RLock()
+		_, running := wm.runs[execution.ExecutionID]
+		wm.executionMutex.RUnlock()
+		if running {
+			continue
+		}
+
+		workflow := wm.activeWorkflows[execution.WorkflowID]
+		if reason := resumeBlocker(workflow, execution); reason != "" {
+			log.Warn().
+				Str("execution_id", execution.ExecutionID.String()).
+				Str("workflow_id", execution.WorkflowID.String()).
+				Str("reason", reason).
+				Msg("Failing orphaned workflow execution")
+
+			if _, err := wm.workflowDB.FinishRunningExecution(execution.ExecutionID, "FAILED", reason); err != nil {
+				log.Error().Err(err).Str("execution_id", execution.ExecutionID.String()).Msg("Failed to update orphaned execution record in PostgreSQL")
+			}
+			continue
+		}
+
+		wm.registerRun(execution.ExecutionID)
+		go wm.resumeExecution(workflow, execution)
+		resumed++
+	}
+
+	if len(executions) > 0 {
+		log.Info().
+			Int("resumed", resumed).
+			Int("failed", len(executions)-resumed).
+			Msg("Reconciled workflow executions left running by the previous shutdown")
+	}
+}
+
+// resumeExecution continues an execution from its checkpoint
+func (wm *WorkflowManager) resumeExecution(workflow *models.ServerWorkflow, execution *models.ServerWorkflowExecution) {
+	context := restoreExecutionContext(workflow, execution)
+	checkpoint := execution.Checkpoint
+
+	wm.executionMutex.Lock()
+	wm.executionContext[context.ExecutionID] = context
+	wm.executionMutex.Unlock()
+
+	log.Info().
+		Str("execution_id", context.ExecutionID.String()).
+		Str("workflow_id", workflow.ID.String()).
+		Str("step_id", checkpoint.StepID).
+		Int("step_index", checkpoint.StepIndex).
+		Msg("Resuming workflow execution from checkpoint")
+
+	wm.logWorkflowStep(context, workflow, "workflow_resumed", "WORKFLOW", uint32(checkpoint.StepIndex+1), "RUNNING",
+		map[string]interface{}{"step_id": checkpoint.StepID, "step_index": checkpoint.StepIndex},
+		map[string]interface{}{"status": "resumed"}, nil, 0)
+
+	wm.runExecution(context, workflow, execution, checkpoint)
+}
+
+// CancelExecution stops a running execution. Executions left RUNNING without
+// a goroutine behind them are marked as cancelled directly.
+func (wm *WorkflowManager) CancelExecution(executionID uuid.UUID) error {
+	wm.executionMutex.RLock()
+	run := wm.runs[executionID]
+	wm.executionMutex.RUnlock()
+
+	if run != nil {
+		run.cancelled.Store(true)
+		run.cancel()
+
+		log.Info().Str("execution_id", executionID.String()).Msg("Cancelling workflow execution")
+		return nil
+	}
+
+	if wm.db == nil {
+		return ErrExecutionNotRunning
+	}
+
+	finished, err := wm.workflowDB.FinishRunningExecution(executionID, "CANCELLED", errExecutionCancelled.Error())
+	if err != nil {
+		return err
+	}
+	if !finished {
+		return ErrExecutionNotRunning
+	}
+	return nil
+}
+
+// RetryExecution starts a new execution of the workflow with the trigger
+// event of a finished execution and returns the new execution ID
+func (wm *WorkflowManager) RetryExecution(executionID uuid.UUID) (uuid.UUID, error) {
+	execution, err := wm.workflowDB.GetWorkflowExecution(executionID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if execution.Status == "RUNNING" {
+		return uuid.Nil, ErrExecutionRunning
+	}
+
+	wm.mutex.RLock()
+	workflow := wm.activeWorkflows[execution.WorkflowID]
+	wm.mutex.RUnlock()
+
+	if workflow == nil {
+		return uuid.Nil, ErrWorkflowNotActive
+	}
+
+	triggerEvent := execution.TriggerData
+	if triggerEvent == nil {
+		triggerEvent = make(map[string]interface{})
+	}
+
+	context, pgExecution := wm.startExecution(workflow, triggerEvent, &execution.ExecutionID)
+	go wm.runExecution(context, workflow, pgExecution, nil)
+
+	return context.ExecutionID, nil
+}
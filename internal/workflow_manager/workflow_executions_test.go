@@ -0,0 +1,128 @@
+package workflow_manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func delayWorkflow() *models.ServerWorkflow {
+	return &models.ServerWorkflow{
+		Definition: models.WorkflowDefinition{
+			Steps: []models.WorkflowStep{
+				{ID: "wait", Name: "Wait", Type: models.StepTypeDelay, Enabled: true, Config: map[string]interface{}{"delay_ms": float64(60000)}},
+				{ID: "after", Name: "After", Type: models.StepTypeVariable, Enabled: true, Config: map[string]interface{}{
+					"operation": "set", "variable_name": "done", "value": true,
+				}},
+			},
+		},
+	}
+}
+
+func TestExecutionCancelAndInterrupt(t *testing.T) {
+	tests := []struct {
+		name    string
+		stop    func(wm *WorkflowManager, execution *models.WorkflowExecutionContext)
+		wantErr error
+	}{
+		{
+			name: "cancel",
+			stop: func(wm *WorkflowManager, execution *models.WorkflowExecutionContext) {
+				if err := wm.CancelExecution(execution.ExecutionID); err != nil {
+					t.Errorf("Failed to cancel: %v", err)
+				}
+			},
+			wantErr: errExecutionCancelled,
+		},
+		{
+			name:    "shutdown",
+			stop:    func(wm *WorkflowManager, _ *models.WorkflowExecutionContext) { wm.cancel() },
+			wantErr: errExecutionInterrupted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			wm := &WorkflowManager{ctx: ctx, cancel: cancel}
+
+			workflow := delayWorkflow()
+			execution := wm.newExecutionContext(workflow, map[string]interface{}{})
+			wm.registerRun(execution.ExecutionID)
+			defer wm.unregisterRun(execution.ExecutionID)
+
+			done := make(chan error, 1)
+			go func() {
+				done <- wm.executeWorkflowSteps(execution, workflow, &models.WorkflowExecutionSummary{})
+			}()
+
+			time.Sleep(20 * time.Millisecond)
+			tt.stop(wm, execution)
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Execution did not stop during the delay step")
+			}
+
+			if _, ok := execution.Variables["done"]; ok {
+				t.Errorf("Expected the step after the delay not to run")
+			}
+		})
+	}
+
+	wm := &WorkflowManager{ctx: context.Background()}
+	if err := wm.CancelExecution(delayWorkflow().ID); !errors.Is(err, ErrExecutionNotRunning) {
+		t.Errorf("Expected ErrExecutionNotRunning for an unknown execution, got %v", err)
+	}
+}
+
+func TestRestoreExecutionContext(t *testing.T) {
+	workflow := delayWorkflow()
+	revision := workflow.Revision
+
+	// Checkpoints go through JSON on their way to and from PostgreSQL
+	data, err := json.Marshal(&models.WorkflowExecutionCheckpoint{
+		StepIndex:   1,
+		StepID:      "after",
+		Variables:   map[string]interface{}{"count": 2},
+		StepResults: map[string]interface{}{"wait": map[string]interface{}{"ok": true}},
+		Metadata:    map[string]interface{}{"skipped_steps": map[string]bool{"after": true}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal checkpoint: %v", err)
+	}
+	var checkpoint models.WorkflowExecutionCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		t.Fatalf("Failed to unmarshal checkpoint: %v", err)
+	}
+
+	execution := &models.ServerWorkflowExecution{Revision: &revision, Checkpoint: &checkpoint}
+	if reason := resumeBlocker(workflow, execution); reason != "" {
+		t.Fatalf("Expected the execution to be resumable, got %q", reason)
+	}
+
+	restored := restoreExecutionContext(workflow, execution)
+	if restored.Variables["count"] != float64(2) || restored.CurrentStep != "after" {
+		t.Errorf("Unexpected restored context: %+v", restored)
+	}
+	if skipped, ok := restored.Metadata["skipped_steps"].(map[string]bool); !ok || !skipped["after"] {
+		t.Errorf("Expected skipped_steps to be restored as map[string]bool, got %T", restored.Metadata["skipped_steps"])
+	}
+
+	workflow.Definition.Steps[1].ID = "renamed"
+	if reason := resumeBlocker(workflow, execution); reason != orphanWorkflowChanged {
+		t.Errorf("Expected a changed workflow to block resuming, got %q", reason)
+	}
+	if reason := resumeBlocker(nil, execution); reason != orphanWorkflowInactive {
+		t.Errorf("Expected a missing workflow to block resuming, got %q", reason)
+	}
+}
@@ -0,0 +1,399 @@
+package workflow_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// Lua resource limits. Workflows can lower or raise the defaults up to the maximums.
+const (
+	defaultLuaTimeout          = 30 * time.Second
+	maxLuaTimeout              = 5 * time.Minute
+	defaultLuaInstructionLimit = int64(50_000_000)
+	maxLuaInstructionLimit     = int64(1_000_000_000)
+	defaultLuaMemoryLimitKB    = 16 * 1024
+	maxLuaMemoryLimitKB        = 256 * 1024
+
+	// luaMemoryCheckInterval is how many instructions run between memory estimates
+	luaMemoryCheckInterval = 10_000
+	// luaCallStackSize bounds recursion depth
+	luaCallStackSize = 200
+	// luaRegistryMaxSize bounds the value stack shared by all call frames
+	luaRegistryMaxSize = 256 * 1024
+	// luaFormatMaxWidth is the largest width or precision fmt accepts
+	luaFormatMaxWidth = 1_000_000
+)
+
+// luaLibraries are the standard libraries a workflow may load. io, package,
+// debug, channel and coroutine are never available.
+var luaLibraries = map[string]lua.LGFunction{
+	"base":   lua.OpenBase,
+	"table":  lua.OpenTable,
+	"string": lua.OpenString,
+	"math":   lua.OpenMath,
+	"os":     lua.OpenOs,
+}
+
+// defaultLuaLibraries are loaded when a workflow doesn't list its own
+var defaultLuaLibraries = []string{"base", "table", "string", "math", "os"}
+
+// luaRemovedFunctions are stripped from the loaded libraries because they
+// reach the file system, the environment or the process
+var luaRemovedFunctions = map[string][]string{
+	"base": {"dofile", "loadfile", "module", "require"},
+	"os":   {"execute", "exit", "getenv", "remove", "rename", "setenv", "setlocale", "tmpname"},
+}
+
+// luaScriptLimits are the resolved limits for a single script run
+type luaScriptLimits struct {
+	timeout         time.Duration
+	maxInstructions int64
+	maxMemoryBytes  int64
+	libraries       []string
+}
+
+// resolveLuaLimits combines the defaults, the workflow's limits and a step's
+// timeout_seconds override, clamped to the maximums
+func resolveLuaLimits(limits *models.WorkflowLuaLimits, step *models.WorkflowStep) luaScriptLimits {
+	resolved := luaScriptLimits{
+		timeout:         defaultLuaTimeout,
+		maxInstructions: defaultLuaInstructionLimit,
+		maxMemoryBytes:  int64(defaultLuaMemoryLimitKB) * 1024,
+		libraries:       defaultLuaLibraries,
+	}
+
+	if limits != nil {
+		if limits.TimeoutSeconds > 0 {
+			resolved.timeout = time.Duration(limits.TimeoutSeconds) * time.Second
+		}
+		if limits.MaxInstructions > 0 {
+			resolved.maxInstructions = limits.MaxInstructions
+		}
+		if limits.MaxMemoryKB > 0 {
+			resolved.maxMemoryBytes = int64(limits.MaxMemoryKB) * 1024
+		}
+		if len(limits.Libraries) > 0 {
+			resolved.libraries = limits.Libraries
+		}
+	}
+
+	if timeout, ok := step.Config["timeout_seconds"].(float64); ok && timeout > 0 {
+		resolved.timeout = time.Duration(timeout * float64(time.Second))
+	}
+
+	resolved.timeout = min(resolved.timeout, maxLuaTimeout)
+	resolved.maxInstructions = min(resolved.maxInstructions, maxLuaInstructionLimit)
+	resolved.maxMemoryBytes = min(resolved.maxMemoryBytes, int64(maxLuaMemoryLimitKB)*1024)
+
+	return resolved
+}
+
+// validateLuaLimits reports problems with a workflow's Lua limits
+func validateLuaLimits(limits *models.WorkflowLuaLimits) []string {
+	if limits == nil {
+		return nil
+	}
+
+	var problems []string
+	if limits.TimeoutSeconds < 0 || time.Duration(limits.TimeoutSeconds)*time.Second > maxLuaTimeout {
+		problems = append(problems, fmt.Sprintf("timeout_seconds must be between 0 and %d", int(maxLuaTimeout.Seconds())))
+	}
+	if limits.MaxInstructions < 0 || limits.MaxInstructions > maxLuaInstructionLimit {
+		problems = append(problems, fmt.Sprintf("max_instructions must be between 0 and %d", maxLuaInstructionLimit))
+	}
+	if limits.MaxMemoryKB < 0 || limits.MaxMemoryKB > maxLuaMemoryLimitKB {
+		problems = append(problems, fmt.Sprintf("max_memory_kb must be between 0 and %d", maxLuaMemoryLimitKB))
+	}
+	for _, library := range limits.Libraries {
+		if _, ok := luaLibraries[library]; !ok {
+			problems = append(problems, fmt.Sprintf("Lua library %q is not available", library))
+		}
+	}
+	return problems
+}
+
+// newSandboxedLuaState creates a Lua state with only the whitelisted libraries
+func newSandboxedLuaState(limits luaScriptLimits) *lua.LState {
+	L := lua.NewState(lua.Options{
+		SkipOpenLibs:        true,
+		CallStackSize:       luaCallStackSize,
+		RegistryMaxSize:     luaRegistryMaxSize,
+		MinimizeStackMemory: true,
+	})
+
+	for _, library := range limits.libraries {
+		open, ok := luaLibraries[library]
+		if !ok {
+			continue
+		}
+
+		name := library
+		if library == "base" {
+			name = lua.BaseLibName
+		}
+		L.Push(L.NewFunction(open))
+		L.Push(lua.LString(name))
+		L.Call(1, 0)
+
+		for _, function := range luaRemovedFunctions[library] {
+			if library == "base" {
+				L.SetGlobal(function, lua.LNil)
+			} else if module, ok := L.GetGlobal(library).(*lua.LTable); ok {
+				module.RawSetString(function, lua.LNil)
+			}
+		}
+	}
+
+	return L
+}
+
+// luaBudget is the context a sandboxed Lua state runs with. gopher-lua checks
+// Done once per VM instruction, which is used to count instructions and to
+// periodically estimate memory use. It is only safe for states without
+// coroutines, since all calls have to come from the goroutine running the VM.
+type luaBudget struct {
+	context.Context
+	cancel context.CancelFunc
+
+	L               *lua.LState
+	timeout         time.Duration
+	maxInstructions int64
+	maxMemoryBytes  int64
+
+	instructions    int64
+	peakMemoryBytes int64
+	err             error
+}
+
+// newLuaBudget creates the budget context for a script run
+func newLuaBudget(parent context.Context, L *lua.LState, limits luaScriptLimits) *luaBudget {
+	ctx, cancel := context.WithTimeout(parent, limits.timeout)
+	return &luaBudget{
+		Context:         ctx,
+		cancel:          cancel,
+		L:               L,
+		timeout:         limits.timeout,
+		maxInstructions: limits.maxInstructions,
+		maxMemoryBytes:  limits.maxMemoryBytes,
+	}
+}
+
+func (b *luaBudget) Done() <-chan struct{} {
+	b.instructions++
+
+	if b.err == nil {
+		if b.instructions > b.maxInstructions {
+			b.stop(fmt.Errorf("instruction limit of %d exceeded", b.maxInstructions))
+		} else if b.instructions%luaMemoryCheckInterval == 0 {
+			b.measureMemory()
+		}
+	}
+
+	return b.Context.Done()
+}
+
+func (b *luaBudget) Err() error {
+	if b.err != nil {
+		return b.err
+	}
+
+	err := b.Context.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %v", b.timeout)
+	}
+	return err
+}
+
+// stop aborts the script with err
+func (b *luaBudget) stop(err error) {
+	b.err = err
+	b.cancel()
+}
+
+// measureMemory updates the peak memory estimate and stops the script when
+// it is over the limit
+func (b *luaBudget) measureMemory() {
+	used := estimateLuaMemory(b.L, b.maxMemoryBytes)
+	b.peakMemoryBytes = max(b.peakMemoryBytes, used)
+
+	if used > b.maxMemoryBytes && b.err == nil {
+		b.stop(fmt.Errorf("memory limit of %d KB exceeded", b.maxMemoryBytes/1024))
+	}
+}
+
+// guardAllocations wraps the library functions that can build a large string
+// in a single instruction, so their output is checked against the memory
+// limit before it is allocated instead of at the next memory estimate
+func (b *luaBudget) guardAllocations() {
+	b.guardAllocation("string", "rep", func(L *lua.LState) int64 {
+		str := L.CheckString(1)
+		n := L.CheckInt(2)
+		if n <= 0 || len(str) == 0 {
+			return 0
+		}
+		return min(int64(n), math.MaxInt64/int64(len(str))) * int64(len(str))
+	})
+
+	b.guardAllocation("string", "format", func(L *lua.LState) int64 {
+		format := L.CheckString(1)
+		size := int64(len(format)) + luaFormatPadding(format)
+		for i := 2; i <= L.GetTop(); i++ {
+			size += 32 + int64(len(lua.LVAsString(L.Get(i))))
+		}
+		return size
+	})
+
+	b.guardAllocation("table", "concat", func(L *lua.LState) int64 {
+		tbl := L.CheckTable(1)
+		sep := int64(len(L.OptString(2, "")))
+		first := max(L.OptInt(3, 1), 1)
+		last := min(L.OptInt(4, tbl.Len()), tbl.Len())
+
+		var size int64
+		for i := first; i <= last; i++ {
+			size += int64(len(lua.LVAsString(tbl.RawGetInt(i)))) + sep
+		}
+		return size
+	})
+}
+
+// guardAllocation replaces library.name with a function that stops the script
+// when size reports a result larger than the memory limit, and otherwise
+// calls the original
+func (b *luaBudget) guardAllocation(library, name string, size func(L *lua.LState) int64) {
+	module, ok := b.L.GetGlobal(library).(*lua.LTable)
+	if !ok {
+		return
+	}
+	original, ok := module.RawGetString(name).(*lua.LFunction)
+	if !ok || original.GFunction == nil {
+		return
+	}
+
+	module.RawSetString(name, b.L.NewFunction(func(L *lua.LState) int {
+		if size(L) > b.maxMemoryBytes {
+			err := fmt.Errorf("memory limit of %d KB exceeded", b.maxMemoryBytes/1024)
+			b.stop(err)
+			L.RaiseError("%s.%s: %v", library, name, err)
+		}
+		return original.GFunction(L)
+	}))
+}
+
+// luaFormatPadding sums the widths and precisions of a string.format
+// pattern, which can make the output far longer than the pattern itself
+func luaFormatPadding(format string) int64 {
+	var padding int64
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("-+ #0", format[i]) >= 0 {
+			i++
+		}
+
+		var number int64
+		for ; i < len(format) && (format[i] == '.' || format[i] >= '0' && format[i] <= '9'); i++ {
+			if format[i] == '.' {
+				padding += number
+				number = 0
+			} else {
+				number = min(number*10+int64(format[i]-'0'), luaFormatMaxWidth)
+			}
+		}
+		padding += number
+	}
+	return padding
+}
+
+// metrics returns the script's resource use for the step results and logs
+func (b *luaBudget) metrics(duration time.Duration) map[string]interface{} {
+	metrics := map[string]interface{}{
+		"duration_ms":      duration.Milliseconds(),
+		"instructions":     b.instructions,
+		"peak_memory_kb":   b.peakMemoryBytes / 1024,
+		"max_instructions": b.maxInstructions,
+		"max_memory_kb":    b.maxMemoryBytes / 1024,
+		"timeout_ms":       b.timeout.Milliseconds(),
+	}
+	if err := b.Err(); err != nil {
+		metrics["limit_exceeded"] = err.Error()
+	}
+	return metrics
+}
+
+// estimateLuaMemory approximates the memory held by values reachable from
+// the globals and from the locals of the running functions. It stops
+// counting once the total is over limit.
+func estimateLuaMemory(L *lua.LState, limit int64) int64 {
+	var size int64
+	seen := make(map[interface{}]bool)
+
+	var visit func(value lua.LValue)
+	visit = func(value lua.LValue) {
+		if size > limit {
+			return
+		}
+
+		switch value := value.(type) {
+		case lua.LString:
+			size += 16 + int64(len(value))
+		case *lua.LTable:
+			if seen[value] {
+				return
+			}
+			seen[value] = true
+			size += 64
+			for key, item := value.Next(lua.LNil); key != lua.LNil && size <= limit; key, item = value.Next(key) {
+				size += 32
+				visit(key)
+				visit(item)
+			}
+			if value.Metatable != nil {
+				visit(value.Metatable)
+			}
+		case *lua.LFunction:
+			if seen[value] {
+				return
+			}
+			seen[value] = true
+			size += 64
+			for _, upvalue := range value.Upvalues {
+				visit(upvalue.Value())
+			}
+		case *lua.LUserData:
+			if seen[value] {
+				return
+			}
+			seen[value] = true
+			size += 64
+		default:
+			size += 16
+		}
+	}
+
+	visit(L.G.Global)
+	for level := 0; ; level++ {
+		frame, ok := L.GetStack(level)
+		if !ok {
+			break
+		}
+		for n := 1; ; n++ {
+			name, value := L.GetLocal(frame, n)
+			if name == "" {
+				break
+			}
+			visit(value)
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,104 @@
+package workflow_manager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func TestLuaScriptLimits(t *testing.T) {
+	wm := &WorkflowManager{ctx: context.Background()}
+
+	tests := []struct {
+		name    string
+		limits  *models.WorkflowLuaLimits
+		config  map[string]interface{}
+		script  string
+		wantErr string
+	}{
+		{
+			name:    "instruction limit",
+			limits:  &models.WorkflowLuaLimits{MaxInstructions: 100000},
+			script:  "while true do end",
+			wantErr: "instruction limit of 100000 exceeded",
+		},
+		{
+			name:    "memory limit",
+			limits:  &models.WorkflowLuaLimits{MaxMemoryKB: 512},
+			script:  `local t = {} for i = 1, 1000000 do t[i] = string.rep("x", 64) .. i end`,
+			wantErr: "memory limit of 512 KB exceeded",
+		},
+		{
+			name:    "string.rep memory limit",
+			limits:  &models.WorkflowLuaLimits{MaxMemoryKB: 512},
+			script:  `local s = string.rep("x", 1000000000)`,
+			wantErr: "memory limit of 512 KB exceeded",
+		},
+		{
+			name:    "table.concat memory limit",
+			limits:  &models.WorkflowLuaLimits{MaxMemoryKB: 512},
+			script:  `local s = string.rep("x", 400000) local t = table.concat({s, s})`,
+			wantErr: "memory limit of 512 KB exceeded",
+		},
+		{
+			name:    "string.format memory limit",
+			limits:  &models.WorkflowLuaLimits{MaxMemoryKB: 512},
+			script:  `local s = string.format("%999999s", "x")`,
+			wantErr: "memory limit of 512 KB exceeded",
+		},
+		{
+			name:    "timeout",
+			config:  map[string]interface{}{"timeout_seconds": 0.05},
+			script:  "while true do end",
+			wantErr: "timed out",
+		},
+		{
+			name:    "removed library",
+			script:  "io.write('hello')",
+			wantErr: "LUA script execution failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := &models.ServerWorkflow{Definition: models.WorkflowDefinition{LuaLimits: tt.limits}}
+			execution := wm.newExecutionContext(workflow, map[string]interface{}{})
+			step := &models.WorkflowStep{ID: "lua", Name: "Lua", Type: models.StepTypeLua, Config: tt.config}
+
+			err := wm.executeLuaScript(execution, step, tt.script)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLuaScriptSandbox(t *testing.T) {
+	wm := &WorkflowManager{ctx: context.Background()}
+	workflow := &models.ServerWorkflow{}
+	execution := wm.newExecutionContext(workflow, map[string]interface{}{})
+	step := &models.WorkflowStep{ID: "lua", Name: "Lua", Type: models.StepTypeLua}
+
+	script := `
+		result.sandboxed = io == nil and debug == nil and require == nil and dofile == nil and os.execute == nil
+		result.has_time = os.time() > 0
+	`
+	if err := wm.executeLuaScript(execution, step, script); err != nil {
+		t.Fatalf("Script failed: %v", err)
+	}
+
+	result, ok := execution.StepResults["lua"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a result table, got %v", execution.StepResults["lua"])
+	}
+	if result["sandboxed"] != true || result["has_time"] != true {
+		t.Errorf("Expected a sandboxed state with os.time, got %v", result)
+	}
+
+	metrics, ok := result["lua_metrics"].(map[string]interface{})
+	if !ok || metrics["instructions"].(int64) == 0 {
+		t.Errorf("Expected lua_metrics with an instruction count, got %v", result["lua_metrics"])
+	}
+}
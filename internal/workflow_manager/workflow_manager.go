@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -20,24 +21,36 @@ import (
 	"go.codycody31.dev/squad-aegis/internal/clickhouse"
 	"go.codycody31.dev/squad-aegis/internal/event_manager"
 	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/player_tracker_manager"
 	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
 )
 
 // WorkflowManager manages workflow execution and lifecycle
 type WorkflowManager struct {
-	ctx              context.Context
-	cancel           context.CancelFunc
-	db               *sql.DB
-	eventManager     *event_manager.EventManager
-	rconManager      *rcon_manager.RconManager
-	clickhouseClient *clickhouse.Client
-	workflowDB       *WorkflowDatabase
-	activeWorkflows  map[uuid.UUID]*models.ServerWorkflow
-	executionContext map[uuid.UUID]*models.WorkflowExecutionContext
-	mutex            sync.RWMutex
-	executionMutex   sync.RWMutex
-	isRunning        bool
-	subscriber       *event_manager.EventSubscriber
+	ctx                  context.Context
+	cancel               context.CancelFunc
+	db                   *sql.DB
+	eventManager         *event_manager.EventManager
+	rconManager          *rcon_manager.RconManager
+	playerTrackerManager *player_tracker_manager.PlayerTrackerManager
+	clickhouseClient     *clickhouse.Client
+	workflowDB           *WorkflowDatabase
+	activeWorkflows      map[uuid.UUID]*models.ServerWorkflow
+	executionContext     map[uuid.UUID]*models.WorkflowExecutionContext
+	runs                 map[uuid.UUID]*executionRun
+	branches             map[*models.WorkflowExecutionContext]context.Context
+	simulations          map[uuid.UUID]*workflowSimulation
+	schedules            map[string]*scheduledTrigger
+	mutex                sync.RWMutex
+	executionMutex       sync.RWMutex
+	scheduleMutex        sync.Mutex
+	limiters             map[uuid.UUID]*workflowLimiter
+	serverRunning        map[uuid.UUID]int
+	triggerDrops         map[uuid.UUID]map[string]int64
+	concurrencyMutex     sync.Mutex
+	serverExecutionLimit int // Running executions allowed per server, 0 for no limit
+	isRunning            bool
+	subscriber           *event_manager.EventSubscriber
 }
 
 // NewWorkflowManager creates a new workflow manager
@@ -46,20 +59,27 @@ func NewWorkflowManager(
 	db *sql.DB,
 	eventManager *event_manager.EventManager,
 	rconManager *rcon_manager.RconManager,
+	playerTrackerManager *player_tracker_manager.PlayerTrackerManager,
 	clickhouseClient *clickhouse.Client,
 ) *WorkflowManager {
 	ctx, cancel := context.WithCancel(ctx)
 
 	return &WorkflowManager{
-		ctx:              ctx,
-		cancel:           cancel,
-		db:               db,
-		eventManager:     eventManager,
-		rconManager:      rconManager,
-		clickhouseClient: clickhouseClient,
-		workflowDB:       NewWorkflowDatabase(db),
-		activeWorkflows:  make(map[uuid.UUID]*models.ServerWorkflow),
-		executionContext: make(map[uuid.UUID]*models.WorkflowExecutionContext),
+		ctx:                  ctx,
+		cancel:               cancel,
+		db:                   db,
+		eventManager:         eventManager,
+		rconManager:          rconManager,
+		playerTrackerManager: playerTrackerManager,
+		clickhouseClient:     clickhouseClient,
+		workflowDB:           NewWorkflowDatabase(db),
+		activeWorkflows:      make(map[uuid.UUID]*models.ServerWorkflow),
+		executionContext:     make(map[uuid.UUID]*models.WorkflowExecutionContext),
+		runs:                 make(map[uuid.UUID]*executionRun),
+		simulations:          make(map[uuid.UUID]*workflowSimulation),
+		schedules:            make(map[string]*scheduledTrigger),
+		limiters:             make(map[uuid.UUID]*workflowLimiter),
+		serverRunning:        make(map[uuid.UUID]int),
 	}
 }
 
@@ -88,11 +108,20 @@ func (wm *WorkflowManager) Start() error {
 	// Start event handler goroutine
 	go wm.eventHandler()
 
+	// Start scheduled trigger loop
+	go wm.scheduleLoop()
+
+	// Start writing dropped trigger counts
+	go wm.triggerDropLoop()
+
 	log.Trace().Str("subscriber_id", wm.subscriber.ID.String()).Msg("Workflow manager subscribed to events")
 
 	wm.isRunning = true
 	log.Info().Msgf("Workflow manager started with %d active workflows", len(wm.activeWorkflows))
 
+	// Pick up executions interrupted by the previous shutdown
+	wm.resumeExecutions()
+
 	return nil
 }
 
@@ -107,7 +136,8 @@ func (wm *WorkflowManager) Stop() {
 
 	log.Debug().Msg("Stopping workflow manager")
 
-	// Cancel all running executions
+	// Running executions are interrupted by the cancel below and resume from
+	// their checkpoints on the next start
 	wm.executionMutex.Lock()
 	for executionID := range wm.executionContext {
 		log.Debug().Str("execution_id", executionID.String()).Msg("Cancelling workflow execution")
@@ -120,6 +150,8 @@ func (wm *WorkflowManager) Stop() {
 		wm.eventManager.Unsubscribe(wm.subscriber.ID)
 	}
 
+	wm.flushTriggerDrops()
+
 	wm.cancel()
 	wm.isRunning = false
 	log.Info().Msg("Workflow manager stopped")
@@ -181,6 +213,7 @@ func (wm *WorkflowManager) loadWorkflows() error {
 						Str("workflow_id", workflow.ID.String()).
 						Str("trigger_id", trigger.ID).
 						Str("trigger_name", trigger.Name).
+						Str("trigger_type", trigger.Type).
 						Str("event_type", trigger.EventType).
 						Bool("enabled", trigger.Enabled).
 						Msg("Workflow trigger")
@@ -194,6 +227,9 @@ func (wm *WorkflowManager) loadWorkflows() error {
 		}
 	}
 
+	wm.syncSchedules()
+	wm.pruneQueuedTriggers()
+
 	log.Info().Msgf("Loaded %d active workflows", len(wm.activeWorkflows))
 	return nil
 }
@@ -259,8 +295,8 @@ func (wm *WorkflowManager) handleEvent(event event_manager.Event) {
 		log.Debug().
 			Str("workflow_id", workflow.ID.String()).
 			Str("workflow_name", workflow.Name).
-			Msg("Starting workflow execution")
-		go wm.executeWorkflow(workflow, eventDataMap)
+			Msg("Dispatching workflow execution")
+		wm.dispatchWorkflow(workflow, eventDataMap)
 	}
 }
 
@@ -494,8 +530,8 @@ func (wm *WorkflowManager) evaluateRegexOperator(fieldValue, conditionValue inte
 	return matched
 }
 
-// executeWorkflow executes a workflow instance
-func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, triggerEvent map[string]interface{}) {
+// newExecutionContext creates the execution context of a workflow run
+func (wm *WorkflowManager) newExecutionContext(workflow *models.ServerWorkflow, triggerEvent map[string]interface{}) *models.WorkflowExecutionContext {
 	executionID := uuid.New()
 
 	// Create execution context
@@ -508,11 +544,13 @@ func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, trig
 		Variables:    make(map[string]interface{}),
 		StepResults:  make(map[string]interface{}),
 		StartedAt:    time.Now(),
+		LuaLimits:    workflow.Definition.LuaLimits,
 	}
 
 	// Initialize metadata with workflow information
 	context.Metadata["workflow_name"] = workflow.Name
 	context.Metadata["workflow_id"] = workflow.ID.String()
+	context.Metadata["workflow_revision"] = workflow.Revision
 	context.Metadata["server_id"] = workflow.ServerID.String()
 	context.Metadata["execution_id"] = executionID.String()
 	context.Metadata["started_at"] = context.StartedAt.Format(time.RFC3339)
@@ -529,10 +567,25 @@ func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, trig
 		}
 	}
 
+	return context
+}
+
+// executeWorkflow executes a workflow instance
+func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, triggerEvent map[string]interface{}) {
+	context, pgExecution := wm.startExecution(workflow, triggerEvent, nil)
+	wm.runExecution(context, workflow, pgExecution, nil)
+}
+
+// startExecution creates the context and execution record of a new run
+func (wm *WorkflowManager) startExecution(workflow *models.ServerWorkflow, triggerEvent map[string]interface{}, retryOf *uuid.UUID) (*models.WorkflowExecutionContext, *models.ServerWorkflowExecution) {
+	context := wm.newExecutionContext(workflow, triggerEvent)
+	executionID := context.ExecutionID
+
 	// Store execution context
 	wm.executionMutex.Lock()
 	wm.executionContext[executionID] = context
 	wm.executionMutex.Unlock()
+	wm.registerRun(executionID)
 
 	log.Debug().
 		Str("execution_id", executionID.String()).
@@ -548,6 +601,11 @@ func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, trig
 		Status:      "RUNNING",
 		TriggerData: triggerEvent,
 		StartedAt:   context.StartedAt,
+		RetryOf:     retryOf,
+	}
+	if workflow.Revision > 0 {
+		revision := workflow.Revision
+		pgExecution.Revision = &revision
 	}
 
 	if err := wm.workflowDB.CreateWorkflowExecution(pgExecution); err != nil {
@@ -559,6 +617,22 @@ func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, trig
 		map[string]interface{}{"trigger_event": triggerEvent},
 		map[string]interface{}{"status": "started"}, nil, 0)
 
+	return context, pgExecution
+}
+
+// runExecution runs the steps of an execution, from the start or from a
+// checkpoint, and records the outcome
+func (wm *WorkflowManager) runExecution(context *models.WorkflowExecutionContext, workflow *models.ServerWorkflow, pgExecution *models.ServerWorkflowExecution, checkpoint *models.WorkflowExecutionCheckpoint) {
+	executionID := context.ExecutionID
+
+	// Clean up execution context
+	defer func() {
+		wm.unregisterRun(executionID)
+		wm.executionMutex.Lock()
+		delete(wm.executionContext, executionID)
+		wm.executionMutex.Unlock()
+	}()
+
 	// Initialize execution summary
 	summary := &models.WorkflowExecutionSummary{
 		ExecutionID:      executionID,
@@ -567,7 +641,7 @@ func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, trig
 		WorkflowName:     workflow.Name,
 		StartedAt:        context.StartedAt,
 		Status:           "RUNNING",
-		TriggerEventType: wm.getTriggerEventType(triggerEvent),
+		TriggerEventType: wm.getTriggerEventType(context.TriggerEvent),
 		TotalSteps:       uint32(len(workflow.Definition.Steps)),
 		CompletedSteps:   0,
 		FailedSteps:      0,
@@ -575,8 +649,49 @@ func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, trig
 		TotalDurationMs:  0,
 	}
 
+	run := wm.runFor(context)
+	if run == nil {
+		run = wm.registerRun(executionID)
+	}
+	run.summary = summary
+
+	var err error
+	startIndex := 0
+	if checkpoint != nil {
+		summary.CompletedSteps = checkpoint.CompletedSteps
+		summary.FailedSteps = checkpoint.FailedSteps
+		summary.SkippedSteps = checkpoint.SkippedSteps
+		startIndex = checkpoint.StepIndex
+
+		// The execution was stopped during a delay step, only the rest of the delay is waited
+		if checkpoint.DelayUntil != nil {
+			run.stepIndex = checkpoint.StepIndex
+			run.stepID = checkpoint.StepID
+			err = wm.sleep(context, time.Until(*checkpoint.DelayUntil))
+			if err == nil {
+				summary.CompletedSteps++
+				startIndex++
+			}
+		}
+	}
+
 	// Execute workflow steps
-	err := wm.executeWorkflowSteps(context, workflow, summary)
+	if err == nil {
+		err = wm.executeWorkflowStepsFrom(context, workflow, summary, startIndex)
+	}
+
+	// A cancelled step may have failed with its own error
+	if runErr := run.err(); runErr != nil {
+		err = runErr
+	}
+
+	if errors.Is(err, errExecutionInterrupted) {
+		log.Info().
+			Str("execution_id", executionID.String()).
+			Str("workflow_id", workflow.ID.String()).
+			Msg("Workflow execution interrupted, it will resume from its checkpoint on the next start")
+		return
+	}
 
 	// Calculate total duration
 	totalDuration := time.Since(context.StartedAt)
@@ -584,7 +699,25 @@ func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, trig
 
 	// Update execution status
 	completedAt := time.Now()
-	if err != nil {
+	if errors.Is(err, errExecutionCancelled) {
+		pgExecution.Status = "CANCELLED"
+		pgExecution.CompletedAt = &completedAt
+		errorMsg := err.Error()
+		pgExecution.ErrorMessage = &errorMsg
+		summary.Status = "CANCELLED"
+		summary.ErrorMessage = &errorMsg
+		summary.CompletedAt = &completedAt
+
+		log.Info().
+			Str("execution_id", executionID.String()).
+			Str("workflow_id", workflow.ID.String()).
+			Msg("Workflow execution cancelled")
+
+		// Log cancellation in ClickHouse
+		wm.logWorkflowStep(context, workflow, "workflow_cancelled", "WORKFLOW", uint32(len(workflow.Definition.Steps)+1), "CANCELLED",
+			map[string]interface{}{},
+			map[string]interface{}{"completed_steps": summary.CompletedSteps, "failed_steps": summary.FailedSteps}, &errorMsg, uint32(totalDuration.Milliseconds()))
+	} else if err != nil {
 		pgExecution.Status = "FAILED"
 		pgExecution.CompletedAt = &completedAt
 		errorMsg := err.Error()
@@ -631,16 +764,18 @@ func (wm *WorkflowManager) executeWorkflow(workflow *models.ServerWorkflow, trig
 			log.Error().Err(err).Str("execution_id", executionID.String()).Msg("Failed to update execution summary in ClickHouse")
 		}
 	}
-
-	// Clean up execution context
-	wm.executionMutex.Lock()
-	delete(wm.executionContext, executionID)
-	wm.executionMutex.Unlock()
 }
 
 // executeWorkflowSteps executes the steps of a workflow
 func (wm *WorkflowManager) executeWorkflowSteps(context *models.WorkflowExecutionContext, workflow *models.ServerWorkflow, summary *models.WorkflowExecutionSummary) error {
-	for i, step := range workflow.Definition.Steps {
+	return wm.executeWorkflowStepsFrom(context, workflow, summary, 0)
+}
+
+// executeWorkflowStepsFrom executes the steps of a workflow starting at startIndex
+func (wm *WorkflowManager) executeWorkflowStepsFrom(context *models.WorkflowExecutionContext, workflow *models.ServerWorkflow, summary *models.WorkflowExecutionSummary, startIndex int) error {
+	for i := startIndex; i < len(workflow.Definition.Steps); i++ {
+		step := workflow.Definition.Steps[i]
+
 		if !step.Enabled {
 			summary.SkippedSteps++
 			continue
@@ -661,6 +796,10 @@ func (wm *WorkflowManager) executeWorkflowSteps(context *models.WorkflowExecutio
 			}
 		}
 
+		if err := wm.checkpointStep(context, i, &step); err != nil {
+			return err
+		}
+
 		context.CurrentStep = step.ID
 		stepStartTime := time.Now()
 
@@ -715,7 +854,9 @@ func (wm *WorkflowManager) executeWorkflowSteps(context *models.WorkflowExecutio
 
 						// Wait before retry (except for first attempt)
 						if retry > 0 {
-							time.Sleep(retryDelay)
+							if err := wm.sleep(context, retryDelay); err != nil {
+								return err
+							}
 						}
 
 						// Log retry attempt in ClickHouse
@@ -823,7 +964,9 @@ func (wm *WorkflowManager) executeWorkflowSteps(context *models.WorkflowExecutio
 
 					// Wait before retry (except for first attempt)
 					if retry > 0 {
-						time.Sleep(retryDelay)
+						if err := wm.sleep(context, retryDelay); err != nil {
+							return err
+						}
 					}
 
 					// Log retry attempt in ClickHouse
@@ -936,6 +1079,10 @@ func (wm *WorkflowManager) executeWorkflowFromStep(context *models.WorkflowExecu
 			continue
 		}
 
+		if err := wm.checkpointStep(context, i, &step); err != nil {
+			return err
+		}
+
 		context.CurrentStep = step.ID
 		stepStartTime := time.Now()
 
@@ -1078,6 +1225,12 @@ func (wm *WorkflowManager) executeStep(context *models.WorkflowExecutionContext,
 		return wm.executeVariableStep(context, step)
 	case models.StepTypeDelay:
 		return wm.executeDelayStep(context, step)
+	case models.StepTypeLoop:
+		return wm.executeLoopStep(context, step, workflow)
+	case models.StepTypeParallel:
+		return wm.executeParallelStep(context, step, workflow)
+	case models.StepTypeLua:
+		return wm.executeLuaStep(context, step)
 	default:
 		return fmt.Errorf("unsupported step type: %s", step.Type)
 	}
@@ -1139,7 +1292,7 @@ func (wm *WorkflowManager) executeRconAction(context *models.WorkflowExecutionCo
 		Msg("Executing RCON command")
 
 	// Execute RCON command
-	response, err := wm.rconManager.ExecuteCommand(context.ServerID, command)
+	response, err := wm.executeRconCommand(context, command)
 	if err != nil {
 		return fmt.Errorf("failed to execute RCON command: %w", err)
 	}
@@ -1219,7 +1372,7 @@ func (wm *WorkflowManager) executeAdminBroadcastAction(context *models.WorkflowE
 
 	// Execute admin broadcast command
 	command := fmt.Sprintf("AdminBroadcast %s", message)
-	response, err := wm.rconManager.ExecuteCommand(context.ServerID, command)
+	response, err := wm.executeRconCommand(context, command)
 	if err != nil {
 		return fmt.Errorf("failed to execute admin broadcast: %w", err)
 	}
@@ -1259,7 +1412,7 @@ func (wm *WorkflowManager) executeChatMessageAction(context *models.WorkflowExec
 
 	// Execute chat message command
 	command := fmt.Sprintf("AdminChatMessage \"%s\" %s", targetPlayer, message)
-	response, err := wm.rconManager.ExecuteCommand(context.ServerID, command)
+	response, err := wm.executeRconCommand(context, command)
 	if err != nil {
 		return fmt.Errorf("failed to send chat message: %w", err)
 	}
@@ -1300,7 +1453,7 @@ func (wm *WorkflowManager) executeKickPlayerAction(context *models.WorkflowExecu
 
 	// Execute kick command
 	command := fmt.Sprintf("AdminKick \"%s\" %s", playerId, reason)
-	response, err := wm.rconManager.ExecuteCommand(context.ServerID, command)
+	response, err := wm.executeRconCommand(context, command)
 	if err != nil {
 		return fmt.Errorf("failed to kick player: %w", err)
 	}
@@ -1347,7 +1500,7 @@ func (wm *WorkflowManager) executeBanPlayerAction(context *models.WorkflowExecut
 
 	// Execute ban command (duration in days)
 	command := fmt.Sprintf("AdminBan \"%s\" %.0f %s", playerId, duration, reason)
-	response, err := wm.rconManager.ExecuteCommand(context.ServerID, command)
+	response, err := wm.executeRconCommand(context, command)
 	if err != nil {
 		return fmt.Errorf("failed to ban player: %w", err)
 	}
@@ -1416,12 +1569,7 @@ func (wm *WorkflowManager) executeBanPlayerWithEvidenceAction(context *models.Wo
 		Msg("Banning player with evidence")
 
 	// Extract evidence from trigger event
-	var evidenceItems []struct {
-		EvidenceType    string
-		ClickhouseTable string
-		RecordID        string
-		EventTime       time.Time
-	}
+	var evidenceItems []banEvidence
 
 	if eventID, ok := context.TriggerEvent["event_id"].(string); ok {
 		eventType, _ := context.TriggerEvent["event_type"].(string)
@@ -1438,12 +1586,7 @@ func (wm *WorkflowManager) executeBanPlayerWithEvidenceAction(context *models.Wo
 				eventTime = time.Now()
 			}
 
-			evidenceItems = append(evidenceItems, struct {
-				EvidenceType    string
-				ClickhouseTable string
-				RecordID        string
-				EventTime       time.Time
-			}{
+			evidenceItems = append(evidenceItems, banEvidence{
 				EvidenceType:    evidenceType,
 				ClickhouseTable: clickhouseTable,
 				RecordID:        eventID,
@@ -1465,77 +1608,20 @@ func (wm *WorkflowManager) executeBanPlayerWithEvidenceAction(context *models.Wo
 		return fmt.Errorf("invalid steam ID format: %w", err)
 	}
 
-	// Start transaction
-	tx, err := wm.db.BeginTx(wm.ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	now := time.Now()
-
-	// Insert ban
-	var banQuery string
-	var banArgs []interface{}
-
-	if ruleID != "" {
-		ruleUUID, err := uuid.Parse(ruleID)
-		if err != nil {
-			return fmt.Errorf("invalid rule ID format: %w", err)
-		}
-		banQuery = `INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, rule_id, created_at, updated_at) VALUES ($1, $2, NULL, $3, $4, $5, $6, $7, $8)`
-		banArgs = []interface{}{banID, context.ServerID, steamID, reason, int(duration), ruleUUID, now, now}
-	} else {
-		banQuery = `INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, created_at, updated_at) VALUES ($1, $2, NULL, $3, $4, $5, $6, $7)`
-		banArgs = []interface{}{banID, context.ServerID, steamID, reason, int(duration), now, now}
-	}
-
-	_, err = tx.ExecContext(wm.ctx, banQuery, banArgs...)
-	if err != nil {
-		return fmt.Errorf("failed to create ban: %w", err)
-	}
-
-	// Insert evidence records
-	for _, ev := range evidenceItems {
-		metadata := map[string]interface{}{
-			"event_type": context.TriggerEvent["event_type"],
-			"event_id":   ev.RecordID,
-		}
-		metadataJSON, _ := json.Marshal(metadata)
-
-		evidenceQuery := `INSERT INTO ban_evidence (id, ban_id, evidence_type, clickhouse_table, record_id, server_id, event_time, metadata, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
-		_, err = tx.ExecContext(wm.ctx, evidenceQuery,
-			uuid.New(),
-			banID.String(),
-			ev.EvidenceType,
-			ev.ClickhouseTable,
-			ev.RecordID,
-			context.ServerID,
-			ev.EventTime,
-			metadataJSON,
-			now,
-			now,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to insert evidence: %w", err)
-		}
-	}
-
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if err := wm.insertBanWithEvidence(context, banID, steamID, reason, duration, ruleID, evidenceItems); err != nil {
+		return err
 	}
 
 	// Execute RCON ban
 	command := fmt.Sprintf("AdminBan \"%s\" %.0f %s", playerId, duration, reason)
-	response, err := wm.rconManager.ExecuteCommand(context.ServerID, command)
+	response, err := wm.executeRconCommand(context, command)
 	if err != nil {
 		log.Error().Err(err).Str("banID", banID.String()).Msg("RCON ban failed but database ban created")
 	}
 
 	// Kick player
 	kickCommand := fmt.Sprintf("AdminKick \"%s\" %s", playerId, reason)
-	_, kickErr := wm.rconManager.ExecuteCommand(context.ServerID, kickCommand)
+	_, kickErr := wm.executeRconCommand(context, kickCommand)
 
 	// Store results
 	context.StepResults[step.ID] = map[string]interface{}{
@@ -1578,7 +1664,7 @@ func (wm *WorkflowManager) executeWarnPlayerAction(context *models.WorkflowExecu
 
 	// Execute warn command
 	command := fmt.Sprintf("AdminWarn \"%s\" %s", playerId, message)
-	response, err := wm.rconManager.ExecuteCommand(context.ServerID, command)
+	response, err := wm.executeRconCommand(context, command)
 	if err != nil {
 		return fmt.Errorf("failed to warn player: %w", err)
 	}
@@ -1629,7 +1715,7 @@ func (wm *WorkflowManager) executeHTTPRequestAction(context *models.WorkflowExec
 		}
 	}
 
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(wm.executionCtx(context), method, url, body)
 	if err != nil {
 		return fmt.Errorf("failed to create HTTP request: %w", err)
 	}
@@ -1647,7 +1733,7 @@ func (wm *WorkflowManager) executeHTTPRequestAction(context *models.WorkflowExec
 	}
 
 	// Execute request
-	resp, err := client.Do(req)
+	resp, err := wm.doHTTPRequest(context, client, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute HTTP request: %w", err)
 	}
@@ -1730,7 +1816,7 @@ func (wm *WorkflowManager) executeWebhookAction(context *models.WorkflowExecutio
 	}
 
 	// Create POST request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(wm.executionCtx(context), "POST", url, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("failed to create webhook request: %w", err)
 	}
@@ -1750,7 +1836,7 @@ func (wm *WorkflowManager) executeWebhookAction(context *models.WorkflowExecutio
 	}
 
 	// Execute request
-	resp, err := client.Do(req)
+	resp, err := wm.doHTTPRequest(context, client, req)
 	if err != nil {
 		return fmt.Errorf("failed to execute webhook: %w", err)
 	}
@@ -1827,7 +1913,7 @@ func (wm *WorkflowManager) executeDiscordMessageAction(context *models.WorkflowE
 	}
 
 	// Create POST request
-	req, err := http.NewRequest("POST", webhookUrl, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(wm.executionCtx(context), "POST", webhookUrl, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("failed to create Discord request: %w", err)
 	}
@@ -1835,7 +1921,7 @@ func (wm *WorkflowManager) executeDiscordMessageAction(context *models.WorkflowE
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
-	resp, err := client.Do(req)
+	resp, err := wm.doHTTPRequest(context, client, req)
 	if err != nil {
 		return fmt.Errorf("failed to send Discord message: %w", err)
 	}
@@ -2715,8 +2801,19 @@ func (wm *WorkflowManager) executeDelayStep(context *models.WorkflowExecutionCon
 		Dur("duration", duration).
 		Msg("Executing delay step")
 
-	time.Sleep(duration)
-	return nil
+	// Simulations report the delay without waiting for it
+	if wm.simulationFor(context) != nil {
+		context.StepResults[step.ID] = map[string]interface{}{"delay_ms": delayMs, "simulated": true}
+		return nil
+	}
+
+	// A restart during a top-level delay resumes with only the remaining time
+	if run := wm.runFor(context); run != nil && run.stepID == step.ID {
+		delayUntil := time.Now().Add(duration)
+		wm.saveCheckpoint(context, run, &delayUntil)
+	}
+
+	return wm.sleep(context, duration)
 }
 
 // replaceVariablesWithContext replaces variable placeholders with access to trigger_event and metadata
@@ -2839,7 +2936,7 @@ func (wm *WorkflowManager) DebugStatus() map[string]interface{} {
 		workflowsByServer[serverID] = append(workflowsByServer[serverID], workflow.Name)
 
 		for _, trigger := range workflow.Definition.Triggers {
-			if trigger.Enabled {
+			if trigger.Enabled && !trigger.IsScheduled() {
 				if triggersByEventType[trigger.EventType] == nil {
 					triggersByEventType[trigger.EventType] = []string{}
 				}
@@ -2856,6 +2953,7 @@ func (wm *WorkflowManager) DebugStatus() map[string]interface{} {
 		"subscriber_id":            wm.subscriber.ID.String(),
 		"workflows_by_server":      workflowsByServer,
 		"triggers_by_event_type":   triggersByEventType,
+		"scheduled_triggers":       wm.GetScheduledTriggers(),
 	}
 }
 
@@ -2883,6 +2981,11 @@ func (wm *WorkflowManager) logWorkflowStep(
 	stepError *string,
 	stepDurationMs uint32,
 ) {
+	if sim := wm.simulationFor(context); sim != nil {
+		sim.recordStep(context, stepName, stepType, stepOrder, stepStatus, stepInput, stepOutput, stepError, stepDurationMs)
+		return
+	}
+
 	if wm.clickhouseClient == nil {
 		return
 	}
@@ -2905,7 +3008,8 @@ func (wm *WorkflowManager) logWorkflowStep(
 		StepDurationMs:   stepDurationMs,
 		Variables:        context.Variables,
 		Metadata: map[string]interface{}{
-			"workflow_name": workflow.Name,
+			"workflow_name":     workflow.Name,
+			"workflow_revision": workflow.Revision,
 		},
 	}
 
@@ -2948,54 +3052,57 @@ func (wm *WorkflowManager) executeLuaAction(context *models.WorkflowExecutionCon
 	return wm.executeLuaScript(context, step, script)
 }
 
-// executeLuaScript executes a LUA script with access to workflow context
-func (wm *WorkflowManager) executeLuaScript(workflowContext *models.WorkflowExecutionContext, step *models.WorkflowStep, script string) error {
-	L := lua.NewState()
-	defer L.Close()
+// executeLuaScript executes a LUA script with access to workflow context. The
+// script runs in a sandboxed state under the workflow's Lua limits and its
+// resource use is added to the step result as lua_metrics.
+func (wm *WorkflowManager) executeLuaScript(workflowContext *models.WorkflowExecutionContext, step *models.WorkflowStep, script string) (err error) {
+	limits := resolveLuaLimits(workflowContext.LuaLimits, step)
 
-	// Set timeout for script execution
-	timeout := 30 * time.Second
-	if timeoutConfig, ok := step.Config["timeout_seconds"].(float64); ok && timeoutConfig > 0 {
-		timeout = time.Duration(timeoutConfig) * time.Second
-	}
-
-	// Create a context with timeout
-	scriptCtx, cancel := context.WithTimeout(wm.ctx, timeout)
-	defer cancel()
+	L := newSandboxedLuaState(limits)
+	defer L.Close()
 
 	// Set up the LUA environment with workflow data
 	if err := wm.setupLuaEnvironment(L, workflowContext, step); err != nil {
 		return fmt.Errorf("failed to setup LUA environment: %w", err)
 	}
 
-	// Execute the script in a goroutine to handle timeout
-	scriptError := make(chan error, 1)
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				scriptError <- fmt.Errorf("LUA script panicked: %v", r)
-			}
-		}()
+	budget := newLuaBudget(wm.executionCtx(workflowContext), L, limits)
+	defer budget.cancel()
+	budget.guardAllocations()
+	L.SetContext(budget)
 
-		if err := L.DoString(script); err != nil {
-			scriptError <- fmt.Errorf("LUA script execution failed: %w", err)
-		} else {
-			scriptError <- nil
+	startTime := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("LUA script panicked: %v", r)
 		}
 	}()
 
-	// Wait for script completion or timeout
-	select {
-	case err := <-scriptError:
-		if err != nil {
-			return err
+	scriptErr := L.DoString(script)
+	L.RemoveContext()
+	budget.measureMemory()
+	metrics := budget.metrics(time.Since(startTime))
+
+	if scriptErr != nil {
+		if budget.Err() != nil {
+			wm.logWorkflowMessage(workflowContext, step, "ERROR",
+				fmt.Sprintf("LUA script stopped: %v (%d instructions, %d KB peak memory, %d ms)",
+					budget.Err(), metrics["instructions"], metrics["peak_memory_kb"], metrics["duration_ms"]))
+			return fmt.Errorf("LUA script stopped: %w", budget.Err())
 		}
-	case <-scriptCtx.Done():
-		return fmt.Errorf("LUA script execution timed out after %v", timeout)
+		return fmt.Errorf("LUA script execution failed: %w", scriptErr)
 	}
 
 	// Extract results from LUA state
-	return wm.extractLuaResults(L, workflowContext, step)
+	if err := wm.extractLuaResults(L, workflowContext, step); err != nil {
+		return err
+	}
+
+	if result, ok := workflowContext.StepResults[step.ID].(map[string]interface{}); ok {
+		result["lua_metrics"] = metrics
+	}
+
+	return nil
 }
 
 // setupLuaEnvironment sets up the LUA environment with workflow context and utilities
@@ -3087,6 +3194,11 @@ func (wm *WorkflowManager) logWorkflowMessage(workflowContext *models.WorkflowEx
 		logger.Info().Msg(message)
 	}
 
+	if sim := wm.simulationFor(workflowContext); sim != nil {
+		sim.recordMessage(step, level, message)
+		return
+	}
+
 	// Log to ClickHouse if client is available
 	if wm.clickhouseClient != nil {
 		logMsg := &models.WorkflowLogMessage{
@@ -3238,7 +3350,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 			Msg("LUA script executing RCON command")
 
 		// Execute RCON command
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -3270,7 +3382,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 			Str("reason", reason).
 			Msg("LUA script kicking player")
 
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			L.Push(lua.LBool(false))
 			L.Push(lua.LString(err.Error()))
@@ -3299,7 +3411,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 			Str("reason", reason).
 			Msg("LUA script banning player")
 
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			L.Push(lua.LBool(false))
 			L.Push(lua.LString(err.Error()))
@@ -3326,12 +3438,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 		}
 
 		// Extract evidence from trigger event
-		var evidenceItems []struct {
-			EvidenceType    string
-			ClickhouseTable string
-			RecordID        string
-			EventTime       time.Time
-		}
+		var evidenceItems []banEvidence
 
 		if eventID, ok := workflowContext.TriggerEvent["event_id"].(string); ok {
 			eventType, _ := workflowContext.TriggerEvent["event_type"].(string)
@@ -3348,12 +3455,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 					eventTime = time.Now()
 				}
 
-				evidenceItems = append(evidenceItems, struct {
-					EvidenceType    string
-					ClickhouseTable string
-					RecordID        string
-					EventTime       time.Time
-				}{
+				evidenceItems = append(evidenceItems, banEvidence{
 					EvidenceType:    evidenceType,
 					ClickhouseTable: clickhouseTable,
 					RecordID:        eventID,
@@ -3375,74 +3477,9 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 			return 2
 		}
 
-		// Start transaction
-		tx, err := wm.db.BeginTx(wm.ctx, nil)
-		if err != nil {
+		if err := wm.insertBanWithEvidence(workflowContext, banID, steamIDInt, reason, float64(duration), ruleID, evidenceItems); err != nil {
 			L.Push(lua.LNil)
-			L.Push(lua.LString(fmt.Sprintf("failed to begin transaction: %v", err)))
-			return 2
-		}
-		defer tx.Rollback()
-
-		now := time.Now()
-
-		// Insert ban
-		var banQuery string
-		var banArgs []interface{}
-
-		if ruleID != "" {
-			ruleUUID, err := uuid.Parse(ruleID)
-			if err != nil {
-				L.Push(lua.LNil)
-				L.Push(lua.LString(fmt.Sprintf("invalid rule ID format: %v", err)))
-				return 2
-			}
-			banQuery = `INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, rule_id, created_at, updated_at) VALUES ($1, $2, NULL, $3, $4, $5, $6, $7, $8)`
-			banArgs = []interface{}{banID, workflowContext.ServerID, steamIDInt, reason, int(duration), ruleUUID, now, now}
-		} else {
-			banQuery = `INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, created_at, updated_at) VALUES ($1, $2, NULL, $3, $4, $5, $6, $7)`
-			banArgs = []interface{}{banID, workflowContext.ServerID, steamIDInt, reason, int(duration), now, now}
-		}
-
-		_, err = tx.ExecContext(wm.ctx, banQuery, banArgs...)
-		if err != nil {
-			L.Push(lua.LNil)
-			L.Push(lua.LString(fmt.Sprintf("failed to create ban: %v", err)))
-			return 2
-		}
-
-		// Insert evidence records
-		for _, ev := range evidenceItems {
-			metadata := map[string]interface{}{
-				"event_type": workflowContext.TriggerEvent["event_type"],
-				"event_id":   ev.RecordID,
-			}
-			metadataJSON, _ := json.Marshal(metadata)
-
-			evidenceQuery := `INSERT INTO ban_evidence (id, ban_id, evidence_type, clickhouse_table, record_id, server_id, event_time, metadata, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
-			_, err = tx.ExecContext(wm.ctx, evidenceQuery,
-				uuid.New(),
-				banID.String(),
-				ev.EvidenceType,
-				ev.ClickhouseTable,
-				ev.RecordID,
-				workflowContext.ServerID,
-				ev.EventTime,
-				metadataJSON,
-				now,
-				now,
-			)
-			if err != nil {
-				L.Push(lua.LNil)
-				L.Push(lua.LString(fmt.Sprintf("failed to insert evidence: %v", err)))
-				return 2
-			}
-		}
-
-		// Commit transaction
-		if err := tx.Commit(); err != nil {
-			L.Push(lua.LNil)
-			L.Push(lua.LString(fmt.Sprintf("failed to commit transaction: %v", err)))
+			L.Push(lua.LString(err.Error()))
 			return 2
 		}
 
@@ -3457,14 +3494,14 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 
 		// Execute RCON ban
 		command := fmt.Sprintf("AdminBan \"%s\" %.0f %s", steamID, float64(duration), reason)
-		_, err = wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		_, err = wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			log.Error().Err(err).Str("banID", banID.String()).Msg("RCON ban failed but database ban created")
 		}
 
 		// Kick player
 		kickCommand := fmt.Sprintf("AdminKick \"%s\" %s", steamID, reason)
-		_, _ = wm.rconManager.ExecuteCommand(workflowContext.ServerID, kickCommand)
+		_, _ = wm.executeRconCommand(workflowContext, kickCommand)
 
 		// Return ban ID and nil error
 		L.Push(lua.LString(banID.String()))
@@ -3488,7 +3525,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 			Str("message", message).
 			Msg("LUA script warning player")
 
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			L.Push(lua.LBool(false))
 			L.Push(lua.LString(err.Error()))
@@ -3512,7 +3549,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 			Str("message", message).
 			Msg("LUA script broadcasting message")
 
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			L.Push(lua.LBool(false))
 			L.Push(lua.LString(err.Error()))
@@ -3531,7 +3568,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 		key := L.CheckString(1)
 		defaultValue := L.Get(2) // Optional default value
 
-		value, err := wm.workflowDB.GetKVValue(workflowContext.WorkflowID, key)
+		value, err := wm.kvStore(workflowContext).GetKVValue(workflowContext.WorkflowID, key)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				// Key doesn't exist, return default value
@@ -3568,7 +3605,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 
 		goValue := wm.convertFromLuaValue(value)
 
-		err := wm.workflowDB.SetKVValue(workflowContext.WorkflowID, key, goValue)
+		err := wm.kvStore(workflowContext).SetKVValue(workflowContext.WorkflowID, key, goValue)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -3595,7 +3632,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 	L.SetField(kvTable, "delete", L.NewFunction(func(L *lua.LState) int {
 		key := L.CheckString(1)
 
-		err := wm.workflowDB.DeleteKVValue(workflowContext.WorkflowID, key)
+		err := wm.kvStore(workflowContext).DeleteKVValue(workflowContext.WorkflowID, key)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -3621,7 +3658,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 	L.SetField(kvTable, "exists", L.NewFunction(func(L *lua.LState) int {
 		key := L.CheckString(1)
 
-		exists, err := wm.workflowDB.KVExists(workflowContext.WorkflowID, key)
+		exists, err := wm.kvStore(workflowContext).KVExists(workflowContext.WorkflowID, key)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -3637,7 +3674,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 		return 1
 	}))
 	L.SetField(kvTable, "keys", L.NewFunction(func(L *lua.LState) int {
-		keys, err := wm.workflowDB.ListKVKeys(workflowContext.WorkflowID)
+		keys, err := wm.kvStore(workflowContext).ListKVKeys(workflowContext.WorkflowID)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -3658,7 +3695,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 		return 1
 	}))
 	L.SetField(kvTable, "get_all", L.NewFunction(func(L *lua.LState) int {
-		kvPairs, err := wm.workflowDB.GetAllKVPairs(workflowContext.WorkflowID)
+		kvPairs, err := wm.kvStore(workflowContext).GetAllKVPairs(workflowContext.WorkflowID)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -3679,7 +3716,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 		return 1
 	}))
 	L.SetField(kvTable, "clear", L.NewFunction(func(L *lua.LState) int {
-		err := wm.workflowDB.ClearKVStore(workflowContext.WorkflowID)
+		err := wm.kvStore(workflowContext).ClearKVStore(workflowContext.WorkflowID)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -3701,7 +3738,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 		return 2 // Return success boolean and error
 	}))
 	L.SetField(kvTable, "count", L.NewFunction(func(L *lua.LState) int {
-		count, err := wm.workflowDB.CountKVPairs(workflowContext.WorkflowID)
+		count, err := wm.kvStore(workflowContext).CountKVPairs(workflowContext.WorkflowID)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -3720,7 +3757,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 		delta := L.OptNumber(2, 1) // Default increment by 1
 
 		// Get current value
-		value, err := wm.workflowDB.GetKVValue(workflowContext.WorkflowID, key)
+		value, err := wm.kvStore(workflowContext).GetKVValue(workflowContext.WorkflowID, key)
 		var currentNum float64
 		if err != nil {
 			if err == sql.ErrNoRows {
@@ -3757,7 +3794,7 @@ func (wm *WorkflowManager) addLuaUtilityFunctions(L *lua.LState, workflowTable *
 		newNum := currentNum + float64(delta)
 
 		// Save back
-		err = wm.workflowDB.SetKVValue(workflowContext.WorkflowID, key, newNum)
+		err = wm.kvStore(workflowContext).SetKVValue(workflowContext.WorkflowID, key, newNum)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -3883,7 +3920,7 @@ func (wm *WorkflowManager) addLuaBackwardCompatibilityFunctions(L *lua.LState, w
 	L.SetGlobal("rcon_execute", L.NewFunction(func(L *lua.LState) int {
 		command := L.CheckString(1)
 		command = wm.replaceVariablesWithContext(command, workflowContext.Variables, workflowContext.TriggerEvent, workflowContext.Metadata)
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			L.Push(lua.LNil)
 			L.Push(lua.LString(err.Error()))
@@ -3901,7 +3938,7 @@ func (wm *WorkflowManager) addLuaBackwardCompatibilityFunctions(L *lua.LState, w
 		playerId = wm.replaceVariablesWithContext(playerId, workflowContext.Variables, workflowContext.TriggerEvent, workflowContext.Metadata)
 		reason = wm.replaceVariablesWithContext(reason, workflowContext.Variables, workflowContext.TriggerEvent, workflowContext.Metadata)
 		command := fmt.Sprintf("AdminKick \"%s\" %s", playerId, reason)
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			L.Push(lua.LBool(false))
 			L.Push(lua.LString(err.Error()))
@@ -3920,7 +3957,7 @@ func (wm *WorkflowManager) addLuaBackwardCompatibilityFunctions(L *lua.LState, w
 		playerId = wm.replaceVariablesWithContext(playerId, workflowContext.Variables, workflowContext.TriggerEvent, workflowContext.Metadata)
 		reason = wm.replaceVariablesWithContext(reason, workflowContext.Variables, workflowContext.TriggerEvent, workflowContext.Metadata)
 		command := fmt.Sprintf("AdminBan \"%s\" %.0f %s", playerId, float64(duration), reason)
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			L.Push(lua.LBool(false))
 			L.Push(lua.LString(err.Error()))
@@ -3938,7 +3975,7 @@ func (wm *WorkflowManager) addLuaBackwardCompatibilityFunctions(L *lua.LState, w
 		playerId = wm.replaceVariablesWithContext(playerId, workflowContext.Variables, workflowContext.TriggerEvent, workflowContext.Metadata)
 		message = wm.replaceVariablesWithContext(message, workflowContext.Variables, workflowContext.TriggerEvent, workflowContext.Metadata)
 		command := fmt.Sprintf("AdminWarn \"%s\" %s", playerId, message)
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			L.Push(lua.LBool(false))
 			L.Push(lua.LString(err.Error()))
@@ -3954,7 +3991,7 @@ func (wm *WorkflowManager) addLuaBackwardCompatibilityFunctions(L *lua.LState, w
 		message := L.CheckString(1)
 		message = wm.replaceVariablesWithContext(message, workflowContext.Variables, workflowContext.TriggerEvent, workflowContext.Metadata)
 		command := fmt.Sprintf("AdminBroadcast %s", message)
-		response, err := wm.rconManager.ExecuteCommand(workflowContext.ServerID, command)
+		response, err := wm.executeRconCommand(workflowContext, command)
 		if err != nil {
 			L.Push(lua.LBool(false))
 			L.Push(lua.LString(err.Error()))
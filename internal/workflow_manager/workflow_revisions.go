@@ -0,0 +1,286 @@
+package workflow_manager
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// insertWorkflowRevision stores the current state of a workflow as an immutable revision
+func insertWorkflowRevision(tx *sql.Tx, workflow *models.ServerWorkflow, definitionJSON []byte, createdBy uuid.UUID, changeSummary *string, restoredFrom *int) error {
+	query := `
+		INSERT INTO server_workflow_revisions (id, workflow_id, revision, name, description, enabled, definition, change_summary, restored_from, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := tx.Exec(query,
+		uuid.New(),
+		workflow.ID,
+		workflow.Revision,
+		workflow.Name,
+		workflow.Description,
+		workflow.Enabled,
+		definitionJSON,
+		changeSummary,
+		restoredFrom,
+		createdBy,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create workflow revision: %w", err)
+	}
+
+	return nil
+}
+
+// scanWorkflowRevision scans a revision row
+func scanWorkflowRevision(scanner interface{ Scan(...interface{}) error }) (*models.ServerWorkflowRevision, error) {
+	var revision models.ServerWorkflowRevision
+	var definitionJSON []byte
+	var description, changeSummary sql.NullString
+	var restoredFrom sql.NullInt64
+
+	err := scanner.Scan(
+		&revision.ID,
+		&revision.WorkflowID,
+		&revision.Revision,
+		&revision.Name,
+		&description,
+		&revision.Enabled,
+		&definitionJSON,
+		&changeSummary,
+		&restoredFrom,
+		&revision.CreatedBy,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		revision.Description = &description.String
+	}
+	if changeSummary.Valid {
+		revision.ChangeSummary = &changeSummary.String
+	}
+	if restoredFrom.Valid {
+		from := int(restoredFrom.Int64)
+		revision.RestoredFrom = &from
+	}
+
+	if err := json.Unmarshal(definitionJSON, &revision.Definition); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal definition of revision %d: %w", revision.Revision, err)
+	}
+
+	return &revision, nil
+}
+
+// GetWorkflowRevisions returns all revisions of a workflow, newest first, each
+// with its changes from the previous revision
+func (wd *WorkflowDatabase) GetWorkflowRevisions(workflowID uuid.UUID) ([]models.ServerWorkflowRevision, error) {
+	query := `
+		SELECT id, workflow_id, revision, name, description, enabled, definition, change_summary, restored_from, created_by, created_at
+		FROM server_workflow_revisions
+		WHERE workflow_id = $1
+		ORDER BY revision DESC
+	`
+
+	rows, err := wd.db.Query(query, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.ServerWorkflowRevision{}
+	for rows.Next() {
+		revision, err := scanWorkflowRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i+1 < len(revisions); i++ {
+		revisions[i].Changes = DiffWorkflowRevisions(&revisions[i+1], &revisions[i])
+	}
+
+	return revisions, nil
+}
+
+// GetWorkflowRevision returns a single revision of a workflow
+func (wd *WorkflowDatabase) GetWorkflowRevision(workflowID uuid.UUID, revision int) (*models.ServerWorkflowRevision, error) {
+	query := `
+		SELECT id, workflow_id, revision, name, description, enabled, definition, change_summary, restored_from, created_by, created_at
+		FROM server_workflow_revisions
+		WHERE workflow_id = $1 AND revision = $2
+	`
+
+	return scanWorkflowRevision(wd.db.QueryRow(query, workflowID, revision))
+}
+
+// RestoreWorkflowRevision rolls a workflow back to an earlier revision. The
+// restored state is saved as a new revision so history is never rewritten.
+func (wd *WorkflowDatabase) RestoreWorkflowRevision(workflow *models.ServerWorkflow, revision *models.ServerWorkflowRevision, restoredBy uuid.UUID, changeSummary *string) error {
+	workflow.Name = revision.Name
+	workflow.Description = revision.Description
+	workflow.Enabled = revision.Enabled
+	workflow.Definition = revision.Definition
+	workflow.UpdatedAt = time.Now()
+
+	if changeSummary == nil {
+		summary := fmt.Sprintf("Rolled back to revision %d", revision.Revision)
+		changeSummary = &summary
+	}
+
+	restoredFrom := revision.Revision
+	return wd.saveWorkflow(workflow, restoredBy, changeSummary, &restoredFrom)
+}
+
+// DiffWorkflowRevisions lists the differences between two revisions. Arrays of
+// objects with an "id" field, like steps and triggers, are matched by ID so
+// reordering or inserting a step doesn't report every later step as changed.
+func DiffWorkflowRevisions(from, to *models.ServerWorkflowRevision) []models.WorkflowChange {
+	changes := []models.WorkflowChange{}
+	diffValues("", revisionDocument(from), revisionDocument(to), &changes)
+	return changes
+}
+
+// revisionDocument converts the diffable fields of a revision to plain JSON values
+func revisionDocument(revision *models.ServerWorkflowRevision) interface{} {
+	data, _ := json.Marshal(map[string]interface{}{
+		"name":        revision.Name,
+		"description": revision.Description,
+		"enabled":     revision.Enabled,
+		"definition":  revision.Definition,
+	})
+
+	var document interface{}
+	_ = json.Unmarshal(data, &document)
+	return document
+}
+
+// joinPath appends a key to a dotted path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// diffValues appends the differences between two JSON values
+func diffValues(path string, oldValue, newValue interface{}, changes *[]models.WorkflowChange) {
+	switch {
+	case oldValue == nil && newValue == nil:
+		return
+	case oldValue == nil:
+		*changes = append(*changes, models.WorkflowChange{Path: path, Type: models.WorkflowChangeAdded, NewValue: newValue})
+		return
+	case newValue == nil:
+		*changes = append(*changes, models.WorkflowChange{Path: path, Type: models.WorkflowChangeRemoved, OldValue: oldValue})
+		return
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make(map[string]struct{}, len(oldMap)+len(newMap))
+		for key := range oldMap {
+			keys[key] = struct{}{}
+		}
+		for key := range newMap {
+			keys[key] = struct{}{}
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			diffValues(joinPath(path, key), oldMap[key], newMap[key], changes)
+		}
+		return
+	}
+
+	oldSlice, oldIsSlice := oldValue.([]interface{})
+	newSlice, newIsSlice := newValue.([]interface{})
+	if oldIsSlice && newIsSlice {
+		if oldByID, ok := indexByID(oldSlice); ok {
+			if newByID, ok := indexByID(newSlice); ok {
+				diffByID(path, oldSlice, newSlice, oldByID, newByID, changes)
+				return
+			}
+		}
+
+		length := len(oldSlice)
+		if len(newSlice) > length {
+			length = len(newSlice)
+		}
+		for i := 0; i < length; i++ {
+			var oldItem, newItem interface{}
+			if i < len(oldSlice) {
+				oldItem = oldSlice[i]
+			}
+			if i < len(newSlice) {
+				newItem = newSlice[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), oldItem, newItem, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*changes = append(*changes, models.WorkflowChange{Path: path, Type: models.WorkflowChangeChanged, OldValue: oldValue, NewValue: newValue})
+	}
+}
+
+// indexByID maps the "id" field of every element to its index. It fails if
+// any element is not an object with a unique string ID.
+func indexByID(items []interface{}) (map[string]int, bool) {
+	index := make(map[string]int, len(items))
+	for i, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		id, ok := object["id"].(string)
+		if !ok || id == "" {
+			return nil, false
+		}
+		if _, exists := index[id]; exists {
+			return nil, false
+		}
+		index[id] = i
+	}
+	return index, true
+}
+
+// diffByID compares array elements with the same ID
+func diffByID(path string, oldSlice, newSlice []interface{}, oldByID, newByID map[string]int, changes *[]models.WorkflowChange) {
+	for _, item := range oldSlice {
+		id := item.(map[string]interface{})["id"].(string)
+		itemPath := fmt.Sprintf("%s[id=%s]", path, id)
+		if newIndex, ok := newByID[id]; ok {
+			diffValues(itemPath, item, newSlice[newIndex], changes)
+		} else {
+			diffValues(itemPath, item, nil, changes)
+		}
+	}
+
+	for _, item := range newSlice {
+		id := item.(map[string]interface{})["id"].(string)
+		if _, ok := oldByID[id]; !ok {
+			diffValues(fmt.Sprintf("%s[id=%s]", path, id), nil, item, changes)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package workflow_manager
+
+import (
+	"testing"
+
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func TestDiffWorkflowRevisions(t *testing.T) {
+	from := &models.ServerWorkflowRevision{
+		Revision: 1,
+		Name:     "Warn teamkillers",
+		Enabled:  true,
+		Definition: models.WorkflowDefinition{
+			Steps: []models.WorkflowStep{
+				{ID: "warn", Name: "Warn", Type: models.StepTypeAction, Enabled: true, Config: map[string]interface{}{"message": "Stop"}},
+				{ID: "log", Name: "Log", Type: models.StepTypeAction, Enabled: true},
+			},
+		},
+	}
+
+	to := &models.ServerWorkflowRevision{
+		Revision: 2,
+		Name:     "Warn teamkillers",
+		Enabled:  false,
+		Definition: models.WorkflowDefinition{
+			Steps: []models.WorkflowStep{
+				{ID: "kick", Name: "Kick", Type: models.StepTypeAction, Enabled: true},
+				{ID: "warn", Name: "Warn", Type: models.StepTypeAction, Enabled: true, Config: map[string]interface{}{"message": "Stop teamkilling"}},
+			},
+		},
+	}
+
+	changes := DiffWorkflowRevisions(from, to)
+
+	expected := map[string]string{
+		"enabled": models.WorkflowChangeChanged,
+		"definition.steps[id=warn].config.message": models.WorkflowChangeChanged,
+		"definition.steps[id=log]":                 models.WorkflowChangeRemoved,
+		"definition.steps[id=kick]":                models.WorkflowChangeAdded,
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(expected), len(changes), changes)
+	}
+
+	for _, change := range changes {
+		changeType, ok := expected[change.Path]
+		if !ok {
+			t.Errorf("Unexpected change at %s", change.Path)
+			continue
+		}
+		if change.Type != changeType {
+			t.Errorf("Expected %s at %s, got %s", changeType, change.Path, change.Type)
+		}
+	}
+
+	if len(DiffWorkflowRevisions(from, from)) != 0 {
+		t.Errorf("Expected no changes when comparing a revision with itself")
+	}
+}
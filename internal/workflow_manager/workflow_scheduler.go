@@ -0,0 +1,300 @@
+package workflow_manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// Event types reported in the trigger data of scheduled executions
+const (
+	scheduleEventTypeCron     = "SCHEDULE_CRON"
+	scheduleEventTypeInterval = "SCHEDULE_INTERVAL"
+)
+
+// scheduledTrigger tracks the next run of a cron or interval trigger
+type scheduledTrigger struct {
+	workflowID uuid.UUID
+	serverID   uuid.UUID
+	trigger    models.WorkflowTrigger
+	cron       *cronSchedule
+	interval   time.Duration
+	lastRun    time.Time
+	nextRun    time.Time
+	missed     bool // nextRun is a catch-up for a run missed while offline
+}
+
+// scheduleKey identifies a trigger across reloads
+func scheduleKey(workflowID uuid.UUID, triggerID string) string {
+	return workflowID.String() + ":" + triggerID
+}
+
+// next returns the first run time after t
+func (st *scheduledTrigger) next(t time.Time) time.Time {
+	if st.cron != nil {
+		return st.cron.Next(t)
+	}
+	return t.Add(st.interval)
+}
+
+// sameSchedule reports whether two triggers fire at the same times
+func sameSchedule(a, b models.WorkflowTrigger) bool {
+	return a.Type == b.Type && a.Cron == b.Cron && a.IntervalSeconds == b.IntervalSeconds && a.Timezone == b.Timezone
+}
+
+// newScheduledTrigger parses the schedule of a trigger
+func newScheduledTrigger(workflow *models.ServerWorkflow, trigger models.WorkflowTrigger) (*scheduledTrigger, error) {
+	st := &scheduledTrigger{
+		workflowID: workflow.ID,
+		serverID:   workflow.ServerID,
+		trigger:    trigger,
+	}
+
+	switch trigger.Type {
+	case models.TriggerTypeCron:
+		location := time.UTC
+		if trigger.Timezone != "" {
+			var err error
+			location, err = time.LoadLocation(trigger.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timezone %q: %w", trigger.Timezone, err)
+			}
+		}
+
+		schedule, err := parseCronSchedule(trigger.Cron, location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", trigger.Cron, err)
+		}
+		st.cron = schedule
+	case models.TriggerTypeInterval:
+		if trigger.IntervalSeconds <= 0 {
+			return nil, fmt.Errorf("interval_seconds must be greater than 0")
+		}
+		st.interval = time.Duration(trigger.IntervalSeconds) * time.Second
+	default:
+		return nil, fmt.Errorf("unsupported schedule trigger type %q", trigger.Type)
+	}
+
+	return st, nil
+}
+
+// syncSchedules rebuilds the scheduled triggers from the active workflows.
+// Triggers whose schedule didn't change keep their next run time; new ones
+// are initialized from the last run stored in the database so downtime is
+// detected. Must be called with wm.mutex held.
+func (wm *WorkflowManager) syncSchedules() {
+	now := time.Now()
+
+	wm.scheduleMutex.Lock()
+	defer wm.scheduleMutex.Unlock()
+
+	schedules := make(map[string]*scheduledTrigger)
+
+	for _, workflow := range wm.activeWorkflows {
+		var lastRuns map[string]time.Time
+
+		for _, trigger := range workflow.Definition.Triggers {
+			if !trigger.Enabled || !trigger.IsScheduled() {
+				continue
+			}
+
+			key := scheduleKey(workflow.ID, trigger.ID)
+			if existing, ok := wm.schedules[key]; ok && sameSchedule(existing.trigger, trigger) {
+				existing.trigger = trigger
+				schedules[key] = existing
+				continue
+			}
+
+			st, err := newScheduledTrigger(workflow, trigger)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("workflow_id", workflow.ID.String()).
+					Str("trigger_id", trigger.ID).
+					Msg("Skipping invalid scheduled trigger")
+				continue
+			}
+
+			if lastRuns == nil {
+				lastRuns, err = wm.workflowDB.GetTriggerLastRuns(workflow.ID)
+				if err != nil {
+					log.Error().Err(err).Str("workflow_id", workflow.ID.String()).Msg("Failed to load scheduled trigger runs")
+					lastRuns = make(map[string]time.Time)
+				}
+			}
+
+			st.lastRun = lastRuns[trigger.ID]
+			st.nextRun = st.next(now)
+
+			if !st.lastRun.IsZero() {
+				if due := st.next(st.lastRun); !due.IsZero() && due.Before(now) {
+					if trigger.MissedRuns == models.MissedRunsRunOnce {
+						st.nextRun = now
+						st.missed = true
+					}
+					log.Info().
+						Str("workflow_id", workflow.ID.String()).
+						Str("trigger_id", trigger.ID).
+						Time("missed_run", due).
+						Bool("catch_up", st.missed).
+						Msg("Scheduled trigger missed a run while offline")
+				} else if !due.IsZero() {
+					st.nextRun = due
+				}
+			}
+
+			schedules[key] = st
+
+			log.Debug().
+				Str("workflow_id", workflow.ID.String()).
+				Str("trigger_id", trigger.ID).
+				Str("trigger_type", trigger.Type).
+				Time("next_run", st.nextRun).
+				Msg("Scheduled workflow trigger")
+		}
+	}
+
+	wm.schedules = schedules
+}
+
+// scheduleLoop fires scheduled triggers when they are due
+func (wm *WorkflowManager) scheduleLoop() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wm.ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, due := range wm.dueSchedules(now) {
+				go wm.fireScheduledTrigger(due, now)
+			}
+		}
+	}
+}
+
+// dueSchedules returns copies of the triggers due at now and advances them
+func (wm *WorkflowManager) dueSchedules(now time.Time) []scheduledTrigger {
+	wm.scheduleMutex.Lock()
+	defer wm.scheduleMutex.Unlock()
+
+	var due []scheduledTrigger
+	for _, st := range wm.schedules {
+		if st.nextRun.IsZero() || st.nextRun.After(now) {
+			continue
+		}
+
+		due = append(due, *st)
+		st.lastRun = now
+		st.nextRun = st.next(now)
+		st.missed = false
+	}
+
+	return due
+}
+
+// fireScheduledTrigger records the run and executes the workflow if the
+// trigger conditions match the current server state
+func (wm *WorkflowManager) fireScheduledTrigger(st scheduledTrigger, firedAt time.Time) {
+	if err := wm.workflowDB.SetTriggerLastRun(st.workflowID, st.trigger.ID, firedAt); err != nil {
+		log.Error().Err(err).Str("workflow_id", st.workflowID.String()).Msg("Failed to record scheduled trigger run")
+	}
+
+	wm.mutex.RLock()
+	workflow, ok := wm.activeWorkflows[st.workflowID]
+	wm.mutex.RUnlock()
+	if !ok {
+		return
+	}
+
+	eventType := scheduleEventTypeInterval
+	if st.trigger.Type == models.TriggerTypeCron {
+		eventType = scheduleEventTypeCron
+	}
+
+	triggerEvent := map[string]interface{}{
+		"event_type":     eventType,
+		"event_id":       uuid.New().String(),
+		"event_time":     firedAt.Format(time.RFC3339Nano),
+		"trigger_id":     st.trigger.ID,
+		"trigger_name":   st.trigger.Name,
+		"scheduled_time": st.nextRun.Format(time.RFC3339Nano),
+		"missed_run":     st.missed,
+		"server":         wm.getServerState(st.serverID),
+	}
+	if !st.lastRun.IsZero() {
+		triggerEvent["last_run"] = st.lastRun.Format(time.RFC3339Nano)
+	}
+
+	if !wm.evaluateConditions(st.trigger.Conditions, triggerEvent) {
+		log.Debug().
+			Str("workflow_id", workflow.ID.String()).
+			Str("trigger_id", st.trigger.ID).
+			Msg("Scheduled trigger conditions not met, skipping run")
+		return
+	}
+
+	log.Debug().
+		Str("workflow_id", workflow.ID.String()).
+		Str("workflow_name", workflow.Name).
+		Str("trigger_id", st.trigger.ID).
+		Msg("Dispatching scheduled workflow execution")
+
+	wm.dispatchWorkflow(workflow, triggerEvent)
+}
+
+// getServerState returns live server state that scheduled trigger conditions
+// can check, e.g. "server.player_count"
+func (wm *WorkflowManager) getServerState(serverID uuid.UUID) map[string]interface{} {
+	state := map[string]interface{}{
+		"player_count": 0,
+		"tracked":      false,
+	}
+
+	if wm.playerTrackerManager == nil {
+		return state
+	}
+
+	tracker, ok := wm.playerTrackerManager.GetTracker(serverID)
+	if !ok {
+		return state
+	}
+
+	playerCount := 0
+	for _, player := range tracker.GetAllPlayers() {
+		if player.IsConnected {
+			playerCount++
+		}
+	}
+
+	state["player_count"] = playerCount
+	state["tracked"] = true
+	return state
+}
+
+// GetScheduledTriggers returns the next run of every scheduled trigger
+func (wm *WorkflowManager) GetScheduledTriggers() []map[string]interface{} {
+	wm.scheduleMutex.Lock()
+	defer wm.scheduleMutex.Unlock()
+
+	result := make([]map[string]interface{}, 0, len(wm.schedules))
+	for _, st := range wm.schedules {
+		entry := map[string]interface{}{
+			"workflow_id":  st.workflowID.String(),
+			"trigger_id":   st.trigger.ID,
+			"trigger_name": st.trigger.Name,
+			"type":         st.trigger.Type,
+			"next_run":     st.nextRun,
+		}
+		if !st.lastRun.IsZero() {
+			entry["last_run"] = st.lastRun
+		}
+		result = append(result, entry)
+	}
+
+	return result
+}
@@ -0,0 +1,537 @@
+package workflow_manager
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.codycody31.dev/squad-aegis/internal/models"
+	"go.codycody31.dev/squad-aegis/internal/rcon_manager"
+)
+
+// workflowKVStore is the KV storage available to workflow scripts
+type workflowKVStore interface {
+	GetKVValue(workflowID uuid.UUID, key string) (interface{}, error)
+	SetKVValue(workflowID uuid.UUID, key string, value interface{}) error
+	DeleteKVValue(workflowID uuid.UUID, key string) error
+	KVExists(workflowID uuid.UUID, key string) (bool, error)
+	ListKVKeys(workflowID uuid.UUID) ([]string, error)
+	GetAllKVPairs(workflowID uuid.UUID) (map[string]interface{}, error)
+	ClearKVStore(workflowID uuid.UUID) error
+	CountKVPairs(workflowID uuid.UUID) (int, error)
+}
+
+// banEvidence links a workflow ban to the event that caused it
+type banEvidence struct {
+	EvidenceType    string
+	ClickhouseTable string
+	RecordID        string
+	EventTime       time.Time
+}
+
+// workflowSimulation captures everything a simulated execution would have done
+type workflowSimulation struct {
+	mu            sync.Mutex
+	trace         []models.WorkflowSimulationStep
+	messages      []models.WorkflowSimulationMessage
+	sideEffects   []models.WorkflowSideEffect
+	rconResponses map[string]string
+	kv            *simulatedKVStore
+}
+
+// simulationFor returns the simulation the execution belongs to, or nil for real executions
+func (wm *WorkflowManager) simulationFor(context *models.WorkflowExecutionContext) *workflowSimulation {
+	wm.executionMutex.RLock()
+	defer wm.executionMutex.RUnlock()
+
+	return wm.simulations[context.ExecutionID]
+}
+
+// recordSideEffect stores an action that was captured instead of performed
+func (s *workflowSimulation) recordSideEffect(context *models.WorkflowExecutionContext, effectType, target string, details map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sideEffects = append(s.sideEffects, models.WorkflowSideEffect{
+		Time:    time.Now(),
+		StepID:  context.CurrentStep,
+		Type:    effectType,
+		Target:  target,
+		Details: details,
+	})
+}
+
+// recordStep stores a step log entry with a snapshot of the variables
+func (s *workflowSimulation) recordStep(
+	context *models.WorkflowExecutionContext,
+	stepName, stepType string,
+	stepOrder uint32,
+	stepStatus string,
+	stepInput, stepOutput map[string]interface{},
+	stepError *string,
+	stepDurationMs uint32,
+) {
+	variables := make(map[string]interface{}, len(context.Variables))
+	for k, v := range context.Variables {
+		variables[k] = v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.trace = append(s.trace, models.WorkflowSimulationStep{
+		Time:       time.Now(),
+		StepName:   stepName,
+		StepType:   stepType,
+		StepOrder:  stepOrder,
+		Status:     stepStatus,
+		Input:      stepInput,
+		Output:     stepOutput,
+		Error:      stepError,
+		DurationMs: stepDurationMs,
+		Variables:  variables,
+	})
+}
+
+// recordMessage stores a log message written by a step
+func (s *workflowSimulation) recordMessage(step *models.WorkflowStep, level, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, models.WorkflowSimulationMessage{
+		Time:     time.Now(),
+		StepID:   step.ID,
+		StepName: step.Name,
+		Level:    level,
+		Message:  message,
+	})
+}
+
+// rconResponse returns the mocked response for a command, matching the full
+// command first and then the command name
+func (s *workflowSimulation) rconResponse(command string) string {
+	if response, ok := s.rconResponses[command]; ok {
+		return response
+	}
+
+	if name, _, _ := strings.Cut(strings.TrimSpace(command), " "); name != "" {
+		for key, response := range s.rconResponses {
+			if strings.EqualFold(key, name) {
+				return response
+			}
+		}
+	}
+
+	return ""
+}
+
+// executeRconCommand sends an RCON command to the server of the execution.
+// Simulations record the command and return the mocked response instead.
+func (wm *WorkflowManager) executeRconCommand(context *models.WorkflowExecutionContext, command string) (string, error) {
+	if sim := wm.simulationFor(context); sim != nil {
+		response := sim.rconResponse(command)
+		sim.recordSideEffect(context, models.SideEffectRcon, command, map[string]interface{}{
+			"response": response,
+		})
+		return response, nil
+	}
+
+	return wm.rconManager.ExecuteCommandWithOptions(context.ServerID, command, rcon_manager.CommandOptions{
+		Caller: rcon_manager.WorkflowCaller(context.WorkflowID),
+	})
+}
+
+// doHTTPRequest performs an outgoing HTTP request of a step. Simulations
+// record the request and answer with an empty 200 response instead.
+func (wm *WorkflowManager) doHTTPRequest(context *models.WorkflowExecutionContext, client *http.Client, req *http.Request) (*http.Response, error) {
+	sim := wm.simulationFor(context)
+	if sim == nil {
+		return client.Do(req)
+	}
+
+	var body string
+	if req.Body != nil {
+		bodyBytes, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		body = string(bodyBytes)
+	}
+
+	headers := make(map[string]interface{}, len(req.Header))
+	for key := range req.Header {
+		headers[key] = req.Header.Get(key)
+	}
+
+	sim.recordSideEffect(context, models.SideEffectHTTP, req.URL.String(), map[string]interface{}{
+		"method":  req.Method,
+		"headers": headers,
+		"body":    body,
+	})
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString("{}")),
+		Request:    req,
+	}, nil
+}
+
+// kvStore returns the KV store used by an execution
+func (wm *WorkflowManager) kvStore(context *models.WorkflowExecutionContext) workflowKVStore {
+	if sim := wm.simulationFor(context); sim != nil {
+		return sim.kv
+	}
+	return wm.workflowDB
+}
+
+// insertBanWithEvidence creates a ban and its evidence records in one transaction.
+// Simulations record the rows that would have been written instead.
+func (wm *WorkflowManager) insertBanWithEvidence(context *models.WorkflowExecutionContext, banID uuid.UUID, steamID int64, reason string, duration float64, ruleID string, evidenceItems []banEvidence) error {
+	var ruleUUID *uuid.UUID
+	if ruleID != "" {
+		parsed, err := uuid.Parse(ruleID)
+		if err != nil {
+			return fmt.Errorf("invalid rule ID format: %w", err)
+		}
+		ruleUUID = &parsed
+	}
+
+	if sim := wm.simulationFor(context); sim != nil {
+		details := map[string]interface{}{
+			"ban_id":         banID.String(),
+			"steam_id":       steamID,
+			"reason":         reason,
+			"duration":       int(duration),
+			"evidence_count": len(evidenceItems),
+		}
+		if ruleUUID != nil {
+			details["rule_id"] = ruleUUID.String()
+		}
+		sim.recordSideEffect(context, models.SideEffectDatabase, "server_bans", details)
+		return nil
+	}
+
+	tx, err := wm.db.BeginTx(wm.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	if ruleUUID != nil {
+		_, err = tx.ExecContext(wm.ctx, `INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, rule_id, created_at, updated_at) VALUES ($1, $2, NULL, $3, $4, $5, $6, $7, $8)`,
+			banID, context.ServerID, steamID, reason, int(duration), *ruleUUID, now, now)
+	} else {
+		_, err = tx.ExecContext(wm.ctx, `INSERT INTO server_bans (id, server_id, admin_id, steam_id, reason, duration, created_at, updated_at) VALUES ($1, $2, NULL, $3, $4, $5, $6, $7)`,
+			banID, context.ServerID, steamID, reason, int(duration), now, now)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create ban: %w", err)
+	}
+
+	for _, ev := range evidenceItems {
+		metadata := map[string]interface{}{
+			"event_type": context.TriggerEvent["event_type"],
+			"event_id":   ev.RecordID,
+		}
+		metadataJSON, _ := json.Marshal(metadata)
+
+		evidenceQuery := `INSERT INTO ban_evidence (id, ban_id, evidence_type, clickhouse_table, record_id, server_id, event_time, metadata, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+		_, err = tx.ExecContext(wm.ctx, evidenceQuery,
+			uuid.New(),
+			banID.String(),
+			ev.EvidenceType,
+			ev.ClickhouseTable,
+			ev.RecordID,
+			context.ServerID,
+			ev.EventTime,
+			metadataJSON,
+			now,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert evidence: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// simulatedKVStore is an in-memory KV store that records writes as side effects
+type simulatedKVStore struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+	record func(operation, key string, value interface{})
+}
+
+// normalizeKVValue round-trips a value through JSON like the database store does
+func normalizeKVValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal KV value: %w", err)
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal KV value: %w", err)
+	}
+	return normalized, nil
+}
+
+func (s *simulatedKVStore) GetKVValue(_ uuid.UUID, key string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return value, nil
+}
+
+func (s *simulatedKVStore) SetKVValue(_ uuid.UUID, key string, value interface{}) error {
+	normalized, err := normalizeKVValue(value)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.values[key] = normalized
+	s.mu.Unlock()
+
+	s.record("set", key, normalized)
+	return nil
+}
+
+func (s *simulatedKVStore) DeleteKVValue(_ uuid.UUID, key string) error {
+	s.mu.Lock()
+	delete(s.values, key)
+	s.mu.Unlock()
+
+	s.record("delete", key, nil)
+	return nil
+}
+
+func (s *simulatedKVStore) KVExists(_ uuid.UUID, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.values[key]
+	return ok, nil
+}
+
+func (s *simulatedKVStore) ListKVKeys(_ uuid.UUID) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *simulatedKVStore) GetAllKVPairs(_ uuid.UUID) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pairs := make(map[string]interface{}, len(s.values))
+	for key, value := range s.values {
+		pairs[key] = value
+	}
+	return pairs, nil
+}
+
+func (s *simulatedKVStore) ClearKVStore(_ uuid.UUID) error {
+	s.mu.Lock()
+	s.values = make(map[string]interface{})
+	s.mu.Unlock()
+
+	s.record("clear", "*", nil)
+	return nil
+}
+
+func (s *simulatedKVStore) CountKVPairs(_ uuid.UUID) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.values), nil
+}
+
+// matchSimulationTrigger finds the trigger that a simulated event would fire and
+// reports whether its conditions match
+func (wm *WorkflowManager) matchSimulationTrigger(workflow *models.ServerWorkflow, triggerEvent map[string]interface{}) (*models.WorkflowTrigger, bool) {
+	eventType := wm.getTriggerEventType(triggerEvent)
+
+	var trigger *models.WorkflowTrigger
+	switch eventType {
+	case scheduleEventTypeCron, scheduleEventTypeInterval:
+		triggerID, _ := triggerEvent["trigger_id"].(string)
+		for i := range workflow.Definition.Triggers {
+			candidate := &workflow.Definition.Triggers[i]
+			if !candidate.Enabled || !candidate.IsScheduled() {
+				continue
+			}
+			if triggerID == "" || candidate.ID == triggerID {
+				trigger = candidate
+				break
+			}
+		}
+	default:
+		trigger = workflow.GetTriggerByEventType(eventType)
+	}
+
+	if trigger == nil {
+		return nil, false
+	}
+	return trigger, wm.evaluateConditions(trigger.Conditions, triggerEvent)
+}
+
+// SimulateWorkflow runs a workflow against a trigger event without side
+// effects. RCON commands, HTTP requests, ban inserts and KV writes are
+// captured and returned with the step trace; nothing is written to the
+// execution history. The run happens even if the trigger wouldn't match.
+func (wm *WorkflowManager) SimulateWorkflow(workflow *models.ServerWorkflow, request *models.WorkflowSimulateRequest) (*models.WorkflowSimulationResult, error) {
+	if request.Definition != nil {
+		simulated := *workflow
+		simulated.Definition = *request.Definition
+		simulated.Revision = 0
+		workflow = &simulated
+	}
+
+	triggerEvent := request.TriggerEvent
+	if triggerEvent == nil {
+		triggerEvent = make(map[string]interface{})
+	}
+
+	sim := &workflowSimulation{
+		rconResponses: request.RconResponses,
+	}
+
+	kvValues := make(map[string]interface{})
+	if request.KV != nil {
+		for key, value := range request.KV {
+			normalized, err := normalizeKVValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid KV value for key %s: %w", key, err)
+			}
+			kvValues[key] = normalized
+		}
+	} else if wm.db != nil {
+		// Start from a copy of the stored KV data when none is given
+		stored, err := wm.workflowDB.GetAllKVPairs(workflow.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workflow KV store: %w", err)
+		}
+		kvValues = stored
+	}
+
+	context := wm.newExecutionContext(workflow, triggerEvent)
+	context.Metadata["simulation"] = true
+	for key, value := range request.Variables {
+		context.Variables[key] = value
+	}
+
+	sim.kv = &simulatedKVStore{
+		values: kvValues,
+		record: func(operation, key string, value interface{}) {
+			sim.recordSideEffect(context, models.SideEffectKV, key, map[string]interface{}{
+				"operation": operation,
+				"value":     value,
+			})
+		},
+	}
+
+	trigger, matched := wm.matchSimulationTrigger(workflow, triggerEvent)
+
+	wm.executionMutex.Lock()
+	if wm.simulations == nil {
+		wm.simulations = make(map[uuid.UUID]*workflowSimulation)
+	}
+	wm.simulations[context.ExecutionID] = sim
+	wm.executionMutex.Unlock()
+
+	defer func() {
+		wm.executionMutex.Lock()
+		delete(wm.simulations, context.ExecutionID)
+		wm.executionMutex.Unlock()
+	}()
+
+	log.Debug().
+		Str("execution_id", context.ExecutionID.String()).
+		Str("workflow_id", workflow.ID.String()).
+		Bool("trigger_matched", matched).
+		Msg("Starting workflow simulation")
+
+	summary := &models.WorkflowExecutionSummary{
+		ExecutionID:  context.ExecutionID,
+		WorkflowID:   workflow.ID,
+		ServerID:     workflow.ServerID,
+		WorkflowName: workflow.Name,
+		StartedAt:    context.StartedAt,
+		Status:       "RUNNING",
+		TotalSteps:   uint32(len(workflow.Definition.Steps)),
+	}
+
+	wm.logWorkflowStep(context, workflow, "workflow_start", "WORKFLOW", 0, "RUNNING",
+		map[string]interface{}{"trigger_event": triggerEvent},
+		map[string]interface{}{"status": "started"}, nil, 0)
+
+	err := wm.executeWorkflowSteps(context, workflow, summary)
+	duration := time.Since(context.StartedAt)
+
+	result := &models.WorkflowSimulationResult{
+		ExecutionID:    context.ExecutionID,
+		WorkflowID:     workflow.ID,
+		TriggerMatched: matched,
+		TriggerEvent:   triggerEvent,
+		Status:         "COMPLETED",
+		DurationMs:     duration.Milliseconds(),
+		Variables:      context.Variables,
+		StepResults:    context.StepResults,
+	}
+	if trigger != nil {
+		result.MatchedTriggerID = trigger.ID
+	}
+
+	finalOrder := uint32(len(workflow.Definition.Steps) + 1)
+	if err != nil {
+		errorMsg := err.Error()
+		result.Status = "FAILED"
+		result.ErrorMessage = &errorMsg
+		wm.logWorkflowStep(context, workflow, "workflow_failed", "WORKFLOW", finalOrder, "FAILED",
+			map[string]interface{}{},
+			map[string]interface{}{"error": errorMsg}, &errorMsg, uint32(duration.Milliseconds()))
+	} else {
+		wm.logWorkflowStep(context, workflow, "workflow_completed", "WORKFLOW", finalOrder, "COMPLETED",
+			map[string]interface{}{},
+			map[string]interface{}{"completed_steps": summary.CompletedSteps, "failed_steps": summary.FailedSteps}, nil, uint32(duration.Milliseconds()))
+	}
+
+	sim.mu.Lock()
+	result.Trace = append([]models.WorkflowSimulationStep{}, sim.trace...)
+	result.Messages = append([]models.WorkflowSimulationMessage{}, sim.messages...)
+	result.SideEffects = append([]models.WorkflowSideEffect{}, sim.sideEffects...)
+	sim.mu.Unlock()
+
+	result.KV, _ = sim.kv.GetAllKVPairs(workflow.ID)
+
+	return result, nil
+}
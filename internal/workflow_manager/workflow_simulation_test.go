@@ -0,0 +1,122 @@
+package workflow_manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func TestSimulateWorkflowCapturesSideEffects(t *testing.T) {
+	wm := &WorkflowManager{ctx: context.Background()}
+
+	workflow := &models.ServerWorkflow{
+		ID:       uuid.New(),
+		ServerID: uuid.New(),
+		Name:     "simulation",
+		Definition: models.WorkflowDefinition{
+			Triggers: []models.WorkflowTrigger{{
+				ID:        "chat",
+				EventType: "RCON_CHAT_MESSAGE",
+				Enabled:   true,
+				Conditions: []models.WorkflowCondition{
+					{Field: "message", Operator: models.OperatorStartsWith, Value: "!help"},
+				},
+			}},
+			Steps: []models.WorkflowStep{
+				{
+					ID: "players", Name: "players", Type: models.StepTypeAction, Enabled: true,
+					Config: map[string]interface{}{"action_type": models.ActionTypeRconCommand, "command": "ListPlayers"},
+				},
+				{
+					ID: "wait", Name: "wait", Type: models.StepTypeDelay, Enabled: true,
+					Config: map[string]interface{}{"delay_ms": float64(60000)},
+				},
+				{
+					ID: "notify", Name: "notify", Type: models.StepTypeAction, Enabled: true,
+					Config: map[string]interface{}{"action_type": models.ActionTypeWebhook, "url": "http://127.0.0.1:1/hook"},
+				},
+				{
+					ID: "script", Name: "script", Type: models.StepTypeAction, Enabled: true,
+					Config: map[string]interface{}{
+						"action_type": models.ActionTypeLuaScript,
+						"script":      `workflow.kv.set("count", workflow.kv.get("count", 0) + 1)`,
+					},
+				},
+			},
+		},
+	}
+
+	start := time.Now()
+	result, err := wm.SimulateWorkflow(workflow, &models.WorkflowSimulateRequest{
+		TriggerEvent:  map[string]interface{}{"event_type": "RCON_CHAT_MESSAGE", "message": "!help me"},
+		KV:            map[string]interface{}{"count": 41},
+		RconResponses: map[string]string{"listplayers": "----- Active Players -----"},
+	})
+	if err != nil {
+		t.Fatalf("Simulation failed: %v", err)
+	}
+
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("Expected the delay step to be skipped in simulation")
+	}
+	if result.Status != "COMPLETED" {
+		t.Fatalf("Expected COMPLETED, got %s (%v)", result.Status, result.ErrorMessage)
+	}
+	if !result.TriggerMatched || result.MatchedTriggerID != "chat" {
+		t.Errorf("Expected trigger chat to match, got %v %q", result.TriggerMatched, result.MatchedTriggerID)
+	}
+
+	playersResult := result.StepResults["players"].(map[string]interface{})
+	if playersResult["response"] != "----- Active Players -----" {
+		t.Errorf("Expected mocked RCON response, got %v", playersResult["response"])
+	}
+
+	types := make(map[string]int)
+	for _, effect := range result.SideEffects {
+		types[effect.Type]++
+	}
+	if types[models.SideEffectRcon] != 1 || types[models.SideEffectHTTP] != 1 || types[models.SideEffectKV] != 1 {
+		t.Errorf("Unexpected side effects: %+v", result.SideEffects)
+	}
+
+	if result.KV["count"] != 42.0 {
+		t.Errorf("Expected simulated KV count 42, got %v", result.KV["count"])
+	}
+
+	// workflow_start, then RUNNING and COMPLETED for each step, then workflow_completed
+	if len(result.Trace) != 2+2*len(workflow.Definition.Steps) {
+		t.Errorf("Unexpected trace length %d", len(result.Trace))
+	}
+
+	if len(wm.simulations) != 0 {
+		t.Errorf("Expected simulation to be unregistered after the run")
+	}
+}
+
+func TestSimulateWorkflowReportsUnmatchedTrigger(t *testing.T) {
+	wm := &WorkflowManager{ctx: context.Background()}
+
+	workflow := &models.ServerWorkflow{
+		ID: uuid.New(),
+		Definition: models.WorkflowDefinition{
+			Triggers: []models.WorkflowTrigger{{ID: "chat", EventType: "RCON_CHAT_MESSAGE", Enabled: true}},
+		},
+	}
+
+	result, err := wm.SimulateWorkflow(workflow, &models.WorkflowSimulateRequest{
+		TriggerEvent: map[string]interface{}{"event_type": "LOG_PLAYER_DIED"},
+	})
+	if err != nil {
+		t.Fatalf("Simulation failed: %v", err)
+	}
+
+	if result.TriggerMatched {
+		t.Errorf("Expected trigger not to match a different event type")
+	}
+	if result.Status != "COMPLETED" {
+		t.Errorf("Expected simulation to run regardless of the trigger, got %s", result.Status)
+	}
+}
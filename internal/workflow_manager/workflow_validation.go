@@ -0,0 +1,773 @@
+package workflow_manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/gopher-lua/parse"
+	"go.codycody31.dev/squad-aegis/internal/event_manager"
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+// Validation issue codes
+const (
+	issueInvalidTrigger           = "invalid_trigger"
+	issueUnknownEventType         = "unknown_event_type"
+	issueNoEnabledTriggers        = "no_enabled_triggers"
+	issueMissingStepID            = "missing_step_id"
+	issueDuplicateStepID          = "duplicate_step_id"
+	issueDuplicateStepName        = "duplicate_step_name"
+	issueUnknownStepType          = "unknown_step_type"
+	issueUnknownActionType        = "unknown_action_type"
+	issueMissingConfig            = "missing_config"
+	issueInvalidConfig            = "invalid_config"
+	issueDanglingReference        = "dangling_reference"
+	issueDisabledReference        = "disabled_reference"
+	issueIgnoredNextSteps         = "ignored_next_steps"
+	issueUnreachableStep          = "unreachable_step"
+	issueUnconditionalBranchStep  = "unconditional_branch_step"
+	issueBackwardGoto             = "backward_goto"
+	issueCycle                    = "cycle"
+	issueInvalidOperator          = "invalid_operator"
+	issueInvalidRegex             = "invalid_regex"
+	issueLuaSyntax                = "lua_syntax"
+	issueInvalidLuaLimits         = "invalid_lua_limits"
+	issueInvalidConcurrency       = "invalid_concurrency"
+	issueUnknownTemplateReference = "unknown_template_reference"
+	issueUnknownVariable          = "unknown_variable"
+)
+
+// supportedStepTypes are the step types executeStep can run
+var supportedStepTypes = map[string]bool{
+	models.StepTypeAction:    true,
+	models.StepTypeCondition: true,
+	models.StepTypeVariable:  true,
+	models.StepTypeDelay:     true,
+	models.StepTypeLoop:      true,
+	models.StepTypeParallel:  true,
+	models.StepTypeLua:       true,
+}
+
+// supportedActionTypes maps the action types executeActionStep can run to
+// the config keys each of them requires
+var supportedActionTypes = map[string][]string{
+	models.ActionTypeRconCommand:           {"command"},
+	models.ActionTypeAdminBroadcast:        {"message"},
+	models.ActionTypeChatMessage:           {"message", "target_player"},
+	models.ActionTypeKickPlayer:            {"player_id"},
+	models.ActionTypeBanPlayer:             {"player_id", "duration"},
+	models.ActionTypeBanPlayerWithEvidence: {"player_id", "duration"},
+	models.ActionTypeWarnPlayer:            {"player_id", "message"},
+	models.ActionTypeHTTPRequest:           {"url"},
+	models.ActionTypeWebhook:               {"url"},
+	models.ActionTypeDiscordMessage:        {"webhook_url", "message"},
+	models.ActionTypeLogMessage:            {"message"},
+	models.ActionTypeSetVariable:           {"variable_name", "variable_value"},
+	models.ActionTypeLuaScript:             {"script"},
+}
+
+// variableOperations maps variable step operations to their required config keys
+var variableOperations = map[string][]string{
+	"set":       {"variable_name"},
+	"increment": {"variable_name"},
+	"decrement": {"variable_name"},
+	"append":    {"variable_name", "value"},
+	"prepend":   {"variable_name", "value"},
+	"delete":    {"variable_name"},
+	"copy":      {"source_variable", "target_variable"},
+	"transform": {"variable_name", "transformation"},
+}
+
+// conditionOperators are the operators evaluateCondition understands
+var conditionOperators = map[string]bool{
+	models.OperatorEquals:         true,
+	models.OperatorNotEquals:      true,
+	models.OperatorContains:       true,
+	models.OperatorNotContains:    true,
+	models.OperatorStartsWith:     true,
+	models.OperatorEndsWith:       true,
+	models.OperatorRegex:          true,
+	models.OperatorGreaterThan:    true,
+	models.OperatorLessThan:       true,
+	models.OperatorGreaterOrEqual: true,
+	models.OperatorLessOrEqual:    true,
+	models.OperatorIn:             true,
+	models.OperatorNotIn:          true,
+}
+
+// errorActions are the values accepted in a step's on_error.action
+var errorActions = map[string]bool{
+	"continue": true,
+	"stop":     true,
+	"retry":    true,
+	"goto":     true,
+}
+
+// eventTriggerFields are added to the data of every event trigger by handleEvent
+var eventTriggerFields = []string{"event_type", "event_id", "event_time"}
+
+// scheduleTriggerFields are the fields of the data passed to scheduled executions
+var scheduleTriggerFields = []string{
+	"event_type", "event_id", "event_time", "trigger_id", "trigger_name",
+	"scheduled_time", "missed_run", "server", "last_run",
+}
+
+// metadataFields are the keys newExecutionContext and the executor put in metadata
+var metadataFields = map[string]bool{
+	"workflow_name":     true,
+	"workflow_id":       true,
+	"workflow_revision": true,
+	"server_id":         true,
+	"execution_id":      true,
+	"started_at":        true,
+	"simulation":        true,
+	"skipped_steps":     true,
+}
+
+// nestedStepKeys are config keys holding nested steps or step references
+// rather than values the step uses
+var nestedStepKeys = map[string]bool{
+	"true_steps":  true,
+	"false_steps": true,
+	"steps":       true,
+	"branches":    true,
+}
+
+// walkSteps calls fn for every step including the inline steps nested in
+// condition, loop and parallel steps. The path identifies the step, e.g.
+// "steps[2].true_steps[0]".
+func walkSteps(path string, steps []models.WorkflowStep, fn func(path string, step models.WorkflowStep)) {
+	for i, step := range steps {
+		stepPath := fmt.Sprintf("%s[%d]", path, i)
+		fn(stepPath, step)
+
+		for _, key := range []string{"true_steps", "false_steps", "steps"} {
+			raw, ok := step.Config[key].([]interface{})
+			if !ok {
+				continue
+			}
+
+			// Condition branches may mix step name references with inline steps
+			var inline []interface{}
+			for _, item := range raw {
+				if _, isMap := item.(map[string]interface{}); isMap {
+					inline = append(inline, item)
+				}
+			}
+
+			nested, err := parseInlineSteps(inline)
+			if err == nil {
+				walkSteps(stepPath+"."+key, nested, fn)
+			}
+		}
+
+		if branches, ok := step.Config["branches"].([]interface{}); ok {
+			for b, rawBranch := range branches {
+				branch, ok := rawBranch.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				nested, err := parseInlineSteps(branch["steps"])
+				if err == nil {
+					walkSteps(fmt.Sprintf("%s.branches[%d].steps", stepPath, b), nested, fn)
+				}
+			}
+		}
+	}
+}
+
+// branchReferences returns the step names a condition step's true_steps and
+// false_steps reference, keyed by config key
+func branchReferences(step models.WorkflowStep) map[string][]string {
+	references := make(map[string][]string)
+	for _, key := range []string{"true_steps", "false_steps"} {
+		raw, _ := step.Config[key].([]interface{})
+		for _, item := range raw {
+			if name, ok := item.(string); ok {
+				references[key] = append(references[key], name)
+			}
+		}
+	}
+	return references
+}
+
+// workflowValidator collects the issues found in a single definition
+type workflowValidator struct {
+	definition *models.WorkflowDefinition
+	result     *models.WorkflowValidationResult
+
+	stepsByID   map[string]int
+	stepsByName map[string]int
+
+	// Variables that exist at runtime. Lua steps can set arbitrary
+	// variables, so unknown variable checks are skipped when one is present.
+	variables    map[string]bool
+	hasLuaScript bool
+
+	// Known trigger event fields per enabled trigger. A nil set means the
+	// trigger's fields are unknown and template checks are skipped.
+	triggerFields []map[string]bool
+}
+
+// ValidateWorkflowDefinition checks a definition for problems that would
+// otherwise only show up at runtime. variableNames are the names provided by
+// the workflow's stored variables.
+func ValidateWorkflowDefinition(definition *models.WorkflowDefinition, variableNames []string) *models.WorkflowValidationResult {
+	v := &workflowValidator{
+		definition: definition,
+		result: &models.WorkflowValidationResult{
+			Errors:   []models.WorkflowValidationIssue{},
+			Warnings: []models.WorkflowValidationIssue{},
+		},
+		stepsByID:   make(map[string]int),
+		stepsByName: make(map[string]int),
+		variables:   make(map[string]bool),
+	}
+
+	for name := range definition.Variables {
+		v.variables[name] = true
+	}
+	for _, name := range variableNames {
+		v.variables[name] = true
+	}
+
+	v.validateTriggers()
+	for _, problem := range validateLuaLimits(definition.LuaLimits) {
+		v.error(issueInvalidLuaLimits, "lua_limits", nil, "%s", problem)
+	}
+	for _, problem := range validateConcurrency(definition.Concurrency) {
+		v.error(issueInvalidConcurrency, "concurrency", nil, "%s", problem)
+	}
+	v.indexSteps()
+	v.collectVariables()
+
+	if definition.Concurrency != nil && definition.Concurrency.RateLimit != nil {
+		for _, reference := range templateReferences(definition.Concurrency.RateLimit.Key) {
+			v.validateTemplateReference("concurrency.rate_limit.key", models.WorkflowStep{}, reference)
+		}
+	}
+
+	walkSteps("steps", definition.Steps, func(path string, step models.WorkflowStep) {
+		v.validateStep(path, step)
+	})
+
+	v.checkBranchPlacement()
+	v.checkCycles()
+
+	if len(definition.ErrorHandling.OnFailure) > 0 {
+		v.warn(issueUnreachableStep, "error_handling.on_failure", nil,
+			"on_failure steps are never executed; use step on_error handling instead")
+	}
+
+	v.result.Valid = len(v.result.Errors) == 0
+	return v.result
+}
+
+// WorkflowVariableNames returns the variable names a workflow's stored variables provide
+func WorkflowVariableNames(variables []models.ServerWorkflowVariable) []string {
+	var names []string
+	for _, variable := range variables {
+		for name := range variable.Value {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (v *workflowValidator) add(severity, code, path string, step *models.WorkflowStep, format string, args ...interface{}) {
+	issue := models.WorkflowValidationIssue{
+		Severity: severity,
+		Code:     code,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if step != nil {
+		issue.StepID = step.ID
+		issue.StepName = step.Name
+	}
+
+	if severity == models.ValidationSeverityError {
+		v.result.Errors = append(v.result.Errors, issue)
+	} else {
+		v.result.Warnings = append(v.result.Warnings, issue)
+	}
+}
+
+func (v *workflowValidator) error(code, path string, step *models.WorkflowStep, format string, args ...interface{}) {
+	v.add(models.ValidationSeverityError, code, path, step, format, args...)
+}
+
+func (v *workflowValidator) warn(code, path string, step *models.WorkflowStep, format string, args ...interface{}) {
+	v.add(models.ValidationSeverityWarning, code, path, step, format, args...)
+}
+
+// validateTriggers checks trigger schedules and conditions and records the
+// event fields each enabled trigger provides
+func (v *workflowValidator) validateTriggers() {
+	enabled := 0
+
+	for i, trigger := range v.definition.Triggers {
+		path := fmt.Sprintf("triggers[%d]", i)
+		v.validateConditions(path+".conditions", nil, trigger.Conditions)
+
+		var fields map[string]bool
+		if trigger.IsScheduled() {
+			if _, err := newScheduledTrigger(&models.ServerWorkflow{}, trigger); err != nil {
+				v.error(issueInvalidTrigger, path, nil, "%v", err)
+			}
+			fields = stringSet(scheduleTriggerFields)
+		} else if trigger.EventType == "" {
+			v.error(issueInvalidTrigger, path, nil, "event trigger is missing event_type")
+		} else if dataFields, ok := event_manager.EventDataFields(event_manager.EventType(trigger.EventType)); ok {
+			fields = stringSet(append(dataFields, eventTriggerFields...))
+		} else {
+			v.warn(issueUnknownEventType, path, nil, "unknown event type %q; its fields can't be checked", trigger.EventType)
+		}
+
+		if trigger.Enabled {
+			enabled++
+			v.triggerFields = append(v.triggerFields, fields)
+		}
+	}
+
+	if enabled == 0 {
+		v.warn(issueNoEnabledTriggers, "triggers", nil, "workflow has no enabled triggers, so none of its steps will run")
+	}
+}
+
+// indexSteps maps top-level step IDs and names to their index
+func (v *workflowValidator) indexSteps() {
+	for i := range v.definition.Steps {
+		step := &v.definition.Steps[i]
+		path := fmt.Sprintf("steps[%d]", i)
+
+		if step.ID == "" {
+			v.error(issueMissingStepID, path, step, "step has no id")
+		} else if _, exists := v.stepsByID[step.ID]; exists {
+			v.error(issueDuplicateStepID, path, step, "step id %q is used by more than one step", step.ID)
+		} else {
+			v.stepsByID[step.ID] = i
+		}
+
+		if _, exists := v.stepsByName[step.Name]; exists && step.Name != "" {
+			v.warn(issueDuplicateStepName, path, step, "step name %q is used by more than one step; branch references resolve to the last one", step.Name)
+		}
+		v.stepsByName[step.Name] = i
+	}
+}
+
+// collectVariables records the variables steps set so later references resolve
+func (v *workflowValidator) collectVariables() {
+	walkSteps("steps", v.definition.Steps, func(path string, step models.WorkflowStep) {
+		actionType, _ := step.Config["action_type"].(string)
+		if step.Type == models.StepTypeLua || actionType == models.ActionTypeLuaScript {
+			v.hasLuaScript = true
+		}
+
+		for _, key := range []string{"variable_name", "target_variable"} {
+			if name, ok := step.Config[key].(string); ok && name != "" {
+				v.variables[name] = true
+			}
+		}
+
+		if step.Type == models.StepTypeLoop {
+			itemVariable, _ := step.Config["item_variable"].(string)
+			if itemVariable == "" {
+				itemVariable = "item"
+			}
+			indexVariable, _ := step.Config["index_variable"].(string)
+			if indexVariable == "" {
+				indexVariable = "index"
+			}
+			v.variables[itemVariable] = true
+			v.variables[indexVariable] = true
+		}
+	})
+}
+
+// validateStep checks a single step's type, config, references and templates
+func (v *workflowValidator) validateStep(path string, step models.WorkflowStep) {
+	if !supportedStepTypes[step.Type] {
+		v.error(issueUnknownStepType, path, &step, "unsupported step type %q", step.Type)
+		return
+	}
+
+	switch step.Type {
+	case models.StepTypeAction:
+		actionType, _ := step.Config["action_type"].(string)
+		required, ok := supportedActionTypes[actionType]
+		if !ok {
+			v.error(issueUnknownActionType, path, &step, "unsupported action type %q", actionType)
+			return
+		}
+		v.requireConfig(path, step, required)
+		if actionType == models.ActionTypeLuaScript {
+			v.validateLua(path, step)
+		}
+	case models.StepTypeCondition:
+		v.validateConditionStep(path, step)
+	case models.StepTypeVariable:
+		operation, _ := step.Config["operation"].(string)
+		required, ok := variableOperations[operation]
+		if !ok {
+			v.error(issueInvalidConfig, path, &step, "unsupported variable operation %q", operation)
+			break
+		}
+		v.requireConfig(path, step, required)
+	case models.StepTypeDelay:
+		if delay, ok := step.Config["delay_ms"].(float64); !ok || delay < 0 {
+			v.error(issueInvalidConfig, path, &step, "delay_ms must be a non-negative number")
+		}
+	case models.StepTypeLoop:
+		v.requireConfig(path, step, []string{"items"})
+	case models.StepTypeParallel:
+		branches, _ := step.Config["branches"].([]interface{})
+		if len(branches) == 0 {
+			v.error(issueMissingConfig, path, &step, "parallel step requires at least one branch")
+		}
+		if mode, _ := step.Config["mode"].(string); mode != "" && mode != parallelModeAll && mode != parallelModeAny {
+			v.error(issueInvalidConfig, path, &step, "unsupported parallel mode %q", mode)
+		}
+	case models.StepTypeLua:
+		v.requireConfig(path, step, []string{"script"})
+		v.validateLua(path, step)
+	}
+
+	if len(step.NextSteps) > 0 && step.Type != models.StepTypeCondition {
+		v.warn(issueIgnoredNextSteps, path+".next_steps", &step, "next_steps is only used by condition steps")
+	}
+
+	v.validateErrorAction(path, step)
+	v.validateTemplates(path+".config", step, step.Config)
+}
+
+// requireConfig reports config keys a step needs but doesn't set
+func (v *workflowValidator) requireConfig(path string, step models.WorkflowStep, keys []string) {
+	for _, key := range keys {
+		value, ok := step.Config[key]
+		if !ok || value == nil || value == "" {
+			v.error(issueMissingConfig, path+".config."+key, &step, "missing required config %q", key)
+		}
+	}
+}
+
+// validateConditionStep checks a condition step's conditions and branch references
+func (v *workflowValidator) validateConditionStep(path string, step models.WorkflowStep) {
+	rawConditions, ok := step.Config["conditions"]
+	if !ok {
+		v.error(issueMissingConfig, path+".config.conditions", &step, "missing required config %q", "conditions")
+	} else {
+		var conditions []models.WorkflowCondition
+		data, _ := json.Marshal(rawConditions)
+		if err := json.Unmarshal(data, &conditions); err != nil {
+			v.error(issueInvalidConfig, path+".config.conditions", &step, "conditions must be a list of conditions")
+		} else {
+			v.validateConditions(path+".config.conditions", &step, conditions)
+		}
+	}
+
+	if logic, _ := step.Config["logic"].(string); logic != "" && !strings.EqualFold(logic, "AND") && !strings.EqualFold(logic, "OR") {
+		v.error(issueInvalidConfig, path+".config.logic", &step, "logic must be AND or OR, got %q", logic)
+	}
+
+	references := branchReferences(step)
+	for _, key := range []string{"true_steps", "false_steps"} {
+		for _, name := range references[key] {
+			v.validateStepReference(path+".config."+key, step, name)
+		}
+	}
+
+	if len(step.NextSteps) > 0 && len(references["true_steps"]) > 0 {
+		v.warn(issueIgnoredNextSteps, path+".next_steps", &step, "next_steps is ignored because true_steps is set")
+	} else {
+		for _, name := range step.NextSteps {
+			v.validateStepReference(path+".next_steps", step, name)
+		}
+	}
+}
+
+// validateStepReference checks that a branch reference names a top-level step
+func (v *workflowValidator) validateStepReference(path string, step models.WorkflowStep, name string) {
+	index, ok := v.stepsByName[name]
+	if !ok || name == "" {
+		if _, isID := v.stepsByID[name]; isID {
+			v.error(issueDanglingReference, path, &step, "%q is a step id; branches reference steps by name", name)
+			return
+		}
+		v.error(issueDanglingReference, path, &step, "referenced step %q does not exist", name)
+		return
+	}
+
+	if !v.definition.Steps[index].Enabled {
+		v.warn(issueDisabledReference, path, &step, "referenced step %q is disabled and will be skipped", name)
+	}
+}
+
+// validateConditions checks condition operators and regular expressions
+func (v *workflowValidator) validateConditions(path string, step *models.WorkflowStep, conditions []models.WorkflowCondition) {
+	for i, condition := range conditions {
+		conditionPath := fmt.Sprintf("%s[%d]", path, i)
+
+		if !conditionOperators[condition.Operator] {
+			v.error(issueInvalidOperator, conditionPath, step, "unsupported condition operator %q", condition.Operator)
+			continue
+		}
+
+		if condition.Operator == models.OperatorRegex {
+			pattern := fmt.Sprintf("%v", condition.Value)
+			if _, err := regexp.Compile(pattern); err != nil {
+				v.error(issueInvalidRegex, conditionPath, step, "invalid regular expression %q: %v", pattern, err)
+			}
+		}
+	}
+}
+
+// validateLua checks that a Lua script parses
+func (v *workflowValidator) validateLua(path string, step models.WorkflowStep) {
+	script, ok := step.Config["script"].(string)
+	if !ok || script == "" {
+		return
+	}
+
+	if _, err := parse.Parse(strings.NewReader(script), step.Name); err != nil {
+		v.error(issueLuaSyntax, path+".config.script", &step, "Lua syntax error: %v", err)
+	}
+}
+
+// validateErrorAction checks a step's on_error settings
+func (v *workflowValidator) validateErrorAction(path string, step models.WorkflowStep) {
+	if step.OnError == nil {
+		return
+	}
+
+	path += ".on_error"
+	if !errorActions[step.OnError.Action] {
+		v.error(issueInvalidConfig, path, &step, "unsupported on_error action %q", step.OnError.Action)
+		return
+	}
+	if step.OnError.Action != "goto" {
+		return
+	}
+
+	target, ok := v.stepsByID[step.OnError.GotoStep]
+	if !ok {
+		v.error(issueDanglingReference, path+".goto_step", &step, "goto step %q does not exist", step.OnError.GotoStep)
+		return
+	}
+
+	if source, ok := v.stepsByID[step.ID]; ok && target <= source {
+		v.warn(issueBackwardGoto, path+".goto_step", &step, "goto step %q comes before this step, so steps in between run again after an error", step.OnError.GotoStep)
+	}
+}
+
+// validateTemplates checks the ${...} references in a step's config values
+func (v *workflowValidator) validateTemplates(path string, step models.WorkflowStep, value interface{}) {
+	switch value := value.(type) {
+	case string:
+		for _, reference := range templateReferences(value) {
+			v.validateTemplateReference(path, step, reference)
+		}
+	case map[string]interface{}:
+		for key, item := range value {
+			if nestedStepKeys[key] || key == "script" {
+				continue
+			}
+			v.validateTemplates(path+"."+key, step, item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			v.validateTemplates(fmt.Sprintf("%s[%d]", path, i), step, item)
+		}
+	}
+}
+
+// templateReferences returns the paths of the ${...} placeholders in text,
+// matching the way replaceVariablesWithContext finds them
+func templateReferences(text string) []string {
+	var references []string
+	for {
+		start := strings.Index(text, "${")
+		if start == -1 {
+			return references
+		}
+		end := strings.Index(text[start:], "}")
+		if end == -1 {
+			return references
+		}
+		references = append(references, text[start+2:start+end])
+		text = text[start+end+1:]
+	}
+}
+
+// validateTemplateReference resolves a single ${...} reference
+func (v *workflowValidator) validateTemplateReference(path string, step models.WorkflowStep, reference string) {
+	segments := strings.Split(strings.TrimSpace(reference), ".")
+	root := segments[0]
+	if root == "" {
+		v.error(issueUnknownTemplateReference, path, &step, "empty template reference ${%s}", reference)
+		return
+	}
+
+	switch root {
+	case "trigger_event":
+		if len(segments) < 2 || len(v.triggerFields) == 0 {
+			return
+		}
+		providedBy := 0
+		for _, fields := range v.triggerFields {
+			if fields == nil {
+				return
+			}
+			if fields[segments[1]] {
+				providedBy++
+			}
+		}
+		if providedBy == 0 {
+			v.error(issueUnknownTemplateReference, path, &step, "${%s} does not match a field of this workflow's trigger events", reference)
+		} else if providedBy < len(v.triggerFields) {
+			v.warn(issueUnknownTemplateReference, path, &step, "${%s} is only set by some of this workflow's triggers", reference)
+		}
+	case "metadata":
+		if len(segments) >= 2 && !metadataFields[segments[1]] {
+			v.warn(issueUnknownTemplateReference, path, &step, "${%s} does not match a known metadata field", reference)
+		}
+	default:
+		if !v.variables[root] && !v.hasLuaScript {
+			v.warn(issueUnknownVariable, path, &step, "${%s} references variable %q which is never set", reference, root)
+		}
+	}
+}
+
+// checkBranchPlacement warns about branch steps placed before the condition
+// that references them. They run in sequence before the condition can mark
+// them as branch steps, so they run unconditionally.
+func (v *workflowValidator) checkBranchPlacement() {
+	for i := range v.definition.Steps {
+		step := &v.definition.Steps[i]
+		if step.Type != models.StepTypeCondition || !step.Enabled {
+			continue
+		}
+
+		references := branchReferences(*step)
+		for _, name := range append(references["true_steps"], references["false_steps"]...) {
+			target, ok := v.stepsByName[name]
+			if !ok || target >= i || !v.definition.Steps[target].Enabled {
+				continue
+			}
+			v.warn(issueUnconditionalBranchStep, fmt.Sprintf("steps[%d]", target), &v.definition.Steps[target],
+				"step is a branch of condition %q but comes before it, so it always runs", step.Name)
+		}
+	}
+}
+
+// checkCycles finds top-level steps that reference each other through
+// condition branches. Such cycles recurse until the execution fails, unless a
+// delay step in the cycle slows them down, which is reported as a warning.
+func (v *workflowValidator) checkCycles() {
+	steps := v.definition.Steps
+	edges := make(map[int][]int)
+
+	for i := range steps {
+		walkSteps(fmt.Sprintf("steps[%d]", i), steps[i:i+1], func(_ string, step models.WorkflowStep) {
+			if step.Type != models.StepTypeCondition {
+				return
+			}
+
+			references := branchReferences(step)
+			names := append(references["true_steps"], references["false_steps"]...)
+			if len(references["true_steps"]) == 0 {
+				names = append(names, step.NextSteps...)
+			}
+			for _, name := range names {
+				if target, ok := v.stepsByName[name]; ok {
+					edges[i] = append(edges[i], target)
+				}
+			}
+		})
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(steps))
+	var stack []int
+	reported := make(map[string]bool)
+
+	var visit func(int)
+	visit = func(i int) {
+		state[i] = visiting
+		stack = append(stack, i)
+
+		for _, next := range edges[i] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				v.reportCycle(stack, next, reported)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[i] = done
+	}
+
+	for i := range steps {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+}
+
+// reportCycle reports the cycle that starts at index start on the DFS stack
+func (v *workflowValidator) reportCycle(stack []int, start int, reported map[string]bool) {
+	var cycle []int
+	for i := len(stack) - 1; i >= 0; i-- {
+		cycle = append([]int{stack[i]}, cycle...)
+		if stack[i] == start {
+			break
+		}
+	}
+
+	key := make([]int, len(cycle))
+	copy(key, cycle)
+	sort.Ints(key)
+	if reported[fmt.Sprint(key)] {
+		return
+	}
+	reported[fmt.Sprint(key)] = true
+
+	var names []string
+	hasDelay := false
+	for _, index := range cycle {
+		step := v.definition.Steps[index]
+		names = append(names, step.Name)
+		walkSteps("", []models.WorkflowStep{step}, func(_ string, nested models.WorkflowStep) {
+			if nested.Type == models.StepTypeDelay && nested.Enabled {
+				hasDelay = true
+			}
+		})
+	}
+	names = append(names, v.definition.Steps[start].Name)
+	description := strings.Join(names, " -> ")
+
+	path := fmt.Sprintf("steps[%d]", start)
+	if hasDelay {
+		v.warn(issueCycle, path, &v.definition.Steps[start], "steps reference each other in a loop (%s); the loop only ends when a condition changes", description)
+		return
+	}
+	v.error(issueCycle, path, &v.definition.Steps[start], "steps reference each other in a loop without a delay (%s)", description)
+}
+
+// stringSet converts a list of strings into a set
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
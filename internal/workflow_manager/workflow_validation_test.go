@@ -0,0 +1,87 @@
+package workflow_manager
+
+import (
+	"testing"
+
+	"go.codycody31.dev/squad-aegis/internal/models"
+)
+
+func issueCodes(issues []models.WorkflowValidationIssue) map[string]string {
+	codes := make(map[string]string)
+	for _, issue := range issues {
+		codes[issue.Code] = issue.Path
+	}
+	return codes
+}
+
+func TestValidateWorkflowDefinition(t *testing.T) {
+	definition := &models.WorkflowDefinition{
+		Triggers: []models.WorkflowTrigger{
+			{ID: "chat", Name: "Chat", EventType: "RCON_CHAT_MESSAGE", Enabled: true, Conditions: []models.WorkflowCondition{
+				{Field: "message", Operator: models.OperatorRegex, Value: "^!(help"},
+			}},
+		},
+		Steps: []models.WorkflowStep{
+			{ID: "check", Name: "Check", Type: models.StepTypeCondition, Enabled: true, Config: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"field": "trigger_event.message", "operator": "equals", "value": "!help"},
+				},
+				"true_steps":  []interface{}{"Recheck"},
+				"false_steps": []interface{}{"Missing"},
+			}},
+			{ID: "recheck", Name: "Recheck", Type: models.StepTypeCondition, Enabled: true, Config: map[string]interface{}{
+				"conditions": []interface{}{},
+				"true_steps": []interface{}{"Check"},
+			}},
+			{ID: "reply", Name: "Reply", Type: models.StepTypeAction, Enabled: true, Config: map[string]interface{}{
+				"action_type":   models.ActionTypeChatMessage,
+				"target_player": "${trigger_event.steam_id}",
+				"message":       "Hi ${trigger_event.player_nme}, you asked ${times} times",
+			}},
+			{ID: "script", Name: "Script", Type: models.StepTypeLua, Enabled: true, OnError: &models.WorkflowErrorAction{Action: "goto", GotoStep: "nowhere"}, Config: map[string]interface{}{
+				"script": "if then end",
+			}},
+		},
+	}
+
+	result := ValidateWorkflowDefinition(definition, nil)
+	if result.Valid {
+		t.Fatalf("Expected the definition to be invalid")
+	}
+
+	errors := issueCodes(result.Errors)
+	expected := map[string]string{
+		issueInvalidRegex:             "triggers[0].conditions[0]",
+		issueDanglingReference:        "steps[3].on_error.goto_step",
+		issueCycle:                    "steps[0]",
+		issueLuaSyntax:                "steps[3].config.script",
+		issueUnknownTemplateReference: "steps[2].config.message",
+	}
+	for code, path := range expected {
+		if errors[code] != path {
+			t.Errorf("Expected %s error at %s, got %q (errors: %+v)", code, path, errors[code], result.Errors)
+		}
+	}
+
+	// The Lua step could set any variable, so unknown variables aren't reported
+	if _, ok := issueCodes(result.Warnings)[issueUnknownVariable]; ok {
+		t.Errorf("Expected no unknown variable warning when a Lua step is present")
+	}
+
+	definition.Steps = definition.Steps[2:3]
+	definition.Triggers[0].Conditions = nil
+	definition.Steps[0].Config["message"] = "Hi ${trigger_event.player_name}, you asked ${times} times"
+
+	result = ValidateWorkflowDefinition(definition, nil)
+	if !result.Valid {
+		t.Fatalf("Expected the definition to be valid, got %+v", result.Errors)
+	}
+	if issueCodes(result.Warnings)[issueUnknownVariable] != "steps[0].config.message" {
+		t.Errorf("Expected an unknown variable warning, got %+v", result.Warnings)
+	}
+
+	result = ValidateWorkflowDefinition(definition, []string{"times"})
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected stored variables to resolve references, got %+v", result.Warnings)
+	}
+}
@@ -26,6 +26,13 @@ const navigationItems: NavigationItem[] = [
     },
     icon: "mdi:server",
   },
+  {
+    title: "Server Groups",
+    to: {
+      name: "server-groups",
+    },
+    icon: "mdi:server-network",
+  },
   {
     title: "Players",
     to: {
@@ -40,6 +47,14 @@ const navigationItems: NavigationItem[] = [
     },
     icon: "mdi:ban",
   },
+  {
+    title: "Federation",
+    to: {
+      name: "federation",
+    },
+    icon: "mdi:handshake",
+    permissions: ["super_admin"],
+  },
   {
     title: "Connectors",
     to: {
@@ -34,6 +34,14 @@ export default [
     },
     permissions: [UI_PERMISSIONS.BANS_VIEW],
   },
+  {
+    title: "Ban Appeals",
+    icon: "mdi:gavel",
+    to: {
+      name: "servers-serverId-ban-appeals",
+    },
+    permissions: [UI_PERMISSIONS.BAN_APPEALS_REVIEW],
+  },
   {
     title: "Users & Roles",
     icon: "mdi:account-star",
@@ -42,6 +50,14 @@ export default [
     },
     permissions: ["super_admin"],
   },
+  {
+    title: "Import Config",
+    icon: "mdi:file-import",
+    to: {
+      name: "servers-serverId-cfg-import",
+    },
+    permissions: ["super_admin"],
+  },
   {
     title: "Console",
     icon: "mdi:console",
@@ -74,6 +90,14 @@ export default [
     },
     permissions: [UI_PERMISSIONS.AUDIT_LOGS_VIEW],
   },
+  {
+    title: "Admin Camera",
+    icon: "mdi:cctv",
+    to: {
+      name: "servers-serverId-admin-camera",
+    },
+    permissions: [UI_PERMISSIONS.AUDIT_LOGS_VIEW],
+  },
   {
     title: "Rules",
     icon: "mdi:book-open",
@@ -106,6 +130,22 @@ export default [
     },
     permissions: [UI_PERMISSIONS.WORKFLOWS_VIEW],
   },
+  {
+    title: "Log Parsers",
+    icon: "mdi:regex",
+    to: {
+      name: "servers-serverId-log-parsers",
+    },
+    permissions: [UI_PERMISSIONS.SETTINGS_VIEW],
+  },
+  {
+    title: "Map Rotation",
+    icon: "mdi:map-marker-path",
+    to: {
+      name: "servers-serverId-rotation",
+    },
+    permissions: [UI_PERMISSIONS.SETTINGS_VIEW],
+  },
   {
     title: "Settings",
     icon: "mdi:cog",